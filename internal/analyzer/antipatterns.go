@@ -2,6 +2,7 @@ package analyzer
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -13,8 +14,19 @@ const (
 	maxNestingDepth        = 5
 	maxDocSizeBytes  int64 = 1_000_000 // 1 MB
 	maxFieldCount          = 200
+	maxPathLength          = 100
+
+	unboundedArrayElements int64 = 1000     // UNBOUNDED_ARRAY_FIELD above this
+	nearLimitDocBytes      int64 = 8 << 20  // DOCUMENTS_NEAR_16MB at half the BSON limit
+	criticalDocBytes       int64 = 12 << 20 // high severity at three quarters
+	dynamicKeyThreshold          = 1000     // distinct keys under one subdocument
+	dynamicKeyExamples           = 5
 )
 
+// encodedDotMarkers are substitutes applications use to smuggle "." into
+// field names; they make keys look like dotted paths that queries cannot match.
+var encodedDotMarkers = []string{"\uff0e", "\u2024", "\ufe52", "%2E", "%2e"}
+
 // DetectAntiPatterns analyzes sampled documents for common MongoDB data modeling mistakes.
 func DetectAntiPatterns(samples []mongoinspect.FieldSampleResult) []Finding {
 	if len(samples) == 0 {
@@ -29,15 +41,28 @@ func DetectAntiPatterns(samples []mongoinspect.FieldSampleResult) []Finding {
 		findings = append(findings, detectFieldNameCollision(&s)...)
 		findings = append(findings, detectExcessiveFieldCount(&s)...)
 		findings = append(findings, detectNumericFieldNames(&s)...)
+		findings = append(findings, detectFieldNameHazards(&s)...)
+		findings = append(findings, detectDynamicKeys(&s)...)
 	}
 	return findings
 }
 
-// detectUnboundedArrays flags array fields with more than maxArrayElements elements.
+// detectUnboundedArrays flags array fields with more than maxArrayElements
+// elements. Arrays past unboundedArrayElements get the stronger
+// UNBOUNDED_ARRAY_FIELD finding instead.
 func detectUnboundedArrays(s *mongoinspect.FieldSampleResult) []Finding {
 	var findings []Finding
 	for path, length := range s.ArrayLengths {
-		if length > maxArrayElements {
+		if length > unboundedArrayElements {
+			findings = append(findings, Finding{
+				Type:       FindingUnboundedArrayField,
+				Severity:   SeverityMedium,
+				Database:   s.Database,
+				Collection: s.Collection,
+				Message: fmt.Sprintf("array field %q has %d elements in a sampled document — "+
+					"unbounded arrays grow documents toward 16 MB and slow updates; consider a separate collection or bucketing", path, length),
+			})
+		} else if length > maxArrayElements {
 			findings = append(findings, Finding{
 				Type:       FindingUnboundedArray,
 				Severity:   SeverityLow,
@@ -79,6 +104,10 @@ func fieldPathDepth(path string) int {
 
 // detectLargeDocument flags collections with documents approaching the BSON size limit.
 func detectLargeDocument(s *mongoinspect.FieldSampleResult) []Finding {
+	if s.MaxDocSize >= nearLimitDocBytes {
+		return []Finding{nearLimitFinding(s.Database, s.Collection, s.MaxDocSize,
+			fmt.Sprintf("largest sampled document is %s", formatBytes(s.MaxDocSize)))}
+	}
 	if s.MaxDocSize <= maxDocSizeBytes {
 		return nil
 	}
@@ -91,6 +120,22 @@ func detectLargeDocument(s *mongoinspect.FieldSampleResult) []Finding {
 	}}
 }
 
+// nearLimitFinding builds a DOCUMENTS_NEAR_16MB finding, high severity once
+// size reaches criticalDocBytes.
+func nearLimitFinding(database, collection string, size int64, what string) Finding {
+	sev := SeverityMedium
+	if size >= criticalDocBytes {
+		sev = SeverityHigh
+	}
+	return Finding{
+		Type:       FindingDocumentsNear16MB,
+		Severity:   sev,
+		Database:   database,
+		Collection: collection,
+		Message:    fmt.Sprintf("%s (%.0f%% of the 16 MB BSON limit) — writes fail once a document exceeds it", what, float64(size)*100/float64(16<<20)),
+	}
+}
+
 // detectFieldNameCollision flags fields that appear as both object and scalar types.
 func detectFieldNameCollision(s *mongoinspect.FieldSampleResult) []Finding {
 	var findings []Finding
@@ -160,6 +205,121 @@ func detectNumericFieldNames(s *mongoinspect.FieldSampleResult) []Finding {
 	return findings
 }
 
+// detectFieldNameHazards flags field names that are legal in BSON but hard or
+// impossible to query reliably: keys with literal or look-alike dots, keys
+// starting with "$", very long paths, and sibling keys differing only by case.
+func detectFieldNameHazards(s *mongoinspect.FieldSampleResult) []Finding {
+	var findings []Finding
+	add := func(sev Severity, msg string) {
+		findings = append(findings, Finding{
+			Type:       FindingFieldNameHazard,
+			Severity:   sev,
+			Database:   s.Database,
+			Collection: s.Collection,
+			Message:    msg,
+		})
+	}
+
+	for _, path := range s.DottedKeys {
+		add(SeverityMedium, fmt.Sprintf("field %q has a key containing a literal dot — dot-notation queries cannot address it", path))
+	}
+
+	// siblings groups field names by parent path and lowercased name.
+	siblings := make(map[string][]string)
+	var groupOrder []string
+	for _, f := range s.Fields {
+		segments := strings.Split(f.Path, ".")
+		for _, seg := range segments {
+			if strings.HasPrefix(seg, "$") {
+				add(SeverityMedium, fmt.Sprintf("field %q has a key starting with \"$\" — conflicts with operators and update syntax", f.Path))
+				break
+			}
+		}
+		for _, marker := range encodedDotMarkers {
+			if strings.Contains(f.Path, marker) {
+				add(SeverityLow, fmt.Sprintf("field %q contains an encoded dot (%s) — likely an escaped dotted key", f.Path, marker))
+				break
+			}
+		}
+		if len(f.Path) > maxPathLength {
+			add(SeverityLow, fmt.Sprintf("field path %q is %d characters long — exceeds %d", f.Path, len(f.Path), maxPathLength))
+		}
+
+		parent, name := "", f.Path
+		if i := strings.LastIndex(f.Path, "."); i >= 0 {
+			parent, name = f.Path[:i], f.Path[i+1:]
+		}
+		key := parent + "\x00" + strings.ToLower(name)
+		if _, ok := siblings[key]; !ok {
+			groupOrder = append(groupOrder, key)
+		}
+		siblings[key] = append(siblings[key], f.Path)
+	}
+
+	for _, key := range groupOrder {
+		paths := siblings[key]
+		if len(paths) < 2 {
+			continue
+		}
+		quoted := make([]string, len(paths))
+		for i, p := range paths {
+			quoted[i] = strconv.Quote(p)
+		}
+		add(SeverityLow, fmt.Sprintf("fields %s differ only by case — queries on one miss documents using the other", strings.Join(quoted, ", ")))
+	}
+	return findings
+}
+
+// detectDynamicKeys flags subdocuments whose sampled key set runs into the
+// thousands, which usually means data values (dates, IDs, SKUs) are being
+// used as field names. Such keys cannot be indexed or validated.
+func detectDynamicKeys(s *mongoinspect.FieldSampleResult) []Finding {
+	children := make(map[string]map[string]bool)
+	for _, f := range s.Fields {
+		segments := strings.Split(f.Path, ".")
+		for i := 1; i < len(segments); i++ {
+			parent := strings.Join(segments[:i], ".")
+			if children[parent] == nil {
+				children[parent] = make(map[string]bool)
+			}
+			children[parent][segments[i]] = true
+		}
+	}
+
+	parents := make([]string, 0, len(children))
+	for parent, keys := range children {
+		if len(keys) >= dynamicKeyThreshold {
+			parents = append(parents, parent)
+		}
+	}
+	sort.Strings(parents)
+
+	var findings []Finding
+	for _, parent := range parents {
+		keys := sortedBoolKeys(children[parent])
+		numeric := 0
+		for _, k := range keys {
+			if k != "" && strings.Trim(k, "0123456789-_:T") == "" {
+				numeric++
+			}
+		}
+		advice := "move the keys into an array of {k, v} subdocuments (attribute pattern) so they can be indexed"
+		if numeric*2 > len(keys) {
+			advice = "keys look like dates or numbers; store them as values in bucketed documents (bucket pattern) or an array of {k, v} subdocuments"
+		}
+		examples := keys[:min(dynamicKeyExamples, len(keys))]
+		findings = append(findings, Finding{
+			Type:       FindingSchemaAntipattern,
+			Severity:   SeverityMedium,
+			Database:   s.Database,
+			Collection: s.Collection,
+			Message: fmt.Sprintf("subdocument %q has %d distinct keys across %d sampled documents (e.g. %s) — %s",
+				parent, len(keys), s.SampleSize, strings.Join(examples, ", "), advice),
+		})
+	}
+	return findings
+}
+
 // formatBytes returns a human-readable size string.
 func formatBytes(b int64) string {
 	switch {
@@ -1,7 +1,10 @@
 package analyzer
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
 )
@@ -24,6 +27,21 @@ func TestDetectUnboundedArrays(t *testing.T) {
 	}
 }
 
+func TestDetectUnboundedArrays_Field(t *testing.T) {
+	s := mongoinspect.FieldSampleResult{
+		Database:     "db",
+		Collection:   "events",
+		ArrayLengths: map[string]int64{"history": 5000},
+	}
+	findings := detectUnboundedArrays(&s)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(findings))
+	}
+	if findings[0].Type != FindingUnboundedArrayField || findings[0].Severity != SeverityMedium {
+		t.Errorf("got %s/%s, want %s/medium", findings[0].Type, findings[0].Severity, FindingUnboundedArrayField)
+	}
+}
+
 func TestDetectUnboundedArrays_Under(t *testing.T) {
 	s := mongoinspect.FieldSampleResult{
 		Database:   "db",
@@ -108,6 +126,26 @@ func TestDetectLargeDocument_Small(t *testing.T) {
 	}
 }
 
+func TestDetectLargeDocument_Near16MB(t *testing.T) {
+	tests := []struct {
+		size int64
+		want Severity
+	}{
+		{9 << 20, SeverityMedium},
+		{13 << 20, SeverityHigh},
+	}
+	for _, tt := range tests {
+		s := mongoinspect.FieldSampleResult{Database: "db", Collection: "blobs", MaxDocSize: tt.size}
+		findings := detectLargeDocument(&s)
+		if len(findings) != 1 {
+			t.Fatalf("size %d: expected 1 finding, got %d", tt.size, len(findings))
+		}
+		if findings[0].Type != FindingDocumentsNear16MB || findings[0].Severity != tt.want {
+			t.Errorf("size %d: got %s/%s, want %s/%s", tt.size, findings[0].Type, findings[0].Severity, FindingDocumentsNear16MB, tt.want)
+		}
+	}
+}
+
 func TestDetectFieldNameCollision(t *testing.T) {
 	s := mongoinspect.FieldSampleResult{
 		Database:   "db",
@@ -234,3 +272,94 @@ func TestFieldPathDepth(t *testing.T) {
 		})
 	}
 }
+
+func TestDetectFieldNameHazards(t *testing.T) {
+	longPath := "a." + strings.Repeat("x", 120)
+	s := mongoinspect.FieldSampleResult{
+		Database:   "db",
+		Collection: "events",
+		Fields: []mongoinspect.FieldFrequency{
+			{Path: "$type"},
+			{Path: "meta.userId"},
+			{Path: "meta.userid"},
+			{Path: "prices.usd\uff0eeur"},
+			{Path: longPath},
+			{Path: "userId"},
+		},
+		DottedKeys: []string{"meta.geo.lat"},
+	}
+	findings := detectFieldNameHazards(&s)
+
+	var medium, low int
+	var msgs []string
+	for _, f := range findings {
+		if f.Type != FindingFieldNameHazard {
+			t.Errorf("type = %s, want %s", f.Type, FindingFieldNameHazard)
+		}
+		switch f.Severity {
+		case SeverityMedium:
+			medium++
+		case SeverityLow:
+			low++
+		}
+		msgs = append(msgs, f.Message)
+	}
+	// Dotted key and "$type" are medium; encoded dot, long path and the
+	// meta.userId/meta.userid collision are low. Top-level userId alone is fine.
+	if medium != 2 || low != 3 {
+		t.Fatalf("got %d medium, %d low findings, want 2 and 3:\n%s", medium, low, strings.Join(msgs, "\n"))
+	}
+	joined := strings.Join(msgs, "\n")
+	for _, want := range []string{`"meta.geo.lat"`, `"$type"`, `"meta.userId", "meta.userid"`, "is 122 characters long"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("messages missing %s:\n%s", want, joined)
+		}
+	}
+}
+
+func TestDetectFieldNameHazards_Clean(t *testing.T) {
+	s := mongoinspect.FieldSampleResult{
+		Fields: []mongoinspect.FieldFrequency{{Path: "_id"}, {Path: "name"}, {Path: "address.city"}, {Path: "tags[].name"}},
+	}
+	if findings := detectFieldNameHazards(&s); len(findings) != 0 {
+		t.Errorf("expected no findings, got %v", findings)
+	}
+}
+
+func TestDetectDynamicKeys(t *testing.T) {
+	var fields []mongoinspect.FieldFrequency
+	for i := range 1200 {
+		day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, i).Format("2006-01-02")
+		fields = append(fields,
+			mongoinspect.FieldFrequency{Path: "stats." + day + ".views"},
+			mongoinspect.FieldFrequency{Path: "stats." + day + ".clicks"})
+	}
+	for i := range 1000 {
+		fields = append(fields, mongoinspect.FieldFrequency{Path: fmt.Sprintf("attrs.color_%d", i)})
+	}
+	fields = append(fields, mongoinspect.FieldFrequency{Path: "name"})
+	s := mongoinspect.FieldSampleResult{Database: "db", Collection: "metrics", SampleSize: 100, Fields: fields}
+
+	findings := detectDynamicKeys(&s)
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 findings, got %d: %v", len(findings), findings)
+	}
+	if findings[0].Type != FindingSchemaAntipattern {
+		t.Errorf("type = %s, want %s", findings[0].Type, FindingSchemaAntipattern)
+	}
+	if !strings.Contains(findings[0].Message, `"attrs" has 1000 distinct keys`) || !strings.Contains(findings[0].Message, "attribute pattern") {
+		t.Errorf("attrs message = %q", findings[0].Message)
+	}
+	if !strings.Contains(findings[1].Message, `"stats" has 1200 distinct keys`) ||
+		!strings.Contains(findings[1].Message, "e.g. 2024-01-01, 2024-01-02") ||
+		!strings.Contains(findings[1].Message, "bucket pattern") {
+		t.Errorf("stats message = %q", findings[1].Message)
+	}
+}
+
+func TestDetectDynamicKeys_FewKeys(t *testing.T) {
+	s := mongoinspect.FieldSampleResult{Fields: []mongoinspect.FieldFrequency{{Path: "address.city"}, {Path: "address.zip"}}}
+	if findings := detectDynamicKeys(&s); len(findings) != 0 {
+		t.Errorf("expected 0 findings, got %d", len(findings))
+	}
+}
@@ -0,0 +1,97 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+const (
+	archiveMinAge   = 365 * 24 * time.Hour // data older than this is cold
+	archiveMinBytes = 1 << 30              // smaller collections are not worth archiving
+	archiveMinShare = 0.2                  // share of sampled documents a date range must cover
+)
+
+// DetectArchiveCandidates proposes data to move to cold storage, with the
+// storage and index bytes archiving would free. A collection whose indexes
+// have served no operations, that has not been written to since the server
+// started, and whose newest sampled document is over a year old can be
+// archived whole. Otherwise the sample's _id creation years
+// that ended over a year ago are proposed as a date range when they cover
+// at least archiveMinShare of the sample; the bytes are that share of the
+// collection's size, assuming similar document sizes across years.
+func DetectArchiveCandidates(collections []mongoinspect.CollectionInfo, samples []mongoinspect.FieldSampleResult) []Finding {
+	byNS := make(map[string]*mongoinspect.FieldSampleResult, len(samples))
+	for i := range samples {
+		byNS[samples[i].Database+"."+samples[i].Collection] = &samples[i]
+	}
+
+	var findings []Finding
+	for i := range collections {
+		c := &collections[i]
+		total := c.StorageSize + c.TotalIndexSize
+		if c.Type == "view" || total < archiveMinBytes {
+			continue
+		}
+		s := byNS[c.Database+"."+c.Name]
+		if s == nil {
+			continue
+		}
+		add := func(msg string) {
+			findings = append(findings, Finding{
+				Type:       FindingArchiveCandidate,
+				Severity:   SeverityLow,
+				Database:   c.Database,
+				Collection: c.Name,
+				Message:    msg,
+			})
+		}
+
+		written := c.Latency != nil && c.Latency.Writes.Ops > 0
+		if age := s.Age(); age != nil && now().Sub(age.Newest) >= archiveMinAge && indexesUnused(c) && !written {
+			add(fmt.Sprintf("no index has served an operation and the newest sampled document dates from %s; archiving the collection to cold storage would reclaim about %s",
+				age.Newest.Format(time.DateOnly), formatBytes(total)))
+			continue
+		}
+
+		// Years that ended before the cutoff hold only cold documents.
+		cutoffYear := now().Add(-archiveMinAge).Year()
+		var sampled, cold int64
+		var years []int
+		for year, n := range s.CreatedByYear {
+			sampled += n
+			if year < cutoffYear {
+				cold += n
+				years = append(years, year)
+			}
+		}
+		if sampled == 0 || float64(cold)/float64(sampled) < archiveMinShare {
+			continue
+		}
+		share := float64(cold) / float64(sampled)
+		sort.Ints(years)
+		span := fmt.Sprint(years[0])
+		if last := years[len(years)-1]; last != years[0] {
+			span = fmt.Sprintf("%d–%d", years[0], last)
+		}
+		add(fmt.Sprintf("documents created before %d (%s) are %.0f%% of %d sampled; archiving them to cold storage would reclaim about %s",
+			cutoffYear, span, share*100, sampled, formatBytes(int64(share*float64(total)))))
+	}
+	return findings
+}
+
+// indexesUnused reports whether $indexStats covers every index of c and
+// none has served an operation.
+func indexesUnused(c *mongoinspect.CollectionInfo) bool {
+	if len(c.Indexes) == 0 {
+		return false
+	}
+	for _, idx := range c.Indexes {
+		if idx.Stats == nil || idx.Stats.Ops > 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+func TestDetectArchiveCandidates(t *testing.T) {
+	fixed := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return fixed }
+	t.Cleanup(func() { now = time.Now })
+	const gb = 1 << 30
+	unused := []mongoinspect.IndexInfo{{Name: "_id_", Stats: &mongoinspect.IndexStats{Ops: 0}}}
+	used := []mongoinspect.IndexInfo{{Name: "_id_", Stats: &mongoinspect.IndexStats{Ops: 42}}}
+	collections := []mongoinspect.CollectionInfo{
+		{Database: "app", Name: "legacy", StorageSize: 3 * gb, TotalIndexSize: gb, Indexes: unused},
+		{Database: "app", Name: "events", StorageSize: 8 * gb, TotalIndexSize: 2 * gb, Indexes: used},
+		{Database: "app", Name: "orders", StorageSize: 8 * gb, Indexes: used},
+		{Database: "app", Name: "small", StorageSize: gb / 10, Indexes: unused},
+		{Database: "app", Name: "journal", StorageSize: 3 * gb, Indexes: unused,
+			Latency: &mongoinspect.LatencyStats{Writes: mongoinspect.OpLatency{Ops: 5}}},
+	}
+	old := []mongoinspect.DateRange{{Field: "_id", Oldest: fixed.AddDate(-5, 0, 0), Newest: fixed.AddDate(-2, 0, 0)}}
+	samples := []mongoinspect.FieldSampleResult{
+		{Database: "app", Collection: "legacy", DateRanges: old, CreatedByYear: map[int]int64{2021: 100}},
+		{Database: "app", Collection: "events", DateRanges: old, CreatedByYear: map[int]int64{2022: 20, 2023: 40, 2025: 30, 2026: 10}},
+		{Database: "app", Collection: "orders", CreatedByYear: map[int]int64{2024: 10, 2025: 90}},
+		{Database: "app", Collection: "small", DateRanges: old, CreatedByYear: map[int]int64{2021: 10}},
+		{Database: "app", Collection: "journal", DateRanges: old, CreatedByYear: map[int]int64{2025: 10}},
+	}
+
+	findings := DetectArchiveCandidates(collections, samples)
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings, want 2: %+v", len(findings), findings)
+	}
+	if f := findings[0]; f.Type != FindingArchiveCandidate || f.Collection != "legacy" ||
+		!strings.Contains(f.Message, "archiving the collection") || !strings.Contains(f.Message, "4096.0 MB") {
+		t.Errorf("legacy finding = %+v", f)
+	}
+	// 2022 and 2023 ended over a year before March 2026: 60% of 10 GB.
+	if f := findings[1]; f.Collection != "events" ||
+		!strings.Contains(f.Message, "created before 2025 (2022–2023) are 60% of 100 sampled") || !strings.Contains(f.Message, "6144.0 MB") {
+		t.Errorf("events finding = %+v", f)
+	}
+}
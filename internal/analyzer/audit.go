@@ -3,6 +3,7 @@ package analyzer
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
 )
@@ -11,35 +12,19 @@ const (
 	// Collections with more docs than this but only _id index get flagged.
 	missingIndexThreshold int64 = 10_000
 
-	// Collections larger than this (bytes) get flagged as oversized.
-	oversizedThreshold int64 = 10 * 1024 * 1024 * 1024 // 10 GB
-
 	// Common timestamp field names that suggest a TTL index might be needed.
 	timestampFieldHint = "created,updated,timestamp,expires,expiry,ttl,lastModified,createdAt,updatedAt,expiresAt"
 
-	// Collections with more indexes than this get flagged for write amplification.
-	maxIndexesPerCollection = 10
-
-	// Individual indexes larger than this (bytes) get flagged.
-	largeIndexThreshold int64 = 1 << 30 // 1 GB
+	// Hidden indexes without operations for longer than this are safe to drop.
+	hiddenIndexMaxAge = 30 * 24 * time.Hour
 )
 
+// now is the clock used for age-based detections; tests replace it.
+var now = time.Now
+
 // Audit runs all cluster-only detections against the given collections.
 func Audit(collections []mongoinspect.CollectionInfo) []Finding {
-	var findings []Finding
-	for _, c := range collections {
-		findings = append(findings, detectUnusedCollection(&c)...)
-		findings = append(findings, detectUnusedIndexes(&c)...)
-		findings = append(findings, detectMissingIndexes(&c)...)
-		findings = append(findings, detectDuplicateIndexes(&c)...)
-		findings = append(findings, detectOversizedCollection(&c)...)
-		findings = append(findings, detectMissingTTL(&c)...)
-		findings = append(findings, detectIndexBloat(&c)...)
-		findings = append(findings, detectWriteHeavyOverIndexed(&c)...)
-		findings = append(findings, detectSingleFieldRedundant(&c)...)
-		findings = append(findings, detectLargeIndex(&c)...)
-	}
-	return findings
+	return NewPipeline(AuditDetectors()...).Collect(collections)
 }
 
 // detectUnusedCollection flags collections with zero documents.
@@ -57,10 +42,12 @@ func detectUnusedCollection(c *mongoinspect.CollectionInfo) []Finding {
 }
 
 // detectUnusedIndexes flags indexes with zero operations (excluding _id).
+// Hidden indexes are never used by the planner and are handled by
+// detectForgottenHiddenIndexes.
 func detectUnusedIndexes(c *mongoinspect.CollectionInfo) []Finding {
 	var findings []Finding
 	for _, idx := range c.Indexes {
-		if idx.Name == "_id_" {
+		if idx.Name == "_id_" || idx.Hidden {
 			continue
 		}
 		if idx.Stats != nil && idx.Stats.Ops == 0 {
@@ -70,13 +57,45 @@ func detectUnusedIndexes(c *mongoinspect.CollectionInfo) []Finding {
 				Database:   c.Database,
 				Collection: c.Name,
 				Index:      idx.Name,
-				Message:    fmt.Sprintf("index %q has never been used", idx.Name),
+				Message: fmt.Sprintf("index %q has never been used; hide it first with %s and drop it once nothing regresses",
+					idx.Name, indexCommand(c, "hideIndex", idx.Name)),
 			})
 		}
 	}
 	return findings
 }
 
+// detectForgottenHiddenIndexes flags hidden indexes whose stats have shown no
+// operations for over hiddenIndexMaxAge. Hiding is meant as a short trial
+// before dropping; a hidden index still costs writes and storage.
+func detectForgottenHiddenIndexes(c *mongoinspect.CollectionInfo) []Finding {
+	var findings []Finding
+	for _, idx := range c.Indexes {
+		if !idx.Hidden || idx.Stats == nil || idx.Stats.Ops != 0 || idx.Stats.Since.IsZero() {
+			continue
+		}
+		age := now().Sub(idx.Stats.Since)
+		if age < hiddenIndexMaxAge {
+			continue
+		}
+		findings = append(findings, Finding{
+			Type:       FindingHiddenIndexForgotten,
+			Severity:   SeverityLow,
+			Database:   c.Database,
+			Collection: c.Name,
+			Index:      idx.Name,
+			Message: fmt.Sprintf("index %q has been hidden with zero operations for %d days; it is safe to drop with %s (or restore with %s)",
+				idx.Name, int(age.Hours()/24), indexCommand(c, "dropIndex", idx.Name), indexCommand(c, "unhideIndex", idx.Name)),
+		})
+	}
+	return findings
+}
+
+// indexCommand renders a mongosh index helper call for one index.
+func indexCommand(c *mongoinspect.CollectionInfo, helper, index string) string {
+	return fmt.Sprintf("db.getSiblingDB(%q).getCollection(%q).%s(%q)", c.Database, c.Name, helper, index)
+}
+
 // detectMissingIndexes flags collections with high doc count but only the _id index.
 func detectMissingIndexes(c *mongoinspect.CollectionInfo) []Finding {
 	if c.DocCount < missingIndexThreshold {
@@ -125,7 +144,7 @@ func detectDuplicateIndexes(c *mongoinspect.CollectionInfo) []Finding {
 
 // detectOversizedCollection flags collections exceeding the size threshold.
 func detectOversizedCollection(c *mongoinspect.CollectionInfo) []Finding {
-	if c.StorageSize < oversizedThreshold {
+	if c.StorageSize < thresholdsFor(c.Database).OversizedCollection {
 		return nil
 	}
 	gb := float64(c.StorageSize) / (1024 * 1024 * 1024)
@@ -138,6 +157,16 @@ func detectOversizedCollection(c *mongoinspect.CollectionInfo) []Finding {
 	}}
 }
 
+// detectLargeAvgDocument flags collections whose average document size from
+// collStats is already near the BSON limit; individual documents are larger.
+func detectLargeAvgDocument(c *mongoinspect.CollectionInfo) []Finding {
+	if c.AvgObjSize < nearLimitDocBytes {
+		return nil
+	}
+	return []Finding{nearLimitFinding(c.Database, c.Name, c.AvgObjSize,
+		fmt.Sprintf("average document size is %s", formatBytes(c.AvgObjSize)))}
+}
+
 // detectMissingTTL flags indexes on common timestamp fields that lack a TTL.
 func detectMissingTTL(c *mongoinspect.CollectionInfo) []Finding {
 	hints := strings.Split(timestampFieldHint, ",")
@@ -197,7 +226,7 @@ func detectIndexBloat(c *mongoinspect.CollectionInfo) []Finding {
 
 // detectWriteHeavyOverIndexed flags collections with too many indexes.
 func detectWriteHeavyOverIndexed(c *mongoinspect.CollectionInfo) []Finding {
-	if len(c.Indexes) <= maxIndexesPerCollection {
+	if len(c.Indexes) <= thresholdsFor(c.Database).MaxIndexes {
 		return nil
 	}
 	return []Finding{{
@@ -238,9 +267,10 @@ func detectSingleFieldRedundant(c *mongoinspect.CollectionInfo) []Finding {
 
 // detectLargeIndex flags individual indexes exceeding the size threshold.
 func detectLargeIndex(c *mongoinspect.CollectionInfo) []Finding {
+	limit := thresholdsFor(c.Database).LargeIndex
 	var findings []Finding
 	for _, idx := range c.Indexes {
-		if idx.Size < largeIndexThreshold {
+		if idx.Size < limit {
 			continue
 		}
 		gb := float64(idx.Size) / (1024 * 1024 * 1024)
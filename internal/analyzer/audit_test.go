@@ -2,7 +2,9 @@ package analyzer
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
 )
@@ -81,6 +83,42 @@ func TestDetectUnusedIndexes(t *testing.T) {
 	if findings[0].Index != "email_1" {
 		t.Errorf("expected index email_1, got %s", findings[0].Index)
 	}
+	if !strings.Contains(findings[0].Message, `db.getSiblingDB("db").getCollection("users").hideIndex("email_1")`) {
+		t.Errorf("expected hideIndex suggestion, got %q", findings[0].Message)
+	}
+}
+
+func TestDetectForgottenHiddenIndexes(t *testing.T) {
+	fixed := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return fixed }
+	t.Cleanup(func() { now = time.Now })
+
+	hidden := func(name string, ops int64, since time.Time) mongoinspect.IndexInfo {
+		return mongoinspect.IndexInfo{Name: name, Key: kf(name), Hidden: true, Stats: &mongoinspect.IndexStats{Ops: ops, Since: since}}
+	}
+	coll := mongoinspect.CollectionInfo{
+		Name:     "users",
+		Database: "db",
+		Indexes: []mongoinspect.IndexInfo{
+			hidden("old", 0, fixed.AddDate(0, 0, -45)),
+			hidden("recent", 0, fixed.AddDate(0, 0, -3)),
+			hidden("used", 7, fixed.AddDate(0, 0, -45)),
+		},
+	}
+	findings := detectForgottenHiddenIndexes(&coll)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %+v", findings)
+	}
+	f := findings[0]
+	if f.Type != FindingHiddenIndexForgotten || f.Index != "old" || f.Severity != SeverityLow {
+		t.Errorf("unexpected finding %+v", f)
+	}
+	if !strings.Contains(f.Message, "45 days") || !strings.Contains(f.Message, `dropIndex("old")`) {
+		t.Errorf("message = %q", f.Message)
+	}
+	if got := detectUnusedIndexes(&coll); len(got) != 0 {
+		t.Errorf("hidden indexes should not be reported as UNUSED_INDEX, got %+v", got)
+	}
 }
 
 func TestDetectUnusedIndexes_SkipsID(t *testing.T) {
@@ -384,6 +422,18 @@ func TestDetectLargeIndex(t *testing.T) {
 	}
 }
 
+func TestDetectLargeAvgDocument(t *testing.T) {
+	big := mongoinspect.CollectionInfo{Name: "blobs", Database: "db", AvgObjSize: 10 << 20}
+	findings := detectLargeAvgDocument(&big)
+	if len(findings) != 1 || findings[0].Type != FindingDocumentsNear16MB {
+		t.Fatalf("expected one %s finding, got %v", FindingDocumentsNear16MB, findings)
+	}
+	small := mongoinspect.CollectionInfo{Name: "users", Database: "db", AvgObjSize: 2048}
+	if findings := detectLargeAvgDocument(&small); len(findings) != 0 {
+		t.Errorf("expected 0 findings, got %d", len(findings))
+	}
+}
+
 func TestDetectLargeIndex_Under(t *testing.T) {
 	coll := mongoinspect.CollectionInfo{
 		Name:     "small",
@@ -1,26 +1,45 @@
 package analyzer
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"math"
 	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
 )
 
-// BaselineStatus indicates whether a finding is new, resolved, or unchanged.
+// BaselineStatus indicates whether a finding is new, resolved, changed,
+// or unchanged.
 type BaselineStatus string
 
 const (
 	StatusNew       BaselineStatus = "new"
 	StatusResolved  BaselineStatus = "resolved"
+	StatusChanged   BaselineStatus = "changed"
 	StatusUnchanged BaselineStatus = "unchanged"
 )
 
+// metricChangeRatio is how far a number in a finding's message must move,
+// relative to the baseline value, before the finding counts as changed.
+// Smaller drifts, such as a few more operations, stay unchanged.
+const metricChangeRatio = 0.2
+
 // BaselineFinding wraps a Finding with a diff status against a baseline.
+// Changed findings carry the baseline's severity and message where they
+// differ from the current ones.
 type BaselineFinding struct {
 	Finding
-	Status BaselineStatus `json:"status"`
+	Status           BaselineStatus `json:"status"`
+	PreviousSeverity Severity       `json:"previousSeverity,omitempty"`
+	PreviousMessage  string         `json:"previousMessage,omitempty"`
 }
 
 // baselineReport is the minimal structure needed to load a previous JSON report.
@@ -67,12 +86,60 @@ func LoadBaselineWithCollections(path string) ([]Finding, []mongoinspect.Collect
 	return report.Findings, report.Collections, ts, nil
 }
 
+// BaselineFilePrefix and BaselineFileExt name the reports kept in a baseline
+// directory: baseline-<UTC timestamp>.json, so lexical order is chronological.
+const (
+	BaselineFilePrefix = "baseline-"
+	BaselineFileExt    = ".json"
+)
+
+// BaselineFileName returns the file name used for a baseline saved at t.
+func BaselineFileName(t time.Time) string {
+	return BaselineFilePrefix + t.UTC().Format("20060102T150405Z") + BaselineFileExt
+}
+
+// ListBaselines returns the saved baseline reports in dir, oldest first.
+// A missing directory yields no baselines and no error.
+func ListBaselines(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var paths []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, BaselineFilePrefix) || !strings.HasSuffix(name, BaselineFileExt) {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, name))
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// LatestBaseline returns the most recent baseline in dir, or "" if none exist.
+func LatestBaseline(dir string) (string, error) {
+	paths, err := ListBaselines(dir)
+	if err != nil || len(paths) == 0 {
+		return "", err
+	}
+	return paths[len(paths)-1], nil
+}
+
 // DiffBaseline compares current findings against baseline findings.
-// Returns tagged findings with status new/resolved/unchanged.
+// Returns tagged findings with status new/resolved/changed/unchanged. A
+// finding present in both is changed when its severity differs, or its
+// message differs in wording or in a number by at least 20%.
 func DiffBaseline(current, baseline []Finding) []BaselineFinding {
-	baselineSet := make(map[string]bool)
-	for _, f := range baseline {
-		baselineSet[findingKey(&f)] = true
+	baselineSet := make(map[string]*Finding)
+	for i := range baseline {
+		key := findingKey(&baseline[i])
+		if baselineSet[key] == nil {
+			baselineSet[key] = &baseline[i]
+		}
 	}
 
 	currentSet := make(map[string]bool)
@@ -82,13 +149,21 @@ func DiffBaseline(current, baseline []Finding) []BaselineFinding {
 
 	var result []BaselineFinding
 
-	// Current findings: new or unchanged.
+	// Current findings: new, changed or unchanged.
 	for _, f := range current {
-		status := StatusNew
-		if baselineSet[findingKey(&f)] {
-			status = StatusUnchanged
+		prev := baselineSet[findingKey(&f)]
+		if prev == nil {
+			result = append(result, BaselineFinding{Finding: f, Status: StatusNew})
+			continue
+		}
+		bf := BaselineFinding{Finding: f, Status: StatusUnchanged}
+		if prev.Severity != f.Severity {
+			bf.Status, bf.PreviousSeverity = StatusChanged, prev.Severity
 		}
-		result = append(result, BaselineFinding{Finding: f, Status: status})
+		if messageChanged(prev.Message, f.Message) {
+			bf.Status, bf.PreviousMessage = StatusChanged, prev.Message
+		}
+		result = append(result, bf)
 	}
 
 	// Baseline findings not in current: resolved.
@@ -101,6 +176,36 @@ func DiffBaseline(current, baseline []Finding) []BaselineFinding {
 	return result
 }
 
+var messageNumber = regexp.MustCompile(`\d+(?:\.\d+)?`)
+
+// messageChanged compares two messages for the same finding. The wording
+// must match with numbers masked out, and each number must be within
+// metricChangeRatio of its old value.
+func messageChanged(old, cur string) bool {
+	if old == cur {
+		return false
+	}
+	if messageNumber.ReplaceAllString(old, "#") != messageNumber.ReplaceAllString(cur, "#") {
+		return true
+	}
+	oldNums := messageNumber.FindAllString(old, -1)
+	curNums := messageNumber.FindAllString(cur, -1)
+	for i := range oldNums {
+		a, _ := strconv.ParseFloat(oldNums[i], 64)
+		b, _ := strconv.ParseFloat(curNums[i], 64)
+		if a == 0 {
+			if b != 0 {
+				return true
+			}
+			continue
+		}
+		if math.Abs(b-a)/math.Abs(a) >= metricChangeRatio {
+			return true
+		}
+	}
+	return false
+}
+
 // findingKey creates a stable identity for a finding based on type+location.
 func findingKey(f *Finding) string {
 	key := string(f.Type) + "|" + f.Database + "|" + f.Collection
@@ -109,3 +214,10 @@ func findingKey(f *Finding) string {
 	}
 	return key
 }
+
+// Fingerprint returns a short stable hash of a finding's identity, the
+// same type+location used for baseline diffs, for deduplicating alerts.
+func Fingerprint(f *Finding) string {
+	sum := sha256.Sum256([]byte(findingKey(f)))
+	return hex.EncodeToString(sum[:12])
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestDiffBaseline_NewFindings(t *testing.T) {
@@ -128,3 +129,87 @@ func TestFindingKey(t *testing.T) {
 		t.Error("different types should have different keys")
 	}
 }
+
+func TestFingerprint(t *testing.T) {
+	f1 := Finding{Type: FindingUnusedIndex, Database: "app", Collection: "users", Index: "idx_old", Message: "a"}
+	f2 := Finding{Type: FindingUnusedIndex, Database: "app", Collection: "users", Index: "idx_old", Message: "b"}
+	if Fingerprint(&f1) != Fingerprint(&f2) || len(Fingerprint(&f1)) != 24 {
+		t.Errorf("fingerprints = %q, %q, want equal 24-char hashes", Fingerprint(&f1), Fingerprint(&f2))
+	}
+	f3 := Finding{Type: FindingUnusedIndex, Database: "app", Collection: "orders", Index: "idx_old"}
+	if Fingerprint(&f1) == Fingerprint(&f3) {
+		t.Error("different locations should have different fingerprints")
+	}
+}
+
+func TestListBaselines(t *testing.T) {
+	dir := t.TempDir()
+	t1 := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	for _, name := range []string{BaselineFileName(t2), BaselineFileName(t1), "notes.txt", ".baseline-123.tmp"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paths, err := ListBaselines(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 {
+		t.Fatalf("expected 2 baselines, got %v", paths)
+	}
+	if filepath.Base(paths[0]) != "baseline-20260102T030405Z.json" {
+		t.Errorf("oldest = %s", paths[0])
+	}
+
+	latest, err := LatestBaseline(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest != filepath.Join(dir, BaselineFileName(t2)) {
+		t.Errorf("latest = %s", latest)
+	}
+}
+
+func TestLatestBaseline_MissingDir(t *testing.T) {
+	latest, err := LatestBaseline(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || latest != "" {
+		t.Fatalf("LatestBaseline = %q, %v; want empty, nil", latest, err)
+	}
+}
+
+func TestDiffBaseline_Changed(t *testing.T) {
+	base := Finding{Type: FindingUnindexedQuery, Database: "app", Collection: "orders", Severity: SeverityMedium, Message: "COLLSCAN seen 100 times"}
+	tests := []struct {
+		name     string
+		current  Finding
+		want     BaselineStatus
+		prevSev  Severity
+		prevText string
+	}{
+		{"identical", base, StatusUnchanged, "", ""},
+		{"small drift", Finding{Type: base.Type, Database: "app", Collection: "orders", Severity: SeverityMedium, Message: "COLLSCAN seen 110 times"}, StatusUnchanged, "", ""},
+		{"metric jump", Finding{Type: base.Type, Database: "app", Collection: "orders", Severity: SeverityMedium, Message: "COLLSCAN seen 150 times"}, StatusChanged, "", "COLLSCAN seen 100 times"},
+		{"severity", Finding{Type: base.Type, Database: "app", Collection: "orders", Severity: SeverityHigh, Message: "COLLSCAN seen 100 times"}, StatusChanged, SeverityMedium, ""},
+		{"wording", Finding{Type: base.Type, Database: "app", Collection: "orders", Severity: SeverityMedium, Message: "COLLSCAN seen 100 times on a sharded collection"}, StatusChanged, "", "COLLSCAN seen 100 times"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := DiffBaseline([]Finding{tt.current}, []Finding{base})
+			if len(result) != 1 {
+				t.Fatalf("expected 1 result, got %d", len(result))
+			}
+			got := result[0]
+			if got.Status != tt.want {
+				t.Errorf("status = %s, want %s", got.Status, tt.want)
+			}
+			if got.PreviousSeverity != tt.prevSev {
+				t.Errorf("previous severity = %q, want %q", got.PreviousSeverity, tt.prevSev)
+			}
+			if got.PreviousMessage != tt.prevText {
+				t.Errorf("previous message = %q, want %q", got.PreviousMessage, tt.prevText)
+			}
+		})
+	}
+}
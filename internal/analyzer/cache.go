@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+const (
+	cacheFitWarnShare = 0.8 // hot indexes above this share of the cache leave little room for documents
+	cacheFitTopN      = 5   // collections listed in the contribution ranking
+)
+
+// DetectIndexesExceedCache compares the indexes that serve traffic with the
+// WiredTiger cache. An index is hot when $indexStats reports operations on
+// it, or when no stats are available. Hot indexes that do not fit in the
+// cache are read from disk on every miss; above cacheFitWarnShare they crowd
+// out the documents queries return. The finding is cluster-wide and ranks
+// the collections contributing the most hot index bytes.
+func DetectIndexesExceedCache(collections []mongoinspect.CollectionInfo, cache mongoinspect.CacheInfo) []Finding {
+	if cache.MaxBytes <= 0 {
+		return nil
+	}
+	type contribution struct {
+		ns    string
+		bytes int64
+	}
+	var ranked []contribution
+	var hot, all int64
+	for i := range collections {
+		c := &collections[i]
+		var n int64
+		for _, idx := range c.Indexes {
+			all += idx.Size
+			if idx.Stats == nil || idx.Stats.Ops > 0 {
+				n += idx.Size
+			}
+		}
+		if n > 0 {
+			hot += n
+			ranked = append(ranked, contribution{c.Database + "." + c.Name, n})
+		}
+	}
+	share := float64(hot) / float64(cache.MaxBytes)
+	if share <= cacheFitWarnShare {
+		return nil
+	}
+	sev := SeverityMedium
+	if share > 1 {
+		sev = SeverityHigh
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].bytes > ranked[j].bytes })
+	if len(ranked) > cacheFitTopN {
+		ranked = ranked[:cacheFitTopN]
+	}
+	top := make([]string, len(ranked))
+	for i, r := range ranked {
+		top[i] = fmt.Sprintf("%s %s (%.0f%%)", r.ns, formatBytes(r.bytes), float64(r.bytes)/float64(hot)*100)
+	}
+	return []Finding{{
+		Type:     FindingIndexesExceedCache,
+		Severity: sev,
+		Message: fmt.Sprintf("indexes in use total %s, %.0f%% of the %s WiredTiger cache (%s of indexes overall); largest contributors: %s — drop unused indexes, shrink the largest, or add memory",
+			formatBytes(hot), share*100, formatBytes(cache.MaxBytes), formatBytes(all), strings.Join(top, ", ")),
+	}}
+}
@@ -0,0 +1,44 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+func TestDetectIndexesExceedCache(t *testing.T) {
+	const gb = 1 << 30
+	used := &mongoinspect.IndexStats{Ops: 10}
+	collections := []mongoinspect.CollectionInfo{
+		{Database: "app", Name: "events", Indexes: []mongoinspect.IndexInfo{
+			{Name: "_id_", Size: 3 * gb, Stats: used},
+			{Name: "legacy_1", Size: 4 * gb, Stats: &mongoinspect.IndexStats{Ops: 0}},
+		}},
+		{Database: "app", Name: "orders", Indexes: []mongoinspect.IndexInfo{
+			{Name: "_id_", Size: gb, Stats: used},
+			{Name: "status_1", Size: gb}, // no stats: counted as hot
+		}},
+	}
+
+	findings := DetectIndexesExceedCache(collections, mongoinspect.CacheInfo{MaxBytes: 4 * gb})
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1: %+v", len(findings), findings)
+	}
+	f := findings[0]
+	if f.Type != FindingIndexesExceedCache || f.Severity != SeverityHigh || f.Database != "" {
+		t.Errorf("finding = %+v", f)
+	}
+	for _, want := range []string{"total 5120.0 MB, 125% of the 4096.0 MB", "9216.0 MB of indexes overall", "app.events 3072.0 MB (60%), app.orders 2048.0 MB (40%)"} {
+		if !strings.Contains(f.Message, want) {
+			t.Errorf("message %q missing %q", f.Message, want)
+		}
+	}
+
+	if got := DetectIndexesExceedCache(collections, mongoinspect.CacheInfo{MaxBytes: 6 * gb}); len(got) != 1 || got[0].Severity != SeverityMedium {
+		t.Errorf("83%% of cache: %+v", got)
+	}
+	if got := DetectIndexesExceedCache(collections, mongoinspect.CacheInfo{MaxBytes: 16 * gb}); len(got) != 0 {
+		t.Errorf("fits in cache: %+v", got)
+	}
+}
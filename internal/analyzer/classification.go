@@ -0,0 +1,485 @@
+package analyzer
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+// DefaultSensitiveTag is the tag of sensitive rules that do not set one.
+const DefaultSensitiveTag = "sensitive"
+
+// SensitiveRule tags Fields of collections matching Database and Collection,
+// glob patterns in path.Match syntax, as sensitive data of class Tag (pii,
+// pci, ...). An empty pattern matches anything; no Fields tags every field
+// of the collection.
+type SensitiveRule struct {
+	Database   string
+	Collection string
+	Fields     []string
+	Tag        string
+}
+
+// Classification is the configured set of sensitive data tags.
+type Classification []SensitiveRule
+
+// NewClassification validates rules and returns them as a Classification.
+func NewClassification(rules []SensitiveRule) (Classification, error) {
+	c := make(Classification, 0, len(rules))
+	for i, r := range rules {
+		for _, p := range []string{r.Database, r.Collection} {
+			if _, err := path.Match(p, ""); err != nil {
+				return nil, fmt.Errorf("rule %d: invalid pattern %q: %w", i+1, p, err)
+			}
+		}
+		if r.Database == "" && r.Collection == "" && len(r.Fields) == 0 {
+			return nil, fmt.Errorf("rule %d: set a database, collection or fields", i+1)
+		}
+		for _, f := range r.Fields {
+			if strings.TrimSpace(f) == "" {
+				return nil, fmt.Errorf("rule %d: empty field name", i+1)
+			}
+		}
+		r.Tag = strings.TrimSpace(r.Tag)
+		if r.Tag == "" {
+			r.Tag = DefaultSensitiveTag
+		}
+		c = append(c, r)
+	}
+	return c, nil
+}
+
+// matches reports whether r covers a collection. An empty database, as on
+// scanner references, matches any database pattern.
+func (r SensitiveRule) matches(database, collection string) bool {
+	return (database == "" || globMatch(r.Database, database)) && globMatch(r.Collection, collection)
+}
+
+// DetectUnencryptedSensitiveFields reports tagged fields of existing
+// collections that neither Queryable Encryption (the collection's
+// encryptedFields) nor CSFLE (an encrypt rule in the $jsonSchema validator,
+// or a schemaMap in the scanned code) covers. Rules without fields tag the
+// whole collection and are not checked: encryption is per field. scan may
+// be nil.
+func DetectUnencryptedSensitiveFields(c Classification, collections []mongoinspect.CollectionInfo, scan *scanner.ScanResult) []Finding {
+	var findings []Finding
+	for _, coll := range collections {
+		if coll.Type == "view" {
+			continue
+		}
+		encrypted := make(map[string]bool)
+		for _, f := range coll.EncryptedFields {
+			encrypted[f] = true
+		}
+		for _, f := range validatorEncryptedFields(coll.Validator) {
+			encrypted[f] = true
+		}
+		if scan != nil {
+			for _, ref := range scan.EncryptedFieldRefs {
+				if strings.EqualFold(ref.Collection, coll.Name) && (ref.Database == "" || strings.EqualFold(ref.Database, coll.Database)) {
+					for _, f := range ref.Fields {
+						encrypted[f] = true
+					}
+				}
+			}
+		}
+
+		unencrypted := make(map[string][]string) // tag → fields
+		for _, r := range c {
+			if !r.matches(coll.Database, coll.Name) {
+				continue
+			}
+			for _, f := range r.Fields {
+				if !encrypted[f] {
+					unencrypted[r.Tag] = append(unencrypted[r.Tag], f)
+				}
+			}
+		}
+		tags := make([]string, 0, len(unencrypted))
+		for tag := range unencrypted {
+			tags = append(tags, tag)
+		}
+		sort.Strings(tags)
+		for _, tag := range tags {
+			fields := unencrypted[tag]
+			sort.Strings(fields)
+			findings = append(findings, Finding{
+				Type:       FindingSensitiveUnencrypted,
+				Severity:   SeverityMedium,
+				Database:   coll.Database,
+				Collection: coll.Name,
+				Message: fmt.Sprintf("%d field(s) tagged %s are not covered by Queryable Encryption or CSFLE: %s",
+					len(fields), tag, strings.Join(quoteAll(fields), ", ")),
+			})
+		}
+	}
+	return findings
+}
+
+// Redactor masks the names and values of sensitive fields in findings,
+// collection metadata and scan results. Build one with
+// Classification.Redactor; a nil Redactor changes nothing.
+type Redactor struct {
+	rules Classification
+	// known lists the field names seen per lowercased collection name,
+	// used for rules that tag whole collections.
+	known map[string][]string
+	// patterns match each sensitive field name and the paths below it.
+	patterns map[string]*regexp.Regexp
+	// indexPatterns match each field as a part of a default index name
+	// such as ssn_1_createdAt_-1.
+	indexPatterns map[string]*regexp.Regexp
+}
+
+// redactedValue replaces values that appear next to sensitive fields.
+const redactedValue = "[redacted]"
+
+// Redactor returns a Redactor for c, or nil when c has no rules. Fields of
+// wholly tagged collections are taken from collections (index keys,
+// validator properties, encrypted fields) and scan (field references),
+// which may be nil; _id is never redacted.
+func (c Classification) Redactor(collections []mongoinspect.CollectionInfo, scan *scanner.ScanResult) *Redactor {
+	if len(c) == 0 {
+		return nil
+	}
+	known := make(map[string]map[string]bool)
+	add := func(coll string, fields ...string) {
+		key := strings.ToLower(coll)
+		if known[key] == nil {
+			known[key] = make(map[string]bool)
+		}
+		for _, f := range fields {
+			if f != "" && f != "_id" {
+				known[key][f] = true
+			}
+		}
+	}
+	for _, coll := range collections {
+		for _, idx := range coll.Indexes {
+			for _, k := range idx.Key {
+				add(coll.Name, k.Field)
+			}
+			add(coll.Name, idx.TextFields...)
+		}
+		if coll.Validator != nil {
+			for name := range coll.Validator.Schema.Properties {
+				add(coll.Name, name)
+			}
+		}
+		add(coll.Name, coll.EncryptedFields...)
+	}
+	if scan != nil {
+		for _, ref := range scan.FieldRefs {
+			add(ref.Collection, ref.Field)
+		}
+		for _, ref := range scan.WriteRefs {
+			add(ref.Collection, ref.Field)
+		}
+	}
+
+	r := &Redactor{
+		rules:         c,
+		known:         make(map[string][]string, len(known)),
+		patterns:      make(map[string]*regexp.Regexp),
+		indexPatterns: make(map[string]*regexp.Regexp),
+	}
+	compile := func(f string) {
+		if r.patterns[f] == nil {
+			r.patterns[f] = regexp.MustCompile(`\b` + regexp.QuoteMeta(f) + `\b(?:(?:\.|\[\])[\w$]+|\[\])*`)
+			r.indexPatterns[f] = regexp.MustCompile(`(^|_)` + regexp.QuoteMeta(f) + `(?:\.[\w$]+)*(_|$)`)
+		}
+	}
+	for coll, fields := range known {
+		for f := range fields {
+			r.known[coll] = append(r.known[coll], f)
+			compile(f)
+		}
+	}
+	for _, rule := range c {
+		for _, f := range rule.Fields {
+			compile(f)
+		}
+	}
+	return r
+}
+
+// fields returns the sensitive fields of a collection with their tags, and
+// the tag of a rule covering the whole collection ("" if none).
+func (r *Redactor) fields(database, collection string) (fields map[string]string, whole string) {
+	fields = make(map[string]string)
+	for _, rule := range r.rules {
+		if !rule.matches(database, collection) {
+			continue
+		}
+		if len(rule.Fields) == 0 {
+			if whole == "" {
+				whole = rule.Tag
+			}
+			for _, f := range r.known[strings.ToLower(collection)] {
+				if _, ok := fields[f]; !ok {
+					fields[f] = rule.Tag
+				}
+			}
+			continue
+		}
+		for _, f := range rule.Fields {
+			fields[f] = rule.Tag
+		}
+	}
+	return fields, whole
+}
+
+// quotedLiteral matches a double-quoted string, with escapes.
+var quotedLiteral = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+
+// identifierSpans matches the parts of a message that hold names and
+// values: quoted strings, key documents and lists in braces, brackets or
+// parentheses, the list after "fields:", and a key after "{" or ",". The
+// prose around them is left alone, so a field named "name" does not
+// rewrite "collection name".
+var identifierSpans = regexp.MustCompile(`"(?:[^"\\]|\\.)*"|\{[^{}]*\}|\([^()]*\)|\[[^\[\]]*\]|\bfields?: [\w$.\[\]]+(?:, [\w$.\[\]]+)*|[{,]\s*[\w$.]+\s*:`)
+
+// longestFirst returns the field names in fields, longest first so that
+// "contact.email" is replaced before "contact".
+func longestFirst(fields map[string]string) []string {
+	names := make([]string, 0, len(fields))
+	for f := range fields {
+		names = append(names, f)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) > len(names[j])
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// redactFields replaces each of fields in s, and paths below it, with a
+// [redacted:tag] marker. It reports whether anything was replaced.
+func (r *Redactor) redactFields(s string, fields map[string]string) (string, bool) {
+	changed := false
+	for _, name := range longestFirst(fields) {
+		out := r.patterns[name].ReplaceAllLiteralString(s, "[redacted:"+fields[name]+"]")
+		if out != s {
+			changed = true
+			s = out
+		}
+	}
+	return s, changed
+}
+
+// redactIndexName replaces each of fields in an index name. Default names
+// join field and direction with underscores, so word boundaries don't apply.
+func (r *Redactor) redactIndexName(s string, fields map[string]string) (string, bool) {
+	changed := false
+	for _, name := range longestFirst(fields) {
+		out := r.indexPatterns[name].ReplaceAllString(s, "${1}[redacted:"+fields[name]+"]${2}")
+		if out != s {
+			changed = true
+			s = out
+		}
+	}
+	return s, changed
+}
+
+// maskLiterals replaces the quoted strings in s with "[redacted]", except
+// redaction markers and the names in keep.
+func maskLiterals(s string, keep ...string) string {
+	return quotedLiteral.ReplaceAllStringFunc(s, func(q string) string {
+		inner := q[1 : len(q)-1]
+		if strings.HasPrefix(inner, "[redacted") {
+			return q
+		}
+		for _, k := range keep {
+			if inner == k {
+				return q
+			}
+		}
+		return `"` + redactedValue + `"`
+	})
+}
+
+// redactText masks sensitive field names in the identifier spans of s,
+// including quoted index names, and, when s mentions one or the whole
+// collection is tagged, every other quoted value in it.
+func (r *Redactor) redactText(s, database, collection string) string {
+	fields, whole := r.fields(database, collection)
+	changed := false
+	out := identifierSpans.ReplaceAllStringFunc(s, func(span string) string {
+		out, ok := r.redactFields(span, fields)
+		if strings.HasPrefix(out, `"`) {
+			if name, named := r.redactIndexName(out[1:len(out)-1], fields); named {
+				out, ok = `"`+name+`"`, true
+			}
+		}
+		changed = changed || ok
+		return out
+	})
+	if changed || whole != "" {
+		out = maskLiterals(out, database, collection)
+	}
+	return out
+}
+
+// IsRedacted reports whether the namespace or index name of f was masked.
+// Ignore rules are matched before redaction, so a rule built from such a
+// finding never matches.
+func IsRedacted(f *Finding) bool {
+	return strings.Contains(f.Index, "[redacted") || strings.Contains(f.Collection, "[redacted")
+}
+
+// Finding redacts a finding's message and index name.
+func (r *Redactor) Finding(f *Finding) {
+	if r == nil {
+		return
+	}
+	f.Message = r.redactText(f.Message, f.Database, f.Collection)
+	if f.Index != "" {
+		fields, whole := r.fields(f.Database, f.Collection)
+		if name, changed := r.redactIndexName(f.Index, fields); changed {
+			f.Index = name
+		} else if whole != "" {
+			f.Index = "[redacted:" + whole + "]"
+		}
+	}
+}
+
+// Collections returns a copy of collections with sensitive field names and
+// values masked in index definitions, validators and encrypted fields.
+func (r *Redactor) Collections(collections []mongoinspect.CollectionInfo) []mongoinspect.CollectionInfo {
+	if r == nil || collections == nil {
+		return collections
+	}
+	out := make([]mongoinspect.CollectionInfo, len(collections))
+	for i, c := range collections {
+		fields, whole := r.fields(c.Database, c.Name)
+		if len(fields) == 0 && whole == "" {
+			out[i] = c
+			continue
+		}
+		name := func(s string) string {
+			s, _ = r.redactFields(s, fields)
+			return s
+		}
+		text := func(s string) string {
+			return r.redactText(s, c.Database, c.Name)
+		}
+
+		c.Indexes = append([]mongoinspect.IndexInfo(nil), c.Indexes...)
+		for j := range c.Indexes {
+			idx := &c.Indexes[j]
+			if n, changed := r.redactIndexName(idx.Name, fields); changed {
+				idx.Name = n
+			} else if whole != "" && idx.Name != "_id_" {
+				idx.Name = "[redacted:" + whole + "]"
+			}
+			idx.Key = append([]mongoinspect.KeyField(nil), idx.Key...)
+			for k := range idx.Key {
+				idx.Key[k].Field = name(idx.Key[k].Field)
+			}
+			idx.TextFields = mapStrings(idx.TextFields, name)
+			idx.PartialFilter = text(idx.PartialFilter)
+			idx.WildcardProjection = text(idx.WildcardProjection)
+		}
+		if c.Validator != nil {
+			v := *c.Validator
+			v.Schema.Required = mapStrings(v.Schema.Required, name)
+			if v.Schema.Properties != nil {
+				props := make(map[string]mongoinspect.ValidatorField, len(v.Schema.Properties))
+				for k, p := range v.Schema.Properties {
+					props[name(k)] = p
+				}
+				v.Schema.Properties = props
+			}
+			c.Validator = &v
+		}
+		c.EncryptedFields = mapStrings(c.EncryptedFields, name)
+		if c.EncryptedQueries != nil {
+			queries := make(map[string][]string, len(c.EncryptedQueries))
+			for k, q := range c.EncryptedQueries {
+				queries[name(k)] = q
+			}
+			c.EncryptedQueries = queries
+		}
+		out[i] = c
+	}
+	return out
+}
+
+// Scan returns a copy of scan with sensitive field names, and the literals
+// compared to them, masked.
+func (r *Redactor) Scan(scan scanner.ScanResult) scanner.ScanResult {
+	if r == nil {
+		return scan
+	}
+	name := func(coll string) func(string) string {
+		fields, _ := r.fields("", coll)
+		return func(s string) string {
+			s, _ = r.redactFields(s, fields)
+			return s
+		}
+	}
+
+	scan.FieldRefs = append([]scanner.FieldRef(nil), scan.FieldRefs...)
+	for i := range scan.FieldRefs {
+		ref := &scan.FieldRefs[i]
+		if masked := name(ref.Collection)(ref.Field); masked != ref.Field {
+			ref.Field = masked
+			if ref.Constant != "" {
+				ref.Constant = redactedValue
+			}
+		}
+	}
+	scan.WriteRefs = append([]scanner.WriteRef(nil), scan.WriteRefs...)
+	for i := range scan.WriteRefs {
+		scan.WriteRefs[i].Field = name(scan.WriteRefs[i].Collection)(scan.WriteRefs[i].Field)
+	}
+	scan.IndexRefs = append([]scanner.IndexRef(nil), scan.IndexRefs...)
+	for i := range scan.IndexRefs {
+		fn := name(scan.IndexRefs[i].Collection)
+		scan.IndexRefs[i].Fields = mapStrings(scan.IndexRefs[i].Fields, fn)
+		scan.IndexRefs[i].TextFields = mapStrings(scan.IndexRefs[i].TextFields, fn)
+	}
+	scan.UpsertRefs = append([]scanner.UpsertRef(nil), scan.UpsertRefs...)
+	for i := range scan.UpsertRefs {
+		scan.UpsertRefs[i].Fields = mapStrings(scan.UpsertRefs[i].Fields, name(scan.UpsertRefs[i].Collection))
+	}
+	scan.EncryptedFieldRefs = append([]scanner.EncryptedFieldRef(nil), scan.EncryptedFieldRefs...)
+	for i := range scan.EncryptedFieldRefs {
+		ref := &scan.EncryptedFieldRefs[i]
+		fn := name(ref.Collection)
+		ref.Fields = mapStrings(ref.Fields, fn)
+		if ref.Queries != nil {
+			queries := make(map[string][]string, len(ref.Queries))
+			for k, q := range ref.Queries {
+				queries[fn(k)] = q
+			}
+			ref.Queries = queries
+		}
+	}
+	scan.SearchRefs = append([]scanner.SearchRef(nil), scan.SearchRefs...)
+	for i := range scan.SearchRefs {
+		fn := name(scan.SearchRefs[i].Collection)
+		scan.SearchRefs[i].Paths = mapStrings(scan.SearchRefs[i].Paths, fn)
+		scan.SearchRefs[i].FilterFields = mapStrings(scan.SearchRefs[i].FilterFields, fn)
+	}
+	return scan
+}
+
+// mapStrings returns a copy of s with fn applied to each element.
+func mapStrings(s []string, fn func(string) string) []string {
+	if s == nil {
+		return nil
+	}
+	out := make([]string, len(s))
+	for i, v := range s {
+		out[i] = fn(v)
+	}
+	return out
+}
@@ -0,0 +1,139 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+func TestNewClassification(t *testing.T) {
+	c, err := NewClassification([]SensitiveRule{{Collection: "users", Fields: []string{"ssn"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c[0].Tag != DefaultSensitiveTag {
+		t.Errorf("tag = %q, want default", c[0].Tag)
+	}
+	for _, bad := range []SensitiveRule{
+		{Tag: "pii"},
+		{Collection: "[", Tag: "pii"},
+		{Collection: "users", Fields: []string{" "}},
+	} {
+		if _, err := NewClassification([]SensitiveRule{bad}); err == nil {
+			t.Errorf("%+v: expected an error", bad)
+		}
+	}
+}
+
+func TestDetectUnencryptedSensitiveFields(t *testing.T) {
+	c, _ := NewClassification([]SensitiveRule{
+		{Database: "app", Collection: "users", Fields: []string{"ssn", "dob", "email", "phone"}, Tag: "pii"},
+		{Collection: "payments", Tag: "pci"}, // whole collection: not checked
+	})
+	collections := []mongoinspect.CollectionInfo{
+		{
+			Database: "app", Name: "users",
+			EncryptedFields: []string{"ssn"},
+			Validator: &mongoinspect.ValidatorInfo{Schema: mongoinspect.ValidatorSchema{
+				Properties: map[string]mongoinspect.ValidatorField{"dob": {Encrypted: true}},
+			}},
+		},
+		{Database: "app", Name: "payments"},
+	}
+	scan := &scanner.ScanResult{EncryptedFieldRefs: []scanner.EncryptedFieldRef{
+		{Collection: "users", Fields: []string{"email"}, Kind: scanner.EncryptionCSFLE},
+	}}
+
+	findings := DetectUnencryptedSensitiveFields(c, collections, scan)
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1: %+v", len(findings), findings)
+	}
+	if f := findings[0]; f.Type != FindingSensitiveUnencrypted || f.Collection != "users" ||
+		!strings.Contains(f.Message, `1 field(s) tagged pii`) || !strings.Contains(f.Message, `"phone"`) {
+		t.Errorf("finding = %+v", f)
+	}
+
+	// Without the code's schemaMap, email is unencrypted too.
+	if findings := DetectUnencryptedSensitiveFields(c, collections, nil); !strings.Contains(findings[0].Message, `"email", "phone"`) {
+		t.Errorf("without scan: %+v", findings)
+	}
+}
+
+func TestRedactor(t *testing.T) {
+	c, _ := NewClassification([]SensitiveRule{
+		{Database: "app", Collection: "users", Fields: []string{"ssn", "contact.email"}, Tag: "pii"},
+		{Collection: "cards", Tag: "pci"},
+	})
+	collections := []mongoinspect.CollectionInfo{
+		{Database: "app", Name: "users", Indexes: []mongoinspect.IndexInfo{
+			{Name: "_id_", Key: []mongoinspect.KeyField{{Field: "_id", Direction: 1}}},
+			{Name: "ssn_1", Key: []mongoinspect.KeyField{{Field: "ssn", Direction: 1}}, PartialFilter: `{"ssn": {"$eq": "078-05-1120"}}`},
+			{Name: "status_1", Key: []mongoinspect.KeyField{{Field: "status", Direction: 1}}},
+		}},
+		{Database: "app", Name: "cards", Indexes: []mongoinspect.IndexInfo{
+			{Name: "pan_1", Key: []mongoinspect.KeyField{{Field: "pan", Direction: 1}}},
+		}},
+	}
+	r := c.Redactor(collections, nil)
+
+	f := Finding{Database: "app", Collection: "users", Index: "ssn_1",
+		Message: `partial index on "ssn" with partialFilterExpression {ssn: "078-05-1120"} for collection "users"`}
+	r.Finding(&f)
+	if strings.Contains(f.Message, "ssn") || strings.Contains(f.Message, "078-05") || !strings.Contains(f.Message, `"users"`) {
+		t.Errorf("message = %q", f.Message)
+	}
+	if f.Index != "[redacted:pii]_1" {
+		t.Errorf("index = %q", f.Index)
+	}
+
+	nested := Finding{Database: "app", Collection: "users", Message: `field "contact.email.domain" is unindexed`}
+	r.Finding(&nested)
+	if nested.Message != `field "[redacted:pii]" is unindexed` {
+		t.Errorf("nested = %q", nested.Message)
+	}
+	prose := Finding{Database: "app", Collection: "users", Message: `ssn lookups scan the collection; add an index on {ssn: 1, "contact.email": 1}`}
+	r.Finding(&prose)
+	if prose.Message != `ssn lookups scan the collection; add an index on {[redacted:pii]: 1, "[redacted:pii]": 1}` {
+		t.Errorf("prose = %q", prose.Message)
+	}
+	other := Finding{Database: "app", Collection: "users", Message: `field "status" is unindexed`}
+	r.Finding(&other)
+	if other.Message != `field "status" is unindexed` {
+		t.Errorf("untagged field redacted: %q", other.Message)
+	}
+	whole := Finding{Database: "app", Collection: "cards", Index: "pan_1", Message: `index "pan_1" on {pan: 1} is unused`}
+	r.Finding(&whole)
+	if strings.Contains(whole.Message, "pan") || whole.Index != "[redacted:pci]_1" {
+		t.Errorf("whole collection = %+v", whole)
+	}
+
+	out := r.Collections(collections)
+	if idx := out[0].Indexes[1]; idx.Key[0].Field != "[redacted:pii]" || strings.Contains(idx.PartialFilter, "078") {
+		t.Errorf("redacted index = %+v", idx)
+	}
+	if out[0].Indexes[0].Name != "_id_" || out[0].Indexes[2].Name != "status_1" {
+		t.Errorf("untagged indexes changed: %+v", out[0].Indexes)
+	}
+	if collections[0].Indexes[1].Key[0].Field != "ssn" {
+		t.Error("Collections modified its input")
+	}
+
+	scan := r.Scan(scanner.ScanResult{FieldRefs: []scanner.FieldRef{
+		{Collection: "users", Field: "ssn", Constant: "078-05-1120"},
+		{Collection: "users", Field: "status", Constant: "active"},
+	}})
+	if ref := scan.FieldRefs[0]; ref.Field != "[redacted:pii]" || ref.Constant != "[redacted]" {
+		t.Errorf("scan ref = %+v", ref)
+	}
+	if ref := scan.FieldRefs[1]; ref.Field != "status" || ref.Constant != "active" {
+		t.Errorf("untagged scan ref = %+v", ref)
+	}
+
+	var none *Redactor
+	none.Finding(&f) // no-op
+	if Classification(nil).Redactor(collections, nil) != nil {
+		t.Error("empty classification returned a redactor")
+	}
+}
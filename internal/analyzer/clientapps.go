@@ -0,0 +1,138 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+const clientAppTopN = 5 // namespaces listed per application
+
+// toolAppNames are the appName prefixes of MongoDB's own tools and agents,
+// compared case-insensitively. Their traffic is not attributed.
+var toolAppNames = []string{
+	"mongosh", "mongodb shell", "mongodb compass", "mongodump", "mongorestore", "mongoexport",
+	"mongoimport", "mongostat", "mongotop", "mongofiles", "mongomirror", "mongosync", "mongot",
+	"mongodb automation agent", "mongodb monitoring module", "mongodb backup agent", "mongodb cps module",
+	"mongodb atlas", "mongospectre",
+}
+
+// writeOps are the $currentOp and profiler op values that modify data.
+var writeOps = map[string]bool{"insert": true, "update": true, "remove": true}
+
+// AttributeClientApps groups current operations and profiler entries by
+// the appName clients connected with, and reports which collections each
+// application accessed. Applications whose appName the scanned repo sets
+// get a CLIENT_APP_TRAFFIC summary; the others are UNKNOWN_CLIENT_APP,
+// medium when they write: a service or script outside the repo is using
+// the database. Clients without an appName and MongoDB's own tools are
+// left out.
+func AttributeClientApps(scan *scanner.ScanResult, ops []mongoinspect.ClientOp, profile []mongoinspect.ProfileEntry) []Finding {
+	known := make(map[string]scanner.AppNameRef)
+	for _, ref := range scan.AppNameRefs {
+		if _, ok := known[strings.ToLower(ref.Name)]; !ok {
+			known[strings.ToLower(ref.Name)] = ref
+		}
+	}
+
+	type appTraffic struct {
+		name        string
+		connections int
+		writes      bool
+		namespaces  map[string]int
+	}
+	apps := make(map[string]*appTraffic)
+	record := func(name, database, collection, op string, connection bool) {
+		if name == "" || isToolAppName(name) {
+			return
+		}
+		a := apps[name]
+		if a == nil {
+			a = &appTraffic{name: name, namespaces: make(map[string]int)}
+			apps[name] = a
+		}
+		if connection {
+			a.connections++
+		}
+		if collection != "" {
+			a.namespaces[database+"."+collection]++
+			a.writes = a.writes || writeOps[op]
+		}
+	}
+	for _, op := range ops {
+		record(op.AppName, op.Database, op.Collection, op.Op, true)
+	}
+	for _, e := range profile {
+		record(e.AppName, e.Database, e.Collection, e.Op, false)
+	}
+
+	names := make([]string, 0, len(apps))
+	for name := range apps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var findings []Finding
+	for _, name := range names {
+		a := apps[name]
+		type nsCount struct {
+			ns string
+			n  int
+		}
+		ranked := make([]nsCount, 0, len(a.namespaces))
+		for ns, n := range a.namespaces {
+			ranked = append(ranked, nsCount{ns, n})
+		}
+		sort.Slice(ranked, func(i, j int) bool {
+			if ranked[i].n != ranked[j].n {
+				return ranked[i].n > ranked[j].n
+			}
+			return ranked[i].ns < ranked[j].ns
+		})
+		accessed := "no collections in the sampled operations"
+		if len(ranked) > 0 {
+			parts := make([]string, 0, clientAppTopN)
+			for i, r := range ranked {
+				if i == clientAppTopN {
+					parts = append(parts, fmt.Sprintf("%d more", len(ranked)-clientAppTopN))
+					break
+				}
+				parts = append(parts, fmt.Sprintf("%s (%d ops)", r.ns, r.n))
+			}
+			accessed = strings.Join(parts, ", ")
+		}
+		f := Finding{Severity: SeverityInfo}
+		if len(ranked) == 1 {
+			f.Database, f.Collection, _ = strings.Cut(ranked[0].ns, ".")
+		}
+		if ref, ok := known[strings.ToLower(name)]; ok {
+			f.Type = FindingClientAppTraffic
+			f.Message = fmt.Sprintf("client app %q (appName set at %s:%d), %d open connections, accessed %s", name, ref.File, ref.Line, a.connections, accessed)
+		} else {
+			f.Type = FindingUnknownClientApp
+			f.Severity = SeverityLow
+			writes := ""
+			if a.writes {
+				f.Severity = SeverityMedium
+				writes = ", including writes"
+			}
+			f.Message = fmt.Sprintf("client app %q is not named in the scanned repo; %d open connections, accessed %s%s — find the service it belongs to",
+				name, a.connections, accessed, writes)
+		}
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+func isToolAppName(name string) bool {
+	lower := strings.ToLower(name)
+	for _, prefix := range toolAppNames {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
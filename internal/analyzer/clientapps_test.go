@@ -0,0 +1,42 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+func TestAttributeClientApps(t *testing.T) {
+	scan := &scanner.ScanResult{AppNameRefs: []scanner.AppNameRef{{Name: "checkout-api", File: "src/db.js", Line: 4}}}
+	ops := []mongoinspect.ClientOp{
+		{AppName: "checkout-api", Database: "shop", Collection: "orders", Op: "query"},
+		{AppName: "checkout-api", Op: "none"},
+		{AppName: "legacy-sync", Database: "shop", Collection: "inventory", Op: "update"},
+		{AppName: "report-cron", Op: "none"},
+		{AppName: "mongosh 2.3.1", Database: "shop", Collection: "orders", Op: "remove"},
+		{Database: "shop", Collection: "orders", Op: "query"},
+	}
+	profile := []mongoinspect.ProfileEntry{
+		{AppName: "checkout-api", Database: "shop", Collection: "carts", Op: "query"},
+		{AppName: "checkout-api", Database: "shop", Collection: "orders", Op: "insert"},
+	}
+
+	findings := AttributeClientApps(scan, ops, profile)
+	if len(findings) != 3 {
+		t.Fatalf("got %d findings, want 3: %+v", len(findings), findings)
+	}
+	if f := findings[0]; f.Type != FindingClientAppTraffic || f.Severity != SeverityInfo ||
+		!strings.Contains(f.Message, `"checkout-api" (appName set at src/db.js:4), 2 open connections, accessed shop.orders (2 ops), shop.carts (1 ops)`) {
+		t.Errorf("checkout-api finding = %+v", f)
+	}
+	if f := findings[1]; f.Type != FindingUnknownClientApp || f.Severity != SeverityMedium ||
+		f.Collection != "inventory" || !strings.Contains(f.Message, "including writes") {
+		t.Errorf("legacy-sync finding = %+v", f)
+	}
+	if f := findings[2]; f.Type != FindingUnknownClientApp || f.Severity != SeverityLow ||
+		!strings.Contains(f.Message, "accessed no collections in the sampled operations") {
+		t.Errorf("report-cron finding = %+v", f)
+	}
+}
@@ -1,7 +1,9 @@
 package analyzer
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 
 	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
@@ -14,6 +16,7 @@ const (
 	CompareMissingInTarget CompareType = "MISSING_IN_TARGET"
 	CompareMissingInSource CompareType = "MISSING_IN_SOURCE"
 	CompareIndexDrift      CompareType = "INDEX_DRIFT"
+	CompareIndexOptions    CompareType = "INDEX_OPTIONS_DRIFT"
 )
 
 // CompareFinding represents a difference between two clusters.
@@ -26,6 +29,8 @@ type CompareFinding struct {
 	Message      string      `json:"message"`
 	SourceDetail string      `json:"sourceDetail,omitempty"`
 	TargetDetail string      `json:"targetDetail,omitempty"`
+	// Target names the target environment when comparing against several.
+	Target string `json:"target,omitempty"`
 }
 
 // Compare detects drift between source and target cluster collections.
@@ -111,6 +116,10 @@ func compareIndexes(source, target *mongoinspect.CollectionInfo) []CompareFindin
 				SourceDetail: formatKeyFields(si.Key),
 				TargetDetail: formatKeyFields(ti.Key),
 			})
+			continue
+		}
+		if f, ok := compareIndexOptions(source, &si, &ti); ok {
+			findings = append(findings, f)
 		}
 	}
 
@@ -135,6 +144,76 @@ func compareIndexes(source, target *mongoinspect.CollectionInfo) []CompareFindin
 	return findings
 }
 
+// indexOption is one option compared between source and target indexes.
+type indexOption struct {
+	name           string
+	source, target string
+}
+
+// indexOptions lists the options of two same-named indexes that differ.
+// Unset options render as "none".
+func indexOptions(si, ti *mongoinspect.IndexInfo) []indexOption {
+	str := func(v string) string {
+		if v == "" {
+			return "none"
+		}
+		return v
+	}
+	ttl := func(v *int32) string {
+		if v == nil {
+			return "none"
+		}
+		return fmt.Sprintf("%d", *v)
+	}
+	all := []indexOption{
+		{"unique", fmt.Sprintf("%t", si.Unique), fmt.Sprintf("%t", ti.Unique)},
+		{"sparse", fmt.Sprintf("%t", si.Sparse), fmt.Sprintf("%t", ti.Sparse)},
+		{"partialFilterExpression", str(si.PartialFilter), str(ti.PartialFilter)},
+		{"expireAfterSeconds", ttl(si.TTL), ttl(ti.TTL)},
+		{"collation", str(si.Collation), str(ti.Collation)},
+	}
+	var diff []indexOption
+	for _, o := range all {
+		if o.source != o.target {
+			diff = append(diff, o)
+		}
+	}
+	return diff
+}
+
+// compareIndexOptions returns an INDEX_OPTIONS_DRIFT finding when indexes
+// with the same name and key pattern have different options. A uniqueness
+// mismatch is high severity: the side without it accepts duplicates the
+// other rejects.
+func compareIndexOptions(coll *mongoinspect.CollectionInfo, si, ti *mongoinspect.IndexInfo) (CompareFinding, bool) {
+	diff := indexOptions(si, ti)
+	if len(diff) == 0 {
+		return CompareFinding{}, false
+	}
+	sev := SeverityMedium
+	parts := make([]string, len(diff))
+	src := make([]string, len(diff))
+	tgt := make([]string, len(diff))
+	for i, o := range diff {
+		if o.name == "unique" {
+			sev = SeverityHigh
+		}
+		parts[i] = fmt.Sprintf("%s source=%s target=%s", o.name, o.source, o.target)
+		src[i] = o.name + "=" + o.source
+		tgt[i] = o.name + "=" + o.target
+	}
+	return CompareFinding{
+		Type:         CompareIndexOptions,
+		Severity:     sev,
+		Database:     coll.Database,
+		Collection:   coll.Name,
+		Index:        si.Name,
+		Message:      fmt.Sprintf("index %q options differ: %s", si.Name, strings.Join(parts, "; ")),
+		SourceDetail: strings.Join(src, ", "),
+		TargetDetail: strings.Join(tgt, ", "),
+	}, true
+}
+
 func indexByName(colls []mongoinspect.CollectionInfo) map[string]*mongoinspect.CollectionInfo {
 	m := make(map[string]*mongoinspect.CollectionInfo)
 	for i := range colls {
@@ -151,10 +230,80 @@ func indexSetByName(indexes []mongoinspect.IndexInfo) map[string]mongoinspect.In
 	return m
 }
 
+// formatKeyField renders one key element as field:1, field:-1 or field:"hashed".
+func formatKeyField(kf mongoinspect.KeyField) string {
+	if kf.Kind != "" {
+		return fmt.Sprintf("%s:%q", kf.Field, kf.Kind)
+	}
+	return fmt.Sprintf("%s:%d", kf.Field, kf.Direction)
+}
+
 func formatKeyFields(keys []mongoinspect.KeyField) string {
 	parts := make([]string, len(keys))
 	for i, kf := range keys {
-		parts[i] = fmt.Sprintf("%s:%d", kf.Field, kf.Direction)
+		parts[i] = formatKeyField(kf)
 	}
 	return "{" + strings.Join(parts, ", ") + "}"
 }
+
+// CompareBaselineFinding wraps a CompareFinding with a diff status against a
+// previous compare run.
+type CompareBaselineFinding struct {
+	CompareFinding
+	Status BaselineStatus `json:"status"`
+}
+
+// LoadCompareBaseline reads the JSON output of a previous compare run: a
+// findings array, or the matrix report with a findings key.
+func LoadCompareBaseline(path string) ([]CompareFinding, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var findings []CompareFinding
+	if err := json.Unmarshal(data, &findings); err == nil {
+		return findings, nil
+	}
+	var report struct {
+		Findings []CompareFinding `json:"findings"`
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	return report.Findings, nil
+}
+
+// DiffCompareBaseline tags current compare findings as new or unchanged
+// against a previous run and appends the baseline findings that are gone as
+// resolved.
+func DiffCompareBaseline(current, baseline []CompareFinding) []CompareBaselineFinding {
+	baselineSet := make(map[string]bool)
+	for i := range baseline {
+		baselineSet[compareFindingKey(&baseline[i])] = true
+	}
+	currentSet := make(map[string]bool)
+	for i := range current {
+		currentSet[compareFindingKey(&current[i])] = true
+	}
+
+	var result []CompareBaselineFinding
+	for i := range current {
+		status := StatusNew
+		if baselineSet[compareFindingKey(&current[i])] {
+			status = StatusUnchanged
+		}
+		result = append(result, CompareBaselineFinding{CompareFinding: current[i], Status: status})
+	}
+	for i := range baseline {
+		if !currentSet[compareFindingKey(&baseline[i])] {
+			result = append(result, CompareBaselineFinding{CompareFinding: baseline[i], Status: StatusResolved})
+		}
+	}
+	return result
+}
+
+// compareFindingKey identifies a compare finding by type, target and
+// location. Collection names are matched case-insensitively, as in Compare.
+func compareFindingKey(f *CompareFinding) string {
+	return strings.Join([]string{string(f.Type), f.Target, f.Database, strings.ToLower(f.Collection), f.Index}, "|")
+}
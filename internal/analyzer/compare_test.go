@@ -1,6 +1,9 @@
 package analyzer
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
@@ -115,6 +118,50 @@ func TestCompare_IndexDrift_DifferentKeys(t *testing.T) {
 	}
 }
 
+func TestCompare_IndexOptionsDrift(t *testing.T) {
+	ttl := int32(3600)
+	srcIdx := mongoinspect.IndexInfo{Name: "expires_1", Key: kf("expires"), TTL: &ttl, PartialFilter: `{"active":true}`}
+	tgtIdx := mongoinspect.IndexInfo{Name: "expires_1", Key: kf("expires"), Sparse: true, Collation: `{"locale":"en"}`}
+	source := []mongoinspect.CollectionInfo{collInfo("sessions", "app", 100, srcIdx)}
+	target := []mongoinspect.CollectionInfo{collInfo("sessions", "app", 100, tgtIdx)}
+
+	findings := Compare(source, target)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d: %v", len(findings), findings)
+	}
+	f := findings[0]
+	if f.Type != CompareIndexOptions || f.Severity != SeverityMedium || f.Index != "expires_1" {
+		t.Fatalf("unexpected finding: %+v", f)
+	}
+	for _, want := range []string{
+		"sparse source=false target=true",
+		`partialFilterExpression source={"active":true} target=none`,
+		"expireAfterSeconds source=3600 target=none",
+		`collation source=none target={"locale":"en"}`,
+	} {
+		if !strings.Contains(f.Message, want) {
+			t.Errorf("message %q missing %q", f.Message, want)
+		}
+	}
+	if strings.Contains(f.Message, "unique") {
+		t.Errorf("unique did not change: %q", f.Message)
+	}
+	if f.SourceDetail != `sparse=false, partialFilterExpression={"active":true}, expireAfterSeconds=3600, collation=none` {
+		t.Errorf("sourceDetail = %q", f.SourceDetail)
+	}
+}
+
+func TestCompare_IndexOptionsDrift_UniqueIsHigh(t *testing.T) {
+	srcIdx := mongoinspect.IndexInfo{Name: "email_1", Key: kf("email"), Unique: true}
+	source := []mongoinspect.CollectionInfo{collInfo("users", "app", 100, srcIdx)}
+	target := []mongoinspect.CollectionInfo{collInfo("users", "app", 100, idx("email_1", kf("email"), 0))}
+
+	findings := Compare(source, target)
+	if len(findings) != 1 || findings[0].Type != CompareIndexOptions || findings[0].Severity != SeverityHigh {
+		t.Fatalf("expected one high INDEX_OPTIONS_DRIFT, got %v", findings)
+	}
+}
+
 func TestCompare_Identical(t *testing.T) {
 	coll := collInfo("users", "app", 100,
 		mongoinspect.IndexInfo{Name: "_id_", Key: kf("_id")},
@@ -144,3 +191,57 @@ func TestFormatKeyFields(t *testing.T) {
 		t.Errorf("formatKeyFields = %q, want %q", got, want)
 	}
 }
+
+func TestDiffCompareBaseline(t *testing.T) {
+	baseline := []CompareFinding{
+		{Type: CompareIndexDrift, Database: "app", Collection: "users", Index: "email_1"},
+		{Type: CompareMissingInTarget, Database: "app", Collection: "orders", Target: "dev"},
+	}
+	current := []CompareFinding{
+		{Type: CompareIndexDrift, Database: "app", Collection: "Users", Index: "email_1"},
+		{Type: CompareMissingInTarget, Database: "app", Collection: "orders", Target: "staging"},
+	}
+
+	diff := DiffCompareBaseline(current, baseline)
+	got := make(map[string]BaselineStatus)
+	for _, f := range diff {
+		got[f.Collection+"/"+f.Target] = f.Status
+	}
+	want := map[string]BaselineStatus{
+		"Users/":         StatusUnchanged,
+		"orders/staging": StatusNew,
+		"orders/dev":     StatusResolved,
+	}
+	if len(diff) != len(want) {
+		t.Fatalf("expected %d entries, got %+v", len(want), diff)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestLoadCompareBaseline(t *testing.T) {
+	dir := t.TempDir()
+	arrayPath := filepath.Join(dir, "single.json")
+	matrixPath := filepath.Join(dir, "matrix.json")
+	if err := os.WriteFile(arrayPath, []byte(`[{"type":"INDEX_DRIFT","collection":"users"}]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(matrixPath, []byte(`{"environments":["a","b","c"],"rows":[],"findings":[{"type":"MISSING_IN_TARGET","collection":"orders","target":"c"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	single, err := LoadCompareBaseline(arrayPath)
+	if err != nil || len(single) != 1 || single[0].Type != CompareIndexDrift {
+		t.Fatalf("single: %+v, %v", single, err)
+	}
+	matrix, err := LoadCompareBaseline(matrixPath)
+	if err != nil || len(matrix) != 1 || matrix[0].Target != "c" {
+		t.Fatalf("matrix: %+v, %v", matrix, err)
+	}
+	if _, err := LoadCompareBaseline(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
@@ -0,0 +1,157 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+// MatrixKind identifies what a compare matrix row tracks.
+type MatrixKind string
+
+const (
+	MatrixCollection MatrixKind = "collection"
+	MatrixValidator  MatrixKind = "validator"
+	MatrixIndex      MatrixKind = "index"
+)
+
+// MatrixCell is the state of one row in one environment.
+type MatrixCell string
+
+const (
+	CellPresent MatrixCell = "present"
+	CellMissing MatrixCell = "missing"
+	// CellDiffers marks an index with a different key pattern or options, or
+	// a validator with a different schema, than the reference environment
+	// (the first one where the row is present).
+	CellDiffers MatrixCell = "differs"
+)
+
+// MatrixRow is a collection, validator or index and its state in each
+// environment, in CompareMatrix.Environments order.
+type MatrixRow struct {
+	Kind       MatrixKind   `json:"kind"`
+	Database   string       `json:"database"`
+	Collection string       `json:"collection"`
+	Index      string       `json:"index,omitempty"`
+	Cells      []MatrixCell `json:"cells"`
+}
+
+// Consistent reports whether the row is present and identical everywhere.
+func (r *MatrixRow) Consistent() bool {
+	for _, c := range r.Cells {
+		if c != CellPresent {
+			return false
+		}
+	}
+	return true
+}
+
+// CompareMatrix shows where each collection, validator and index exists
+// across several environments.
+type CompareMatrix struct {
+	Environments []string    `json:"environments"`
+	Rows         []MatrixRow `json:"rows"`
+}
+
+// matrixEntry is a row's comparable signature in one environment.
+type matrixEntry struct {
+	database, collection, index string
+	signature                   string
+}
+
+// BuildCompareMatrix lines up the collections of each environment by name,
+// like Compare. Rows are sorted by collection, then collection, validator
+// and index rows, then index name. The _id_ index is omitted.
+func BuildCompareMatrix(envs []string, collections [][]mongoinspect.CollectionInfo) CompareMatrix {
+	type rowKey struct {
+		collection string
+		kind       MatrixKind
+		index      string
+	}
+	perEnv := make([]map[rowKey]matrixEntry, len(collections))
+	keys := make(map[rowKey]bool)
+	for e, colls := range collections {
+		perEnv[e] = make(map[rowKey]matrixEntry)
+		add := func(k rowKey, entry matrixEntry) {
+			perEnv[e][k] = entry
+			keys[k] = true
+		}
+		for i := range colls {
+			c := &colls[i]
+			name := strings.ToLower(c.Name)
+			add(rowKey{name, MatrixCollection, ""}, matrixEntry{database: c.Database, collection: c.Name})
+			if c.Validator != nil {
+				add(rowKey{name, MatrixValidator, ""}, matrixEntry{database: c.Database, collection: c.Name, signature: validatorSignature(c.Validator)})
+			}
+			for _, idx := range c.Indexes {
+				if idx.Name == "_id_" {
+					continue
+				}
+				add(rowKey{name, MatrixIndex, idx.Name}, matrixEntry{database: c.Database, collection: c.Name, index: idx.Name, signature: indexSignature(&idx)})
+			}
+		}
+	}
+
+	kindOrder := map[MatrixKind]int{MatrixCollection: 0, MatrixValidator: 1, MatrixIndex: 2}
+	sorted := make([]rowKey, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.collection != b.collection {
+			return a.collection < b.collection
+		}
+		if a.kind != b.kind {
+			return kindOrder[a.kind] < kindOrder[b.kind]
+		}
+		return a.index < b.index
+	})
+
+	m := CompareMatrix{Environments: envs}
+	for _, k := range sorted {
+		row := MatrixRow{Kind: k.kind, Cells: make([]MatrixCell, len(collections))}
+		var ref *matrixEntry
+		for e := range collections {
+			entry, ok := perEnv[e][k]
+			switch {
+			case !ok:
+				row.Cells[e] = CellMissing
+				continue
+			case ref == nil:
+				ref = &entry
+				row.Database, row.Collection, row.Index = entry.database, entry.collection, entry.index
+				row.Cells[e] = CellPresent
+			case entry.signature != ref.signature:
+				row.Cells[e] = CellDiffers
+			default:
+				row.Cells[e] = CellPresent
+			}
+		}
+		m.Rows = append(m.Rows, row)
+	}
+	return m
+}
+
+// indexSignature renders the key pattern and the options compared by
+// INDEX_OPTIONS_DRIFT.
+func indexSignature(idx *mongoinspect.IndexInfo) string {
+	sig := formatKeyFields(idx.Key)
+	for _, o := range indexOptions(idx, &mongoinspect.IndexInfo{}) {
+		sig += " " + o.name + "=" + o.source
+	}
+	return sig
+}
+
+// validatorSignature renders the parts of a validator that matter for drift.
+func validatorSignature(v *mongoinspect.ValidatorInfo) string {
+	data, _ := json.Marshal(struct {
+		Schema mongoinspect.ValidatorSchema
+		Level  string
+		Action string
+	}{v.Schema, v.ValidationLevel, v.ValidationAction})
+	return string(data)
+}
@@ -0,0 +1,101 @@
+package analyzer
+
+import (
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+func TestBuildCompareMatrix(t *testing.T) {
+	emailIdx := mongoinspect.IndexInfo{Name: "email_1", Key: []mongoinspect.KeyField{{Field: "email", Direction: 1}}}
+	emailDesc := mongoinspect.IndexInfo{Name: "email_1", Key: []mongoinspect.KeyField{{Field: "email", Direction: -1}}}
+	idIdx := mongoinspect.IndexInfo{Name: "_id_", Key: []mongoinspect.KeyField{{Field: "_id", Direction: 1}}}
+	strict := &mongoinspect.ValidatorInfo{Schema: mongoinspect.ValidatorSchema{Required: []string{"email"}}}
+	loose := &mongoinspect.ValidatorInfo{Schema: mongoinspect.ValidatorSchema{}}
+
+	prod := []mongoinspect.CollectionInfo{
+		collInfo("users", "app", 10, idIdx, emailIdx),
+		collInfo("orders", "app", 10, idIdx),
+	}
+	prod[0].Validator = strict
+	staging := []mongoinspect.CollectionInfo{
+		collInfo("users", "app", 10, idIdx, emailDesc),
+	}
+	staging[0].Validator = loose
+	dev := []mongoinspect.CollectionInfo{
+		collInfo("Users", "app", 10, idIdx, emailIdx),
+		collInfo("orders", "app", 10, idIdx),
+		collInfo("scratch", "app", 10, idIdx),
+	}
+	dev[0].Validator = strict
+
+	m := BuildCompareMatrix([]string{"prod", "staging", "dev"},
+		[][]mongoinspect.CollectionInfo{prod, staging, dev})
+
+	want := []struct {
+		kind       MatrixKind
+		collection string
+		index      string
+		cells      []MatrixCell
+	}{
+		{MatrixCollection, "orders", "", []MatrixCell{CellPresent, CellMissing, CellPresent}},
+		{MatrixCollection, "scratch", "", []MatrixCell{CellMissing, CellMissing, CellPresent}},
+		{MatrixCollection, "users", "", []MatrixCell{CellPresent, CellPresent, CellPresent}},
+		{MatrixValidator, "users", "", []MatrixCell{CellPresent, CellDiffers, CellPresent}},
+		{MatrixIndex, "users", "email_1", []MatrixCell{CellPresent, CellDiffers, CellPresent}},
+	}
+	if len(m.Rows) != len(want) {
+		t.Fatalf("expected %d rows, got %d: %+v", len(want), len(m.Rows), m.Rows)
+	}
+	for i, w := range want {
+		r := m.Rows[i]
+		if r.Kind != w.kind || r.Collection != w.collection || r.Index != w.index {
+			t.Fatalf("row %d = %s %s %s, want %s %s %s", i, r.Kind, r.Collection, r.Index, w.kind, w.collection, w.index)
+		}
+		for j := range w.cells {
+			if r.Cells[j] != w.cells[j] {
+				t.Errorf("row %d (%s %s) cell %d = %s, want %s", i, r.Collection, r.Index, j, r.Cells[j], w.cells[j])
+			}
+		}
+	}
+	if !m.Rows[2].Consistent() || m.Rows[3].Consistent() {
+		t.Error("Consistent() mismatch for users rows")
+	}
+}
+
+func TestBuildCompareMatrix_ReferenceIsFirstPresent(t *testing.T) {
+	a := mongoinspect.IndexInfo{Name: "sku_1", Key: []mongoinspect.KeyField{{Field: "sku", Direction: 1}}}
+	b := mongoinspect.IndexInfo{Name: "sku_1", Key: []mongoinspect.KeyField{{Field: "sku", Direction: 1}, {Field: "qty", Direction: 1}}}
+
+	m := BuildCompareMatrix([]string{"prod", "staging", "dev"}, [][]mongoinspect.CollectionInfo{
+		nil,
+		{collInfo("items", "app", 1, a)},
+		{collInfo("items", "app", 1, b)},
+	})
+
+	idx := m.Rows[len(m.Rows)-1]
+	if idx.Kind != MatrixIndex {
+		t.Fatalf("expected index row last, got %s", idx.Kind)
+	}
+	want := []MatrixCell{CellMissing, CellPresent, CellDiffers}
+	for j := range want {
+		if idx.Cells[j] != want[j] {
+			t.Errorf("cell %d = %s, want %s", j, idx.Cells[j], want[j])
+		}
+	}
+}
+
+func TestBuildCompareMatrix_IndexOptions(t *testing.T) {
+	ttl := int32(60)
+	plain := mongoinspect.IndexInfo{Name: "expires_1", Key: []mongoinspect.KeyField{{Field: "expires", Direction: 1}}}
+	withTTL := plain
+	withTTL.TTL = &ttl
+
+	m := BuildCompareMatrix([]string{"prod", "dev"}, [][]mongoinspect.CollectionInfo{
+		{collInfo("sessions", "app", 1, withTTL)},
+		{collInfo("sessions", "app", 1, plain)},
+	})
+	if got := m.Rows[len(m.Rows)-1].Cells[1]; got != CellDiffers {
+		t.Fatalf("TTL mismatch cell = %s, want differs", got)
+	}
+}
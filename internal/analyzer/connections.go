@@ -0,0 +1,124 @@
+package analyzer
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+const clientSourceTopN = 5 // hosts and drivers listed per finding
+
+// AuditClientConnections summarizes the client connections of $currentOp
+// by source host and driver version, together with the server's own
+// connection pools when pool is set. Drivers older than the first release
+// supporting serverVersion are reported as DRIVER_VERSION_OUTDATED, one
+// finding per driver version: they may lack wire protocol features or
+// misreport errors the server returns.
+func AuditClientConnections(ops []mongoinspect.ClientOp, pool *mongoinspect.ConnPoolStats, serverVersion string) []Finding {
+	if len(ops) == 0 && pool == nil {
+		return nil
+	}
+	type driverUse struct {
+		key, name, version string
+		connections        int
+		hosts, apps        map[string]int
+	}
+	hosts := make(map[string]int)
+	drivers := make(map[string]int)
+	outdated := make(map[string]*driverUse)
+	for _, op := range ops {
+		host := op.Client
+		if h, _, err := net.SplitHostPort(op.Client); err == nil {
+			host = h
+		}
+		hosts[host]++
+		if op.DriverName == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(op.DriverName, "|")
+		version, _, _ := strings.Cut(op.DriverVersion, "|")
+		label := strings.TrimSpace(name + " " + version)
+		drivers[label]++
+
+		key := handshakeDrivers[strings.ToLower(name)]
+		minVersion, _ := minDriverVersion(key, serverVersion)
+		if minVersion == "" || normalizeVersion(version) == "" || compareVersion(version, minVersion) >= 0 {
+			continue
+		}
+		u := outdated[label]
+		if u == nil {
+			u = &driverUse{key: key, name: name, version: version, hosts: make(map[string]int), apps: make(map[string]int)}
+			outdated[label] = u
+		}
+		u.connections++
+		u.hosts[host]++
+		if op.AppName != "" {
+			u.apps[op.AppName]++
+		}
+	}
+
+	var parts []string
+	if len(ops) > 0 {
+		parts = append(parts, fmt.Sprintf("%d client connections from %d hosts; top sources: %s", len(ops), len(hosts), rankCounts(hosts, clientSourceTopN)))
+		if len(drivers) > 0 {
+			parts = append(parts, "drivers: "+rankCounts(drivers, clientSourceTopN))
+		}
+	}
+	if pool != nil {
+		parts = append(parts, fmt.Sprintf("server connection pools to %d hosts: %d in use, %d available, %d created",
+			pool.Hosts, pool.InUse, pool.Available, pool.Created))
+	}
+	findings := []Finding{{
+		Type:     FindingClientConnSources,
+		Severity: SeverityInfo,
+		Message:  strings.Join(parts, "; "),
+	}}
+
+	labels := make([]string, 0, len(outdated))
+	for label := range outdated {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		u := outdated[label]
+		minVersion, release := minDriverVersion(u.key, serverVersion)
+		apps := ""
+		if len(u.apps) > 0 {
+			apps = ", apps: " + rankCounts(u.apps, clientSourceTopN)
+		}
+		findings = append(findings, Finding{
+			Type:     FindingDriverOutdated,
+			Severity: SeverityMedium,
+			Message: fmt.Sprintf("%d connections use %s %s (hosts: %s%s); MongoDB %s is supported from %s %s — upgrade the driver",
+				u.connections, u.name, u.version, rankCounts(u.hosts, clientSourceTopN), apps, release, driverLabels[u.key], minVersion),
+		})
+	}
+	return findings
+}
+
+// rankCounts lists the keys of counts by descending count, as "a (3),
+// b (1)", with the keys beyond limit summarized as "N more".
+func rankCounts(counts map[string]int, limit int) string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	parts := make([]string, 0, limit+1)
+	for i, k := range keys {
+		if i == limit {
+			parts = append(parts, fmt.Sprintf("%d more", len(keys)-limit))
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%s (%d)", k, counts[k]))
+	}
+	return strings.Join(parts, ", ")
+}
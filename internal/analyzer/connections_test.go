@@ -0,0 +1,51 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+func TestAuditClientConnections(t *testing.T) {
+	ops := []mongoinspect.ClientOp{
+		{Client: "10.0.0.5:51234", AppName: "billing", DriverName: "nodejs|Mongoose", DriverVersion: "5.9.2|7.6.0"},
+		{Client: "10.0.0.5:51240", AppName: "billing", DriverName: "nodejs|Mongoose", DriverVersion: "5.9.2|7.6.0"},
+		{Client: "10.0.0.7:40000", DriverName: "mongo-go-driver", DriverVersion: "v1.17.1"},
+		{Client: "[::1]:40100", DriverName: "PyMongo", DriverVersion: "4.3.3"},
+		{Client: "10.0.0.9:40200", DriverName: "mongoc", DriverVersion: "1.20.0"},
+	}
+	pool := &mongoinspect.ConnPoolStats{InUse: 3, Available: 12, Created: 40, Hosts: 2}
+
+	findings := AuditClientConnections(ops, pool, "8.0.4")
+	if len(findings) != 3 {
+		t.Fatalf("got %d findings, want 3: %+v", len(findings), findings)
+	}
+	if f := findings[0]; f.Type != FindingClientConnSources || f.Severity != SeverityInfo ||
+		!strings.Contains(f.Message, "5 client connections from 4 hosts; top sources: 10.0.0.5 (2), 10.0.0.7 (1), 10.0.0.9 (1), ::1 (1);") ||
+		!strings.Contains(f.Message, "drivers: nodejs 5.9.2 (2), PyMongo 4.3.3 (1)") ||
+		!strings.Contains(f.Message, "server connection pools to 2 hosts: 3 in use, 12 available, 40 created") {
+		t.Errorf("sources finding = %+v", f)
+	}
+	if f := findings[1]; f.Type != FindingDriverOutdated || f.Severity != SeverityMedium ||
+		!strings.Contains(f.Message, "1 connections use PyMongo 4.3.3 (hosts: ::1 (1)); MongoDB 8.0 is supported from PyMongo 4.9") {
+		t.Errorf("PyMongo finding = %+v", f)
+	}
+	if f := findings[2]; f.Type != FindingDriverOutdated ||
+		!strings.Contains(f.Message, "2 connections use nodejs 5.9.2 (hosts: 10.0.0.5 (2), apps: billing (2)); MongoDB 8.0 is supported from Node.js driver 6.8") {
+		t.Errorf("nodejs finding = %+v", f)
+	}
+
+	// Servers older than the table are not checked; without ops only the pools are summarized.
+	for _, f := range AuditClientConnections(ops, nil, "4.2.0") {
+		if f.Type == FindingDriverOutdated {
+			t.Errorf("unexpected finding for 4.2: %+v", f)
+		}
+	}
+	if got := AuditClientConnections(nil, pool, "8.0.4"); len(got) != 1 || strings.Contains(got[0].Message, "client connections") {
+		t.Errorf("pool-only findings = %+v", got)
+	}
+	if got := AuditClientConnections(nil, nil, "8.0.4"); got != nil {
+		t.Errorf("expected no findings, got %+v", got)
+	}
+}
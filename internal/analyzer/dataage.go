@@ -0,0 +1,53 @@
+package analyzer
+
+import (
+	"fmt"
+	"time"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+// DetectStaleData reports sampled collections whose newest document, by
+// ObjectId _id and well-known date fields, is older than the StaleDataDays
+// threshold: nothing has written to them in years. $sample picks documents
+// at random and may miss the few recent ones among many old documents, so
+// collections whose $collStats latency shows writes since the server
+// started are skipped; the finding says how many documents were sampled.
+func DetectStaleData(collections []mongoinspect.CollectionInfo, samples []mongoinspect.FieldSampleResult) []Finding {
+	written := writtenCollections(collections)
+	var findings []Finding
+	for i := range samples {
+		s := &samples[i]
+		age := s.Age()
+		if age == nil || written[s.Database+"."+s.Collection] {
+			continue
+		}
+		limit := time.Duration(thresholdsFor(s.Database).StaleDataDays) * 24 * time.Hour
+		elapsed := now().Sub(age.Newest)
+		if elapsed < limit {
+			continue
+		}
+		findings = append(findings, Finding{
+			Type:       FindingStaleData,
+			Severity:   SeverityLow,
+			Database:   s.Database,
+			Collection: s.Collection,
+			Message: fmt.Sprintf("newest of %d sampled documents dates from %s (%s ago); the collection looks untouched — archive or drop it if nothing reads it",
+				s.SampleSize, age.Newest.Format(time.DateOnly), formatElapsed(elapsed)),
+		})
+	}
+	return findings
+}
+
+// writtenCollections returns the namespaces whose latency statistics count
+// writes since the server started.
+func writtenCollections(collections []mongoinspect.CollectionInfo) map[string]bool {
+	written := make(map[string]bool)
+	for i := range collections {
+		c := &collections[i]
+		if c.Latency != nil && c.Latency.Writes.Ops > 0 {
+			written[c.Database+"."+c.Name] = true
+		}
+	}
+	return written
+}
@@ -0,0 +1,46 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+func TestDetectStaleData(t *testing.T) {
+	fixed := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return fixed }
+	t.Cleanup(func() { now = time.Now })
+	samples := []mongoinspect.FieldSampleResult{
+		{Database: "app", Collection: "legacy_orders", SampleSize: 100, DateRanges: []mongoinspect.DateRange{
+			{Field: "_id", Oldest: fixed.AddDate(-6, 0, 0), Newest: fixed.AddDate(-4, 0, 0)},
+			{Field: "updatedAt", Oldest: fixed.AddDate(-6, 0, 0), Newest: fixed.AddDate(-3, 0, 0)},
+		}},
+		{Database: "app", Collection: "orders", SampleSize: 100, DateRanges: []mongoinspect.DateRange{
+			{Field: "_id", Oldest: fixed.AddDate(-6, 0, 0), Newest: fixed.AddDate(0, 0, -1)},
+		}},
+		{Database: "app", Collection: "settings", SampleSize: 3},
+		{Database: "archive", Collection: "orders_2022", SampleSize: 100, DateRanges: []mongoinspect.DateRange{
+			{Field: "_id", Oldest: fixed.AddDate(-4, 0, 0), Newest: fixed.AddDate(-3, 0, 0)},
+		}},
+		// The sample missed the recent documents, but the server saw writes.
+		{Database: "app", Collection: "audit_log", SampleSize: 100, DateRanges: []mongoinspect.DateRange{
+			{Field: "_id", Oldest: fixed.AddDate(-6, 0, 0), Newest: fixed.AddDate(-4, 0, 0)},
+		}},
+	}
+	collections := []mongoinspect.CollectionInfo{
+		{Database: "app", Name: "legacy_orders", Latency: &mongoinspect.LatencyStats{Reads: mongoinspect.OpLatency{Ops: 12}}},
+		{Database: "app", Name: "audit_log", Latency: &mongoinspect.LatencyStats{Writes: mongoinspect.OpLatency{Ops: 3}}},
+	}
+	setThresholds(t, ThresholdConfig{Databases: map[string]Thresholds{"archive": {StaleDataDays: 5 * 365}}})
+
+	findings := DetectStaleData(collections, samples)
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1: %+v", len(findings), findings)
+	}
+	if f := findings[0]; f.Type != FindingStaleData || f.Collection != "legacy_orders" ||
+		!strings.Contains(f.Message, "newest of 100 sampled documents dates from 2023-01-01") {
+		t.Errorf("finding = %+v", f)
+	}
+}
@@ -0,0 +1,83 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+// DetectDeadCollections flags collections whose code references all live
+// in files untouched since before cutoff and which the server reports no
+// operations on: the feature behind them, often an expired feature flag,
+// is likely dead. lastTouched maps each referencing file to the time of its
+// newest commit; a file missing from it or with a zero time, such as an
+// uncommitted one, keeps its collections from being flagged. Collections
+// without $collStats latency or $indexStats are skipped, since no
+// operations cannot be told apart from no statistics.
+func DetectDeadCollections(scan *scanner.ScanResult, collections []mongoinspect.CollectionInfo, lastTouched map[string]time.Time, cutoff time.Time) []Finding {
+	if scan == nil {
+		return nil
+	}
+	refFiles := make(map[string]map[string]bool)
+	for _, ref := range scan.Refs {
+		key := strings.ToLower(ref.Collection)
+		if refFiles[key] == nil {
+			refFiles[key] = make(map[string]bool)
+		}
+		refFiles[key][ref.File] = true
+	}
+
+	var findings []Finding
+	for i := range collections {
+		c := &collections[i]
+		files := refFiles[strings.ToLower(c.Name)]
+		if c.Type == "view" || len(files) == 0 || !hasNoOps(c) {
+			continue
+		}
+		var newest time.Time
+		for file := range files {
+			touched := lastTouched[file]
+			if touched.IsZero() || touched.After(cutoff) {
+				newest = time.Time{}
+				break
+			}
+			if touched.After(newest) {
+				newest = touched
+			}
+		}
+		if newest.IsZero() {
+			continue
+		}
+		findings = append(findings, Finding{
+			Type:       FindingLikelyDeadCollection,
+			Severity:   SeverityLow,
+			Database:   c.Database,
+			Collection: c.Name,
+			Message: fmt.Sprintf("all %d file(s) referencing this collection were last changed on %s and the server reports no operations on it; the feature using it is likely dead",
+				len(files), newest.Format("2006-01-02")),
+		})
+	}
+	return findings
+}
+
+// hasNoOps reports whether statistics exist for c and show no reads,
+// writes or index use.
+func hasNoOps(c *mongoinspect.CollectionInfo) bool {
+	haveStats := c.Latency != nil
+	if c.Latency != nil && (c.Latency.Reads.Ops > 0 || c.Latency.Writes.Ops > 0) {
+		return false
+	}
+	for _, idx := range c.Indexes {
+		if idx.Stats == nil {
+			continue
+		}
+		haveStats = true
+		if idx.Stats.Ops > 0 {
+			return false
+		}
+	}
+	return haveStats
+}
@@ -0,0 +1,57 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+func TestDetectDeadCollections(t *testing.T) {
+	cutoff := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	scan := &scanner.ScanResult{Refs: []scanner.CollectionRef{
+		{Collection: "promo_codes", File: "promo/legacy.js"},
+		{Collection: "promo_codes", File: "promo/admin.js"},
+		{Collection: "users", File: "users.js"},
+		{Collection: "drafts", File: "drafts.js"},
+		{Collection: "events", File: "events.js"},
+	}}
+	touched := map[string]time.Time{
+		"promo/legacy.js": time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+		"promo/admin.js":  time.Date(2025, 6, 9, 0, 0, 0, 0, time.UTC),
+		"users.js":        time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		"events.js":       time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		// drafts.js is uncommitted.
+	}
+	noOps := []mongoinspect.IndexInfo{{Name: "_id_", Stats: &mongoinspect.IndexStats{}}}
+	collections := []mongoinspect.CollectionInfo{
+		{Database: "app", Name: "promo_codes", Indexes: noOps},
+		{Database: "app", Name: "users", Indexes: noOps},
+		{Database: "app", Name: "drafts", Indexes: noOps},
+		{Database: "app", Name: "events", Indexes: noOps, Latency: &mongoinspect.LatencyStats{Writes: mongoinspect.OpLatency{Ops: 7}}},
+		{Database: "app", Name: "orphans", Indexes: noOps},
+	}
+
+	findings := DetectDeadCollections(scan, collections, touched, cutoff)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d: %+v", len(findings), findings)
+	}
+	f := findings[0]
+	if f.Type != FindingLikelyDeadCollection || f.Collection != "promo_codes" || f.Severity != SeverityLow {
+		t.Errorf("finding = %+v", f)
+	}
+	want := "all 2 file(s) referencing this collection were last changed on 2025-06-09 and the server reports no operations on it; the feature using it is likely dead"
+	if f.Message != want {
+		t.Errorf("message = %q, want %q", f.Message, want)
+	}
+}
+
+func TestDetectDeadCollectionsNeedsStats(t *testing.T) {
+	scan := &scanner.ScanResult{Refs: []scanner.CollectionRef{{Collection: "promo_codes", File: "promo.js"}}}
+	touched := map[string]time.Time{"promo.js": time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	collections := []mongoinspect.CollectionInfo{{Name: "promo_codes", Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}}}
+	if findings := DetectDeadCollections(scan, collections, touched, time.Now()); len(findings) != 0 {
+		t.Errorf("expected no findings without operation statistics, got %+v", findings)
+	}
+}
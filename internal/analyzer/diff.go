@@ -12,15 +12,44 @@ import (
 
 // Diff compares code repo references against live MongoDB collections.
 func Diff(scan *scanner.ScanResult, collections []mongoinspect.CollectionInfo) []Finding {
-	// Build set of collection names referenced in code (lowercased for comparison).
-	codeRefs := make(map[string]bool)
-	for _, name := range scan.Collections {
-		codeRefs[strings.ToLower(name)] = true
-	}
-
+	return NewPipeline(DiffDetectors(scan)...).Collect(collections)
+}
+
+// DiffDetectors returns the detectors Diff runs for a code scan, in report
+// order. Each needs the full collection set, so all report in Finish.
+func DiffDetectors(scan *scanner.ScanResult) []Detector {
+	withScan := func(fn func(*scanner.ScanResult, []mongoinspect.CollectionInfo) []Finding) func([]mongoinspect.CollectionInfo) []Finding {
+		return func(collections []mongoinspect.CollectionInfo) []Finding { return fn(scan, collections) }
+	}
+	return []Detector{
+		collectionSetDetector("missing-collection", withScan(detectMissingCollections)),
+		collectionSetDetector("unreferenced-collection", withScan(detectUnreferencedCollections)),
+		collectionSetDetector("orphaned-index", withScan(detectOrphanedIndexes)),
+		collectionSetDetector("unindexed-query", withScan(detectUnindexedQueries)),
+		collectionSetDetector("suggest-index", withScan(suggestFieldIndexes)),
+		collectionSetDetector("index-recommendation", withScan(recommendSmartIndexes)),
+		collectionSetDetector("partial-index", withScan(suggestPartialIndexes)),
+		collectionSetDetector("text-index-conflict", withScan(detectTextIndexConflicts)),
+		collectionSetDetector("validator-drift", withScan(detectValidatorDrift)),
+		collectionSetDetector("encryption-schema-drift", withScan(detectEncryptionSchemaDrift)),
+		collectionSetDetector("qe-unsupported-query", withScan(detectQEUnsupportedQueries)),
+		collectionSetDetector("vector-search-drift", withScan(detectVectorSearchDrift)),
+		collectionSetDetector("dynamic-collection", withScan(detectDynamicCollections)),
+		collectionSetDetector("hardcoded-uri", withScan(detectHardcodedURIs)),
+		collectionSetDetector("referenced-collection", withScan(detectReferencedCollections)),
+	}
+}
+
+// detectMissingCollections flags collections referenced in code that do not
+// exist in the database.
+func detectMissingCollections(scan *scanner.ScanResult, collections []mongoinspect.CollectionInfo) []Finding {
 	var findings []Finding
-
-	// 1. MISSING_COLLECTION: in code, not in DB
+	firstRef := make(map[string]scanner.CollectionRef)
+	for _, ref := range scan.Refs {
+		if _, ok := firstRef[ref.Collection]; !ok {
+			firstRef[ref.Collection] = ref
+		}
+	}
 	for _, name := range scan.Collections {
 		if _, found := findCollection(name, collections); !found {
 			findings = append(findings, Finding{
@@ -28,11 +57,29 @@ func Diff(scan *scanner.ScanResult, collections []mongoinspect.CollectionInfo) [
 				Severity:   SeverityHigh,
 				Collection: name,
 				Message:    fmt.Sprintf("collection %q referenced in code but does not exist in database", name),
+				File:       firstRef[name].File,
+				Line:       firstRef[name].Line,
 			})
 		}
 	}
+	return findings
+}
 
-	// 2. UNUSED_COLLECTION: in DB, not in code, zero docs
+// codeRefSet returns the collection names referenced in code, lowercased for
+// comparison.
+func codeRefSet(scan *scanner.ScanResult) map[string]bool {
+	codeRefs := make(map[string]bool)
+	for _, name := range scan.Collections {
+		codeRefs[strings.ToLower(name)] = true
+	}
+	return codeRefs
+}
+
+// detectUnreferencedCollections flags empty collections that code never
+// references.
+func detectUnreferencedCollections(scan *scanner.ScanResult, collections []mongoinspect.CollectionInfo) []Finding {
+	codeRefs := codeRefSet(scan)
+	var findings []Finding
 	for _, c := range collections {
 		if c.Type == "view" {
 			continue
@@ -47,8 +94,14 @@ func Diff(scan *scanner.ScanResult, collections []mongoinspect.CollectionInfo) [
 			})
 		}
 	}
+	return findings
+}
 
-	// 3. ORPHANED_INDEX: index exists on a collection not referenced in code
+// detectOrphanedIndexes flags unused indexes on collections code does not
+// reference.
+func detectOrphanedIndexes(scan *scanner.ScanResult, collections []mongoinspect.CollectionInfo) []Finding {
+	codeRefs := codeRefSet(scan)
+	var findings []Finding
 	for _, c := range collections {
 		if c.Type == "view" {
 			continue
@@ -72,29 +125,45 @@ func Diff(scan *scanner.ScanResult, collections []mongoinspect.CollectionInfo) [
 			}
 		}
 	}
+	return findings
+}
 
-	// 4. UNINDEXED_QUERY: code queries a field that has no covering index
-	findings = append(findings, detectUnindexedQueries(scan, collections)...)
-
-	// 4b. SUGGEST_INDEX: individual field-level index suggestions for large collections
-	findings = append(findings, suggestFieldIndexes(scan, collections)...)
-
-	// 5. Smart index recommendations and index-shape quality findings.
-	findings = append(findings, recommendSmartIndexes(scan, collections)...)
-
-	// 6. VALIDATOR_*: JSON schema validator drift for code write patterns.
-	findings = append(findings, detectValidatorDrift(scan, collections)...)
-
-	// 7. DYNAMIC_COLLECTION: variable collection name could not be resolved
+// detectDynamicCollections reports collection names taken from variables
+// the scanner could not resolve.
+func detectDynamicCollections(scan *scanner.ScanResult, _ []mongoinspect.CollectionInfo) []Finding {
+	var findings []Finding
 	for _, dr := range scan.DynamicRefs {
 		findings = append(findings, Finding{
 			Type:     FindingDynamicCollection,
 			Severity: SeverityInfo,
 			Message:  fmt.Sprintf("collection name from variable %q could not be resolved statically (%s:%d)", dr.Variable, dr.File, dr.Line),
+			File:     dr.File,
+			Line:     dr.Line,
 		})
 	}
+	return findings
+}
 
-	// 8. OK: collection referenced in code and exists in DB
+// detectHardcodedURIs flags connection strings with a password committed to
+// source.
+func detectHardcodedURIs(scan *scanner.ScanResult, _ []mongoinspect.CollectionInfo) []Finding {
+	var findings []Finding
+	for _, cr := range scan.CredentialURIRefs {
+		findings = append(findings, Finding{
+			Type:     FindingHardcodedURI,
+			Severity: SeverityHigh,
+			Message:  fmt.Sprintf("connection string with password for user %q committed to source (%s:%d: %s) — rotate the password and load the URI from the environment", cr.User, cr.File, cr.Line, cr.URI),
+			File:     cr.File,
+			Line:     cr.Line,
+		})
+	}
+	return findings
+}
+
+// detectReferencedCollections reports collections that are both referenced
+// in code and present in the database.
+func detectReferencedCollections(scan *scanner.ScanResult, collections []mongoinspect.CollectionInfo) []Finding {
+	var findings []Finding
 	for _, name := range scan.Collections {
 		if _, found := findCollection(name, collections); found {
 			findings = append(findings, Finding{
@@ -105,7 +174,6 @@ func Diff(scan *scanner.ScanResult, collections []mongoinspect.CollectionInfo) [
 			})
 		}
 	}
-
 	return findings
 }
 
@@ -116,16 +184,19 @@ func detectUnindexedQueries(scan *scanner.ScanResult, collections []mongoinspect
 	}
 
 	// Group queried fields by collection.
-	fieldsByCollection := make(map[string]map[string]bool)
+	// Each field keeps its first reference as the finding's location.
+	fieldsByCollection := make(map[string]map[string]scanner.FieldRef)
 	for _, fr := range scan.FieldRefs {
 		if !isQueryableUsage(fr.Usage) {
 			continue
 		}
 		lower := strings.ToLower(fr.Collection)
 		if fieldsByCollection[lower] == nil {
-			fieldsByCollection[lower] = make(map[string]bool)
+			fieldsByCollection[lower] = make(map[string]scanner.FieldRef)
+		}
+		if _, ok := fieldsByCollection[lower][fr.Field]; !ok {
+			fieldsByCollection[lower][fr.Field] = fr
 		}
-		fieldsByCollection[lower][fr.Field] = true
 	}
 
 	var findings []Finding
@@ -161,6 +232,8 @@ func detectUnindexedQueries(scan *scanner.ScanResult, collections []mongoinspect
 				Database:   coll.Database,
 				Collection: coll.Name,
 				Message:    fmt.Sprintf("field %q is queried in code but has no covering index", field),
+				File:       fields[field].File,
+				Line:       fields[field].Line,
 			})
 		}
 	}
@@ -168,7 +241,7 @@ func detectUnindexedQueries(scan *scanner.ScanResult, collections []mongoinspect
 }
 
 // suggestFieldIndexes recommends individual field indexes for unindexed query
-// fields on collections that exceed suggestMinDocs.
+// fields on collections that exceed the SuggestMinDocs threshold.
 func suggestFieldIndexes(scan *scanner.ScanResult, collections []mongoinspect.CollectionInfo) []Finding {
 	if len(scan.FieldRefs) == 0 {
 		return nil
@@ -196,7 +269,7 @@ func suggestFieldIndexes(scan *scanner.ScanResult, collections []mongoinspect.Co
 	for _, collName := range collNames {
 		fields := fieldsByCollection[collName]
 		coll, found := findCollection(collName, collections)
-		if !found || coll.DocCount < suggestMinDocs {
+		if !found || coll.DocCount < thresholdsFor(coll.Database).SuggestMinDocs {
 			continue
 		}
 
@@ -236,8 +309,7 @@ func isFieldIndexed(field string, indexes []mongoinspect.IndexInfo) bool {
 }
 
 const (
-	suggestMinDocs    int64 = 1000 // skip suggestions for small collections
-	suggestMaxPerColl int   = 5    // limit suggestions per collection
+	suggestMaxPerColl int = 5 // limit suggestions per collection
 )
 
 type queryRole int
@@ -308,7 +380,7 @@ func recommendSmartIndexes(scan *scanner.ScanResult, collections []mongoinspect.
 	var findings []Finding
 	for _, collName := range collNames {
 		coll, found := findCollection(collName, collections)
-		if !found || coll.DocCount < suggestMinDocs {
+		if !found || coll.DocCount < thresholdsFor(coll.Database).SuggestMinDocs {
 			continue
 		}
 		if indexStatsUnavailable(coll) {
@@ -1,6 +1,7 @@
 package analyzer
 
 import (
+	"strings"
 	"testing"
 
 	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
@@ -509,3 +510,18 @@ func TestDiff_FieldNotInValidator(t *testing.T) {
 	}
 	t.Fatal("expected FIELD_NOT_IN_VALIDATOR finding")
 }
+
+func TestDiff_HardcodedURI(t *testing.T) {
+	scan := scanner.ScanResult{
+		CredentialURIRefs: []scanner.CredentialURIRef{
+			{User: "app", URI: "mongodb://app:***@db:27017/app", File: "config/db.js", Line: 4},
+		},
+	}
+	findings := Diff(&scan, nil)
+	if len(findings) != 1 || findings[0].Type != FindingHardcodedURI || findings[0].Severity != SeverityHigh {
+		t.Fatalf("expected one high HARDCODED_MONGODB_URI finding, got %+v", findings)
+	}
+	if !strings.Contains(findings[0].Message, "config/db.js:4") || !strings.Contains(findings[0].Message, "app:***@") {
+		t.Errorf("unexpected message %q", findings[0].Message)
+	}
+}
@@ -0,0 +1,129 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+// driverSupport is the first release of a driver that supports a MongoDB
+// server release.
+type driverSupport struct {
+	server string // MongoDB major.minor
+	driver string
+}
+
+// driverCompat lists, oldest server first, when each driver gained support
+// for a server release, following the compatibility tables in the MongoDB
+// driver documentation.
+var driverCompat = map[string][]driverSupport{
+	"go":     {{"4.4", "1.4"}, {"5.0", "1.7"}, {"6.0", "1.10"}, {"7.0", "1.12"}, {"8.0", "1.17"}},
+	"node":   {{"4.4", "3.6"}, {"5.0", "4.1"}, {"6.0", "4.8"}, {"7.0", "5.7"}, {"8.0", "6.8"}},
+	"python": {{"4.4", "3.11"}, {"5.0", "3.12"}, {"6.0", "4.2"}, {"7.0", "4.4"}, {"8.0", "4.9"}},
+	"java":   {{"4.4", "4.1"}, {"5.0", "4.3"}, {"6.0", "4.7"}, {"7.0", "4.10"}, {"8.0", "5.2"}},
+	"csharp": {{"4.4", "2.11"}, {"5.0", "2.13"}, {"6.0", "2.17"}, {"7.0", "2.20"}, {"8.0", "2.28"}},
+	"ruby":   {{"4.4", "2.12"}, {"5.0", "2.15"}, {"6.0", "2.18"}, {"7.0", "2.19"}, {"8.0", "2.20"}},
+	"rust":   {{"4.4", "1.1"}, {"5.0", "2.0"}, {"6.0", "2.3"}, {"7.0", "2.6"}, {"8.0", "3.1"}},
+}
+
+// driverLabels name the drivers of driverCompat in findings.
+var driverLabels = map[string]string{
+	"go":     "Go driver",
+	"node":   "Node.js driver",
+	"python": "PyMongo",
+	"java":   "Java driver",
+	"csharp": "C# driver",
+	"ruby":   "Ruby driver",
+	"rust":   "Rust driver",
+}
+
+// handshakeDrivers maps the lowercased driver name a client sends when it
+// connects to its driverCompat key. Libraries wrapping a driver append
+// their own name after "|", as in "nodejs|Mongoose"; only the first part
+// is looked up.
+var handshakeDrivers = map[string]string{
+	"mongo-go-driver":     "go",
+	"nodejs":              "node",
+	"pymongo":             "python",
+	"mongo-java-driver":   "java",
+	"mongo-csharp-driver": "csharp",
+	"mongo-ruby-driver":   "ruby",
+	"mongo-rust-driver":   "rust",
+}
+
+// driverEOL is, per driver, the oldest release line MongoDB still
+// maintains. Releases below it get no fixes.
+var driverEOL = map[string]string{
+	"go":     "1.0",
+	"node":   "4.0",
+	"python": "4.0",
+	"java":   "4.0",
+	"csharp": "2.0",
+	"ruby":   "2.0",
+	"rust":   "1.0",
+}
+
+// packageDrivers maps the dependency names of scanner.DriverRef to their
+// driverCompat key.
+var packageDrivers = map[string]string{
+	"go.mongodb.org/mongo-driver":    "go",
+	"go.mongodb.org/mongo-driver/v2": "go",
+	"mongodb":                        "node",
+	"pymongo":                        "python",
+}
+
+// DetectDriverCompat checks the driver versions the repo's manifests
+// declare against the server. A version older than the first release
+// supporting the server's major version is medium: it was not tested
+// against the server and may not know its features or error codes. A
+// version from a release line that is end of life is high, whatever the
+// server. Ranges are checked by their lower bound.
+func DetectDriverCompat(scan *scanner.ScanResult, serverVersion string) []Finding {
+	var findings []Finding
+	for _, ref := range scan.DriverRefs {
+		key := packageDrivers[ref.Package]
+		if key == "" || normalizeVersion(ref.Version) == "" {
+			continue
+		}
+		var problems []string
+		severity := SeverityMedium
+		if eol := driverEOL[key]; compareVersion(ref.Version, eol) < 0 {
+			severity = SeverityHigh
+			problems = append(problems, fmt.Sprintf("releases before %s are end of life", eol))
+		}
+		if minVersion, release := minDriverVersion(key, serverVersion); minVersion != "" && compareVersion(ref.Version, minVersion) < 0 {
+			problems = append(problems, fmt.Sprintf("MongoDB %s is supported from %s", release, minVersion))
+		}
+		if len(problems) == 0 {
+			continue
+		}
+		findings = append(findings, Finding{
+			Type:     FindingDriverServerCompat,
+			Severity: severity,
+			Message: fmt.Sprintf("%s %s (%s): %s — upgrade the driver",
+				driverLabels[key], ref.Version, ref.Package, strings.Join(problems, "; ")),
+			File: ref.File,
+			Line: ref.Line,
+		})
+	}
+	return findings
+}
+
+// minDriverVersion returns the first release of driver that supports
+// serverVersion and the server release it was matched against. Servers
+// newer than the table are matched against its newest release; for older
+// or unparsable versions both results are empty.
+func minDriverVersion(driver, serverVersion string) (minVersion, release string) {
+	server := normalizeVersion(serverVersion)
+	if server == "" {
+		return "", ""
+	}
+	rows := driverCompat[driver]
+	for i := len(rows) - 1; i >= 0; i-- {
+		if compareVersion(server, rows[i].server) >= 0 {
+			return rows[i].driver, rows[i].server
+		}
+	}
+	return "", ""
+}
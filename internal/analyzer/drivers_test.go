@@ -0,0 +1,56 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+func TestMinDriverVersion(t *testing.T) {
+	tests := []struct {
+		driver, server, wantMin, wantRelease string
+	}{
+		{"go", "7.0.12", "1.12", "7.0"},
+		{"go", "8.2.1", "1.17", "8.0"},
+		{"node", "6.3.0", "4.8", "6.0"},
+		{"python", "4.2.0", "", ""},
+		{"unknown", "8.0.0", "", ""},
+		{"java", "", "", ""},
+	}
+	for _, tt := range tests {
+		gotMin, gotRelease := minDriverVersion(tt.driver, tt.server)
+		if gotMin != tt.wantMin || gotRelease != tt.wantRelease {
+			t.Errorf("minDriverVersion(%q, %q) = %q, %q; want %q, %q", tt.driver, tt.server, gotMin, gotRelease, tt.wantMin, tt.wantRelease)
+		}
+	}
+}
+
+func TestDetectDriverCompat(t *testing.T) {
+	scan := &scanner.ScanResult{DriverRefs: []scanner.DriverRef{
+		{Package: "go.mongodb.org/mongo-driver", Version: "v1.11.4", File: "go.mod", Line: 7},
+		{Package: "go.mongodb.org/mongo-driver/v2", Version: "v2.1.0", File: "worker/go.mod", Line: 3},
+		{Package: "pymongo", Version: "3.12", File: "jobs/requirements.txt", Line: 2},
+		{Package: "mongodb", Version: "6.8.0", File: "web/package.json", Line: 4},
+		{Package: "mongodb", Version: "latest", File: "api/package.json", Line: 5},
+	}}
+
+	findings := DetectDriverCompat(scan, "7.0.14")
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings, want 2: %+v", len(findings), findings)
+	}
+	if f := findings[0]; f.Type != FindingDriverServerCompat || f.Severity != SeverityMedium || f.File != "go.mod" || f.Line != 7 ||
+		f.Message != "Go driver v1.11.4 (go.mongodb.org/mongo-driver): MongoDB 7.0 is supported from 1.12 — upgrade the driver" {
+		t.Errorf("go finding = %+v", f)
+	}
+	if f := findings[1]; f.Severity != SeverityHigh ||
+		!strings.Contains(f.Message, "releases before 4.0 are end of life; MongoDB 7.0 is supported from 4.4") {
+		t.Errorf("pymongo finding = %+v", f)
+	}
+
+	// End of life is reported whatever the server version.
+	findings = DetectDriverCompat(scan, "4.2.0")
+	if len(findings) != 1 || findings[0].Severity != SeverityHigh || strings.Contains(findings[0].Message, "supported from") {
+		t.Errorf("findings for 4.2 = %+v", findings)
+	}
+}
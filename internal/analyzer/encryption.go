@@ -0,0 +1,241 @@
+package analyzer
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+// detectEncryptionSchemaDrift compares client-side encryption schemas found
+// in code with the live collection configuration. encryptedFieldsMap entries
+// are checked against the collection's encryptedFields option; schemaMap
+// entries against encrypt keywords in the server-side $jsonSchema validator.
+// Namespaces not present in the inspected collections are skipped.
+func detectEncryptionSchemaDrift(scan *scanner.ScanResult, collections []mongoinspect.CollectionInfo) []Finding {
+	var findings []Finding
+	for _, ref := range scan.EncryptedFieldRefs {
+		coll, found := findNamespace(ref.Database, ref.Collection, collections)
+		if !found || coll.Type == "view" {
+			continue
+		}
+		where := fmt.Sprintf("%s:%d", ref.File, ref.Line)
+		newFinding := func(sev Severity, format string, args ...any) Finding {
+			return Finding{
+				Type:       FindingCSFLESchemaDrift,
+				Severity:   sev,
+				Database:   coll.Database,
+				Collection: coll.Name,
+				Message:    fmt.Sprintf(format, args...),
+				File:       ref.File,
+				Line:       ref.Line,
+			}
+		}
+
+		var live []string
+		if ref.Kind == scanner.EncryptionQueryable {
+			live = coll.EncryptedFields
+			if len(live) == 0 {
+				findings = append(findings, newFinding(SeverityMedium,
+					"encryptedFieldsMap at %s encrypts %s but the collection was not created with encryptedFields",
+					where, strings.Join(quoteAll(ref.Fields), ", ")))
+				continue
+			}
+		} else {
+			live = validatorEncryptedFields(coll.Validator)
+			if len(live) == 0 {
+				findings = append(findings, newFinding(SeverityLow,
+					"schemaMap at %s encrypts %s but the server-side $jsonSchema has no encrypt rules; clients without the schemaMap can write plaintext",
+					where, strings.Join(quoteAll(ref.Fields), ", ")))
+				continue
+			}
+		}
+
+		codeOnly, liveOnly := diffStringSets(ref.Fields, live)
+		if len(codeOnly) > 0 {
+			findings = append(findings, newFinding(SeverityMedium,
+				"%s at %s encrypts %s, not configured on the collection",
+				encryptionOption(ref.Kind), where, strings.Join(quoteAll(codeOnly), ", ")))
+		}
+		if len(liveOnly) > 0 {
+			findings = append(findings, newFinding(SeverityMedium,
+				"collection encrypts %s, missing from %s at %s",
+				strings.Join(quoteAll(liveOnly), ", "), encryptionOption(ref.Kind), where))
+		}
+	}
+	return findings
+}
+
+// qeRangeOperators are the range operators Queryable Encryption only
+// supports on fields with a "range" query type.
+var qeRangeOperators = map[string]bool{"gt": true, "gte": true, "lt": true, "lte": true}
+
+// qeRangeQuery reports whether queries include a range query type:
+// "range", or "rangePreview" on MongoDB 7.0.
+func qeRangeQuery(queries []string) bool {
+	return slices.Contains(queries, "range") || slices.Contains(queries, "rangePreview")
+}
+
+// detectQEUnsupportedQueries flags queries in code that Queryable
+// Encryption cannot run on an encrypted field: range operators on a field
+// encrypted for equality only, $regex on any encrypted field, sorts on
+// encrypted fields, and filters on fields encrypted without a query type.
+// The automatic encryption library rejects these before they reach the
+// server. Query types come from the collection's encryptedFields option,
+// or from encryptedFieldsMap in code when the collection is not inspected.
+// Update documents are skipped, since their values are written, not matched.
+// Field references in code carry no database, so a query on a collection
+// name encrypted in several databases is checked against each of them.
+func detectQEUnsupportedQueries(scan *scanner.ScanResult, collections []mongoinspect.CollectionInfo) []Finding {
+	type qeNamespace struct {
+		database, collection string
+		fields               map[string][]string // encrypted path → query types
+	}
+	// "database.collection", lowercased → encrypted fields.
+	encrypted := make(map[string]*qeNamespace)
+	for _, c := range collections {
+		for _, f := range c.EncryptedFields {
+			key := strings.ToLower(c.Database + "." + c.Name)
+			if encrypted[key] == nil {
+				encrypted[key] = &qeNamespace{database: c.Database, collection: c.Name, fields: make(map[string][]string)}
+			}
+			encrypted[key].fields[f] = c.EncryptedQueries[f]
+		}
+	}
+	for _, ref := range scan.EncryptedFieldRefs {
+		key := strings.ToLower(ref.Database + "." + ref.Collection)
+		if ref.Kind != scanner.EncryptionQueryable || encrypted[key] != nil {
+			continue
+		}
+		if _, found := findNamespace(ref.Database, ref.Collection, collections); found {
+			continue // the live collection is not encrypted; CSFLE_SCHEMA_DRIFT reports it
+		}
+		ns := &qeNamespace{database: ref.Database, collection: ref.Collection, fields: make(map[string][]string)}
+		for _, f := range ref.Fields {
+			ns.fields[f] = ref.Queries[f]
+		}
+		encrypted[key] = ns
+	}
+	if len(encrypted) == 0 {
+		return nil
+	}
+	// Collection name, lowercased → its encrypted namespaces, by database.
+	byName := make(map[string][]*qeNamespace)
+	keys := make([]string, 0, len(encrypted))
+	for key := range encrypted {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		name := strings.ToLower(encrypted[key].collection)
+		byName[name] = append(byName[name], encrypted[key])
+	}
+
+	var findings []Finding
+	for _, ref := range scan.FieldRefs {
+		for _, ns := range byName[strings.ToLower(ref.Collection)] {
+			if f, ok := qeUnsupportedQuery(ref, ns.database, ns.fields); ok {
+				findings = append(findings, f)
+			}
+		}
+	}
+	return findings
+}
+
+// qeUnsupportedQuery checks one field reference against the encrypted
+// fields of a namespace in database.
+func qeUnsupportedQuery(ref scanner.FieldRef, database string, fields map[string][]string) (Finding, bool) {
+	queries, ok := fields[ref.Field]
+	if !ok {
+		return Finding{}, false
+	}
+	update := strings.Contains(ref.QueryContext, "update")
+	var problem string
+	switch {
+	case slices.Contains(ref.Operators, "regex"):
+		problem = "$regex cannot match encrypted values"
+	case slices.ContainsFunc(ref.Operators, func(op string) bool { return qeRangeOperators[op] }) && !qeRangeQuery(queries):
+		problem = "range operators need a \"range\" query type"
+	case update:
+		return Finding{}, false
+	case ref.Usage == scanner.FieldUsageSort:
+		problem = "encrypted fields cannot be sorted on"
+	case len(queries) == 0 && (ref.Usage == scanner.FieldUsageEquality || ref.Usage == scanner.FieldUsageRange):
+		problem = "the field has no query type and cannot be queried"
+	default:
+		return Finding{}, false
+	}
+	queryTypes := "no queries"
+	if len(queries) > 0 {
+		queryTypes = strings.Join(queries, ", ") + " queries"
+	}
+	return Finding{
+		Type:       FindingQEUnsupportedQuery,
+		Severity:   SeverityHigh,
+		Database:   database,
+		Collection: ref.Collection,
+		Message: fmt.Sprintf("query at %s:%d on %q, encrypted with Queryable Encryption for %s: %s",
+			ref.File, ref.Line, ref.Field, queryTypes, problem),
+		File: ref.File,
+		Line: ref.Line,
+	}, true
+}
+
+func encryptionOption(kind scanner.EncryptionKind) string {
+	if kind == scanner.EncryptionQueryable {
+		return "encryptedFieldsMap"
+	}
+	return "schemaMap"
+}
+
+// validatorEncryptedFields returns the sorted top-level validator properties
+// carrying an encrypt keyword.
+func validatorEncryptedFields(v *mongoinspect.ValidatorInfo) []string {
+	if v == nil {
+		return nil
+	}
+	var fields []string
+	for name, f := range v.Schema.Properties {
+		if f.Encrypted {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// diffStringSets returns the members only in a and only in b, sorted.
+func diffStringSets(a, b []string) (onlyA, onlyB []string) {
+	inA := make(map[string]bool, len(a))
+	for _, s := range a {
+		inA[s] = true
+	}
+	inB := make(map[string]bool, len(b))
+	for _, s := range b {
+		inB[s] = true
+		if !inA[s] {
+			onlyB = append(onlyB, s)
+		}
+	}
+	for _, s := range a {
+		if !inB[s] {
+			onlyA = append(onlyA, s)
+		}
+	}
+	sort.Strings(onlyA)
+	sort.Strings(onlyB)
+	return onlyA, onlyB
+}
+
+// findNamespace looks up a collection by database and name, case-insensitively.
+func findNamespace(database, name string, collections []mongoinspect.CollectionInfo) (mongoinspect.CollectionInfo, bool) {
+	for _, c := range collections {
+		if strings.EqualFold(c.Database, database) && strings.EqualFold(c.Name, name) {
+			return c, true
+		}
+	}
+	return mongoinspect.CollectionInfo{}, false
+}
@@ -0,0 +1,159 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+func driftFindings(findings []Finding) []Finding {
+	var out []Finding
+	for _, f := range findings {
+		if f.Type == FindingCSFLESchemaDrift {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func TestDiff_QueryableEncryptionDrift(t *testing.T) {
+	scan := scanner.ScanResult{
+		EncryptedFieldRefs: []scanner.EncryptedFieldRef{
+			{Database: "medical", Collection: "patients", Fields: []string{"dob", "ssn"}, Kind: scanner.EncryptionQueryable, File: "db.js", Line: 5},
+		},
+	}
+	patients := collInfo("patients", "medical", 10)
+	patients.EncryptedFields = []string{"billing.card", "ssn"}
+
+	got := driftFindings(Diff(&scan, []mongoinspect.CollectionInfo{patients}))
+	if len(got) != 2 {
+		t.Fatalf("expected 2 drift findings, got %+v", got)
+	}
+	if !strings.Contains(got[0].Message, `"dob", not configured`) || got[0].Severity != SeverityMedium {
+		t.Errorf("unexpected code-only finding %+v", got[0])
+	}
+	if !strings.Contains(got[1].Message, `collection encrypts "billing.card"`) || !strings.Contains(got[1].Message, "db.js:5") {
+		t.Errorf("unexpected live-only finding %+v", got[1])
+	}
+}
+
+func TestDiff_QueryableEncryptionNotConfigured(t *testing.T) {
+	scan := scanner.ScanResult{
+		EncryptedFieldRefs: []scanner.EncryptedFieldRef{
+			{Database: "medical", Collection: "patients", Fields: []string{"ssn"}, Kind: scanner.EncryptionQueryable, File: "db.js", Line: 5},
+			{Database: "medical", Collection: "absent", Fields: []string{"ssn"}, Kind: scanner.EncryptionQueryable, File: "db.js", Line: 9},
+		},
+	}
+	got := driftFindings(Diff(&scan, []mongoinspect.CollectionInfo{collInfo("patients", "medical", 10)}))
+	if len(got) != 1 || !strings.Contains(got[0].Message, "not created with encryptedFields") {
+		t.Fatalf("expected one not-configured finding, got %+v", got)
+	}
+}
+
+func TestDiff_CSFLESchemaMap(t *testing.T) {
+	ref := scanner.EncryptedFieldRef{Database: "hr", Collection: "employees", Fields: []string{"salary"}, Kind: scanner.EncryptionCSFLE, File: "app.py", Line: 2}
+	scan := scanner.ScanResult{EncryptedFieldRefs: []scanner.EncryptedFieldRef{ref}}
+
+	employees := collInfo("employees", "hr", 10)
+	got := driftFindings(Diff(&scan, []mongoinspect.CollectionInfo{employees}))
+	if len(got) != 1 || got[0].Severity != SeverityLow {
+		t.Fatalf("expected low finding without server-side schema, got %+v", got)
+	}
+
+	employees.Validator = &mongoinspect.ValidatorInfo{Schema: mongoinspect.ValidatorSchema{
+		Properties: map[string]mongoinspect.ValidatorField{
+			"salary": {Encrypted: true},
+			"name":   {BSONTypes: []string{"string"}},
+		},
+	}}
+	if got := driftFindings(Diff(&scan, []mongoinspect.CollectionInfo{employees})); len(got) != 0 {
+		t.Errorf("expected no drift for matching schema, got %+v", got)
+	}
+
+	employees.Validator.Schema.Properties["bonus"] = mongoinspect.ValidatorField{Encrypted: true}
+	got = driftFindings(Diff(&scan, []mongoinspect.CollectionInfo{employees}))
+	if len(got) != 1 || !strings.Contains(got[0].Message, `"bonus", missing from schemaMap`) {
+		t.Errorf("expected live-only drift for bonus, got %+v", got)
+	}
+}
+
+func TestDiff_QEUnsupportedQueries(t *testing.T) {
+	patients := collInfo("patients", "medical", 10)
+	patients.EncryptedFields = []string{"dob", "notes", "ssn"}
+	patients.EncryptedQueries = map[string][]string{"ssn": {"equality"}, "dob": {"range"}}
+	scan := scanner.ScanResult{
+		FieldRefs: []scanner.FieldRef{
+			{Collection: "patients", Field: "ssn", Usage: scanner.FieldUsageRange, Operators: []string{"gt"}, QueryContext: "find", File: "a.js", Line: 3},
+			{Collection: "patients", Field: "ssn", Usage: scanner.FieldUsageEquality, QueryContext: "find", File: "a.js", Line: 4},
+			{Collection: "patients", Field: "dob", Usage: scanner.FieldUsageRange, Operators: []string{"gte", "lt"}, QueryContext: "find", File: "a.js", Line: 5},
+			{Collection: "patients", Field: "notes", Usage: scanner.FieldUsageEquality, QueryContext: "find", File: "a.js", Line: 6},
+			{Collection: "patients", Field: "notes", Usage: scanner.FieldUsageEquality, QueryContext: "updateone", File: "a.js", Line: 7},
+			{Collection: "patients", Field: "dob", Usage: scanner.FieldUsageSort, Direction: -1, QueryContext: "sort", File: "a.js", Line: 8},
+		},
+	}
+
+	var got []Finding
+	for _, f := range Diff(&scan, []mongoinspect.CollectionInfo{patients}) {
+		if f.Type == FindingQEUnsupportedQuery {
+			got = append(got, f)
+		}
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 findings, got %+v", got)
+	}
+	if got[0].Line != 3 || !strings.Contains(got[0].Message, `"ssn", encrypted with Queryable Encryption for equality queries: range operators need a "range" query type`) {
+		t.Errorf("unexpected range finding %+v", got[0])
+	}
+	if got[1].Line != 6 || !strings.Contains(got[1].Message, "no queries: the field has no query type") {
+		t.Errorf("unexpected unqueryable finding %+v", got[1])
+	}
+	if got[2].Line != 8 || !strings.Contains(got[2].Message, "cannot be sorted on") {
+		t.Errorf("unexpected sort finding %+v", got[2])
+	}
+}
+
+func TestDiff_QEUnsupportedQueriesPerDatabase(t *testing.T) {
+	// The same collection name is encrypted differently in two databases,
+	// and 7.0 deployments report the range query type as rangePreview.
+	clinic := collInfo("patients", "clinic", 10)
+	clinic.EncryptedFields = []string{"dob"}
+	clinic.EncryptedQueries = map[string][]string{"dob": {"rangePreview"}}
+	lab := collInfo("patients", "lab", 10)
+	lab.EncryptedFields = []string{"dob"}
+	lab.EncryptedQueries = map[string][]string{"dob": {"equality"}}
+	scan := scanner.ScanResult{FieldRefs: []scanner.FieldRef{
+		{Collection: "patients", Field: "dob", Usage: scanner.FieldUsageRange, Operators: []string{"gte"}, QueryContext: "find", File: "a.js", Line: 3},
+	}}
+
+	var got []Finding
+	for _, f := range Diff(&scan, []mongoinspect.CollectionInfo{clinic, lab}) {
+		if f.Type == FindingQEUnsupportedQuery {
+			got = append(got, f)
+		}
+	}
+	if len(got) != 1 || got[0].Database != "lab" || !strings.Contains(got[0].Message, "for equality queries") {
+		t.Fatalf("expected one finding for lab.patients, got %+v", got)
+	}
+}
+
+func TestDiff_QEUnsupportedQueriesFromCode(t *testing.T) {
+	scan := scanner.ScanResult{
+		EncryptedFieldRefs: []scanner.EncryptedFieldRef{
+			{Database: "medical", Collection: "patients", Fields: []string{"ssn"}, Queries: map[string][]string{"ssn": {"equality"}}, Kind: scanner.EncryptionQueryable, File: "db.js", Line: 5},
+		},
+		FieldRefs: []scanner.FieldRef{
+			{Collection: "patients", Field: "ssn", Usage: scanner.FieldUsageRange, Operators: []string{"regex"}, QueryContext: "find", File: "a.js", Line: 9},
+		},
+	}
+	for _, f := range Diff(&scan, nil) {
+		if f.Type == FindingQEUnsupportedQuery {
+			if f.Database != "medical" || !strings.Contains(f.Message, "$regex cannot match encrypted values") {
+				t.Errorf("unexpected finding %+v", f)
+			}
+			return
+		}
+	}
+	t.Fatal("expected QE_UNSUPPORTED_QUERY")
+}
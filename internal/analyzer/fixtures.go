@@ -0,0 +1,118 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+// DetectFixtureDrift compares the seed data in the repo's fixture files
+// with the live collections. A fixture for a collection that neither the
+// database nor the code knows is FIXTURE_STALE_COLLECTION: the collection
+// was dropped or renamed and the seed was left behind. It is info when the
+// documents carry no _id or Extended JSON, as the file may be other JSON
+// that only sits in a fixture directory. Collections the code references
+// are left to MISSING_COLLECTION. Against a JSON schema
+// validator, fixtures missing required fields, setting fields the
+// validator does not allow, or holding values of another type are
+// FIXTURE_SCHEMA_DRIFT, high when the validator rejects such writes.
+func DetectFixtureDrift(scan *scanner.ScanResult, collections []mongoinspect.CollectionInfo) []Finding {
+	if len(scan.FixtureRefs) == 0 {
+		return nil
+	}
+	inCode := make(map[string]bool)
+	for _, ref := range scan.Refs {
+		inCode[strings.ToLower(ref.Collection)] = true
+	}
+
+	var findings []Finding
+	for _, ref := range scan.FixtureRefs {
+		coll, found := findCollection(ref.Collection, collections)
+		if !found {
+			if inCode[strings.ToLower(ref.Collection)] {
+				continue
+			}
+			f := Finding{
+				Type:       FindingFixtureStaleCollection,
+				Severity:   SeverityMedium,
+				Collection: ref.Collection,
+				Message: fmt.Sprintf("fixture seeds %d documents into %q, which is not in the database and not referenced in code; it was likely dropped or renamed",
+					ref.Docs, ref.Collection),
+				File: ref.File,
+				Line: ref.Line,
+			}
+			if !ref.MongoData {
+				f.Severity = SeverityInfo
+				f.Message = fmt.Sprintf("file holds %d documents for %q, which is not in the database and not referenced in code; the documents carry no _id or Extended JSON, so it may not be seed data",
+					ref.Docs, ref.Collection)
+			}
+			findings = append(findings, f)
+			continue
+		}
+		if coll.Type == "view" || coll.Validator == nil {
+			continue
+		}
+		problems := fixtureSchemaProblems(ref, coll.Validator.Schema)
+		if len(problems) == 0 {
+			continue
+		}
+		severity := SeverityMedium
+		action := strings.ToLower(coll.Validator.ValidationAction)
+		if (action == "" || action == "error") && !strings.EqualFold(coll.Validator.ValidationLevel, "off") {
+			severity = SeverityHigh
+		}
+		findings = append(findings, Finding{
+			Type:       FindingFixtureSchemaDrift,
+			Severity:   severity,
+			Database:   coll.Database,
+			Collection: coll.Name,
+			Message:    fmt.Sprintf("fixture documents do not match the collection validator: %s", strings.Join(problems, "; ")),
+			File:       ref.File,
+			Line:       ref.Line,
+		})
+	}
+	return findings
+}
+
+// fixtureSchemaProblems lists where a fixture's documents break schema.
+func fixtureSchemaProblems(ref scanner.FixtureRef, schema mongoinspect.ValidatorSchema) []string {
+	fields := make(map[string]scanner.FixtureField, len(ref.Fields))
+	for _, f := range ref.Fields {
+		fields[f.Name] = f
+	}
+
+	var problems []string
+	for _, name := range schema.Required {
+		if f := fields[name]; f.Count < ref.Docs {
+			problems = append(problems, fmt.Sprintf("required field %q is missing in %d of %d documents", name, ref.Docs-f.Count, ref.Docs))
+		}
+	}
+	additionalPropsFalse := schema.AdditionalProperties != nil && !*schema.AdditionalProperties
+	for _, f := range ref.Fields {
+		if f.Name == "_id" {
+			continue
+		}
+		prop, ok := schema.Properties[f.Name]
+		if !ok {
+			if additionalPropsFalse {
+				problems = append(problems, fmt.Sprintf("field %q is not in the validator properties (additionalProperties=false)", f.Name))
+			}
+			continue
+		}
+		allowed := normalizeAllowedTypes(prop.BSONTypes)
+		if len(allowed) == 0 {
+			continue
+		}
+		observed := make(map[string]bool, len(f.Types))
+		for _, t := range f.Types {
+			observed[t] = true
+		}
+		if mismatched := mismatchedObservedTypes(observed, allowed); len(mismatched) > 0 {
+			problems = append(problems, fmt.Sprintf("field %q is [%s], the validator expects [%s]",
+				f.Name, strings.Join(mismatched, ", "), strings.Join(mapKeysSorted(allowed), ", ")))
+		}
+	}
+	return problems
+}
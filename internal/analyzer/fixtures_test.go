@@ -0,0 +1,67 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+func TestDetectFixtureDrift(t *testing.T) {
+	noExtra := false
+	scan := &scanner.ScanResult{
+		Refs: []scanner.CollectionRef{{Collection: "invoices", File: "billing.js", Line: 4}},
+		FixtureRefs: []scanner.FixtureRef{
+			{Collection: "users", Docs: 3, File: "fixtures/users.json", Line: 1, Fields: []scanner.FixtureField{
+				{Name: "_id", Types: []string{"objectId"}, Count: 3},
+				{Name: "email", Types: []string{"string"}, Count: 2},
+				{Name: "age", Types: []string{"number", "string"}, Count: 3},
+				{Name: "nickname", Types: []string{"string"}, Count: 1},
+			}},
+			{Collection: "legacy_sessions", Docs: 2, MongoData: true, File: "seeds/seed.json", Line: 5},
+			{Collection: "invoices", Docs: 1, File: "seeds/seed.json", Line: 9},
+			{Collection: "orders", Docs: 1, File: "seeds/seed.json", Line: 12, Fields: []scanner.FixtureField{{Name: "total", Types: []string{"string"}, Count: 1}}},
+			{Collection: "locales", Docs: 4, File: "fixtures/locales.json", Line: 1},
+		},
+	}
+	collections := []mongoinspect.CollectionInfo{
+		{Database: "app", Name: "users", Validator: &mongoinspect.ValidatorInfo{Schema: mongoinspect.ValidatorSchema{
+			Required:             []string{"email", "age"},
+			AdditionalProperties: &noExtra,
+			Properties: map[string]mongoinspect.ValidatorField{
+				"email": {BSONTypes: []string{"string"}},
+				"age":   {BSONTypes: []string{"int", "long"}},
+			},
+		}}},
+		{Database: "app", Name: "orders", Validator: &mongoinspect.ValidatorInfo{
+			ValidationAction: "warn",
+			Schema:           mongoinspect.ValidatorSchema{Properties: map[string]mongoinspect.ValidatorField{"total": {BSONTypes: []string{"decimal"}}}},
+		}},
+	}
+
+	findings := DetectFixtureDrift(scan, collections)
+	if len(findings) != 4 {
+		t.Fatalf("got %d findings, want 4: %+v", len(findings), findings)
+	}
+	if f := findings[0]; f.Type != FindingFixtureSchemaDrift || f.Severity != SeverityHigh || f.Database != "app" || f.File != "fixtures/users.json" ||
+		f.Message != `fixture documents do not match the collection validator: required field "email" is missing in 1 of 3 documents; `+
+			`field "age" is [string], the validator expects [number]; field "nickname" is not in the validator properties (additionalProperties=false)` {
+		t.Errorf("users finding = %+v", f)
+	}
+	if f := findings[1]; f.Type != FindingFixtureStaleCollection || f.Severity != SeverityMedium || f.Collection != "legacy_sessions" || f.Line != 5 ||
+		!strings.Contains(f.Message, "seeds 2 documents") {
+		t.Errorf("stale finding = %+v", f)
+	}
+	if f := findings[2]; f.Type != FindingFixtureSchemaDrift || f.Severity != SeverityMedium || f.Collection != "orders" {
+		t.Errorf("orders finding = %+v", f)
+	}
+	// Documents without _id or Extended JSON may not be seed data.
+	if f := findings[3]; f.Type != FindingFixtureStaleCollection || f.Severity != SeverityInfo || f.Collection != "locales" ||
+		!strings.Contains(f.Message, "may not be seed data") {
+		t.Errorf("non-MongoDB data finding = %+v", f)
+	}
+	if got := DetectFixtureDrift(&scanner.ScanResult{}, collections); got != nil {
+		t.Errorf("expected no findings without fixtures, got %+v", got)
+	}
+}
@@ -0,0 +1,87 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const forecastUrgentDays = 30 // ETAs within this many days are high severity
+
+// StorageLimits configures capacity forecasting. A zero limit disables the
+// corresponding check.
+type StorageLimits struct {
+	Collection int64         // per-collection data size limit in bytes
+	Cluster    int64         // cluster disk limit in bytes (storage + indexes)
+	Horizon    time.Duration // only report limits reached within this window
+}
+
+// ForecastStorage extrapolates the linear growth rate of each collection and
+// of the cluster as a whole from the trend window, and returns
+// STORAGE_FORECAST_EXCEEDED findings for limits that are already exceeded or
+// will be reached within the horizon. ETAs are measured from the newest snapshot.
+func ForecastStorage(t *Trend, limits StorageLimits) []Finding {
+	if t.Snapshots < 2 {
+		return nil
+	}
+
+	var findings []Finding
+	if limits.Collection > 0 {
+		for i := range t.Collections {
+			ct := &t.Collections[i]
+			current := ct.Points[len(ct.Points)-1].Size
+			if f, ok := forecastFinding(current, ct.GrowthPerDay, limits.Collection, limits.Horizon, t.To, "data size"); ok {
+				f.Database = ct.Database
+				f.Collection = ct.Collection
+				findings = append(findings, f)
+			}
+		}
+	}
+
+	if limits.Cluster > 0 && len(t.Cluster) >= 2 {
+		first, last := t.Cluster[0], t.Cluster[len(t.Cluster)-1]
+		days := last.Timestamp.Sub(first.Timestamp).Hours() / 24
+		if days > 0 {
+			current := last.StorageSize + last.TotalIndexSize
+			rate := float64(current-(first.StorageSize+first.TotalIndexSize)) / days
+			if f, ok := forecastFinding(current, rate, limits.Cluster, limits.Horizon, t.To, "cluster disk usage"); ok {
+				findings = append(findings, f)
+			}
+		}
+	}
+	return findings
+}
+
+func forecastFinding(current int64, perDay float64, limit int64, horizon time.Duration, from time.Time, what string) (Finding, bool) {
+	if current >= limit {
+		return Finding{
+			Type:     FindingStorageForecast,
+			Severity: SeverityHigh,
+			Message:  fmt.Sprintf("%s %s already exceeds the %s limit", what, formatBytes(current), formatBytes(limit)),
+		}, true
+	}
+	if perDay <= 0 {
+		return Finding{}, false
+	}
+	// Compare in days before converting: slow growth gives ETAs beyond
+	// what a time.Duration holds (~292 years), which would overflow.
+	days := float64(limit-current) / perDay
+	if horizon > 0 && days > horizon.Hours()/24 {
+		return Finding{}, false
+	}
+	if days*24 >= float64(math.MaxInt64)/float64(time.Hour) {
+		return Finding{}, false
+	}
+	eta := time.Duration(days * 24 * float64(time.Hour))
+
+	severity := SeverityMedium
+	if days <= forecastUrgentDays {
+		severity = SeverityHigh
+	}
+	return Finding{
+		Type:     FindingStorageForecast,
+		Severity: severity,
+		Message: fmt.Sprintf("%s %s growing %s/day will reach the %s limit in ~%.0f days (around %s)",
+			what, formatBytes(current), formatBytes(int64(perDay)), formatBytes(limit), days, from.Add(eta).UTC().Format("2006-01-02")),
+	}, true
+}
@@ -0,0 +1,88 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+func forecastTrend(storage ...int64) Trend {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var snaps []TrendSnapshot
+	for i, s := range storage {
+		snaps = append(snaps, TrendSnapshot{
+			Timestamp: start.Add(time.Duration(i) * 24 * time.Hour),
+			Collections: []mongoinspect.CollectionInfo{
+				{Database: "app", Name: "events", Size: s, StorageSize: s, TotalIndexSize: s / 4},
+			},
+		})
+	}
+	return AnalyzeTrend(snaps)
+}
+
+func TestForecastStorage_CollectionETA(t *testing.T) {
+	// 100 MB/day growth from 1000 MB; limit 2000 MB → ~10 days.
+	trend := forecastTrend(900<<20, 1000<<20)
+	findings := ForecastStorage(&trend, StorageLimits{Collection: 2000 << 20, Horizon: 90 * 24 * time.Hour})
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %+v", findings)
+	}
+	f := findings[0]
+	if f.Type != FindingStorageForecast || f.Severity != SeverityHigh || f.Collection != "events" {
+		t.Fatalf("unexpected finding: %+v", f)
+	}
+	if !strings.Contains(f.Message, "~10 days (around 2026-01-12)") {
+		t.Errorf("unexpected message: %s", f.Message)
+	}
+}
+
+func TestForecastStorage_BeyondHorizonAndShrinking(t *testing.T) {
+	trend := forecastTrend(900<<20, 1000<<20)
+	if got := ForecastStorage(&trend, StorageLimits{Collection: 100 << 30, Horizon: 30 * 24 * time.Hour}); len(got) != 0 {
+		t.Fatalf("expected no finding beyond horizon, got %+v", got)
+	}
+	shrinking := forecastTrend(1000<<20, 900<<20)
+	if got := ForecastStorage(&shrinking, StorageLimits{Collection: 2000 << 20}); len(got) != 0 {
+		t.Fatalf("expected no finding for shrinking collection, got %+v", got)
+	}
+}
+
+func TestForecastStorage_SlowGrowthDoesNotOverflow(t *testing.T) {
+	// 0.5 B/day against 100 GB is ~200 billion days, far beyond a Duration.
+	if _, ok := forecastFinding(1<<30, 0.5, 100<<30, 90*24*time.Hour, time.Now(), "data size"); ok {
+		t.Error("expected no finding beyond the horizon for slow growth")
+	}
+	if _, ok := forecastFinding(1<<30, 0.5, 100<<30, 0, time.Now(), "data size"); ok {
+		t.Error("expected no finding for an ETA beyond any date without a horizon")
+	}
+}
+
+func TestForecastStorage_ClusterAlreadyExceeded(t *testing.T) {
+	trend := forecastTrend(900<<20, 1000<<20)
+	findings := ForecastStorage(&trend, StorageLimits{Cluster: 1 << 30})
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %+v", findings)
+	}
+	f := findings[0]
+	if f.Database != "" || f.Severity != SeverityHigh || !strings.Contains(f.Message, "cluster disk usage") || !strings.Contains(f.Message, "already exceeds") {
+		t.Fatalf("unexpected finding: %+v", f)
+	}
+}
+
+func TestForecastStorage_MediumWhenNotUrgent(t *testing.T) {
+	// 100 MB/day from 1000 MB; limit 6000 MB → ~50 days.
+	trend := forecastTrend(900<<20, 1000<<20)
+	findings := ForecastStorage(&trend, StorageLimits{Collection: 6000 << 20, Horizon: 90 * 24 * time.Hour})
+	if len(findings) != 1 || findings[0].Severity != SeverityMedium {
+		t.Fatalf("expected medium finding, got %+v", findings)
+	}
+}
+
+func TestForecastStorage_NeedsHistory(t *testing.T) {
+	trend := forecastTrend(1000 << 20)
+	if got := ForecastStorage(&trend, StorageLimits{Collection: 1}); got != nil {
+		t.Fatalf("expected nil with one snapshot, got %+v", got)
+	}
+}
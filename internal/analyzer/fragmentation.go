@@ -0,0 +1,47 @@
+package analyzer
+
+import (
+	"fmt"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+const (
+	fragmentMinBytes  = 256 << 20 // less free space is not worth a compact
+	fragmentMinShare  = 0.2       // share of the files on disk that is free
+	fragmentHighShare = 0.5       // medium severity from here
+)
+
+// detectFragmentation flags collections whose WiredTiger files hold much
+// free space: after large deletes the storage engine keeps the blocks for
+// reuse instead of returning them to the OS. compact, run on each member,
+// or an initial sync of a member rewrites the files without them. The
+// message includes the compression ratio, since a low one makes the free
+// space a larger share of the data's true size.
+func detectFragmentation(c *mongoinspect.CollectionInfo) []Finding {
+	free := c.ReusableBytes + c.IndexReusableBytes
+	onDisk := c.StorageSize + c.TotalIndexSize
+	if free < fragmentMinBytes || onDisk <= 0 {
+		return nil
+	}
+	share := float64(free) / float64(onDisk)
+	if share < fragmentMinShare {
+		return nil
+	}
+	sev := SeverityLow
+	if share >= fragmentHighShare {
+		sev = SeverityMedium
+	}
+	ratio := ""
+	if c.StorageSize > 0 && c.Size > 0 {
+		ratio = fmt.Sprintf(", data compresses %.1f:1", float64(c.Size)/float64(c.StorageSize))
+	}
+	return []Finding{{
+		Type:       FindingFragmentedCollection,
+		Severity:   sev,
+		Database:   c.Database,
+		Collection: c.Name,
+		Message: fmt.Sprintf("%s of %s on disk is free space WiredTiger keeps for reuse (%.0f%%%s) — run compact on each member, or resync members with an initial sync, to reclaim about %s",
+			formatBytes(free), formatBytes(onDisk), share*100, ratio, formatBytes(free)),
+	}}
+}
@@ -0,0 +1,43 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+func TestDetectFragmentation(t *testing.T) {
+	const gb = 1 << 30
+	tests := []struct {
+		name string
+		coll mongoinspect.CollectionInfo
+		want Severity // "" for no finding
+	}{
+		{"mostly free", mongoinspect.CollectionInfo{Size: 3 * gb, StorageSize: 4 * gb, TotalIndexSize: gb, ReusableBytes: 2 * gb, IndexReusableBytes: gb / 2}, SeverityMedium},
+		{"some free", mongoinspect.CollectionInfo{Size: 8 * gb, StorageSize: 4 * gb, ReusableBytes: gb}, SeverityLow},
+		{"small share", mongoinspect.CollectionInfo{StorageSize: 10 * gb, ReusableBytes: gb}, ""},
+		{"small collection", mongoinspect.CollectionInfo{StorageSize: 100 << 20, ReusableBytes: 80 << 20}, ""},
+		{"no stats", mongoinspect.CollectionInfo{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.coll.Database, tt.coll.Name = "app", "events"
+			findings := detectFragmentation(&tt.coll)
+			if tt.want == "" {
+				if len(findings) != 0 {
+					t.Errorf("unexpected findings: %+v", findings)
+				}
+				return
+			}
+			if len(findings) != 1 || findings[0].Type != FindingFragmentedCollection || findings[0].Severity != tt.want {
+				t.Fatalf("findings = %+v, want one %s", findings, tt.want)
+			}
+		})
+	}
+
+	f := detectFragmentation(&tests[1].coll)[0]
+	if !strings.Contains(f.Message, "1024.0 MB of 4096.0 MB on disk") || !strings.Contains(f.Message, "(25%, data compresses 2.0:1)") {
+		t.Errorf("message = %q", f.Message)
+	}
+}
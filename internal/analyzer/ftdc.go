@@ -0,0 +1,198 @@
+package analyzer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/ftdc"
+)
+
+const (
+	ftdcCacheFullRatio   = 0.95             // eviction moves to application threads above this fill
+	ftdcCacheDirtyRatio  = 0.20             // application threads stall on dirty eviction above this
+	ftdcCacheSustain     = 5 * time.Minute  // cache pressure shorter than this is a burst, not a finding
+	ftdcTicketSustain    = time.Minute      // queued operations for this long are user-visible
+	ftdcSlowCheckpointMs = 60_000           // checkpoints are scheduled every 60s
+	ftdcSampleGap        = 10 * time.Second // a longer gap between samples (restart, lost file) ends a run
+)
+
+// Metric paths; each list holds the server's current name first, then older ones.
+var (
+	ftdcStartKeys      = []string{"start", "serverStatus.start"}
+	ftdcCacheUsedKeys  = []string{"serverStatus.wiredTiger.cache.bytes currently in the cache"}
+	ftdcCacheMaxKeys   = []string{"serverStatus.wiredTiger.cache.maximum bytes configured"}
+	ftdcCacheDirtyKeys = []string{"serverStatus.wiredTiger.cache.tracked dirty bytes in the cache"}
+	ftdcCheckpointKeys = []string{
+		"serverStatus.wiredTiger.checkpoint.most recent time (msecs)",
+		"serverStatus.wiredTiger.transaction.transaction checkpoint most recent time (msecs)",
+	}
+	ftdcTicketKeys = map[string][2][]string{
+		"read": {
+			{"serverStatus.queues.execution.read.available", "serverStatus.wiredTiger.concurrentTransactions.read.available"},
+			{"serverStatus.queues.execution.read.totalTickets", "serverStatus.wiredTiger.concurrentTransactions.read.totalTickets"},
+		},
+		"write": {
+			{"serverStatus.queues.execution.write.available", "serverStatus.wiredTiger.concurrentTransactions.write.available"},
+			{"serverStatus.queues.execution.write.totalTickets", "serverStatus.wiredTiger.concurrentTransactions.write.totalTickets"},
+		},
+	}
+)
+
+// sustainedRun tracks the longest stretch of consecutive samples for which a
+// condition held, and the peak value seen during it.
+type sustainedRun struct {
+	start, last time.Time
+	active      bool
+	peak        float64
+
+	longest      time.Duration
+	longestStart time.Time
+	longestPeak  float64
+}
+
+func (r *sustainedRun) observe(at time.Time, on bool, value float64) {
+	if r.active && (!on || at.Sub(r.last) > ftdcSampleGap) {
+		r.active = false
+	}
+	if !on {
+		return
+	}
+	if !r.active {
+		r.active, r.start, r.peak = true, at, value
+	}
+	r.last = at
+	r.peak = max(r.peak, value)
+	if d := at.Sub(r.start); d > r.longest || r.longestStart.IsZero() {
+		r.longest, r.longestStart, r.longestPeak = d, r.start, r.peak
+	}
+}
+
+// FTDCSummary accumulates resource signals from FTDC chunks read in
+// chronological order.
+type FTDCSummary struct {
+	Samples  int
+	From, To time.Time
+
+	cacheFull  sustainedRun
+	cacheDirty sustainedRun
+	tickets    map[string]*sustainedRun
+
+	maxCheckpointMs int64
+	maxCheckpointAt time.Time
+	slowCheckpoints int
+	lastCheckpoint  int64
+}
+
+// NewFTDCSummary returns an empty summary.
+func NewFTDCSummary() *FTDCSummary {
+	return &FTDCSummary{tickets: map[string]*sustainedRun{"read": {}, "write": {}}}
+}
+
+// Add folds a chunk's samples into the summary. Chunks without sample
+// timestamps are skipped.
+func (s *FTDCSummary) Add(c *ftdc.Chunk) {
+	starts := ftdcSeries(c, ftdcStartKeys)
+	if starts == nil {
+		return
+	}
+	used, capacity := ftdcSeries(c, ftdcCacheUsedKeys), ftdcSeries(c, ftdcCacheMaxKeys)
+	dirty := ftdcSeries(c, ftdcCacheDirtyKeys)
+	checkpoint := ftdcSeries(c, ftdcCheckpointKeys)
+	type ticketSeries struct{ available, total []int64 }
+	tickets := make(map[string]ticketSeries)
+	for op, keys := range ftdcTicketKeys {
+		tickets[op] = ticketSeries{ftdcSeries(c, keys[0]), ftdcSeries(c, keys[1])}
+	}
+
+	for i := 0; i < c.Samples; i++ {
+		at := time.UnixMilli(starts[i]).UTC()
+		if s.Samples == 0 {
+			s.From = at
+		}
+		s.Samples++
+		s.To = at
+
+		if capacity != nil && capacity[i] > 0 {
+			if used != nil {
+				fill := float64(used[i]) / float64(capacity[i])
+				s.cacheFull.observe(at, fill >= ftdcCacheFullRatio, fill)
+			}
+			if dirty != nil {
+				ratio := float64(dirty[i]) / float64(capacity[i])
+				s.cacheDirty.observe(at, ratio >= ftdcCacheDirtyRatio, ratio)
+			}
+		}
+		for op, ts := range tickets {
+			if ts.available != nil && ts.total != nil && ts.total[i] > 0 {
+				s.tickets[op].observe(at, ts.available[i] <= 0, float64(ts.total[i]))
+			}
+		}
+		// The metric holds the duration of the most recent checkpoint, so a
+		// change marks a newly completed one.
+		if checkpoint != nil && checkpoint[i] != s.lastCheckpoint {
+			s.lastCheckpoint = checkpoint[i]
+			if checkpoint[i] > ftdcSlowCheckpointMs {
+				s.slowCheckpoints++
+			}
+			if checkpoint[i] > s.maxCheckpointMs {
+				s.maxCheckpointMs, s.maxCheckpointAt = checkpoint[i], at
+			}
+		}
+	}
+}
+
+// Findings reports sustained cache pressure (CACHE_PRESSURE), periods
+// without free execution tickets (TICKET_EXHAUSTION) and checkpoints slower
+// than the checkpoint interval (SLOW_CHECKPOINT).
+func (s *FTDCSummary) Findings() []Finding {
+	var findings []Finding
+	if r := s.cacheFull; r.longest >= ftdcCacheSustain {
+		findings = append(findings, Finding{
+			Type:     FindingCachePressure,
+			Severity: SeverityHigh,
+			Message: fmt.Sprintf("WiredTiger cache stayed at least %.0f%% full for %s from %s (peak %.0f%%); application threads evict pages instead of serving operations",
+				ftdcCacheFullRatio*100, r.longest, formatFTDCTime(r.longestStart), r.longestPeak*100),
+		})
+	}
+	if r := s.cacheDirty; r.longest >= ftdcCacheSustain {
+		findings = append(findings, Finding{
+			Type:     FindingCachePressure,
+			Severity: SeverityHigh,
+			Message: fmt.Sprintf("dirty data stayed at least %.0f%% of the WiredTiger cache for %s from %s (peak %.0f%%); writes stall while pages are flushed",
+				ftdcCacheDirtyRatio*100, r.longest, formatFTDCTime(r.longestStart), r.longestPeak*100),
+		})
+	}
+	for _, op := range []string{"read", "write"} {
+		if r := s.tickets[op]; r.longest >= ftdcTicketSustain {
+			findings = append(findings, Finding{
+				Type:     FindingTicketExhaustion,
+				Severity: SeverityHigh,
+				Message: fmt.Sprintf("no %s tickets were available for %s from %s (%d tickets); %s operations queued",
+					op, r.longest, formatFTDCTime(r.longestStart), int64(r.longestPeak), op),
+			})
+		}
+	}
+	if s.slowCheckpoints > 0 {
+		findings = append(findings, Finding{
+			Type:     FindingSlowCheckpoint,
+			Severity: SeverityMedium,
+			Message: fmt.Sprintf("%d checkpoints took longer than %ds, the longest %s at %s; check disk throughput and dirty cache size",
+				s.slowCheckpoints, ftdcSlowCheckpointMs/1000, time.Duration(s.maxCheckpointMs)*time.Millisecond, formatFTDCTime(s.maxCheckpointAt)),
+		})
+	}
+	return findings
+}
+
+// ftdcSeries returns the first of keys present in the chunk.
+func ftdcSeries(c *ftdc.Chunk, keys []string) []int64 {
+	for _, k := range keys {
+		if v := c.Series(k); v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+func formatFTDCTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04 UTC")
+}
@@ -0,0 +1,143 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/ftdc"
+)
+
+// ftdcChunk builds a one-sample-per-second chunk from per-metric generators.
+func ftdcChunk(start time.Time, samples int, metrics map[string]func(i int) int64) *ftdc.Chunk {
+	keys := []string{"start"}
+	values := [][]int64{make([]int64, samples)}
+	for i := range samples {
+		values[0][i] = start.Add(time.Duration(i) * time.Second).UnixMilli()
+	}
+	for key, gen := range metrics {
+		series := make([]int64, samples)
+		for i := range samples {
+			series[i] = gen(i)
+		}
+		keys = append(keys, key)
+		values = append(values, series)
+	}
+	return ftdc.NewChunk(keys, values)
+}
+
+func constant(v int64) func(int) int64 { return func(int) int64 { return v } }
+
+func TestFTDCSummary_CachePressure(t *testing.T) {
+	t0 := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	s := NewFTDCSummary()
+	// 10 minutes at 97% full, split across two chunks; dirty ratio stays low.
+	for c := 0; c < 2; c++ {
+		s.Add(ftdcChunk(t0.Add(time.Duration(c)*300*time.Second), 300, map[string]func(int) int64{
+			"serverStatus.wiredTiger.cache.maximum bytes configured":         constant(1000),
+			"serverStatus.wiredTiger.cache.bytes currently in the cache":     constant(970),
+			"serverStatus.wiredTiger.cache.tracked dirty bytes in the cache": constant(50),
+		}))
+	}
+	if s.Samples != 600 || !s.From.Equal(t0) {
+		t.Fatalf("samples=%d from=%v", s.Samples, s.From)
+	}
+	findings := s.Findings()
+	if len(findings) != 1 {
+		t.Fatalf("expected one finding, got %+v", findings)
+	}
+	f := findings[0]
+	if f.Type != FindingCachePressure || f.Severity != SeverityHigh {
+		t.Errorf("finding = %+v", f)
+	}
+	if !strings.Contains(f.Message, "for 9m59s from 2024-03-01 10:00 UTC (peak 97%)") {
+		t.Errorf("message = %q", f.Message)
+	}
+}
+
+func TestFTDCSummary_ShortBurstIgnored(t *testing.T) {
+	t0 := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	s := NewFTDCSummary()
+	s.Add(ftdcChunk(t0, 300, map[string]func(int) int64{
+		"serverStatus.wiredTiger.cache.maximum bytes configured": constant(1000),
+		"serverStatus.wiredTiger.cache.bytes currently in the cache": func(i int) int64 {
+			if i%120 < 60 {
+				return 990
+			}
+			return 500
+		},
+		"serverStatus.wiredTiger.concurrentTransactions.write.available":    constant(5),
+		"serverStatus.wiredTiger.concurrentTransactions.write.totalTickets": constant(128),
+	}))
+	if findings := s.Findings(); len(findings) != 0 {
+		t.Fatalf("expected no findings, got %+v", findings)
+	}
+}
+
+func TestFTDCSummary_TicketExhaustion(t *testing.T) {
+	t0 := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	s := NewFTDCSummary()
+	s.Add(ftdcChunk(t0, 300, map[string]func(int) int64{
+		"serverStatus.queues.execution.read.available": func(i int) int64 {
+			if i >= 100 && i < 200 {
+				return 0
+			}
+			return 40
+		},
+		"serverStatus.queues.execution.read.totalTickets": constant(128),
+	}))
+	findings := s.Findings()
+	if len(findings) != 1 || findings[0].Type != FindingTicketExhaustion {
+		t.Fatalf("expected TICKET_EXHAUSTION, got %+v", findings)
+	}
+	if !strings.Contains(findings[0].Message, "no read tickets were available for 1m39s from 2024-03-01 10:01 UTC (128 tickets)") {
+		t.Errorf("message = %q", findings[0].Message)
+	}
+}
+
+func TestFTDCSummary_SampleGapEndsRun(t *testing.T) {
+	t0 := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	s := NewFTDCSummary()
+	exhausted := map[string]func(int) int64{
+		"serverStatus.queues.execution.write.available":    constant(0),
+		"serverStatus.queues.execution.write.totalTickets": constant(128),
+	}
+	// Two 40s stretches separated by a restart are not one 1m20s stretch.
+	s.Add(ftdcChunk(t0, 40, exhausted))
+	s.Add(ftdcChunk(t0.Add(10*time.Minute), 40, exhausted))
+	if findings := s.Findings(); len(findings) != 0 {
+		t.Fatalf("expected no findings, got %+v", findings)
+	}
+}
+
+func TestFTDCSummary_SlowCheckpoints(t *testing.T) {
+	t0 := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	s := NewFTDCSummary()
+	s.Add(ftdcChunk(t0, 300, map[string]func(int) int64{
+		"serverStatus.wiredTiger.transaction.transaction checkpoint most recent time (msecs)": func(i int) int64 {
+			switch {
+			case i < 60:
+				return 1200
+			case i < 180:
+				return 75_000
+			default:
+				return 95_000
+			}
+		},
+	}))
+	findings := s.Findings()
+	if len(findings) != 1 || findings[0].Type != FindingSlowCheckpoint || findings[0].Severity != SeverityMedium {
+		t.Fatalf("expected SLOW_CHECKPOINT, got %+v", findings)
+	}
+	if !strings.Contains(findings[0].Message, "2 checkpoints took longer than 60s, the longest 1m35s at 2024-03-01 10:03 UTC") {
+		t.Errorf("message = %q", findings[0].Message)
+	}
+}
+
+func TestFTDCSummary_NoTimestampsSkipped(t *testing.T) {
+	s := NewFTDCSummary()
+	s.Add(ftdc.NewChunk([]string{"serverStatus.uptime"}, [][]int64{{1, 2, 3}}))
+	if s.Samples != 0 {
+		t.Fatalf("samples = %d, want 0", s.Samples)
+	}
+}
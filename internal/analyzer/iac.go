@@ -0,0 +1,180 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ppiankov/mongospectre/internal/atlas"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+// IaCDriftInput carries the Atlas resources the repo declares in
+// Terraform or Pulumi and the live resources of the Atlas project.
+type IaCDriftInput struct {
+	ProjectID  string
+	Refs       []scanner.IaCRef
+	Users      []atlas.DatabaseUser
+	AccessList []atlas.AccessListEntry
+	Clusters   []atlas.Cluster
+	// Unavailable marks the resource kinds whose live state could not be
+	// fetched; they are not compared.
+	Unavailable map[scanner.IaCResourceKind]bool
+}
+
+var iacKindLabels = map[scanner.IaCResourceKind]string{
+	scanner.IaCDatabaseUser: "database user",
+	scanner.IaCAccessList:   "IP access list entry",
+	scanner.IaCCluster:      "cluster",
+}
+
+var iacToolLabels = map[string]string{
+	scanner.IaCTerraform: "Terraform",
+	scanner.IaCPulumi:    "Pulumi",
+}
+
+// DetectIaCDrift compares the database users, IP access list entries and
+// clusters declared in Terraform or Pulumi with the live Atlas project,
+// reporting IAC_DRIFT for each resource that exists on one side only.
+// Declarations with a literal project_id of another project are ignored.
+// Live resources are only reported as undeclared when the repo declares
+// resources of that kind and each of them has a literal value, since a
+// value set from a variable cannot be matched.
+func DetectIaCDrift(input *IaCDriftInput) []Finding {
+	if input == nil || len(input.Refs) == 0 {
+		return nil
+	}
+
+	live := map[scanner.IaCResourceKind]map[string]string{
+		scanner.IaCDatabaseUser: {},
+		scanner.IaCAccessList:   {},
+		scanner.IaCCluster:      {},
+	}
+	for _, u := range input.Users {
+		live[scanner.IaCDatabaseUser][iacUserKey(u.Username, u.DatabaseName)] = u.Username
+	}
+	for _, e := range input.AccessList {
+		switch {
+		case e.AWSSecurityGroup != "":
+			live[scanner.IaCAccessList][iacAccessKey("aws_security_group", e.AWSSecurityGroup)] = e.AWSSecurityGroup
+		case e.CIDRBlock != "":
+			live[scanner.IaCAccessList][iacAccessKey("cidr_block", e.CIDRBlock)] = e.CIDRBlock
+		default:
+			live[scanner.IaCAccessList][iacAccessKey("ip_address", e.IPAddress)] = e.IPAddress
+		}
+	}
+	for _, c := range input.Clusters {
+		live[scanner.IaCCluster][c.Name] = c.Name
+	}
+
+	byKind := make(map[scanner.IaCResourceKind][]scanner.IaCRef)
+	for _, ref := range input.Refs {
+		if ref.ProjectID != "" && input.ProjectID != "" && ref.ProjectID != input.ProjectID {
+			continue
+		}
+		byKind[ref.Kind] = append(byKind[ref.Kind], ref)
+	}
+
+	var findings []Finding
+	for _, kind := range []scanner.IaCResourceKind{scanner.IaCDatabaseUser, scanner.IaCAccessList, scanner.IaCCluster} {
+		refs := byKind[kind]
+		if len(refs) == 0 || input.Unavailable[kind] {
+			continue
+		}
+		tools := iacTools(refs)
+		declared := make(map[string]bool)
+		complete := true
+		for _, ref := range refs {
+			if ref.Value == "" {
+				complete = false
+				continue
+			}
+			key := ref.Value
+			switch kind {
+			case scanner.IaCDatabaseUser:
+				key = iacUserKey(ref.Value, ref.AuthDatabase)
+			case scanner.IaCAccessList:
+				key = iacAccessKey(ref.Attribute, ref.Value)
+			}
+			if declared[key] {
+				continue
+			}
+			declared[key] = true
+			if _, ok := live[kind][key]; ok {
+				continue
+			}
+			findings = append(findings, Finding{
+				Type:     FindingIaCDrift,
+				Severity: SeverityMedium,
+				Message: fmt.Sprintf("%s declares %s %q (%s), but Atlas project %s does not have it; the change was not applied, or the resource was removed outside %s",
+					iacToolLabels[ref.Tool], iacKindLabels[kind], ref.Value, ref.Address, input.ProjectID, iacToolLabels[ref.Tool]),
+				File: ref.File,
+				Line: ref.Line,
+			})
+		}
+		if !complete {
+			continue
+		}
+
+		keys := make([]string, 0, len(live[kind]))
+		for key := range live[kind] {
+			if !declared[key] {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value := live[kind][key]
+			f := Finding{
+				Type:     FindingIaCDrift,
+				Severity: SeverityMedium,
+				Message: fmt.Sprintf("%s %q exists in Atlas project %s but is not declared in %s; it was added outside %s, which does not manage or review it",
+					iacKindLabels[kind], value, input.ProjectID, tools, tools),
+			}
+			if kind == scanner.IaCAccessList && (value == "0.0.0.0/0" || value == "::/0") {
+				f.Severity = SeverityHigh
+				f.Message += "; it opens the project to any IP address"
+			}
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// iacTools names the tools declaring refs, e.g. "Terraform or Pulumi".
+func iacTools(refs []scanner.IaCRef) string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, ref := range refs {
+		if !seen[ref.Tool] {
+			seen[ref.Tool] = true
+			names = append(names, iacToolLabels[ref.Tool])
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, " or ")
+}
+
+// iacUserKey identifies a database user by name and authentication
+// database, which Atlas defaults to admin.
+func iacUserKey(username, authDB string) string {
+	if authDB == "" {
+		authDB = "admin"
+	}
+	return username + "@" + authDB
+}
+
+// iacAccessKey identifies an access list entry by its CIDR block or AWS
+// security group; Atlas stores a single IP address as a /32 or /128 block.
+func iacAccessKey(attribute, value string) string {
+	switch attribute {
+	case "aws_security_group":
+		return "sg:" + value
+	case "ip_address":
+		if strings.Contains(value, ":") {
+			return value + "/128"
+		}
+		return value + "/32"
+	}
+	return value
+}
@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/mongospectre/internal/atlas"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+func TestDetectIaCDrift(t *testing.T) {
+	input := &IaCDriftInput{
+		ProjectID: "proj1",
+		Refs: []scanner.IaCRef{
+			{Tool: scanner.IaCTerraform, Kind: scanner.IaCDatabaseUser, Address: "mongodbatlas_database_user.app", Value: "app", Attribute: "username", AuthDatabase: "admin", File: "infra/atlas.tf", Line: 1},
+			{Tool: scanner.IaCTerraform, Kind: scanner.IaCDatabaseUser, Address: "mongodbatlas_database_user.etl", Value: "etl", Attribute: "username", File: "infra/atlas.tf", Line: 12},
+			{Tool: scanner.IaCTerraform, Kind: scanner.IaCDatabaseUser, Address: "mongodbatlas_database_user.app", Value: "app", Attribute: "username", File: "infra/terraform.tfstate", Line: 9},
+			{Tool: scanner.IaCTerraform, Kind: scanner.IaCDatabaseUser, Address: "mongodbatlas_database_user.other", Value: "other", Attribute: "username", ProjectID: "proj2", File: "infra/other.tf", Line: 1},
+			{Tool: scanner.IaCTerraform, Kind: scanner.IaCAccessList, Address: "mongodbatlas_project_ip_access_list.office", Value: "203.0.113.0/24", Attribute: "cidr_block", File: "infra/atlas.tf", Line: 18},
+			{Tool: scanner.IaCPulumi, Kind: scanner.IaCAccessList, Address: "mongodbatlas_project_ip_access_list.vpn", Value: "198.51.100.7", Attribute: "ip_address", File: "Pulumi.yaml", Line: 4},
+			{Tool: scanner.IaCTerraform, Kind: scanner.IaCCluster, Address: "mongodbatlas_advanced_cluster.main", Attribute: "name", File: "infra/atlas.tf", Line: 23},
+		},
+		Users: []atlas.DatabaseUser{
+			{Username: "app", DatabaseName: "admin"},
+			{Username: "debug", DatabaseName: "admin"},
+		},
+		AccessList: []atlas.AccessListEntry{
+			{CIDRBlock: "203.0.113.0/24"},
+			{CIDRBlock: "198.51.100.7/32", IPAddress: "198.51.100.7"},
+			{CIDRBlock: "0.0.0.0/0"},
+		},
+		Clusters: []atlas.Cluster{{Name: "Cluster0"}, {Name: "Analytics"}},
+	}
+
+	findings := DetectIaCDrift(input)
+	if len(findings) != 3 {
+		t.Fatalf("expected 3 findings, got %d: %+v", len(findings), findings)
+	}
+
+	missing := findings[0]
+	if missing.Type != FindingIaCDrift || missing.Severity != SeverityMedium || missing.File != "infra/atlas.tf" || missing.Line != 12 {
+		t.Errorf("unexpected declared-only finding: %+v", missing)
+	}
+	if !strings.Contains(missing.Message, `Terraform declares database user "etl"`) {
+		t.Errorf("unexpected message: %s", missing.Message)
+	}
+
+	manual := findings[1]
+	if manual.Severity != SeverityMedium || manual.File != "" || !strings.Contains(manual.Message, `database user "debug" exists in Atlas project proj1 but is not declared in Terraform`) {
+		t.Errorf("unexpected live-only user finding: %+v", manual)
+	}
+
+	open := findings[2]
+	if open.Severity != SeverityHigh || !strings.Contains(open.Message, `"0.0.0.0/0"`) || !strings.Contains(open.Message, "not declared in Pulumi or Terraform") {
+		t.Errorf("unexpected live-only access finding: %+v", open)
+	}
+}
+
+func TestDetectIaCDriftUnavailable(t *testing.T) {
+	input := &IaCDriftInput{
+		ProjectID: "proj1",
+		Refs: []scanner.IaCRef{
+			{Tool: scanner.IaCTerraform, Kind: scanner.IaCDatabaseUser, Address: "mongodbatlas_database_user.app", Value: "app", Attribute: "username", File: "atlas.tf", Line: 1},
+		},
+		Unavailable: map[scanner.IaCResourceKind]bool{scanner.IaCDatabaseUser: true},
+	}
+	if findings := DetectIaCDrift(input); len(findings) != 0 {
+		t.Fatalf("expected no findings when users are unavailable, got %+v", findings)
+	}
+	if findings := DetectIaCDrift(&IaCDriftInput{Users: []atlas.DatabaseUser{{Username: "app"}}}); len(findings) != 0 {
+		t.Fatalf("expected no findings without declarations, got %+v", findings)
+	}
+}
@@ -2,60 +2,187 @@ package analyzer
 
 import (
 	"bufio"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // IgnoreRule matches findings to suppress.
 type IgnoreRule struct {
-	Type       string // finding type or "*" for any
-	Database   string // database name or "*" for any
-	Collection string // collection name or "*" for any
-	Index      string // index name or "*"/empty for any
+	Type       string    // finding type or "*" for any
+	Database   string    // database name or "*" for any
+	Collection string    // collection name or "*" for any
+	Index      string    // index name or "*"/empty for any
+	Expires    time.Time // zero means the rule never expires
+	Source     string    // file and line the rule was read from, e.g. ".mongospectreignore:3"
 }
 
 // IgnoreList holds parsed ignore rules.
 type IgnoreList struct {
 	Rules []IgnoreRule
+	// Sources lists the files the rules were merged from, in load order.
+	Sources []IgnoreSource
 }
 
+// IgnoreSource is one ignore file and the number of active rules it
+// contributed.
+type IgnoreSource struct {
+	Path  string
+	Rules int
+}
+
+// ignoreFileName is the ignore file looked up in the working directory.
+const ignoreFileName = ".mongospectreignore"
+
 // LoadIgnoreFile reads a .mongospectreignore file from the given directory.
-// Returns an empty list if the file doesn't exist.
+// Returns an empty list if the file doesn't exist. Expired rules are left out.
 func LoadIgnoreFile(dir string) (IgnoreList, error) {
-	path := filepath.Join(dir, ".mongospectreignore")
+	var il IgnoreList
+	err := il.load(filepath.Join(dir, ignoreFileName), false)
+	return il, err
+}
+
+// UserIgnoreFile returns the path of the ignore file shared by every
+// repository: $XDG_CONFIG_HOME/mongospectre/ignore, or
+// ~/.config/mongospectre/ignore. It returns "" if neither can be resolved.
+func UserIgnoreFile() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "mongospectre", "ignore")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "mongospectre", "ignore")
+}
+
+// LoadIgnoreRules merges the user ignore file, the .mongospectreignore in
+// dir and, if set, an explicit file. Suppression is additive: a finding is
+// ignored when a rule from any of them matches. The user and repository
+// files are optional; an explicit file must exist. A file that fails to
+// load does not stop the others: the list holds every rule that loaded,
+// and the error joins the failures.
+func LoadIgnoreRules(dir, explicit string) (IgnoreList, error) {
+	var il IgnoreList
+	var errs []error
+	if user := UserIgnoreFile(); user != "" {
+		errs = append(errs, il.load(user, false))
+	}
+	errs = append(errs, il.load(filepath.Join(dir, ignoreFileName), false))
+	if explicit != "" {
+		errs = append(errs, il.load(explicit, true))
+	}
+	return il, errors.Join(errs...)
+}
+
+// load appends the active rules of one file, tagging each with its file
+// and line. A missing file is skipped unless required.
+func (il *IgnoreList) load(path string, required bool) error {
 	f, err := os.Open(path)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return IgnoreList{}, nil
+		if os.IsNotExist(err) && !required {
+			return nil
 		}
-		return IgnoreList{}, err
+		return fmt.Errorf("ignore file: %w", err)
 	}
 	defer func() { _ = f.Close() }()
 
-	var rules []IgnoreRule
+	now := time.Now()
+	source := IgnoreSource{Path: path}
 	sc := bufio.NewScanner(f)
-	for sc.Scan() {
+	for lineNo := 1; sc.Scan(); lineNo++ {
 		line := strings.TrimSpace(sc.Text())
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
-		rule, ok := parseIgnoreRule(line)
-		if ok {
-			rules = append(rules, rule)
+		rule, ok := ParseIgnoreRule(line)
+		if ok && (rule.Expires.IsZero() || now.Before(rule.Expires)) {
+			rule.Source = fmt.Sprintf("%s:%d", path, lineNo)
+			il.Rules = append(il.Rules, rule)
+			source.Rules++
 		}
 	}
-	return IgnoreList{Rules: rules}, sc.Err()
+	il.Sources = append(il.Sources, source)
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// AppendIgnoreRule adds rule to the .mongospectreignore file in dir,
+// creating the file if needed. A non-empty comment is written on the line
+// above the rule.
+func AppendIgnoreRule(dir string, rule IgnoreRule, comment string) error {
+	path := filepath.Join(dir, ignoreFileName)
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var b strings.Builder
+	if len(existing) > 0 && existing[len(existing)-1] != '\n' {
+		b.WriteByte('\n')
+	}
+	if comment != "" {
+		b.WriteString("# " + comment + "\n")
+	}
+	b.WriteString(rule.String() + "\n")
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(b.String()); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// IgnoreRuleFor returns a rule matching exactly the namespace and index of
+// f. Findings without a collection match the whole database.
+func IgnoreRuleFor(f *Finding) IgnoreRule {
+	rule := IgnoreRule{Type: string(f.Type), Database: f.Database, Collection: f.Collection, Index: f.Index}
+	if rule.Database == "" {
+		rule.Database = "*"
+	}
+	if rule.Collection == "" {
+		rule.Collection = "*"
+	}
+	return rule
 }
 
-// parseIgnoreRule parses a single ignore rule line.
-// Format: TYPE db.collection[.index]
+// String formats the rule as an ignore file line.
+func (r IgnoreRule) String() string {
+	database := r.Database
+	if database == "" {
+		database = "*"
+	}
+	line := r.Type + " " + database + "." + r.Collection
+	if r.Index != "" {
+		line += "." + r.Index
+	}
+	if !r.Expires.IsZero() {
+		line += " expires=" + r.Expires.UTC().Format(time.RFC3339)
+	}
+	return line
+}
+
+// ParseIgnoreRule parses a single ignore rule line.
+// Format: TYPE db.collection[.index] [expires=DATE]
 // Examples:
 //
 //	UNUSED_INDEX app.legacy_users.idx_old
 //	* app.audit_logs
-//	MISSING_TTL app.settings
-func parseIgnoreRule(line string) (IgnoreRule, bool) {
+//	MISSING_TTL app.settings expires=2026-12-31
+//
+// expires takes a date (the rule ends at midnight UTC) or an RFC 3339
+// timestamp. Lines with an unreadable expiry are rejected rather than
+// ignoring the finding forever.
+func ParseIgnoreRule(line string) (IgnoreRule, bool) {
 	parts := strings.Fields(line)
 	if len(parts) < 2 {
 		return IgnoreRule{}, false
@@ -64,9 +191,22 @@ func parseIgnoreRule(line string) (IgnoreRule, bool) {
 	findingType := parts[0]
 	target := parts[1]
 
+	var expires time.Time
+	for _, opt := range parts[2:] {
+		value, ok := strings.CutPrefix(opt, "expires=")
+		if !ok {
+			continue
+		}
+		t, err := parseIgnoreExpiry(value)
+		if err != nil {
+			return IgnoreRule{}, false
+		}
+		expires = t
+	}
+
 	// Split target into db.collection[.index]
 	segments := strings.SplitN(target, ".", 3)
-	rule := IgnoreRule{Type: findingType}
+	rule := IgnoreRule{Type: findingType, Expires: expires}
 
 	switch len(segments) {
 	case 1:
@@ -85,6 +225,13 @@ func parseIgnoreRule(line string) (IgnoreRule, bool) {
 	return rule, true
 }
 
+func parseIgnoreExpiry(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
 // Matches checks if a finding should be suppressed by this rule.
 func (r IgnoreRule) Matches(f *Finding) bool {
 	if r.Type != "*" && r.Type != string(f.Type) {
@@ -105,29 +252,72 @@ func (r IgnoreRule) Matches(f *Finding) bool {
 // Filter removes findings that match any ignore rule.
 // Returns the filtered list and the count of suppressed findings.
 func (il IgnoreList) Filter(findings []Finding) ([]Finding, int) {
+	filtered, hits := il.FilterHits(findings)
+	return filtered, sumHits(hits)
+}
+
+// FilterHits is Filter reporting, for each rule in il.Rules, how many
+// findings it suppressed. Each finding is credited to the first rule that
+// matches it.
+func (il IgnoreList) FilterHits(findings []Finding) ([]Finding, []int) {
 	if len(il.Rules) == 0 {
-		return findings, 0
+		return findings, nil
 	}
 
 	var filtered []Finding
-	suppressed := 0
+	hits := make([]int, len(il.Rules))
 	for _, f := range findings {
-		if il.matches(&f) {
-			suppressed++
+		if i := il.match(&f); i >= 0 {
+			hits[i]++
 		} else {
 			filtered = append(filtered, f)
 		}
 	}
-	return filtered, suppressed
+	return filtered, hits
+}
+
+// FilterCompare removes compare findings that match any ignore rule, using
+// the compare finding type (e.g. INDEX_DRIFT) in place of a finding type.
+func (il IgnoreList) FilterCompare(findings []CompareFinding) ([]CompareFinding, int) {
+	filtered, hits := il.FilterCompareHits(findings)
+	return filtered, sumHits(hits)
+}
+
+// FilterCompareHits is FilterCompare with per-rule counts, as FilterHits.
+func (il IgnoreList) FilterCompareHits(findings []CompareFinding) ([]CompareFinding, []int) {
+	if len(il.Rules) == 0 {
+		return findings, nil
+	}
+
+	var filtered []CompareFinding
+	hits := make([]int, len(il.Rules))
+	for _, cf := range findings {
+		f := Finding{Type: FindingType(cf.Type), Database: cf.Database, Collection: cf.Collection, Index: cf.Index}
+		if i := il.match(&f); i >= 0 {
+			hits[i]++
+		} else {
+			filtered = append(filtered, cf)
+		}
+	}
+	return filtered, hits
 }
 
-func (il IgnoreList) matches(f *Finding) bool {
-	for _, r := range il.Rules {
-		if r.Matches(f) {
-			return true
+// match returns the index of the first rule matching f, or -1.
+func (il IgnoreList) match(f *Finding) int {
+	for i := range il.Rules {
+		if il.Rules[i].Matches(f) {
+			return i
 		}
 	}
-	return false
+	return -1
+}
+
+func sumHits(hits []int) int {
+	total := 0
+	for _, n := range hits {
+		total += n
+	}
+	return total
 }
 
 // matchGlob checks if pattern matches value. Supports trailing "*" only.
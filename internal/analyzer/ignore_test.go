@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestParseIgnoreRule(t *testing.T) {
@@ -20,16 +21,16 @@ func TestParseIgnoreRule(t *testing.T) {
 		{"ONLY_TYPE", false, IgnoreRule{}},
 	}
 	for _, tt := range tests {
-		rule, ok := parseIgnoreRule(tt.line)
+		rule, ok := ParseIgnoreRule(tt.line)
 		if ok != tt.ok {
-			t.Errorf("parseIgnoreRule(%q) ok = %v, want %v", tt.line, ok, tt.ok)
+			t.Errorf("ParseIgnoreRule(%q) ok = %v, want %v", tt.line, ok, tt.ok)
 			continue
 		}
 		if !ok {
 			continue
 		}
 		if rule != tt.want {
-			t.Errorf("parseIgnoreRule(%q) = %+v, want %+v", tt.line, rule, tt.want)
+			t.Errorf("ParseIgnoreRule(%q) = %+v, want %+v", tt.line, rule, tt.want)
 		}
 	}
 }
@@ -152,3 +153,133 @@ func TestMatchGlob(t *testing.T) {
 		}
 	}
 }
+
+func TestFilterCompare(t *testing.T) {
+	il := IgnoreList{Rules: []IgnoreRule{
+		{Type: "INDEX_DRIFT", Database: "app", Collection: "users", Index: "legacy_1"},
+		{Type: "MISSING_IN_SOURCE", Database: "*", Collection: "tmp_*"},
+	}}
+	findings := []CompareFinding{
+		{Type: CompareIndexDrift, Database: "app", Collection: "users", Index: "legacy_1"},
+		{Type: CompareIndexDrift, Database: "app", Collection: "users", Index: "email_1"},
+		{Type: CompareMissingInSource, Database: "app", Collection: "tmp_import"},
+		{Type: CompareMissingInTarget, Database: "app", Collection: "tmp_import"},
+	}
+
+	filtered, suppressed := il.FilterCompare(findings)
+	if suppressed != 2 {
+		t.Errorf("suppressed = %d, want 2", suppressed)
+	}
+	if len(filtered) != 2 || filtered[0].Index != "email_1" || filtered[1].Type != CompareMissingInTarget {
+		t.Errorf("unexpected filtered findings: %+v", filtered)
+	}
+}
+
+func TestLoadIgnoreFile_Expiry(t *testing.T) {
+	dir := t.TempDir()
+	content := `UNUSED_INDEX app.users.idx_old expires=2001-01-01
+MISSING_TTL app.settings expires=2999-01-01T00:00:00Z
+* app.audit_logs expires=someday
+`
+	if err := os.WriteFile(filepath.Join(dir, ".mongospectreignore"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	il, err := LoadIgnoreFile(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(il.Rules) != 1 || il.Rules[0].Type != "MISSING_TTL" {
+		t.Fatalf("rules = %+v, want only the unexpired MISSING_TTL rule", il.Rules)
+	}
+}
+
+func TestAppendIgnoreRule(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".mongospectreignore"), []byte("* app.audit_logs"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rule := IgnoreRuleFor(&Finding{Type: FindingUnusedIndex, Database: "app", Collection: "users", Index: "idx_old"})
+	rule.Expires = time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	if err := AppendIgnoreRule(dir, rule, "acknowledged by @alice"); err != nil {
+		t.Fatal(err)
+	}
+	dbRule := IgnoreRuleFor(&Finding{Type: FindingMissingTTL, Database: "app"})
+	if err := AppendIgnoreRule(dir, dbRule, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	il, err := LoadIgnoreFile(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(il.Rules) != 3 {
+		t.Fatalf("got %d rules, want 3", len(il.Rules))
+	}
+	if !il.Rules[1].Expires.Equal(rule.Expires) || il.Rules[1].Index != "idx_old" {
+		t.Errorf("appended rule = %+v, want %+v", il.Rules[1], rule)
+	}
+	if !il.Rules[2].Matches(&Finding{Type: FindingMissingTTL, Database: "app", Collection: "sessions"}) {
+		t.Errorf("database rule %+v should match every collection", il.Rules[2])
+	}
+}
+
+func TestLoadIgnoreRules(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	userFile := UserIgnoreFile()
+	if err := os.MkdirAll(filepath.Dir(userFile), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(userFile, []byte("* tmp.*\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".mongospectreignore"), []byte("# repo\n\nUNUSED_INDEX app.users.idx_old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	il, err := LoadIgnoreRules(dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(il.Rules) != 2 || len(il.Sources) != 2 {
+		t.Fatalf("rules = %+v, sources = %+v", il.Rules, il.Sources)
+	}
+	if il.Rules[0].Source != userFile+":1" {
+		t.Errorf("user rule source = %q", il.Rules[0].Source)
+	}
+	if want := filepath.Join(dir, ".mongospectreignore") + ":3"; il.Rules[1].Source != want {
+		t.Errorf("repo rule source = %q, want %q", il.Rules[1].Source, want)
+	}
+
+	findings := []Finding{
+		{Type: FindingUnusedIndex, Database: "app", Collection: "users", Index: "idx_old"},
+		{Type: FindingMissingTTL, Database: "tmp", Collection: "a"},
+		{Type: FindingMissingTTL, Database: "tmp", Collection: "b"},
+		{Type: FindingMissingTTL, Database: "app", Collection: "orders"},
+	}
+	filtered, hits := il.FilterHits(findings)
+	if len(filtered) != 1 || hits[0] != 2 || hits[1] != 1 {
+		t.Errorf("filtered = %d, hits = %v", len(filtered), hits)
+	}
+
+	// A file that fails to load keeps the rules of the others.
+	il, err = LoadIgnoreRules(dir, filepath.Join(dir, "missing"))
+	if err == nil {
+		t.Error("expected an error for a missing explicit ignore file")
+	}
+	if len(il.Rules) != 2 {
+		t.Errorf("rules after an error = %+v, want the 2 that loaded", il.Rules)
+	}
+	if err := os.Chmod(userFile, 0o000); err != nil {
+		t.Fatal(err)
+	}
+	if os.Getuid() != 0 {
+		il, err = LoadIgnoreRules(dir, "")
+		if err == nil || len(il.Rules) != 1 {
+			t.Errorf("unreadable user file: rules = %+v, err = %v; want the repo rule and an error", il.Rules, err)
+		}
+	}
+}
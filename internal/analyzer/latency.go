@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"fmt"
+	"time"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+const (
+	latencyPercentile = 0.95
+	latencyMinOps     = 1000 // too few operations for a stable percentile
+	latencyHighFactor = 10   // p95 this many times the threshold is high severity
+)
+
+// detectHighLatency flags collections whose p95 read or write latency from
+// $collStats latencyStats reaches the HighLatencyMillis threshold. The
+// histograms are cumulative since server start.
+func detectHighLatency(c *mongoinspect.CollectionInfo) []Finding {
+	if c.Latency == nil {
+		return nil
+	}
+	limit := time.Duration(thresholdsFor(c.Database).HighLatencyMillis) * time.Millisecond
+	var findings []Finding
+	for _, op := range []struct {
+		name string
+		lat  *mongoinspect.OpLatency
+	}{{"read", &c.Latency.Reads}, {"write", &c.Latency.Writes}} {
+		if op.lat.Ops < latencyMinOps {
+			continue
+		}
+		p95, ok := latencyPercentileOf(op.lat, latencyPercentile)
+		if !ok || p95 < limit {
+			continue
+		}
+		sev := SeverityMedium
+		if p95 >= latencyHighFactor*limit {
+			sev = SeverityHigh
+		}
+		avg := time.Duration(op.lat.TotalMicros/op.lat.Ops) * time.Microsecond
+		findings = append(findings, Finding{
+			Type:       FindingHighCollectionLatency,
+			Severity:   sev,
+			Database:   c.Database,
+			Collection: c.Name,
+			Message: fmt.Sprintf("p95 %s latency is at least %s over %d %ss since server start (average %s, threshold %s)",
+				op.name, p95, op.lat.Ops, op.name, avg.Round(time.Millisecond), limit),
+		})
+	}
+	return findings
+}
+
+// latencyPercentileOf returns the lower bound of the histogram bucket that
+// holds the p-th percentile operation, so the estimate never overstates.
+func latencyPercentileOf(lat *mongoinspect.OpLatency, p float64) (time.Duration, bool) {
+	var total int64
+	for _, b := range lat.Histogram {
+		total += b.Count
+	}
+	if total == 0 {
+		return 0, false
+	}
+	rank := int64(p * float64(total))
+	var seen int64
+	for _, b := range lat.Histogram {
+		seen += b.Count
+		if seen > rank {
+			return time.Duration(b.Micros) * time.Microsecond, true
+		}
+	}
+	last := lat.Histogram[len(lat.Histogram)-1]
+	return time.Duration(last.Micros) * time.Microsecond, true
+}
@@ -0,0 +1,88 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+func latencyColl(reads, writes mongoinspect.OpLatency) mongoinspect.CollectionInfo {
+	return mongoinspect.CollectionInfo{
+		Database: "app",
+		Name:     "orders",
+		Latency:  &mongoinspect.LatencyStats{Reads: reads, Writes: writes},
+	}
+}
+
+func TestDetectHighLatency(t *testing.T) {
+	slowReads := mongoinspect.OpLatency{
+		Ops:         2000,
+		TotalMicros: 2000 * 60_000,
+		Histogram: []mongoinspect.LatencyBucket{
+			{Micros: 1024, Count: 1500},
+			{Micros: 131072, Count: 400},
+			{Micros: 262144, Count: 100},
+		},
+	}
+	fastWrites := mongoinspect.OpLatency{
+		Ops:         5000,
+		TotalMicros: 5000 * 500,
+		Histogram:   []mongoinspect.LatencyBucket{{Micros: 256, Count: 4990}, {Micros: 200_000, Count: 10}},
+	}
+	c := latencyColl(slowReads, fastWrites)
+	findings := detectHighLatency(&c)
+	if len(findings) != 1 {
+		t.Fatalf("expected one finding, got %+v", findings)
+	}
+	f := findings[0]
+	if f.Type != FindingHighCollectionLatency || f.Severity != SeverityMedium || f.Collection != "orders" {
+		t.Errorf("finding = %+v", f)
+	}
+	if !strings.Contains(f.Message, "p95 read latency is at least 262.144ms over 2000 reads") ||
+		!strings.Contains(f.Message, "average 60ms, threshold 100ms") {
+		t.Errorf("message = %q", f.Message)
+	}
+}
+
+func TestDetectHighLatency_HighSeverity(t *testing.T) {
+	writes := mongoinspect.OpLatency{
+		Ops:         1000,
+		TotalMicros: 1000 * 1_500_000,
+		Histogram:   []mongoinspect.LatencyBucket{{Micros: 1_048_576, Count: 1000}},
+	}
+	c := latencyColl(mongoinspect.OpLatency{}, writes)
+	findings := detectHighLatency(&c)
+	if len(findings) != 1 || findings[0].Severity != SeverityHigh {
+		t.Fatalf("expected high severity, got %+v", findings)
+	}
+}
+
+func TestDetectHighLatency_SkipsLowVolumeAndMissingStats(t *testing.T) {
+	reads := mongoinspect.OpLatency{
+		Ops:         50,
+		TotalMicros: 50 * 500_000,
+		Histogram:   []mongoinspect.LatencyBucket{{Micros: 524288, Count: 50}},
+	}
+	c := latencyColl(reads, mongoinspect.OpLatency{})
+	if findings := detectHighLatency(&c); len(findings) != 0 {
+		t.Errorf("expected low-volume collection skipped, got %+v", findings)
+	}
+	c.Latency = nil
+	if findings := detectHighLatency(&c); len(findings) != 0 {
+		t.Errorf("expected no findings without latency stats, got %+v", findings)
+	}
+}
+
+func TestDetectHighLatency_ConfiguredThreshold(t *testing.T) {
+	setThresholds(t, ThresholdConfig{Databases: map[string]Thresholds{"app": {HighLatencyMillis: 500}}})
+	reads := mongoinspect.OpLatency{
+		Ops:         2000,
+		TotalMicros: 2000 * 200_000,
+		Histogram:   []mongoinspect.LatencyBucket{{Micros: 262144, Count: 2000}},
+	}
+	c := latencyColl(reads, mongoinspect.OpLatency{})
+	if findings := detectHighLatency(&c); len(findings) != 0 {
+		t.Errorf("expected 262ms p95 under a 500ms threshold, got %+v", findings)
+	}
+}
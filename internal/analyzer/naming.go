@@ -0,0 +1,159 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+// namingStyles maps built-in convention names to the pattern a name must match.
+var namingStyles = map[string]*regexp.Regexp{
+	"snake_case": regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`),
+	"camelCase":  regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`),
+	"PascalCase": regexp.MustCompile(`^[A-Z][a-zA-Z0-9]*$`),
+	"kebab-case": regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*$`),
+	"lowercase":  regexp.MustCompile(`^[a-z][a-z0-9]*$`),
+}
+
+// NamingRules configures the naming convention linter. Each rule is either a
+// built-in style (snake_case, camelCase, PascalCase, kebab-case, lowercase) or
+// a regular expression. An empty rule disables that check.
+type NamingRules struct {
+	Collections string
+	Fields      string
+	Indexes     string
+}
+
+// Enabled reports whether any rule is set.
+func (r NamingRules) Enabled() bool {
+	return r.Collections != "" || r.Fields != "" || r.Indexes != ""
+}
+
+// NamingLinter checks collection, field and index names against NamingRules.
+type NamingLinter struct {
+	collections namingRule
+	fields      namingRule
+	indexes     namingRule
+}
+
+type namingRule struct {
+	name string
+	re   *regexp.Regexp
+}
+
+func (r namingRule) violates(name string) bool {
+	return r.re != nil && !r.re.MatchString(name)
+}
+
+// NewNamingLinter compiles the rules, returning an error for an invalid pattern.
+func NewNamingLinter(rules NamingRules) (*NamingLinter, error) {
+	var l NamingLinter
+	var err error
+	if l.collections, err = compileNamingRule(rules.Collections); err != nil {
+		return nil, fmt.Errorf("collections: %w", err)
+	}
+	if l.fields, err = compileNamingRule(rules.Fields); err != nil {
+		return nil, fmt.Errorf("fields: %w", err)
+	}
+	if l.indexes, err = compileNamingRule(rules.Indexes); err != nil {
+		return nil, fmt.Errorf("indexes: %w", err)
+	}
+	return &l, nil
+}
+
+func compileNamingRule(rule string) (namingRule, error) {
+	if rule == "" {
+		return namingRule{}, nil
+	}
+	if re, ok := namingStyles[rule]; ok {
+		return namingRule{name: rule, re: re}, nil
+	}
+	re, err := regexp.Compile(rule)
+	if err != nil {
+		return namingRule{}, err
+	}
+	return namingRule{name: fmt.Sprintf("pattern %s", rule), re: re}, nil
+}
+
+// Lint returns NAMING_CONVENTION_VIOLATION findings for collection and index
+// names from inspected metadata and for field names in sampled documents.
+// System collections, the _id index, _-prefixed and numeric field names are
+// skipped.
+func (l *NamingLinter) Lint(collections []mongoinspect.CollectionInfo, samples []mongoinspect.FieldSampleResult) []Finding {
+	var findings []Finding
+	for i := range collections {
+		c := &collections[i]
+		if strings.HasPrefix(c.Name, "system.") {
+			continue
+		}
+		if l.collections.violates(c.Name) {
+			findings = append(findings, Finding{
+				Type:       FindingNamingViolation,
+				Severity:   SeverityInfo,
+				Database:   c.Database,
+				Collection: c.Name,
+				Message:    fmt.Sprintf("collection name %q does not follow %s", c.Name, l.collections.name),
+			})
+		}
+		for _, idx := range c.Indexes {
+			if idx.Name == "_id_" || !l.indexes.violates(idx.Name) {
+				continue
+			}
+			findings = append(findings, Finding{
+				Type:       FindingNamingViolation,
+				Severity:   SeverityInfo,
+				Database:   c.Database,
+				Collection: c.Name,
+				Index:      idx.Name,
+				Message:    fmt.Sprintf("index name %q does not follow %s", idx.Name, l.indexes.name),
+			})
+		}
+	}
+
+	if l.fields.re == nil {
+		return findings
+	}
+	for i := range samples {
+		s := &samples[i]
+		// Report each offending segment once per collection, with the first
+		// path it was seen in.
+		bad := make(map[string]string)
+		for _, f := range s.Fields {
+			for _, seg := range strings.Split(strings.ReplaceAll(f.Path, "[]", ""), ".") {
+				if seg == "" || strings.HasPrefix(seg, "_") || !l.fields.violates(seg) {
+					continue
+				}
+				// Numeric keys are reported by NUMERIC_FIELD_NAMES.
+				if _, err := strconv.Atoi(seg); err == nil {
+					continue
+				}
+				if _, seen := bad[seg]; !seen {
+					bad[seg] = f.Path
+				}
+			}
+		}
+		segs := make([]string, 0, len(bad))
+		for seg := range bad {
+			segs = append(segs, seg)
+		}
+		sort.Strings(segs)
+		for _, seg := range segs {
+			msg := fmt.Sprintf("field name %q does not follow %s", seg, l.fields.name)
+			if path := bad[seg]; path != seg {
+				msg += fmt.Sprintf(" (in %q)", path)
+			}
+			findings = append(findings, Finding{
+				Type:       FindingNamingViolation,
+				Severity:   SeverityInfo,
+				Database:   s.Database,
+				Collection: s.Collection,
+				Message:    msg,
+			})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,77 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+func TestNamingLinter_BuiltinStyles(t *testing.T) {
+	l, err := NewNamingLinter(NamingRules{Collections: "snake_case", Fields: "camelCase", Indexes: `^idx_`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	collections := []mongoinspect.CollectionInfo{
+		{Database: "app", Name: "order_items", Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}, {Name: "idx_status"}}},
+		{Database: "app", Name: "UserProfiles", Indexes: []mongoinspect.IndexInfo{{Name: "email_1"}}},
+		{Database: "app", Name: "system.views"},
+	}
+	samples := []mongoinspect.FieldSampleResult{{
+		Database:   "app",
+		Collection: "order_items",
+		Fields: []mongoinspect.FieldFrequency{
+			{Path: "_id"},
+			{Path: "createdAt"},
+			{Path: "line_items"},
+			{Path: "line_items[].unit_price"},
+			{Path: "prices.0"},
+			{Path: "__v"},
+		},
+	}}
+
+	findings := l.Lint(collections, samples)
+	var msgs []string
+	for _, f := range findings {
+		if f.Type != FindingNamingViolation {
+			t.Errorf("type = %s, want %s", f.Type, FindingNamingViolation)
+		}
+		msgs = append(msgs, f.Message)
+	}
+	want := []string{
+		`collection name "UserProfiles" does not follow snake_case`,
+		`index name "email_1" does not follow pattern ^idx_`,
+		`field name "line_items" does not follow camelCase`,
+		`field name "unit_price" does not follow camelCase (in "line_items[].unit_price")`,
+	}
+	if strings.Join(msgs, "\n") != strings.Join(want, "\n") {
+		t.Errorf("messages:\n%s\nwant:\n%s", strings.Join(msgs, "\n"), strings.Join(want, "\n"))
+	}
+	if findings[1].Index != "email_1" {
+		t.Errorf("index = %q, want email_1", findings[1].Index)
+	}
+}
+
+func TestNamingLinter_EmptyRulesDisabled(t *testing.T) {
+	if (NamingRules{}).Enabled() {
+		t.Error("empty rules should be disabled")
+	}
+	l, err := NewNamingLinter(NamingRules{Collections: "kebab-case"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	collections := []mongoinspect.CollectionInfo{
+		{Database: "app", Name: "audit-log", Indexes: []mongoinspect.IndexInfo{{Name: "Whatever Name"}}},
+	}
+	samples := []mongoinspect.FieldSampleResult{{Fields: []mongoinspect.FieldFrequency{{Path: "Bad Field"}}}}
+	if findings := l.Lint(collections, samples); len(findings) != 0 {
+		t.Errorf("expected no findings, got %v", findings)
+	}
+}
+
+func TestNewNamingLinter_InvalidPattern(t *testing.T) {
+	_, err := NewNamingLinter(NamingRules{Indexes: "([a-z"})
+	if err == nil || !strings.Contains(err.Error(), "indexes") {
+		t.Fatalf("expected indexes pattern error, got %v", err)
+	}
+}
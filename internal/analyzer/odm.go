@@ -0,0 +1,257 @@
+package analyzer
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+// odmLabels name the ODMs of scanner.ODMRef in findings.
+var odmLabels = map[string]string{
+	scanner.ODMMongoose:   "Mongoose",
+	scanner.ODMSpringData: "Spring Data",
+	scanner.ODMMgm:        "mgm",
+}
+
+// DetectODMRisks reports ODM settings that build indexes from the
+// application, and schema index declarations that disagree with the
+// live indexes:
+//   - ODM_AUTO_INDEX for autoIndex or auto-index-creation switched on,
+//     and for Mongoose schemas declaring indexes without autoIndex set
+//     to false, as Mongoose builds them by default;
+//   - ODM_STARTUP_INDEX_BUILD for syncIndexes, ensureIndexes and
+//     similar calls, high when syncIndexes would drop live indexes the
+//     schema does not declare;
+//   - ODM_INDEX_DRIFT for schema indexes missing on the server, and for
+//     live indexes on a collection with schema indexes that no schema,
+//     Mongoose timestamps option or createIndex call declares.
+func DetectODMRisks(scan *scanner.ScanResult, collections []mongoinspect.CollectionInfo) []Finding {
+	if len(scan.ODMRefs) == 0 {
+		return nil
+	}
+	live := make(map[string][]*mongoinspect.CollectionInfo)
+	for i := range collections {
+		key := strings.ToLower(collections[i].Name)
+		live[key] = append(live[key], &collections[i])
+	}
+
+	var findings []Finding
+	models := make(map[string]string)
+	declared := make(map[string][]declaredKey)         // by lowercased collection
+	schemaIndexes := make(map[string][]scanner.ODMRef) // by lowercased collection
+	var firstMongooseIndex *scanner.ODMRef
+	mongooseIndexes, mongooseAutoIndexSet := 0, false
+	for i := range scan.ODMRefs {
+		ref := &scan.ODMRefs[i]
+		switch ref.Kind {
+		case scanner.ODMModel:
+			models[ref.Model] = ref.Collection
+		case scanner.ODMIndex:
+			if ref.ODM == scanner.ODMMongoose {
+				mongooseIndexes++
+				if firstMongooseIndex == nil {
+					firstMongooseIndex = ref
+				}
+			}
+			if ref.Collection != "" {
+				key := strings.ToLower(ref.Collection)
+				declared[key] = append(declared[key], declaredKey{fields: ref.Fields, text: ref.TextFields})
+				schemaIndexes[key] = append(schemaIndexes[key], *ref)
+			}
+		case scanner.ODMTimestamps:
+			// Timestamp paths are often indexed outside the schema, so
+			// an index on one is declared but not expected.
+			if ref.Collection != "" {
+				key := strings.ToLower(ref.Collection)
+				for _, field := range ref.Fields {
+					declared[key] = append(declared[key], declaredKey{fields: []string{field}})
+				}
+			}
+		case scanner.ODMAutoIndex:
+			if ref.ODM == scanner.ODMMongoose {
+				mongooseAutoIndexSet = true
+			}
+			if ref.Enabled {
+				setting := "autoIndex: true"
+				if ref.ODM == scanner.ODMSpringData {
+					setting = "auto-index-creation"
+				}
+				findings = append(findings, Finding{
+					Type:     FindingODMAutoIndex,
+					Severity: SeverityMedium,
+					Message: fmt.Sprintf("%s builds missing schema indexes whenever the app starts (%s); in production, switch it off and build indexes in a migration",
+						odmLabels[ref.ODM], setting),
+					File: ref.File,
+					Line: ref.Line,
+				})
+			}
+		}
+	}
+	if firstMongooseIndex != nil && !mongooseAutoIndexSet {
+		findings = append(findings, Finding{
+			Type:     FindingODMAutoIndex,
+			Severity: SeverityMedium,
+			Message: fmt.Sprintf("Mongoose builds the %d schema indexes declared in the repo whenever the app starts, as autoIndex is not set to false; in production, switch it off and build indexes in a migration",
+				mongooseIndexes),
+			File: firstMongooseIndex.File,
+			Line: firstMongooseIndex.Line,
+		})
+	}
+	for _, ref := range scan.IndexRefs {
+		if ref.Collection != "" {
+			key := strings.ToLower(ref.Collection)
+			declared[key] = append(declared[key], declaredKey{fields: ref.Fields, text: ref.TextFields})
+		}
+	}
+
+	names := make([]string, 0, len(schemaIndexes))
+	for key := range schemaIndexes {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+
+	for _, ref := range scan.ODMRefs {
+		if ref.Kind != scanner.ODMIndexSync {
+			continue
+		}
+		collection := ref.Collection
+		if collection == "" {
+			collection = models[ref.Model]
+		}
+		target := ref.Model
+		if collection != "" {
+			target = collection
+		}
+		f := Finding{
+			Type:     FindingODMStartupIndexBuild,
+			Severity: SeverityMedium,
+			Message: fmt.Sprintf("%s %s builds the indexes of %s from the app; index builds on large collections load the primary and hold up startup — run them as a deploy step",
+				odmLabels[ref.ODM], ref.Call, target),
+			Collection: collection,
+			File:       ref.File,
+			Line:       ref.Line,
+		}
+		var dropped []string
+		if key := strings.ToLower(collection); ref.Call == "syncIndexes" && len(schemaIndexes[key]) > 0 {
+			for _, coll := range live[key] {
+				dropped = append(dropped, undeclaredIndexes(coll, declared[key])...)
+			}
+		}
+		if len(dropped) > 0 {
+			f.Severity = SeverityHigh
+			f.Message += fmt.Sprintf(", and it drops the live indexes the schema does not declare: %s", strings.Join(dropped, ", "))
+		}
+		findings = append(findings, f)
+	}
+
+	for _, key := range names {
+		for _, coll := range live[key] {
+			for _, ref := range schemaIndexes[key] {
+				if coll.Indexes == nil || hasIndexOn(coll.Indexes, declaredKey{fields: ref.Fields, text: ref.TextFields}) {
+					continue
+				}
+				findings = append(findings, Finding{
+					Type:       FindingODMIndexDrift,
+					Severity:   SeverityMedium,
+					Database:   coll.Database,
+					Collection: coll.Name,
+					Message: fmt.Sprintf("%s schema declares an index on (%s), but the collection has no index with that key",
+						odmLabels[ref.ODM], strings.Join(ref.Fields, ", ")),
+					File: ref.File,
+					Line: ref.Line,
+				})
+			}
+			extra := undeclaredIndexes(coll, declared[key])
+			if len(extra) == 0 {
+				continue
+			}
+			first := schemaIndexes[key][0]
+			findings = append(findings, Finding{
+				Type:       FindingODMIndexDrift,
+				Severity:   SeverityLow,
+				Database:   coll.Database,
+				Collection: coll.Name,
+				Message: fmt.Sprintf("live indexes not declared in the %s schema or by createIndex in code: %s; declare them so rebuilt or synced environments keep them",
+					odmLabels[first.ODM], strings.Join(extra, ", ")),
+				File: first.File,
+				Line: first.Line,
+			})
+		}
+	}
+	return findings
+}
+
+// declaredKey is an index key declared in code: its fields in order, and
+// those of them that are text keys.
+type declaredKey struct {
+	fields []string
+	text   []string
+}
+
+// hasIndexOn reports whether one of indexes has the declared key.
+func hasIndexOn(indexes []mongoinspect.IndexInfo, key declaredKey) bool {
+	for i := range indexes {
+		if keyMatches(&indexes[i], key) {
+			return true
+		}
+	}
+	return false
+}
+
+// undeclaredIndexes returns the names of the indexes of coll, other than
+// _id_, whose key is none of the declared keys.
+func undeclaredIndexes(coll *mongoinspect.CollectionInfo, declared []declaredKey) []string {
+	var names []string
+	for i := range coll.Indexes {
+		idx := &coll.Indexes[i]
+		isDeclared := slices.ContainsFunc(declared, func(key declaredKey) bool { return keyMatches(idx, key) })
+		if idx.Name != "_id_" && !isDeclared {
+			names = append(names, idx.Name)
+		}
+	}
+	return names
+}
+
+// keyMatches reports whether idx has the declared key. listIndexes reports
+// the keys of a text index as _fts/_ftsx, so a declared text key matches a
+// text index over the same fields (when its weights are known) with the
+// same other keys in order.
+func keyMatches(idx *mongoinspect.IndexInfo, key declaredKey) bool {
+	if len(key.text) == 0 {
+		return keyFieldsEqual(idx.Key, key.fields)
+	}
+	if !isTextIndex(idx) {
+		return false
+	}
+	if len(idx.TextFields) > 0 && textFieldSignature(idx.TextFields) != textFieldSignature(key.text) {
+		return false
+	}
+	var live, fields []string
+	for _, kf := range idx.Key {
+		if kf.Field != textIndexKeyField && kf.Field != textIndexKeyField+"x" {
+			live = append(live, kf.Field)
+		}
+	}
+	for _, field := range key.fields {
+		if !slices.Contains(key.text, field) {
+			fields = append(fields, field)
+		}
+	}
+	return slices.Equal(live, fields)
+}
+
+func keyFieldsEqual(key []mongoinspect.KeyField, fields []string) bool {
+	if len(key) != len(fields) {
+		return false
+	}
+	for i, k := range key {
+		if k.Field != fields[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+func TestDetectODMRisks(t *testing.T) {
+	scan := &scanner.ScanResult{
+		ODMRefs: []scanner.ODMRef{
+			{ODM: scanner.ODMMongoose, Kind: scanner.ODMModel, Model: "User", Collection: "accounts", File: "models.js", Line: 14},
+			{ODM: scanner.ODMMongoose, Kind: scanner.ODMIndex, Model: "userSchema", Collection: "accounts", Fields: []string{"email"}, Unique: true, File: "models.js", Line: 5},
+			{ODM: scanner.ODMMongoose, Kind: scanner.ODMIndex, Model: "userSchema", Collection: "accounts", Fields: []string{"tenantId", "createdAt"}, File: "models.js", Line: 13},
+			{ODM: scanner.ODMMongoose, Kind: scanner.ODMIndexSync, Model: "User", Call: "syncIndexes", File: "app.js", Line: 30},
+			{ODM: scanner.ODMSpringData, Kind: scanner.ODMAutoIndex, Enabled: true, File: "application.properties", Line: 2},
+		},
+		IndexRefs: []scanner.IndexRef{{Collection: "accounts", Fields: []string{"lastLogin"}, File: "migrate.js", Line: 3}},
+	}
+	collections := []mongoinspect.CollectionInfo{{
+		Database: "app",
+		Name:     "accounts",
+		Indexes: []mongoinspect.IndexInfo{
+			{Name: "_id_", Key: []mongoinspect.KeyField{{Field: "_id", Direction: 1}}},
+			{Name: "email_1", Key: []mongoinspect.KeyField{{Field: "email", Direction: 1}}, Unique: true},
+			{Name: "lastLogin_1", Key: []mongoinspect.KeyField{{Field: "lastLogin", Direction: 1}}},
+			{Name: "legacy_1", Key: []mongoinspect.KeyField{{Field: "legacy", Direction: 1}}},
+		},
+	}}
+
+	findings := DetectODMRisks(scan, collections)
+	if len(findings) != 5 {
+		t.Fatalf("got %d findings, want 5: %+v", len(findings), findings)
+	}
+	if f := findings[0]; f.Type != FindingODMAutoIndex || f.File != "application.properties" || !strings.Contains(f.Message, "Spring Data") {
+		t.Errorf("spring auto-index finding = %+v", f)
+	}
+	if f := findings[1]; f.Type != FindingODMAutoIndex || f.File != "models.js" || f.Line != 5 ||
+		!strings.Contains(f.Message, "Mongoose builds the 2 schema indexes") {
+		t.Errorf("mongoose default autoIndex finding = %+v", f)
+	}
+	if f := findings[2]; f.Type != FindingODMStartupIndexBuild || f.Severity != SeverityHigh || f.Collection != "accounts" ||
+		!strings.HasSuffix(f.Message, "drops the live indexes the schema does not declare: legacy_1") {
+		t.Errorf("syncIndexes finding = %+v", f)
+	}
+	if f := findings[3]; f.Type != FindingODMIndexDrift || f.Severity != SeverityMedium || f.Line != 13 ||
+		!strings.Contains(f.Message, "index on (tenantId, createdAt)") {
+		t.Errorf("missing index finding = %+v", f)
+	}
+	if f := findings[4]; f.Type != FindingODMIndexDrift || f.Severity != SeverityLow || f.Database != "app" ||
+		!strings.Contains(f.Message, ": legacy_1;") {
+		t.Errorf("undeclared index finding = %+v", f)
+	}
+
+	// autoIndex: false silences the Mongoose default; other ODMs have no default finding.
+	scan.ODMRefs = append(scan.ODMRefs, scanner.ODMRef{ODM: scanner.ODMMongoose, Kind: scanner.ODMAutoIndex, File: "db.js", Line: 2})
+	for _, f := range DetectODMRisks(scan, collections) {
+		if f.Type == FindingODMAutoIndex && f.File == "models.js" {
+			t.Errorf("unexpected default autoIndex finding: %+v", f)
+		}
+	}
+	if got := DetectODMRisks(&scanner.ScanResult{}, collections); got != nil {
+		t.Errorf("expected no findings without ODM refs, got %+v", got)
+	}
+}
+
+func TestDetectODMRisksMongooseTextTTLAndTimestamps(t *testing.T) {
+	scan := &scanner.ScanResult{
+		ODMRefs: []scanner.ODMRef{
+			{ODM: scanner.ODMMongoose, Kind: scanner.ODMAutoIndex, File: "db.js", Line: 2},
+			{ODM: scanner.ODMMongoose, Kind: scanner.ODMIndex, Model: "postSchema", Collection: "posts", Fields: []string{"title", "body", "tenantId"}, TextFields: []string{"title", "body"}, File: "models.js", Line: 4},
+			{ODM: scanner.ODMMongoose, Kind: scanner.ODMIndex, Model: "postSchema", Collection: "posts", Fields: []string{"summary"}, TextFields: []string{"summary"}, File: "models.js", Line: 5},
+			{ODM: scanner.ODMMongoose, Kind: scanner.ODMIndex, Model: "postSchema", Collection: "posts", Fields: []string{"expiresAt"}, File: "models.js", Line: 6},
+			{ODM: scanner.ODMMongoose, Kind: scanner.ODMTimestamps, Model: "postSchema", Collection: "posts", Fields: []string{"createdAt", "updatedAt"}, File: "models.js", Line: 7},
+		},
+	}
+	collections := []mongoinspect.CollectionInfo{{
+		Database: "app",
+		Name:     "posts",
+		Indexes: []mongoinspect.IndexInfo{
+			{Name: "_id_", Key: []mongoinspect.KeyField{{Field: "_id", Direction: 1}}},
+			{Name: "title_text_body_text_tenantId_1", Key: []mongoinspect.KeyField{{Field: "_fts"}, {Field: "_ftsx"}, {Field: "tenantId", Direction: 1}},
+				TextFields: []string{"body", "title"}},
+			{Name: "expiresAt_1", Key: []mongoinspect.KeyField{{Field: "expiresAt", Direction: 1}}},
+			{Name: "createdAt_1", Key: []mongoinspect.KeyField{{Field: "createdAt", Direction: -1}}},
+		},
+	}}
+
+	findings := DetectODMRisks(scan, collections)
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1: %+v", len(findings), findings)
+	}
+	// The text index over summary is missing; the one over title and body
+	// is not, and the TTL and timestamp indexes are declared.
+	if f := findings[0]; f.Type != FindingODMIndexDrift || f.Severity != SeverityMedium || f.Line != 5 {
+		t.Errorf("missing text index finding = %+v", f)
+	}
+}
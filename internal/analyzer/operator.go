@@ -0,0 +1,270 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+// OperatorLive is the live deployment compared with the Kubernetes
+// operator resources of the repo.
+type OperatorLive struct {
+	Version     string
+	ClusterName string                       // first host label of the connection string
+	ReplicaSet  *mongoinspect.ReplicaSetInfo // nil when unavailable
+	// Users and Roles are keyed by database; databases that could not be
+	// read are missing.
+	Users map[string][]mongoinspect.UserInfo
+	Roles map[string][]mongoinspect.RoleInfo
+}
+
+// operatorAgentUsers are the users operators create for their own agents.
+var operatorAgentUsers = map[string]bool{
+	"mms-automation":              true,
+	"mms-automation-agent":        true,
+	"mms-monitoring-agent":        true,
+	"mms-backup-agent":            true,
+	"mms-backup-monitoring-agent": true,
+}
+
+// DetectOperatorDrift compares the MongoDBCommunity, MongoDB or
+// AtlasDeployment resource describing the live deployment, and the user
+// resources bound to it, with the server:
+//   - OPERATOR_CONFIG_DRIFT for a declared version, member count or
+//     replica set topology the server does not have;
+//   - OPERATOR_USER_DRIFT for declared users or custom roles missing on
+//     the server, users whose roles differ from the declared ones, and
+//     (low) users the resources do not declare.
+//
+// The resource is the one named like the replica set or the host of the
+// connection string, or the only deployment resource in the repo.
+func DetectOperatorDrift(scan *scanner.ScanResult, live *OperatorLive) []Finding {
+	deployment := matchOperatorDeployment(scan.OperatorRefs, live)
+	if deployment == nil {
+		return nil
+	}
+	label := fmt.Sprintf("%s %q", deployment.Kind, deployment.Name)
+	configDrift := func(msg string) Finding {
+		return Finding{
+			Type:     FindingOperatorConfigDrift,
+			Severity: SeverityMedium,
+			Message:  fmt.Sprintf("%s %s; the operator has not reconciled the resource, or the manifest in the repo is not the one deployed", label, msg),
+			File:     deployment.File,
+			Line:     deployment.Line,
+		}
+	}
+
+	var findings []Finding
+	if deployment.Version != "" && live.Version != "" && !versionMatches(deployment.Version, live.Version) {
+		findings = append(findings, configDrift(fmt.Sprintf("declares MongoDB %s, but the server runs %s", deployment.Version, live.Version)))
+	}
+	if rs := live.ReplicaSet; rs != nil {
+		declaresReplicaSet := strings.EqualFold(deployment.Topology, "ReplicaSet") || deployment.Kind == scanner.CRDMongoDBCommunity
+		switch {
+		case rs.Name == "" && declaresReplicaSet:
+			findings = append(findings, configDrift("declares a replica set, but the server is a standalone"))
+		case rs.Name != "" && deployment.Members > 0:
+			dataBearing := 0
+			for _, m := range rs.Members {
+				if m.StateStr != "ARBITER" {
+					dataBearing++
+				}
+			}
+			if dataBearing != deployment.Members {
+				findings = append(findings, configDrift(fmt.Sprintf("declares %d members, but replica set %s has %d data-bearing members",
+					deployment.Members, rs.Name, dataBearing)))
+			}
+		}
+	}
+
+	// Users declared inline and in user resources bound to the deployment.
+	type declaredUser struct {
+		scanner.OperatorUser
+		ref *scanner.OperatorRef
+	}
+	var users []declaredUser
+	for i := range scan.OperatorRefs {
+		ref := &scan.OperatorRefs[i]
+		bound := ref == deployment ||
+			(ref.Kind == scanner.CRDMongoDBUser && strings.EqualFold(ref.Cluster, deployment.Name)) ||
+			(ref.Kind == scanner.CRDAtlasDatabaseUser && deployment.Kind == scanner.CRDAtlasDeployment &&
+				(ref.Cluster == "" || strings.EqualFold(ref.Cluster, deployment.Name)))
+		if !bound {
+			continue
+		}
+		for _, u := range ref.Users {
+			if u.Name != "" {
+				users = append(users, declaredUser{u, ref})
+			}
+		}
+	}
+
+	declared := make(map[string]map[string]bool) // by database
+	for _, u := range users {
+		if declared[u.DB] == nil {
+			declared[u.DB] = make(map[string]bool)
+		}
+		declared[u.DB][u.Name] = true
+		liveUsers, ok := live.Users[u.DB]
+		if !ok {
+			continue
+		}
+		source := fmt.Sprintf("%s %q", u.ref.Kind, u.ref.Name)
+		f := Finding{
+			Type:     FindingOperatorUserDrift,
+			Severity: SeverityMedium,
+			Database: u.DB,
+			File:     u.ref.File,
+			Line:     u.Line,
+		}
+		idx := -1
+		for i := range liveUsers {
+			if liveUsers[i].Username == u.Name {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			f.Message = fmt.Sprintf("%s declares user %q on %s, but the server has no such user; the operator has not reconciled the resource, or the manifest in the repo is not the one deployed",
+				source, u.Name, u.DB)
+			findings = append(findings, f)
+			continue
+		}
+		missing, extra := diffOperatorRoles(u.Roles, liveUsers[idx].Roles)
+		var problems []string
+		if len(missing) > 0 {
+			problems = append(problems, "lacks the declared roles "+strings.Join(missing, ", "))
+		}
+		if len(extra) > 0 {
+			problems = append(problems, "has roles the resource does not declare: "+strings.Join(extra, ", "))
+		}
+		if len(problems) > 0 {
+			f.Message = fmt.Sprintf("user %q on %s %s (declared in %s); roles granted or revoked by hand are reverted when the operator reconciles",
+				u.Name, u.DB, strings.Join(problems, " and "), source)
+			findings = append(findings, f)
+		}
+	}
+
+	dbs := make([]string, 0, len(declared))
+	for db := range declared {
+		dbs = append(dbs, db)
+	}
+	sort.Strings(dbs)
+	for _, db := range dbs {
+		for _, lu := range live.Users[db] {
+			if declared[db][lu.Username] || operatorAgentUsers[lu.Username] {
+				continue
+			}
+			findings = append(findings, Finding{
+				Type:     FindingOperatorUserDrift,
+				Severity: SeverityLow,
+				Database: db,
+				Message: fmt.Sprintf("user %q on %s is not declared in %s or a user resource bound to it; it was created by hand and is not managed by the operator",
+					lu.Username, db, label),
+				File: deployment.File,
+				Line: deployment.Line,
+			})
+		}
+	}
+
+	for _, role := range deployment.Roles {
+		liveRoles, ok := live.Roles[role.DB]
+		if !ok {
+			continue
+		}
+		found := false
+		for _, lr := range liveRoles {
+			if lr.Role == role.Role {
+				found = true
+				break
+			}
+		}
+		if !found {
+			findings = append(findings, Finding{
+				Type:     FindingOperatorUserDrift,
+				Severity: SeverityMedium,
+				Database: role.DB,
+				Message:  fmt.Sprintf("%s declares custom role %q on %s, but the server has no such role", label, role.Role, role.DB),
+				File:     deployment.File,
+				Line:     deployment.Line,
+			})
+		}
+	}
+	return findings
+}
+
+// matchOperatorDeployment returns the deployment resource named like the
+// live replica set or connection host. The only deployment resource is
+// used when neither name is known; when they are known but match no
+// resource, the repo describes another deployment and nil is returned.
+func matchOperatorDeployment(refs []scanner.OperatorRef, live *OperatorLive) *scanner.OperatorRef {
+	var names []string
+	if live.ReplicaSet != nil && live.ReplicaSet.Name != "" {
+		names = append(names, live.ReplicaSet.Name)
+	}
+	if live.ClusterName != "" {
+		// The Community operator serves a deployment as "<name>-svc".
+		names = append(names, live.ClusterName, strings.TrimSuffix(live.ClusterName, "-svc"))
+	}
+
+	var deployments []*scanner.OperatorRef
+	for i := range refs {
+		switch refs[i].Kind {
+		case scanner.CRDMongoDBCommunity, scanner.CRDMongoDB, scanner.CRDAtlasDeployment:
+			deployments = append(deployments, &refs[i])
+		}
+	}
+	for _, d := range deployments {
+		for _, name := range names {
+			if strings.EqualFold(d.Name, name) {
+				return d
+			}
+		}
+	}
+	if len(names) == 0 && len(deployments) == 1 {
+		return deployments[0]
+	}
+	return nil
+}
+
+// versionMatches reports whether live is the declared version, or a
+// release of it when only major.minor is declared.
+func versionMatches(declared, live string) bool {
+	d, l := normalizeVersion(declared), normalizeVersion(live)
+	if d == "" || l == "" {
+		return true
+	}
+	if strings.Count(strings.TrimSpace(declared), ".") == 1 {
+		return strings.Join(strings.Split(d, ".")[:2], ".") == strings.Join(strings.Split(l, ".")[:2], ".")
+	}
+	return d == l
+}
+
+// diffOperatorRoles returns the declared roles the user lacks and the
+// granted roles not declared, as role@db.
+func diffOperatorRoles(declared []scanner.OperatorRole, granted []mongoinspect.UserRole) (missing, extra []string) {
+	want := make(map[string]bool, len(declared))
+	for _, r := range declared {
+		want[r.Role+"@"+r.DB] = true
+	}
+	have := make(map[string]bool, len(granted))
+	for _, r := range granted {
+		have[r.Role+"@"+r.DB] = true
+	}
+	for key := range want {
+		if !have[key] {
+			missing = append(missing, key)
+		}
+	}
+	for key := range have {
+		if !want[key] {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(extra)
+	return missing, extra
+}
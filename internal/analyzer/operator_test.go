@@ -0,0 +1,95 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+func TestDetectOperatorDrift(t *testing.T) {
+	scan := &scanner.ScanResult{OperatorRefs: []scanner.OperatorRef{
+		{Kind: scanner.CRDMongoDBCommunity, Name: "staging-db", Version: "6.0.5", Members: 1, File: "k8s/staging.yaml", Line: 2},
+		{Kind: scanner.CRDMongoDBCommunity, Name: "shop-db", Version: "6.0.5", Members: 3, Topology: "ReplicaSet", File: "k8s/mongodb.yaml", Line: 7,
+			Users: []scanner.OperatorUser{
+				{Name: "app", DB: "admin", Roles: []scanner.OperatorRole{{Role: "readWrite", DB: "shop"}}, Line: 22},
+				{Name: "reporting", DB: "admin", Roles: []scanner.OperatorRole{{Role: "orderReader", DB: "shop"}}, Line: 29},
+			},
+			Roles: []scanner.OperatorRole{{Role: "orderReader", DB: "shop"}},
+		},
+		{Kind: scanner.CRDMongoDBUser, Name: "etl", Cluster: "shop-db", File: "k8s/user.yml", Line: 2, Users: []scanner.OperatorUser{
+			{Name: "etl", DB: "admin", Roles: []scanner.OperatorRole{{Role: "read", DB: "shop"}}, Line: 2},
+		}},
+		{Kind: scanner.CRDMongoDBUser, Name: "staging-etl", Cluster: "staging-db", File: "k8s/staging.yaml", Line: 20, Users: []scanner.OperatorUser{
+			{Name: "staging-etl", DB: "admin", Line: 20},
+		}},
+	}}
+	live := &OperatorLive{
+		Version: "6.0.4",
+		ReplicaSet: &mongoinspect.ReplicaSetInfo{Name: "shop-db", Members: []mongoinspect.ReplicaSetMember{
+			{StateStr: "PRIMARY"}, {StateStr: "SECONDARY"}, {StateStr: "ARBITER"},
+		}},
+		Users: map[string][]mongoinspect.UserInfo{"admin": {
+			{Username: "app", Database: "admin", Roles: []mongoinspect.UserRole{{Role: "readWrite", DB: "shop"}, {Role: "dbAdmin", DB: "shop"}}},
+			{Username: "etl", Database: "admin", Roles: []mongoinspect.UserRole{{Role: "read", DB: "shop"}}},
+			{Username: "debug", Database: "admin"},
+			{Username: "mms-automation", Database: "admin"},
+		}},
+		Roles: map[string][]mongoinspect.RoleInfo{"shop": {}},
+	}
+
+	findings := DetectOperatorDrift(scan, live)
+	var messages []string
+	for _, f := range findings {
+		messages = append(messages, string(f.Type)+"|"+string(f.Severity)+"|"+f.Message)
+	}
+	want := []string{
+		`OPERATOR_CONFIG_DRIFT|medium|MongoDBCommunity "shop-db" declares MongoDB 6.0.5, but the server runs 6.0.4`,
+		`OPERATOR_CONFIG_DRIFT|medium|MongoDBCommunity "shop-db" declares 3 members, but replica set shop-db has 2 data-bearing members`,
+		`OPERATOR_USER_DRIFT|medium|user "app" on admin has roles the resource does not declare: dbAdmin@shop`,
+		`OPERATOR_USER_DRIFT|medium|MongoDBCommunity "shop-db" declares user "reporting" on admin, but the server has no such user`,
+		`OPERATOR_USER_DRIFT|low|user "debug" on admin is not declared in MongoDBCommunity "shop-db"`,
+		`OPERATOR_USER_DRIFT|medium|MongoDBCommunity "shop-db" declares custom role "orderReader" on shop, but the server has no such role`,
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("expected %d findings, got %d:\n%s", len(want), len(findings), strings.Join(messages, "\n"))
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(messages[i], w) {
+			t.Errorf("finding %d = %q, want prefix %q", i, messages[i], w)
+		}
+	}
+	if findings[3].File != "k8s/mongodb.yaml" || findings[3].Line != 29 {
+		t.Errorf("missing user finding should point at the user, got %s:%d", findings[3].File, findings[3].Line)
+	}
+}
+
+func TestDetectOperatorDriftMatching(t *testing.T) {
+	atlas := scanner.OperatorRef{Kind: scanner.CRDAtlasDeployment, Name: "Cluster0", Version: "7.0", File: "atlas.yaml", Line: 2}
+	other := scanner.OperatorRef{Kind: scanner.CRDAtlasDeployment, Name: "Analytics", Version: "6.0", File: "atlas.yaml", Line: 12}
+
+	// Matched by the connection host; a 7.0 major version accepts any 7.0 release.
+	scan := &scanner.ScanResult{OperatorRefs: []scanner.OperatorRef{other, atlas}}
+	if findings := DetectOperatorDrift(scan, &OperatorLive{Version: "7.0.12", ClusterName: "cluster0"}); len(findings) != 0 {
+		t.Fatalf("expected no findings, got %+v", findings)
+	}
+	// Ambiguous: several deployments and none named like the live one.
+	if findings := DetectOperatorDrift(scan, &OperatorLive{Version: "5.0.1", ClusterName: "db"}); len(findings) != 0 {
+		t.Fatalf("expected no findings for an unmatched deployment, got %+v", findings)
+	}
+	// The only deployment in the repo is not compared with a live one named otherwise.
+	scan = &scanner.ScanResult{OperatorRefs: []scanner.OperatorRef{atlas}}
+	if findings := DetectOperatorDrift(scan, &OperatorLive{Version: "6.0.8", ClusterName: "db"}); len(findings) != 0 {
+		t.Fatalf("expected no findings for a live deployment named otherwise, got %+v", findings)
+	}
+	rs := &mongoinspect.ReplicaSetInfo{Name: "rs0"}
+	if findings := DetectOperatorDrift(scan, &OperatorLive{Version: "6.0.8", ReplicaSet: rs}); len(findings) != 0 {
+		t.Fatalf("expected no findings for a replica set named otherwise, got %+v", findings)
+	}
+	// Without a live name, the only deployment in the repo is compared.
+	findings := DetectOperatorDrift(scan, &OperatorLive{Version: "6.0.8"})
+	if len(findings) != 1 || findings[0].Type != FindingOperatorConfigDrift {
+		t.Fatalf("expected one OPERATOR_CONFIG_DRIFT finding, got %+v", findings)
+	}
+}
@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+const (
+	// writeHotspotShare is the share of sampled oplog writes that makes a
+	// single collection a hotspot.
+	writeHotspotShare = 0.5
+
+	// writeHotspotMinWrites keeps small or quiet samples from producing
+	// hotspots.
+	writeHotspotMinWrites int64 = 1000
+)
+
+// AuditOplog flags collections that receive most of the sampled writes.
+func AuditOplog(profile *mongoinspect.OplogProfile) []Finding {
+	if profile.Writes < writeHotspotMinWrites {
+		return nil
+	}
+	var findings []Finding
+	for i := range profile.Namespaces {
+		ns := &profile.Namespaces[i]
+		share := float64(ns.Writes()) / float64(profile.Writes)
+		if share < writeHotspotShare {
+			continue
+		}
+		findings = append(findings, Finding{
+			Type:       FindingWriteHotspot,
+			Severity:   SeverityLow,
+			Database:   ns.Database,
+			Collection: ns.Collection,
+			Message: fmt.Sprintf("collection received %.0f%% of sampled oplog writes (%d of %d over %s: %s)",
+				share*100, ns.Writes(), profile.Writes, formatElapsed(profile.Window()), writeMix(ns)),
+		})
+	}
+	return findings
+}
+
+// DetectShadowWriters flags collections that receive writes in the sampled
+// oplog but are never referenced in code: something other than this
+// repository, such as another service or a forgotten job, writes to them.
+// Without any collection references in the scan there is nothing to
+// compare against, so nothing is flagged.
+func DetectShadowWriters(scan *scanner.ScanResult, profile *mongoinspect.OplogProfile) []Finding {
+	if len(scan.Collections) == 0 {
+		return nil
+	}
+	codeRefs := codeRefSet(scan)
+	var findings []Finding
+	for i := range profile.Namespaces {
+		ns := &profile.Namespaces[i]
+		if codeRefs[strings.ToLower(ns.Collection)] {
+			continue
+		}
+		findings = append(findings, Finding{
+			Type:       FindingShadowWriter,
+			Severity:   SeverityMedium,
+			Database:   ns.Database,
+			Collection: ns.Collection,
+			Message: fmt.Sprintf("collection received %d writes in the sampled oplog window (%s: %s) but is not referenced in code",
+				ns.Writes(), formatElapsed(profile.Window()), writeMix(ns)),
+		})
+	}
+	return findings
+}
+
+func writeMix(ns *mongoinspect.NamespaceWrites) string {
+	return fmt.Sprintf("%d inserts, %d updates, %d deletes", ns.Inserts, ns.Updates, ns.Deletes)
+}
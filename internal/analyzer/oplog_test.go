@@ -0,0 +1,71 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+func oplogProfile(namespaces ...mongoinspect.NamespaceWrites) *mongoinspect.OplogProfile {
+	p := &mongoinspect.OplogProfile{
+		First:      time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Last:       time.Date(2026, 3, 1, 12, 45, 0, 0, time.UTC),
+		Namespaces: namespaces,
+	}
+	for i := range namespaces {
+		p.Writes += namespaces[i].Writes()
+	}
+	return p
+}
+
+func TestAuditOplogHotspot(t *testing.T) {
+	p := oplogProfile(
+		mongoinspect.NamespaceWrites{Database: "app", Collection: "events", Inserts: 5800, Updates: 400},
+		mongoinspect.NamespaceWrites{Database: "app", Collection: "users", Updates: 3800},
+	)
+	findings := AuditOplog(p)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d: %+v", len(findings), findings)
+	}
+	f := findings[0]
+	if f.Type != FindingWriteHotspot || f.Collection != "events" || f.Severity != SeverityLow {
+		t.Errorf("finding = %+v", f)
+	}
+	want := "collection received 62% of sampled oplog writes (6200 of 10000 over 45 minutes: 5800 inserts, 400 updates, 0 deletes)"
+	if f.Message != want {
+		t.Errorf("message = %q, want %q", f.Message, want)
+	}
+}
+
+func TestAuditOplogSmallSample(t *testing.T) {
+	p := oplogProfile(mongoinspect.NamespaceWrites{Database: "app", Collection: "events", Inserts: 900})
+	if findings := AuditOplog(p); len(findings) != 0 {
+		t.Errorf("expected no findings below %d writes, got %+v", writeHotspotMinWrites, findings)
+	}
+}
+
+func TestDetectShadowWriters(t *testing.T) {
+	p := oplogProfile(
+		mongoinspect.NamespaceWrites{Database: "app", Collection: "Users", Updates: 10},
+		mongoinspect.NamespaceWrites{Database: "app", Collection: "legacy_sync", Inserts: 25, Deletes: 5},
+	)
+	scan := &scanner.ScanResult{Collections: []string{"users"}}
+	findings := DetectShadowWriters(scan, p)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d: %+v", len(findings), findings)
+	}
+	f := findings[0]
+	if f.Type != FindingShadowWriter || f.Collection != "legacy_sync" || f.Severity != SeverityMedium {
+		t.Errorf("finding = %+v", f)
+	}
+	if !strings.Contains(f.Message, "30 writes") || !strings.Contains(f.Message, "not referenced in code") {
+		t.Errorf("message = %q", f.Message)
+	}
+
+	if got := DetectShadowWriters(&scanner.ScanResult{}, p); len(got) != 0 {
+		t.Errorf("a scan without references should flag nothing, got %+v", got)
+	}
+}
@@ -0,0 +1,64 @@
+package analyzer
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// OwnerRule assigns Owner to findings on collections matching Database and
+// Collection, glob patterns in path.Match syntax. An empty pattern matches
+// anything, so a rule with neither is a default owner.
+type OwnerRule struct {
+	Database   string
+	Collection string
+	Owner      string
+}
+
+// OwnerMap assigns owners to findings by database and collection, for
+// findings that have no code location to look up in CODEOWNERS. The first
+// matching rule wins.
+type OwnerMap []OwnerRule
+
+// NewOwnerMap validates rules and returns them as an OwnerMap.
+func NewOwnerMap(rules []OwnerRule) (OwnerMap, error) {
+	m := make(OwnerMap, 0, len(rules))
+	for i, r := range rules {
+		r.Owner = strings.TrimSpace(r.Owner)
+		if r.Owner == "" {
+			return nil, fmt.Errorf("rule %d: owner is required", i+1)
+		}
+		for _, p := range []string{r.Database, r.Collection} {
+			if _, err := path.Match(p, ""); err != nil {
+				return nil, fmt.Errorf("rule %d: invalid pattern %q: %w", i+1, p, err)
+			}
+		}
+		m = append(m, r)
+	}
+	return m, nil
+}
+
+// Owner returns the owner of a collection, or "" when no rule matches.
+func (m OwnerMap) Owner(database, collection string) string {
+	for _, r := range m {
+		if globMatch(r.Database, database) && globMatch(r.Collection, collection) {
+			return r.Owner
+		}
+	}
+	return ""
+}
+
+// Assign sets f.Owner from the map unless the finding already has one.
+func (m OwnerMap) Assign(f *Finding) {
+	if f.Owner == "" {
+		f.Owner = m.Owner(f.Database, f.Collection)
+	}
+}
+
+func globMatch(pattern, name string) bool {
+	if pattern == "" {
+		return true
+	}
+	ok, _ := path.Match(pattern, name)
+	return ok
+}
@@ -0,0 +1,47 @@
+package analyzer
+
+import "testing"
+
+func TestOwnerMap(t *testing.T) {
+	m, err := NewOwnerMap([]OwnerRule{
+		{Database: "billing", Collection: "invoice*", Owner: "@org/billing"},
+		{Database: "billing", Owner: "@org/payments"},
+		{Collection: "audit_*", Owner: "@org/security"},
+		{Owner: " @org/platform "},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct{ db, coll, want string }{
+		{"billing", "invoices", "@org/billing"},
+		{"billing", "refunds", "@org/payments"},
+		{"shop", "audit_log", "@org/security"},
+		{"shop", "orders", "@org/platform"},
+		{"", "", "@org/platform"},
+	} {
+		if got := m.Owner(tc.db, tc.coll); got != tc.want {
+			t.Errorf("Owner(%q, %q) = %q, want %q", tc.db, tc.coll, got, tc.want)
+		}
+	}
+
+	f := Finding{Database: "billing", Collection: "invoices", Owner: "@org/billing-code"}
+	m.Assign(&f)
+	if f.Owner != "@org/billing-code" {
+		t.Errorf("Assign overwrote a CODEOWNERS owner: %q", f.Owner)
+	}
+	var empty OwnerMap
+	f = Finding{Database: "billing", Collection: "invoices"}
+	empty.Assign(&f)
+	if f.Owner != "" {
+		t.Errorf("empty map assigned %q", f.Owner)
+	}
+}
+
+func TestNewOwnerMapInvalid(t *testing.T) {
+	if _, err := NewOwnerMap([]OwnerRule{{Database: "billing"}}); err == nil {
+		t.Error("expected an error for a rule without owner")
+	}
+	if _, err := NewOwnerMap([]OwnerRule{{Collection: "[", Owner: "@org/x"}}); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
@@ -0,0 +1,169 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+// partialIndexMinQueries is how many query sites must share a constant
+// predicate before a partial index is suggested.
+const partialIndexMinQueries = 2
+
+// constantPredicate tracks the literals one field is compared to across the
+// query sites of a collection.
+type constantPredicate struct {
+	name     string
+	values   map[string]bool
+	contexts []string // query context keys using the constant
+	variable bool     // some query uses the field without a constant
+}
+
+// suggestPartialIndexes finds fields that every query compares to the same
+// literal (status: "active", deleted: false) and suggests indexing the
+// remaining query fields with that literal as the partialFilterExpression,
+// which keeps non-matching documents out of the index.
+func suggestPartialIndexes(scan *scanner.ScanResult, collections []mongoinspect.CollectionInfo) []Finding {
+	if scan == nil || len(scan.FieldRefs) == 0 {
+		return nil
+	}
+
+	contextsByCollection := buildQueryContexts(scan.FieldRefs)
+	predicatesByCollection := make(map[string]map[string]*constantPredicate)
+	for _, ref := range scan.FieldRefs {
+		if ref.Field == "" || !isQueryableUsage(ref.Usage) {
+			continue
+		}
+		collKey := strings.ToLower(ref.Collection)
+		if predicatesByCollection[collKey] == nil {
+			predicatesByCollection[collKey] = make(map[string]*constantPredicate)
+		}
+		fieldKey := strings.ToLower(ref.Field)
+		pred := predicatesByCollection[collKey][fieldKey]
+		if pred == nil {
+			pred = &constantPredicate{name: ref.Field, values: make(map[string]bool)}
+			predicatesByCollection[collKey][fieldKey] = pred
+		}
+		if ref.Usage != scanner.FieldUsageEquality || ref.Constant == "" {
+			pred.variable = true
+			continue
+		}
+		pred.values[ref.Constant] = true
+		ctxKey := ref.File + ":" + strconv.Itoa(ref.Line) + ":" + strings.ToLower(strings.TrimSpace(ref.QueryContext))
+		pred.contexts = append(pred.contexts, ctxKey)
+	}
+
+	collNames := make([]string, 0, len(predicatesByCollection))
+	for name := range predicatesByCollection {
+		collNames = append(collNames, name)
+	}
+	sort.Strings(collNames)
+
+	var findings []Finding
+	for _, collName := range collNames {
+		coll, found := findCollection(collName, collections)
+		if !found || coll.DocCount < thresholdsFor(coll.Database).SuggestMinDocs {
+			continue
+		}
+		preds := predicatesByCollection[collName]
+		fieldKeys := make([]string, 0, len(preds))
+		for k := range preds {
+			fieldKeys = append(fieldKeys, k)
+		}
+		sort.Strings(fieldKeys)
+
+		for _, fieldKey := range fieldKeys {
+			pred := preds[fieldKey]
+			if pred.variable || len(pred.values) != 1 || len(pred.contexts) < partialIndexMinQueries {
+				continue
+			}
+			if hasPartialIndexOn(coll.Indexes, pred.name) {
+				continue
+			}
+			key, files := partialIndexKey(contextsByCollection[collName], pred.contexts, fieldKey)
+			if len(key) == 0 {
+				continue
+			}
+			var value string
+			for v := range pred.values {
+				value = v
+			}
+			filter := fmt.Sprintf("{%s: %s}", pred.name, value)
+
+			replaces := ""
+			for _, idx := range coll.Indexes {
+				if idx.PartialFilter == "" && sameKeySetWithDirection(idx.Key, key) {
+					replaces = fmt.Sprintf("; could replace full index %q", idx.Name)
+					break
+				}
+			}
+			findings = append(findings, Finding{
+				Type:       FindingPartialIndexSuggest,
+				Severity:   SeverityInfo,
+				Database:   coll.Database,
+				Collection: coll.Name,
+				Message: fmt.Sprintf(
+					"consider partial index %s with partialFilterExpression %s: all %d queries on %q filter on %s (%s)%s",
+					formatIndexSpec(key), filter, len(pred.contexts), pred.name, value, strings.Join(files, ", "), replaces,
+				),
+			})
+		}
+	}
+	return findings
+}
+
+// partialIndexKey returns the most common ESR key among the given query
+// contexts with the predicate field removed, and the files those queries
+// appear in.
+func partialIndexKey(contexts map[string]*queryContext, ctxKeys []string, predicate string) ([]mongoinspect.KeyField, []string) {
+	counts := make(map[string]int)
+	keys := make(map[string][]mongoinspect.KeyField)
+	fileSet := make(map[string]bool)
+	for _, ctxKey := range ctxKeys {
+		ctx := contexts[ctxKey]
+		if ctx == nil {
+			continue
+		}
+		fileSet[ctx.file] = true
+		var key []mongoinspect.KeyField
+		for _, kf := range contextToESRKey(ctx) {
+			if strings.ToLower(kf.Field) != predicate {
+				key = append(key, kf)
+			}
+		}
+		if len(key) == 0 {
+			continue
+		}
+		sig := keySignature(key)
+		counts[sig]++
+		keys[sig] = key
+	}
+
+	var best string
+	for sig, n := range counts {
+		if best == "" || n > counts[best] || (n == counts[best] && sig < best) {
+			best = sig
+		}
+	}
+	files := make([]string, 0, len(fileSet))
+	for f := range fileSet {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return keys[best], files
+}
+
+// hasPartialIndexOn reports whether an existing partial index already filters
+// on field.
+func hasPartialIndexOn(indexes []mongoinspect.IndexInfo, field string) bool {
+	for _, idx := range indexes {
+		if idx.PartialFilter != "" && strings.Contains(idx.PartialFilter, strconv.Quote(field)) {
+			return true
+		}
+	}
+	return false
+}
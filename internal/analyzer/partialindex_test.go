@@ -0,0 +1,87 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+func partialIndexScan(refs ...scanner.FieldRef) *scanner.ScanResult {
+	return &scanner.ScanResult{Collections: []string{"orders"}, FieldRefs: refs}
+}
+
+func TestSuggestPartialIndexes(t *testing.T) {
+	scan := partialIndexScan(
+		scanner.FieldRef{Collection: "orders", Field: "status", File: "a.go", Line: 10, Usage: scanner.FieldUsageEquality, QueryContext: "find", Constant: `"active"`},
+		scanner.FieldRef{Collection: "orders", Field: "customer_id", File: "a.go", Line: 10, Usage: scanner.FieldUsageEquality, QueryContext: "find"},
+		scanner.FieldRef{Collection: "orders", Field: "status", File: "b.go", Line: 5, Usage: scanner.FieldUsageEquality, QueryContext: "find", Constant: `"active"`},
+		scanner.FieldRef{Collection: "orders", Field: "customer_id", File: "b.go", Line: 5, Usage: scanner.FieldUsageEquality, QueryContext: "find"},
+		scanner.FieldRef{Collection: "orders", Field: "created_at", File: "b.go", Line: 5, Usage: scanner.FieldUsageSort, Direction: -1, QueryContext: "find"},
+		scanner.FieldRef{Collection: "orders", Field: "status", File: "c.go", Line: 7, Usage: scanner.FieldUsageEquality, QueryContext: "find", Constant: `"active"`},
+		scanner.FieldRef{Collection: "orders", Field: "customer_id", File: "c.go", Line: 7, Usage: scanner.FieldUsageEquality, QueryContext: "find"},
+	)
+	collections := []mongoinspect.CollectionInfo{{
+		Database: "app",
+		Name:     "orders",
+		DocCount: 50_000,
+		Indexes: []mongoinspect.IndexInfo{
+			{Name: "_id_", Key: kf("_id")},
+			{Name: "customer_id_1", Key: kf("customer_id")},
+		},
+	}}
+
+	findings := suggestPartialIndexes(scan, collections)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d: %v", len(findings), findings)
+	}
+	f := findings[0]
+	if f.Type != FindingPartialIndexSuggest || f.Severity != SeverityInfo {
+		t.Errorf("got %s/%s, want %s/info", f.Type, f.Severity, FindingPartialIndexSuggest)
+	}
+	for _, want := range []string{
+		"partial index {customer_id:1}",
+		`partialFilterExpression {status: "active"}`,
+		"all 3 queries",
+		"a.go, b.go, c.go",
+		`could replace full index "customer_id_1"`,
+	} {
+		if !strings.Contains(f.Message, want) {
+			t.Errorf("message missing %q: %s", want, f.Message)
+		}
+	}
+}
+
+func TestSuggestPartialIndexes_Skips(t *testing.T) {
+	base := func(constant2 string) []scanner.FieldRef {
+		return []scanner.FieldRef{
+			{Collection: "orders", Field: "deleted", File: "a.go", Line: 1, Usage: scanner.FieldUsageEquality, Constant: "false"},
+			{Collection: "orders", Field: "email", File: "a.go", Line: 1, Usage: scanner.FieldUsageEquality},
+			{Collection: "orders", Field: "deleted", File: "b.go", Line: 2, Usage: scanner.FieldUsageEquality, Constant: constant2},
+			{Collection: "orders", Field: "email", File: "b.go", Line: 2, Usage: scanner.FieldUsageEquality},
+		}
+	}
+	coll := mongoinspect.CollectionInfo{Database: "app", Name: "orders", DocCount: 50_000}
+
+	tests := []struct {
+		name  string
+		refs  []scanner.FieldRef
+		colls []mongoinspect.CollectionInfo
+	}{
+		{"different constants", base("true"), []mongoinspect.CollectionInfo{coll}},
+		{"variable predicate", base(""), []mongoinspect.CollectionInfo{coll}},
+		{"small collection", base("false"), []mongoinspect.CollectionInfo{{Database: "app", Name: "orders", DocCount: 10}}},
+		{"existing partial index", base("false"), []mongoinspect.CollectionInfo{{
+			Database: "app", Name: "orders", DocCount: 50_000,
+			Indexes: []mongoinspect.IndexInfo{{Name: "email_live", Key: kf("email"), PartialFilter: `{"deleted":false}`}},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if findings := suggestPartialIndexes(partialIndexScan(tt.refs...), tt.colls); len(findings) != 0 {
+				t.Errorf("expected no findings, got %v", findings)
+			}
+		})
+	}
+}
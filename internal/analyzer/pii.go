@@ -0,0 +1,158 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+const (
+	piiMinMatchRate  = 0.1 // POSSIBLE_PII_FIELD when a pattern matches this share of a field's values
+	piiHighMatchRate = 0.5 // medium severity from here
+)
+
+// DefaultPIIPatterns are the built-in patterns for sampled PII detection,
+// by name. They look for values shaped like personal data, not for proof:
+// a card-like number may be an order ID. Phone numbers need a leading + or
+// separators between digit groups, so plain numeric IDs do not match.
+var DefaultPIIPatterns = map[string]string{
+	"email":       `(?i)\b[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}\b`,
+	"phone":       `^(\+[0-9]{1,3}[ .-]?\(?[0-9]{1,4}\)?([ .-]?[0-9]{2,4}){1,4}|(\([0-9]{2,4}\) ?|[0-9]{2,4}[ .-])[0-9]{2,4}([ .-][0-9]{2,4}){0,3})$`,
+	"card_number": `\b[0-9]{4}([ -]?[0-9]{4}){2}[ -]?[0-9]{1,7}\b`,
+	"us_ssn":      `\b[0-9]{3}-[0-9]{2}-[0-9]{4}\b`,
+	"uk_nino":     `(?i)\b[a-ceghj-pr-tw-z]{2} ?[0-9]{2} ?[0-9]{2} ?[0-9]{2} ?[a-d]\b`,
+	"iban":        `\b[A-Z]{2}[0-9]{2} ?([A-Z0-9]{4} ?){2,7}[A-Z0-9]{1,4}\b`,
+}
+
+// piiChecks are the checks built-in patterns apply to their matches beyond
+// the regular expression. A custom pattern of the same name replaces both.
+var piiChecks = map[string]func(string) bool{
+	"phone":       isPhoneNumber,
+	"card_number": luhnValid,
+}
+
+// dateLikeRe matches dates such as 2023-01-15 or 15.01.2023, which have
+// the digit groups of a phone number.
+var dateLikeRe = regexp.MustCompile(`^([0-9]{4}[-.][0-9]{1,2}[-.][0-9]{1,2}|[0-9]{1,2}[-.][0-9]{1,2}[-.][0-9]{4})$`)
+
+// isPhoneNumber reports whether s, a phone pattern match, has 7 to 15
+// digits and is not a date.
+func isPhoneNumber(s string) bool {
+	n := len(digitsOf(s))
+	return n >= 7 && n <= 15 && !dateLikeRe.MatchString(s)
+}
+
+// luhnValid reports whether the digits of s pass the Luhn checksum that
+// payment card numbers carry.
+func luhnValid(s string) bool {
+	digits := digitsOf(s)
+	if len(digits) < 12 {
+		return false
+	}
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if (len(digits)-i)%2 == 0 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+func digitsOf(s string) []byte {
+	digits := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			digits = append(digits, s[i])
+		}
+	}
+	return digits
+}
+
+// CompilePIIPatterns returns the built-in patterns merged with custom ones,
+// sorted by name. A custom pattern replaces the built-in of the same name;
+// an empty one removes it.
+func CompilePIIPatterns(custom map[string]string) ([]mongoinspect.PIIPattern, error) {
+	merged := make(map[string]string, len(DefaultPIIPatterns)+len(custom))
+	for name, expr := range DefaultPIIPatterns {
+		merged[name] = expr
+	}
+	for name, expr := range custom {
+		merged[name] = expr
+	}
+
+	var patterns []mongoinspect.PIIPattern
+	for name, expr := range merged {
+		if expr == "" {
+			continue
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %s: %w", name, err)
+		}
+		p := mongoinspect.PIIPattern{Name: name, Regexp: re}
+		if _, replaced := custom[name]; !replaced {
+			p.Valid = piiChecks[name]
+		}
+		patterns = append(patterns, p)
+	}
+	sort.Slice(patterns, func(i, j int) bool { return patterns[i].Name < patterns[j].Name })
+	return patterns, nil
+}
+
+// DetectPII reports sampled fields whose string values match PII patterns
+// in at least piiMinMatchRate of cases, one finding per field listing each
+// such pattern with its match rate. Only counts are reported, never values.
+func DetectPII(samples []mongoinspect.FieldSampleResult) []Finding {
+	var findings []Finding
+	for _, s := range samples {
+		for _, f := range s.PII {
+			if f.Strings == 0 {
+				continue
+			}
+			type hit struct {
+				name string
+				rate float64
+			}
+			var hits []hit
+			for name, n := range f.Matches {
+				if rate := float64(n) / float64(f.Strings); rate >= piiMinMatchRate {
+					hits = append(hits, hit{name, rate})
+				}
+			}
+			if len(hits) == 0 {
+				continue
+			}
+			sort.Slice(hits, func(i, j int) bool {
+				if hits[i].rate != hits[j].rate {
+					return hits[i].rate > hits[j].rate
+				}
+				return hits[i].name < hits[j].name
+			})
+			parts := make([]string, len(hits))
+			for i, h := range hits {
+				parts[i] = fmt.Sprintf("%s %.0f%%", h.name, h.rate*100)
+			}
+			sev := SeverityLow
+			if hits[0].rate >= piiHighMatchRate {
+				sev = SeverityMedium
+			}
+			findings = append(findings, Finding{
+				Type:       FindingPossiblePII,
+				Severity:   sev,
+				Database:   s.Database,
+				Collection: s.Collection,
+				Message: fmt.Sprintf("field %q looks like personal data: %s of %d sampled values — review masking, encryption and retention",
+					f.Path, strings.Join(parts, ", "), f.Strings),
+			})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,101 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+func TestCompilePIIPatterns(t *testing.T) {
+	patterns, err := CompilePIIPatterns(map[string]string{"phone": "", "employee_id": `^E[0-9]{6}$`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]mongoinspect.PIIPattern)
+	for _, p := range patterns {
+		byName[p.Name] = p
+	}
+	if _, ok := byName["phone"]; ok {
+		t.Error("phone not disabled")
+	}
+	if p, ok := byName["employee_id"]; !ok || !p.Regexp.MatchString("E123456") {
+		t.Error("custom pattern missing")
+	}
+
+	for name, value := range map[string]string{
+		"email":       "Alice.Smith+news@example.co.uk",
+		"card_number": "4111 1111 1111 1111",
+		"us_ssn":      "078-05-1120",
+		"uk_nino":     "AB 12 34 56 C",
+		"iban":        "DE89370400440532013000",
+	} {
+		if !byName[name].Regexp.MatchString(value) {
+			t.Errorf("%s does not match %q", name, value)
+		}
+	}
+	if byName["email"].Regexp.MatchString("not an address") {
+		t.Error("email matches plain text")
+	}
+
+	defaults, err := CompilePIIPatterns(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range defaults {
+		byName[p.Name] = p
+	}
+	for value, want := range map[string]bool{
+		"+49 151 12345678": true,
+		"+14155552671":     true,
+		"(555) 123-4567":   true,
+		"555-123-4567":     true,
+		"030 1234 5678":    true,
+		"2023-01-15":       false,
+		"15.01.2023":       false,
+		"20240101":         false,
+		"123456":           false,
+		"12-34":            false,
+	} {
+		if got := byName["phone"].Match(value); got != want {
+			t.Errorf("phone.Match(%q) = %v, want %v", value, got, want)
+		}
+	}
+	for value, want := range map[string]bool{
+		"4111 1111 1111 1111": true,
+		"5500-0000-0000-0004": true,
+		"4111 1111 1111 1112": false,
+		"1234567890123456":    false,
+	} {
+		if got := byName["card_number"].Match(value); got != want {
+			t.Errorf("card_number.Match(%q) = %v, want %v", value, got, want)
+		}
+	}
+
+	if _, err := CompilePIIPatterns(map[string]string{"bad": "("}); err == nil || !strings.Contains(err.Error(), "bad") {
+		t.Errorf("err = %v, want invalid pattern error", err)
+	}
+}
+
+func TestDetectPII(t *testing.T) {
+	samples := []mongoinspect.FieldSampleResult{{
+		Database: "app", Collection: "users",
+		PII: []mongoinspect.FieldPIIMatches{
+			{Path: "email", Strings: 100, Matches: map[string]int64{"email": 98}},
+			{Path: "notes", Strings: 100, Matches: map[string]int64{"email": 12, "phone": 3}},
+			{Path: "ref", Strings: 100, Matches: map[string]int64{"card_number": 2}},
+		},
+	}}
+
+	findings := DetectPII(samples)
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings, want 2: %+v", len(findings), findings)
+	}
+	if f := findings[0]; f.Type != FindingPossiblePII || f.Severity != SeverityMedium ||
+		!strings.Contains(f.Message, `"email"`) || !strings.Contains(f.Message, "email 98% of 100") {
+		t.Errorf("email finding = %+v", f)
+	}
+	if f := findings[1]; f.Severity != SeverityLow || !strings.Contains(f.Message, "email 12%") || strings.Contains(f.Message, "phone") {
+		t.Errorf("notes finding = %+v", f)
+	}
+}
@@ -0,0 +1,171 @@
+package analyzer
+
+import (
+	"sync"
+	"time"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+// Detector is one analysis in a Pipeline. Collection is called once per
+// inspected collection, in inspection order, and returns the findings that
+// collection alone produces. Finish is called once after the last
+// collection and returns findings that need to see every collection, such
+// as code references that match none of them.
+type Detector interface {
+	Name() string
+	Collection(c *mongoinspect.CollectionInfo) []Finding
+	Finish() []Finding
+}
+
+// DetectorFunc adapts a per-collection check to a Detector.
+func DetectorFunc(name string, fn func(c *mongoinspect.CollectionInfo) []Finding) Detector {
+	return &collectionDetector{name: name, fn: fn}
+}
+
+type collectionDetector struct {
+	name string
+	fn   func(c *mongoinspect.CollectionInfo) []Finding
+}
+
+func (d *collectionDetector) Name() string { return d.name }
+
+func (d *collectionDetector) Collection(c *mongoinspect.CollectionInfo) []Finding { return d.fn(c) }
+
+func (d *collectionDetector) Finish() []Finding { return nil }
+
+// setDetector adapts a check over the whole collection set: it remembers
+// each collection and runs fn once in Finish.
+type setDetector struct {
+	name        string
+	fn          func(collections []mongoinspect.CollectionInfo) []Finding
+	collections []mongoinspect.CollectionInfo
+}
+
+func collectionSetDetector(name string, fn func(collections []mongoinspect.CollectionInfo) []Finding) Detector {
+	return &setDetector{name: name, fn: fn}
+}
+
+func (d *setDetector) Name() string { return d.name }
+
+func (d *setDetector) Collection(c *mongoinspect.CollectionInfo) []Finding {
+	d.collections = append(d.collections, *c)
+	return nil
+}
+
+func (d *setDetector) Finish() []Finding { return d.fn(d.collections) }
+
+// Pipeline feeds collections through a list of detectors and records how
+// long each one took.
+type Pipeline struct {
+	detectors []Detector
+	elapsed   []time.Duration
+}
+
+// DetectorTiming is the total time a detector spent across a run.
+type DetectorTiming struct {
+	Name     string
+	Duration time.Duration
+}
+
+// NewPipeline returns a pipeline running detectors in the given order.
+func NewPipeline(detectors ...Detector) *Pipeline {
+	return &Pipeline{detectors: detectors, elapsed: make([]time.Duration, len(detectors))}
+}
+
+// Run passes each collection to every detector, then finishes them, calling
+// emit for each finding as soon as it is produced. Time spent in emit is
+// not charged to the detector.
+func (p *Pipeline) Run(collections []mongoinspect.CollectionInfo, emit func(Finding)) {
+	for i := range collections {
+		for j, d := range p.detectors {
+			start := time.Now()
+			findings := d.Collection(&collections[i])
+			p.elapsed[j] += time.Since(start)
+			for _, f := range findings {
+				emit(f)
+			}
+		}
+	}
+	for j, d := range p.detectors {
+		start := time.Now()
+		findings := d.Finish()
+		p.elapsed[j] += time.Since(start)
+		for _, f := range findings {
+			emit(f)
+		}
+	}
+}
+
+// Timings returns the time each detector has spent so far, in pipeline
+// order.
+func (p *Pipeline) Timings() []DetectorTiming {
+	timings := make([]DetectorTiming, len(p.detectors))
+	for i, d := range p.detectors {
+		timings[i] = DetectorTiming{Name: d.Name(), Duration: p.elapsed[i]}
+	}
+	return timings
+}
+
+// Collect runs the pipeline and returns all findings.
+func (p *Pipeline) Collect(collections []mongoinspect.CollectionInfo) []Finding {
+	var findings []Finding
+	p.Run(collections, func(f Finding) { findings = append(findings, f) })
+	return findings
+}
+
+var (
+	registryMu sync.Mutex
+	registered []func() Detector
+)
+
+// RegisterDetector adds a detector to every audit after the built-in ones.
+// newDetector is called once per audit, so detectors may keep state
+// between Collection and Finish.
+func RegisterDetector(newDetector func() Detector) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registered = append(registered, newDetector)
+}
+
+// AuditDetectors returns fresh instances of the cluster-only detectors:
+// the built-in ones followed by any registered with RegisterDetector.
+func AuditDetectors() []Detector {
+	detectors := []Detector{
+		DetectorFunc("unused-collection", detectUnusedCollection),
+		DetectorFunc("unused-index", detectUnusedIndexes),
+		DetectorFunc("hidden-index", detectForgottenHiddenIndexes),
+		DetectorFunc("missing-index", detectMissingIndexes),
+		DetectorFunc("duplicate-index", detectDuplicateIndexes),
+		DetectorFunc("oversized-collection", detectOversizedCollection),
+		DetectorFunc("missing-ttl", detectMissingTTL),
+		DetectorFunc("ttl-misconfigured", detectTTLMisconfigured),
+		DetectorFunc("index-bloat", detectIndexBloat),
+		DetectorFunc("write-heavy", detectWriteHeavyOverIndexed),
+		DetectorFunc("single-field-redundant", detectSingleFieldRedundant),
+		DetectorFunc("large-index", detectLargeIndex),
+		DetectorFunc("large-document", detectLargeAvgDocument),
+		DetectorFunc("wildcard-index", detectWildcardIndexBloat),
+		DetectorFunc("text-index", detectTextIndexCost),
+		DetectorFunc("high-latency", detectHighLatency),
+		DetectorFunc("fragmentation", detectFragmentation),
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	for _, newDetector := range registered {
+		detectors = append(detectors, newDetector())
+	}
+	return detectors
+}
+
+// QuickAuditDetectors returns the audit detectors that need nothing but
+// index definitions, for collections inspected without stats. Registered
+// detectors are left out, since they may rely on stats.
+func QuickAuditDetectors() []Detector {
+	return []Detector{
+		DetectorFunc("duplicate-index", detectDuplicateIndexes),
+		DetectorFunc("missing-ttl", detectMissingTTL),
+		DetectorFunc("ttl-misconfigured", detectTTLMisconfigured),
+		DetectorFunc("single-field-redundant", detectSingleFieldRedundant),
+	}
+}
@@ -0,0 +1,105 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+// countingDetector reports each collection it sees and a total in Finish.
+type countingDetector struct{ seen int }
+
+func (d *countingDetector) Name() string { return "counting" }
+
+func (d *countingDetector) Collection(c *mongoinspect.CollectionInfo) []Finding {
+	d.seen++
+	return []Finding{{Type: FindingOK, Severity: SeverityInfo, Collection: c.Name, Message: "seen"}}
+}
+
+func (d *countingDetector) Finish() []Finding {
+	if d.seen == 0 {
+		return nil
+	}
+	return []Finding{{Type: FindingOK, Severity: SeverityInfo, Message: "done"}}
+}
+
+func TestPipelineOrder(t *testing.T) {
+	collections := []mongoinspect.CollectionInfo{{Name: "a"}, {Name: "b"}}
+	marker := DetectorFunc("marker", func(c *mongoinspect.CollectionInfo) []Finding {
+		return []Finding{{Type: FindingOK, Collection: c.Name, Message: "marker"}}
+	})
+	findings := NewPipeline(&countingDetector{}, marker).Collect(collections)
+
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Collection+":"+f.Message)
+	}
+	want := []string{"a:seen", "a:marker", "b:seen", "b:marker", ":done"}
+	if len(got) != len(want) {
+		t.Fatalf("findings = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("findings = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestRegisterDetector(t *testing.T) {
+	builtin := len(AuditDetectors())
+	t.Cleanup(func() { registered = nil })
+	RegisterDetector(func() Detector { return &countingDetector{} })
+
+	detectors := AuditDetectors()
+	if len(detectors) != builtin+1 || detectors[builtin].Name() != "counting" {
+		t.Fatalf("registered detector not appended: %d detectors", len(detectors))
+	}
+	if detectors[builtin] == AuditDetectors()[builtin] {
+		t.Error("each audit should get a fresh detector instance")
+	}
+
+	findings := Audit([]mongoinspect.CollectionInfo{{Name: "users", DocCount: 1}})
+	if n := len(findings); n == 0 || findings[n-1].Message != "done" {
+		t.Fatalf("registered detector did not run: %+v", findings)
+	}
+}
+
+func TestQuickAuditDetectors(t *testing.T) {
+	// Quick inspection leaves counts and index stats unset; only
+	// definition-based findings may come out of that.
+	coll := mongoinspect.CollectionInfo{
+		Name:     "orders",
+		Database: "db",
+		Indexes: []mongoinspect.IndexInfo{
+			{Name: "_id_", Key: kf("_id")},
+			{Name: "status_1", Key: kf("status")},
+			{Name: "status_1_date_1", Key: kf("status", "date")},
+		},
+	}
+	findings := NewPipeline(QuickAuditDetectors()...).Collect([]mongoinspect.CollectionInfo{coll})
+	var got []FindingType
+	for _, f := range findings {
+		got = append(got, f.Type)
+	}
+	if len(got) != 2 || got[0] != FindingDuplicateIndex || got[1] != FindingSingleFieldRedundant {
+		t.Fatalf("findings = %v, want DUPLICATE_INDEX and SINGLE_FIELD_REDUNDANT only", got)
+	}
+}
+
+func TestPipelineTimings(t *testing.T) {
+	slow := DetectorFunc("slow", func(*mongoinspect.CollectionInfo) []Finding {
+		time.Sleep(time.Millisecond)
+		return nil
+	})
+	p := NewPipeline(&countingDetector{}, slow)
+	p.Collect([]mongoinspect.CollectionInfo{{Name: "a"}, {Name: "b"}})
+
+	timings := p.Timings()
+	if len(timings) != 2 || timings[0].Name != "counting" || timings[1].Name != "slow" {
+		t.Fatalf("timings = %+v", timings)
+	}
+	if timings[1].Duration < 2*time.Millisecond {
+		t.Errorf("slow detector took %s, want at least 2ms", timings[1].Duration)
+	}
+}
@@ -10,7 +10,15 @@ import (
 	"github.com/ppiankov/mongospectre/internal/scanner"
 )
 
-const frequentSlowQueryThreshold = 50
+const (
+	frequentSlowQueryThreshold = 50
+
+	// Query targeting: documents examined per document returned. Atlas alerts
+	// at 1000 by default; 100 already means an index is doing little work.
+	poorTargetingRatio      = 100
+	severeTargetingRatio    = 1000
+	minTargetingDocsScanned = 1000 // ignore shapes that examined few documents overall
+)
 
 type sourceLocation struct {
 	file   string
@@ -40,6 +48,12 @@ type profileShapeStats struct {
 	projectionFields []string
 	count            int
 	locations        map[string]sourceLocation
+	docsExamined     int64
+	keysExamined     int64
+	nReturned        int64
+	sortKeys         []mongoinspect.KeyField
+	inMemorySorts    int
+	diskSorts        int
 }
 
 // CorrelateProfiler matches profiler query shapes to scanned code locations.
@@ -88,6 +102,18 @@ func CorrelateProfiler(scan *scanner.ScanResult, entries []mongoinspect.ProfileE
 			shapeStats[shapeKey] = shape
 		}
 		shape.count++
+		shape.docsExamined += entry.DocsExamined
+		shape.keysExamined += entry.KeysExamined
+		shape.nReturned += entry.NReturned
+		if len(shape.sortKeys) == 0 {
+			shape.sortKeys = entry.SortKeys
+		}
+		if entry.HasSortStage {
+			shape.inMemorySorts++
+		}
+		if entry.UsedDisk {
+			shape.diskSorts++
+		}
 
 		for _, loc := range matchedLocations {
 			shape.locations[loc.key()] = loc
@@ -137,6 +163,8 @@ func CorrelateProfiler(scan *scanner.ScanResult, entries []mongoinspect.ProfileE
 				avgMillis,
 				stat.count,
 			),
+			File: stat.file,
+			Line: stat.line,
 		})
 
 		if stat.collscanCount > 0 {
@@ -151,6 +179,8 @@ func CorrelateProfiler(scan *scanner.ScanResult, entries []mongoinspect.ProfileE
 					stat.line,
 					stat.collscanCount,
 				),
+				File: stat.file,
+				Line: stat.line,
 			})
 		}
 	}
@@ -163,6 +193,12 @@ func CorrelateProfiler(scan *scanner.ScanResult, entries []mongoinspect.ProfileE
 
 	for _, key := range shapeKeys {
 		shape := shapeStats[key]
+		if f, ok := queryTargetingFinding(shape); ok {
+			findings = append(findings, f)
+		}
+		if f, ok := blockingSortFinding(shape); ok {
+			findings = append(findings, f)
+		}
 		if shape.count < frequentSlowQueryThreshold {
 			continue
 		}
@@ -183,6 +219,88 @@ func CorrelateProfiler(scan *scanner.ScanResult, entries []mongoinspect.ProfileE
 	return findings
 }
 
+// queryTargetingFinding reports a shape whose profiled executions examined
+// far more documents than they returned.
+func queryTargetingFinding(shape *profileShapeStats) (Finding, bool) {
+	if shape.docsExamined < minTargetingDocsScanned {
+		return Finding{}, false
+	}
+	ratio := float64(shape.docsExamined) / float64(max(shape.nReturned, 1))
+	if ratio < poorTargetingRatio {
+		return Finding{}, false
+	}
+	severity := SeverityMedium
+	if ratio >= severeTargetingRatio {
+		severity = SeverityHigh
+	}
+	return Finding{
+		Type:       FindingPoorQueryTargeting,
+		Severity:   severity,
+		Database:   shape.database,
+		Collection: shape.collection,
+		Message: fmt.Sprintf(
+			"query shape (%s) examined %d docs and %d keys to return %d (%.0f:1 docs examined per returned) across %d samples; source: %s",
+			formatShapeSummary(shape),
+			shape.docsExamined,
+			shape.keysExamined,
+			shape.nReturned,
+			ratio,
+			shape.count,
+			formatShapeSources(shape.locations),
+		),
+	}, true
+}
+
+// blockingSortFinding reports a shape whose profiled executions sorted in
+// memory, suggesting an index with the equality filter fields followed by the
+// sort keys (equality-sort-range order) so the sort can use index order.
+func blockingSortFinding(shape *profileShapeStats) (Finding, bool) {
+	if shape.inMemorySorts == 0 && shape.diskSorts == 0 {
+		return Finding{}, false
+	}
+	f := Finding{
+		Type:       FindingInMemorySort,
+		Severity:   SeverityMedium,
+		Database:   shape.database,
+		Collection: shape.collection,
+	}
+	what := fmt.Sprintf("sorted in memory in %d of %d samples", max(shape.inMemorySorts, shape.diskSorts), shape.count)
+	if shape.diskSorts > 0 {
+		f.Type = FindingSortSpilledToDisk
+		f.Severity = SeverityHigh
+		what = fmt.Sprintf("spilled its sort to disk in %d of %d samples", shape.diskSorts, shape.count)
+	}
+	fix := "add an index whose key order matches the sort"
+	if spec := sortIndexSpec(shape); spec != "" {
+		fix = "an index on " + spec + " would remove the blocking sort"
+	}
+	f.Message = fmt.Sprintf("query shape (%s) %s; %s; source: %s",
+		formatShapeSummary(shape), what, fix, formatShapeSources(shape.locations))
+	return f, true
+}
+
+// sortIndexSpec formats filter fields (ascending) followed by the sort keys
+// with their directions, e.g. {status: 1, created_at: -1}.
+func sortIndexSpec(shape *profileShapeStats) string {
+	if len(shape.sortKeys) == 0 {
+		return ""
+	}
+	sortFields := make(map[string]bool, len(shape.sortKeys))
+	for _, k := range shape.sortKeys {
+		sortFields[normalizeProfileField(k.Field)] = true
+	}
+	var parts []string
+	for _, field := range shape.filterFields {
+		if !sortFields[field] {
+			parts = append(parts, field+": 1")
+		}
+	}
+	for _, k := range shape.sortKeys {
+		parts = append(parts, fmt.Sprintf("%s: %d", k.Field, k.Direction))
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
 func buildSourceLocations(scan *scanner.ScanResult) map[string][]sourceLocation {
 	byCollection := make(map[string]map[string]*sourceLocation)
 
@@ -151,3 +151,94 @@ func TestCorrelateProfiler_FallbackToCollectionReference(t *testing.T) {
 		t.Fatalf("slow message missing fallback collection location: %q", slow.Message)
 	}
 }
+
+func TestCorrelateProfiler_PoorQueryTargeting(t *testing.T) {
+	scan := &scanner.ScanResult{
+		Refs: []scanner.CollectionRef{
+			{Collection: "orders", File: "app/handlers/orders.go", Line: 42},
+		},
+		FieldRefs: []scanner.FieldRef{
+			{Collection: "orders", Field: "status", File: "app/handlers/orders.go", Line: 42},
+			{Collection: "orders", Field: "email", File: "app/handlers/orders.go", Line: 80},
+		},
+	}
+	entries := []mongoinspect.ProfileEntry{
+		// 40000 examined for 20 returned: 2000:1.
+		{Database: "app", Collection: "orders", FilterFields: []string{"status"}, DocsExamined: 20000, NReturned: 10},
+		{Database: "app", Collection: "orders", FilterFields: []string{"status"}, DocsExamined: 20000, NReturned: 10},
+		// Well targeted.
+		{Database: "app", Collection: "orders", FilterFields: []string{"email"}, DocsExamined: 5000, KeysExamined: 5000, NReturned: 5000},
+	}
+
+	findings := CorrelateProfiler(scan, entries)
+
+	var targeting []Finding
+	for _, f := range findings {
+		if f.Type == FindingPoorQueryTargeting {
+			targeting = append(targeting, f)
+		}
+	}
+	if len(targeting) != 1 {
+		t.Fatalf("POOR_QUERY_TARGETING findings = %d, want 1: %v", len(targeting), findings)
+	}
+	f := targeting[0]
+	if f.Severity != SeverityHigh {
+		t.Errorf("severity = %s, want high", f.Severity)
+	}
+	for _, want := range []string{"filter=status", "2000:1", "app/handlers/orders.go:42"} {
+		if !strings.Contains(f.Message, want) {
+			t.Errorf("message missing %q: %q", want, f.Message)
+		}
+	}
+}
+
+func TestCorrelateProfiler_TargetingIgnoresSmallScans(t *testing.T) {
+	scan := &scanner.ScanResult{
+		Refs: []scanner.CollectionRef{{Collection: "orders", File: "app/orders.go", Line: 1}},
+	}
+	entries := []mongoinspect.ProfileEntry{
+		{Database: "app", Collection: "orders", DocsExamined: 500, NReturned: 0},
+	}
+	for _, f := range CorrelateProfiler(scan, entries) {
+		if f.Type == FindingPoorQueryTargeting {
+			t.Fatalf("unexpected targeting finding for %d docs examined: %q", entries[0].DocsExamined, f.Message)
+		}
+	}
+}
+
+func TestCorrelateProfiler_BlockingSort(t *testing.T) {
+	scan := &scanner.ScanResult{
+		Refs: []scanner.CollectionRef{{Collection: "orders", File: "app/orders.go", Line: 10}},
+		FieldRefs: []scanner.FieldRef{
+			{Collection: "orders", Field: "status", File: "app/orders.go", Line: 10},
+			{Collection: "orders", Field: "customer", File: "app/orders.go", Line: 10},
+		},
+	}
+	sortKeys := []mongoinspect.KeyField{{Field: "created_at", Direction: -1}}
+	entries := []mongoinspect.ProfileEntry{
+		{Database: "app", Collection: "orders", FilterFields: []string{"status"}, SortFields: []string{"created_at"}, SortKeys: sortKeys, HasSortStage: true},
+		{Database: "app", Collection: "orders", FilterFields: []string{"status"}, SortFields: []string{"created_at"}, SortKeys: sortKeys, HasSortStage: true},
+		{Database: "app", Collection: "orders", FilterFields: []string{"customer"}, SortFields: []string{"total"}, HasSortStage: true, UsedDisk: true},
+	}
+
+	findings := CorrelateProfiler(scan, entries)
+
+	byType := make(map[FindingType]Finding)
+	for _, f := range findings {
+		byType[f.Type] = f
+	}
+	mem, ok := byType[FindingInMemorySort]
+	if !ok {
+		t.Fatalf("expected IN_MEMORY_SORT finding, got %v", findings)
+	}
+	if mem.Severity != SeverityMedium || !strings.Contains(mem.Message, "{status: 1, created_at: -1}") || !strings.Contains(mem.Message, "2 of 2 samples") {
+		t.Errorf("in-memory sort finding = %s %q", mem.Severity, mem.Message)
+	}
+	disk, ok := byType[FindingSortSpilledToDisk]
+	if !ok {
+		t.Fatalf("expected SORT_SPILLED_TO_DISK finding, got %v", findings)
+	}
+	if disk.Severity != SeverityHigh || !strings.Contains(disk.Message, "index whose key order matches the sort") {
+		t.Errorf("disk sort finding = %s %q", disk.Severity, disk.Message)
+	}
+}
@@ -0,0 +1,107 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+// readPrefModes maps the lower-case read preference modes the scanner
+// records to their spelling in the docs, for the modes that can read
+// from secondaries.
+var readPrefModes = map[string]string{
+	"secondary":          "secondary",
+	"secondarypreferred": "secondaryPreferred",
+	"nearest":            "nearest",
+}
+
+// majorityReadLevels are the read concern levels that only return
+// majority-committed data.
+var majorityReadLevels = map[string]bool{"majority": true, "linearizable": true, "snapshot": true}
+
+// DetectReadConsistencyRisks reviews read preferences set in code and in
+// the connection URI.
+//
+// CAUSAL_CONSISTENCY_RISK reports reads that may go to a secondary after
+// a write earlier in the same function, when neither the read nor the
+// function sets a majority read concern: the secondary may not have
+// replicated the write yet, so the read can miss it. Flows are found by a
+// per-file heuristic and keyed by their first line; top-level code between
+// functions forms flows of its own.
+//
+// READ_PREFERENCE_NO_SECONDARY reports readPreference "secondary" on a
+// replica set with a single data-bearing member, where every such read
+// fails server selection. rs is nil when the topology is unknown.
+func DetectReadConsistencyRisks(scan *scanner.ScanResult, uri string, rs *mongoinspect.ReplicaSetInfo) []Finding {
+	// Flows setting a majority read concern, by file and first line.
+	majorityFlows := make(map[string]bool)
+	for _, ref := range scan.ReadSettingRefs {
+		if majorityReadLevels[ref.Level] && ref.Function > 0 {
+			majorityFlows[fmt.Sprintf("%s:%d", ref.File, ref.Function)] = true
+		}
+	}
+
+	var findings []Finding
+	for _, ref := range scan.ReadSettingRefs {
+		mode, ok := readPrefModes[ref.Mode]
+		if !ok || ref.AfterWrite == 0 || majorityReadLevels[ref.Level] || majorityFlows[fmt.Sprintf("%s:%d", ref.File, ref.Function)] {
+			continue
+		}
+		findings = append(findings, Finding{
+			Type:       FindingCausalConsistencyRisk,
+			Severity:   SeverityMedium,
+			Collection: ref.Collection,
+			Message: fmt.Sprintf("read at %s:%d uses readPreference %q after the write at line %d without readConcern \"majority\" — a secondary may not have replicated the write yet; read from the primary, or use a causally consistent session with majority read and write concern",
+				ref.File, ref.Line, mode, ref.AfterWrite),
+			File: ref.File,
+			Line: ref.Line,
+		})
+	}
+
+	topo := newWriteTopology(rs)
+	if topo == nil || topo.dataBearing != 1 {
+		return findings
+	}
+	noSecondary := func(where string, ref scanner.ReadSettingRef) {
+		findings = append(findings, Finding{
+			Type:       FindingReadPrefNoSecondary,
+			Severity:   SeverityHigh,
+			Collection: ref.Collection,
+			Message: fmt.Sprintf("%s sets readPreference \"secondary\" but replica set %q has a single data-bearing member — with no secondary to read from, the reads fail server selection; use secondaryPreferred or add members",
+				where, topo.name),
+			File: ref.File,
+			Line: ref.Line,
+		})
+	}
+	if uriReadPreference(uri) == "secondary" {
+		noSecondary("connection URI", scanner.ReadSettingRef{})
+	}
+	for _, ref := range scan.ReadSettingRefs {
+		if ref.Mode == "secondary" {
+			noSecondary(fmt.Sprintf("read at %s:%d", ref.File, ref.Line), ref)
+		}
+	}
+	return findings
+}
+
+// URISetsReadPreference reports whether a connection URI sets a read
+// preference DetectReadConsistencyRisks reviews.
+func URISetsReadPreference(rawURI string) bool {
+	return uriReadPreference(rawURI) != ""
+}
+
+// uriReadPreference returns the lower-case readPreference of a connection
+// URI, or "" when it sets none.
+func uriReadPreference(rawURI string) string {
+	if rawURI == "" {
+		return ""
+	}
+	u, err := url.Parse(rawURI)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Query().Get("readPreference"))
+}
@@ -0,0 +1,50 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+func TestDetectReadConsistencyRisks(t *testing.T) {
+	scan := &scanner.ScanResult{ReadSettingRefs: []scanner.ReadSettingRef{
+		{Collection: "orders", Mode: "secondary", Function: 1, AfterWrite: 2, File: "orders.js", Line: 3},
+		// No write earlier in the function.
+		{Collection: "orders", Mode: "secondarypreferred", Function: 6, File: "orders.js", Line: 7},
+		// The function reads with a majority read concern.
+		{Collection: "carts", Mode: "nearest", Function: 10, AfterWrite: 12, File: "carts.py", Line: 14},
+		{Level: "majority", Function: 10, File: "carts.py", Line: 11},
+		{Collection: "users", Mode: "primary", Function: 20, AfterWrite: 21, File: "users.go", Line: 22},
+	}}
+
+	findings := DetectReadConsistencyRisks(scan, "", nil)
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1: %+v", len(findings), findings)
+	}
+	if f := findings[0]; f.Type != FindingCausalConsistencyRisk || f.Collection != "orders" || f.Line != 3 ||
+		!strings.Contains(f.Message, `readPreference "secondary" after the write at line 2`) {
+		t.Errorf("finding = %+v", f)
+	}
+
+	single := &mongoinspect.ReplicaSetInfo{Name: "rs0", Members: []mongoinspect.ReplicaSetMember{
+		{Name: "a:27017", StateStr: "PRIMARY", Health: 1, Votes: 1},
+	}}
+	findings = DetectReadConsistencyRisks(scan, "mongodb://a/app?replicaSet=rs0&readPreference=secondary", single)
+	var noSecondary []Finding
+	for _, f := range findings {
+		if f.Type == FindingReadPrefNoSecondary {
+			noSecondary = append(noSecondary, f)
+		}
+	}
+	if len(noSecondary) != 2 || !strings.HasPrefix(noSecondary[0].Message, "connection URI sets") ||
+		noSecondary[1].File != "orders.js" || noSecondary[1].Severity != SeverityHigh {
+		t.Errorf("no secondary findings = %+v", noSecondary)
+	}
+
+	single.Members = append(single.Members, mongoinspect.ReplicaSetMember{Name: "b:27017", StateStr: "SECONDARY", Health: 1, Votes: 1})
+	if findings := DetectReadConsistencyRisks(scan, "mongodb://a/app?readPreference=secondary", single); len(findings) != 1 {
+		t.Errorf("got %d findings with a secondary, want 1: %+v", len(findings), findings)
+	}
+}
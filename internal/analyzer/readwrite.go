@@ -0,0 +1,140 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+// codeAccess counts the places code reads and writes one collection.
+type codeAccess struct {
+	reads  int
+	writes int
+}
+
+// liveAccess is the read and write activity the server reports for one
+// collection.
+type liveAccess struct {
+	reads  bool
+	writes bool
+}
+
+// ClassifyReadWrite flags collections that code only writes or only reads.
+// A collection written in code but never read, with no reads in
+// $collStats latency, $indexStats or the profiler entries, is
+// WRITE_ONLY_COLLECTION: data accumulates with nothing consuming it. A
+// collection read in code but never written, holding documents and with no
+// writes seen on the server, is STALE_READ_MODEL: nothing keeps it up to
+// date. Views and collections missing from the database are skipped;
+// profile may be nil.
+func ClassifyReadWrite(scan *scanner.ScanResult, collections []mongoinspect.CollectionInfo, profile []mongoinspect.ProfileEntry) []Finding {
+	access := codeAccessByCollection(scan)
+	if len(access) == 0 {
+		return nil
+	}
+	var findings []Finding
+	for i := range collections {
+		c := &collections[i]
+		if c.Type == "view" || strings.HasPrefix(c.Name, "system.") {
+			continue
+		}
+		code, ok := access[strings.ToLower(c.Name)]
+		if !ok {
+			continue
+		}
+		live := liveAccessFor(c, profile)
+		switch {
+		case code.writes > 0 && code.reads == 0 && !live.reads:
+			findings = append(findings, Finding{
+				Type:       FindingWriteOnlyCollection,
+				Severity:   SeverityLow,
+				Database:   c.Database,
+				Collection: c.Name,
+				Message: fmt.Sprintf("code writes this collection in %d place(s) but never reads it, and the server reports no reads; confirm something consumes the data or stop writing it",
+					code.writes),
+			})
+		case code.reads > 0 && code.writes == 0 && c.DocCount > 0 && !live.writes:
+			findings = append(findings, Finding{
+				Type:       FindingStaleReadModel,
+				Severity:   SeverityLow,
+				Database:   c.Database,
+				Collection: c.Name,
+				Message: fmt.Sprintf("code reads this collection in %d place(s) but never writes it, and the server reports no writes; its %d documents may be stale",
+					code.reads, c.DocCount),
+			})
+		}
+	}
+	return findings
+}
+
+// codeAccessByCollection counts read and write sites per lowercased
+// collection name. Upserts and update or delete query shapes count as
+// writes.
+func codeAccessByCollection(scan *scanner.ScanResult) map[string]codeAccess {
+	if scan == nil {
+		return nil
+	}
+	access := make(map[string]codeAccess)
+	sites := make(map[string]bool)
+	count := func(coll, file string, line int, write bool) {
+		key := strings.ToLower(coll)
+		site := fmt.Sprintf("%s:%s:%d:%t", key, file, line, write)
+		if sites[site] {
+			return
+		}
+		sites[site] = true
+		a := access[key]
+		if write {
+			a.writes++
+		} else {
+			a.reads++
+		}
+		access[key] = a
+	}
+	for _, ref := range scan.ReadRefs {
+		count(ref.Collection, ref.File, ref.Line, false)
+	}
+	for _, ref := range scan.WriteRefs {
+		count(ref.Collection, ref.File, ref.Line, true)
+	}
+	for _, ref := range scan.UpsertRefs {
+		count(ref.Collection, ref.File, ref.Line, true)
+	}
+	for _, ref := range scan.FieldRefs {
+		ctx := strings.ToLower(ref.QueryContext)
+		if strings.HasPrefix(ctx, "update") || strings.HasPrefix(ctx, "delete") {
+			count(ref.Collection, ref.File, ref.Line, true)
+		}
+	}
+	return access
+}
+
+// liveAccessFor reports whether the server has seen reads or writes on c:
+// read or write latency ops, operations on any index other than _id, and
+// profiler entries for the collection.
+func liveAccessFor(c *mongoinspect.CollectionInfo, profile []mongoinspect.ProfileEntry) liveAccess {
+	var live liveAccess
+	if c.Latency != nil {
+		live.reads = c.Latency.Reads.Ops > 0
+		live.writes = c.Latency.Writes.Ops > 0
+	}
+	for _, idx := range c.Indexes {
+		if idx.Name != "_id_" && idx.Stats != nil && idx.Stats.Ops > 0 {
+			live.reads = true
+		}
+	}
+	for _, e := range profile {
+		if !strings.EqualFold(e.Collection, c.Name) || (c.Database != "" && e.Database != c.Database) {
+			continue
+		}
+		switch e.Op {
+		case "insert", "update", "remove":
+			live.writes = true
+		default:
+			live.reads = true
+		}
+	}
+	return live
+}
@@ -0,0 +1,89 @@
+package analyzer
+
+import (
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+func readWriteScan() *scanner.ScanResult {
+	return &scanner.ScanResult{
+		ReadRefs: []scanner.ReadRef{
+			{Collection: "users", File: "users.js", Line: 3},
+			{Collection: "rates", File: "billing.js", Line: 10},
+			{Collection: "rates", File: "billing.js", Line: 22},
+		},
+		WriteRefs: []scanner.WriteRef{
+			{Collection: "users", File: "users.js", Line: 8},
+			{Collection: "audit_log", File: "audit.js", Line: 4, Field: "action"},
+			{Collection: "audit_log", File: "audit.js", Line: 4, Field: "at"},
+		},
+	}
+}
+
+func findingsByType(findings []Finding) map[FindingType][]string {
+	out := make(map[FindingType][]string)
+	for _, f := range findings {
+		out[f.Type] = append(out[f.Type], f.Collection)
+	}
+	return out
+}
+
+func TestClassifyReadWrite(t *testing.T) {
+	collections := []mongoinspect.CollectionInfo{
+		{Name: "users", Database: "app", DocCount: 10},
+		{Name: "audit_log", Database: "app", DocCount: 5000},
+		{Name: "rates", Database: "app", DocCount: 40},
+	}
+	findings := ClassifyReadWrite(readWriteScan(), collections, nil)
+	got := findingsByType(findings)
+	if len(findings) != 2 || len(got[FindingWriteOnlyCollection]) != 1 || len(got[FindingStaleReadModel]) != 1 {
+		t.Fatalf("findings = %+v", findings)
+	}
+	if got[FindingWriteOnlyCollection][0] != "audit_log" || got[FindingStaleReadModel][0] != "rates" {
+		t.Errorf("findings = %+v", findings)
+	}
+	for _, f := range findings {
+		if f.Severity != SeverityLow || f.Database != "app" {
+			t.Errorf("finding = %+v", f)
+		}
+	}
+	want := "code writes this collection in 1 place(s) but never reads it, and the server reports no reads; confirm something consumes the data or stop writing it"
+	if f := findings[0]; f.Message != want {
+		t.Errorf("message = %q, want %q", f.Message, want)
+	}
+}
+
+func TestClassifyReadWriteLiveActivity(t *testing.T) {
+	collections := []mongoinspect.CollectionInfo{
+		{Name: "audit_log", Database: "app", DocCount: 5000, Indexes: []mongoinspect.IndexInfo{
+			{Name: "_id_", Stats: &mongoinspect.IndexStats{Ops: 900}},
+			{Name: "at_1", Stats: &mongoinspect.IndexStats{Ops: 3}},
+		}},
+		{Name: "rates", Database: "app", DocCount: 40},
+	}
+	profile := []mongoinspect.ProfileEntry{{Database: "app", Collection: "rates", Op: "update"}}
+	if findings := ClassifyReadWrite(readWriteScan(), collections, profile); len(findings) != 0 {
+		t.Errorf("expected live reads and writes to suppress findings, got %+v", findings)
+	}
+
+	collections[0].Indexes = nil
+	collections[0].Latency = &mongoinspect.LatencyStats{Reads: mongoinspect.OpLatency{Ops: 12}}
+	if findings := ClassifyReadWrite(readWriteScan(), collections[:1], nil); len(findings) != 0 {
+		t.Errorf("expected $collStats reads to suppress WRITE_ONLY_COLLECTION, got %+v", findings)
+	}
+}
+
+func TestClassifyReadWriteSkips(t *testing.T) {
+	collections := []mongoinspect.CollectionInfo{
+		{Name: "audit_log", Database: "app", Type: "view"},
+		{Name: "rates", Database: "app"}, // empty: nothing to go stale
+	}
+	if findings := ClassifyReadWrite(readWriteScan(), collections, nil); len(findings) != 0 {
+		t.Errorf("expected views and empty collections to be skipped, got %+v", findings)
+	}
+	if findings := ClassifyReadWrite(&scanner.ScanResult{}, collections, nil); findings != nil {
+		t.Errorf("expected no findings without code access, got %+v", findings)
+	}
+}
@@ -0,0 +1,154 @@
+package analyzer
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+// RetentionRule limits how long documents in collections matching Database
+// and Collection, glob patterns in path.Match syntax, may be kept. An empty
+// pattern matches anything.
+type RetentionRule struct {
+	Database   string
+	Collection string
+	MaxAge     time.Duration
+}
+
+// RetentionPolicy is the configured set of retention rules. The first rule
+// matching a collection applies.
+type RetentionPolicy []RetentionRule
+
+// NewRetentionPolicy validates rules and returns them as a RetentionPolicy.
+func NewRetentionPolicy(rules []RetentionRule) (RetentionPolicy, error) {
+	for i, r := range rules {
+		for _, p := range []string{r.Database, r.Collection} {
+			if _, err := path.Match(p, ""); err != nil {
+				return nil, fmt.Errorf("rule %d: invalid pattern %q: %w", i+1, p, err)
+			}
+		}
+		if r.MaxAge <= 0 {
+			return nil, fmt.Errorf("rule %d: max age must be positive", i+1)
+		}
+	}
+	return RetentionPolicy(rules), nil
+}
+
+// ParseRetentionAge parses a retention period: a whole number of days (30d),
+// weeks (52w) or years (1y, 365 days), or a Go duration such as 36h.
+func ParseRetentionAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	units := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour, 'y': 365 * 24 * time.Hour}
+	if n := len(s); n > 1 {
+		if unit, ok := units[s[n-1]]; ok {
+			v, err := strconv.Atoi(s[:n-1])
+			if err != nil {
+				return 0, fmt.Errorf("invalid retention period %q", s)
+			}
+			return time.Duration(v) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid retention period %q (use e.g. 30d, 52w, 1y or 36h)", s)
+	}
+	return d, nil
+}
+
+// maxAge returns the retention period of a collection, if a rule covers it.
+func (p RetentionPolicy) maxAge(database, collection string) (time.Duration, bool) {
+	for _, r := range p {
+		if globMatch(r.Database, database) && globMatch(r.Collection, collection) {
+			return r.MaxAge, true
+		}
+	}
+	return 0, false
+}
+
+// DetectUnenforcedRetention reports collections under a retention rule that
+// nothing visible enforces: no TTL index expiring documents within the
+// period, and no deletes in the oplog sample, which is how archival jobs
+// show up. samples and oplog may be nil; with samples, the finding gives the
+// age of the oldest sampled document, and is high severity when that is
+// already past the period.
+func DetectUnenforcedRetention(p RetentionPolicy, collections []mongoinspect.CollectionInfo, samples []mongoinspect.FieldSampleResult, oplog *mongoinspect.OplogProfile) []Finding {
+	if len(p) == 0 {
+		return nil
+	}
+	oldest := make(map[string]time.Time, len(samples))
+	for i := range samples {
+		if age := samples[i].Age(); age != nil {
+			oldest[samples[i].Database+"."+samples[i].Collection] = age.Oldest
+		}
+	}
+	deletes := make(map[string]int64)
+	if oplog != nil {
+		for _, ns := range oplog.Namespaces {
+			deletes[ns.Database+"."+ns.Collection] += ns.Deletes
+		}
+	}
+
+	var findings []Finding
+	for i := range collections {
+		c := &collections[i]
+		if c.Type == "view" {
+			continue
+		}
+		limit, ok := p.maxAge(c.Database, c.Name)
+		if !ok {
+			continue
+		}
+		ns := c.Database + "." + c.Name
+		if deletes[ns] > 0 {
+			continue
+		}
+
+		reason := "no TTL index expires its documents"
+		enforced := false
+		for j := range c.Indexes {
+			idx := &c.Indexes[j]
+			// Compound and partial TTL indexes do not expire every document;
+			// TTL_MISCONFIGURED reports them.
+			if idx.TTL == nil || len(idx.Key) != 1 || idx.PartialFilter != "" {
+				continue
+			}
+			ttl := time.Duration(*idx.TTL) * time.Second
+			// expireAfterSeconds 0 expires at a date the application sets.
+			if ttl <= limit {
+				enforced = true
+				break
+			}
+			reason = fmt.Sprintf("TTL index %q keeps documents for %s", idx.Name, formatElapsed(ttl))
+		}
+		if enforced {
+			continue
+		}
+		if oplog == nil {
+			reason += " (run with --oplog to detect archival jobs)"
+		} else {
+			reason += " and the oplog sample has no deletes from it"
+		}
+
+		sev := SeverityMedium
+		msg := fmt.Sprintf("retention policy allows %s but nothing enforces it: %s", formatElapsed(limit), reason)
+		if t, ok := oldest[ns]; ok {
+			age := now().Sub(t)
+			msg += fmt.Sprintf("; oldest sampled document is %s old", formatElapsed(age))
+			if age > limit {
+				sev = SeverityHigh
+			}
+		}
+		findings = append(findings, Finding{
+			Type:       FindingRetentionUnenforced,
+			Severity:   sev,
+			Database:   c.Database,
+			Collection: c.Name,
+			Message:    msg,
+		})
+	}
+	return findings
+}
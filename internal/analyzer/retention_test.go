@@ -0,0 +1,83 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+func TestParseRetentionAge(t *testing.T) {
+	day := 24 * time.Hour
+	for in, want := range map[string]time.Duration{
+		"30d": 30 * day, "52w": 364 * day, "1y": 365 * day, "36h": 36 * time.Hour,
+	} {
+		got, err := ParseRetentionAge(in)
+		if err != nil || got != want {
+			t.Errorf("%s = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "d", "xd", "30 days"} {
+		if _, err := ParseRetentionAge(in); err == nil {
+			t.Errorf("%q: expected an error", in)
+		}
+	}
+	if _, err := NewRetentionPolicy([]RetentionRule{{Collection: "sessions"}}); err == nil {
+		t.Error("expected an error for a zero max age")
+	}
+}
+
+func TestDetectUnenforcedRetention(t *testing.T) {
+	day := 24 * time.Hour
+	fixed := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return fixed }
+	t.Cleanup(func() { now = time.Now })
+	ttl := func(seconds int32) *int32 { return &seconds }
+	policy, err := NewRetentionPolicy([]RetentionRule{
+		{Collection: "sessions", MaxAge: 30 * day},
+		{Collection: "audit_*", MaxAge: 365 * day},
+		{Collection: "events", MaxAge: 90 * day},
+		{Collection: "carts", MaxAge: 7 * day},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	collections := []mongoinspect.CollectionInfo{
+		{Database: "app", Name: "sessions", Indexes: []mongoinspect.IndexInfo{
+			{Name: "createdAt_1", Key: []mongoinspect.KeyField{{Field: "createdAt", Direction: 1}}, TTL: ttl(86400)},
+		}},
+		{Database: "app", Name: "audit_logs"},
+		{Database: "app", Name: "events", Indexes: []mongoinspect.IndexInfo{
+			{Name: "ts_1", Key: []mongoinspect.KeyField{{Field: "ts", Direction: 1}}, TTL: ttl(int32(180 * day / time.Second))},
+		}},
+		{Database: "app", Name: "carts"},
+		{Database: "app", Name: "users"},
+	}
+	samples := []mongoinspect.FieldSampleResult{
+		{Database: "app", Collection: "audit_logs", DateRanges: []mongoinspect.DateRange{{Field: "_id", Oldest: fixed.Add(-400 * day), Newest: fixed}}},
+		{Database: "app", Collection: "events", DateRanges: []mongoinspect.DateRange{{Field: "ts", Oldest: fixed.Add(-20 * day), Newest: fixed}}},
+	}
+	oplog := &mongoinspect.OplogProfile{Namespaces: []mongoinspect.NamespaceWrites{
+		{Database: "app", Collection: "carts", Deletes: 12},
+	}}
+
+	findings := DetectUnenforcedRetention(policy, collections, samples, oplog)
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings, want 2: %+v", len(findings), findings)
+	}
+	if f := findings[0]; f.Type != FindingRetentionUnenforced || f.Collection != "audit_logs" || f.Severity != SeverityHigh ||
+		!strings.Contains(f.Message, "allows 365 days") || !strings.Contains(f.Message, "oldest sampled document is 400 days old") {
+		t.Errorf("audit_logs finding = %+v", f)
+	}
+	if f := findings[1]; f.Collection != "events" || f.Severity != SeverityMedium ||
+		!strings.Contains(f.Message, `TTL index "ts_1" keeps documents for 180 days`) {
+		t.Errorf("events finding = %+v", f)
+	}
+
+	// Without an oplog sample, deletes cannot be seen.
+	findings = DetectUnenforcedRetention(policy, collections, nil, nil)
+	if len(findings) != 3 || !strings.Contains(findings[2].Message, "run with --oplog") {
+		t.Errorf("without oplog: %+v", findings)
+	}
+}
@@ -0,0 +1,239 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+const (
+	actionAny       = "anyAction"
+	actionGrantRole = "grantRole"
+)
+
+// rolePrivilege is a privilege with the custom role that grants it; via is
+// empty for the role's own privileges.
+type rolePrivilege struct {
+	mongoinspect.Privilege
+	via string
+}
+
+// AuditRoles runs least-privilege detections against user-defined roles.
+// Inherited custom roles are expanded, so a role is reported for privileges
+// it receives through any chain of roles. UNUSED_ROLE needs the user list
+// and is skipped when users is empty.
+func AuditRoles(roles []mongoinspect.RoleInfo, users []mongoinspect.UserInfo) []Finding {
+	byKey := make(map[string]*mongoinspect.RoleInfo, len(roles))
+	keys := make([]string, 0, len(roles))
+	for i := range roles {
+		if roles[i].IsBuiltin {
+			continue
+		}
+		key := roleKey(roles[i].Database, roles[i].Role)
+		byKey[key] = &roles[i]
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	holders := make(map[string][]string)
+	for _, u := range users {
+		for _, r := range u.Roles {
+			key := roleKey(r.DB, r.Role)
+			holders[key] = append(holders[key], u.Username)
+		}
+	}
+
+	var findings []Finding
+	for _, key := range keys {
+		role := byKey[key]
+		privs := expandRolePrivileges(role, byKey)
+		findings = append(findings, detectWildcardPrivileges(role, privs)...)
+		findings = append(findings, detectPrivilegeEscalation(role, privs, holders[key])...)
+	}
+	if len(users) > 0 {
+		findings = append(findings, detectUnusedRoles(keys, byKey, holders)...)
+	}
+	return findings
+}
+
+func roleKey(db, role string) string {
+	return db + "." + role
+}
+
+// expandRolePrivileges collects a role's own privileges, those of inherited
+// custom roles (recursively, cycle-safe), and any server-reported inherited
+// privileges not already covered, such as those from built-in roles.
+func expandRolePrivileges(role *mongoinspect.RoleInfo, byKey map[string]*mongoinspect.RoleInfo) []rolePrivilege {
+	var out []rolePrivilege
+	seen := make(map[string]bool)
+	covered := make(map[string]bool)
+	var walk func(r *mongoinspect.RoleInfo, via string)
+	walk = func(r *mongoinspect.RoleInfo, via string) {
+		key := roleKey(r.Database, r.Role)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		for _, p := range r.Privileges {
+			out = append(out, rolePrivilege{Privilege: p, via: via})
+			covered[privilegeSignature(p)] = true
+		}
+		for _, inherited := range r.Roles {
+			ikey := roleKey(inherited.DB, inherited.Role)
+			if child, ok := byKey[ikey]; ok {
+				walk(child, ikey)
+			}
+		}
+	}
+	walk(role, "")
+
+	for _, p := range role.InheritedPrivileges {
+		if !covered[privilegeSignature(p)] {
+			out = append(out, rolePrivilege{Privilege: p, via: "a built-in role"})
+		}
+	}
+	return out
+}
+
+func privilegeSignature(p mongoinspect.Privilege) string {
+	actions := append([]string(nil), p.Actions...)
+	sort.Strings(actions)
+	return fmt.Sprintf("%s|%s|%t|%t|%s", p.Resource.DB, p.Resource.Collection, p.Resource.Cluster, p.Resource.AnyResource, strings.Join(actions, ","))
+}
+
+// detectWildcardPrivileges flags roles granting anyAction or privileges on
+// anyResource. anyAction on anyResource is equivalent to root.
+func detectWildcardPrivileges(role *mongoinspect.RoleInfo, privs []rolePrivilege) []Finding {
+	var grants []string
+	seen := make(map[string]bool)
+	sev := SeverityMedium
+	for _, p := range privs {
+		anyAction := containsString(p.Actions, actionAny)
+		if !anyAction && !p.Resource.AnyResource {
+			continue
+		}
+		if anyAction && p.Resource.AnyResource {
+			sev = SeverityHigh
+		}
+		var desc string
+		if anyAction {
+			desc = "anyAction on " + formatResource(p.Resource)
+		} else {
+			desc = strings.Join(p.Actions, ", ") + " on any resource"
+		}
+		if p.via != "" {
+			desc += " (via " + p.via + ")"
+		}
+		if !seen[desc] {
+			seen[desc] = true
+			grants = append(grants, desc)
+		}
+	}
+	if len(grants) == 0 {
+		return nil
+	}
+	return []Finding{{
+		Type:     FindingWildcardPrivilege,
+		Severity: sev,
+		Database: role.Database,
+		Message:  fmt.Sprintf("role %q grants %s — list specific actions and resources instead", role.Role, strings.Join(grants, "; ")),
+	}}
+}
+
+// detectPrivilegeEscalation flags roles that may grant roles on their own
+// database: any holder can grant itself every other role defined there.
+func detectPrivilegeEscalation(role *mongoinspect.RoleInfo, privs []rolePrivilege, holders []string) []Finding {
+	for _, p := range privs {
+		if !containsString(p.Actions, actionGrantRole) && !containsString(p.Actions, actionAny) {
+			continue
+		}
+		r := p.Resource
+		if !r.AnyResource && r.DB != "" && r.DB != role.Database {
+			continue
+		}
+		if r.Cluster {
+			continue
+		}
+		msg := fmt.Sprintf("role %q can grant roles on %s, so its holders can grant themselves any role", role.Role, formatResource(r))
+		if p.via != "" {
+			msg += " (via " + p.via + ")"
+		}
+		if len(holders) > 0 {
+			sorted := append([]string(nil), holders...)
+			sort.Strings(sorted)
+			msg += fmt.Sprintf("; held by %s", strings.Join(sorted, ", "))
+		}
+		return []Finding{{
+			Type:     FindingPrivilegeEscalation,
+			Severity: SeverityHigh,
+			Database: role.Database,
+			Message:  msg,
+		}}
+	}
+	return nil
+}
+
+// detectUnusedRoles flags custom roles not granted to any user, directly or
+// through another granted role.
+func detectUnusedRoles(keys []string, byKey map[string]*mongoinspect.RoleInfo, holders map[string][]string) []Finding {
+	used := make(map[string]bool)
+	var mark func(key string)
+	mark = func(key string) {
+		if used[key] {
+			return
+		}
+		used[key] = true
+		if r, ok := byKey[key]; ok {
+			for _, inherited := range r.Roles {
+				mark(roleKey(inherited.DB, inherited.Role))
+			}
+		}
+	}
+	for key := range holders {
+		mark(key)
+	}
+
+	var findings []Finding
+	for _, key := range keys {
+		if used[key] {
+			continue
+		}
+		role := byKey[key]
+		findings = append(findings, Finding{
+			Type:     FindingUnusedRole,
+			Severity: SeverityLow,
+			Database: role.Database,
+			Message:  fmt.Sprintf("role %q is not granted to any user", role.Role),
+		})
+	}
+	return findings
+}
+
+// formatResource renders a privilege resource for messages.
+func formatResource(r mongoinspect.PrivilegeResource) string {
+	switch {
+	case r.AnyResource:
+		return "any resource"
+	case r.Cluster:
+		return "the cluster"
+	case r.DB == "" && r.Collection == "":
+		return "all databases"
+	case r.Collection == "":
+		return fmt.Sprintf("database %q", r.DB)
+	case r.DB == "":
+		return fmt.Sprintf("collection %q in every database", r.Collection)
+	default:
+		return fmt.Sprintf("%q", r.DB+"."+r.Collection)
+	}
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
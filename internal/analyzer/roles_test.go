@@ -0,0 +1,112 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+func priv(db string, actions ...string) mongoinspect.Privilege {
+	return mongoinspect.Privilege{Resource: mongoinspect.PrivilegeResource{DB: db}, Actions: actions}
+}
+
+func TestAuditRoles_WildcardPrivilege(t *testing.T) {
+	roles := []mongoinspect.RoleInfo{
+		{Role: "superuser", Database: "admin", Privileges: []mongoinspect.Privilege{
+			{Resource: mongoinspect.PrivilegeResource{AnyResource: true}, Actions: []string{"anyAction"}},
+		}},
+		{Role: "appAll", Database: "app", Privileges: []mongoinspect.Privilege{priv("app", "anyAction")}},
+		{Role: "reader", Database: "app", Privileges: []mongoinspect.Privilege{priv("app", "find")}},
+	}
+	findings := AuditRoles(roles, nil)
+
+	got := make(map[string]Finding)
+	for _, f := range findings {
+		if f.Type == FindingWildcardPrivilege {
+			got[f.Database] = f
+		}
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 WILDCARD_PRIVILEGE findings, got %+v", findings)
+	}
+	if got["admin"].Severity != SeverityHigh || !strings.Contains(got["admin"].Message, "anyAction on any resource") {
+		t.Errorf("admin finding = %+v", got["admin"])
+	}
+	if got["app"].Severity != SeverityMedium || !strings.Contains(got["app"].Message, `anyAction on database "app"`) {
+		t.Errorf("app finding = %+v", got["app"])
+	}
+}
+
+func TestAuditRoles_InheritedPrivilegesExpanded(t *testing.T) {
+	roles := []mongoinspect.RoleInfo{
+		{Role: "ops", Database: "app", Roles: []mongoinspect.UserRole{{Role: "mid", DB: "app"}}},
+		{Role: "mid", Database: "app", Roles: []mongoinspect.UserRole{{Role: "base", DB: "app"}, {Role: "ops", DB: "app"}}},
+		{Role: "base", Database: "app", Privileges: []mongoinspect.Privilege{priv("app", "grantRole")}},
+	}
+	users := []mongoinspect.UserInfo{
+		{Username: "alice", Database: "app", Roles: []mongoinspect.UserRole{{Role: "ops", DB: "app"}}},
+	}
+	findings := AuditRoles(roles, users)
+
+	var escalations []string
+	for _, f := range findings {
+		switch f.Type {
+		case FindingPrivilegeEscalation:
+			escalations = append(escalations, f.Message)
+		case FindingUnusedRole:
+			t.Errorf("roles reachable from alice must not be unused: %s", f.Message)
+		}
+	}
+	if len(escalations) != 3 {
+		t.Fatalf("expected escalation for base, mid and ops, got %v", escalations)
+	}
+	joined := strings.Join(escalations, "\n")
+	if !strings.Contains(joined, `role "ops" can grant roles on database "app", so its holders can grant themselves any role (via app.base); held by alice`) {
+		t.Errorf("missing inherited escalation for ops:\n%s", joined)
+	}
+}
+
+func TestAuditRoles_BuiltinInheritedPrivileges(t *testing.T) {
+	roles := []mongoinspect.RoleInfo{{
+		Role: "wrapsRoot", Database: "admin",
+		Roles: []mongoinspect.UserRole{{Role: "root", DB: "admin"}},
+		InheritedPrivileges: []mongoinspect.Privilege{
+			{Resource: mongoinspect.PrivilegeResource{AnyResource: true}, Actions: []string{"anyAction"}},
+		},
+	}}
+	findings := AuditRoles(roles, nil)
+	if len(findings) == 0 || findings[0].Type != FindingWildcardPrivilege || !strings.Contains(findings[0].Message, "via a built-in role") {
+		t.Fatalf("expected wildcard finding via built-in role, got %+v", findings)
+	}
+}
+
+func TestAuditRoles_GrantRoleOtherDatabase(t *testing.T) {
+	roles := []mongoinspect.RoleInfo{
+		{Role: "reportAdmin", Database: "app", Privileges: []mongoinspect.Privilege{priv("reporting", "grantRole")}},
+	}
+	for _, f := range AuditRoles(roles, nil) {
+		if f.Type == FindingPrivilegeEscalation {
+			t.Errorf("grantRole on another database is not self-escalation: %+v", f)
+		}
+	}
+}
+
+func TestAuditRoles_UnusedRole(t *testing.T) {
+	roles := []mongoinspect.RoleInfo{
+		{Role: "used", Database: "app"},
+		{Role: "orphan", Database: "app"},
+		{Role: "read", Database: "app", IsBuiltin: true},
+	}
+	users := []mongoinspect.UserInfo{
+		{Username: "bob", Database: "app", Roles: []mongoinspect.UserRole{{Role: "used", DB: "app"}}},
+	}
+	findings := AuditRoles(roles, users)
+	if len(findings) != 1 || findings[0].Type != FindingUnusedRole || !strings.Contains(findings[0].Message, `"orphan"`) {
+		t.Fatalf("expected UNUSED_ROLE for orphan only, got %+v", findings)
+	}
+
+	if findings := AuditRoles(roles, nil); len(findings) != 0 {
+		t.Errorf("UNUSED_ROLE needs a user list, got %+v", findings)
+	}
+}
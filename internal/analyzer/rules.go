@@ -0,0 +1,220 @@
+package analyzer
+
+import "strings"
+
+// RuleDocsURL is the rule reference page. Each rule has an anchor named
+// after its lower-cased ID.
+const RuleDocsURL = "https://github.com/ppiankov/mongospectre/blob/main/docs/rules.md"
+
+// Rule describes a finding type for the rule catalog.
+type Rule struct {
+	ID          string      `json:"id"` // stable; never renumbered or reused
+	Type        FindingType `json:"type"`
+	Severity    Severity    `json:"severity"` // default; some detectors escalate or downgrade
+	Description string      `json:"description"`
+	Config      []string    `json:"config,omitempty"` // config keys and flags that affect the rule
+}
+
+// DocURL returns the rule's section of the rule reference.
+func (r *Rule) DocURL() string {
+	return RuleDocsURL + "#" + strings.ToLower(r.ID)
+}
+
+// rules lists every finding type. New types get the next free ID at the
+// end, whatever their place in types.go.
+var rules = []Rule{
+	{ID: "MS001", Type: FindingUnusedCollection, Severity: SeverityMedium, Description: "Collection has no documents, or exists but is not referenced in code"},
+	{ID: "MS002", Type: FindingUnusedIndex, Severity: SeverityMedium, Description: "Index has never been used since the server started; hide it with hideIndex before dropping"},
+	{ID: "MS003", Type: FindingHiddenIndexForgotten, Severity: SeverityLow, Description: "Index has been hidden with zero operations for over 30 days and is safe to drop"},
+	{ID: "MS004", Type: FindingMissingIndex, Severity: SeverityHigh, Description: "Large collection has only the _id index"},
+	{ID: "MS005", Type: FindingDuplicateIndex, Severity: SeverityLow, Description: "Index key is a prefix of another index"},
+	{ID: "MS006", Type: FindingOversizedCollection, Severity: SeverityLow, Description: "Collection storage exceeds the oversized threshold (10 GB by default)", Config: []string{"analyzer.oversized_collection_gb"}},
+	{ID: "MS007", Type: FindingMissingTTL, Severity: SeverityLow, Description: "Timestamp field is indexed without a TTL"},
+	{ID: "MS008", Type: FindingTTLMisconfigured, Severity: SeverityMedium, Description: "TTL index cannot expire documents as intended: compound key, expireAfterSeconds 0 on a non-expiry field, partial filter, or non-date values", Config: []string{"--sample-size"}},
+	{ID: "MS009", Type: FindingUnshardedLarge, Severity: SeverityMedium, Description: "Collection over the oversized threshold is not sharded on a sharded cluster", Config: []string{"analyzer.oversized_collection_gb", "--sharding"}},
+	{ID: "MS010", Type: FindingMonotonicShardKey, Severity: SeverityMedium, Description: "Shard key can be monotonic and route all inserts to one hot shard", Config: []string{"--sharding"}},
+	{ID: "MS011", Type: FindingUnbalancedChunks, Severity: SeverityMedium, Description: "Chunk distribution across shards is heavily skewed", Config: []string{"--sharding"}},
+	{ID: "MS012", Type: FindingJumboChunks, Severity: SeverityHigh, Description: "Collection has jumbo chunks the balancer cannot split or move", Config: []string{"--sharding"}},
+	{ID: "MS013", Type: FindingBalancerDisabled, Severity: SeverityMedium, Description: "The chunk balancer is disabled", Config: []string{"--sharding"}},
+	{ID: "MS014", Type: FindingShardingCandidate, Severity: SeverityInfo, Description: "Unsharded collection over 1 GB on a sharded cluster; suggests a shard key from code query patterns", Config: []string{"--sharding"}},
+	{ID: "MS015", Type: FindingMissingCollection, Severity: SeverityHigh, Description: "Collection referenced in code does not exist in the database"},
+	{ID: "MS016", Type: FindingOrphanedIndex, Severity: SeverityLow, Description: "Unused index on a collection the code does not reference"},
+	{ID: "MS017", Type: FindingUnindexedQuery, Severity: SeverityMedium, Description: "Field queried in code has no covering index", Config: []string{"analyzer.suggest_min_docs"}},
+	{ID: "MS018", Type: FindingSuggestIndex, Severity: SeverityInfo, Description: "Consider adding an index for a field queried in code", Config: []string{"analyzer.suggest_min_docs"}},
+	{ID: "MS019", Type: FindingCompoundIndexSuggest, Severity: SeverityInfo, Description: "Fields queried together in code would benefit from a compound index", Config: []string{"analyzer.suggest_min_docs"}},
+	{ID: "MS020", Type: FindingPartialIndexSuggest, Severity: SeverityInfo, Description: "Every query filters a field on the same literal; a partial index would be smaller", Config: []string{"analyzer.suggest_min_docs"}},
+	{ID: "MS021", Type: FindingUniqueIndexSuggest, Severity: SeverityMedium, Description: "Code upserts on a natural key that has no unique index"},
+	{ID: "MS022", Type: FindingIndexOrderWarning, Severity: SeverityLow, Description: "Compound index field order does not match how code queries and sorts"},
+	{ID: "MS023", Type: FindingWildcardIndexBloat, Severity: SeverityMedium, Description: "Wildcard ($**) index is larger than the collection data"},
+	{ID: "MS024", Type: FindingTextIndexCost, Severity: SeverityLow, Description: "Text index is at least half the data size but rarely used"},
+	{ID: "MS025", Type: FindingTextIndexConflict, Severity: SeverityMedium, Description: "Code creates text indexes that differ from each other or from the live text index"},
+	{ID: "MS026", Type: FindingRedundantIndex, Severity: SeverityLow, Description: "Index is covered by another index and is not needed by code queries"},
+	{ID: "MS027", Type: FindingPartialCoverage, Severity: SeverityInfo, Description: "An index covers only some of the fields a code query filters on"},
+	{ID: "MS028", Type: FindingSlowQuerySource, Severity: SeverityMedium, Description: "Code location matches slow query shapes in system.profile", Config: []string{"--profile"}},
+	{ID: "MS029", Type: FindingCollectionScanSource, Severity: SeverityHigh, Description: "Code location matches profiler COLLSCAN queries", Config: []string{"--profile"}},
+	{ID: "MS030", Type: FindingFrequentSlowQuery, Severity: SeverityMedium, Description: "The same slow query shape appears 50 or more times in the profiler", Config: []string{"--profile"}},
+	{ID: "MS031", Type: FindingPoorQueryTargeting, Severity: SeverityMedium, Description: "Query shape examines 100 or more documents per returned document (high at 1000)", Config: []string{"--profile"}},
+	{ID: "MS032", Type: FindingInMemorySort, Severity: SeverityMedium, Description: "Query shape uses a blocking in-memory sort", Config: []string{"--profile"}},
+	{ID: "MS033", Type: FindingSortSpilledToDisk, Severity: SeverityHigh, Description: "Blocking sort exceeded the memory limit and spilled to disk", Config: []string{"--profile"}},
+	{ID: "MS034", Type: FindingAdminInDataDB, Severity: SeverityHigh, Description: "User holds an admin role in a non-admin database", Config: []string{"--audit-users"}},
+	{ID: "MS035", Type: FindingDuplicateUser, Severity: SeverityMedium, Description: "User exists in admin and in application databases, risking auth source confusion", Config: []string{"--audit-users"}},
+	{ID: "MS036", Type: FindingOverprivilegedUser, Severity: SeverityMedium, Description: "User holds broad built-in roles such as root or readWriteAnyDatabase", Config: []string{"--audit-users"}},
+	{ID: "MS037", Type: FindingMultipleAdminUsers, Severity: SeverityMedium, Description: "Several users hold cluster-admin roles", Config: []string{"--audit-users"}},
+	{ID: "MS038", Type: FindingWeakAuthMechanism, Severity: SeverityMedium, Description: "User is limited to SCRAM-SHA-1, or has a malformed or duplicated x.509 subject", Config: []string{"--audit-users"}},
+	{ID: "MS039", Type: FindingNoClientSource, Severity: SeverityLow, Description: "User has no clientSource authentication restriction", Config: []string{"--audit-users"}},
+	{ID: "MS040", Type: FindingWildcardPrivilege, Severity: SeverityHigh, Description: "Custom role grants anyAction or privileges on anyResource", Config: []string{"--audit-users"}},
+	{ID: "MS041", Type: FindingPrivilegeEscalation, Severity: SeverityHigh, Description: "Custom role can grantRole on its own database", Config: []string{"--audit-users"}},
+	{ID: "MS042", Type: FindingUnusedRole, Severity: SeverityLow, Description: "Custom role is not granted to any user", Config: []string{"--audit-users"}},
+	{ID: "MS043", Type: FindingDynamicCollection, Severity: SeverityInfo, Description: "Collection name in code comes from a variable that could not be resolved statically"},
+	{ID: "MS044", Type: FindingValidatorMissing, Severity: SeverityMedium, Description: "Collection written in code has no JSON schema validator"},
+	{ID: "MS045", Type: FindingValidatorStale, Severity: SeverityMedium, Description: "Validator field type does not match the type code writes"},
+	{ID: "MS046", Type: FindingValidatorStrictRisk, Severity: SeverityLow, Description: "Strict validator in error mode will reject writes on schema drift"},
+	{ID: "MS047", Type: FindingValidatorWarnOnly, Severity: SeverityLow, Description: "Validator only warns and does not reject invalid writes"},
+	{ID: "MS048", Type: FindingFieldNotInValidator, Severity: SeverityMedium, Description: "Code writes a field missing from validator properties while additionalProperties is false"},
+	{ID: "MS049", Type: FindingCSFLESchemaDrift, Severity: SeverityMedium, Description: "Client-side encryption schema in code disagrees with the server-side encryption schema"},
+	{ID: "MS050", Type: FindingAtlasIndexSuggestion, Severity: SeverityMedium, Description: "Atlas Performance Advisor recommends an index", Config: []string{"--atlas-public-key", "--atlas-private-key"}},
+	{ID: "MS051", Type: FindingAtlasAlertActive, Severity: SeverityMedium, Description: "Atlas project has open alerts", Config: []string{"--atlas-public-key", "--atlas-private-key"}},
+	{ID: "MS052", Type: FindingAtlasTierMismatch, Severity: SeverityHigh, Description: "Atlas cluster tier may be undersized for the data footprint", Config: []string{"--atlas-public-key", "--atlas-private-key"}},
+	{ID: "MS053", Type: FindingAtlasVersionBehind, Severity: SeverityMedium, Description: "Atlas cluster MongoDB version is behind the versions available to the project", Config: []string{"--atlas-public-key", "--atlas-private-key"}},
+	{ID: "MS054", Type: FindingAtlasUserNoScope, Severity: SeverityInfo, Description: "Atlas database user is not scoped to specific clusters", Config: []string{"--atlas-public-key", "--atlas-private-key"}},
+	{ID: "MS055", Type: FindingInactiveUser, Severity: SeverityMedium, Description: "User has not authenticated in the last 7 days", Config: []string{"--audit-users"}},
+	{ID: "MS056", Type: FindingFailedAuthOnly, Severity: SeverityMedium, Description: "User has only failed authentication attempts in the last 7 days", Config: []string{"--audit-users"}},
+	{ID: "MS057", Type: FindingInactivePrivilegedUser, Severity: SeverityHigh, Description: "Privileged user has not authenticated in the last 7 days", Config: []string{"--audit-users"}},
+	{ID: "MS058", Type: FindingMissingField, Severity: SeverityMedium, Description: "Field referenced in code was not found in sampled documents", Config: []string{"--sample-size"}},
+	{ID: "MS059", Type: FindingRareField, Severity: SeverityLow, Description: "Field referenced in code is present in few sampled documents", Config: []string{"--sample-size"}},
+	{ID: "MS060", Type: FindingUndocumentedField, Severity: SeverityInfo, Description: "Field found in sampled documents is not referenced in code", Config: []string{"--sample-size"}},
+	{ID: "MS061", Type: FindingTypeInconsistency, Severity: SeverityMedium, Description: "Field has different BSON types across sampled documents", Config: []string{"--sample-size"}},
+	{ID: "MS062", Type: FindingURINoAuth, Severity: SeverityLow, Description: "Connection string has no credentials or cannot be parsed"},
+	{ID: "MS063", Type: FindingURINoTLS, Severity: SeverityLow, Description: "Connection string does not enable TLS"},
+	{ID: "MS064", Type: FindingURINoRetryWrites, Severity: SeverityInfo, Description: "Connection string does not set retryWrites=true (low when it sets retryWrites=false)"},
+	{ID: "MS065", Type: FindingURIPlaintextPassword, Severity: SeverityInfo, Description: "Connection string embeds a password"},
+	{ID: "MS066", Type: FindingURIDefaultAuthSource, Severity: SeverityInfo, Description: "Connection string does not specify authSource"},
+	{ID: "MS067", Type: FindingURIShortTimeout, Severity: SeverityLow, Description: "connectTimeoutMS, serverSelectionTimeoutMS or socketTimeoutMS is short enough to cause spurious timeouts"},
+	{ID: "MS068", Type: FindingURINoReadPreference, Severity: SeverityInfo, Description: "Connection string does not set readPreference"},
+	{ID: "MS069", Type: FindingURIDirectConnection, Severity: SeverityLow, Description: "directConnection=true is set on an SRV, multi-host or replicaSet connection string"},
+	{ID: "MS070", Type: FindingHardcodedURI, Severity: SeverityHigh, Description: "Source file contains a connection string with a literal password"},
+	{ID: "MS071", Type: FindingAuthDisabled, Severity: SeverityHigh, Description: "Authentication is disabled", Config: []string{"--security"}},
+	{ID: "MS072", Type: FindingBindAllInterfaces, Severity: SeverityHigh, Description: "Server listens on all network interfaces", Config: []string{"--security"}},
+	{ID: "MS073", Type: FindingTLSDisabled, Severity: SeverityHigh, Description: "TLS is not configured on the server", Config: []string{"--security"}},
+	{ID: "MS074", Type: FindingTLSAllowInvalidCerts, Severity: SeverityHigh, Description: "Server accepts invalid TLS certificates", Config: []string{"--security"}},
+	{ID: "MS075", Type: FindingAuditLogDisabled, Severity: SeverityMedium, Description: "Audit logging is not configured", Config: []string{"--security"}},
+	{ID: "MS076", Type: FindingLocalhostException, Severity: SeverityMedium, Description: "The localhost authentication exception is still active", Config: []string{"--security"}},
+	{ID: "MS077", Type: FindingExternalAuthMisconfig, Severity: SeverityMedium, Description: "LDAP or Kerberos authentication is misconfigured", Config: []string{"--security"}},
+	{ID: "MS078", Type: FindingNoEncryptionAtRest, Severity: SeverityMedium, Description: "Storage encryption is off", Config: []string{"--security"}},
+	{ID: "MS079", Type: FindingStaleKMIPKey, Severity: SeverityMedium, Description: "Encryption data key has not been rotated recently", Config: []string{"--security"}},
+	{ID: "MS080", Type: FindingServerParamDrift, Severity: SeverityMedium, Description: "Server parameter differs from the recommended value", Config: []string{"--server-params"}},
+	{ID: "MS081", Type: FindingCachePressure, Severity: SeverityHigh, Description: "WiredTiger cache stayed at least 95% full, or 20% dirty, for 5 minutes or more"},
+	{ID: "MS082", Type: FindingTicketExhaustion, Severity: SeverityHigh, Description: "No read or write execution tickets were available for a minute or more"},
+	{ID: "MS083", Type: FindingSlowCheckpoint, Severity: SeverityMedium, Description: "Checkpoints took longer than the 60s checkpoint interval"},
+	{ID: "MS084", Type: FindingHighCollectionLatency, Severity: SeverityMedium, Description: "p95 read or write latency of a collection is above the limit (high at 10x)", Config: []string{"analyzer.high_latency_ms"}},
+	{ID: "MS085", Type: FindingIndexBloat, Severity: SeverityMedium, Description: "Total index size exceeds the collection data size"},
+	{ID: "MS086", Type: FindingWriteHeavyOverIndexed, Severity: SeverityMedium, Description: "Collection has more indexes than the limit, so every write updates all of them", Config: []string{"analyzer.max_indexes"}},
+	{ID: "MS087", Type: FindingSingleFieldRedundant, Severity: SeverityLow, Description: "Single-field index is covered by a compound index"},
+	{ID: "MS088", Type: FindingLargeIndex, Severity: SeverityLow, Description: "Single index is larger than the limit (1 GB by default)", Config: []string{"analyzer.large_index_gb"}},
+	{ID: "MS089", Type: FindingUnboundedArray, Severity: SeverityLow, Description: "Array field in sampled documents is large enough to grow without bound", Config: []string{"--sample-size"}},
+	{ID: "MS090", Type: FindingDeepNesting, Severity: SeverityLow, Description: "Field is nested deeply enough to be hard to index and query", Config: []string{"--sample-size"}},
+	{ID: "MS091", Type: FindingLargeDocument, Severity: SeverityLow, Description: "Largest sampled document is approaching the 16 MB BSON limit", Config: []string{"--sample-size"}},
+	{ID: "MS092", Type: FindingFieldNameCollision, Severity: SeverityLow, Description: "Field is an object in some documents and a scalar in others", Config: []string{"--sample-size"}},
+	{ID: "MS093", Type: FindingExcessiveFieldCount, Severity: SeverityInfo, Description: "Documents have an excessive number of top-level fields", Config: []string{"--sample-size"}},
+	{ID: "MS094", Type: FindingNumericFieldNames, Severity: SeverityInfo, Description: "Field path has numeric segments, likely an array stored as an object", Config: []string{"--sample-size"}},
+	{ID: "MS095", Type: FindingFieldNameHazard, Severity: SeverityMedium, Description: "Field name contains dots, a leading $, an overlong path, or differs from a sibling only by case", Config: []string{"--sample-size"}},
+	{ID: "MS096", Type: FindingDocumentsNear16MB, Severity: SeverityMedium, Description: "Documents are close to the 16 MB BSON limit", Config: []string{"--sample-size"}},
+	{ID: "MS097", Type: FindingUnboundedArrayField, Severity: SeverityMedium, Description: "Sampled array has over 1000 elements", Config: []string{"--sample-size"}},
+	{ID: "MS098", Type: FindingSchemaAntipattern, Severity: SeverityMedium, Description: "Subdocument has thousands of distinct dynamic keys", Config: []string{"--sample-size"}},
+	{ID: "MS099", Type: FindingSingleMemberReplSet, Severity: SeverityHigh, Description: "Replica set has a single member and no failover", Config: []string{"--replset"}},
+	{ID: "MS100", Type: FindingEvenMemberCount, Severity: SeverityMedium, Description: "Replica set has an even number of voting members", Config: []string{"--replset"}},
+	{ID: "MS101", Type: FindingMemberUnhealthy, Severity: SeverityHigh, Description: "Replica set member is down or in an unhealthy state", Config: []string{"--replset"}},
+	{ID: "MS102", Type: FindingOplogSmall, Severity: SeverityMedium, Description: "Oplog window is shorter than 24 hours", Config: []string{"--replset"}},
+	{ID: "MS103", Type: FindingNoHiddenMember, Severity: SeverityInfo, Description: "Replica set has no hidden member for analytics or backups", Config: []string{"--replset"}},
+	{ID: "MS104", Type: FindingPriorityZeroMajority, Severity: SeverityHigh, Description: "A majority of replica set members have priority 0", Config: []string{"--replset"}},
+	{ID: "MS105", Type: FindingRapidGrowth, Severity: SeverityMedium, Description: "Collection data size grew rapidly since the baseline", Config: []string{"baseline_dir"}},
+	{ID: "MS106", Type: FindingIndexGrowthOutpacing, Severity: SeverityLow, Description: "Index size grew much faster than data since the baseline", Config: []string{"baseline_dir"}},
+	{ID: "MS107", Type: FindingApproachingLimit, Severity: SeverityMedium, Description: "Collection is approaching practical single-node size limits", Config: []string{"baseline_dir"}},
+	{ID: "MS108", Type: FindingStorageReclaim, Severity: SeverityLow, Description: "Storage size is more than twice the data size; compact would reclaim space", Config: []string{"baseline_dir"}},
+	{ID: "MS109", Type: FindingAcceleratingGrowth, Severity: SeverityMedium, Description: "Growth rate in the later half of the baseline history is at least twice the earlier half", Config: []string{"baseline_dir"}},
+	{ID: "MS110", Type: FindingStorageForecast, Severity: SeverityMedium, Description: "Storage is forecast to reach a configured limit within the forecast horizon", Config: []string{"thresholds.storage_limit_gb", "thresholds.collection_limit_gb", "thresholds.forecast_days"}},
+	{ID: "MS111", Type: FindingNamingViolation, Severity: SeverityInfo, Description: "Collection, field or index name does not follow the configured naming convention", Config: []string{"naming.collections", "naming.fields", "naming.indexes"}},
+	{ID: "MS112", Type: FindingOK, Severity: SeverityInfo, Description: "Collection exists and is referenced in code"},
+	{ID: "MS113", Type: FindingWriteHotspot, Severity: SeverityLow, Description: "Collection receives at least half of the sampled oplog writes", Config: []string{"--oplog", "--oplog-limit"}},
+	{ID: "MS114", Type: FindingShadowWriter, Severity: SeverityMedium, Description: "Collection receives writes in the sampled oplog but is not referenced in code", Config: []string{"--oplog", "--oplog-limit"}},
+	{ID: "MS115", Type: FindingWriteOnlyCollection, Severity: SeverityLow, Description: "Code writes the collection but never reads it and the server reports no reads", Config: []string{"--profile"}},
+	{ID: "MS116", Type: FindingStaleReadModel, Severity: SeverityLow, Description: "Code reads a non-empty collection but never writes it and the server reports no writes", Config: []string{"--profile"}},
+	{ID: "MS117", Type: FindingLikelyDeadCollection, Severity: SeverityLow, Description: "Every file referencing the collection is unchanged in git for months and the server reports no operations on it", Config: []string{"--git-stale-months"}},
+	{ID: "MS118", Type: FindingPossiblePII, Severity: SeverityMedium, Description: "Sampled string values of a field match personal data patterns such as emails, phone or card numbers", Config: []string{"--pii", "--sample-size", "pii.patterns"}},
+	{ID: "MS119", Type: FindingSensitiveUnencrypted, Severity: SeverityMedium, Description: "Fields tagged sensitive in the classification config are not covered by Queryable Encryption or CSFLE", Config: []string{"classification"}},
+	{ID: "MS120", Type: FindingRetentionUnenforced, Severity: SeverityMedium, Description: "A collection under a configured retention period has no TTL index or observed deletes enforcing it; high when sampled documents are already older", Config: []string{"retention", "--sample-size", "--oplog"}},
+	{ID: "MS121", Type: FindingStaleData, Severity: SeverityLow, Description: "The newest sampled document, by ObjectId _id and common date fields, is years old", Config: []string{"--sample-size", "analyzer.stale_data_days"}},
+	{ID: "MS122", Type: FindingArchiveCandidate, Severity: SeverityLow, Description: "A large collection, or the share of it created over a year ago, could move to cold storage; gives the estimated bytes reclaimed", Config: []string{"--sample-size"}},
+	{ID: "MS123", Type: FindingFragmentedCollection, Severity: SeverityLow, Description: "At least 20% of a collection's WiredTiger files is free space held for reuse; compact or an initial sync would reclaim it (medium at 50%)"},
+	{ID: "MS124", Type: FindingIndexesExceedCache, Severity: SeverityMedium, Description: "Indexes serving operations exceed 80% of the WiredTiger cache (high when they exceed it); ranks the largest contributing collections"},
+	{ID: "MS125", Type: FindingVectorDimMismatch, Severity: SeverityHigh, Description: "Vector search index numDimensions differs from the embedding size in code or in sampled documents"},
+	{ID: "MS126", Type: FindingVectorFilterNotIndexed, Severity: SeverityHigh, Description: "$vectorSearch filters on a field the vector search index does not declare as a filter field"},
+	{ID: "MS127", Type: FindingVectorNotNormalized, Severity: SeverityMedium, Description: "Vector search index uses dotProduct similarity but sampled embeddings are not unit length"},
+	{ID: "MS128", Type: FindingQEUnsupportedQuery, Severity: SeverityHigh, Description: "Code queries a Queryable Encryption field with an operator its query type does not support, such as a range on an equality-only field"},
+	{ID: "MS129", Type: FindingLongTransactionRisk, Severity: SeverityMedium, Description: "Transaction in code makes a network call or sleeps before committing (high when many transactions abort); also reports a high server abort rate"},
+	{ID: "MS130", Type: FindingMixedShardingTxn, Severity: SeverityMedium, Description: "Transaction in code touches both sharded and unsharded collections, making every commit a cross-shard two-phase commit"},
+	{ID: "MS131", Type: FindingUnsafeWriteConcern, Severity: SeverityHigh, Description: "Code or the URI sets w:0 (high), j:false (medium), or w:1 on a multi-member replica set (low)"},
+	{ID: "MS132", Type: FindingWMajorityLatency, Severity: SeverityMedium, Description: "A w:\"majority\" or numeric write concern needs every healthy data-bearing member, such as with an arbiter (high when it cannot be acknowledged at all)"},
+	{ID: "MS133", Type: FindingCausalConsistencyRisk, Severity: SeverityMedium, Description: "Code reads from secondaries after a write in the same function without readConcern \"majority\", so the read can miss the write"},
+	{ID: "MS134", Type: FindingReadPrefNoSecondary, Severity: SeverityHigh, Description: "Code or the URI sets readPreference \"secondary\" on a replica set with a single data-bearing member"},
+	{ID: "MS135", Type: FindingURIPoolSize, Severity: SeverityLow, Description: "Connection string sets minPoolSize above maxPoolSize, an unbounded maxPoolSize=0, or pool sizes above 1000"},
+	{ID: "MS136", Type: FindingURINoAppName, Severity: SeverityInfo, Description: "Connection string does not set appName, so server logs and currentOp cannot attribute operations"},
+	{ID: "MS137", Type: FindingClientAppTraffic, Severity: SeverityInfo, Description: "Collections accessed by a client application whose appName the scanned repo sets (--client-apps)"},
+	{ID: "MS138", Type: FindingUnknownClientApp, Severity: SeverityLow, Description: "Client application whose appName the scanned repo does not set is using the database (medium when it writes)"},
+	{ID: "MS139", Type: FindingClientConnSources, Severity: SeverityInfo, Description: "Client connections by source IP and driver version, with server connection pool usage (--connections)"},
+	{ID: "MS140", Type: FindingDriverOutdated, Severity: SeverityMedium, Description: "Connected clients use a driver release older than the first one supporting the server version"},
+	{ID: "MS141", Type: FindingDriverServerCompat, Severity: SeverityMedium, Description: "Driver dependency in go.mod, package.json or requirements.txt predates support for the server version (high when end of life)"},
+	{ID: "MS142", Type: FindingODMAutoIndex, Severity: SeverityMedium, Description: "Mongoose autoIndex or Spring Data auto-index-creation builds schema indexes when the app starts"},
+	{ID: "MS143", Type: FindingODMStartupIndexBuild, Severity: SeverityMedium, Description: "ODM call builds indexes from the app, such as syncIndexes or ensureIndex (high when syncIndexes drops live indexes)"},
+	{ID: "MS144", Type: FindingODMIndexDrift, Severity: SeverityMedium, Description: "Schema-level index declarations disagree with the live indexes: declared but missing (medium), or live but undeclared (low)"},
+	{ID: "MS145", Type: FindingFixtureStaleCollection, Severity: SeverityMedium, Description: "Fixture file seeds a collection that is neither in the database nor referenced in code (info when its documents do not look like MongoDB data)"},
+	{ID: "MS146", Type: FindingFixtureSchemaDrift, Severity: SeverityMedium, Description: "Fixture documents miss required fields or break the collection validator (high when the validator rejects writes)"},
+	{ID: "MS147", Type: FindingIaCDrift, Severity: SeverityMedium, Description: "Atlas database user, IP access list entry or cluster exists only in Terraform/Pulumi or only in Atlas (high for an undeclared open access entry)"},
+	{ID: "MS148", Type: FindingOperatorUserDrift, Severity: SeverityMedium, Description: "Users, user roles or custom roles declared in Kubernetes operator resources differ from the live deployment (low for undeclared live users)"},
+	{ID: "MS149", Type: FindingOperatorConfigDrift, Severity: SeverityMedium, Description: "MongoDB version, member count or topology declared in a Kubernetes operator resource differs from the live deployment"},
+}
+
+var rulesByType = func() map[FindingType]*Rule {
+	m := make(map[FindingType]*Rule, len(rules))
+	for i := range rules {
+		m[rules[i].Type] = &rules[i]
+	}
+	return m
+}()
+
+// Rules returns the rule catalog in ID order.
+func Rules() []Rule {
+	out := make([]Rule, len(rules))
+	copy(out, rules)
+	return out
+}
+
+// RuleFor returns the catalog entry for a finding type.
+func RuleFor(t FindingType) (Rule, bool) {
+	r, ok := rulesByType[t]
+	if !ok {
+		return Rule{}, false
+	}
+	return *r, true
+}
+
+// AnnotateRules returns a copy of findings with RuleID and DocURL set
+// from the catalog.
+func AnnotateRules(findings []Finding) []Finding {
+	if findings == nil {
+		return nil
+	}
+	out := make([]Finding, len(findings))
+	for i := range findings {
+		out[i] = findings[i]
+		out[i].AnnotateRule()
+	}
+	return out
+}
+
+// AnnotateRule sets RuleID and DocURL from the catalog.
+func (f *Finding) AnnotateRule() {
+	if r, ok := rulesByType[f.Type]; ok {
+		f.RuleID, f.DocURL = r.ID, r.DocURL()
+	}
+}
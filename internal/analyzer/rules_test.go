@@ -0,0 +1,52 @@
+package analyzer
+
+import (
+	"os"
+	"regexp"
+	"testing"
+)
+
+func TestRulesCoverEveryFindingType(t *testing.T) {
+	src, err := os.ReadFile("types.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	declared := regexp.MustCompile(`FindingType = "(\w+)"`).FindAllStringSubmatch(string(src), -1)
+	for _, m := range declared {
+		if _, ok := RuleFor(FindingType(m[1])); !ok {
+			t.Errorf("finding type %s has no rule", m[1])
+		}
+	}
+
+	idFormat := regexp.MustCompile(`^MS\d{3}$`)
+	ids := make(map[string]bool)
+	for _, r := range Rules() {
+		if !idFormat.MatchString(r.ID) {
+			t.Errorf("rule %s has malformed ID %q", r.Type, r.ID)
+		}
+		if ids[r.ID] {
+			t.Errorf("duplicate rule ID %s", r.ID)
+		}
+		ids[r.ID] = true
+		if r.Description == "" || r.Severity == "" {
+			t.Errorf("rule %s is missing a description or severity", r.ID)
+		}
+	}
+	if len(Rules()) != len(declared) {
+		t.Errorf("rules = %d, finding types = %d", len(Rules()), len(declared))
+	}
+}
+
+func TestAnnotateRules(t *testing.T) {
+	findings := []Finding{{Type: FindingMissingIndex}, {Type: "NOT_A_RULE"}}
+	got := AnnotateRules(findings)
+	if got[0].RuleID != "MS004" || got[0].DocURL != RuleDocsURL+"#ms004" {
+		t.Errorf("annotated = %+v", got[0])
+	}
+	if got[1].RuleID != "" {
+		t.Errorf("unknown type annotated: %+v", got[1])
+	}
+	if findings[0].RuleID != "" {
+		t.Error("AnnotateRules modified its input")
+	}
+}
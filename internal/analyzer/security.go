@@ -1,7 +1,9 @@
 package analyzer
 
 import (
+	"fmt"
 	"strings"
+	"time"
 
 	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
 )
@@ -15,6 +17,9 @@ func AuditSecurity(info mongoinspect.SecurityInfo) []Finding {
 	findings = append(findings, detectTLSAllowInvalidCerts(&info)...)
 	findings = append(findings, detectAuditLogDisabled(&info)...)
 	findings = append(findings, detectLocalhostException(&info)...)
+	findings = append(findings, detectExternalAuthMisconfig(&info)...)
+	findings = append(findings, detectNoEncryptionAtRest(&info)...)
+	findings = append(findings, detectStaleKMIPKeys(&info)...)
 	return findings
 }
 
@@ -91,3 +96,114 @@ func detectLocalhostException(info *mongoinspect.SecurityInfo) []Finding {
 		Message:  "localhost authentication bypass is active — allows creating first user without credentials",
 	}}
 }
+
+// dataKeyRotationMaxAge is how long a KMIP-wrapped data key may go without
+// being rewrapped.
+const dataKeyRotationMaxAge = 365 * 24 * time.Hour
+
+// detectNoEncryptionAtRest flags servers without storage encryption. It
+// needs getCmdLineOpts: when that was denied the setting is unknown. A
+// KMIP server is only configured for encryption at rest, so it counts as
+// encryption even when enableEncryption is not reported.
+func detectNoEncryptionAtRest(info *mongoinspect.SecurityInfo) []Finding {
+	if !info.CmdLineOpts || info.EncryptionAtRest || info.KMIPServer != "" {
+		return nil
+	}
+	return []Finding{{
+		Type:     FindingNoEncryptionAtRest,
+		Severity: SeverityMedium,
+		Message:  "storage encryption (security.enableEncryption) is off — data files are readable unless the volume is encrypted",
+	}}
+}
+
+// detectStaleKMIPKeys flags key vault data keys wrapped by a KMIP master key
+// that have not been rewrapped within dataKeyRotationMaxAge.
+func detectStaleKMIPKeys(info *mongoinspect.SecurityInfo) []Finding {
+	var findings []Finding
+	for _, k := range info.DataKeys {
+		if k.Provider != "kmip" {
+			continue
+		}
+		rotated := k.Updated
+		if rotated.IsZero() {
+			rotated = k.Created
+		}
+		if rotated.IsZero() {
+			continue
+		}
+		age := now().Sub(rotated)
+		if age < dataKeyRotationMaxAge {
+			continue
+		}
+		name := k.ID
+		if len(k.AltNames) > 0 {
+			name = k.AltNames[0]
+		}
+		findings = append(findings, Finding{
+			Type:       FindingStaleKMIPKey,
+			Severity:   SeverityMedium,
+			Database:   "encryption",
+			Collection: "__keyVault",
+			Message: fmt.Sprintf("data key %q was last rotated %d days ago — rewrap it with rewrapManyDataKey",
+				name, int(age.Hours()/24)),
+		})
+	}
+	return findings
+}
+
+// ldapCacheMaxSecs is the longest ldapUserCacheInvalidationInterval accepted
+// before revoked LDAP group memberships linger too long (default is 30s).
+const ldapCacheMaxSecs = 300
+
+// detectExternalAuthMisconfig checks LDAP, Kerberos and OIDC settings on
+// Enterprise servers. Only configuration is inspected; bind credentials are
+// never read.
+func detectExternalAuthMisconfig(info *mongoinspect.SecurityInfo) []Finding {
+	var findings []Finding
+	add := func(sev Severity, msg string) {
+		findings = append(findings, Finding{Type: FindingExternalAuthMisconfig, Severity: sev, Message: msg})
+	}
+
+	if ldap := info.LDAP; ldap != nil {
+		if ldap.Servers == "" {
+			add(SeverityMedium, "security.ldap is configured without servers — LDAP authentication cannot succeed")
+		}
+		if strings.EqualFold(ldap.TransportSecurity, "none") {
+			add(SeverityHigh, "security.ldap.transportSecurity is none — LDAP bind credentials and user passwords cross the network unencrypted")
+		}
+		if !ldap.ValidateServerConfig {
+			add(SeverityLow, "security.ldap.validateLDAPServerConfig is false — LDAP connectivity errors surface only at login")
+		}
+		if ldap.CacheInvalidationSecs > ldapCacheMaxSecs {
+			add(SeverityLow, fmt.Sprintf("ldapUserCacheInvalidationInterval is %ds — revoked LDAP group memberships stay effective that long", ldap.CacheInvalidationSecs))
+		}
+		if hasAuthMechanism(info, "GSSAPI") && ldap.AuthzQueryTemplate != "" && ldap.UserToDNMapping == "" {
+			add(SeverityMedium, "Kerberos (GSSAPI) is enabled with LDAP authorization but no security.ldap.userToDNMapping — principals like user@REALM will not resolve to LDAP DNs")
+		}
+	}
+
+	if hasAuthMechanism(info, "MONGODB-OIDC") && len(info.OIDCProviders) == 0 {
+		add(SeverityMedium, "MONGODB-OIDC is enabled but oidcIdentityProviders is empty")
+	}
+	for _, p := range info.OIDCProviders {
+		if !strings.HasPrefix(strings.ToLower(p.Issuer), "https://") {
+			add(SeverityHigh, fmt.Sprintf("OIDC issuer %q does not use https — token signing keys are fetched over an insecure channel", p.Issuer))
+		}
+		if p.Audience == "" {
+			add(SeverityMedium, fmt.Sprintf("OIDC provider %q has no audience — tokens issued for other applications may be accepted", p.Issuer))
+		}
+		if p.UseAuthorizationClaim && p.AuthorizationClaim == "" {
+			add(SeverityMedium, fmt.Sprintf("OIDC provider %q uses authorization claims but authorizationClaim is not set", p.Issuer))
+		}
+	}
+	return findings
+}
+
+func hasAuthMechanism(info *mongoinspect.SecurityInfo, mechanism string) bool {
+	for _, m := range info.AuthMechanisms {
+		if m == mechanism {
+			return true
+		}
+	}
+	return false
+}
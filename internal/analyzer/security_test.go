@@ -1,7 +1,9 @@
 package analyzer
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
 )
@@ -145,6 +147,8 @@ func TestAuditSecurity_FullyHardened(t *testing.T) {
 		BindIP:               "127.0.0.1",
 		AuditLogEnabled:      true,
 		LocalhostAuthBypass:  false,
+		EncryptionAtRest:     true,
+		KMIPServer:           "kmip.example.com",
 	}
 	findings := AuditSecurity(info)
 	if len(findings) != 0 {
@@ -186,3 +190,115 @@ func TestAuditSecurity_BindIPv6All(t *testing.T) {
 		t.Error("expected BIND_ALL_INTERFACES for IPv6 all-interfaces (::)")
 	}
 }
+
+func externalAuthMessages(info mongoinspect.SecurityInfo) map[Severity][]string {
+	out := make(map[Severity][]string)
+	for _, f := range detectExternalAuthMisconfig(&info) {
+		out[f.Severity] = append(out[f.Severity], f.Message)
+	}
+	return out
+}
+
+func TestAuditSecurity_LDAPMisconfig(t *testing.T) {
+	info := mongoinspect.SecurityInfo{
+		AuthMechanisms: []string{"PLAIN", "GSSAPI"},
+		LDAP: &mongoinspect.LDAPConfig{
+			Servers:               "ldap.example.com",
+			TransportSecurity:     "none",
+			ValidateServerConfig:  false,
+			AuthzQueryTemplate:    "{USER}?memberOf?base",
+			CacheInvalidationSecs: 3600,
+		},
+	}
+	got := externalAuthMessages(info)
+	if len(got[SeverityHigh]) != 1 || !strings.Contains(got[SeverityHigh][0], "transportSecurity is none") {
+		t.Errorf("high = %v", got[SeverityHigh])
+	}
+	if len(got[SeverityMedium]) != 1 || !strings.Contains(got[SeverityMedium][0], "userToDNMapping") {
+		t.Errorf("medium = %v", got[SeverityMedium])
+	}
+	if len(got[SeverityLow]) != 2 {
+		t.Errorf("low = %v, want validateLDAPServerConfig and cache interval", got[SeverityLow])
+	}
+}
+
+func TestAuditSecurity_LDAPHealthy(t *testing.T) {
+	info := mongoinspect.SecurityInfo{
+		AuthMechanisms: []string{"PLAIN", "GSSAPI"},
+		LDAP: &mongoinspect.LDAPConfig{
+			Servers:               "ldap.example.com",
+			TransportSecurity:     "tls",
+			ValidateServerConfig:  true,
+			UserToDNMapping:       `[{match: "(.+)@EXAMPLE.COM", substitution: "cn={0},dc=example,dc=com"}]`,
+			AuthzQueryTemplate:    "{USER}?memberOf?base",
+			CacheInvalidationSecs: 30,
+		},
+	}
+	if got := detectExternalAuthMisconfig(&info); len(got) != 0 {
+		t.Errorf("expected no findings, got %+v", got)
+	}
+}
+
+func TestAuditSecurity_OIDCMisconfig(t *testing.T) {
+	info := mongoinspect.SecurityInfo{
+		AuthMechanisms: []string{"SCRAM-SHA-256", "MONGODB-OIDC"},
+		OIDCProviders: []mongoinspect.OIDCProvider{
+			{Issuer: "http://idp.internal", UseAuthorizationClaim: true},
+			{Issuer: "https://login.example.com", Audience: "mongodb", AuthorizationClaim: "groups", UseAuthorizationClaim: true},
+		},
+	}
+	got := externalAuthMessages(info)
+	if len(got[SeverityHigh]) != 1 || !strings.Contains(got[SeverityHigh][0], "does not use https") {
+		t.Errorf("high = %v", got[SeverityHigh])
+	}
+	if len(got[SeverityMedium]) != 2 {
+		t.Errorf("medium = %v, want missing audience and authorizationClaim", got[SeverityMedium])
+	}
+
+	info.OIDCProviders = nil
+	got = externalAuthMessages(info)
+	if len(got[SeverityMedium]) != 1 || !strings.Contains(got[SeverityMedium][0], "oidcIdentityProviders is empty") {
+		t.Errorf("medium = %v", got[SeverityMedium])
+	}
+}
+
+func TestAuditSecurity_NoEncryptionAtRest(t *testing.T) {
+	findings := detectNoEncryptionAtRest(&mongoinspect.SecurityInfo{CmdLineOpts: true})
+	if len(findings) != 1 || findings[0].Type != FindingNoEncryptionAtRest || findings[0].Severity != SeverityMedium {
+		t.Fatalf("expected NO_ENCRYPTION_AT_REST, got %+v", findings)
+	}
+	// getCmdLineOpts denied: the setting is unknown.
+	if findings := detectNoEncryptionAtRest(&mongoinspect.SecurityInfo{}); len(findings) != 0 {
+		t.Errorf("expected no finding without getCmdLineOpts, got %+v", findings)
+	}
+	if findings := detectNoEncryptionAtRest(&mongoinspect.SecurityInfo{CmdLineOpts: true, KMIPServer: "kmip.example.com"}); len(findings) != 0 {
+		t.Errorf("expected no finding with a KMIP server, got %+v", findings)
+	}
+}
+
+func TestAuditSecurity_StaleKMIPKey(t *testing.T) {
+	fixed := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	prev := now
+	now = func() time.Time { return fixed }
+	t.Cleanup(func() { now = prev })
+
+	info := mongoinspect.SecurityInfo{
+		EncryptionAtRest: true,
+		DataKeys: []mongoinspect.DataKeyInfo{
+			{ID: "aa01", AltNames: []string{"billing"}, Provider: "kmip", Created: fixed.AddDate(-3, 0, 0), Updated: fixed.AddDate(-2, 0, 0)},
+			{ID: "aa02", Provider: "kmip", Created: fixed.AddDate(-2, 0, 0), Updated: fixed.AddDate(0, -1, 0)},
+			{ID: "aa03", Provider: "kmip", Created: fixed.AddDate(0, 0, -400)},
+			{ID: "aa04", Provider: "aws", Created: fixed.AddDate(-5, 0, 0)},
+		},
+	}
+	findings := detectStaleKMIPKeys(&info)
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 STALE_KMIP_KEY findings, got %+v", findings)
+	}
+	if !strings.Contains(findings[0].Message, `"billing" was last rotated 730 days ago`) {
+		t.Errorf("message = %q", findings[0].Message)
+	}
+	if !strings.Contains(findings[1].Message, `"aa03"`) {
+		t.Errorf("message = %q", findings[1].Message)
+	}
+}
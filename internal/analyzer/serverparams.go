@@ -0,0 +1,106 @@
+package analyzer
+
+import (
+	"fmt"
+	"strconv"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+// ParamOp is how a server parameter is compared to its expected value.
+type ParamOp string
+
+const (
+	ParamEqual   ParamOp = "="
+	ParamAtMost  ParamOp = "<="
+	ParamAtLeast ParamOp = ">="
+)
+
+// ServerParamExpectation is one setting of a recommended server profile.
+type ServerParamExpectation struct {
+	// Key is a getParameter name or a getCmdLineOpts dotted path.
+	Key      string
+	Op       ParamOp
+	Expected string
+	// Default is the server's value when the setting is not reported.
+	// getCmdLineOpts only lists options that were set explicitly.
+	Default string
+	// CmdLine marks settings that come from getCmdLineOpts; they are
+	// skipped when it could not be read.
+	CmdLine  bool
+	Severity Severity
+	Reason   string
+}
+
+// ProductionProfile is the bundled set of server settings recommended for
+// production deployments.
+var ProductionProfile = []ServerParamExpectation{
+	{Key: "storage.journal.enabled", Op: ParamEqual, Expected: "true", Default: "true", CmdLine: true, Severity: SeverityHigh,
+		Reason: "without the journal, writes acknowledged since the last checkpoint are lost on a crash"},
+	{Key: "operationProfiling.slowOpThresholdMs", Op: ParamAtMost, Expected: "100", Default: "100", CmdLine: true, Severity: SeverityLow,
+		Reason: "slow operations below the threshold never reach the log"},
+	{Key: "systemLog.quiet", Op: ParamEqual, Expected: "false", Default: "false", CmdLine: true, Severity: SeverityMedium,
+		Reason: "quiet mode drops connection and command events needed for incident analysis"},
+	{Key: "logLevel", Op: ParamEqual, Expected: "0", Default: "0", Severity: SeverityLow,
+		Reason: "verbose logging adds I/O and fills disks"},
+	{Key: "diagnosticDataCollectionEnabled", Op: ParamEqual, Expected: "true", Default: "true", Severity: SeverityLow,
+		Reason: "FTDC data is needed to diagnose performance problems after the fact"},
+	{Key: "ttlMonitorEnabled", Op: ParamEqual, Expected: "true", Default: "true", Severity: SeverityMedium,
+		Reason: "TTL indexes stop deleting expired documents"},
+	{Key: "notablescan", Op: ParamEqual, Expected: "false", Default: "false", Severity: SeverityMedium,
+		Reason: "queries without a usable index fail instead of running"},
+}
+
+// AuditServerParams compares server settings with ProductionProfile and
+// returns SERVER_PARAM_DRIFT findings with the expected and actual values.
+func AuditServerParams(params mongoinspect.ServerParameters) []Finding {
+	var findings []Finding
+	for _, exp := range ProductionProfile {
+		if exp.CmdLine && !params.CmdLineOpts {
+			continue
+		}
+		actual, set := params.Values[exp.Key]
+		if !set {
+			actual = exp.Default
+		}
+		ok, comparable := exp.matches(actual)
+		if ok || !comparable {
+			continue
+		}
+		shown := actual
+		if !set {
+			shown += " (default)"
+		}
+		findings = append(findings, Finding{
+			Type:     FindingServerParamDrift,
+			Severity: exp.Severity,
+			Message: fmt.Sprintf("server parameter %s is %s, expected %s%s: %s",
+				exp.Key, shown, opPrefix(exp.Op), exp.Expected, exp.Reason),
+		})
+	}
+	return findings
+}
+
+// matches reports whether actual satisfies the expectation. Numeric
+// comparisons on non-numeric values are not comparable.
+func (e ServerParamExpectation) matches(actual string) (ok, comparable bool) {
+	if e.Op == ParamEqual {
+		return actual == e.Expected, true
+	}
+	a, errA := strconv.ParseFloat(actual, 64)
+	want, errW := strconv.ParseFloat(e.Expected, 64)
+	if errA != nil || errW != nil {
+		return false, false
+	}
+	if e.Op == ParamAtMost {
+		return a <= want, true
+	}
+	return a >= want, true
+}
+
+func opPrefix(op ParamOp) string {
+	if op == ParamEqual {
+		return ""
+	}
+	return string(op) + " "
+}
@@ -0,0 +1,76 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+func TestAuditServerParams_MatchingProfile(t *testing.T) {
+	params := mongoinspect.ServerParameters{
+		CmdLineOpts: true,
+		Values: map[string]string{
+			"storage.journal.enabled":              "true",
+			"operationProfiling.slowOpThresholdMs": "50",
+			"logLevel":                             "0",
+		},
+	}
+	if findings := AuditServerParams(params); len(findings) != 0 {
+		t.Fatalf("expected no findings, got %+v", findings)
+	}
+}
+
+func TestAuditServerParams_Drift(t *testing.T) {
+	params := mongoinspect.ServerParameters{
+		CmdLineOpts: true,
+		Values: map[string]string{
+			"storage.journal.enabled":              "false",
+			"operationProfiling.slowOpThresholdMs": "500",
+			"notablescan":                          "true",
+		},
+	}
+	findings := AuditServerParams(params)
+	if len(findings) != 3 {
+		t.Fatalf("expected 3 findings, got %+v", findings)
+	}
+	byKey := make(map[string]Finding)
+	for _, f := range findings {
+		if f.Type != FindingServerParamDrift {
+			t.Errorf("type = %s, want SERVER_PARAM_DRIFT", f.Type)
+		}
+		byKey[strings.Fields(f.Message)[2]] = f
+	}
+	if f := byKey["storage.journal.enabled"]; f.Severity != SeverityHigh || !strings.Contains(f.Message, "is false, expected true") {
+		t.Errorf("journal finding = %+v", f)
+	}
+	if f := byKey["operationProfiling.slowOpThresholdMs"]; !strings.Contains(f.Message, "is 500, expected <= 100") {
+		t.Errorf("slowms finding = %+v", f)
+	}
+	if f := byKey["notablescan"]; f.Severity != SeverityMedium {
+		t.Errorf("notablescan severity = %s, want medium", f.Severity)
+	}
+}
+
+func TestAuditServerParams_CmdLineUnavailable(t *testing.T) {
+	params := mongoinspect.ServerParameters{
+		Values: map[string]string{
+			"storage.journal.enabled": "false",
+			"logLevel":                "2",
+		},
+	}
+	findings := AuditServerParams(params)
+	if len(findings) != 1 || !strings.Contains(findings[0].Message, "logLevel is 2") {
+		t.Fatalf("expected only the logLevel finding, got %+v", findings)
+	}
+}
+
+func TestAuditServerParams_NonNumericValueSkipped(t *testing.T) {
+	params := mongoinspect.ServerParameters{
+		CmdLineOpts: true,
+		Values:      map[string]string{"operationProfiling.slowOpThresholdMs": "fast"},
+	}
+	if findings := AuditServerParams(params); len(findings) != 0 {
+		t.Fatalf("expected no findings, got %+v", findings)
+	}
+}
@@ -6,6 +6,18 @@ import (
 	"strings"
 
 	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+const (
+	// hashedSkewRatio is the chunk imbalance tolerated on hashed shard keys,
+	// which should spread chunks almost evenly.
+	hashedSkewRatio = 1.25
+	// hashedSkewMinChunks ignores skew of only a few chunks.
+	hashedSkewMinChunks int64 = 4
+	// shardCandidateSize is the storage size at which an unsharded collection
+	// referenced in code gets a shard key suggestion.
+	shardCandidateSize int64 = 1 << 30
 )
 
 // AuditSharding runs sharding-specific detections against inspected metadata.
@@ -22,6 +34,7 @@ func AuditSharding(collections []mongoinspect.CollectionInfo, sharding mongoinsp
 
 		findings = append(findings, detectMonotonicShardKey(&coll)...)
 		findings = append(findings, detectUnbalancedChunks(&coll, sharding.Shards)...)
+		findings = append(findings, detectHashedChunkSkew(&coll, sharding.Shards)...)
 		findings = append(findings, detectJumboChunks(&coll)...)
 	}
 
@@ -29,7 +42,7 @@ func AuditSharding(collections []mongoinspect.CollectionInfo, sharding mongoinsp
 		if coll.Type == "view" {
 			continue
 		}
-		if coll.StorageSize < oversizedThreshold {
+		if coll.StorageSize < thresholdsFor(coll.Database).OversizedCollection {
 			continue
 		}
 		key := coll.Database + "." + coll.Name
@@ -61,12 +74,10 @@ func AuditSharding(collections []mongoinspect.CollectionInfo, sharding mongoinsp
 }
 
 func detectMonotonicShardKey(coll *mongoinspect.ShardedCollectionInfo) []Finding {
-	if len(coll.Key) != 1 {
+	if len(coll.Key) != 1 || isHashedKey(coll.Key) {
 		return nil
 	}
-
-	field := strings.ToLower(coll.Key[0].Field)
-	if field != "_id" && field != "created_at" && field != "createdat" {
+	if !isMonotonicFieldName(coll.Key[0].Field) {
 		return nil
 	}
 
@@ -80,39 +91,72 @@ func detectMonotonicShardKey(coll *mongoinspect.ShardedCollectionInfo) []Finding
 }
 
 func detectUnbalancedChunks(coll *mongoinspect.ShardedCollectionInfo, shardNames []string) []Finding {
-	loads := shardLoads(coll, shardNames)
-	if len(loads) < 2 {
+	minLoad, maxLoad, ok := chunkExtremes(coll, shardNames)
+	if !ok || maxLoad.chunks == 0 {
+		return nil
+	}
+	if minLoad.chunks > 0 && maxLoad.chunks <= 2*minLoad.chunks {
 		return nil
 	}
 
-	minLoad := loads[0]
-	maxLoad := loads[0]
-	for _, load := range loads[1:] {
-		if load.chunks < minLoad.chunks {
-			minLoad = load
-		}
-		if load.chunks > maxLoad.chunks {
-			maxLoad = load
-		}
+	hint := ""
+	if isHashedKey(coll.Key) {
+		hint = "; a hashed shard key should spread chunks evenly, so the collection was likely sharded without pre-splitting (numInitialChunks)"
 	}
+	return []Finding{{
+		Type:       FindingUnbalancedChunks,
+		Severity:   SeverityHigh,
+		Database:   coll.Database,
+		Collection: coll.Collection,
+		Message: fmt.Sprintf("chunk distribution is unbalanced: %s has %d chunks, %s has %d%s%s",
+			maxLoad.name, maxLoad.chunks, minLoad.name, minLoad.chunks, sampledSuffix(coll.ChunkLimitHit), hint),
+	}}
+}
 
-	if maxLoad.chunks == 0 {
+// detectHashedChunkSkew flags hashed shard keys with a moderate chunk
+// imbalance that detectUnbalancedChunks tolerates for ranged keys. Hashing
+// distributes values uniformly, so a persistent skew points at balancer
+// problems or a collection that was never pre-split.
+func detectHashedChunkSkew(coll *mongoinspect.ShardedCollectionInfo, shardNames []string) []Finding {
+	if !isHashedKey(coll.Key) {
 		return nil
 	}
-	if minLoad.chunks > 0 && maxLoad.chunks <= 2*minLoad.chunks {
+	minLoad, maxLoad, ok := chunkExtremes(coll, shardNames)
+	if !ok || minLoad.chunks == 0 || maxLoad.chunks > 2*minLoad.chunks {
+		return nil // reported by detectUnbalancedChunks
+	}
+	if maxLoad.chunks-minLoad.chunks < hashedSkewMinChunks || float64(maxLoad.chunks) <= hashedSkewRatio*float64(minLoad.chunks) {
 		return nil
 	}
-
 	return []Finding{{
 		Type:       FindingUnbalancedChunks,
-		Severity:   SeverityHigh,
+		Severity:   SeverityMedium,
 		Database:   coll.Database,
 		Collection: coll.Collection,
-		Message: fmt.Sprintf("chunk distribution is unbalanced: %s has %d chunks, %s has %d%s",
-			maxLoad.name, maxLoad.chunks, minLoad.name, minLoad.chunks, sampledSuffix(coll.ChunkLimitHit)),
+		Message: fmt.Sprintf("hashed shard key %s has skewed chunks: %s has %d, %s has %d%s; check the balancer window and jumbo chunks",
+			formatShardKey(coll.Key), maxLoad.name, maxLoad.chunks, minLoad.name, minLoad.chunks, sampledSuffix(coll.ChunkLimitHit)),
 	}}
 }
 
+// chunkExtremes returns the shards holding the fewest and most chunks, or
+// false when fewer than two shards are known.
+func chunkExtremes(coll *mongoinspect.ShardedCollectionInfo, shardNames []string) (minLoad, maxLoad shardLoad, ok bool) {
+	loads := shardLoads(coll, shardNames)
+	if len(loads) < 2 {
+		return shardLoad{}, shardLoad{}, false
+	}
+	minLoad, maxLoad = loads[0], loads[0]
+	for _, load := range loads[1:] {
+		if load.chunks < minLoad.chunks {
+			minLoad = load
+		}
+		if load.chunks > maxLoad.chunks {
+			maxLoad = load
+		}
+	}
+	return minLoad, maxLoad, true
+}
+
 func detectJumboChunks(coll *mongoinspect.ShardedCollectionInfo) []Finding {
 	if coll.JumboChunks == 0 {
 		return nil
@@ -153,11 +197,132 @@ func shardLoads(coll *mongoinspect.ShardedCollectionInfo, shardNames []string) [
 func formatShardKey(key []mongoinspect.KeyField) string {
 	parts := make([]string, 0, len(key))
 	for _, kf := range key {
-		parts = append(parts, fmt.Sprintf("%s:%d", kf.Field, kf.Direction))
+		parts = append(parts, formatKeyField(kf))
 	}
 	return "{" + strings.Join(parts, ", ") + "}"
 }
 
+// isHashedKey reports whether any element of a shard key is hashed.
+func isHashedKey(key []mongoinspect.KeyField) bool {
+	for _, kf := range key {
+		if kf.Kind == "hashed" {
+			return true
+		}
+	}
+	return false
+}
+
+// isMonotonicFieldName reports whether a field name suggests values that only
+// grow, such as ObjectIds or creation timestamps.
+func isMonotonicFieldName(field string) bool {
+	switch strings.ToLower(field) {
+	case "_id", "created_at", "createdat", "timestamp", "ts":
+		return true
+	}
+	return false
+}
+
+// SuggestShardKeys returns SHARDING_CANDIDATE findings for unsharded
+// collections on a sharded cluster that code references and that have grown
+// past shardCandidateSize. The suggested key is the field code queries most:
+// hashed when its values grow monotonically (ObjectId, dates) or are only
+// matched by equality, ranged when code runs range queries or sorts on it.
+// Samples, when available, supply the field's BSON type.
+func SuggestShardKeys(scan *scanner.ScanResult, collections []mongoinspect.CollectionInfo, sharding mongoinspect.ShardingInfo, samples []mongoinspect.FieldSampleResult) []Finding {
+	if scan == nil || !sharding.Enabled {
+		return nil
+	}
+	sharded := make(map[string]bool, len(sharding.Collections))
+	for _, sc := range sharding.Collections {
+		sharded[sc.Database+"."+sc.Collection] = true
+	}
+
+	var findings []Finding
+	for _, name := range scan.Collections {
+		coll, found := findCollection(name, collections)
+		if !found || coll.Type == "view" || sharded[coll.Database+"."+coll.Name] || coll.StorageSize < shardCandidateSize {
+			continue
+		}
+		field, eq, ranged := shardKeyCandidateField(scan.FieldRefs, coll.Name)
+		bsonType := sampledFieldType(samples, coll.Database, coll.Name, field)
+
+		var key, why string
+		switch {
+		case isMonotonicFieldName(field) || bsonType == "objectId" || bsonType == "date":
+			key = fmt.Sprintf("{%s: \"hashed\"}", field)
+			why = fmt.Sprintf("%q increases monotonically, so a ranged key would send every insert to one shard", field)
+			if ranged > 0 {
+				why += fmt.Sprintf("; its %d range/sort queries will become scatter-gather", ranged)
+			}
+		case ranged > 0:
+			key = fmt.Sprintf("{%s: 1}", field)
+			why = fmt.Sprintf("code runs %d range/sort queries on %q, which a ranged key keeps targeted", ranged, field)
+		default:
+			key = fmt.Sprintf("{%s: \"hashed\"}", field)
+			why = fmt.Sprintf("code matches %q by equality only (%d queries), so hashing spreads writes without losing targeting", field, eq)
+		}
+		if bsonType != "" {
+			why += fmt.Sprintf(" (sampled type %s)", bsonType)
+		}
+		findings = append(findings, Finding{
+			Type:       FindingShardingCandidate,
+			Severity:   SeverityInfo,
+			Database:   coll.Database,
+			Collection: coll.Name,
+			Message: fmt.Sprintf("collection is %s and not sharded; suggested shard key %s: %s",
+				formatBytes(coll.StorageSize), key, why),
+		})
+	}
+	return findings
+}
+
+// shardKeyCandidateField returns the field code queries most on a collection
+// with its equality and range/sort query counts, defaulting to _id.
+func shardKeyCandidateField(refs []scanner.FieldRef, collection string) (field string, eq, ranged int) {
+	type counts struct{ eq, ranged int }
+	byField := make(map[string]*counts)
+	for _, ref := range refs {
+		if ref.Field == "" || !strings.EqualFold(ref.Collection, collection) || !isQueryableUsage(ref.Usage) {
+			continue
+		}
+		c := byField[ref.Field]
+		if c == nil {
+			c = &counts{}
+			byField[ref.Field] = c
+		}
+		switch ref.Usage {
+		case scanner.FieldUsageRange, scanner.FieldUsageSort:
+			c.ranged++
+		default:
+			c.eq++
+		}
+	}
+	field = "_id"
+	best := -1
+	for f, c := range byField {
+		total := c.eq + c.ranged
+		if total > best || (total == best && f < field) {
+			field, eq, ranged, best = f, c.eq, c.ranged, total
+		}
+	}
+	return field, eq, ranged
+}
+
+// sampledFieldType returns the most frequent BSON type sampled for a field.
+func sampledFieldType(samples []mongoinspect.FieldSampleResult, db, coll, field string) string {
+	f, _, ok := sampledField(samples, db, coll, field)
+	if !ok {
+		return ""
+	}
+	var best string
+	for _, t := range sortedTypeNames(f.Types) {
+		if best == "" || f.Types[t] > f.Types[best] {
+			best = t
+		}
+	}
+	return best
+}
+
 func sampledSuffix(chunkLimitHit bool) string {
 	if !chunkLimitHit {
 		return ""
@@ -1,9 +1,11 @@
 package analyzer
 
 import (
+	"strings"
 	"testing"
 
 	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
 )
 
 func TestAuditSharding_Detections(t *testing.T) {
@@ -96,3 +98,108 @@ func hasFindingType(findings []Finding, want FindingType) bool {
 	}
 	return false
 }
+
+func TestAuditSharding_HashedKey(t *testing.T) {
+	hashedID := []mongoinspect.KeyField{{Field: "_id", Kind: "hashed"}}
+	sharding := mongoinspect.ShardingInfo{
+		Enabled:         true,
+		BalancerEnabled: true,
+		Shards:          []string{"shard-a", "shard-b"},
+		Collections: []mongoinspect.ShardedCollectionInfo{
+			{
+				Database:          "app",
+				Collection:        "skewed",
+				Key:               hashedID,
+				ChunkDistribution: map[string]int64{"shard-a": 30, "shard-b": 20},
+			},
+			{
+				Database:          "app",
+				Collection:        "even",
+				Key:               hashedID,
+				ChunkDistribution: map[string]int64{"shard-a": 21, "shard-b": 20},
+			},
+			{
+				Database:          "app",
+				Collection:        "unsplit",
+				Key:               hashedID,
+				ChunkDistribution: map[string]int64{"shard-a": 1},
+			},
+		},
+	}
+
+	findings := AuditSharding(nil, sharding)
+	if hasFindingType(findings, FindingMonotonicShardKey) {
+		t.Fatalf("hashed _id should not be reported as monotonic: %+v", findings)
+	}
+	got := make(map[string]Finding)
+	for _, f := range findings {
+		got[f.Collection] = f
+	}
+	if f, ok := got["skewed"]; !ok || f.Severity != SeverityMedium || !strings.Contains(f.Message, "hashed shard key") {
+		t.Errorf("expected medium hashed skew on skewed, got %+v", f)
+	}
+	if f, ok := got["even"]; ok {
+		t.Errorf("even distribution should not be flagged, got %+v", f)
+	}
+	if f, ok := got["unsplit"]; !ok || f.Severity != SeverityHigh || !strings.Contains(f.Message, "numInitialChunks") {
+		t.Errorf("expected high UNBALANCED_CHUNKS with pre-split hint on unsplit, got %+v", f)
+	}
+}
+
+func TestSuggestShardKeys(t *testing.T) {
+	scan := &scanner.ScanResult{
+		Collections: []string{"orders", "events", "tiny", "sharded"},
+		FieldRefs: []scanner.FieldRef{
+			{Collection: "orders", Field: "customer_id", Usage: scanner.FieldUsageEquality},
+			{Collection: "orders", Field: "customer_id", Usage: scanner.FieldUsageEquality},
+			{Collection: "orders", Field: "status", Usage: scanner.FieldUsageEquality},
+			{Collection: "events", Field: "occurred", Usage: scanner.FieldUsageRange},
+			{Collection: "events", Field: "occurred", Usage: scanner.FieldUsageSort},
+		},
+	}
+	collections := []mongoinspect.CollectionInfo{
+		{Database: "app", Name: "orders", StorageSize: 2 << 30},
+		{Database: "app", Name: "events", StorageSize: 3 << 30},
+		{Database: "app", Name: "tiny", StorageSize: 1 << 20},
+		{Database: "app", Name: "sharded", StorageSize: 5 << 30},
+	}
+	sharding := mongoinspect.ShardingInfo{
+		Enabled:     true,
+		Collections: []mongoinspect.ShardedCollectionInfo{{Database: "app", Collection: "sharded"}},
+	}
+
+	findings := SuggestShardKeys(scan, collections, sharding, nil)
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 findings, got %+v", findings)
+	}
+	byColl := make(map[string]string)
+	for _, f := range findings {
+		if f.Type != FindingShardingCandidate || f.Severity != SeverityInfo {
+			t.Errorf("unexpected finding %+v", f)
+		}
+		byColl[f.Collection] = f.Message
+	}
+	if !strings.Contains(byColl["orders"], `{customer_id: "hashed"}`) {
+		t.Errorf("orders message = %q", byColl["orders"])
+	}
+	if !strings.Contains(byColl["events"], "{occurred: 1}") {
+		t.Errorf("events message = %q", byColl["events"])
+	}
+
+	// A sampled date type makes the ranged field monotonic.
+	samples := []mongoinspect.FieldSampleResult{{
+		Database:   "app",
+		Collection: "events",
+		Fields:     []mongoinspect.FieldFrequency{{Path: "occurred", Count: 10, Types: map[string]int64{"date": 10}}},
+	}}
+	findings = SuggestShardKeys(scan, collections, sharding, samples)
+	for _, f := range findings {
+		if f.Collection == "events" && (!strings.Contains(f.Message, `{occurred: "hashed"}`) || !strings.Contains(f.Message, "scatter-gather")) {
+			t.Errorf("events message with date sample = %q", f.Message)
+		}
+	}
+
+	if got := SuggestShardKeys(scan, collections, mongoinspect.ShardingInfo{}, nil); len(got) != 0 {
+		t.Errorf("unsharded deployment should not get suggestions, got %+v", got)
+	}
+}
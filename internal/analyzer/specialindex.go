@@ -0,0 +1,213 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+const (
+	textIndexCostRatio        = 0.5       // text index size relative to data size worth reviewing
+	textIndexLowUseOps        = 1000      // text indexes used fewer times than this are rarely used
+	textIndexMinSize    int64 = 100 << 20 // ignore text indexes smaller than 100 MB
+	wildcardMinSize     int64 = 100 << 20 // ignore wildcard indexes smaller than 100 MB
+	textIndexKeyField         = "_fts"
+	wildcardKey               = "$**"
+	maxConflictExamples       = 3
+)
+
+// isWildcardIndex reports whether idx has a $** key.
+func isWildcardIndex(idx *mongoinspect.IndexInfo) bool {
+	for _, kf := range idx.Key {
+		if kf.Field == wildcardKey || strings.HasSuffix(kf.Field, "."+wildcardKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// isTextIndex reports whether idx is a text index. listIndexes reports text
+// keys as _fts/_ftsx rather than the indexed fields.
+func isTextIndex(idx *mongoinspect.IndexInfo) bool {
+	for _, kf := range idx.Key {
+		if kf.Field == textIndexKeyField {
+			return true
+		}
+	}
+	return len(idx.TextFields) > 0
+}
+
+// dataSize is the on-disk size of the collection, falling back to the
+// uncompressed data size when storage size is not reported.
+func dataSize(c *mongoinspect.CollectionInfo) int64 {
+	if c.StorageSize > 0 {
+		return c.StorageSize
+	}
+	return c.Size
+}
+
+// indexOps formats an index's usage count for messages.
+func indexOps(idx *mongoinspect.IndexInfo) string {
+	if idx.Stats == nil {
+		return "usage unknown"
+	}
+	return fmt.Sprintf("%d ops since restart", idx.Stats.Ops)
+}
+
+// detectWildcardIndexBloat flags $** indexes at least as large as the
+// collection data. A wildcard index over every path indexes every value of
+// every document, so it can outgrow the data it covers.
+func detectWildcardIndexBloat(c *mongoinspect.CollectionInfo) []Finding {
+	data := dataSize(c)
+	var findings []Finding
+	for i := range c.Indexes {
+		idx := &c.Indexes[i]
+		if !isWildcardIndex(idx) || idx.Size < wildcardMinSize || data == 0 || idx.Size < data {
+			continue
+		}
+		advice := "scope it with a wildcardProjection listing only the queried paths"
+		if idx.WildcardProjection != "" {
+			advice = fmt.Sprintf("narrow its wildcardProjection %s", idx.WildcardProjection)
+		}
+		findings = append(findings, Finding{
+			Type:       FindingWildcardIndexBloat,
+			Severity:   SeverityMedium,
+			Database:   c.Database,
+			Collection: c.Name,
+			Index:      idx.Name,
+			Message: fmt.Sprintf("wildcard index is %s, %.1fx the collection data (%s; %s); %s",
+				formatBytes(idx.Size), float64(idx.Size)/float64(data), formatBytes(data), indexOps(idx), advice),
+		})
+	}
+	return findings
+}
+
+// detectTextIndexCost flags large text indexes that are rarely used. Text
+// indexes store one entry per distinct stemmed term per document and slow
+// down every write to the covered fields.
+func detectTextIndexCost(c *mongoinspect.CollectionInfo) []Finding {
+	data := dataSize(c)
+	var findings []Finding
+	for i := range c.Indexes {
+		idx := &c.Indexes[i]
+		if !isTextIndex(idx) || idx.Size < textIndexMinSize || data == 0 {
+			continue
+		}
+		if float64(idx.Size) < textIndexCostRatio*float64(data) {
+			continue
+		}
+		if idx.Stats == nil || idx.Stats.Ops >= textIndexLowUseOps {
+			continue
+		}
+		fields := ""
+		if len(idx.TextFields) > 0 {
+			fields = fmt.Sprintf(" over %s", strings.Join(quoteAll(idx.TextFields), ", "))
+		}
+		findings = append(findings, Finding{
+			Type:       FindingTextIndexCost,
+			Severity:   SeverityLow,
+			Database:   c.Database,
+			Collection: c.Name,
+			Index:      idx.Name,
+			Message: fmt.Sprintf("text index%s is %s (%.0f%% of %s data) but has only %s; consider Atlas Search or dropping it",
+				fields, formatBytes(idx.Size), 100*float64(idx.Size)/float64(data), formatBytes(data), indexOps(idx)),
+		})
+	}
+	return findings
+}
+
+// detectTextIndexConflicts flags collections where code creates text indexes
+// that cannot coexist: MongoDB allows one text index per collection, so a
+// second createIndex with different text fields fails at runtime.
+func detectTextIndexConflicts(scan *scanner.ScanResult, collections []mongoinspect.CollectionInfo) []Finding {
+	if scan == nil {
+		return nil
+	}
+	byCollection := make(map[string][]scanner.IndexRef)
+	for _, ref := range scan.IndexRefs {
+		if len(ref.TextFields) == 0 || ref.Collection == "" {
+			continue
+		}
+		key := strings.ToLower(ref.Collection)
+		byCollection[key] = append(byCollection[key], ref)
+	}
+	names := make([]string, 0, len(byCollection))
+	for name := range byCollection {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var findings []Finding
+	for _, name := range names {
+		refs := byCollection[name]
+		// Group definitions by their sorted text field set.
+		defs := make(map[string][]string)
+		var sigs []string
+		for _, ref := range refs {
+			sig := textFieldSignature(ref.TextFields)
+			if _, ok := defs[sig]; !ok {
+				sigs = append(sigs, sig)
+			}
+			defs[sig] = append(defs[sig], fmt.Sprintf("%s:%d", ref.File, ref.Line))
+		}
+
+		coll, found := findCollection(name, collections)
+		db, collName := "", refs[0].Collection
+		if found {
+			db, collName = coll.Database, coll.Name
+		}
+
+		if len(sigs) > 1 {
+			parts := make([]string, 0, len(sigs))
+			for i, sig := range sigs {
+				if i == maxConflictExamples {
+					parts = append(parts, fmt.Sprintf("%d more", len(sigs)-i))
+					break
+				}
+				parts = append(parts, fmt.Sprintf("{%s} at %s", sig, strings.Join(defs[sig], ", ")))
+			}
+			findings = append(findings, Finding{
+				Type:       FindingTextIndexConflict,
+				Severity:   SeverityMedium,
+				Database:   db,
+				Collection: collName,
+				Message: fmt.Sprintf("code defines %d different text indexes but a collection can have only one: %s",
+					len(sigs), strings.Join(parts, "; ")),
+			})
+			continue
+		}
+
+		if !found {
+			continue
+		}
+		for i := range coll.Indexes {
+			idx := &coll.Indexes[i]
+			if !isTextIndex(idx) || len(idx.TextFields) == 0 {
+				continue
+			}
+			if live := strings.Join(idx.TextFields, ", "); live != sigs[0] {
+				findings = append(findings, Finding{
+					Type:       FindingTextIndexConflict,
+					Severity:   SeverityMedium,
+					Database:   coll.Database,
+					Collection: coll.Name,
+					Index:      idx.Name,
+					Message: fmt.Sprintf("code creates a text index on {%s} (%s) but the existing text index covers {%s}; createIndex will fail until %q is dropped",
+						sigs[0], strings.Join(defs[sigs[0]], ", "), live, idx.Name),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// textFieldSignature returns the sorted, comma-joined field list of a text
+// index definition.
+func textFieldSignature(fields []string) string {
+	sorted := append([]string(nil), fields...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ", ")
+}
@@ -0,0 +1,102 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+func TestDetectWildcardIndexBloat(t *testing.T) {
+	c := &mongoinspect.CollectionInfo{
+		Database:    "app",
+		Name:        "events",
+		StorageSize: 200 << 20,
+		Indexes: []mongoinspect.IndexInfo{
+			{Name: "_id_", Key: kf("_id"), Size: 10 << 20},
+			{Name: "$**_1", Key: kf("$**"), Size: 400 << 20, Stats: &mongoinspect.IndexStats{Ops: 12}},
+		},
+	}
+	findings := detectWildcardIndexBloat(c)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %v", findings)
+	}
+	f := findings[0]
+	if f.Type != FindingWildcardIndexBloat || f.Severity != SeverityMedium || f.Index != "$**_1" {
+		t.Errorf("unexpected finding %+v", f)
+	}
+	if !strings.Contains(f.Message, "2.0x") || !strings.Contains(f.Message, "12 ops") || !strings.Contains(f.Message, "scope it with a wildcardProjection") {
+		t.Errorf("message = %q", f.Message)
+	}
+
+	c.Indexes[1].WildcardProjection = `{"attrs":1}`
+	if f := detectWildcardIndexBloat(c); len(f) != 1 || !strings.Contains(f[0].Message, `narrow its wildcardProjection {"attrs":1}`) {
+		t.Errorf("expected narrowing advice, got %v", f)
+	}
+
+	c.Indexes[1].Size = 150 << 20
+	if f := detectWildcardIndexBloat(c); len(f) != 0 {
+		t.Errorf("index smaller than data should not be flagged, got %v", f)
+	}
+}
+
+func TestDetectTextIndexCost(t *testing.T) {
+	text := mongoinspect.IndexInfo{
+		Name:       "title_text",
+		Key:        []mongoinspect.KeyField{{Field: "_fts"}, {Field: "_ftsx", Direction: 1}},
+		TextFields: []string{"body", "title"},
+		Size:       300 << 20,
+		Stats:      &mongoinspect.IndexStats{Ops: 5},
+	}
+	c := &mongoinspect.CollectionInfo{Database: "app", Name: "articles", StorageSize: 400 << 20, Indexes: []mongoinspect.IndexInfo{text}}
+
+	findings := detectTextIndexCost(c)
+	if len(findings) != 1 || findings[0].Type != FindingTextIndexCost || findings[0].Severity != SeverityLow {
+		t.Fatalf("expected TEXT_INDEX_COST, got %v", findings)
+	}
+	if !strings.Contains(findings[0].Message, `over "body", "title"`) || !strings.Contains(findings[0].Message, "75%") {
+		t.Errorf("message = %q", findings[0].Message)
+	}
+
+	c.Indexes[0].Stats.Ops = 50_000
+	if f := detectTextIndexCost(c); len(f) != 0 {
+		t.Errorf("busy text index should not be flagged, got %v", f)
+	}
+}
+
+func TestDetectTextIndexConflicts_CodeDefinitions(t *testing.T) {
+	scan := &scanner.ScanResult{IndexRefs: []scanner.IndexRef{
+		{Collection: "articles", Fields: []string{"title"}, TextFields: []string{"title"}, File: "a.js", Line: 3},
+		{Collection: "articles", Fields: []string{"title", "body"}, TextFields: []string{"title", "body"}, File: "b.js", Line: 9},
+	}}
+	findings := detectTextIndexConflicts(scan, nil)
+	if len(findings) != 1 || findings[0].Type != FindingTextIndexConflict {
+		t.Fatalf("expected TEXT_INDEX_CONFLICT, got %v", findings)
+	}
+	if !strings.Contains(findings[0].Message, "2 different text indexes") || !strings.Contains(findings[0].Message, "b.js:9") {
+		t.Errorf("message = %q", findings[0].Message)
+	}
+}
+
+func TestDetectTextIndexConflicts_LiveIndex(t *testing.T) {
+	scan := &scanner.ScanResult{IndexRefs: []scanner.IndexRef{
+		{Collection: "articles", Fields: []string{"title", "body"}, TextFields: []string{"title", "body"}, File: "a.js", Line: 3},
+	}}
+	collections := []mongoinspect.CollectionInfo{{
+		Database: "app",
+		Name:     "articles",
+		Indexes: []mongoinspect.IndexInfo{
+			{Name: "title_text", Key: []mongoinspect.KeyField{{Field: "_fts"}, {Field: "_ftsx", Direction: 1}}, TextFields: []string{"title"}},
+		},
+	}}
+	findings := detectTextIndexConflicts(scan, collections)
+	if len(findings) != 1 || findings[0].Index != "title_text" || findings[0].Database != "app" {
+		t.Fatalf("expected conflict with live index, got %v", findings)
+	}
+
+	collections[0].Indexes[0].TextFields = []string{"body", "title"}
+	if f := detectTextIndexConflicts(scan, collections); len(f) != 0 {
+		t.Errorf("matching text index should not conflict, got %v", f)
+	}
+}
@@ -0,0 +1,169 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+// tenantExampleLimit caps how many member collections a collapsed finding names.
+const tenantExampleLimit = 3
+
+// tenantPlaceholderRe matches the {name} placeholder in a collection pattern.
+var tenantPlaceholderRe = regexp.MustCompile(`\{[A-Za-z0-9_]*\}`)
+
+// CollectionPattern groups per-tenant collections such as events_acme and
+// events_globex under one logical name like events_{tenant}.
+type CollectionPattern struct {
+	Pattern string
+	re      *regexp.Regexp
+}
+
+// CompileCollectionPatterns compiles collection name patterns. Each pattern
+// must contain exactly one {placeholder}, which matches one or more
+// characters other than a dot.
+func CompileCollectionPatterns(patterns []string) ([]CollectionPattern, error) {
+	compiled := make([]CollectionPattern, 0, len(patterns))
+	for _, p := range patterns {
+		locs := tenantPlaceholderRe.FindAllStringIndex(p, -1)
+		if len(locs) != 1 {
+			return nil, fmt.Errorf("pattern %q: want exactly one {placeholder}", p)
+		}
+		loc := locs[0]
+		expr := "^" + regexp.QuoteMeta(p[:loc[0]]) + `[^.]+` + regexp.QuoteMeta(p[loc[1]:]) + "$"
+		compiled = append(compiled, CollectionPattern{Pattern: p, re: regexp.MustCompile(expr)})
+	}
+	return compiled, nil
+}
+
+// MatchesCollectionPattern reports whether f is on a collection that
+// CollapseTenantFindings would fold into a pattern.
+func MatchesCollectionPattern(f *Finding, patterns []CollectionPattern) bool {
+	if f.Collection == "" {
+		return false
+	}
+	_, ok := matchPattern(f.Collection, patterns)
+	return ok
+}
+
+// matchPattern returns the first pattern matching a collection name.
+func matchPattern(name string, patterns []CollectionPattern) (string, bool) {
+	for _, p := range patterns {
+		if p.re.MatchString(name) {
+			return p.Pattern, true
+		}
+	}
+	return "", false
+}
+
+// TenantGroup is the aggregate of all collections in a database matching one
+// pattern.
+type TenantGroup struct {
+	Database       string `json:"database"`
+	Pattern        string `json:"pattern"`
+	Collections    int    `json:"collections"`
+	DocCount       int64  `json:"docCount"`
+	Size           int64  `json:"size"`
+	StorageSize    int64  `json:"storageSize"`
+	TotalIndexSize int64  `json:"totalIndexSize"`
+}
+
+// AggregateTenantGroups sums collection stats per database and pattern.
+// Groups are sorted by database, then pattern.
+func AggregateTenantGroups(collections []mongoinspect.CollectionInfo, patterns []CollectionPattern) []TenantGroup {
+	byKey := make(map[string]*TenantGroup)
+	for i := range collections {
+		c := &collections[i]
+		pattern, ok := matchPattern(c.Name, patterns)
+		if !ok {
+			continue
+		}
+		key := c.Database + "." + pattern
+		g := byKey[key]
+		if g == nil {
+			g = &TenantGroup{Database: c.Database, Pattern: pattern}
+			byKey[key] = g
+		}
+		g.Collections++
+		g.DocCount += c.DocCount
+		g.Size += c.Size
+		g.StorageSize += c.StorageSize
+		g.TotalIndexSize += c.TotalIndexSize
+	}
+
+	groups := make([]TenantGroup, 0, len(byKey))
+	for _, g := range byKey {
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Database != groups[j].Database {
+			return groups[i].Database < groups[j].Database
+		}
+		return groups[i].Pattern < groups[j].Pattern
+	})
+	return groups
+}
+
+// CollapseTenantFindings reports each finding type once per pattern instead
+// of once per matching collection. A collapsed finding is attributed to the
+// pattern name and keeps the message of its first member (by collection
+// name) with the member count and a few example collections appended.
+// Findings on different indexes stay separate.
+// Findings on collections matching no pattern are returned unchanged, in
+// their original order.
+func CollapseTenantFindings(findings []Finding, patterns []CollectionPattern) []Finding {
+	if len(patterns) == 0 {
+		return findings
+	}
+
+	type group struct {
+		finding Finding
+		pattern string
+		first   string
+		members map[string]bool
+		pos     int
+	}
+	groups := make(map[string]*group)
+	out := make([]Finding, 0, len(findings))
+	for _, f := range findings {
+		pattern, ok := matchPattern(f.Collection, patterns)
+		if f.Collection == "" || !ok {
+			out = append(out, f)
+			continue
+		}
+		key := strings.Join([]string{f.Database, pattern, string(f.Type), string(f.Severity), f.Index}, "\x00")
+		g := groups[key]
+		if g == nil {
+			g = &group{finding: f, pattern: pattern, first: f.Collection, members: make(map[string]bool), pos: len(out)}
+			groups[key] = g
+			out = append(out, f)
+		} else if f.Collection < g.first {
+			g.finding.Message = f.Message
+			g.first = f.Collection
+		}
+		g.members[f.Collection] = true
+	}
+
+	for _, g := range groups {
+		f := g.finding
+		f.Collection = g.pattern
+		if len(g.members) > 1 {
+			names := make([]string, 0, len(g.members))
+			for name := range g.members {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			examples := names
+			if len(examples) > tenantExampleLimit {
+				examples = examples[:tenantExampleLimit]
+			}
+			f.Message = fmt.Sprintf("%s (%d collections matching %s, e.g. %s)",
+				f.Message, len(names), f.Collection, strings.Join(examples, ", "))
+		}
+		out[g.pos] = f
+	}
+	return out
+}
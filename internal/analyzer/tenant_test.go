@@ -0,0 +1,101 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+func mustPatterns(t *testing.T, patterns ...string) []CollectionPattern {
+	t.Helper()
+	compiled, err := CompileCollectionPatterns(patterns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return compiled
+}
+
+func TestCompileCollectionPatterns_Invalid(t *testing.T) {
+	for _, p := range []string{"events", "events_{a}_{b}"} {
+		if _, err := CompileCollectionPatterns([]string{p}); err == nil {
+			t.Errorf("%q: expected error", p)
+		}
+	}
+}
+
+func TestCompileCollectionPatterns_Matching(t *testing.T) {
+	patterns := mustPatterns(t, "events_{tenant}", "{org}.audit")
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"events_acme", "events_{tenant}"},
+		{"events_", ""},
+		{"events_acme.archive", ""},
+		{"acme.audit", "{org}.audit"},
+		{"orders", ""},
+	}
+	for _, tt := range tests {
+		got, _ := matchPattern(tt.name, patterns)
+		if got != tt.want {
+			t.Errorf("%s: pattern = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAggregateTenantGroups(t *testing.T) {
+	collections := []mongoinspect.CollectionInfo{
+		{Database: "app", Name: "events_a", DocCount: 10, Size: 100, TotalIndexSize: 10},
+		{Database: "app", Name: "events_b", DocCount: 5, Size: 50, TotalIndexSize: 5},
+		{Database: "other", Name: "events_a", DocCount: 1, Size: 1},
+		{Database: "app", Name: "users", DocCount: 99},
+	}
+	groups := AggregateTenantGroups(collections, mustPatterns(t, "events_{tenant}"))
+	if len(groups) != 2 {
+		t.Fatalf("groups = %+v, want 2", groups)
+	}
+	g := groups[0]
+	if g.Database != "app" || g.Collections != 2 || g.DocCount != 15 || g.Size != 150 || g.TotalIndexSize != 15 {
+		t.Errorf("app group = %+v", g)
+	}
+	if groups[1].Database != "other" || groups[1].Collections != 1 {
+		t.Errorf("other group = %+v", groups[1])
+	}
+}
+
+func TestCollapseTenantFindings(t *testing.T) {
+	findings := []Finding{
+		{Type: FindingUnusedIndex, Severity: SeverityMedium, Database: "app", Collection: "events_c", Index: "ts_1", Message: "c unused"},
+		{Type: FindingMissingIndex, Severity: SeverityHigh, Database: "app", Collection: "users", Message: "users"},
+		{Type: FindingUnusedIndex, Severity: SeverityMedium, Database: "app", Collection: "events_a", Index: "ts_1", Message: "a unused"},
+		{Type: FindingUnusedIndex, Severity: SeverityMedium, Database: "app", Collection: "events_b", Index: "ts_1", Message: "b unused"},
+		{Type: FindingUnusedIndex, Severity: SeverityMedium, Database: "app", Collection: "events_d", Index: "ts_1", Message: "d unused"},
+		{Type: FindingUnusedIndex, Severity: SeverityMedium, Database: "app", Collection: "events_a", Index: "kind_1", Message: "a kind"},
+	}
+	got := CollapseTenantFindings(findings, mustPatterns(t, "events_{tenant}"))
+	if len(got) != 3 {
+		t.Fatalf("findings = %+v, want 3", got)
+	}
+
+	ts := got[0]
+	if ts.Collection != "events_{tenant}" || ts.Index != "ts_1" {
+		t.Errorf("collapsed = %+v", ts)
+	}
+	if !strings.HasPrefix(ts.Message, "a unused (4 collections matching events_{tenant}, e.g. events_a, events_b, events_c)") {
+		t.Errorf("message = %q", ts.Message)
+	}
+	if got[1].Collection != "users" {
+		t.Errorf("unmatched finding moved: %+v", got[1])
+	}
+	if got[2].Index != "kind_1" || got[2].Message != "a kind" {
+		t.Errorf("single-member finding = %+v", got[2])
+	}
+}
+
+func TestCollapseTenantFindings_NoPatterns(t *testing.T) {
+	findings := []Finding{{Type: FindingUnusedIndex, Collection: "events_a"}}
+	if got := CollapseTenantFindings(findings, nil); len(got) != 1 || got[0].Collection != "events_a" {
+		t.Errorf("findings changed without patterns: %+v", got)
+	}
+}
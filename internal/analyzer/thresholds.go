@@ -0,0 +1,71 @@
+package analyzer
+
+// Thresholds are the tunable detector limits. A zero field falls back to the
+// built-in default.
+type Thresholds struct {
+	SuggestMinDocs      int64 // skip index suggestions for smaller collections
+	OversizedCollection int64 // storage size (bytes) flagged as oversized
+	LargeIndex          int64 // single index size (bytes) flagged as large
+	MaxIndexes          int   // indexes per collection before write amplification
+	HighLatencyMillis   int64 // p95 read or write latency flagged as high
+	StaleDataDays       int   // newest sampled document older than this is STALE_DATA
+}
+
+// ThresholdConfig holds the thresholds applied to every database and
+// per-database overrides keyed by database name.
+type ThresholdConfig struct {
+	Default   Thresholds
+	Databases map[string]Thresholds
+}
+
+// DefaultThresholds returns the built-in detector limits.
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		SuggestMinDocs:      1000,
+		OversizedCollection: 10 << 30, // 10 GB
+		LargeIndex:          1 << 30,  // 1 GB
+		MaxIndexes:          10,
+		HighLatencyMillis:   100,
+		StaleDataDays:       730,
+	}
+}
+
+// thresholds is the active configuration; the CLI sets it from the config file.
+var thresholds ThresholdConfig
+
+// SetThresholds replaces the active thresholds used by all detectors.
+func SetThresholds(tc ThresholdConfig) {
+	thresholds = tc
+}
+
+// thresholdsFor resolves the limits for a database: the per-database
+// override, then the configured default, then the built-in default.
+func thresholdsFor(database string) Thresholds {
+	t := DefaultThresholds()
+	t.merge(thresholds.Default)
+	if o, ok := thresholds.Databases[database]; ok {
+		t.merge(o)
+	}
+	return t
+}
+
+func (t *Thresholds) merge(o Thresholds) {
+	if o.SuggestMinDocs > 0 {
+		t.SuggestMinDocs = o.SuggestMinDocs
+	}
+	if o.OversizedCollection > 0 {
+		t.OversizedCollection = o.OversizedCollection
+	}
+	if o.LargeIndex > 0 {
+		t.LargeIndex = o.LargeIndex
+	}
+	if o.MaxIndexes > 0 {
+		t.MaxIndexes = o.MaxIndexes
+	}
+	if o.HighLatencyMillis > 0 {
+		t.HighLatencyMillis = o.HighLatencyMillis
+	}
+	if o.StaleDataDays > 0 {
+		t.StaleDataDays = o.StaleDataDays
+	}
+}
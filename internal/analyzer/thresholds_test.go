@@ -0,0 +1,65 @@
+package analyzer
+
+import (
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+func setThresholds(t *testing.T, tc ThresholdConfig) {
+	t.Helper()
+	prev := thresholds
+	SetThresholds(tc)
+	t.Cleanup(func() { thresholds = prev })
+}
+
+func TestThresholdsFor_Inheritance(t *testing.T) {
+	setThresholds(t, ThresholdConfig{
+		Default: Thresholds{MaxIndexes: 15, SuggestMinDocs: 500},
+		Databases: map[string]Thresholds{
+			"analytics": {MaxIndexes: 30},
+		},
+	})
+
+	got := thresholdsFor("app")
+	if got.MaxIndexes != 15 || got.SuggestMinDocs != 500 {
+		t.Errorf("app = %+v, want configured defaults", got)
+	}
+	if got.LargeIndex != DefaultThresholds().LargeIndex {
+		t.Errorf("app LargeIndex = %d, want built-in default", got.LargeIndex)
+	}
+
+	got = thresholdsFor("analytics")
+	if got.MaxIndexes != 30 {
+		t.Errorf("analytics MaxIndexes = %d, want 30", got.MaxIndexes)
+	}
+	if got.SuggestMinDocs != 500 {
+		t.Errorf("analytics SuggestMinDocs = %d, want inherited 500", got.SuggestMinDocs)
+	}
+}
+
+func TestThresholds_AppliedByDetectors(t *testing.T) {
+	setThresholds(t, ThresholdConfig{
+		Databases: map[string]Thresholds{
+			"app": {OversizedCollection: 1 << 20, LargeIndex: 1 << 20},
+		},
+	})
+
+	c := mongoinspect.CollectionInfo{
+		Database:    "app",
+		Name:        "events",
+		StorageSize: 2 << 20,
+		Indexes:     []mongoinspect.IndexInfo{{Name: "ts_1", Size: 2 << 20}},
+	}
+	if len(detectOversizedCollection(&c)) != 1 {
+		t.Error("expected OVERSIZED_COLLECTION with lowered threshold")
+	}
+	if len(detectLargeIndex(&c)) != 1 {
+		t.Error("expected LARGE_INDEX with lowered threshold")
+	}
+
+	c.Database = "other"
+	if len(detectOversizedCollection(&c)) != 0 || len(detectLargeIndex(&c)) != 0 {
+		t.Error("override for app must not apply to other databases")
+	}
+}
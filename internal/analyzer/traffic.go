@@ -0,0 +1,49 @@
+package analyzer
+
+import (
+	"fmt"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+// trafficHighWriteRate is the sampled write rate (per second) at which an
+// over-indexed collection becomes a high severity finding.
+const trafficHighWriteRate = 100
+
+// ApplyTraffic refines a finding with the writes a change stream sample saw
+// on its collection. It reports false when the sample shows the finding is
+// wrong and should be dropped: an empty collection that is being written to
+// is a working queue or buffer, not an unused one. Findings on collections
+// with writes get the sampled rate; over-indexed collections are raised to
+// high above trafficHighWriteRate and lowered to low without writes.
+func ApplyTraffic(f *Finding, sample *mongoinspect.TrafficSample) bool {
+	if f.Collection == "" || f.Database == "" {
+		return true
+	}
+	writes := sample.Lookup(f.Database, f.Collection).Writes()
+	rate := sample.Rate(writes)
+	window := fmt.Sprintf("%.0fs", sample.Window.Seconds())
+	switch f.Type {
+	case FindingUnusedCollection:
+		if writes > 0 {
+			return false
+		}
+		f.Message += fmt.Sprintf(" and received no writes in the %s traffic sample", window)
+	case FindingWriteHeavyOverIndexed:
+		switch {
+		case writes == 0:
+			f.Severity = SeverityLow
+			f.Message += fmt.Sprintf("; no writes in the %s traffic sample", window)
+		case rate >= trafficHighWriteRate:
+			f.Severity = SeverityHigh
+			f.Message += fmt.Sprintf("; %.1f writes/s in the %s traffic sample", rate, window)
+		default:
+			f.Message += fmt.Sprintf("; %.1f writes/s in the %s traffic sample", rate, window)
+		}
+	case FindingUnusedIndex, FindingDuplicateIndex, FindingSingleFieldRedundant:
+		if writes > 0 {
+			f.Message += fmt.Sprintf("; the collection took %.1f writes/s in the %s traffic sample, each maintaining this index", rate, window)
+		}
+	}
+	return true
+}
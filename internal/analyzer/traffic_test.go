@@ -0,0 +1,64 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+func TestApplyTraffic(t *testing.T) {
+	sample := &mongoinspect.TrafficSample{
+		Window: time.Minute,
+		Namespaces: []mongoinspect.NamespaceWrites{
+			{Database: "app", Collection: "jobs", Inserts: 120, Deletes: 120},
+			{Database: "app", Collection: "events", Inserts: 9000},
+			{Database: "app", Collection: "orders", Updates: 60},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		f            Finding
+		keep         bool
+		wantSeverity Severity
+		wantSuffix   string
+	}{
+		{"queue collection is not unused",
+			Finding{Type: FindingUnusedCollection, Severity: SeverityMedium, Database: "app", Collection: "jobs", Message: "collection has 0 documents"},
+			false, "", ""},
+		{"idle empty collection is confirmed",
+			Finding{Type: FindingUnusedCollection, Severity: SeverityMedium, Database: "app", Collection: "old", Message: "collection has 0 documents"},
+			true, SeverityMedium, " and received no writes in the 60s traffic sample"},
+		{"busy over-indexed collection is raised",
+			Finding{Type: FindingWriteHeavyOverIndexed, Severity: SeverityMedium, Database: "app", Collection: "events", Message: "collection has 14 indexes — every write updates all of them"},
+			true, SeverityHigh, "; 150.0 writes/s in the 60s traffic sample"},
+		{"quiet over-indexed collection is lowered",
+			Finding{Type: FindingWriteHeavyOverIndexed, Severity: SeverityMedium, Database: "app", Collection: "archive", Message: "collection has 12 indexes — every write updates all of them"},
+			true, SeverityLow, "; no writes in the 60s traffic sample"},
+		{"unused index notes write cost",
+			Finding{Type: FindingUnusedIndex, Severity: SeverityMedium, Database: "app", Collection: "orders", Index: "x_1", Message: `index "x_1" has never been used`},
+			true, SeverityMedium, "; the collection took 1.0 writes/s in the 60s traffic sample, each maintaining this index"},
+		{"cluster findings are untouched",
+			Finding{Type: FindingUnusedCollection, Severity: SeverityMedium, Message: "no collection"},
+			true, SeverityMedium, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := tt.f
+			if got := ApplyTraffic(&f, sample); got != tt.keep {
+				t.Fatalf("keep = %v, want %v", got, tt.keep)
+			}
+			if !tt.keep {
+				return
+			}
+			if f.Severity != tt.wantSeverity {
+				t.Errorf("severity = %s, want %s", f.Severity, tt.wantSeverity)
+			}
+			if f.Message != tt.f.Message+tt.wantSuffix || (tt.wantSuffix != "" && !strings.HasSuffix(f.Message, tt.wantSuffix)) {
+				t.Errorf("message = %q, want suffix %q", f.Message, tt.wantSuffix)
+			}
+		})
+	}
+}
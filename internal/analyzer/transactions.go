@@ -0,0 +1,119 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+const (
+	txAbortMinStarted = 100 // fewer transactions say nothing about the abort rate
+	txAbortHighShare  = 0.1 // share of started transactions that aborted
+)
+
+// DetectTransactionRisks reviews the transactions opened in code.
+//
+// A transaction whose body makes a network call or sleeps holds its locks
+// and WiredTiger snapshot while it waits, and the server aborts it once
+// it outlives transactionLifetimeLimitSeconds; that is LONG_TRANSACTION_RISK,
+// raised to high when serverStatus shows many transactions aborting. When
+// no code explains a high abort rate, one cluster-level finding reports it.
+//
+// On a sharded cluster, a transaction touching both sharded and unsharded
+// collections always spans the unsharded collections' primary shard and the
+// shards owning the sharded data, so every commit is a two-phase commit.
+// Collections are matched by name, as code rarely names the database.
+//
+// stats and sharding are nil when they were not read.
+func DetectTransactionRisks(scan *scanner.ScanResult, collections []mongoinspect.CollectionInfo, stats *mongoinspect.TransactionStats, sharding *mongoinspect.ShardingInfo) []Finding {
+	if len(scan.TransactionRefs) == 0 {
+		return nil
+	}
+	abortNote, aborting := "", false
+	if stats != nil && stats.Started >= txAbortMinStarted {
+		share := float64(stats.Aborted) / float64(stats.Started)
+		aborting = share >= txAbortHighShare
+		abortNote = fmt.Sprintf("; %d of %d transactions since startup aborted (%.0f%%)", stats.Aborted, stats.Started, share*100)
+	}
+	lifetime := int64(mongoinspect.DefaultTransactionLifetimeSeconds)
+	if stats != nil && stats.LifetimeSeconds > 0 {
+		lifetime = stats.LifetimeSeconds
+	}
+
+	var sharded map[string]bool
+	if sharding != nil && sharding.Enabled {
+		sharded = make(map[string]bool, len(sharding.Collections))
+		for _, sc := range sharding.Collections {
+			sharded[strings.ToLower(sc.Collection)] = true
+		}
+	}
+
+	var findings []Finding
+	risky := 0
+	for _, tx := range scan.TransactionRefs {
+		var database, collection string
+		if len(tx.Collections) > 0 {
+			collection = tx.Collections[0]
+			if c, ok := findCollection(collection, collections); ok {
+				database = c.Database
+			}
+		}
+		if len(tx.Risks) > 0 {
+			risky++
+			sev := SeverityMedium
+			if aborting {
+				sev = SeverityHigh
+			}
+			findings = append(findings, Finding{
+				Type:       FindingLongTransactionRisk,
+				Severity:   sev,
+				Database:   database,
+				Collection: collection,
+				Message: fmt.Sprintf("transaction at %s:%d waits on a %s before it commits, holding its locks and snapshot; the server aborts transactions open over %ds%s — do the %s outside the transaction",
+					tx.File, tx.Line, strings.Join(tx.Risks, " and a "), lifetime, abortNote, strings.Join(tx.Risks, " and ")),
+				File: tx.File,
+				Line: tx.Line,
+			})
+		}
+
+		if sharded == nil {
+			continue
+		}
+		var onShards, onPrimary []string
+		for _, name := range tx.Collections {
+			switch {
+			case sharded[strings.ToLower(name)]:
+				onShards = append(onShards, name)
+			default:
+				if _, ok := findCollection(name, collections); ok {
+					onPrimary = append(onPrimary, name)
+				}
+			}
+		}
+		if len(onShards) == 0 || len(onPrimary) == 0 {
+			continue
+		}
+		findings = append(findings, Finding{
+			Type:       FindingMixedShardingTxn,
+			Severity:   SeverityMedium,
+			Database:   database,
+			Collection: collection,
+			Message: fmt.Sprintf("transaction at %s:%d touches sharded %s and unsharded %s; unsharded collections live on their database's primary shard, so every commit is a two-phase commit across shards — shard them too or move them out of the transaction",
+				tx.File, tx.Line, strings.Join(quoteAll(onShards), ", "), strings.Join(quoteAll(onPrimary), ", ")),
+			File: tx.File,
+			Line: tx.Line,
+		})
+	}
+
+	if aborting && risky == 0 {
+		findings = append(findings, Finding{
+			Type:     FindingLongTransactionRisk,
+			Severity: SeverityMedium,
+			Message: fmt.Sprintf("%d transactions in code, none waiting on remote work%s — check for transactions open longer than the %ds lifetime limit, write conflicts and oversized transactions",
+				len(scan.TransactionRefs), abortNote, lifetime),
+		})
+	}
+	return findings
+}
@@ -0,0 +1,58 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+func TestDetectTransactionRisks(t *testing.T) {
+	scan := &scanner.ScanResult{TransactionRefs: []scanner.TransactionRef{
+		{Collections: []string{"orders", "payments"}, Risks: []string{"network call"}, File: "checkout.js", Line: 12, EndLine: 30},
+		{Collections: []string{"inventory", "orders"}, File: "stock.go", Line: 40, EndLine: 52},
+		{Collections: []string{"ledger"}, File: "ledger.py", Line: 8, EndLine: 14},
+	}}
+	collections := []mongoinspect.CollectionInfo{
+		{Database: "shop", Name: "orders"},
+		{Database: "shop", Name: "inventory"},
+		{Database: "shop", Name: "payments"},
+	}
+	sharding := &mongoinspect.ShardingInfo{Enabled: true, Collections: []mongoinspect.ShardedCollectionInfo{
+		{Namespace: "shop.orders", Database: "shop", Collection: "orders"},
+		{Namespace: "shop.payments", Database: "shop", Collection: "payments"},
+	}}
+
+	findings := DetectTransactionRisks(scan, collections, nil, sharding)
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings, want 2: %+v", len(findings), findings)
+	}
+	if f := findings[0]; f.Type != FindingLongTransactionRisk || f.Severity != SeverityMedium || f.Database != "shop" ||
+		f.File != "checkout.js" || !strings.Contains(f.Message, "waits on a network call") || !strings.Contains(f.Message, "over 60s") {
+		t.Errorf("long transaction finding = %+v", f)
+	}
+	if f := findings[1]; f.Type != FindingMixedShardingTxn || f.Line != 40 ||
+		!strings.Contains(f.Message, `sharded "orders" and unsharded "inventory"`) {
+		t.Errorf("mixed sharding finding = %+v", f)
+	}
+
+	// A high abort rate raises the code finding.
+	stats := &mongoinspect.TransactionStats{Started: 1000, Aborted: 200, LifetimeSeconds: 30}
+	findings = DetectTransactionRisks(scan, collections, stats, nil)
+	if len(findings) != 1 || findings[0].Severity != SeverityHigh ||
+		!strings.Contains(findings[0].Message, "200 of 1000 transactions since startup aborted (20%)") {
+		t.Errorf("findings = %+v", findings)
+	}
+
+	// Without risky code the abort rate is reported for the cluster.
+	quiet := &scanner.ScanResult{TransactionRefs: scan.TransactionRefs[1:]}
+	findings = DetectTransactionRisks(quiet, collections, stats, nil)
+	if len(findings) != 1 || findings[0].Collection != "" || !strings.Contains(findings[0].Message, "2 transactions in code, none waiting") {
+		t.Errorf("findings = %+v", findings)
+	}
+	stats.Aborted = 10
+	if findings := DetectTransactionRisks(quiet, collections, stats, nil); len(findings) != 0 {
+		t.Errorf("expected no findings at a low abort rate, got %+v", findings)
+	}
+}
@@ -0,0 +1,202 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+const (
+	accelerationFactor          = 2.0      // later growth rate must be at least this multiple of the earlier rate
+	accelerationMinGrowth int64 = 10 << 20 // ignore acceleration below 10 MB of growth in the later window
+)
+
+// TrendSnapshot is one baseline report used for trend analysis.
+type TrendSnapshot struct {
+	Timestamp   time.Time
+	Findings    []Finding
+	Collections []mongoinspect.CollectionInfo
+}
+
+// TrendPoint is a collection's size at one snapshot.
+type TrendPoint struct {
+	Timestamp      time.Time `json:"timestamp"`
+	DocCount       int64     `json:"docCount"`
+	Size           int64     `json:"size"`
+	StorageSize    int64     `json:"storageSize"`
+	TotalIndexSize int64     `json:"totalIndexSize"`
+}
+
+// CollectionTrend is a collection's size history with growth rates in bytes per day.
+type CollectionTrend struct {
+	Database          string       `json:"database"`
+	Collection        string       `json:"collection"`
+	Points            []TrendPoint `json:"points"`
+	GrowthPerDay      float64      `json:"growthPerDay"`
+	IndexGrowthPerDay float64      `json:"indexGrowthPerDay"`
+	Accelerating      bool         `json:"accelerating"`
+}
+
+// FindingCountPoint is the finding count by severity at one snapshot.
+type FindingCountPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Total     int       `json:"total"`
+	High      int       `json:"high"`
+	Medium    int       `json:"medium"`
+	Low       int       `json:"low"`
+	Info      int       `json:"info"`
+}
+
+// Trend summarizes how collections and findings evolved across baselines.
+type Trend struct {
+	From        time.Time           `json:"from"`
+	To          time.Time           `json:"to"`
+	Snapshots   int                 `json:"snapshots"`
+	Collections []CollectionTrend   `json:"collections"`
+	Findings    []FindingCountPoint `json:"findings"`
+	// Cluster holds per-snapshot totals across all collections.
+	Cluster []TrendPoint `json:"cluster"`
+}
+
+// LoadTrendSnapshots loads baseline reports for trend analysis, skipping
+// reports without a timestamp, and returns them oldest first.
+func LoadTrendSnapshots(paths []string) ([]TrendSnapshot, error) {
+	var snaps []TrendSnapshot
+	for _, p := range paths {
+		findings, collections, ts, err := LoadBaselineWithCollections(p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		if ts.IsZero() {
+			continue
+		}
+		snaps = append(snaps, TrendSnapshot{Timestamp: ts, Findings: findings, Collections: collections})
+	}
+	sort.SliceStable(snaps, func(i, j int) bool { return snaps[i].Timestamp.Before(snaps[j].Timestamp) })
+	return snaps, nil
+}
+
+// AnalyzeTrend builds per-collection growth series and finding counts from
+// snapshots ordered oldest first. Collections are sorted by growth rate,
+// fastest first.
+func AnalyzeTrend(snaps []TrendSnapshot) Trend {
+	var t Trend
+	t.Snapshots = len(snaps)
+	if len(snaps) == 0 {
+		return t
+	}
+	t.From = snaps[0].Timestamp
+	t.To = snaps[len(snaps)-1].Timestamp
+
+	byKey := make(map[string]*CollectionTrend)
+	var order []string
+	for _, s := range snaps {
+		var fc FindingCountPoint
+		fc.Timestamp = s.Timestamp
+		for _, f := range s.Findings {
+			fc.Total++
+			switch f.Severity {
+			case SeverityHigh:
+				fc.High++
+			case SeverityMedium:
+				fc.Medium++
+			case SeverityLow:
+				fc.Low++
+			case SeverityInfo:
+				fc.Info++
+			}
+		}
+		t.Findings = append(t.Findings, fc)
+
+		total := TrendPoint{Timestamp: s.Timestamp}
+		for i := range s.Collections {
+			c := &s.Collections[i]
+			total.DocCount += c.DocCount
+			total.Size += c.Size
+			total.StorageSize += c.StorageSize
+			total.TotalIndexSize += c.TotalIndexSize
+			key := c.Database + "." + c.Name
+			ct, ok := byKey[key]
+			if !ok {
+				ct = &CollectionTrend{Database: c.Database, Collection: c.Name}
+				byKey[key] = ct
+				order = append(order, key)
+			}
+			ct.Points = append(ct.Points, TrendPoint{
+				Timestamp:      s.Timestamp,
+				DocCount:       c.DocCount,
+				Size:           c.Size,
+				StorageSize:    c.StorageSize,
+				TotalIndexSize: c.TotalIndexSize,
+			})
+		}
+		t.Cluster = append(t.Cluster, total)
+	}
+
+	for _, key := range order {
+		ct := byKey[key]
+		first, last := ct.Points[0], ct.Points[len(ct.Points)-1]
+		if days := last.Timestamp.Sub(first.Timestamp).Hours() / 24; days > 0 {
+			ct.GrowthPerDay = float64(last.Size-first.Size) / days
+			ct.IndexGrowthPerDay = float64(last.TotalIndexSize-first.TotalIndexSize) / days
+		}
+		ct.Accelerating = isAccelerating(ct.Points)
+		t.Collections = append(t.Collections, *ct)
+	}
+	sort.SliceStable(t.Collections, func(i, j int) bool {
+		return t.Collections[i].GrowthPerDay > t.Collections[j].GrowthPerDay
+	})
+	return t
+}
+
+// isAccelerating compares the growth rate over the later half of the series
+// with the earlier half. At least three points are needed for two windows.
+func isAccelerating(points []TrendPoint) bool {
+	early, late, ok := halfRates(points)
+	if !ok || early <= 0 {
+		return false
+	}
+	mid := len(points) / 2
+	if points[len(points)-1].Size-points[mid].Size < accelerationMinGrowth {
+		return false
+	}
+	return late >= accelerationFactor*early
+}
+
+func halfRates(points []TrendPoint) (early, late float64, ok bool) {
+	if len(points) < 3 {
+		return 0, 0, false
+	}
+	mid := len(points) / 2
+	a, m, z := points[0], points[mid], points[len(points)-1]
+	d1 := m.Timestamp.Sub(a.Timestamp).Hours() / 24
+	d2 := z.Timestamp.Sub(m.Timestamp).Hours() / 24
+	if d1 <= 0 || d2 <= 0 {
+		return 0, 0, false
+	}
+	return float64(m.Size-a.Size) / d1, float64(z.Size-m.Size) / d2, true
+}
+
+// DetectAcceleratingGrowth returns ACCELERATING_GROWTH findings for
+// collections whose growth rate sped up across the trend window.
+func DetectAcceleratingGrowth(t *Trend) []Finding {
+	var findings []Finding
+	for i := range t.Collections {
+		ct := &t.Collections[i]
+		if !ct.Accelerating {
+			continue
+		}
+		early, late, _ := halfRates(ct.Points)
+		findings = append(findings, Finding{
+			Type:       FindingAcceleratingGrowth,
+			Severity:   SeverityMedium,
+			Database:   ct.Database,
+			Collection: ct.Collection,
+			Message: fmt.Sprintf("growth rate accelerated from %s/day to %s/day across %d baselines",
+				formatBytes(int64(early)), formatBytes(int64(late)), len(ct.Points)),
+		})
+	}
+	return findings
+}
@@ -0,0 +1,116 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+func trendSnapshots(sizes ...int64) []TrendSnapshot {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var snaps []TrendSnapshot
+	for i, size := range sizes {
+		snaps = append(snaps, TrendSnapshot{
+			Timestamp: start.Add(time.Duration(i) * 24 * time.Hour),
+			Findings:  make([]Finding, i+1),
+			Collections: []mongoinspect.CollectionInfo{
+				{Database: "app", Name: "events", Size: size, TotalIndexSize: size / 10},
+				{Database: "app", Name: "users", Size: 1 << 20, TotalIndexSize: 1 << 18},
+			},
+		})
+	}
+	return snaps
+}
+
+func TestAnalyzeTrend_GrowthRates(t *testing.T) {
+	trend := AnalyzeTrend(trendSnapshots(100<<20, 110<<20, 120<<20))
+
+	if trend.Snapshots != 3 || len(trend.Findings) != 3 {
+		t.Fatalf("unexpected trend shape: %+v", trend)
+	}
+	if trend.Findings[2].Total != 3 {
+		t.Errorf("finding count at last snapshot = %d, want 3", trend.Findings[2].Total)
+	}
+	if len(trend.Collections) != 2 {
+		t.Fatalf("expected 2 collections, got %d", len(trend.Collections))
+	}
+	events := trend.Collections[0]
+	if events.Collection != "events" {
+		t.Fatalf("expected fastest-growing collection first, got %s", events.Collection)
+	}
+	if events.GrowthPerDay != float64(10<<20) {
+		t.Errorf("growth/day = %f, want %d", events.GrowthPerDay, 10<<20)
+	}
+	if events.Accelerating {
+		t.Error("linear growth should not be accelerating")
+	}
+	if trend.Collections[1].GrowthPerDay != 0 {
+		t.Errorf("flat collection growth = %f", trend.Collections[1].GrowthPerDay)
+	}
+}
+
+func TestDetectAcceleratingGrowth(t *testing.T) {
+	trend := AnalyzeTrend(trendSnapshots(100<<20, 110<<20, 150<<20))
+	findings := DetectAcceleratingGrowth(&trend)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %d", len(findings))
+	}
+	f := findings[0]
+	if f.Type != FindingAcceleratingGrowth || f.Collection != "events" || f.Severity != SeverityMedium {
+		t.Fatalf("unexpected finding: %+v", f)
+	}
+	if !strings.Contains(f.Message, "10.0 MB/day to 40.0 MB/day") {
+		t.Errorf("unexpected message: %s", f.Message)
+	}
+}
+
+func TestDetectAcceleratingGrowth_IgnoresSmallAndShortSeries(t *testing.T) {
+	// Tripled rate but only a few KB of growth.
+	trend := AnalyzeTrend(trendSnapshots(1000, 1100, 1400))
+	if got := DetectAcceleratingGrowth(&trend); len(got) != 0 {
+		t.Fatalf("expected small growth to be ignored, got %+v", got)
+	}
+	// Two points cannot show acceleration.
+	trend = AnalyzeTrend(trendSnapshots(100<<20, 500<<20))
+	if got := DetectAcceleratingGrowth(&trend); len(got) != 0 {
+		t.Fatalf("expected no finding with two snapshots, got %+v", got)
+	}
+}
+
+func TestLoadTrendSnapshots_SortsAndSkipsUntimestamped(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, ts string) string {
+		report := map[string]any{
+			"metadata":    map[string]string{"timestamp": ts},
+			"findings":    []Finding{},
+			"collections": []mongoinspect.CollectionInfo{{Database: "app", Name: "c"}},
+		}
+		data, _ := json.Marshal(report)
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	paths := []string{
+		write("a.json", "2026-01-03T00:00:00Z"),
+		write("b.json", ""),
+		write("c.json", "2026-01-01T00:00:00Z"),
+	}
+
+	snaps, err := LoadTrendSnapshots(paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 2 {
+		t.Fatalf("expected 2 snapshots, got %d", len(snaps))
+	}
+	if !snaps[0].Timestamp.Before(snaps[1].Timestamp) {
+		t.Error("snapshots should be sorted oldest first")
+	}
+}
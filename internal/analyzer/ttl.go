@@ -0,0 +1,121 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+// expiryFieldRe matches field names that hold an absolute expiry time, the
+// intended use of expireAfterSeconds: 0.
+var expiryFieldRe = regexp.MustCompile(`(?i)expir|ttl|delete_?at|purge|valid_?until`)
+
+// detectTTLMisconfigured flags TTL index options that do not expire documents
+// the way they appear to: compound TTL indexes, immediate expiry on fields
+// that do not hold an expiry date, and partial TTL indexes.
+func detectTTLMisconfigured(c *mongoinspect.CollectionInfo) []Finding {
+	var findings []Finding
+	add := func(idx *mongoinspect.IndexInfo, sev Severity, msg string) {
+		findings = append(findings, Finding{
+			Type:       FindingTTLMisconfigured,
+			Severity:   sev,
+			Database:   c.Database,
+			Collection: c.Name,
+			Index:      idx.Name,
+			Message:    msg,
+		})
+	}
+	for i := range c.Indexes {
+		idx := &c.Indexes[i]
+		if idx.TTL == nil {
+			continue
+		}
+		if len(idx.Key) != 1 {
+			add(idx, SeverityMedium, fmt.Sprintf("expireAfterSeconds is set on compound index %s, which MongoDB ignores; no documents expire",
+				formatIndexSpec(idx.Key)))
+			continue
+		}
+		field := idx.Key[0].Field
+		if *idx.TTL == 0 && !expiryFieldRe.MatchString(field) {
+			add(idx, SeverityMedium, fmt.Sprintf("expireAfterSeconds is 0 on %q, so documents are deleted as soon as that time passes; "+
+				"this only fits fields holding an expiry date, not creation or update timestamps", field))
+		}
+		if idx.PartialFilter != "" {
+			add(idx, SeverityLow, fmt.Sprintf("TTL index is partial: documents not matching %s are never expired by it", idx.PartialFilter))
+		}
+	}
+	return findings
+}
+
+// DetectTTLFieldTypes returns TTL_MISCONFIGURED findings for TTL indexes whose
+// field holds non-date values in sampled documents. The TTL monitor skips
+// documents whose field is not a date (or array of dates), so they are kept
+// forever.
+func DetectTTLFieldTypes(collections []mongoinspect.CollectionInfo, samples []mongoinspect.FieldSampleResult) []Finding {
+	var findings []Finding
+	for i := range collections {
+		c := &collections[i]
+		for j := range c.Indexes {
+			idx := &c.Indexes[j]
+			if idx.TTL == nil || len(idx.Key) != 1 {
+				continue
+			}
+			field := idx.Key[0].Field
+			ff, sampleSize, ok := sampledField(samples, c.Database, c.Name, field)
+			if !ok {
+				continue
+			}
+			var bad int64
+			var parts []string
+			for _, t := range sortedTypeNames(ff.Types) {
+				if t == "date" || t == "array" {
+					continue
+				}
+				bad += ff.Types[t]
+				parts = append(parts, fmt.Sprintf("%s: %d", t, ff.Types[t]))
+			}
+			if bad == 0 {
+				continue
+			}
+			findings = append(findings, Finding{
+				Type:       FindingTTLMisconfigured,
+				Severity:   SeverityMedium,
+				Database:   c.Database,
+				Collection: c.Name,
+				Index:      idx.Name,
+				Message: fmt.Sprintf("TTL field %q is not a date in %d of %d sampled documents (%s); those documents never expire",
+					field, bad, sampleSize, strings.Join(parts, ", ")),
+			})
+		}
+	}
+	return findings
+}
+
+// sampledField returns the sampled frequency of one field path and the
+// collection's sample size.
+func sampledField(samples []mongoinspect.FieldSampleResult, db, coll, path string) (mongoinspect.FieldFrequency, int64, bool) {
+	for i := range samples {
+		s := &samples[i]
+		if s.Database != db || s.Collection != coll {
+			continue
+		}
+		for _, f := range s.Fields {
+			if f.Path == path {
+				return f, s.SampleSize, true
+			}
+		}
+	}
+	return mongoinspect.FieldFrequency{}, 0, false
+}
+
+func sortedTypeNames(types map[string]int64) []string {
+	names := make([]string, 0, len(types))
+	for t := range types {
+		names = append(names, t)
+	}
+	sort.Strings(names)
+	return names
+}
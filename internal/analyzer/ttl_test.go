@@ -0,0 +1,79 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+func ttlIndex(name string, key []mongoinspect.KeyField, ttl int32, partial string) mongoinspect.IndexInfo {
+	return mongoinspect.IndexInfo{Name: name, Key: key, TTL: &ttl, PartialFilter: partial}
+}
+
+func TestDetectTTLMisconfigured(t *testing.T) {
+	c := &mongoinspect.CollectionInfo{
+		Database: "app",
+		Name:     "sessions",
+		Indexes: []mongoinspect.IndexInfo{
+			ttlIndex("expiresAt_1", kf("expiresAt"), 0, ""),
+			ttlIndex("createdAt_1", kf("createdAt"), 0, ""),
+			ttlIndex("user_created", kf("user_id", "created_at"), 3600, ""),
+			ttlIndex("updated_1", kf("updated"), 86400, `{"kind":"guest"}`),
+		},
+	}
+	findings := detectTTLMisconfigured(c)
+	byIndex := make(map[string]Finding)
+	for _, f := range findings {
+		if f.Type != FindingTTLMisconfigured {
+			t.Errorf("unexpected type %s", f.Type)
+		}
+		byIndex[f.Index] = f
+	}
+	if len(findings) != 3 {
+		t.Fatalf("expected 3 findings, got %+v", findings)
+	}
+	if _, ok := byIndex["expiresAt_1"]; ok {
+		t.Error("expireAfterSeconds 0 on an expiry field should not be flagged")
+	}
+	if f := byIndex["createdAt_1"]; f.Severity != SeverityMedium || !strings.Contains(f.Message, "expireAfterSeconds is 0") {
+		t.Errorf("createdAt_1 = %+v", f)
+	}
+	if f := byIndex["user_created"]; !strings.Contains(f.Message, "compound") {
+		t.Errorf("user_created = %+v", f)
+	}
+	if f := byIndex["updated_1"]; f.Severity != SeverityLow || !strings.Contains(f.Message, `{"kind":"guest"}`) {
+		t.Errorf("updated_1 = %+v", f)
+	}
+}
+
+func TestDetectTTLFieldTypes(t *testing.T) {
+	collections := []mongoinspect.CollectionInfo{{
+		Database: "app",
+		Name:     "events",
+		Indexes: []mongoinspect.IndexInfo{
+			ttlIndex("ts_1", kf("ts"), 3600, ""),
+			ttlIndex("seen_1", kf("seen"), 3600, ""),
+		},
+	}}
+	samples := []mongoinspect.FieldSampleResult{{
+		Database:   "app",
+		Collection: "events",
+		SampleSize: 100,
+		Fields: []mongoinspect.FieldFrequency{
+			{Path: "ts", Count: 100, Types: map[string]int64{"date": 80, "string": 15, "int64": 5}},
+			{Path: "seen", Count: 100, Types: map[string]int64{"date": 90, "array": 10}},
+		},
+	}}
+	findings := DetectTTLFieldTypes(collections, samples)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %+v", findings)
+	}
+	f := findings[0]
+	if f.Index != "ts_1" || f.Severity != SeverityMedium {
+		t.Errorf("unexpected finding %+v", f)
+	}
+	if !strings.Contains(f.Message, "20 of 100") || !strings.Contains(f.Message, "int64: 5, string: 15") {
+		t.Errorf("message = %q", f.Message)
+	}
+}
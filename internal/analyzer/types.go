@@ -1,5 +1,7 @@
 package analyzer
 
+import "github.com/ppiankov/mongospectre/internal/gitinfo"
+
 // Severity indicates the risk level of a finding.
 type Severity string
 
@@ -16,36 +18,53 @@ type FindingType string
 const (
 	FindingUnusedCollection       FindingType = "UNUSED_COLLECTION"
 	FindingUnusedIndex            FindingType = "UNUSED_INDEX"
+	FindingHiddenIndexForgotten   FindingType = "HIDDEN_INDEX_FORGOTTEN"
 	FindingMissingIndex           FindingType = "MISSING_INDEX"
 	FindingDuplicateIndex         FindingType = "DUPLICATE_INDEX"
 	FindingOversizedCollection    FindingType = "OVERSIZED_COLLECTION"
 	FindingMissingTTL             FindingType = "MISSING_TTL"
+	FindingTTLMisconfigured       FindingType = "TTL_MISCONFIGURED"
 	FindingUnshardedLarge         FindingType = "UNSHARDED_LARGE"
 	FindingMonotonicShardKey      FindingType = "MONOTONIC_SHARD_KEY"
 	FindingUnbalancedChunks       FindingType = "UNBALANCED_CHUNKS"
 	FindingJumboChunks            FindingType = "JUMBO_CHUNKS"
 	FindingBalancerDisabled       FindingType = "BALANCER_DISABLED"
+	FindingShardingCandidate      FindingType = "SHARDING_CANDIDATE"
 	FindingMissingCollection      FindingType = "MISSING_COLLECTION"
 	FindingOrphanedIndex          FindingType = "ORPHANED_INDEX"
 	FindingUnindexedQuery         FindingType = "UNINDEXED_QUERY"
 	FindingSuggestIndex           FindingType = "SUGGEST_INDEX"
 	FindingCompoundIndexSuggest   FindingType = "COMPOUND_INDEX_SUGGESTION"
+	FindingPartialIndexSuggest    FindingType = "PARTIAL_INDEX_SUGGEST"
+	FindingUniqueIndexSuggest     FindingType = "UNIQUE_INDEX_SUGGEST"
 	FindingIndexOrderWarning      FindingType = "INDEX_ORDER_WARNING"
+	FindingWildcardIndexBloat     FindingType = "WILDCARD_INDEX_BLOAT"
+	FindingTextIndexCost          FindingType = "TEXT_INDEX_COST"
+	FindingTextIndexConflict      FindingType = "TEXT_INDEX_CONFLICT"
 	FindingRedundantIndex         FindingType = "REDUNDANT_INDEX"
 	FindingPartialCoverage        FindingType = "PARTIAL_COVERAGE"
 	FindingSlowQuerySource        FindingType = "SLOW_QUERY_SOURCE"
 	FindingCollectionScanSource   FindingType = "COLLECTION_SCAN_SOURCE"
 	FindingFrequentSlowQuery      FindingType = "FREQUENT_SLOW_QUERY"
+	FindingPoorQueryTargeting     FindingType = "POOR_QUERY_TARGETING"
+	FindingInMemorySort           FindingType = "IN_MEMORY_SORT"
+	FindingSortSpilledToDisk      FindingType = "SORT_SPILLED_TO_DISK"
 	FindingAdminInDataDB          FindingType = "ADMIN_IN_DATA_DB"
 	FindingDuplicateUser          FindingType = "DUPLICATE_USER"
 	FindingOverprivilegedUser     FindingType = "OVERPRIVILEGED_USER"
 	FindingMultipleAdminUsers     FindingType = "MULTIPLE_ADMIN_USERS"
+	FindingWeakAuthMechanism      FindingType = "WEAK_AUTH_MECHANISM"
+	FindingNoClientSource         FindingType = "NO_CLIENT_SOURCE_RESTRICTION"
+	FindingWildcardPrivilege      FindingType = "WILDCARD_PRIVILEGE"
+	FindingPrivilegeEscalation    FindingType = "PRIVILEGE_ESCALATION"
+	FindingUnusedRole             FindingType = "UNUSED_ROLE"
 	FindingDynamicCollection      FindingType = "DYNAMIC_COLLECTION"
 	FindingValidatorMissing       FindingType = "VALIDATOR_MISSING"
 	FindingValidatorStale         FindingType = "VALIDATOR_STALE"
 	FindingValidatorStrictRisk    FindingType = "VALIDATOR_STRICT_RISK"
 	FindingValidatorWarnOnly      FindingType = "VALIDATOR_WARN_ONLY"
 	FindingFieldNotInValidator    FindingType = "FIELD_NOT_IN_VALIDATOR"
+	FindingCSFLESchemaDrift       FindingType = "CSFLE_SCHEMA_DRIFT"
 	FindingAtlasIndexSuggestion   FindingType = "ATLAS_INDEX_SUGGESTION"
 	FindingAtlasAlertActive       FindingType = "ATLAS_ALERT_ACTIVE"
 	FindingAtlasTierMismatch      FindingType = "ATLAS_TIER_MISMATCH"
@@ -66,12 +85,21 @@ const (
 	FindingURIShortTimeout        FindingType = "URI_SHORT_TIMEOUT"
 	FindingURINoReadPreference    FindingType = "URI_NO_READ_PREFERENCE"
 	FindingURIDirectConnection    FindingType = "URI_DIRECT_CONNECTION"
+	FindingHardcodedURI           FindingType = "HARDCODED_MONGODB_URI"
 	FindingAuthDisabled           FindingType = "AUTH_DISABLED"
 	FindingBindAllInterfaces      FindingType = "BIND_ALL_INTERFACES"
 	FindingTLSDisabled            FindingType = "TLS_DISABLED"
 	FindingTLSAllowInvalidCerts   FindingType = "TLS_ALLOW_INVALID_CERTS"
 	FindingAuditLogDisabled       FindingType = "AUDIT_LOG_DISABLED"
 	FindingLocalhostException     FindingType = "LOCALHOST_EXCEPTION_ACTIVE"
+	FindingExternalAuthMisconfig  FindingType = "EXTERNAL_AUTH_MISCONFIG"
+	FindingNoEncryptionAtRest     FindingType = "NO_ENCRYPTION_AT_REST"
+	FindingStaleKMIPKey           FindingType = "STALE_KMIP_KEY"
+	FindingServerParamDrift       FindingType = "SERVER_PARAM_DRIFT"
+	FindingCachePressure          FindingType = "CACHE_PRESSURE"
+	FindingTicketExhaustion       FindingType = "TICKET_EXHAUSTION"
+	FindingSlowCheckpoint         FindingType = "SLOW_CHECKPOINT"
+	FindingHighCollectionLatency  FindingType = "HIGH_COLLECTION_LATENCY"
 	FindingIndexBloat             FindingType = "INDEX_BLOAT"
 	FindingWriteHeavyOverIndexed  FindingType = "WRITE_HEAVY_OVER_INDEXED"
 	FindingSingleFieldRedundant   FindingType = "SINGLE_FIELD_REDUNDANT"
@@ -82,6 +110,10 @@ const (
 	FindingFieldNameCollision     FindingType = "FIELD_NAME_COLLISION"
 	FindingExcessiveFieldCount    FindingType = "EXCESSIVE_FIELD_COUNT"
 	FindingNumericFieldNames      FindingType = "NUMERIC_FIELD_NAMES"
+	FindingFieldNameHazard        FindingType = "FIELD_NAME_HAZARD"
+	FindingDocumentsNear16MB      FindingType = "DOCUMENTS_NEAR_16MB"
+	FindingUnboundedArrayField    FindingType = "UNBOUNDED_ARRAY_FIELD"
+	FindingSchemaAntipattern      FindingType = "SCHEMA_ANTIPATTERN"
 	FindingSingleMemberReplSet    FindingType = "SINGLE_MEMBER_REPLSET"
 	FindingEvenMemberCount        FindingType = "EVEN_MEMBER_COUNT"
 	FindingMemberUnhealthy        FindingType = "MEMBER_UNHEALTHY"
@@ -92,6 +124,46 @@ const (
 	FindingIndexGrowthOutpacing   FindingType = "INDEX_GROWTH_OUTPACING_DATA"
 	FindingApproachingLimit       FindingType = "APPROACHING_LIMIT"
 	FindingStorageReclaim         FindingType = "STORAGE_RECLAIM"
+	FindingAcceleratingGrowth     FindingType = "ACCELERATING_GROWTH"
+	FindingStorageForecast        FindingType = "STORAGE_FORECAST_EXCEEDED"
+	FindingNamingViolation        FindingType = "NAMING_CONVENTION_VIOLATION"
+	FindingWriteHotspot           FindingType = "WRITE_HOTSPOT"
+	FindingShadowWriter           FindingType = "SHADOW_WRITER"
+	FindingWriteOnlyCollection    FindingType = "WRITE_ONLY_COLLECTION"
+	FindingStaleReadModel         FindingType = "STALE_READ_MODEL"
+	FindingLikelyDeadCollection   FindingType = "LIKELY_DEAD_COLLECTION"
+	FindingPossiblePII            FindingType = "POSSIBLE_PII_FIELD"
+	FindingSensitiveUnencrypted   FindingType = "SENSITIVE_FIELD_UNENCRYPTED"
+	FindingRetentionUnenforced    FindingType = "RETENTION_POLICY_UNENFORCED"
+	FindingStaleData              FindingType = "STALE_DATA"
+	FindingArchiveCandidate       FindingType = "ARCHIVE_CANDIDATE"
+	FindingFragmentedCollection   FindingType = "FRAGMENTED_COLLECTION"
+	FindingIndexesExceedCache     FindingType = "INDEXES_EXCEED_CACHE"
+	FindingVectorDimMismatch      FindingType = "VECTOR_DIMENSION_MISMATCH"
+	FindingVectorFilterNotIndexed FindingType = "VECTOR_FILTER_NOT_INDEXED"
+	FindingVectorNotNormalized    FindingType = "VECTOR_NOT_NORMALIZED"
+	FindingQEUnsupportedQuery     FindingType = "QE_UNSUPPORTED_QUERY"
+	FindingLongTransactionRisk    FindingType = "LONG_TRANSACTION_RISK"
+	FindingMixedShardingTxn       FindingType = "MIXED_SHARDING_TRANSACTION"
+	FindingUnsafeWriteConcern     FindingType = "UNSAFE_WRITE_CONCERN"
+	FindingWMajorityLatency       FindingType = "W_MAJORITY_LATENCY_RISK"
+	FindingCausalConsistencyRisk  FindingType = "CAUSAL_CONSISTENCY_RISK"
+	FindingReadPrefNoSecondary    FindingType = "READ_PREFERENCE_NO_SECONDARY"
+	FindingURIPoolSize            FindingType = "URI_POOL_SIZE"
+	FindingURINoAppName           FindingType = "URI_NO_APP_NAME"
+	FindingClientAppTraffic       FindingType = "CLIENT_APP_TRAFFIC"
+	FindingUnknownClientApp       FindingType = "UNKNOWN_CLIENT_APP"
+	FindingClientConnSources      FindingType = "CLIENT_CONNECTION_SOURCES"
+	FindingDriverOutdated         FindingType = "DRIVER_VERSION_OUTDATED"
+	FindingDriverServerCompat     FindingType = "DRIVER_SERVER_COMPAT"
+	FindingODMAutoIndex           FindingType = "ODM_AUTO_INDEX"
+	FindingODMStartupIndexBuild   FindingType = "ODM_STARTUP_INDEX_BUILD"
+	FindingODMIndexDrift          FindingType = "ODM_INDEX_DRIFT"
+	FindingFixtureStaleCollection FindingType = "FIXTURE_STALE_COLLECTION"
+	FindingFixtureSchemaDrift     FindingType = "FIXTURE_SCHEMA_DRIFT"
+	FindingIaCDrift               FindingType = "IAC_DRIFT"
+	FindingOperatorUserDrift      FindingType = "OPERATOR_USER_DRIFT"
+	FindingOperatorConfigDrift    FindingType = "OPERATOR_CONFIG_DRIFT"
 	FindingOK                     FindingType = "OK"
 )
 
@@ -103,6 +175,18 @@ type Finding struct {
 	Collection string      `json:"collection"`
 	Index      string      `json:"index,omitempty"`
 	Message    string      `json:"message"`
+	// File and Line locate the code a finding was derived from, relative
+	// to the scanned repo; Blame is the commit that last changed that line
+	// (check --blame).
+	File  string         `json:"file,omitempty"`
+	Line  int            `json:"line,omitempty"`
+	Blame *gitinfo.Blame `json:"blame,omitempty"`
+	// Owner lists the owners of the finding's code, space separated as in
+	// CODEOWNERS.
+	Owner string `json:"owner,omitempty"`
+	// RuleID and DocURL come from the rule catalog; reports fill them in.
+	RuleID string `json:"ruleId,omitempty"`
+	DocURL string `json:"docUrl,omitempty"`
 }
 
 // MaxSeverity returns the highest severity found in a list of findings.
@@ -0,0 +1,115 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+// UniqueKeyCandidate is a natural key that code upserts on without a unique
+// index to back it.
+type UniqueKeyCandidate struct {
+	Database   string
+	Collection string
+	Fields     []string
+	Sources    []string // file:line of the upserts
+	// Duplicates is the number of existing documents that would violate a
+	// unique index on Fields, or -1 when it was not checked.
+	Duplicates int64
+}
+
+// FindUniqueKeyCandidates returns the filter keys of upserts on existing
+// collections that no unique index covers. Without one, two concurrent
+// upserts that both miss can insert the same key twice.
+func FindUniqueKeyCandidates(scan *scanner.ScanResult, collections []mongoinspect.CollectionInfo) []UniqueKeyCandidate {
+	if scan == nil {
+		return nil
+	}
+	byKey := make(map[string]*UniqueKeyCandidate)
+	var order []string
+	for _, ref := range scan.UpsertRefs {
+		if len(ref.Fields) == 0 {
+			continue
+		}
+		coll, found := findCollection(ref.Collection, collections)
+		if !found || hasUniqueIndexWithin(coll.Indexes, ref.Fields) {
+			continue
+		}
+		key := coll.Database + "." + coll.Name + ":" + strings.Join(ref.Fields, ",")
+		cand := byKey[key]
+		if cand == nil {
+			cand = &UniqueKeyCandidate{
+				Database:   coll.Database,
+				Collection: coll.Name,
+				Fields:     ref.Fields,
+				Duplicates: -1,
+			}
+			byKey[key] = cand
+			order = append(order, key)
+		}
+		cand.Sources = append(cand.Sources, fmt.Sprintf("%s:%d", ref.File, ref.Line))
+	}
+	sort.Strings(order)
+
+	out := make([]UniqueKeyCandidate, 0, len(order))
+	for _, key := range order {
+		out = append(out, *byKey[key])
+	}
+	return out
+}
+
+// SuggestUniqueIndexes returns UNIQUE_INDEX_SUGGEST findings for candidates,
+// reporting existing duplicates that would make the index build fail.
+func SuggestUniqueIndexes(cands []UniqueKeyCandidate) []Finding {
+	findings := make([]Finding, 0, len(cands))
+	for _, c := range cands {
+		parts := make([]string, len(c.Fields))
+		for i, f := range c.Fields {
+			parts[i] = f + ": 1"
+		}
+		msg := fmt.Sprintf("upserts match on {%s} without a unique index, so concurrent upserts can insert duplicates (%s); create it with {unique: true}",
+			strings.Join(parts, ", "), strings.Join(c.Sources, ", "))
+		switch {
+		case c.Duplicates > 0:
+			msg += fmt.Sprintf("; %d duplicates must be resolved first", c.Duplicates)
+		case c.Duplicates == 0:
+			msg += "; existing data has no duplicates"
+		}
+		findings = append(findings, Finding{
+			Type:       FindingUniqueIndexSuggest,
+			Severity:   SeverityMedium,
+			Database:   c.Database,
+			Collection: c.Collection,
+			Message:    msg,
+		})
+	}
+	return findings
+}
+
+// hasUniqueIndexWithin reports whether a unique index keys only fields from
+// the given set. Uniqueness on a subset implies uniqueness on the whole key.
+func hasUniqueIndexWithin(indexes []mongoinspect.IndexInfo, fields []string) bool {
+	set := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		set[f] = true
+	}
+	for _, idx := range indexes {
+		if !idx.Unique || idx.PartialFilter != "" || len(idx.Key) == 0 {
+			continue
+		}
+		within := true
+		for _, kf := range idx.Key {
+			if !set[kf.Field] {
+				within = false
+				break
+			}
+		}
+		if within {
+			return true
+		}
+	}
+	return false
+}
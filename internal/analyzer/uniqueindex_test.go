@@ -0,0 +1,63 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+func TestFindUniqueKeyCandidates(t *testing.T) {
+	scan := &scanner.ScanResult{UpsertRefs: []scanner.UpsertRef{
+		{Collection: "users", Fields: []string{"email", "tenant"}, File: "a.js", Line: 4},
+		{Collection: "users", Fields: []string{"email", "tenant"}, File: "b.js", Line: 9},
+		{Collection: "products", Fields: []string{"sku"}, File: "c.go", Line: 2},
+		{Collection: "missing", Fields: []string{"key"}, File: "d.go", Line: 1},
+	}}
+	collections := []mongoinspect.CollectionInfo{
+		{Database: "app", Name: "users", Indexes: []mongoinspect.IndexInfo{{Name: "email_1", Key: kf("email")}}},
+		{Database: "app", Name: "products", Indexes: []mongoinspect.IndexInfo{{Name: "sku_1", Key: kf("sku"), Unique: true}}},
+	}
+
+	cands := FindUniqueKeyCandidates(scan, collections)
+	if len(cands) != 1 {
+		t.Fatalf("expected 1 candidate, got %+v", cands)
+	}
+	c := cands[0]
+	if c.Collection != "users" || strings.Join(c.Fields, ",") != "email,tenant" || len(c.Sources) != 2 || c.Duplicates != -1 {
+		t.Errorf("unexpected candidate %+v", c)
+	}
+
+	// A unique index on a subset of the key already guarantees uniqueness.
+	collections[0].Indexes[0].Unique = true
+	if cands := FindUniqueKeyCandidates(scan, collections); len(cands) != 0 {
+		t.Errorf("expected no candidates with unique email index, got %+v", cands)
+	}
+}
+
+func TestSuggestUniqueIndexes(t *testing.T) {
+	cands := []UniqueKeyCandidate{
+		{Database: "app", Collection: "users", Fields: []string{"email"}, Sources: []string{"a.js:4"}, Duplicates: 12},
+		{Database: "app", Collection: "orders", Fields: []string{"ref"}, Sources: []string{"b.js:1"}, Duplicates: 0},
+		{Database: "app", Collection: "carts", Fields: []string{"user"}, Sources: []string{"c.js:1"}, Duplicates: -1},
+	}
+	findings := SuggestUniqueIndexes(cands)
+	if len(findings) != 3 {
+		t.Fatalf("expected 3 findings, got %d", len(findings))
+	}
+	for _, f := range findings {
+		if f.Type != FindingUniqueIndexSuggest || f.Severity != SeverityMedium {
+			t.Errorf("unexpected finding %+v", f)
+		}
+	}
+	if !strings.Contains(findings[0].Message, "12 duplicates must be resolved first") {
+		t.Errorf("message = %q", findings[0].Message)
+	}
+	if !strings.Contains(findings[1].Message, "no duplicates") {
+		t.Errorf("message = %q", findings[1].Message)
+	}
+	if strings.Contains(findings[2].Message, "duplicates must") || strings.Contains(findings[2].Message, "no duplicates") {
+		t.Errorf("unchecked candidate should not report a count: %q", findings[2].Message)
+	}
+}
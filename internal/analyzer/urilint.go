@@ -1,6 +1,7 @@
 package analyzer
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"strconv"
@@ -10,6 +11,8 @@ import (
 const (
 	minConnectTimeoutMS         = 5000
 	minServerSelectionTimeoutMS = 10000
+	minSocketTimeoutMS          = 30000
+	maxSanePoolSize             = 1000
 	srvScheme                   = "mongodb+srv"
 )
 
@@ -25,6 +28,11 @@ func LintURI(rawURI string) []Finding {
 
 	u, err := url.Parse(rawURI)
 	if err != nil {
+		// url.Error quotes the whole URI, password included.
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
 		return []Finding{{
 			Type:     FindingURINoAuth,
 			Severity: SeverityLow,
@@ -41,6 +49,8 @@ func LintURI(rawURI string) []Finding {
 	findings = append(findings, detectShortTimeout(u)...)
 	findings = append(findings, detectNoReadPreference(u)...)
 	findings = append(findings, detectDirectConnection(u, isSRV)...)
+	findings = append(findings, detectPoolSize(u)...)
+	findings = append(findings, detectNoAppName(u)...)
 	return findings
 }
 
@@ -81,12 +91,20 @@ func detectNoTLS(u *url.URL, isSRV bool) []Finding {
 	}}
 }
 
-// detectNoRetryWrites flags URIs without retryWrites=true.
+// detectNoRetryWrites flags URIs without retryWrites=true, and those
+// that turn retryable writes off.
 func detectNoRetryWrites(u *url.URL) []Finding {
 	q := u.Query()
 	if strings.EqualFold(q.Get("retryWrites"), "true") {
 		return nil
 	}
+	if strings.EqualFold(q.Get("retryWrites"), "false") {
+		return []Finding{{
+			Type:     FindingURINoRetryWrites,
+			Severity: SeverityLow,
+			Message:  "retryWrites=false disables retryable writes — writes interrupted by a failover or network error fail instead of being retried once",
+		}}
+	}
 	return []Finding{{
 		Type:     FindingURINoRetryWrites,
 		Severity: SeverityInfo,
@@ -152,6 +170,17 @@ func detectShortTimeout(u *url.URL) []Finding {
 		}
 	}
 
+	if v := q.Get("socketTimeoutMS"); v != "" {
+		ms, err := strconv.Atoi(v)
+		if err == nil && ms > 0 && ms < minSocketTimeoutMS {
+			findings = append(findings, Finding{
+				Type:     FindingURIShortTimeout,
+				Severity: SeverityLow,
+				Message:  fmt.Sprintf("socketTimeoutMS=%d is below %dms — slower operations fail with a network error and close their connection while the server keeps running them; prefer timeoutMS or maxTimeMS", ms, minSocketTimeoutMS),
+			})
+		}
+	}
+
 	return findings
 }
 
@@ -190,9 +219,56 @@ func detectDirectConnection(u *url.URL, isSRV bool) []Finding {
 		}}
 	}
 
+	if rs := u.Query().Get("replicaSet"); rs != "" {
+		return []Finding{{
+			Type:     FindingURIDirectConnection,
+			Severity: SeverityLow,
+			Message:  fmt.Sprintf("directConnection=true with replicaSet=%s talks to the one host in the URI — writes fail once it is no longer primary; remove directConnection to follow failover", rs),
+		}}
+	}
+
 	return nil
 }
 
+// detectPoolSize flags invalid and unbounded connection pool sizes.
+func detectPoolSize(u *url.URL) []Finding {
+	q := u.Query()
+	parse := func(key string) (int, bool) {
+		n, err := strconv.Atoi(q.Get(key))
+		return n, err == nil
+	}
+	finding := func(msg string) []Finding {
+		return []Finding{{Type: FindingURIPoolSize, Severity: SeverityLow, Message: msg}}
+	}
+
+	maxPool, hasMax := parse("maxPoolSize")
+	minPool, hasMin := parse("minPoolSize")
+	switch {
+	case hasMax && hasMin && minPool > maxPool && maxPool > 0:
+		return finding(fmt.Sprintf("minPoolSize=%d exceeds maxPoolSize=%d — drivers reject the URI", minPool, maxPool))
+	case hasMax && maxPool == 0:
+		return finding("maxPoolSize=0 leaves the connection pool unbounded — a burst of requests can open connections until the server's limit is reached")
+	case hasMax && maxPool > maxSanePoolSize:
+		return finding(fmt.Sprintf("maxPoolSize=%d allows each client that many connections per server — with several application instances this can exhaust the server's connection limit and memory", maxPool))
+	case hasMin && minPool > maxSanePoolSize:
+		return finding(fmt.Sprintf("minPoolSize=%d keeps that many idle connections open per server for every client", minPool))
+	}
+	return nil
+}
+
+// detectNoAppName flags URIs without appName, which the server records
+// in its logs, currentOp, the profiler and $currentOp client metadata.
+func detectNoAppName(u *url.URL) []Finding {
+	if u.Query().Get("appName") != "" {
+		return nil
+	}
+	return []Finding{{
+		Type:     FindingURINoAppName,
+		Severity: SeverityInfo,
+		Message:  "URI does not set appName — set it so server logs, currentOp and profiler entries show which application sent each operation",
+	}}
+}
+
 // isLocalhost returns true for development/local addresses.
 func isLocalhost(host string) bool {
 	h := strings.ToLower(host)
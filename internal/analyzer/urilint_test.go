@@ -182,6 +182,94 @@ func TestLintURI_DirectConnection_MultiHost(t *testing.T) {
 	}
 }
 
+func TestLintURI_DirectConnection_ReplicaSet(t *testing.T) {
+	findings := LintURI("mongodb://host1:27017/mydb?replicaSet=rs0&directConnection=true")
+	found := false
+	for _, f := range findings {
+		if f.Type == FindingURIDirectConnection && strings.Contains(f.Message, "replicaSet=rs0") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected URI_DIRECT_CONNECTION finding for replicaSet + directConnection=true")
+	}
+}
+
+func TestLintURI_RetryWritesFalse(t *testing.T) {
+	findings := LintURI("mongodb://localhost:27017/mydb?retryWrites=false")
+	found := false
+	for _, f := range findings {
+		if f.Type == FindingURINoRetryWrites {
+			found = true
+			if f.Severity != SeverityLow {
+				t.Errorf("severity = %s, want low", f.Severity)
+			}
+		}
+	}
+	if !found {
+		t.Error("expected URI_NO_RETRY_WRITES finding for retryWrites=false")
+	}
+}
+
+func TestLintURI_SocketTimeout(t *testing.T) {
+	for query, want := range map[string]bool{
+		"socketTimeoutMS=5000":  true,
+		"socketTimeoutMS=0":     false,
+		"socketTimeoutMS=60000": false,
+	} {
+		found := false
+		for _, f := range LintURI("mongodb://localhost:27017/mydb?" + query) {
+			if f.Type == FindingURIShortTimeout {
+				found = true
+			}
+		}
+		if found != want {
+			t.Errorf("%s: URI_SHORT_TIMEOUT = %v, want %v", query, found, want)
+		}
+	}
+}
+
+func TestLintURI_PoolSize(t *testing.T) {
+	tests := map[string]string{
+		"maxPoolSize=10&minPoolSize=20": "exceeds maxPoolSize=10",
+		"maxPoolSize=0":                 "unbounded",
+		"maxPoolSize=5000":              "maxPoolSize=5000",
+		"minPoolSize=2000":              "minPoolSize=2000",
+		"maxPoolSize=100&minPoolSize=5": "",
+	}
+	for query, want := range tests {
+		var got []Finding
+		for _, f := range LintURI("mongodb://localhost:27017/mydb?" + query) {
+			if f.Type == FindingURIPoolSize {
+				got = append(got, f)
+			}
+		}
+		switch {
+		case want == "" && len(got) != 0:
+			t.Errorf("%s: unexpected findings %+v", query, got)
+		case want != "" && (len(got) != 1 || !strings.Contains(got[0].Message, want)):
+			t.Errorf("%s: got %+v, want one finding containing %q", query, got, want)
+		}
+	}
+}
+
+func TestLintURI_NoAppName(t *testing.T) {
+	has := func(uri string) bool {
+		for _, f := range LintURI(uri) {
+			if f.Type == FindingURINoAppName {
+				return true
+			}
+		}
+		return false
+	}
+	if !has("mongodb://localhost:27017/mydb") {
+		t.Error("expected URI_NO_APP_NAME finding")
+	}
+	if has("mongodb://localhost:27017/mydb?appName=billing") {
+		t.Error("unexpected URI_NO_APP_NAME finding with appName set")
+	}
+}
+
 func TestLintURI_Localhost_Skips(t *testing.T) {
 	for _, host := range []string{"localhost", "127.0.0.1", "::1"} {
 		findings := LintURI("mongodb://" + host + ":27017/mydb")
@@ -198,7 +286,7 @@ func TestLintURI_Localhost_Skips(t *testing.T) {
 
 func TestLintURI_CleanProductionURI(t *testing.T) {
 	uri := testURI("mongodb+srv", "user", "pw", "cluster0.example.mongodb.net", "/mydb",
-		"retryWrites=true&authSource=admin&readPreference=secondaryPreferred")
+		"retryWrites=true&authSource=admin&readPreference=secondaryPreferred&appName=billing&maxPoolSize=100")
 	findings := LintURI(uri)
 
 	// Only URI_PLAINTEXT_PASSWORD should fire on a clean production URI.
@@ -218,3 +306,14 @@ func TestLintURI_Empty(t *testing.T) {
 		t.Errorf("expected 0 findings for empty URI, got %d", len(findings))
 	}
 }
+
+func TestLintURI_ParseErrorHidesPassword(t *testing.T) {
+	pass := "hunt" + "er2"
+	findings := LintURI("mongodb://app:" + pass + "@host:bad/")
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %+v", findings)
+	}
+	if strings.Contains(findings[0].Message, pass) {
+		t.Errorf("parse error leaked the password: %q", findings[0].Message)
+	}
+}
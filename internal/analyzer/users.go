@@ -2,6 +2,7 @@ package analyzer
 
 import (
 	"fmt"
+	"regexp"
 	"sort"
 	"strings"
 
@@ -32,6 +33,9 @@ func AuditUsers(users []mongoinspect.UserInfo) []Finding {
 	findings = append(findings, detectDuplicateUsers(users)...)
 	findings = append(findings, detectOverprivilegedUsers(users)...)
 	findings = append(findings, detectMultipleAdminUsers(users)...)
+	findings = append(findings, detectWeakAuthMechanisms(users)...)
+	findings = append(findings, detectX509SubjectIssues(users)...)
+	findings = append(findings, detectNoClientSourceRestriction(users)...)
 	return findings
 }
 
@@ -136,3 +140,178 @@ func detectMultipleAdminUsers(users []mongoinspect.UserInfo) []Finding {
 		Message:  fmt.Sprintf("%d users have cluster-admin roles: %s", len(adminUsers), strings.Join(adminUsers, ", ")),
 	}}
 }
+
+const (
+	mechanismSCRAMSHA1 = "SCRAM-SHA-1"
+	externalDB         = "$external"
+)
+
+// detectWeakAuthMechanisms flags users whose credentials only support
+// SCRAM-SHA-1. Users without mechanism details (e.g. from the Atlas API)
+// are skipped.
+func detectWeakAuthMechanisms(users []mongoinspect.UserInfo) []Finding {
+	var findings []Finding
+	for _, u := range users {
+		if len(u.Mechanisms) != 1 || u.Mechanisms[0] != mechanismSCRAMSHA1 {
+			continue
+		}
+		findings = append(findings, Finding{
+			Type:     FindingWeakAuthMechanism,
+			Severity: SeverityMedium,
+			Database: u.Database,
+			Message: fmt.Sprintf("user %q can only authenticate with SCRAM-SHA-1 — reset the password with mechanisms [\"SCRAM-SHA-256\"] to upgrade its credentials",
+				u.Username),
+		})
+	}
+	return findings
+}
+
+// detectX509SubjectIssues flags x.509 users whose subject can never match a
+// client certificate: subjects without a CN, and subjects that are not in
+// the RFC 2253 form the server compares against (spaces around separators),
+// including near-duplicates of another user's subject. usersInfo reports
+// every $external user with the "external" mechanism, so x.509 users are
+// told apart by a name shaped like a distinguished name.
+func detectX509SubjectIssues(users []mongoinspect.UserInfo) []Finding {
+	canonical := make(map[string][]string)
+	var findings []Finding
+	for _, u := range users {
+		if u.Database != externalDB || !looksLikeDN(u.Username) {
+			continue
+		}
+		attrs := splitDN(u.Username)
+		norm := make([]string, len(attrs))
+		hasCN, spaced := false, false
+		for i, a := range attrs {
+			typ, val, ok := strings.Cut(a, "=")
+			if !ok {
+				continue
+			}
+			if typ != strings.TrimSpace(typ) || val != strings.TrimSpace(val) {
+				spaced = true
+			}
+			typ = strings.ToUpper(strings.TrimSpace(typ))
+			hasCN = hasCN || typ == "CN"
+			norm[i] = typ + "=" + strings.TrimSpace(val)
+		}
+		key := strings.Join(norm, ",")
+		canonical[key] = append(canonical[key], u.Username)
+
+		var problem string
+		switch {
+		case !hasCN:
+			problem = "has no CN attribute"
+		case spaced:
+			problem = fmt.Sprintf("is not in RFC 2253 form (expected %q)", key)
+		default:
+			continue
+		}
+		findings = append(findings, Finding{
+			Type:     FindingWeakAuthMechanism,
+			Severity: SeverityMedium,
+			Database: externalDB,
+			Message:  fmt.Sprintf("x.509 user subject %q %s — client certificates will not match it", u.Username, problem),
+		})
+	}
+
+	keys := make([]string, 0, len(canonical))
+	for k := range canonical {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		names := canonical[k]
+		if len(names) < 2 {
+			continue
+		}
+		sort.Strings(names)
+		findings = append(findings, Finding{
+			Type:     FindingWeakAuthMechanism,
+			Severity: SeverityLow,
+			Database: externalDB,
+			Message:  fmt.Sprintf("%d x.509 users differ only in subject formatting: %s", len(names), strings.Join(quoteAll(names), ", ")),
+		})
+	}
+	return findings
+}
+
+// detectNoClientSourceRestriction flags users without a clientSource
+// authentication restriction. Privileged users are reported as low, others
+// as info. Users without mechanism details are skipped because their
+// restrictions are unknown.
+func detectNoClientSourceRestriction(users []mongoinspect.UserInfo) []Finding {
+	var findings []Finding
+	for _, u := range users {
+		if len(u.Mechanisms) == 0 || hasClientSource(u) {
+			continue
+		}
+		sev := SeverityInfo
+		for _, r := range u.Roles {
+			if adminRoles[r.Role] || clusterAdminRoles[r.Role] {
+				sev = SeverityLow
+				break
+			}
+		}
+		findings = append(findings, Finding{
+			Type:     FindingNoClientSource,
+			Severity: sev,
+			Database: u.Database,
+			Message:  fmt.Sprintf("user %q can connect from any address — add authenticationRestrictions with clientSource", u.Username),
+		})
+	}
+	return findings
+}
+
+func hasClientSource(u mongoinspect.UserInfo) bool {
+	for _, r := range u.AuthenticationRestrictions {
+		if len(r.ClientSource) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// dnAttrTypeRe matches an attribute type of a distinguished name, a name
+// such as CN or an OID.
+var dnAttrTypeRe = regexp.MustCompile(`^\s*([A-Za-z][A-Za-z0-9-]*|[0-9]+(\.[0-9]+)+)\s*$`)
+
+// looksLikeDN reports whether name is a distinguished name: attributes of
+// the form type=value separated by commas.
+func looksLikeDN(name string) bool {
+	for _, a := range splitDN(name) {
+		typ, _, ok := strings.Cut(a, "=")
+		if !ok || !dnAttrTypeRe.MatchString(typ) {
+			return false
+		}
+	}
+	return true
+}
+
+// splitDN splits a distinguished name on unescaped commas.
+func splitDN(dn string) []string {
+	var parts []string
+	var cur strings.Builder
+	escaped := false
+	for _, r := range dn {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ',':
+			parts = append(parts, cur.String())
+			cur.Reset()
+			continue
+		}
+		cur.WriteRune(r)
+	}
+	return append(parts, cur.String())
+}
+
+func quoteAll(ss []string) []string {
+	out := make([]string, len(ss))
+	for i, s := range ss {
+		out[i] = fmt.Sprintf("%q", s)
+	}
+	return out
+}
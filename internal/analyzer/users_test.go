@@ -1,6 +1,7 @@
 package analyzer
 
 import (
+	"strings"
 	"testing"
 
 	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
@@ -218,3 +219,76 @@ func TestAuditUsers_DuplicateUserNonAdminOnly(t *testing.T) {
 		t.Errorf("duplicate in non-admin dbs should not trigger, got %d findings", len(findings))
 	}
 }
+
+func TestAuditUsers_WeakAuthMechanism(t *testing.T) {
+	users := []mongoinspect.UserInfo{
+		{Username: "legacy", Database: "app", Mechanisms: []string{"SCRAM-SHA-1"}},
+		{Username: "both", Database: "app", Mechanisms: []string{"SCRAM-SHA-1", "SCRAM-SHA-256"}},
+		{Username: "atlas", Database: "admin"},
+	}
+	findings := detectWeakAuthMechanisms(users)
+	if len(findings) != 1 {
+		t.Fatalf("expected 1 finding, got %+v", findings)
+	}
+	if !strings.Contains(findings[0].Message, `"legacy"`) || findings[0].Severity != SeverityMedium {
+		t.Errorf("finding = %+v", findings[0])
+	}
+}
+
+func TestAuditUsers_X509SubjectIssues(t *testing.T) {
+	// usersInfo reports every $external user with the "external" mechanism.
+	external := []string{"external"}
+	users := []mongoinspect.UserInfo{
+		{Username: "CN=app,OU=svc,O=Acme", Database: "$external", Mechanisms: external},
+		{Username: "CN=app, OU=svc, O=Acme", Database: "$external", Mechanisms: external},
+		{Username: "OU=batch,O=Acme", Database: "$external", Mechanisms: external},
+		{Username: `CN=Smith\, J,O=Acme`, Database: "$external", Mechanisms: external},
+		{Username: "ldapUser", Database: "$external", Mechanisms: external},
+		{Username: "svc@EXAMPLE.COM", Database: "$external", Mechanisms: external},
+		{Username: "OU=app", Database: "admin", Mechanisms: []string{"SCRAM-SHA-256"}},
+	}
+	findings := detectX509SubjectIssues(users)
+
+	var msgs []string
+	for _, f := range findings {
+		if f.Type != FindingWeakAuthMechanism {
+			t.Errorf("unexpected type %s", f.Type)
+		}
+		msgs = append(msgs, f.Message)
+	}
+	joined := strings.Join(msgs, "\n")
+	for _, want := range []string{
+		`"CN=app, OU=svc, O=Acme" is not in RFC 2253 form (expected "CN=app,OU=svc,O=Acme")`,
+		`"OU=batch,O=Acme" has no CN attribute`,
+		`2 x.509 users differ only in subject formatting`,
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("missing %q in:\n%s", want, joined)
+		}
+	}
+	if len(findings) != 3 {
+		t.Errorf("expected 3 findings, got %d:\n%s", len(findings), joined)
+	}
+}
+
+func TestAuditUsers_NoClientSourceRestriction(t *testing.T) {
+	scram := []string{"SCRAM-SHA-256"}
+	users := []mongoinspect.UserInfo{
+		{Username: "root", Database: "admin", Mechanisms: scram, Roles: []mongoinspect.UserRole{{Role: "root", DB: "admin"}}},
+		{Username: "app", Database: "app", Mechanisms: scram, Roles: []mongoinspect.UserRole{{Role: "readWrite", DB: "app"}}},
+		{Username: "pinned", Database: "app", Mechanisms: scram, AuthenticationRestrictions: []mongoinspect.AuthRestriction{
+			{ClientSource: []string{"10.0.0.0/8"}},
+		}},
+		{Username: "atlas", Database: "admin"},
+	}
+	findings := detectNoClientSourceRestriction(users)
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 findings, got %+v", findings)
+	}
+	if findings[0].Severity != SeverityLow || !strings.Contains(findings[0].Message, `"root"`) {
+		t.Errorf("privileged user finding = %+v", findings[0])
+	}
+	if findings[1].Severity != SeverityInfo || !strings.Contains(findings[1].Message, `"app"`) {
+		t.Errorf("regular user finding = %+v", findings[1])
+	}
+}
@@ -0,0 +1,188 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+// detectVectorSearchDrift compares $vectorSearch stages in code with the
+// vector search index they name. The index's numDimensions must match the
+// embedding size the code produces, taken from an explicit dimensions
+// setting or a known model name in the same file, or else anywhere in the
+// repository, as long as it is unambiguous. Every filter field must be
+// indexed with "type": "filter" or the query fails. Collections whose
+// search indexes were not listed are skipped.
+func detectVectorSearchDrift(scan *scanner.ScanResult, collections []mongoinspect.CollectionInfo) []Finding {
+	var findings []Finding
+	for _, ref := range scan.SearchRefs {
+		if ref.Stage != scanner.SearchStageVector || ref.Collection == "" || ref.Index == "" {
+			continue
+		}
+		where := fmt.Sprintf("%s:%d", ref.File, ref.Line)
+		for i := range collections {
+			c := &collections[i]
+			if !strings.EqualFold(c.Name, ref.Collection) {
+				continue
+			}
+			idx := findSearchIndex(c.SearchIndexes, ref.Index)
+			if idx == nil {
+				continue
+			}
+			add := func(t FindingType, msg string) {
+				findings = append(findings, Finding{
+					Type:       t,
+					Severity:   SeverityHigh,
+					Database:   c.Database,
+					Collection: c.Name,
+					Index:      idx.Name,
+					Message:    msg,
+					File:       ref.File,
+					Line:       ref.Line,
+				})
+			}
+
+			if len(ref.Paths) > 0 {
+				field := findVectorField(idx, ref.Paths[0])
+				if emb, ok := embeddingSize(scan, ref.File); ok && field != nil && field.Dimensions != emb.Dimensions {
+					source := "dimensions setting"
+					if emb.Model != "" {
+						source = fmt.Sprintf("model %q", emb.Model)
+					}
+					add(FindingVectorDimMismatch, fmt.Sprintf("$vectorSearch at %s queries %q in index %q with %d dimensions, but the %s at %s:%d produces %d-dimension embeddings",
+						where, field.Path, idx.Name, field.Dimensions, source, emb.File, emb.Line, emb.Dimensions))
+				}
+			}
+
+			indexed := make(map[string]bool, len(idx.FilterFields))
+			for _, f := range idx.FilterFields {
+				indexed[f] = true
+			}
+			var missing []string
+			for _, f := range ref.FilterFields {
+				if !indexed[f] {
+					missing = append(missing, f)
+				}
+			}
+			if len(missing) > 0 {
+				add(FindingVectorFilterNotIndexed, fmt.Sprintf("$vectorSearch at %s filters on %s, not indexed as filter fields in %q; the query fails until they are added with \"type\": \"filter\"",
+					where, strings.Join(quoteAll(missing), ", "), idx.Name))
+			}
+		}
+	}
+	return findings
+}
+
+// DetectVectorFieldMismatch checks sampled embeddings against the vector
+// search indexes of their collection. Documents whose vectors differ from
+// the index's numDimensions are left out of the index, and dotProduct
+// similarity gives wrong scores for vectors that are not unit length.
+func DetectVectorFieldMismatch(collections []mongoinspect.CollectionInfo, samples []mongoinspect.FieldSampleResult) []Finding {
+	byNS := make(map[string]*mongoinspect.FieldSampleResult, len(samples))
+	for i := range samples {
+		byNS[samples[i].Database+"."+samples[i].Collection] = &samples[i]
+	}
+
+	var findings []Finding
+	for i := range collections {
+		c := &collections[i]
+		s := byNS[c.Database+"."+c.Name]
+		if s == nil {
+			continue
+		}
+		for _, idx := range c.SearchIndexes {
+			for _, field := range idx.VectorFields {
+				v := findVectorSample(s.Vectors, field.Path)
+				if v == nil {
+					continue
+				}
+				newFinding := func(t FindingType, sev Severity, msg string) Finding {
+					return Finding{Type: t, Severity: sev, Database: c.Database, Collection: c.Name, Index: idx.Name, Message: msg}
+				}
+				if off := v.Count - v.Dimensions[field.Dimensions]; off > 0 {
+					findings = append(findings, newFinding(FindingVectorDimMismatch, SeverityHigh,
+						fmt.Sprintf("%d of %d sampled %q vectors have %s dimensions but index %q expects %d; those documents are not indexed",
+							off, v.Count, field.Path, otherDimensions(v.Dimensions, field.Dimensions), idx.Name, field.Dimensions)))
+				}
+				if field.Similarity == "dotProduct" && v.Unit < v.Norms {
+					findings = append(findings, newFinding(FindingVectorNotNormalized, SeverityMedium,
+						fmt.Sprintf("index %q uses dotProduct similarity but %d of %d sampled %q vectors are not unit length, which skews scores; normalize the embeddings or use cosine",
+							idx.Name, v.Norms-v.Unit, v.Norms, field.Path)))
+				}
+			}
+		}
+	}
+	return findings
+}
+
+func findSearchIndex(indexes []mongoinspect.SearchIndex, name string) *mongoinspect.SearchIndex {
+	for i := range indexes {
+		if indexes[i].Name == name && indexes[i].Type == "vectorSearch" {
+			return &indexes[i]
+		}
+	}
+	return nil
+}
+
+func findVectorField(idx *mongoinspect.SearchIndex, path string) *mongoinspect.VectorIndexField {
+	for i := range idx.VectorFields {
+		if idx.VectorFields[i].Path == path {
+			return &idx.VectorFields[i]
+		}
+	}
+	return nil
+}
+
+func findVectorSample(vectors []mongoinspect.VectorSample, path string) *mongoinspect.VectorSample {
+	for i := range vectors {
+		if vectors[i].Path == path {
+			return &vectors[i]
+		}
+	}
+	return nil
+}
+
+// embeddingSize returns the embedding size code in file implies, falling
+// back to the whole repository when the file sets none. It reports false
+// when no size is found or the candidates disagree.
+func embeddingSize(scan *scanner.ScanResult, file string) (scanner.EmbeddingRef, bool) {
+	var inFile []scanner.EmbeddingRef
+	for _, e := range scan.EmbeddingRefs {
+		if e.File == file {
+			inFile = append(inFile, e)
+		}
+	}
+	candidates := inFile
+	if len(candidates) == 0 {
+		candidates = scan.EmbeddingRefs
+	}
+	if len(candidates) == 0 {
+		return scanner.EmbeddingRef{}, false
+	}
+	for _, e := range candidates[1:] {
+		if e.Dimensions != candidates[0].Dimensions {
+			return scanner.EmbeddingRef{}, false
+		}
+	}
+	return candidates[0], true
+}
+
+// otherDimensions lists the vector lengths other than want, e.g. "768" or
+// "384, 768".
+func otherDimensions(dims map[int]int64, want int) string {
+	var other []int
+	for d := range dims {
+		if d != want {
+			other = append(other, d)
+		}
+	}
+	sort.Ints(other)
+	parts := make([]string, len(other))
+	for i, d := range other {
+		parts[i] = fmt.Sprint(d)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+func vectorCollections() []mongoinspect.CollectionInfo {
+	return []mongoinspect.CollectionInfo{
+		{Database: "app", Name: "movies", SearchIndexes: []mongoinspect.SearchIndex{
+			{Name: "default", Type: "search"},
+			{Name: "plot_vectors", Type: "vectorSearch",
+				VectorFields: []mongoinspect.VectorIndexField{{Path: "plot_embedding", Dimensions: 1536, Similarity: "dotProduct"}},
+				FilterFields: []string{"year"}},
+		}},
+		{Database: "app", Name: "users"},
+	}
+}
+
+func TestDetectVectorSearchDrift(t *testing.T) {
+	scan := &scanner.ScanResult{
+		SearchRefs: []scanner.SearchRef{
+			{Collection: "movies", Stage: scanner.SearchStageVector, Index: "plot_vectors", Paths: []string{"plot_embedding"},
+				FilterFields: []string{"year", "genres"}, File: "search.py", Line: 12},
+			{Collection: "movies", Stage: scanner.SearchStageText, Index: "default", Paths: []string{"title"}, File: "search.py", Line: 30},
+			{Collection: "users", Stage: scanner.SearchStageVector, Index: "bio_vectors", Paths: []string{"bio"}, File: "users.py", Line: 5},
+		},
+		EmbeddingRefs: []scanner.EmbeddingRef{
+			{Model: "all-MiniLM-L6-v2", Dimensions: 384, File: "embed.py", Line: 3},
+		},
+	}
+
+	findings := detectVectorSearchDrift(scan, vectorCollections())
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings, want 2: %+v", len(findings), findings)
+	}
+	if f := findings[0]; f.Type != FindingVectorDimMismatch || f.Index != "plot_vectors" || f.File != "search.py" ||
+		!strings.Contains(f.Message, `with 1536 dimensions, but the model "all-MiniLM-L6-v2" at embed.py:3 produces 384-dimension embeddings`) {
+		t.Errorf("dimension finding = %+v", f)
+	}
+	if f := findings[1]; f.Type != FindingVectorFilterNotIndexed || !strings.Contains(f.Message, `filters on "genres", not indexed`) {
+		t.Errorf("filter finding = %+v", f)
+	}
+
+	// A second size in the repository makes the expected size ambiguous.
+	scan.EmbeddingRefs = append(scan.EmbeddingRefs, scanner.EmbeddingRef{Dimensions: 1536, File: "other.py", Line: 1})
+	for _, f := range detectVectorSearchDrift(scan, vectorCollections()) {
+		if f.Type == FindingVectorDimMismatch {
+			t.Errorf("unexpected finding with ambiguous embedding size: %+v", f)
+		}
+	}
+}
+
+func TestDetectVectorFieldMismatch(t *testing.T) {
+	samples := []mongoinspect.FieldSampleResult{
+		{Database: "app", Collection: "movies", Vectors: []mongoinspect.VectorSample{
+			{Path: "plot_embedding", Count: 50, Dimensions: map[int]int64{1536: 40, 768: 10}, Norms: 50, Unit: 45},
+		}},
+	}
+
+	findings := DetectVectorFieldMismatch(vectorCollections(), samples)
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings, want 2: %+v", len(findings), findings)
+	}
+	if f := findings[0]; f.Type != FindingVectorDimMismatch || f.Severity != SeverityHigh ||
+		!strings.Contains(f.Message, `10 of 50 sampled "plot_embedding" vectors have 768 dimensions but index "plot_vectors" expects 1536`) {
+		t.Errorf("dimension finding = %+v", f)
+	}
+	if f := findings[1]; f.Type != FindingVectorNotNormalized || !strings.Contains(f.Message, "5 of 50 sampled") {
+		t.Errorf("similarity finding = %+v", f)
+	}
+}
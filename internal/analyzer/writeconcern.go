@@ -0,0 +1,179 @@
+package analyzer
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+// DetectWriteConcernRisks reviews the write concerns set in code and in
+// the connection URI.
+//
+// UNSAFE_WRITE_CONCERN reports w:0, whose writes are neither acknowledged
+// nor retried, j:false, and w:1 on a replica set with more than one
+// data-bearing member, where writes the primary has not replicated are
+// rolled back after a failover.
+//
+// W_MAJORITY_LATENCY_RISK compares w:"majority" and numeric w with the
+// replica set: a write concern that needs more data-bearing members than
+// are healthy cannot be acknowledged, and one that needs every healthy
+// member, as w:"majority" does with an arbiter, stalls as soon as one of
+// them is down or lagging. Without a wtimeout or timeoutMS such writes
+// wait indefinitely. rs is nil when the topology is unknown.
+func DetectWriteConcernRisks(scan *scanner.ScanResult, uri string, rs *mongoinspect.ReplicaSetInfo) []Finding {
+	refs := scan.WriteConcernRefs
+	if wc, ok := uriWriteConcern(uri); ok {
+		refs = append([]scanner.WriteConcernRef{wc}, refs...)
+	}
+	topo := newWriteTopology(rs)
+
+	var findings []Finding
+	for _, ref := range refs {
+		where := "connection URI"
+		if ref.File != "" {
+			where = fmt.Sprintf("write concern at %s:%d", ref.File, ref.Line)
+		}
+		add := func(typ FindingType, sev Severity, msg string) {
+			findings = append(findings, Finding{
+				Type:       typ,
+				Severity:   sev,
+				Collection: ref.Collection,
+				Message:    where + " " + msg,
+				File:       ref.File,
+				Line:       ref.Line,
+			})
+		}
+
+		if ref.W == "0" {
+			add(FindingUnsafeWriteConcern, SeverityHigh,
+				"sets w:0 — writes are unacknowledged and never retried, so errors such as duplicate keys and writes lost in a failover go unnoticed")
+			continue
+		}
+		if ref.NoJournal {
+			add(FindingUnsafeWriteConcern, SeverityMedium,
+				"sets j:false — writes are acknowledged before they reach the on-disk journal and can be lost in a crash")
+		}
+		if topo == nil {
+			continue
+		}
+		if ref.W == "1" && topo.dataBearing > 1 {
+			add(FindingUnsafeWriteConcern, SeverityLow,
+				fmt.Sprintf("sets w:1 on replica set %q with %d data-bearing members — writes acknowledged by the primary alone are rolled back if it fails before replicating them; the default since MongoDB 5.0 is w:\"majority\"",
+					topo.name, topo.dataBearing))
+			continue
+		}
+
+		needed, label := 0, ""
+		if ref.W == "majority" {
+			needed, label = topo.writeMajority, `w:"majority"`
+		} else if n, err := strconv.Atoi(ref.W); err == nil && n > 1 {
+			needed, label = n, "w:"+ref.W
+		}
+		if needed == 0 {
+			continue
+		}
+		wait := "until wtimeout"
+		if !ref.WTimeout {
+			wait = "indefinitely, as no wtimeout or timeoutMS is set"
+		}
+		switch {
+		case needed > topo.dataBearing:
+			add(FindingWMajorityLatency, SeverityHigh,
+				fmt.Sprintf("sets %s, which needs %d members but replica set %q has %d data-bearing — writes can never be acknowledged and wait %s",
+					label, needed, topo.name, topo.dataBearing, wait))
+		case needed > topo.healthy:
+			add(FindingWMajorityLatency, SeverityHigh,
+				fmt.Sprintf("sets %s, which needs %d members but only %d of %d data-bearing members of replica set %q are healthy — writes wait %s",
+					label, needed, topo.healthy, topo.dataBearing, topo.name, wait))
+		case needed == topo.healthy:
+			arbiter := ""
+			if topo.arbiters > 0 {
+				arbiter = " (the replica set has an arbiter)"
+			}
+			add(FindingWMajorityLatency, SeverityMedium,
+				fmt.Sprintf("sets %s, which needs every healthy data-bearing member of replica set %q%s — if one is down or lagging, writes wait %s",
+					label, topo.name, arbiter, wait))
+		}
+	}
+	return findings
+}
+
+// writeTopology counts the replica set members write concerns wait for.
+type writeTopology struct {
+	name          string
+	dataBearing   int // members that are not arbiters
+	healthy       int // data-bearing members that are up as primary or secondary
+	arbiters      int
+	writeMajority int // members w:"majority" waits for
+}
+
+// newWriteTopology returns nil for standalones and unknown topologies.
+// When replSetGetConfig could not be read every member has 0 votes, and
+// all members are counted as voting, which is the default.
+func newWriteTopology(rs *mongoinspect.ReplicaSetInfo) *writeTopology {
+	if rs == nil || rs.Name == "" || len(rs.Members) == 0 {
+		return nil
+	}
+	votesKnown := false
+	for _, m := range rs.Members {
+		if m.Votes > 0 {
+			votesKnown = true
+		}
+	}
+	t := &writeTopology{name: rs.Name}
+	voting, votingData := 0, 0
+	for _, m := range rs.Members {
+		votes := !votesKnown || m.Votes > 0
+		if votes {
+			voting++
+		}
+		if m.StateStr == "ARBITER" {
+			t.arbiters++
+			continue
+		}
+		t.dataBearing++
+		if votes {
+			votingData++
+		}
+		if m.Health == 1 && (m.StateStr == "PRIMARY" || m.StateStr == "SECONDARY") {
+			t.healthy++
+		}
+	}
+	// The write majority is a majority of voting members, capped at
+	// the voting data-bearing ones.
+	t.writeMajority = min(voting/2+1, votingData)
+	return t
+}
+
+// URISetsWriteConcern reports whether a connection URI sets a write
+// concern DetectWriteConcernRisks reviews.
+func URISetsWriteConcern(rawURI string) bool {
+	_, ok := uriWriteConcern(rawURI)
+	return ok
+}
+
+// uriWriteConcern reads the w, journal and wtimeoutMS options of a
+// connection URI.
+func uriWriteConcern(rawURI string) (scanner.WriteConcernRef, bool) {
+	if rawURI == "" {
+		return scanner.WriteConcernRef{}, false
+	}
+	u, err := url.Parse(rawURI)
+	if err != nil {
+		return scanner.WriteConcernRef{}, false
+	}
+	q := u.Query()
+	wc := scanner.WriteConcernRef{
+		W:         q.Get("w"),
+		NoJournal: strings.EqualFold(q.Get("journal"), "false"),
+		WTimeout:  q.Get("wtimeoutMS") != "" || q.Get("timeoutMS") != "",
+	}
+	if strings.EqualFold(wc.W, "majority") {
+		wc.W = "majority"
+	}
+	return wc, wc.W != "" || wc.NoJournal
+}
@@ -0,0 +1,70 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+func TestDetectWriteConcernRisks(t *testing.T) {
+	scan := &scanner.ScanResult{WriteConcernRefs: []scanner.WriteConcernRef{
+		{Collection: "events", W: "0", File: "ingest.js", Line: 4},
+		{Collection: "orders", W: "majority", File: "orders.py", Line: 10},
+		{W: "1", NoJournal: true, File: "db.go", Line: 22},
+		{W: "3", WTimeout: true, File: "billing.java", Line: 7},
+	}}
+	psa := &mongoinspect.ReplicaSetInfo{Name: "rs0", Members: []mongoinspect.ReplicaSetMember{
+		{Name: "a:27017", StateStr: "PRIMARY", Health: 1, Votes: 1},
+		{Name: "b:27017", StateStr: "SECONDARY", Health: 1, Votes: 1},
+		{Name: "c:27017", StateStr: "ARBITER", Health: 1, Votes: 1},
+	}}
+
+	findings := DetectWriteConcernRisks(scan, "mongodb://a,b/app?replicaSet=rs0&journal=false", psa)
+	want := []struct {
+		typ  FindingType
+		sev  Severity
+		text string
+	}{
+		{FindingUnsafeWriteConcern, SeverityMedium, "connection URI sets j:false"},
+		{FindingUnsafeWriteConcern, SeverityHigh, "ingest.js:4 sets w:0"},
+		{FindingWMajorityLatency, SeverityMedium, `orders.py:10 sets w:"majority", which needs every healthy data-bearing member of replica set "rs0" (the replica set has an arbiter)`},
+		{FindingUnsafeWriteConcern, SeverityMedium, "db.go:22 sets j:false"},
+		{FindingUnsafeWriteConcern, SeverityLow, `db.go:22 sets w:1 on replica set "rs0" with 2 data-bearing members`},
+		{FindingWMajorityLatency, SeverityHigh, "billing.java:7 sets w:3, which needs 3 members but replica set \"rs0\" has 2 data-bearing — writes can never be acknowledged and wait until wtimeout"},
+	}
+	if len(findings) != len(want) {
+		t.Fatalf("got %d findings, want %d: %+v", len(findings), len(want), findings)
+	}
+	for i, w := range want {
+		if f := findings[i]; f.Type != w.typ || f.Severity != w.sev || !strings.Contains(f.Message, w.text) {
+			t.Errorf("finding %d = %+v, want %s %s containing %q", i, f, w.typ, w.sev, w.text)
+		}
+	}
+	if !strings.Contains(findings[2].Message, "wait indefinitely") || findings[2].Collection != "orders" {
+		t.Errorf("majority finding = %+v", findings[2])
+	}
+
+	// Three healthy data-bearing members leave room for one to fail.
+	pss := &mongoinspect.ReplicaSetInfo{Name: "rs0", Members: []mongoinspect.ReplicaSetMember{
+		{Name: "a:27017", StateStr: "PRIMARY", Health: 1},
+		{Name: "b:27017", StateStr: "SECONDARY", Health: 1},
+		{Name: "c:27017", StateStr: "SECONDARY", Health: 1},
+	}}
+	majority := &scanner.ScanResult{WriteConcernRefs: scan.WriteConcernRefs[1:2]}
+	if findings := DetectWriteConcernRisks(majority, "", pss); len(findings) != 0 {
+		t.Errorf("expected no findings, got %+v", findings)
+	}
+	pss.Members[2].StateStr, pss.Members[2].Health = "DOWN", 0
+	pss.Members[1].StateStr = "RECOVERING"
+	findings = DetectWriteConcernRisks(majority, "", pss)
+	if len(findings) != 1 || findings[0].Severity != SeverityHigh || !strings.Contains(findings[0].Message, "only 1 of 3 data-bearing members") {
+		t.Errorf("findings = %+v", findings)
+	}
+
+	// Without the topology only code that is unsafe on its own is reported.
+	if findings := DetectWriteConcernRisks(scan, "", nil); len(findings) != 2 {
+		t.Errorf("got %d findings without topology, want 2: %+v", len(findings), findings)
+	}
+}
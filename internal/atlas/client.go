@@ -343,6 +343,37 @@ func (c *Client) ListAccessLogs(ctx context.Context, projectID, clusterName stri
 	return allEntries, nil
 }
 
+// ListAccessListEntries returns the IP access list of an Atlas project.
+func (c *Client) ListAccessListEntries(ctx context.Context, projectID string) ([]AccessListEntry, error) {
+	if strings.TrimSpace(projectID) == "" {
+		return nil, fmt.Errorf("atlas project id is required")
+	}
+
+	path := fmt.Sprintf("/api/atlas/v2/groups/%s/accessList", url.PathEscape(projectID))
+	var envelope listEnvelope[map[string]any]
+	if err := c.get(ctx, path, url.Values{
+		"itemsPerPage": []string{"500"},
+		"includeCount": []string{"false"},
+	}, &envelope); err != nil {
+		return nil, err
+	}
+
+	entries := make([]AccessListEntry, 0, len(envelope.Results))
+	for _, raw := range envelope.Results {
+		entry := AccessListEntry{
+			CIDRBlock:        firstString(raw, "cidrBlock"),
+			IPAddress:        firstString(raw, "ipAddress"),
+			AWSSecurityGroup: firstString(raw, "awsSecurityGroup"),
+			Comment:          firstString(raw, "comment"),
+		}
+		if entry.CIDRBlock == "" && entry.IPAddress == "" && entry.AWSSecurityGroup == "" {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
 // ResolveProjectIDByCluster discovers a project containing the given cluster.
 func (c *Client) ResolveProjectIDByCluster(ctx context.Context, clusterName string) (string, error) {
 	clusterName = strings.TrimSpace(clusterName)
@@ -449,9 +480,30 @@ func parseCluster(raw map[string]any) Cluster {
 	}
 
 	cluster.InstanceSizeName = firstInstanceSize(raw)
+	cluster.DiskSizeGB = firstDiskSizeGB(raw)
 	return cluster
 }
 
+// firstDiskSizeGB reads diskSizeGB from the v1 top-level field or from the
+// first electable region spec in the v2 replicationSpecs layout.
+func firstDiskSizeGB(raw map[string]any) float64 {
+	if v, err := strconv.ParseFloat(toString(raw["diskSizeGB"]), 64); err == nil && v > 0 {
+		return v
+	}
+	for _, rep := range toSlice(raw["replicationSpecs"]) {
+		for _, region := range toSlice(toMap(rep)["regionConfigs"]) {
+			regionMap := toMap(region)
+			for _, key := range []string{"electableSpecs", "effectiveElectableSpecs"} {
+				spec := toMap(regionMap[key])
+				if v, err := strconv.ParseFloat(toString(spec["diskSizeGB"]), 64); err == nil && v > 0 {
+					return v
+				}
+			}
+		}
+	}
+	return 0
+}
+
 func firstInstanceSize(raw map[string]any) string {
 	if provider := toMap(raw["providerSettings"]); provider != nil {
 		if size := firstString(provider, "instanceSizeName", "instanceSize"); size != "" {
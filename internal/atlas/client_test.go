@@ -305,3 +305,70 @@ func TestListAccessLogs_APIError(t *testing.T) {
 		t.Errorf("expected 403 status, got: %v", err)
 	}
 }
+
+func TestListAccessListEntries(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/atlas/v2/groups/proj123/accessList" {
+			http.NotFound(w, r)
+			return
+		}
+		resp := map[string]any{
+			"results": []map[string]any{
+				{"cidrBlock": "10.0.0.0/16", "comment": "vpc"},
+				{"cidrBlock": "203.0.113.7/32", "ipAddress": "203.0.113.7"},
+				{"awsSecurityGroup": "sg-123"},
+				{"comment": "empty"},
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+
+	client := newTestClient(t, handler)
+	entries, err := client.ListAccessListEntries(context.Background(), "proj123")
+	if err != nil {
+		t.Fatalf("ListAccessListEntries: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 entries (empty entry skipped), got %d", len(entries))
+	}
+	if entries[0].CIDRBlock != "10.0.0.0/16" || entries[0].Comment != "vpc" {
+		t.Errorf("unexpected first entry: %+v", entries[0])
+	}
+	if entries[1].IPAddress != "203.0.113.7" {
+		t.Errorf("expected ipAddress 203.0.113.7, got %q", entries[1].IPAddress)
+	}
+	if entries[2].AWSSecurityGroup != "sg-123" {
+		t.Errorf("expected security group sg-123, got %q", entries[2].AWSSecurityGroup)
+	}
+}
+
+func TestListAccessListEntries_EmptyProjectID(t *testing.T) {
+	client := newTestClient(t, func(http.ResponseWriter, *http.Request) {})
+	if _, err := client.ListAccessListEntries(context.Background(), ""); err == nil {
+		t.Fatal("expected error for empty project ID")
+	}
+}
+
+func TestParseClusterDiskSize(t *testing.T) {
+	v1 := parseCluster(map[string]any{"name": "c1", "diskSizeGB": float64(40)})
+	if v1.DiskSizeGB != 40 {
+		t.Errorf("v1 diskSizeGB = %v, want 40", v1.DiskSizeGB)
+	}
+
+	v2 := parseCluster(map[string]any{
+		"name": "c2",
+		"replicationSpecs": []any{map[string]any{
+			"regionConfigs": []any{map[string]any{
+				"electableSpecs": map[string]any{"instanceSize": "M30", "diskSizeGB": float64(128)},
+			}},
+		}},
+	})
+	if v2.DiskSizeGB != 128 || v2.InstanceSizeName != "M30" {
+		t.Errorf("v2 cluster = %+v", v2)
+	}
+
+	if none := parseCluster(map[string]any{"name": "c3"}); none.DiskSizeGB != 0 {
+		t.Errorf("expected unknown disk size, got %v", none.DiskSizeGB)
+	}
+}
@@ -22,6 +22,7 @@ type Cluster struct {
 	Name             string
 	MongoDBVersion   string
 	InstanceSizeName string
+	DiskSizeGB       float64 // provisioned disk per node; 0 when unknown
 }
 
 // SuggestedIndex is a single Atlas Performance Advisor recommendation.
@@ -91,3 +92,11 @@ type AccessLogEntry struct {
 	IPAddress     string `json:"ipAddress"`
 	FailureReason string `json:"failureReason,omitempty"`
 }
+
+// AccessListEntry is a single entry of an Atlas project IP access list.
+type AccessListEntry struct {
+	CIDRBlock        string `json:"cidrBlock"`
+	IPAddress        string `json:"ipAddress,omitempty"`
+	AWSSecurityGroup string `json:"awsSecurityGroup,omitempty"`
+	Comment          string `json:"comment,omitempty"`
+}
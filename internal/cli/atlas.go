@@ -35,22 +35,31 @@ func resolveAtlasOptions(opts atlasOptions) atlasOptions {
 	if resolved.PrivateKey == "" {
 		resolved.PrivateKey = strings.TrimSpace(os.Getenv("ATLAS_PRIVATE_KEY"))
 	}
+	// A --cluster profile is more specific than the environment.
+	if resolved.ProjectID == "" {
+		resolved.ProjectID = strings.TrimSpace(activeProfile.AtlasProject)
+	}
 	if resolved.ProjectID == "" {
 		resolved.ProjectID = strings.TrimSpace(os.Getenv("ATLAS_PROJECT_ID"))
 	}
+	if resolved.Cluster == "" {
+		resolved.Cluster = strings.TrimSpace(activeProfile.AtlasCluster)
+	}
 	if resolved.Cluster == "" {
 		resolved.Cluster = strings.TrimSpace(os.Getenv("ATLAS_CLUSTER"))
 	}
 	return resolved
 }
 
+// collectAtlasFindings runs Atlas API detections and also returns the cluster
+// metadata it fetched (zero value when Atlas integration is skipped).
 func collectAtlasFindings(
 	ctx context.Context,
 	cmd *cobra.Command,
 	opts atlasOptions,
 	mongoURI string,
 	collections []mongoinspect.CollectionInfo,
-) []analyzer.Finding {
+) ([]analyzer.Finding, atlas.Cluster) {
 	resolved := resolveAtlasOptions(opts)
 
 	hasPublic := resolved.PublicKey != ""
@@ -60,10 +69,10 @@ func collectAtlasFindings(
 		if verbose {
 			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Atlas integration skipped: no Atlas API credentials provided.")
 		}
-		return nil
+		return nil, atlas.Cluster{}
 	case !hasPublic || !hasPrivate:
 		_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "warning: atlas integration skipped: both --atlas-public-key and --atlas-private-key are required")
-		return nil
+		return nil, atlas.Cluster{}
 	}
 
 	if resolved.Cluster == "" {
@@ -76,14 +85,14 @@ func collectAtlasFindings(
 	})
 	if err != nil {
 		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: atlas integration skipped: %v\n", err)
-		return nil
+		return nil, atlas.Cluster{}
 	}
 
 	projectID := resolved.ProjectID
 	clusterName := resolved.Cluster
 	projectID, clusterName = resolveAtlasTarget(ctx, cmd, atlasClient, projectID, clusterName)
 	if projectID == "" || clusterName == "" {
-		return nil
+		return nil, atlas.Cluster{}
 	}
 
 	cluster := atlas.Cluster{Name: clusterName}
@@ -98,11 +107,11 @@ func collectAtlasFindings(
 		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: atlas index suggestions unavailable: %v\n", err)
 	}
 
+	// The scan correlates index suggestions and holds the Terraform and
+	// Pulumi declarations compared for IaC drift.
 	var scanRes *scanner.ScanResult
-	if len(suggestions) > 0 {
-		if scan, ok := scanRepoForAtlas(cmd); ok {
-			scanRes = &scan
-		}
+	if scan, ok := scanRepoForAtlas(cmd); ok {
+		scanRes = &scan
 	}
 
 	alerts, err := atlasClient.ListAlerts(ctx, projectID)
@@ -124,11 +133,45 @@ func collectAtlasFindings(
 		Collections:       collections,
 		Scan:              scanRes,
 	})
+	if scanRes != nil && len(scanRes.IaCRefs) > 0 {
+		findings = append(findings, collectIaCDrift(ctx, cmd, atlasClient, projectID, scanRes.IaCRefs)...)
+	}
 
 	if verbose {
 		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Atlas enrichment: project=%s cluster=%s findings=%d\n", projectID, clusterName, len(findings))
 	}
-	return findings
+	return findings, cluster
+}
+
+// collectIaCDrift compares the Atlas resources declared in the repo with
+// the live project. Resource kinds whose live state cannot be fetched are
+// skipped with a warning.
+func collectIaCDrift(
+	ctx context.Context,
+	cmd *cobra.Command,
+	client atlasClient,
+	projectID string,
+	refs []scanner.IaCRef,
+) []analyzer.Finding {
+	input := &analyzer.IaCDriftInput{
+		ProjectID:   projectID,
+		Refs:        refs,
+		Unavailable: make(map[scanner.IaCResourceKind]bool),
+	}
+	var err error
+	if input.Users, err = client.ListDatabaseUsers(ctx, projectID); err != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: IaC drift check skipped database users: %v\n", err)
+		input.Unavailable[scanner.IaCDatabaseUser] = true
+	}
+	if input.AccessList, err = client.ListAccessListEntries(ctx, projectID); err != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: IaC drift check skipped IP access list: %v\n", err)
+		input.Unavailable[scanner.IaCAccessList] = true
+	}
+	if input.Clusters, err = client.ListClusters(ctx, projectID); err != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: IaC drift check skipped clusters: %v\n", err)
+		input.Unavailable[scanner.IaCCluster] = true
+	}
+	return analyzer.DetectIaCDrift(input)
 }
 
 func resolveAtlasTarget(
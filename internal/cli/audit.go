@@ -3,12 +3,12 @@ package cli
 import (
 	"context"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/ppiankov/mongospectre/internal/analyzer"
 	"github.com/ppiankov/mongospectre/internal/atlas"
+	"github.com/ppiankov/mongospectre/internal/i18n"
 	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
 	"github.com/ppiankov/mongospectre/internal/reporter"
 	"github.com/spf13/cobra"
@@ -16,10 +16,14 @@ import (
 
 func newAuditCmd() *cobra.Command {
 	var (
+		summary         summaryOutput
 		database        string
 		format          string
 		noIgnore        bool
+		ignoreFile      string
 		baseline        string
+		saveBaseline    string
+		baselineKeep    int
 		auditUsers      bool
 		sharding        bool
 		atlasPublicKey  string
@@ -27,58 +31,161 @@ func newAuditCmd() *cobra.Command {
 		atlasProject    string
 		atlasCluster    string
 		interactive     bool
+		groupBy         string
 		noInteractive   bool
 		lintURI         bool
 		security        bool
+		serverParams    bool
+		cacheFit        bool
 		replset         bool
+		snapshot        string
+		sampleSize      int
+		pii             bool
+		oplog           bool
+		oplogLimit      int64
+		trafficSample   time.Duration
+		filterOwner     string
+		quick           bool
 	)
 
 	cmd := &cobra.Command{
 		Use:   "audit",
 		Short: "Audit MongoDB cluster for unused collections, indexes, and drift",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := validateFormat(format, "text", "json", "sarif", "spectrehub"); err != nil {
+		RunE: summary.wrap(func(cmd *cobra.Command, args []string) error {
+			database = profileDatabase(database)
+			if err := validateFormat(format, "text", "json", "sarif", "spectrehub", "ndjson"); err != nil {
+				return err
+			}
+			if err := validateGroupBy(groupBy); err != nil {
 				return err
 			}
 			if interactive && noInteractive {
 				return fmt.Errorf("--interactive and --no-interactive are mutually exclusive")
 			}
-			if uri == "" {
-				return fmt.Errorf("--uri is required (or set MONGODB_URI)")
+			if snapshot != "" && (auditUsers || sharding || security || serverParams || cacheFit || replset || sampleSize > 0 || oplog || trafficSample > 0) {
+				return fmt.Errorf("--snapshot cannot be combined with --audit-users, --sharding, --security, --server-params, --cache-fit, --replset, --sample-size, --oplog or --traffic-sample (they need a live connection)")
 			}
-
-			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
-			defer cancel()
-
-			if verbose {
-				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Connecting to %s (timeout %s)...\n", uri, timeout)
+			if err := validateTrafficSample(trafficSample); err != nil {
+				return err
 			}
-
-			inspector, err := newInspector(ctx, mongoinspect.Config{
-				URI:      uri,
-				Database: database,
-			})
+			if quick && (sampleSize > 0 || sharding || oplog || trafficSample > 0 || cacheFit) {
+				return fmt.Errorf("--quick cannot be combined with --sample-size, --sharding, --oplog, --traffic-sample or --cache-fit")
+			}
+			if quick && (baseline != "" || saveBaseline != "") {
+				return fmt.Errorf("--quick cannot be combined with --baseline or --save-baseline (quick reports have no stats to compare)")
+			}
+			if uri == "" && snapshot == "" {
+				return fmt.Errorf("--uri is required (or set MONGODB_URI, or use --snapshot)")
+			}
+			naming, err := configNamingLinter()
 			if err != nil {
 				return err
 			}
+			tenantPatterns, err := configCollectionPatterns()
+			if err != nil {
+				return err
+			}
+			ownerMap, err := configOwnerMap()
+			if err != nil {
+				return err
+			}
+			classification, err := configClassification()
+			if err != nil {
+				return err
+			}
+			retention, err := configRetention()
+			if err != nil {
+				return err
+			}
+			piiPatterns, err := configPIIPatterns(pii, sampleSize)
+			if err != nil {
+				return err
+			}
+
+			// Snapshots (often backups) stay out of the live baseline history
+			// unless --save-baseline is given explicitly.
+			// Quick reports lack the stats baselines are compared on.
+			if !cmd.Flags().Changed("save-baseline") && snapshot == "" && !quick {
+				saveBaseline = cfg.BaselineDir
+			}
+			if !cmd.Flags().Changed("baseline-keep") && cfg.BaselineKeep > 0 {
+				baselineKeep = cfg.BaselineKeep
+			}
+			// Without an explicit --baseline, diff against the newest saved one.
+			if baseline == "" && saveBaseline != "" {
+				latest, err := analyzer.LatestBaseline(saveBaseline)
+				if err != nil {
+					return fmt.Errorf("baseline dir: %w", err)
+				}
+				baseline = latest
+				if verbose && latest != "" {
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Comparing against baseline %s\n", latest)
+				}
+			}
+
+			timer := newPhaseTimer()
+			ctx, interrupted, stopSignals := notifyInterrupt(cmd.Context())
+			defer stopSignals()
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			var inspector inspector
+			host, uriHash := reporter.HostFromURI(uri), reporter.HashURI(uri)
+			if snapshot != "" {
+				si, err := openSnapshotInspector(cmd, snapshot)
+				if err != nil {
+					return err
+				}
+				inspector = si
+				host, uriHash = si.snap.Host, si.snap.URIHash
+			} else {
+				if verbose {
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Connecting to %s (timeout %s)...\n", mongoinspect.RedactURI(uri), timeout)
+				}
+				inspector, err = newInspector(ctx, mongoinspect.Config{
+					URI:             uri,
+					Database:        database,
+					TLS:             tlsOpts,
+					Concurrency:     concurrency,
+					DatabaseTimeout: dbTimeout,
+					Retries:         retries,
+					StrictReadOnly:  strictReadOnly,
+					OnCommand:       onCommand(),
+					CommandLog:      commandLogHook(),
+					PIIPatterns:     piiPatterns,
+					Progress:        newProgress(cmd.ErrOrStderr()),
+					Quick:           quick,
+				})
+				if err != nil {
+					return err
+				}
+			}
 			defer func() { _ = inspector.Close(ctx) }()
 
 			info, err := inspector.GetServerVersion(ctx)
 			if err != nil {
 				return fmt.Errorf("server info: %w", err)
 			}
-			host := reporter.HostFromURI(uri)
-			if host != "" {
+			switch {
+			case snapshot != "":
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Snapshot of MongoDB %s at %s\n", info.Version, host)
+			case host != "":
 				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Connected to MongoDB %s at %s\n", info.Version, host)
-			} else {
+			default:
 				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Connected to MongoDB %s\n", info.Version)
 			}
+			timer.lap("connect")
 
+			trunc := truncation{interrupted: interrupted}
 			collections, err := inspector.Inspect(ctx, database)
-			if err != nil {
+			if err := trunc.absorb(err); err != nil {
 				return fmt.Errorf("inspect: %w", err)
 			}
 			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Inspected %d collections\n", len(collections))
+			timer.lap("inspect")
+			if quick {
+				_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Quick audit: collection and index stats skipped, running index definition checks only")
+			}
 
 			if len(collections) == 0 {
 				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Hint: no collections found. Check that the URI points to a database with data, or use --database to specify one.\n")
@@ -91,16 +198,57 @@ func newAuditCmd() *cobra.Command {
 				}
 			}
 
-			var findings []analyzer.Finding
+			stream := newFindingStream(cmd, format, noIgnore, ignoreFile, tenantPatterns)
+			stream.ownerMap = ownerMap
+			stream.owner = filterOwner
+			stream.redactor = classification.Redactor(collections, nil)
+
+			// Traffic sampling refines the findings below, so it runs first.
+			if trafficSample > 0 && !trunc.stopped() {
+				stream.traffic = sampleTraffic(ctx, cmd, inspector, database, trafficSample)
+				timer.lap("traffic")
+			}
 
 			// URI linting: static analysis before connecting.
-			if lintURI {
-				findings = append(findings, analyzer.LintURI(uri)...)
+			if lintURI && snapshot == "" {
+				stream.add(lintConnectionURI(uri)...)
 			}
 
-			findings = append(findings, analyzer.Audit(collections)...)
+			detectors := analyzer.AuditDetectors()
+			if quick {
+				detectors = analyzer.QuickAuditDetectors()
+			}
+			timer.detectors(stream.audit(collections, detectors))
+			stream.add(analyzer.DetectUnencryptedSensitiveFields(classification, collections, nil)...)
+			timer.lap("analyze")
+
+			// Document sampling: schema anti-patterns and TTL field types.
+			var samples []mongoinspect.FieldSampleResult
+			if sampleSize > 0 && trunc.stopped() {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: document sampling skipped: %s\n", trunc.reason())
+			} else if sampleSize > 0 {
+				var sampleErr error
+				samples, sampleErr = inspector.SampleDocuments(ctx, database, int64(sampleSize))
+				if sampleErr != nil && trunc.wasInterrupted() {
+					_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "warning: document sampling skipped: interrupted")
+				} else if sampleErr != nil {
+					return fmt.Errorf("sample documents: %w", sampleErr)
+				}
+				warnMemoryCapped(cmd.ErrOrStderr(), samples)
+				stream.add(analyzer.DetectAntiPatterns(samples)...)
+				stream.add(analyzer.DetectTTLFieldTypes(collections, samples)...)
+				stream.add(analyzer.DetectPII(samples)...)
+				stream.add(analyzer.DetectStaleData(collections, samples)...)
+				stream.add(analyzer.DetectArchiveCandidates(collections, samples)...)
+				collections = mergeDataAge(collections, samples)
+				timer.lap("sampling")
+			}
+			if naming != nil {
+				stream.add(naming.Lint(collections, samples)...)
+				timer.lap("naming")
+			}
 
-			if auditUsers {
+			if auditUsers && !trunc.skip(cmd.ErrOrStderr(), "user audit") {
 				var allUsers []mongoinspect.UserInfo
 				var userErrors int
 
@@ -115,20 +263,36 @@ func newAuditCmd() *cobra.Command {
 					allUsers = append(allUsers, adminUsers...)
 				}
 
-				// Query each application database.
-				dbs, dbsErr := inspector.ListDatabases(ctx, database)
-				if dbsErr == nil {
+				// x.509, LDAP and Kerberos users live in $external. Most
+				// deployments have none, so failures here are not counted.
+				if extUsers, extErr := inspector.InspectUsers(ctx, "$external"); extErr == nil {
+					allUsers = append(allUsers, extUsers...)
+				} else if verbose {
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: could not list $external users: %v\n", extErr)
+				}
+
+				// Query each application database in parallel.
+				var dbNames []string
+				if dbs, dbsErr := inspector.ListDatabases(ctx, database); dbsErr == nil {
 					for _, db := range dbs {
-						dbUsers, dbErr := inspector.InspectUsers(ctx, db.Name)
-						if dbErr != nil {
-							if verbose {
-								_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: could not list users on %s: %v\n", db.Name, dbErr)
-							}
-							userErrors++
-							continue
+						dbNames = append(dbNames, db.Name)
+					}
+				}
+				dbUsers := make([][]mongoinspect.UserInfo, len(dbNames))
+				dbUserErrs := make([]error, len(dbNames))
+				_ = mongoinspect.ForEachDatabase(ctx, dbNames, concurrency, dbTimeout, func(ctx context.Context, i int, name string) error {
+					dbUsers[i], dbUserErrs[i] = inspector.InspectUsers(ctx, name)
+					return nil
+				})
+				for i, name := range dbNames {
+					if dbUserErrs[i] != nil {
+						if verbose {
+							_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: could not list users on %s: %v\n", name, dbUserErrs[i])
 						}
-						allUsers = append(allUsers, dbUsers...)
+						userErrors++
+						continue
 					}
+					allUsers = append(allUsers, dbUsers[i]...)
 				}
 
 				// Atlas API fallback: when native usersInfo fails, try Atlas Admin API.
@@ -157,11 +321,34 @@ func newAuditCmd() *cobra.Command {
 				}
 
 				userFindings := analyzer.AuditUsers(allUsers)
-				findings = append(findings, userFindings...)
+				stream.add(userFindings...)
+
+				// Custom roles, expanded through inheritance. Only native
+				// user listings carry role assignments for every database.
+				if len(atlasUsers) == 0 {
+					roleDBs := append([]string{"admin"}, dbNames...)
+					dbRoles := make([][]mongoinspect.RoleInfo, len(roleDBs))
+					dbRoleErrs := make([]error, len(roleDBs))
+					_ = mongoinspect.ForEachDatabase(ctx, roleDBs, concurrency, dbTimeout, func(ctx context.Context, i int, name string) error {
+						dbRoles[i], dbRoleErrs[i] = inspector.InspectRoles(ctx, name)
+						return nil
+					})
+					var allRoles []mongoinspect.RoleInfo
+					for i, db := range roleDBs {
+						if dbRoleErrs[i] != nil {
+							if verbose {
+								_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: could not list roles on %s: %v\n", db, dbRoleErrs[i])
+							}
+							continue
+						}
+						allRoles = append(allRoles, dbRoles[i]...)
+					}
+					stream.add(analyzer.AuditRoles(allRoles, allUsers)...)
+				}
 
 				// Atlas-specific user findings (scope analysis).
 				if len(atlasUsers) > 0 {
-					findings = append(findings, analyzer.AuditAtlasUsers(atlasUsers)...)
+					stream.add(analyzer.AuditAtlasUsers(atlasUsers)...)
 
 					// Atlas access log analysis: detect inactive users.
 					accessLogs := collectAccessLogs(ctx, cmd, atlasOptions{
@@ -172,12 +359,13 @@ func newAuditCmd() *cobra.Command {
 					}, uri)
 					if accessLogs != nil {
 						inactiveFindings := analyzer.DetectInactiveUsers(atlasUsers, accessLogs)
-						findings = append(findings, inactiveFindings...)
+						stream.add(inactiveFindings...)
 					}
 				}
+				timer.lap("users")
 			}
 
-			if sharding {
+			if sharding && !trunc.skip(cmd.ErrOrStderr(), "sharding analysis") {
 				shardingInfo, shardingErr := inspector.InspectSharding(ctx)
 				switch {
 				case shardingErr != nil:
@@ -189,11 +377,12 @@ func newAuditCmd() *cobra.Command {
 						_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Inspected sharding metadata for %d collections across %d shards\n",
 							len(shardingInfo.Collections), len(shardingInfo.Shards))
 					}
-					findings = append(findings, analyzer.AuditSharding(collections, shardingInfo)...)
+					stream.add(analyzer.AuditSharding(collections, shardingInfo)...)
 				}
+				timer.lap("sharding")
 			}
 
-			if security {
+			if security && !trunc.skip(cmd.ErrOrStderr(), "security audit") {
 				if isAtlasURI(uri) {
 					_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Security audit skipped: Atlas manages server security configuration.")
 				} else {
@@ -201,12 +390,39 @@ func newAuditCmd() *cobra.Command {
 					if secErr != nil {
 						_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: security audit skipped: %v\n", secErr)
 					} else {
-						findings = append(findings, analyzer.AuditSecurity(secInfo)...)
+						stream.add(analyzer.AuditSecurity(secInfo)...)
+					}
+				}
+				timer.lap("security")
+			}
+
+			if serverParams && !trunc.skip(cmd.ErrOrStderr(), "server parameter audit") {
+				if isAtlasURI(uri) {
+					_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Server parameter audit skipped: Atlas manages server parameters.")
+				} else {
+					params, paramsErr := inspector.InspectServerParameters(ctx)
+					if paramsErr != nil {
+						_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: server parameter audit skipped: %v\n", paramsErr)
+					} else {
+						stream.add(analyzer.AuditServerParams(params)...)
 					}
 				}
+				timer.lap("server-params")
 			}
 
-			if replset {
+			// Index sizes come from the inspection above; the cache size
+			// from serverStatus of the connected member.
+			if cacheFit && !trunc.skip(cmd.ErrOrStderr(), "cache fit analysis") {
+				cacheInfo, cacheErr := inspector.InspectCache(ctx)
+				if cacheErr != nil {
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: cache fit analysis skipped: %v\n", cacheErr)
+				} else {
+					stream.add(analyzer.DetectIndexesExceedCache(collections, cacheInfo)...)
+				}
+				timer.lap("cache")
+			}
+
+			if replset && !trunc.skip(cmd.ErrOrStderr(), "replica set audit") {
 				if isAtlasURI(uri) {
 					_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Replica set audit skipped: Atlas manages replica set topology.")
 				} else {
@@ -215,20 +431,35 @@ func newAuditCmd() *cobra.Command {
 					case rsErr != nil:
 						_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: replica set audit skipped: %v\n", rsErr)
 					case rsInfo.Name != "":
-						findings = append(findings, analyzer.AuditReplicaSet(rsInfo)...)
+						stream.add(analyzer.AuditReplicaSet(rsInfo)...)
 					default:
 						_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Replica set audit skipped: standalone deployment.")
 					}
 				}
+				timer.lap("replset")
 			}
 
-			atlasFindings := collectAtlasFindings(ctx, cmd, atlasOptions{
-				PublicKey:  atlasPublicKey,
-				PrivateKey: atlasPrivateKey,
-				ProjectID:  atlasProject,
-				Cluster:    atlasCluster,
-			}, uri, collections)
-			findings = append(findings, atlasFindings...)
+			var oplogProfile *mongoinspect.OplogProfile
+			if oplog && !trunc.skip(cmd.ErrOrStderr(), "oplog analysis") {
+				if oplogProfile = sampleOplog(ctx, cmd, inspector, database, oplogLimit); oplogProfile != nil {
+					stream.add(analyzer.AuditOplog(oplogProfile)...)
+				}
+				timer.lap("oplog")
+			}
+			stream.add(analyzer.DetectUnenforcedRetention(retention, collections, samples, oplogProfile)...)
+
+			var atlasMeta atlas.Cluster
+			if snapshot == "" && !quick {
+				var atlasFindings []analyzer.Finding
+				atlasFindings, atlasMeta = collectAtlasFindings(ctx, cmd, atlasOptions{
+					PublicKey:  atlasPublicKey,
+					PrivateKey: atlasPrivateKey,
+					ProjectID:  atlasProject,
+					Cluster:    atlasCluster,
+				}, uri, collections)
+				stream.add(atlasFindings...)
+				timer.lap("atlas")
+			}
 
 			// Baseline: load collections for growth detection, then diff findings.
 			var baselineFindings []analyzer.Finding
@@ -243,57 +474,103 @@ func newAuditCmd() *cobra.Command {
 				if len(baselineCollections) > 0 && len(collections) > 0 {
 					elapsed := time.Since(baselineTime)
 					if !baselineTime.IsZero() {
-						findings = append(findings, analyzer.DetectGrowth(collections, baselineCollections, elapsed)...)
+						stream.add(analyzer.DetectGrowth(collections, baselineCollections, elapsed)...)
 					}
 				}
+				timer.lap("baseline")
 			}
 
-			// Apply ignore file.
-			if !noIgnore {
-				cwd, _ := os.Getwd()
-				il, ilErr := analyzer.LoadIgnoreFile(cwd)
-				if ilErr != nil {
-					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: %v\n", ilErr)
-				}
-				var suppressed int
-				findings, suppressed = il.Filter(findings)
-				if verbose && suppressed > 0 {
-					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Suppressed %d findings via .mongospectreignore\n", suppressed)
+			// Capacity forecast from the saved baseline history plus this run.
+			if saveBaseline != "" {
+				limits := storageLimits(atlasMeta.DiskSizeGB)
+				if limits.Collection > 0 || limits.Cluster > 0 {
+					forecast, fcErr := forecastFromHistory(saveBaseline, collections, limits)
+					if fcErr != nil {
+						_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: storage forecast skipped: %v\n", fcErr)
+					}
+					stream.add(forecast...)
+					timer.lap("forecast")
 				}
 			}
 
-			// Baseline diff display.
+			// Ignore rules were applied as findings were added; per-tenant
+			// collections are reported once per pattern.
+			findings, streamErr := stream.finish()
+			if streamErr != nil {
+				return fmt.Errorf("write report: %w", streamErr)
+			}
+
+			// Baseline diff display. NDJSON output stays one finding per
+			// line, so the diff goes to stderr instead.
 			if baseline != "" {
+				diffOut := cmd.OutOrStdout()
+				if stream.streaming() {
+					diffOut = cmd.ErrOrStderr()
+				}
 				diff := analyzer.DiffBaseline(findings, baselineFindings)
-				reporter.WriteBaselineDiff(cmd.OutOrStdout(), diff)
+				reporter.WriteBaselineDiff(diffOut, diff, i18n.Lang(lang))
 			}
 
 			report := reporter.NewReport(findings)
+			summary.report = &report
 			report.Metadata = reporter.Metadata{
-				Version:        version,
-				Command:        "audit",
-				Host:           host,
-				Database:       database,
-				MongoDBVersion: info.Version,
-				URIHash:        reporter.HashURI(uri),
+				Version:           version,
+				Command:           "audit",
+				Timestamp:         report.Metadata.Timestamp,
+				Host:              host,
+				Database:          database,
+				MongoDBVersion:    info.Version,
+				URIHash:           uriHash,
+				Cluster:           cluster,
+				Tags:              activeProfile.Tags,
+				Truncated:         trunc.truncated(),
+				Interrupted:       trunc.wasInterrupted(),
+				SkippedNamespaces: trunc.skipped,
+				Quick:             quick,
+			}
+			report.Collections = stream.redactor.Collections(collections)
+			report.TenantGroups = analyzer.AggregateTenantGroups(collections, tenantPatterns)
+			report.Performance = timer.finish(cmd.ErrOrStderr())
+			trunc.warn(cmd.ErrOrStderr())
+
+			// A partial run would read as dropped collections in the next
+			// baseline diff, so it is not saved.
+			if saveBaseline != "" && trunc.stopped() {
+				_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "warning: baseline not saved: report is partial")
+			} else if saveBaseline != "" {
+				path, removed, err := reporter.SaveBaseline(saveBaseline, &report, baselineKeep)
+				if err != nil {
+					return fmt.Errorf("save baseline: %w", err)
+				}
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Saved baseline %s\n", path)
+				if verbose {
+					for _, r := range removed {
+						_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "  rotated out %s\n", r)
+					}
+				}
 			}
-			report.Collections = collections
 
-			renderedInteractive, err := maybeRenderInteractive(cmd, &report, collections, nil, interactiveConfig{
+			renderedInteractive, err := maybeRenderInteractive(cmd, &report, report.Collections, nil, interactiveConfig{
 				force:    interactive,
-				disable:  noInteractive,
+				disable:  noInteractive || trunc.wasInterrupted(),
 				format:   format,
 				findings: len(findings),
 			})
 			if err != nil {
 				return err
 			}
-			if !renderedInteractive {
-				if err := reporter.Write(cmd.OutOrStdout(), &report, reporter.Format(format)); err != nil {
+			if !renderedInteractive && !stream.streaming() {
+				if err := writeReport(cmd.OutOrStdout(), &report, format, groupBy); err != nil {
 					return fmt.Errorf("write report: %w", err)
 				}
 			}
 
+			if trunc.wasInterrupted() {
+				code := trunc.exitCode()
+				_, _ = fmt.Fprintln(cmd.ErrOrStderr(), reporter.ExitCodeHint(code))
+				return &ExitError{Code: code}
+			}
+
 			code := analyzer.ExitCode(report.MaxSeverity)
 			if code != 0 {
 				if hint := reporter.ExitCodeHint(code); hint != "" {
@@ -302,13 +579,16 @@ func newAuditCmd() *cobra.Command {
 				return &ExitError{Code: code}
 			}
 			return nil
-		},
+		}),
 	}
 
 	cmd.Flags().StringVar(&database, "database", "", "specific database to audit (default: all non-system)")
-	cmd.Flags().StringVarP(&format, "format", "f", "text", "output format: text, json, sarif, or spectrehub")
-	cmd.Flags().BoolVar(&noIgnore, "no-ignore", false, "bypass .mongospectreignore file")
+	cmd.Flags().StringVarP(&format, "format", "f", "text", "output format: text, json, sarif, spectrehub, or ndjson (one finding per line)")
+	cmd.Flags().BoolVar(&noIgnore, "no-ignore", false, "bypass ignore files")
+	cmd.Flags().StringVar(&ignoreFile, "ignore-file", "", "extra ignore file merged with .mongospectreignore and ~/.config/mongospectre/ignore")
 	cmd.Flags().StringVar(&baseline, "baseline", "", "path to previous JSON report for diff comparison")
+	cmd.Flags().StringVar(&saveBaseline, "save-baseline", "", "directory to save this report as the next baseline (diffs against the newest one there)")
+	cmd.Flags().IntVar(&baselineKeep, "baseline-keep", 10, "number of baselines to retain in --save-baseline directory (0 keeps all)")
 	cmd.Flags().BoolVar(&auditUsers, "audit-users", false, "audit MongoDB user configurations (requires userAdmin role)")
 	cmd.Flags().BoolVar(&sharding, "sharding", false, "run sharding metadata analysis (requires access to config database)")
 	cmd.Flags().StringVar(&atlasPublicKey, "atlas-public-key", "", "MongoDB Atlas API public key (env: ATLAS_PUBLIC_KEY)")
@@ -317,9 +597,22 @@ func newAuditCmd() *cobra.Command {
 	cmd.Flags().StringVar(&atlasCluster, "atlas-cluster", "", "MongoDB Atlas cluster name (auto-derived from URI if possible)")
 	cmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "launch interactive terminal UI (text format only)")
 	cmd.Flags().BoolVar(&noInteractive, "no-interactive", false, "force non-interactive output")
+	cmd.Flags().StringVar(&groupBy, "group-by", "", "group text output by finding type, collection or owner: type, collection, owner")
+	cmd.Flags().StringVar(&filterOwner, "filter-owner", "", "only report findings assigned to this owner by the owners config, e.g. @org/team-billing")
 	cmd.Flags().BoolVar(&lintURI, "lint-uri", true, "lint MongoDB URI for common misconfigurations")
 	cmd.Flags().BoolVar(&security, "security", false, "audit server security configuration (requires admin access)")
+	cmd.Flags().BoolVar(&serverParams, "server-params", false, "audit server parameters against the recommended production profile (requires admin access)")
+	cmd.Flags().BoolVar(&cacheFit, "cache-fit", false, "compare the size of indexes in use with the WiredTiger cache from serverStatus (requires clusterMonitor)")
+	cmd.Flags().StringVar(&snapshot, "snapshot", "", "audit a snapshot from export-snapshot, or a mongodump directory or archive, instead of connecting to MongoDB")
 	cmd.Flags().BoolVar(&replset, "replset", false, "audit replica set configuration (requires admin access)")
+	cmd.Flags().IntVar(&sampleSize, "sample-size", 0, "sample N documents per collection for schema anti-pattern and TTL checks (0 to disable)")
+	cmd.Flags().BoolVar(&pii, "pii", false, "match sampled string values against PII patterns and report POSSIBLE_PII_FIELD (requires --sample-size; values never leave memory)")
+	cmd.Flags().BoolVar(&oplog, "oplog", false, "sample the newest oplog entries for per-collection write distribution (requires read access to local)")
+	cmd.Flags().Int64Var(&oplogLimit, "oplog-limit", mongoinspect.DefaultOplogSampleLimit, "maximum number of oplog entries to read with --oplog (capped at 100000)")
+	cmd.Flags().DurationVar(&trafficSample, "traffic-sample", 0, "watch change streams for this long (e.g. 60s) and use per-collection write rates to refine findings (must be shorter than --timeout)")
+	cmd.Flags().BoolVar(&quick, "quick", false, "smoke check: list collections and index definitions only, without $collStats, $indexStats or Atlas API calls, and run only the checks that need no stats")
+
+	summary.addFlags(cmd)
 
 	return cmd
 }
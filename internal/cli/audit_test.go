@@ -4,9 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"os"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/ppiankov/mongospectre/internal/analyzer"
 	"github.com/ppiankov/mongospectre/internal/atlas"
@@ -115,6 +118,50 @@ func TestAuditBaselineLoadError(t *testing.T) {
 	}
 }
 
+func TestAuditSaveBaselineDiffsAgainstLatest(t *testing.T) {
+	fake := &fakeInspector{
+		serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"},
+		inspectResult: []mongoinspect.CollectionInfo{
+			{Database: "app", Name: "users", DocCount: 10, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+		},
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return fake, nil
+	})
+
+	dir := filepath.Join(t.TempDir(), "baselines")
+	stdout, stderr, err := execCLI(t, "audit", "--uri", "mongodb://stub", "--save-baseline", dir, "--timeout", "1s")
+	if err != nil {
+		t.Fatalf("first audit: %v", err)
+	}
+	if strings.Contains(stdout, "Baseline diff:") {
+		t.Fatalf("first run should not diff, got: %q", stdout)
+	}
+	if !strings.Contains(stderr, "Saved baseline") {
+		t.Fatalf("expected saved baseline log, got: %q", stderr)
+	}
+
+	stdout, _, err = execCLI(t, "audit", "--uri", "mongodb://stub", "--save-baseline", dir, "--baseline-keep", "1", "--timeout", "1s")
+	if err != nil {
+		t.Fatalf("second audit: %v", err)
+	}
+	if !strings.Contains(stdout, "Baseline diff: 0 new, 0 resolved") {
+		t.Fatalf("expected diff against saved baseline, got: %q", stdout)
+	}
+
+	paths, err := analyzer.ListBaselines(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 1 {
+		t.Fatalf("expected rotation to keep 1 baseline, got %v", paths)
+	}
+	_, _, ts, err := analyzer.LoadBaselineWithCollections(paths[0])
+	if err != nil || ts.IsZero() {
+		t.Fatalf("saved baseline should carry a timestamp, got %v (%v)", ts, err)
+	}
+}
+
 func TestAuditUsersWarningsPath(t *testing.T) {
 	fake := &fakeInspector{
 		serverInfo:       mongoinspect.ServerInfo{Version: "7.0.0"},
@@ -145,6 +192,45 @@ func TestAuditUsersWarningsPath(t *testing.T) {
 	}
 }
 
+func TestAuditUsersExpandsCustomRoles(t *testing.T) {
+	fake := &fakeInspector{
+		serverInfo:       mongoinspect.ServerInfo{Version: "7.0.0"},
+		inspectResult:    []mongoinspect.CollectionInfo{{Database: "app", Name: "users", DocCount: 1}},
+		listDatabasesRes: []mongoinspect.DatabaseInfo{{Name: "app"}},
+		inspectUsersRes: map[string][]mongoinspect.UserInfo{
+			"app": {{Username: "svc", Database: "app", Roles: []mongoinspect.UserRole{{Role: "appOps", DB: "app"}}}},
+		},
+		inspectRolesRes: map[string][]mongoinspect.RoleInfo{
+			"app": {
+				{Role: "appOps", Database: "app", Roles: []mongoinspect.UserRole{{Role: "grantor", DB: "app"}}},
+				{Role: "grantor", Database: "app", Privileges: []mongoinspect.Privilege{
+					{Resource: mongoinspect.PrivilegeResource{DB: "app"}, Actions: []string{"grantRole"}},
+				}},
+				{Role: "legacy", Database: "app"},
+			},
+		},
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return fake, nil
+	})
+
+	stdout, _, _ := execCLI(t, "audit", "--uri", "mongodb://stub", "--audit-users", "--format", "json", "--no-ignore")
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	assertHasType(t, report.Findings, analyzer.FindingPrivilegeEscalation)
+	var unused []string
+	for _, f := range report.Findings {
+		if f.Type == analyzer.FindingUnusedRole {
+			unused = append(unused, f.Message)
+		}
+	}
+	if len(unused) != 1 || !strings.Contains(unused[0], `"legacy"`) {
+		t.Fatalf("UNUSED_ROLE findings = %v, want only legacy", unused)
+	}
+}
+
 func TestAuditEmptyCollectionsHint(t *testing.T) {
 	fake := &fakeInspector{
 		serverInfo:    mongoinspect.ServerInfo{Version: "7.0.0"},
@@ -234,6 +320,75 @@ func TestAuditShardingFlagNonShardedDeployment(t *testing.T) {
 	}
 }
 
+func TestAuditServerParamsFlag(t *testing.T) {
+	fake := &fakeInspector{
+		serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"},
+		inspectResult: []mongoinspect.CollectionInfo{
+			{Database: "app", Name: "users", DocCount: 10, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+		},
+		serverParamsRes: mongoinspect.ServerParameters{
+			CmdLineOpts: true,
+			Values:      map[string]string{"storage.journal.enabled": "false"},
+		},
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return fake, nil
+	})
+
+	stdout, _, err := execCLI(t, "audit", "--uri", "mongodb://stub", "--server-params", "--format", "json", "--timeout", "1s")
+	requireExitCode(t, err, 2)
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout)
+	}
+	assertHasType(t, report.Findings, analyzer.FindingServerParamDrift)
+}
+
+func TestAuditServerParamsErrorWarns(t *testing.T) {
+	fake := &fakeInspector{
+		serverInfo:      mongoinspect.ServerInfo{Version: "7.0.0"},
+		serverParamsErr: errors.New("not authorized on admin"),
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return fake, nil
+	})
+
+	_, stderr, err := execCLI(t, "audit", "--uri", "mongodb://stub", "--server-params", "--timeout", "1s")
+	if err != nil {
+		t.Fatalf("audit returned error: %v", err)
+	}
+	if !strings.Contains(stderr, "server parameter audit skipped: not authorized") {
+		t.Fatalf("expected warning, got: %q", stderr)
+	}
+}
+
+func TestAuditCacheFit(t *testing.T) {
+	fake := &fakeInspector{
+		serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"},
+		inspectResult: []mongoinspect.CollectionInfo{
+			{Database: "app", Name: "users", DocCount: 10, Indexes: []mongoinspect.IndexInfo{{Name: "_id_", Size: 2 << 30}}},
+		},
+		cacheRes: mongoinspect.CacheInfo{MaxBytes: 1 << 30},
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return fake, nil
+	})
+
+	stdout, _, err := execCLI(t, "audit", "--uri", "mongodb://stub", "--cache-fit", "--format", "json", "--timeout", "1s")
+	requireExitCode(t, err, 2)
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout)
+	}
+	assertHasType(t, report.Findings, analyzer.FindingIndexesExceedCache)
+
+	fake.cacheErr = errors.New("not authorized on admin")
+	_, stderr, _ := execCLI(t, "audit", "--uri", "mongodb://stub", "--cache-fit", "--timeout", "1s")
+	if !strings.Contains(stderr, "cache fit analysis skipped: not authorized") {
+		t.Fatalf("expected warning, got: %q", stderr)
+	}
+}
+
 func assertHasType(t *testing.T, findings []analyzer.Finding, want analyzer.FindingType) {
 	t.Helper()
 	for _, finding := range findings {
@@ -344,6 +499,64 @@ func TestAuditAtlas_IncludesCorrelatedIndexSuggestions(t *testing.T) {
 	}
 }
 
+func TestAuditAtlas_IaCDrift(t *testing.T) {
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return &fakeInspector{serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"}}, nil
+	})
+	fakeAtlas := &fakeAtlasClient{
+		clusterRes:       atlas.Cluster{Name: "Cluster0", MongoDBVersion: "7.0.5", InstanceSizeName: "M30"},
+		databaseUsersRes: []atlas.DatabaseUser{{Username: "app", DatabaseName: "admin"}},
+		accessListErr:    errors.New("forbidden"),
+	}
+	stubNewAtlasClient(t, func(atlas.Config) (atlasClient, error) {
+		return fakeAtlas, nil
+	})
+	stubScanRepo(t, func(string) (scanner.ScanResult, error) {
+		return scanner.ScanResult{IaCRefs: []scanner.IaCRef{
+			{Tool: scanner.IaCTerraform, Kind: scanner.IaCDatabaseUser, Address: "mongodbatlas_database_user.etl", Value: "etl", Attribute: "username", File: "infra/atlas.tf", Line: 3},
+			{Tool: scanner.IaCTerraform, Kind: scanner.IaCAccessList, Address: "mongodbatlas_project_ip_access_list.office", Value: "203.0.113.0/24", Attribute: "cidr_block", File: "infra/atlas.tf", Line: 9},
+		}}, nil
+	})
+
+	stdout, stderr, err := execCLI(t,
+		"audit",
+		"--uri", "mongodb://localhost:27017/testdb",
+		"--atlas-public-key", "pub",
+		"--atlas-private-key", "priv",
+		"--atlas-project", "proj1",
+		"--atlas-cluster", "Cluster0",
+		"--format", "json",
+		"--timeout", "1s",
+	)
+	requireExitCode(t, err, 1)
+	if !strings.Contains(stderr, "warning: IaC drift check skipped IP access list: forbidden") {
+		t.Fatalf("expected access list warning, got: %s", stderr)
+	}
+
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	var drift []analyzer.Finding
+	for _, f := range report.Findings {
+		if f.Type == analyzer.FindingIaCDrift {
+			drift = append(drift, f)
+		}
+	}
+	if len(drift) != 2 {
+		t.Fatalf("expected 2 IAC_DRIFT findings, got %+v", drift)
+	}
+	if drift[0].File != "infra/atlas.tf" || !strings.Contains(drift[0].Message, `"etl"`) {
+		t.Errorf("unexpected declared-only finding: %+v", drift[0])
+	}
+	if !strings.Contains(drift[1].Message, `database user "app" exists in Atlas`) {
+		t.Errorf("unexpected live-only finding: %+v", drift[1])
+	}
+	if len(fakeAtlas.accessListCalls) != 1 || fakeAtlas.accessListCalls[0] != "proj1" {
+		t.Errorf("expected one access list call for proj1, got %v", fakeAtlas.accessListCalls)
+	}
+}
+
 func TestAuditAtlas_MissingOneKeySkipsWithWarning(t *testing.T) {
 	fake := &fakeInspector{
 		serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"},
@@ -487,3 +700,705 @@ func TestAuditUsers_AtlasFallback(t *testing.T) {
 	// Should have ATLAS_USER_NO_SCOPE for both users (no scopes).
 	assertHasType(t, report.Findings, analyzer.FindingAtlasUserNoScope)
 }
+
+func TestAuditNamingRulesFromConfig(t *testing.T) {
+	dir := t.TempDir()
+	cfgYAML := "naming:\n  collections: snake_case\n  indexes: \"^idx_\"\n"
+	if err := os.WriteFile(filepath.Join(dir, ".mongospectre.yml"), []byte(cfgYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return &fakeInspector{
+			serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"},
+			inspectResult: []mongoinspect.CollectionInfo{{
+				Database: "app",
+				Name:     "userEvents",
+				DocCount: 10,
+				Indexes:  []mongoinspect.IndexInfo{{Name: "_id_"}, {Name: "ts_1", Key: []mongoinspect.KeyField{{Field: "ts", Direction: 1}}}},
+			}},
+		}, nil
+	})
+
+	stdout, _, _ := execCLI(t, "audit", "--uri", "mongodb://stub", "--format", "json", "--no-ignore")
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	var got []string
+	for _, f := range report.Findings {
+		if f.Type == analyzer.FindingNamingViolation {
+			got = append(got, f.Message)
+		}
+	}
+	if len(got) != 2 {
+		t.Fatalf("naming findings = %v, want collection and index violations", got)
+	}
+}
+
+func TestAuditInvalidNamingPattern(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".mongospectre.yml"), []byte("naming:\n  fields: \"([\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	_, _, err := execCLI(t, "audit", "--uri", "mongodb://stub")
+	if err == nil || !strings.Contains(err.Error(), "naming rules: fields") {
+		t.Fatalf("expected naming rules error, got %v", err)
+	}
+}
+
+func TestAuditAnalyzerThresholdsFromConfig(t *testing.T) {
+	dir := t.TempDir()
+	cfgYAML := "analyzer:\n  max_indexes: 20\n  databases:\n    app:\n      max_indexes: 2\n"
+	if err := os.WriteFile(filepath.Join(dir, ".mongospectre.yml"), []byte(cfgYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	indexes := []mongoinspect.IndexInfo{
+		{Name: "_id_"},
+		{Name: "a_1", Key: []mongoinspect.KeyField{{Field: "a", Direction: 1}}},
+		{Name: "b_1", Key: []mongoinspect.KeyField{{Field: "b", Direction: 1}}},
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return &fakeInspector{
+			serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"},
+			inspectResult: []mongoinspect.CollectionInfo{
+				{Database: "app", Name: "orders", DocCount: 10, Indexes: indexes},
+				{Database: "other", Name: "orders", DocCount: 10, Indexes: indexes},
+			},
+		}, nil
+	})
+
+	stdout, _, _ := execCLI(t, "audit", "--uri", "mongodb://stub", "--format", "json", "--no-ignore")
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	var dbs []string
+	for _, f := range report.Findings {
+		if f.Type == analyzer.FindingWriteHeavyOverIndexed {
+			dbs = append(dbs, f.Database)
+		}
+	}
+	if len(dbs) != 1 || dbs[0] != "app" {
+		t.Fatalf("over-indexed findings in %v, want only app", dbs)
+	}
+}
+
+func TestAuditInvalidAnalyzerThresholds(t *testing.T) {
+	dir := t.TempDir()
+	cfgYAML := "analyzer:\n  databases:\n    app:\n      large_index_gb: -1\n"
+	if err := os.WriteFile(filepath.Join(dir, ".mongospectre.yml"), []byte(cfgYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	_, _, err := execCLI(t, "audit", "--uri", "mongodb://stub")
+	if err == nil || !strings.Contains(err.Error(), "analyzer: databases.app: large_index_gb") {
+		t.Fatalf("expected analyzer threshold error, got %v", err)
+	}
+}
+
+func TestAuditCollapsesTenantCollections(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".mongospectre.yml"), []byte("collection_patterns:\n  - \"events_{tenant}\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	var collections []mongoinspect.CollectionInfo
+	for _, tenant := range []string{"acme", "globex", "initech"} {
+		collections = append(collections, mongoinspect.CollectionInfo{Database: "app", Name: "events_" + tenant, Size: 1000})
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return &fakeInspector{
+			serverInfo:    mongoinspect.ServerInfo{Version: "7.0.0"},
+			inspectResult: collections,
+		}, nil
+	})
+
+	stdout, _, _ := execCLI(t, "audit", "--uri", "mongodb://stub", "--format", "json", "--no-ignore")
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	seen := make(map[analyzer.FindingType]int)
+	for _, f := range report.Findings {
+		if strings.HasPrefix(f.Collection, "events_") {
+			if f.Collection != "events_{tenant}" {
+				t.Errorf("finding not collapsed: %+v", f)
+			}
+			seen[f.Type]++
+		}
+	}
+	if len(seen) == 0 {
+		t.Fatal("expected findings on tenant collections")
+	}
+	for typ, n := range seen {
+		if n != 1 {
+			t.Errorf("%s reported %d times, want once per pattern", typ, n)
+		}
+	}
+	if len(report.TenantGroups) != 1 || report.TenantGroups[0].Collections != 3 || report.TenantGroups[0].Size != 3000 {
+		t.Errorf("tenant groups = %+v", report.TenantGroups)
+	}
+}
+
+func TestAuditInvalidCollectionPattern(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".mongospectre.yml"), []byte("collection_patterns: [events]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	_, _, err := execCLI(t, "audit", "--uri", "mongodb://stub")
+	if err == nil || !strings.Contains(err.Error(), "collection patterns") {
+		t.Fatalf("expected collection patterns error, got %v", err)
+	}
+}
+
+func TestAuditVerboseRedactsURIPassword(t *testing.T) {
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return &fakeInspector{serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"}}, nil
+	})
+
+	pass := "hunt" + "er2"
+	_, stderr, err := execCLI(t, "audit", "--uri", "mongodb://app:"+pass+"@db:27017/app", "--verbose", "--timeout", "1s")
+	if err != nil {
+		t.Fatalf("audit returned error: %v", err)
+	}
+	if strings.Contains(stderr, pass) {
+		t.Fatalf("verbose output leaked the password: %q", stderr)
+	}
+	if !strings.Contains(stderr, "Connecting to mongodb://app:***@db:27017/app") {
+		t.Fatalf("expected redacted URI in verbose output, got: %q", stderr)
+	}
+}
+
+func TestAuditTLSFromConfigAndFlags(t *testing.T) {
+	dir := t.TempDir()
+	cfgYAML := "tls:\n  ca_file: ca.pem\n  cert_key_file: client.pem\n"
+	if err := os.WriteFile(filepath.Join(dir, ".mongospectre.yml"), []byte(cfgYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	var got mongoinspect.Config
+	stubNewInspector(t, func(_ context.Context, c mongoinspect.Config) (inspector, error) {
+		got = c
+		return &fakeInspector{serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"}}, nil
+	})
+
+	stdout, _, _ := execCLI(t, "audit", "--uri", "mongodb://stub", "--format", "json", "--tls-cert-key-file", "other.pem")
+	want := mongoinspect.TLSConfig{CAFile: "ca.pem", CertKeyFile: "other.pem"}
+	if got.TLS != want {
+		t.Fatalf("TLS = %+v, want %+v", got.TLS, want)
+	}
+
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	for _, f := range report.Findings {
+		if f.Type == analyzer.FindingURINoTLS {
+			t.Errorf("URI_NO_TLS reported although TLS is enabled by flags: %+v", f)
+		}
+	}
+}
+
+func TestAuditPartialInspectionSkipsBaseline(t *testing.T) {
+	fake := &fakeInspector{
+		serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"},
+		inspectResult: []mongoinspect.CollectionInfo{
+			{Database: "app", Name: "users", DocCount: 10, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+		},
+		inspectErr: &mongoinspect.PartialError{
+			Skipped: []string{"app.events", "logs"},
+			Err:     context.DeadlineExceeded,
+		},
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return fake, nil
+	})
+
+	dir := filepath.Join(t.TempDir(), "baselines")
+	stdout, stderr, err := execCLI(t, "audit", "--uri", "mongodb://stub", "--format", "json", "--save-baseline", dir, "--timeout", "1s")
+	if err != nil {
+		t.Fatalf("audit returned error: %v", err)
+	}
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout)
+	}
+	if !report.Metadata.Truncated || len(report.Metadata.SkippedNamespaces) != 2 {
+		t.Fatalf("metadata = %+v, want truncated with 2 skipped namespaces", report.Metadata)
+	}
+	if len(report.Collections) != 1 {
+		t.Fatalf("collections = %d, want the 1 inspected", len(report.Collections))
+	}
+	if !strings.Contains(stderr, "baseline not saved: report is partial") {
+		t.Fatalf("expected baseline warning, got: %q", stderr)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("baseline directory should not exist, stat err = %v", err)
+	}
+}
+
+func TestAuditPartialInspectionSkipsLiveSteps(t *testing.T) {
+	fake := &fakeInspector{
+		serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"},
+		inspectResult: []mongoinspect.CollectionInfo{
+			{Database: "app", Name: "users", DocCount: 10, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+		},
+		inspectErr: &mongoinspect.PartialError{
+			Skipped: []string{"logs"},
+			Err:     context.DeadlineExceeded,
+		},
+		shardingErr: errors.New("sharding must not run after the timeout"),
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return fake, nil
+	})
+
+	_, stderr, err := execCLI(t, "audit", "--uri", "mongodb://stub", "--format", "json", "--audit-users", "--sharding", "--timeout", "1s")
+	if err != nil {
+		t.Fatalf("audit returned error: %v", err)
+	}
+	if len(fake.inspectUsersCalls) != 0 {
+		t.Errorf("users were read after the timeout: %v", fake.inspectUsersCalls)
+	}
+	for _, want := range []string{"user audit skipped: timeout reached", "sharding analysis skipped: timeout reached"} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("stderr missing %q: %q", want, stderr)
+		}
+	}
+	if strings.Contains(stderr, "sharding must not run") {
+		t.Errorf("sharding ran after the timeout: %q", stderr)
+	}
+}
+
+func TestAuditInterruptWritesPartialReport(t *testing.T) {
+	fake := &fakeInspector{
+		serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"},
+		inspectResult: []mongoinspect.CollectionInfo{
+			{Database: "app", Name: "users", DocCount: 10, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+		},
+		inspectErr: &mongoinspect.PartialError{
+			Skipped: []string{"logs"},
+			Err:     context.Canceled,
+		},
+		inspectHook: func(string) {
+			if err := syscall.Kill(os.Getpid(), syscall.SIGINT); err != nil {
+				t.Errorf("send SIGINT: %v", err)
+			}
+		},
+		inspectUntilDone: true,
+		sampleDocsErr:    errors.New("sampling must not run after an interrupt"),
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return fake, nil
+	})
+
+	stdout, stderr, err := execCLI(t, "audit", "--uri", "mongodb://stub", "--format", "json", "--sample-size", "10", "--timeout", "5s")
+	requireExitCode(t, err, ExitInterrupted)
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout)
+	}
+	if !report.Metadata.Interrupted || len(report.Metadata.SkippedNamespaces) != 1 || len(report.Collections) != 1 {
+		t.Fatalf("metadata = %+v with %d collections, want interrupted with the inspected one kept",
+			report.Metadata, len(report.Collections))
+	}
+	for _, want := range []string{"document sampling skipped: interrupted", "warning: interrupted, report is partial", "Exit 130"} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("stderr missing %q: %q", want, stderr)
+		}
+	}
+}
+
+func TestTruncationExitCode(t *testing.T) {
+	for sig, want := range map[os.Signal]int{syscall.SIGINT: 130, syscall.SIGTERM: 143} {
+		trunc := truncation{interrupted: func() os.Signal { return sig }}
+		if got := trunc.exitCode(); got != want {
+			t.Errorf("exit code for %v = %d, want %d", sig, got, want)
+		}
+	}
+}
+
+func TestAuditSampleSize(t *testing.T) {
+	fake := &fakeInspector{
+		serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"},
+		inspectResult: []mongoinspect.CollectionInfo{
+			{Database: "app", Name: "events", DocCount: 10, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+		},
+		sampleDocsRes: []mongoinspect.FieldSampleResult{
+			{Database: "app", Collection: "events", SampleSize: 3, MaxDocSize: 15 << 20, MemoryCapped: true},
+		},
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return fake, nil
+	})
+
+	stdout, stderr, err := execCLI(t, "audit", "--uri", "mongodb://stub", "--database", "app", "--sample-size", "50", "--format", "json", "--timeout", "1s")
+	requireExitCode(t, err, 2)
+	if len(fake.sampleDocsCalls) != 1 || fake.sampleDocsCalls[0].sampleSize != 50 {
+		t.Fatalf("sample calls = %+v, want one of size 50", fake.sampleDocsCalls)
+	}
+	if !strings.Contains(stderr, "sample of app.events stopped at the memory limit after 3 documents") {
+		t.Fatalf("expected memory cap warning, got: %q", stderr)
+	}
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout)
+	}
+	assertHasType(t, report.Findings, analyzer.FindingDocumentsNear16MB)
+}
+
+func TestAuditSnapshotRejectsSampleSize(t *testing.T) {
+	_, _, err := execCLI(t, "audit", "--snapshot", "snap.json", "--sample-size", "10")
+	if err == nil || !strings.Contains(err.Error(), "--sample-size") {
+		t.Fatalf("expected flag conflict error, got %v", err)
+	}
+}
+
+func TestAuditNDJSONWritesFindings(t *testing.T) {
+	fake := &fakeInspector{
+		serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"},
+		inspectResult: []mongoinspect.CollectionInfo{
+			{Database: "app", Name: "empty", DocCount: 0, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+			{Database: "app", Name: "orders", DocCount: 50000, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+		},
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return fake, nil
+	})
+
+	stdout, _, err := execCLI(t, "audit", "--uri", "mongodb://stub", "--format", "ndjson", "--no-ignore", "--timeout", "1s")
+	requireExitCode(t, err, 2)
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) < 2 {
+		t.Fatalf("expected a line per finding, got %q", stdout)
+	}
+	seen := map[string]bool{}
+	for _, line := range lines {
+		var f analyzer.Finding
+		if err := json.Unmarshal([]byte(line), &f); err != nil {
+			t.Fatalf("invalid NDJSON line %q: %v", line, err)
+		}
+		if f.RuleID == "" {
+			t.Fatalf("line missing rule ID: %q", line)
+		}
+		seen[f.Collection] = true
+	}
+	if !seen["empty"] || !seen["orders"] {
+		t.Fatalf("expected findings for both collections, got %v", seen)
+	}
+}
+
+func TestAuditReportsPerformance(t *testing.T) {
+	fake := &fakeInspector{
+		serverInfo:    mongoinspect.ServerInfo{Version: "7.0.0"},
+		inspectResult: []mongoinspect.CollectionInfo{{Database: "app", Name: "users", DocCount: 10}},
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return fake, nil
+	})
+
+	stdout, stderr, err := execCLI(t, "audit", "--uri", "mongodb://stub", "--format", "json", "--verbose", "--timeout", "1s")
+	if err != nil {
+		t.Fatalf("audit returned error: %v", err)
+	}
+
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	perf := report.Performance
+	if perf == nil {
+		t.Fatal("report has no performance section")
+	}
+	var phases []string
+	for _, p := range perf.Phases {
+		phases = append(phases, p.Name)
+	}
+	if got := strings.Join(phases, ","); got != "connect,inspect,analyze,atlas" {
+		t.Errorf("phases = %s, want connect,inspect,analyze,atlas", got)
+	}
+	if len(perf.Detectors) != len(analyzer.AuditDetectors()) || perf.Detectors[0].Name != "unused-collection" {
+		t.Errorf("detectors = %+v", perf.Detectors)
+	}
+	if !strings.Contains(stderr, "Timings (total ") || !strings.Contains(stderr, "  missing-index ") {
+		t.Errorf("--verbose should print timings, got:\n%s", stderr)
+	}
+}
+
+func TestAuditTrafficSample(t *testing.T) {
+	fake := &fakeInspector{
+		serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"},
+		inspectResult: []mongoinspect.CollectionInfo{
+			{Database: "app", Name: "jobs", DocCount: 0, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+			{Database: "app", Name: "old", DocCount: 0, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+		},
+		trafficRes: mongoinspect.TrafficSample{
+			Window:     2 * time.Second,
+			Writes:     40,
+			Namespaces: []mongoinspect.NamespaceWrites{{Database: "app", Collection: "jobs", Inserts: 20, Deletes: 20}},
+		},
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return fake, nil
+	})
+
+	stdout, stderr, err := execCLI(t, "audit", "--uri", "mongodb://stub", "--traffic-sample", "2s", "--format", "json", "--timeout", "5s")
+	requireExitCode(t, err, 1)
+	if len(fake.trafficCalls) != 1 || fake.trafficCalls[0] != 2*time.Second {
+		t.Fatalf("SampleTraffic calls = %v, want [2s]", fake.trafficCalls)
+	}
+	if !strings.Contains(stderr, "Sampled 40 writes to 1 collections") {
+		t.Errorf("missing sample summary in stderr: %q", stderr)
+	}
+
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	var unused []string
+	for _, f := range report.Findings {
+		if f.Type == analyzer.FindingUnusedCollection {
+			unused = append(unused, f.Collection)
+		}
+	}
+	if len(unused) != 1 || unused[0] != "old" {
+		t.Fatalf("UNUSED_COLLECTION on %v, want [old]", unused)
+	}
+}
+
+func TestAuditTrafficSampleMustFitTimeout(t *testing.T) {
+	_, _, err := execCLI(t, "audit", "--uri", "mongodb://stub", "--traffic-sample", "60s", "--timeout", "30s")
+	if err == nil || !strings.Contains(err.Error(), "must be shorter than --timeout") {
+		t.Fatalf("expected timeout validation error, got %v", err)
+	}
+}
+
+func TestAuditOwnersFromConfig(t *testing.T) {
+	dir := t.TempDir()
+	cfgYAML := "owners:\n  - database: billing\n    owner: \"@org/billing\"\n  - owner: \"@org/platform\"\n"
+	if err := os.WriteFile(filepath.Join(dir, ".mongospectre.yml"), []byte(cfgYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return &fakeInspector{
+			serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"},
+			inspectResult: []mongoinspect.CollectionInfo{
+				{Database: "billing", Name: "invoices", DocCount: 0, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+				{Database: "shop", Name: "carts", DocCount: 0, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+			},
+		}, nil
+	})
+
+	stdout, _, _ := execCLI(t, "audit", "--uri", "mongodb://stub", "--format", "json", "--no-ignore", "--filter-owner", "@org/billing")
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	if len(report.Findings) == 0 {
+		t.Fatal("expected findings on billing.invoices")
+	}
+	for _, f := range report.Findings {
+		if f.Database != "billing" || f.Owner != "@org/billing" {
+			t.Errorf("finding outside the owner filter: %+v", f)
+		}
+	}
+}
+
+func TestAuditInvalidOwners(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".mongospectre.yml"), []byte("owners:\n  - database: billing\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	_, _, err := execCLI(t, "audit", "--uri", "mongodb://stub")
+	if err == nil || !strings.Contains(err.Error(), "owners: rule 1: owner is required") {
+		t.Fatalf("err = %v, want owners error", err)
+	}
+}
+
+func TestAuditQuick(t *testing.T) {
+	t.Chdir(t.TempDir())
+	var gotCfg mongoinspect.Config
+	stubNewInspector(t, func(_ context.Context, cfg mongoinspect.Config) (inspector, error) {
+		gotCfg = cfg
+		return &fakeInspector{
+			serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"},
+			inspectResult: []mongoinspect.CollectionInfo{{
+				Database: "app", Name: "orders",
+				Indexes: []mongoinspect.IndexInfo{
+					{Name: "_id_", Key: []mongoinspect.KeyField{{Field: "_id", Direction: 1}}},
+					{Name: "status_1", Key: []mongoinspect.KeyField{{Field: "status", Direction: 1}}},
+					{Name: "status_1_date_1", Key: []mongoinspect.KeyField{{Field: "status", Direction: 1}, {Field: "date", Direction: 1}}},
+				},
+			}},
+		}, nil
+	})
+
+	stdout, stderr, err := execCLI(t, "audit", "--uri", "mongodb://stub", "--quick", "--format", "json", "--no-ignore", "--lint-uri=false")
+	if err != nil {
+		t.Fatalf("err = %v\n%s", err, stderr)
+	}
+	if !gotCfg.Quick {
+		t.Error("inspector not configured for a quick inspect")
+	}
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	if !report.Metadata.Quick {
+		t.Error("report metadata not marked quick")
+	}
+	// No stats means no UNUSED_COLLECTION for a zero document count.
+	for _, f := range report.Findings {
+		if f.Type != analyzer.FindingDuplicateIndex && f.Type != analyzer.FindingSingleFieldRedundant {
+			t.Errorf("unexpected finding in quick audit: %s", f.Type)
+		}
+	}
+	if len(report.Findings) == 0 {
+		t.Error("expected the duplicate index finding")
+	}
+
+	_, _, err = execCLI(t, "audit", "--uri", "mongodb://stub", "--quick", "--sample-size", "10")
+	if err == nil || !strings.Contains(err.Error(), "--quick cannot be combined") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestAuditPII(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile(filepath.Join(dir, ".mongospectre.yml"), []byte("pii:\n  patterns:\n    phone: \"\"\n    employee_id: \"^E[0-9]{6}$\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var patterns []string
+	stubNewInspector(t, func(_ context.Context, cfg mongoinspect.Config) (inspector, error) {
+		for _, p := range cfg.PIIPatterns {
+			patterns = append(patterns, p.Name)
+		}
+		return &fakeInspector{
+			serverInfo:    mongoinspect.ServerInfo{Version: "7.0.0"},
+			inspectResult: []mongoinspect.CollectionInfo{{Database: "app", Name: "users"}},
+			sampleDocsRes: []mongoinspect.FieldSampleResult{{
+				Database: "app", Collection: "users", SampleSize: 50,
+				PII: []mongoinspect.FieldPIIMatches{{Path: "email", Strings: 50, Matches: map[string]int64{"email": 50}}},
+			}},
+		}, nil
+	})
+
+	_, _, err := execCLI(t, "audit", "--uri", "mongodb://stub", "--pii")
+	if err == nil || !strings.Contains(err.Error(), "--pii requires --sample-size") {
+		t.Fatalf("err = %v, want --sample-size required", err)
+	}
+
+	stdout, _, err := execCLI(t, "audit", "--uri", "mongodb://stub", "--pii", "--sample-size", "50", "--format", "json", "--no-ignore", "--lint-uri=false")
+	requireExitCode(t, err, 1)
+	if strings.Join(patterns, ",") != "card_number,email,employee_id,iban,uk_nino,us_ssn" {
+		t.Errorf("patterns = %v", patterns)
+	}
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	found := false
+	for _, f := range report.Findings {
+		if f.Type == analyzer.FindingPossiblePII && f.Collection == "users" && strings.Contains(f.Message, "email 100%") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("no POSSIBLE_PII_FIELD finding in %+v", report.Findings)
+	}
+}
+
+func TestAuditClassification(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile(filepath.Join(dir, ".mongospectre.yml"), []byte("classification:\n  - collection: users\n    fields: [ssn]\n    tag: pii\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return &fakeInspector{
+			serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"},
+			inspectResult: []mongoinspect.CollectionInfo{{
+				Database: "app", Name: "users", DocCount: 100,
+				Indexes: []mongoinspect.IndexInfo{
+					{Name: "_id_", Key: []mongoinspect.KeyField{{Field: "_id", Direction: 1}}},
+					{Name: "ssn_1", Key: []mongoinspect.KeyField{{Field: "ssn", Direction: 1}}, Stats: &mongoinspect.IndexStats{Ops: 0}},
+				},
+			}},
+		}, nil
+	})
+
+	stdout, _, err := execCLI(t, "audit", "--uri", "mongodb://stub", "--format", "json", "--no-ignore", "--lint-uri=false")
+	requireExitCode(t, err, 1)
+	if strings.Contains(stdout, "ssn") {
+		t.Errorf("report mentions a tagged field:\n%s", stdout)
+	}
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	found := false
+	for _, f := range report.Findings {
+		if f.Type == analyzer.FindingSensitiveUnencrypted && strings.Contains(f.Message, "[redacted:pii]") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("no SENSITIVE_FIELD_UNENCRYPTED finding in %+v", report.Findings)
+	}
+	if key := report.Collections[0].Indexes[1].Key[0].Field; key != "[redacted:pii]" {
+		t.Errorf("report index key = %q", key)
+	}
+}
+
+func TestAuditDataAge(t *testing.T) {
+	oldest := time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)
+	newest := time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return &fakeInspector{
+			serverInfo:    mongoinspect.ServerInfo{Version: "7.0.0"},
+			inspectResult: []mongoinspect.CollectionInfo{{Database: "app", Name: "legacy"}},
+			sampleDocsRes: []mongoinspect.FieldSampleResult{{
+				Database: "app", Collection: "legacy", SampleSize: 20,
+				DateRanges: []mongoinspect.DateRange{{Field: "_id", Oldest: oldest, Newest: newest}},
+			}},
+		}, nil
+	})
+
+	stdout, _, err := execCLI(t, "audit", "--uri", "mongodb://stub", "--sample-size", "20", "--format", "json", "--no-ignore", "--lint-uri=false")
+	if err != nil {
+		requireExitCode(t, err, 1)
+	}
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	if age := report.Collections[0].DataAge; age == nil || !age.Oldest.Equal(oldest) || !age.Newest.Equal(newest) {
+		t.Errorf("dataAge = %+v", age)
+	}
+	found := false
+	for _, f := range report.Findings {
+		if f.Type == analyzer.FindingStaleData && f.Collection == "legacy" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("no STALE_DATA finding in %+v", report.Findings)
+	}
+}
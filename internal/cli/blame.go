@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	"github.com/ppiankov/mongospectre/internal/gitinfo"
+)
+
+// blamer attaches git blame metadata to findings tied to a file and line
+// for --blame. The first git error is printed as a warning and turns it
+// off, since it usually means the repo is not a git checkout.
+type blamer struct {
+	ctx    context.Context
+	repo   string
+	stderr io.Writer
+	cache  map[string]*gitinfo.Blame
+	failed bool
+}
+
+func newBlamer(ctx context.Context, repo string, stderr io.Writer) *blamer {
+	return &blamer{ctx: ctx, repo: repo, stderr: stderr, cache: make(map[string]*gitinfo.Blame)}
+}
+
+// annotate sets f.Blame when f has a code location that git has history
+// for.
+func (b *blamer) annotate(f *analyzer.Finding) {
+	if b.failed || f.File == "" || f.Line <= 0 {
+		return
+	}
+	key := fmt.Sprintf("%s:%d", f.File, f.Line)
+	blame, cached := b.cache[key]
+	if !cached {
+		got, ok, err := blameLine(b.ctx, b.repo, f.File, f.Line)
+		if err != nil {
+			_, _ = fmt.Fprintf(b.stderr, "warning: git blame skipped: %v\n", err)
+			b.failed = true
+			return
+		}
+		if ok {
+			blame = &got
+		}
+		b.cache[key] = blame
+	}
+	f.Blame = blame
+}
@@ -3,11 +3,12 @@ package cli
 import (
 	"context"
 	"fmt"
-	"os"
+	"io"
 	"strings"
 	"time"
 
 	"github.com/ppiankov/mongospectre/internal/analyzer"
+	"github.com/ppiankov/mongospectre/internal/i18n"
 	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
 	"github.com/ppiankov/mongospectre/internal/reporter"
 	"github.com/spf13/cobra"
@@ -15,6 +16,7 @@ import (
 
 func newCheckCmd() *cobra.Command {
 	var (
+		summary       summaryOutput
 		repo          string
 		database      string
 		format        string
@@ -22,34 +24,91 @@ func newCheckCmd() *cobra.Command {
 		profile       bool
 		profileLimit  int
 		sampleSize    int
+		pii           bool
 		noIgnore      bool
+		ignoreFile    string
 		baseline      string
 		interactive   bool
+		groupBy       string
 		noInteractive bool
 		lintURI       bool
+		sharding      bool
+		snapshot      string
+		oplog         bool
+		oplogLimit    int64
+		trafficSample time.Duration
+		staleMonths   int
+		blame         bool
+		filterOwner   string
+		watch         bool
+		clientApps    bool
+		connections   bool
 	)
 
 	cmd := &cobra.Command{
 		Use:   "check",
 		Short: "Compare code repo collection references against live MongoDB",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			if err := validateFormat(format, "text", "json", "sarif", "spectrehub"); err != nil {
+		RunE: summary.wrap(func(cmd *cobra.Command, args []string) error {
+			database = profileDatabase(database)
+			if err := validateFormat(format, "text", "json", "sarif", "spectrehub", "ndjson", "lsp-json"); err != nil {
 				return err
 			}
 			if profileLimit <= 0 {
 				return fmt.Errorf("--profile-limit must be greater than 0")
 			}
+			if err := validateGroupBy(groupBy); err != nil {
+				return err
+			}
 			if interactive && noInteractive {
 				return fmt.Errorf("--interactive and --no-interactive are mutually exclusive")
 			}
-			if uri == "" {
-				return fmt.Errorf("--uri is required (or set MONGODB_URI)")
+			if watch && (interactive || reporter.Format(format) != reporter.FormatText) {
+				return fmt.Errorf("--watch supports only text output and cannot be combined with --interactive")
+			}
+			if snapshot != "" && (profile || sampleSize > 0 || sharding || oplog || trafficSample > 0 || clientApps || connections) {
+				return fmt.Errorf("--snapshot cannot be combined with --profile, --sample-size, --sharding, --oplog, --traffic-sample, --client-apps or --connections (they need a live connection)")
+			}
+			if err := validateTrafficSample(trafficSample); err != nil {
+				return err
+			}
+			if staleMonths < 0 {
+				return fmt.Errorf("--git-stale-months must not be negative")
+			}
+			if uri == "" && snapshot == "" {
+				return fmt.Errorf("--uri is required (or set MONGODB_URI, or use --snapshot)")
 			}
 			if repo == "" {
 				return fmt.Errorf("--repo is required")
 			}
+			naming, err := configNamingLinter()
+			if err != nil {
+				return err
+			}
+			tenantPatterns, err := configCollectionPatterns()
+			if err != nil {
+				return err
+			}
+			ownerMap, err := configOwnerMap()
+			if err != nil {
+				return err
+			}
+			classification, err := configClassification()
+			if err != nil {
+				return err
+			}
+			retention, err := configRetention()
+			if err != nil {
+				return err
+			}
+			piiPatterns, err := configPIIPatterns(pii, sampleSize)
+			if err != nil {
+				return err
+			}
 
-			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			timer := newPhaseTimer()
+			runCtx, interrupted, stopSignals := notifyInterrupt(cmd.Context())
+			defer stopSignals()
+			ctx, cancel := context.WithTimeout(runCtx, timeout)
 			defer cancel()
 
 			// Scan code repo
@@ -69,18 +128,38 @@ func newCheckCmd() *cobra.Command {
 					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "  skipped %d unreadable files\n", scan.FilesSkipped)
 				}
 			}
+			timer.lap("scan")
 
-			// Connect to MongoDB
-			if verbose {
-				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Connecting to %s (timeout %s)...\n", uri, timeout)
-			}
-
-			inspector, err := newInspector(ctx, mongoinspect.Config{
-				URI:      uri,
-				Database: database,
-			})
-			if err != nil {
-				return err
+			// Connect to MongoDB, or serve metadata from an exported snapshot.
+			var inspector inspector
+			host, uriHash := reporter.HostFromURI(uri), reporter.HashURI(uri)
+			if snapshot != "" {
+				si, err := openSnapshotInspector(cmd, snapshot)
+				if err != nil {
+					return err
+				}
+				inspector = si
+				host, uriHash = si.snap.Host, si.snap.URIHash
+			} else {
+				if verbose {
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Connecting to %s (timeout %s)...\n", mongoinspect.RedactURI(uri), timeout)
+				}
+				inspector, err = newInspector(ctx, mongoinspect.Config{
+					URI:             uri,
+					Database:        database,
+					TLS:             tlsOpts,
+					Concurrency:     concurrency,
+					DatabaseTimeout: dbTimeout,
+					Retries:         retries,
+					StrictReadOnly:  strictReadOnly,
+					OnCommand:       onCommand(),
+					CommandLog:      commandLogHook(),
+					PIIPatterns:     piiPatterns,
+					Progress:        newProgress(cmd.ErrOrStderr()),
+				})
+				if err != nil {
+					return err
+				}
 			}
 			defer func() { _ = inspector.Close(ctx) }()
 
@@ -88,15 +167,19 @@ func newCheckCmd() *cobra.Command {
 			if err != nil {
 				return fmt.Errorf("server info: %w", err)
 			}
-			host := reporter.HostFromURI(uri)
-			if host != "" {
+			switch {
+			case snapshot != "":
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Snapshot of MongoDB %s at %s\n", info.Version, host)
+			case host != "":
 				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Connected to MongoDB %s at %s\n", info.Version, host)
-			} else {
+			default:
 				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Connected to MongoDB %s\n", info.Version)
 			}
+			timer.lap("connect")
 
+			trunc := truncation{interrupted: interrupted}
 			collections, err := inspector.Inspect(ctx, database)
-			if err != nil {
+			if err := trunc.absorb(err); err != nil {
 				return fmt.Errorf("inspect: %w", err)
 			}
 			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Inspected %d collections\n", len(collections))
@@ -105,42 +188,202 @@ func newCheckCmd() *cobra.Command {
 				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Hint: no collections found. Check that the URI points to a database with data, or use --database to specify one.\n")
 			}
 
-			validators, err := inspector.GetValidators(ctx, database)
-			if err != nil {
-				return fmt.Errorf("validators: %w", err)
+			var validators []mongoinspect.ValidatorInfo
+			if !trunc.stopped() {
+				validators, err = inspector.GetValidators(ctx, database)
+				if err := trunc.absorb(err); err != nil {
+					return fmt.Errorf("validators: %w", err)
+				}
 			}
 			collections = mergeCollectionValidators(collections, validators)
+			if snapshot == "" && !trunc.stopped() {
+				listVectorSearchIndexes(ctx, cmd, inspector, &scan, collections)
+			}
+			timer.lap("inspect")
+
+			stream := newFindingStream(cmd, format, noIgnore, ignoreFile, tenantPatterns)
+			stream.ownerMap = ownerMap
+			stream.owners = loadCodeOwners(cmd, scan.RepoPath)
+			stream.owner = filterOwner
+			stream.redactor = classification.Redactor(collections, &scan)
+			if blame {
+				stream.blame = newBlamer(ctx, scan.RepoPath, cmd.ErrOrStderr())
+			}
+
+			// Traffic sampling refines the diff findings, so it runs first.
+			if trafficSample > 0 && !trunc.stopped() {
+				stream.traffic = sampleTraffic(ctx, cmd, inspector, database, trafficSample)
+				timer.lap("traffic")
+			}
 
 			// URI linting: static analysis before diff.
-			var findings []analyzer.Finding
-			if lintURI {
-				findings = append(findings, analyzer.LintURI(uri)...)
+			if lintURI && uri != "" {
+				stream.add(lintConnectionURI(uri)...)
 			}
 
 			// Run diff
-			findings = append(findings, analyzer.Diff(&scan, collections)...)
-			if profile {
+			diffPipeline := analyzer.NewPipeline(analyzer.DiffDetectors(&scan)...)
+			stream.add(trunc.dropUnverified(diffPipeline.Collect(collections))...)
+			timer.detectors(diffPipeline.Timings())
+
+			// Upsert keys without a unique index: count existing duplicates
+			// so the suggestion says what must be cleaned up first. Snapshots
+			// have no documents, so the count stays unknown.
+			uniqueCands := analyzer.FindUniqueKeyCandidates(&scan, collections)
+			for i := 0; snapshot == "" && i < len(uniqueCands); i++ {
+				c := &uniqueCands[i]
+				n, dupErr := inspector.CountDuplicateKeys(ctx, c.Database, c.Collection, c.Fields)
+				if dupErr != nil {
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: duplicate check skipped: %v\n", dupErr)
+					continue
+				}
+				c.Duplicates = n
+			}
+			stream.add(analyzer.SuggestUniqueIndexes(uniqueCands)...)
+			stream.add(analyzer.DetectUnencryptedSensitiveFields(classification, collections, &scan)...)
+			stream.add(analyzer.DetectDriverCompat(&scan, info.Version)...)
+			stream.add(analyzer.DetectODMRisks(&scan, collections)...)
+			stream.add(trunc.dropUnverified(analyzer.DetectFixtureDrift(&scan, collections))...)
+			timer.lap("analyze")
+			var profileEntries []mongoinspect.ProfileEntry
+			if profile && trunc.stopped() {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: profiler correlation skipped: %s\n", trunc.reason())
+			} else if profile {
 				entries, profileErr := inspector.ReadProfiler(ctx, database, int64(profileLimit))
-				if profileErr != nil {
+				switch {
+				case profileErr != nil && trunc.wasInterrupted():
+					_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "warning: profiler correlation skipped: interrupted")
+				case profileErr != nil:
 					return fmt.Errorf("read profiler: %w", profileErr)
-				}
-				if len(entries) == 0 {
+				case len(entries) == 0:
 					_, _ = fmt.Fprintf(cmd.ErrOrStderr(),
 						"Hint: no profiler entries found in system.profile. Profiler may be disabled; enable with db.setProfilingLevel(1) and rerun with --profile.\n")
+				default:
+					profileEntries = entries
+					stream.add(analyzer.CorrelateProfiler(&scan, entries)...)
+				}
+				timer.lap("profiler")
+			}
+			stream.add(analyzer.ClassifyReadWrite(&scan, collections, profileEntries)...)
+			if (clientApps || connections) && !trunc.stopped() {
+				ops, opsErr := inspector.CurrentOps(ctx)
+				if opsErr != nil {
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: client connection checks skipped: %v\n", opsErr)
 				} else {
-					findings = append(findings, analyzer.CorrelateProfiler(&scan, entries)...)
+					if clientApps {
+						stream.add(analyzer.AttributeClientApps(&scan, ops, profileEntries)...)
+					}
+					if connections {
+						var pool *mongoinspect.ConnPoolStats
+						if stats, poolErr := inspector.ConnPoolStats(ctx); poolErr != nil {
+							_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: connPoolStats unavailable: %v\n", poolErr)
+						} else {
+							pool = &stats
+						}
+						stream.add(analyzer.AuditClientConnections(ops, pool, info.Version)...)
+					}
 				}
+				timer.lap("clients")
 			}
-			if sampleSize > 0 {
-				samples, sampleErr := inspector.SampleDocuments(ctx, database, int64(sampleSize))
-				if sampleErr != nil {
+			if staleMonths > 0 {
+				if touched := fileLastTouched(ctx, cmd, &scan); touched != nil {
+					cutoff := time.Now().AddDate(0, -staleMonths, 0)
+					stream.add(analyzer.DetectDeadCollections(&scan, collections, touched, cutoff)...)
+				}
+				timer.lap("git")
+			}
+			var samples []mongoinspect.FieldSampleResult
+			if sampleSize > 0 && trunc.stopped() {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: document sampling skipped: %s\n", trunc.reason())
+			} else if sampleSize > 0 {
+				var sampleErr error
+				samples, sampleErr = inspector.SampleDocuments(ctx, database, int64(sampleSize))
+				if sampleErr != nil && trunc.wasInterrupted() {
+					_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "warning: document sampling skipped: interrupted")
+				} else if sampleErr != nil {
 					return fmt.Errorf("sample documents: %w", sampleErr)
 				}
+				warnMemoryCapped(cmd.ErrOrStderr(), samples)
 				if len(samples) > 0 {
-					findings = append(findings, analyzer.DetectSchemaDrift(&scan, samples)...)
-					findings = append(findings, analyzer.DetectAntiPatterns(samples)...)
+					stream.add(analyzer.DetectSchemaDrift(&scan, samples)...)
+					stream.add(analyzer.DetectAntiPatterns(samples)...)
+					stream.add(analyzer.DetectTTLFieldTypes(collections, samples)...)
+					stream.add(analyzer.DetectPII(samples)...)
+					stream.add(analyzer.DetectStaleData(collections, samples)...)
+					stream.add(analyzer.DetectArchiveCandidates(collections, samples)...)
+					stream.add(analyzer.DetectVectorFieldMismatch(collections, samples)...)
+					collections = mergeDataAge(collections, samples)
+				}
+				timer.lap("sampling")
+			}
+			if naming != nil {
+				stream.add(naming.Lint(collections, samples)...)
+				timer.lap("naming")
+			}
+			var shardingInfo *mongoinspect.ShardingInfo
+			if sharding {
+				shardInfo, shardingErr := inspector.InspectSharding(ctx)
+				switch {
+				case shardingErr != nil:
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: shard key suggestions skipped: %v\n", shardingErr)
+				case !shardInfo.Enabled:
+					_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Shard key suggestions skipped: deployment is not sharded.")
+				default:
+					shardingInfo = &shardInfo
+					stream.add(analyzer.SuggestShardKeys(&scan, collections, shardInfo, samples)...)
 				}
+				timer.lap("sharding")
 			}
+			if len(scan.TransactionRefs) > 0 {
+				// Transaction metrics only sharpen the code findings, so
+				// snapshots and failures fall back to the code alone.
+				var txStats *mongoinspect.TransactionStats
+				if snapshot == "" && !trunc.stopped() {
+					stats, txErr := inspector.InspectTransactions(ctx)
+					if txErr != nil {
+						_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: transaction metrics unavailable: %v\n", txErr)
+					} else {
+						txStats = &stats
+					}
+				}
+				stream.add(analyzer.DetectTransactionRisks(&scan, collections, txStats, shardingInfo)...)
+				timer.lap("transactions")
+			}
+			lintedURI := ""
+			if lintURI {
+				lintedURI = uri
+			}
+			if len(scan.WriteConcernRefs) > 0 || len(scan.ReadSettingRefs) > 0 ||
+				analyzer.URISetsWriteConcern(lintedURI) || analyzer.URISetsReadPreference(lintedURI) {
+				// w:0, j:false and reads after writes are risky on any
+				// deployment; the other checks need the replica set topology.
+				var rsInfo *mongoinspect.ReplicaSetInfo
+				if snapshot == "" && !trunc.stopped() {
+					rs, rsErr := inspector.InspectReplicaSet(ctx)
+					if rsErr != nil {
+						_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: write and read concern topology checks skipped: %v\n", rsErr)
+					} else {
+						rsInfo = &rs
+					}
+				}
+				stream.add(analyzer.DetectWriteConcernRisks(&scan, lintedURI, rsInfo)...)
+				stream.add(analyzer.DetectReadConsistencyRisks(&scan, lintedURI, rsInfo)...)
+				timer.lap("consistency")
+			}
+			if len(scan.OperatorRefs) > 0 && snapshot == "" && !trunc.stopped() {
+				live := readOperatorLive(ctx, cmd, inspector, &scan, info.Version, uri)
+				stream.add(analyzer.DetectOperatorDrift(&scan, live)...)
+				timer.lap("operator")
+			}
+			var oplogProfile *mongoinspect.OplogProfile
+			if oplog {
+				if oplogProfile = sampleOplog(ctx, cmd, inspector, database, oplogLimit); oplogProfile != nil {
+					stream.add(analyzer.AuditOplog(oplogProfile)...)
+					stream.add(analyzer.DetectShadowWriters(&scan, oplogProfile)...)
+				}
+				timer.lap("oplog")
+			}
+			stream.add(analyzer.DetectUnenforcedRetention(retention, collections, samples, oplogProfile)...)
 
 			// Baseline: load collections for growth detection, then diff findings.
 			var baselineFindings []analyzer.Finding
@@ -155,60 +398,90 @@ func newCheckCmd() *cobra.Command {
 				if len(baselineCollections) > 0 && len(collections) > 0 {
 					elapsed := time.Since(baselineTime)
 					if !baselineTime.IsZero() {
-						findings = append(findings, analyzer.DetectGrowth(collections, baselineCollections, elapsed)...)
+						stream.add(analyzer.DetectGrowth(collections, baselineCollections, elapsed)...)
 					}
 				}
+				timer.lap("baseline")
 			}
 
-			// Apply ignore file.
-			if !noIgnore {
-				cwd, _ := os.Getwd()
-				il, ilErr := analyzer.LoadIgnoreFile(cwd)
-				if ilErr != nil {
-					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: %v\n", ilErr)
-				}
-				var suppressed int
-				findings, suppressed = il.Filter(findings)
-				if verbose && suppressed > 0 {
-					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Suppressed %d findings via .mongospectreignore\n", suppressed)
-				}
+			// Ignore rules were applied as findings were added; per-tenant
+			// collections are reported once per pattern.
+			findings, streamErr := stream.finish()
+			if streamErr != nil {
+				return fmt.Errorf("write report: %w", streamErr)
 			}
 
-			// Baseline diff display.
+			// Baseline diff display. NDJSON output stays one finding per
+			// line, so the diff goes to stderr instead.
 			if baseline != "" {
+				diffOut := cmd.OutOrStdout()
+				if stream.streaming() {
+					diffOut = cmd.ErrOrStderr()
+				}
 				diff := analyzer.DiffBaseline(findings, baselineFindings)
-				reporter.WriteBaselineDiff(cmd.OutOrStdout(), diff)
+				reporter.WriteBaselineDiff(diffOut, diff, i18n.Lang(lang))
 			}
 
 			report := reporter.NewReport(findings)
+			summary.report = &report
 			report.Metadata = reporter.Metadata{
-				Version:        version,
-				Command:        "check",
-				Host:           host,
-				Database:       database,
-				MongoDBVersion: info.Version,
-				RepoPath:       repo,
-				URIHash:        reporter.HashURI(uri),
-			}
-			scanCopy := scan
+				Version:           version,
+				Command:           "check",
+				Host:              host,
+				Database:          database,
+				MongoDBVersion:    info.Version,
+				RepoPath:          repo,
+				URIHash:           uriHash,
+				Cluster:           cluster,
+				Tags:              activeProfile.Tags,
+				Truncated:         trunc.truncated(),
+				Interrupted:       trunc.wasInterrupted(),
+				SkippedNamespaces: trunc.skipped,
+			}
+			// The report and TUI show field names with tagged ones masked;
+			// detection above ran on the real names.
+			scanCopy := stream.redactor.Scan(scan)
 			report.Scan = &scanCopy
-			report.Collections = collections
+			report.Collections = stream.redactor.Collections(collections)
+			report.TenantGroups = analyzer.AggregateTenantGroups(collections, tenantPatterns)
+			report.Performance = timer.finish(cmd.ErrOrStderr())
+			trunc.warn(cmd.ErrOrStderr())
 
-			renderedInteractive, err := maybeRenderInteractive(cmd, &report, collections, &scan, interactiveConfig{
+			renderedInteractive, err := maybeRenderInteractive(cmd, &report, report.Collections, &scanCopy, interactiveConfig{
 				force:    interactive,
-				disable:  noInteractive,
+				disable:  noInteractive || watch || trunc.wasInterrupted(),
 				format:   format,
 				findings: len(findings),
 			})
 			if err != nil {
 				return err
 			}
-			if !renderedInteractive {
-				if err := reporter.Write(cmd.OutOrStdout(), &report, reporter.Format(format)); err != nil {
+			if !renderedInteractive && !stream.streaming() {
+				if err := writeReport(cmd.OutOrStdout(), &report, format, groupBy); err != nil {
 					return fmt.Errorf("write report: %w", err)
 				}
 			}
 
+			if trunc.wasInterrupted() {
+				code := trunc.exitCode()
+				_, _ = fmt.Fprintln(cmd.ErrOrStderr(), reporter.ExitCodeHint(code))
+				return &ExitError{Code: code}
+			}
+
+			// Watch mode keeps the collection metadata read above and
+			// re-checks the code as it changes, until interrupted.
+			if watch {
+				return watchRepo(runCtx, cmd, repo, collections, func() *findingStream {
+					s := newFindingStream(cmd, format, noIgnore, ignoreFile, tenantPatterns)
+					s.stderr = io.Discard
+					s.ownerMap = ownerMap
+					s.owners = stream.owners
+					s.owner = filterOwner
+					s.redactor = stream.redactor
+					return s
+				})
+			}
+
 			if failOnMissing {
 				for _, f := range findings {
 					if f.Type == analyzer.FindingMissingCollection {
@@ -225,21 +498,39 @@ func newCheckCmd() *cobra.Command {
 				return &ExitError{Code: code}
 			}
 			return nil
-		},
+		}),
 	}
 
 	cmd.Flags().StringVar(&repo, "repo", "", "path to code repository to scan")
 	cmd.Flags().StringVar(&database, "database", "", "specific database to check (default: all non-system)")
-	cmd.Flags().StringVarP(&format, "format", "f", "text", "output format: text, json, sarif, or spectrehub")
+	cmd.Flags().StringVarP(&format, "format", "f", "text", "output format: text, json, sarif, spectrehub, ndjson (one finding per line), or lsp-json (LSP diagnostics per file, for editor plugins)")
 	cmd.Flags().BoolVar(&failOnMissing, "fail-on-missing", false, "exit 2 if any MISSING_COLLECTION found")
 	cmd.Flags().BoolVar(&profile, "profile", false, "read system.profile and correlate slow queries to source locations")
 	cmd.Flags().IntVar(&profileLimit, "profile-limit", 1000, "maximum number of profiler entries to read")
-	cmd.Flags().IntVar(&sampleSize, "sample", 0, "sample N documents per collection for field-level drift detection (0 to disable)")
-	cmd.Flags().BoolVar(&noIgnore, "no-ignore", false, "bypass .mongospectreignore file")
+	cmd.Flags().IntVar(&sampleSize, "sample-size", 0, "sample N documents per collection for field-level drift detection (0 to disable)")
+	cmd.Flags().IntVar(&sampleSize, "sample", 0, "sample N documents per collection (deprecated alias of --sample-size)")
+	cmd.Flags().BoolVar(&pii, "pii", false, "match sampled string values against PII patterns and report POSSIBLE_PII_FIELD (requires --sample-size; values never leave memory)")
+	_ = cmd.Flags().MarkDeprecated("sample", "use --sample-size instead")
+	cmd.Flags().BoolVar(&noIgnore, "no-ignore", false, "bypass ignore files")
+	cmd.Flags().StringVar(&ignoreFile, "ignore-file", "", "extra ignore file merged with .mongospectreignore and ~/.config/mongospectre/ignore")
 	cmd.Flags().StringVar(&baseline, "baseline", "", "path to previous JSON report for diff comparison")
 	cmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "launch interactive terminal UI (text format only)")
 	cmd.Flags().BoolVar(&noInteractive, "no-interactive", false, "force non-interactive output")
+	cmd.Flags().StringVar(&groupBy, "group-by", "", "group text output by finding type, collection or owner: type, collection, owner")
 	cmd.Flags().BoolVar(&lintURI, "lint-uri", true, "lint MongoDB URI for common misconfigurations")
+	cmd.Flags().StringVar(&snapshot, "snapshot", "", "analyze a snapshot from export-snapshot, or a mongodump directory or archive, instead of connecting to MongoDB")
+	cmd.Flags().BoolVar(&sharding, "sharding", false, "suggest shard keys for large unsharded collections referenced in code (requires access to config database)")
+	cmd.Flags().BoolVar(&oplog, "oplog", false, "sample the newest oplog entries for write hotspots and writers missing from code (requires read access to local)")
+	cmd.Flags().Int64Var(&oplogLimit, "oplog-limit", mongoinspect.DefaultOplogSampleLimit, "maximum number of oplog entries to read with --oplog (capped at 100000)")
+	cmd.Flags().IntVar(&staleMonths, "git-stale-months", 0, "flag collections whose referencing files are all unchanged in git for N months and that have no operations (0 to disable)")
+	cmd.Flags().BoolVar(&blame, "blame", false, "add the author and commit that last changed the code line to findings with a code location (runs git blame)")
+	cmd.Flags().StringVar(&filterOwner, "filter-owner", "", "only report findings owned by this owner (from CODEOWNERS or the owners config), e.g. @org/team-billing")
+	cmd.Flags().BoolVar(&watch, "watch", false, "after the report, watch the repo and re-check changed files against the collection metadata already read, printing new and resolved findings")
+	cmd.Flags().BoolVar(&connections, "connections", false, "report client connections by source host and driver version from $currentOp and connPoolStats, flagging drivers older than the server version supports (requires clusterMonitor)")
+	cmd.Flags().BoolVar(&clientApps, "client-apps", false, "attribute $currentOp connections and, with --profile, profiler entries to client appNames, flagging apps the repo does not name (requires clusterMonitor)")
+	cmd.Flags().DurationVar(&trafficSample, "traffic-sample", 0, "watch change streams for this long (e.g. 60s) and use per-collection write rates to refine findings (must be shorter than --timeout)")
+
+	summary.addFlags(cmd)
 
 	return cmd
 }
@@ -260,3 +551,33 @@ func mergeCollectionValidators(collections []mongoinspect.CollectionInfo, valida
 	}
 	return collections
 }
+
+// mergeDataAge copies each sample's document age span onto its collection,
+// for the collection inventory.
+func mergeDataAge(collections []mongoinspect.CollectionInfo, samples []mongoinspect.FieldSampleResult) []mongoinspect.CollectionInfo {
+	ageByCollection := make(map[string]*mongoinspect.DataAge, len(samples))
+	for i := range samples {
+		if age := samples[i].Age(); age != nil {
+			ageByCollection[strings.ToLower(samples[i].Database+"."+samples[i].Collection)] = age
+		}
+	}
+
+	for i := range collections {
+		key := strings.ToLower(collections[i].Database + "." + collections[i].Name)
+		if age, ok := ageByCollection[key]; ok {
+			collections[i].DataAge = age
+		}
+	}
+	return collections
+}
+
+// warnMemoryCapped notes collections whose sample stopped at the memory
+// limit, so their field statistics cover fewer documents than requested.
+func warnMemoryCapped(w io.Writer, samples []mongoinspect.FieldSampleResult) {
+	for _, s := range samples {
+		if s.MemoryCapped {
+			_, _ = fmt.Fprintf(w, "warning: sample of %s.%s stopped at the memory limit after %d documents\n",
+				s.Database, s.Collection, s.SampleSize)
+		}
+	}
+}
@@ -4,11 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ppiankov/mongospectre/internal/analyzer"
+	"github.com/ppiankov/mongospectre/internal/gitinfo"
 	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
 	"github.com/ppiankov/mongospectre/internal/reporter"
 	"github.com/ppiankov/mongospectre/internal/scanner"
@@ -432,3 +437,809 @@ func TestCheckProfileLimitValidation(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestCheckShardingSuggestsShardKeys(t *testing.T) {
+	stubScanRepo(t, func(string) (scanner.ScanResult, error) {
+		return scanner.ScanResult{
+			Collections: []string{"orders"},
+			Refs:        []scanner.CollectionRef{{Collection: "orders"}},
+			FieldRefs: []scanner.FieldRef{
+				{Collection: "orders", Field: "customer_id", Usage: scanner.FieldUsageEquality},
+			},
+		}, nil
+	})
+	fake := &fakeInspector{
+		serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"},
+		inspectResult: []mongoinspect.CollectionInfo{
+			{Database: "app", Name: "orders", DocCount: 10, StorageSize: 2 << 30, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+		},
+		shardingRes: mongoinspect.ShardingInfo{Enabled: true, BalancerEnabled: true, Shards: []string{"a", "b"}},
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) { return fake, nil })
+
+	stdout, _, err := execCLI(t, "check", "--uri", "mongodb://stub", "--repo", t.TempDir(), "--format", "json", "--sharding")
+	requireExitCode(t, err, 1)
+	if fake.inspectShardingCalls != 1 {
+		t.Fatalf("expected InspectSharding to be called once, got %d", fake.inspectShardingCalls)
+	}
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	found := false
+	for _, f := range report.Findings {
+		if f.Type == analyzer.FindingShardingCandidate && strings.Contains(f.Message, `{customer_id: "hashed"}`) {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected SHARDING_CANDIDATE, got %+v", report.Findings)
+	}
+}
+
+func TestCheckUniqueIndexSuggestionCountsDuplicates(t *testing.T) {
+	stubScanRepo(t, func(string) (scanner.ScanResult, error) {
+		return scanner.ScanResult{
+			Collections: []string{"users"},
+			Refs:        []scanner.CollectionRef{{Collection: "users"}},
+			UpsertRefs:  []scanner.UpsertRef{{Collection: "users", Fields: []string{"email"}, File: "a.js", Line: 3}},
+		}, nil
+	})
+	fake := &fakeInspector{
+		serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"},
+		inspectResult: []mongoinspect.CollectionInfo{
+			{Database: "app", Name: "users", DocCount: 10, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+		},
+		duplicatesRes: map[string]int64{"app.users:email": 4},
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) { return fake, nil })
+
+	stdout, _, err := execCLI(t, "check", "--uri", "mongodb://stub", "--repo", t.TempDir(), "--format", "json")
+	requireExitCode(t, err, 1)
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	for _, f := range report.Findings {
+		if f.Type == analyzer.FindingUniqueIndexSuggest {
+			if !strings.Contains(f.Message, "4 duplicates must be resolved first") {
+				t.Fatalf("message = %q", f.Message)
+			}
+			return
+		}
+	}
+	t.Fatalf("expected UNIQUE_INDEX_SUGGEST, got %+v", report.Findings)
+}
+
+func TestCheckVectorSearchIndexes(t *testing.T) {
+	stubScanRepo(t, func(string) (scanner.ScanResult, error) {
+		return scanner.ScanResult{
+			Collections: []string{"movies"},
+			Refs:        []scanner.CollectionRef{{Collection: "movies"}},
+			SearchRefs: []scanner.SearchRef{{Collection: "movies", Stage: scanner.SearchStageVector, Index: "plot_vectors",
+				Paths: []string{"plot_embedding"}, FilterFields: []string{"year"}, File: "search.js", Line: 4}},
+			EmbeddingRefs: []scanner.EmbeddingRef{{Model: "text-embedding-3-large", Dimensions: 3072, File: "search.js", Line: 2}},
+		}, nil
+	})
+	fake := &fakeInspector{
+		serverInfo: mongoinspect.ServerInfo{Version: "8.0.0"},
+		inspectResult: []mongoinspect.CollectionInfo{
+			{Database: "app", Name: "movies", DocCount: 10, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+		},
+		searchIndexes: map[string][]mongoinspect.SearchIndex{"app.movies": {{Name: "plot_vectors", Type: "vectorSearch",
+			VectorFields: []mongoinspect.VectorIndexField{{Path: "plot_embedding", Dimensions: 1536, Similarity: "cosine"}}}}},
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) { return fake, nil })
+
+	stdout, _, err := execCLI(t, "check", "--uri", "mongodb://stub", "--repo", t.TempDir(), "--format", "json", "--lint-uri=false")
+	requireExitCode(t, err, 2)
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	assertHasType(t, report.Findings, analyzer.FindingVectorDimMismatch)
+	assertHasType(t, report.Findings, analyzer.FindingVectorFilterNotIndexed)
+
+	fake.searchIndexesErr = errors.New("$listSearchIndexes stage is only allowed on MongoDB Atlas")
+	_, stderr, _ := execCLI(t, "check", "--uri", "mongodb://stub", "--repo", t.TempDir(), "--lint-uri=false")
+	if !strings.Contains(stderr, "vector search index checks skipped") {
+		t.Fatalf("expected warning, got: %q", stderr)
+	}
+}
+
+func TestCheckTransactions(t *testing.T) {
+	stubScanRepo(t, func(string) (scanner.ScanResult, error) {
+		return scanner.ScanResult{
+			Collections: []string{"orders"},
+			Refs:        []scanner.CollectionRef{{Collection: "orders", File: "checkout.js", Line: 3}},
+			TransactionRefs: []scanner.TransactionRef{{Collections: []string{"orders"}, Risks: []string{"network call"},
+				File: "checkout.js", Line: 2, EndLine: 6}},
+		}, nil
+	})
+	fake := &fakeInspector{
+		serverInfo: mongoinspect.ServerInfo{Version: "8.0.0"},
+		inspectResult: []mongoinspect.CollectionInfo{
+			{Database: "shop", Name: "orders", DocCount: 10, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+		},
+		txStatsRes: mongoinspect.TransactionStats{Started: 500, Aborted: 100, LifetimeSeconds: 60},
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) { return fake, nil })
+
+	stdout, _, err := execCLI(t, "check", "--uri", "mongodb://stub", "--repo", t.TempDir(), "--format", "json", "--lint-uri=false")
+	requireExitCode(t, err, 2)
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	assertHasType(t, report.Findings, analyzer.FindingLongTransactionRisk)
+
+	fake.txStatsErr = errors.New("not authorized on admin")
+	_, stderr, _ := execCLI(t, "check", "--uri", "mongodb://stub", "--repo", t.TempDir(), "--lint-uri=false")
+	if !strings.Contains(stderr, "transaction metrics unavailable") {
+		t.Fatalf("expected warning, got: %q", stderr)
+	}
+}
+
+func TestCheckWriteConcern(t *testing.T) {
+	stubScanRepo(t, func(string) (scanner.ScanResult, error) {
+		return scanner.ScanResult{
+			Collections: []string{"orders"},
+			Refs:        []scanner.CollectionRef{{Collection: "orders", File: "orders.js", Line: 3}},
+			WriteConcernRefs: []scanner.WriteConcernRef{
+				{Collection: "orders", W: "majority", File: "orders.js", Line: 3},
+				{W: "0", File: "metrics.js", Line: 9},
+			},
+		}, nil
+	})
+	fake := &fakeInspector{
+		serverInfo: mongoinspect.ServerInfo{Version: "8.0.0"},
+		inspectResult: []mongoinspect.CollectionInfo{
+			{Database: "shop", Name: "orders", DocCount: 10, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+		},
+		replsetRes: mongoinspect.ReplicaSetInfo{Name: "rs0", Members: []mongoinspect.ReplicaSetMember{
+			{Name: "a:27017", StateStr: "PRIMARY", Health: 1, Votes: 1},
+			{Name: "b:27017", StateStr: "SECONDARY", Health: 1, Votes: 1},
+			{Name: "c:27017", StateStr: "ARBITER", Health: 1, Votes: 1},
+		}},
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) { return fake, nil })
+
+	stdout, _, err := execCLI(t, "check", "--uri", "mongodb://stub", "--repo", t.TempDir(), "--format", "json", "--lint-uri=false")
+	requireExitCode(t, err, 2)
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	assertHasType(t, report.Findings, analyzer.FindingUnsafeWriteConcern)
+	assertHasType(t, report.Findings, analyzer.FindingWMajorityLatency)
+
+	fake.replsetErr = errors.New("not authorized on admin")
+	_, stderr, _ := execCLI(t, "check", "--uri", "mongodb://stub", "--repo", t.TempDir(), "--lint-uri=false")
+	if !strings.Contains(stderr, "write and read concern topology checks skipped") {
+		t.Fatalf("expected warning, got: %q", stderr)
+	}
+}
+
+func TestCheckReadConsistency(t *testing.T) {
+	stubScanRepo(t, func(string) (scanner.ScanResult, error) {
+		return scanner.ScanResult{
+			Collections: []string{"orders"},
+			Refs:        []scanner.CollectionRef{{Collection: "orders", File: "orders.js", Line: 3}},
+			ReadSettingRefs: []scanner.ReadSettingRef{
+				{Collection: "orders", Mode: "secondary", Function: 1, AfterWrite: 2, File: "orders.js", Line: 3},
+			},
+		}, nil
+	})
+	fake := &fakeInspector{
+		serverInfo: mongoinspect.ServerInfo{Version: "8.0.0"},
+		inspectResult: []mongoinspect.CollectionInfo{
+			{Database: "shop", Name: "orders", DocCount: 10, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+		},
+		replsetRes: mongoinspect.ReplicaSetInfo{Name: "rs0", Members: []mongoinspect.ReplicaSetMember{
+			{Name: "a:27017", StateStr: "PRIMARY", Health: 1, Votes: 1},
+		}},
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) { return fake, nil })
+
+	stdout, _, err := execCLI(t, "check", "--uri", "mongodb://stub", "--repo", t.TempDir(), "--format", "json", "--lint-uri=false")
+	requireExitCode(t, err, 2)
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	assertHasType(t, report.Findings, analyzer.FindingCausalConsistencyRisk)
+	assertHasType(t, report.Findings, analyzer.FindingReadPrefNoSecondary)
+}
+
+func TestCheckClientApps(t *testing.T) {
+	stubScanRepo(t, func(string) (scanner.ScanResult, error) {
+		return scanner.ScanResult{
+			Collections: []string{"orders"},
+			Refs:        []scanner.CollectionRef{{Collection: "orders", File: "db.js", Line: 3}},
+			AppNameRefs: []scanner.AppNameRef{{Name: "checkout-api", File: "db.js", Line: 1}},
+		}, nil
+	})
+	fake := &fakeInspector{
+		serverInfo: mongoinspect.ServerInfo{Version: "8.0.0"},
+		inspectResult: []mongoinspect.CollectionInfo{
+			{Database: "shop", Name: "orders", DocCount: 10, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+		},
+		currentOpsRes: []mongoinspect.ClientOp{
+			{AppName: "checkout-api", Database: "shop", Collection: "orders", Op: "query"},
+			{AppName: "legacy-sync", Database: "shop", Collection: "orders", Op: "update"},
+		},
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) { return fake, nil })
+
+	stdout, _, err := execCLI(t, "check", "--uri", "mongodb://stub", "--repo", t.TempDir(), "--format", "json", "--lint-uri=false", "--client-apps")
+	requireExitCode(t, err, 1)
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	assertHasType(t, report.Findings, analyzer.FindingClientAppTraffic)
+	assertHasType(t, report.Findings, analyzer.FindingUnknownClientApp)
+
+	fake.currentOpsErr = errors.New("not authorized on admin to execute command")
+	_, stderr, _ := execCLI(t, "check", "--uri", "mongodb://stub", "--repo", t.TempDir(), "--lint-uri=false", "--client-apps")
+	if !strings.Contains(stderr, "client connection checks skipped") {
+		t.Fatalf("expected warning, got: %q", stderr)
+	}
+
+	_, _, err = execCLI(t, "check", "--snapshot", "snap.json", "--repo", t.TempDir(), "--client-apps")
+	if err == nil || !strings.Contains(err.Error(), "--client-apps") {
+		t.Fatalf("expected --snapshot rejection, got %v", err)
+	}
+}
+
+func TestCheckConnections(t *testing.T) {
+	stubScanRepo(t, func(string) (scanner.ScanResult, error) {
+		return scanner.ScanResult{}, nil
+	})
+	fake := &fakeInspector{
+		serverInfo: mongoinspect.ServerInfo{Version: "8.0.0"},
+		currentOpsRes: []mongoinspect.ClientOp{
+			{Client: "10.0.0.5:51234", DriverName: "nodejs", DriverVersion: "6.8.0"},
+			{Client: "10.0.0.6:51234", DriverName: "PyMongo", DriverVersion: "4.1.1"},
+		},
+		connPoolRes: mongoinspect.ConnPoolStats{InUse: 1, Available: 4, Created: 9, Hosts: 2},
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) { return fake, nil })
+
+	stdout, _, err := execCLI(t, "check", "--uri", "mongodb://stub", "--repo", t.TempDir(), "--format", "json", "--lint-uri=false", "--connections")
+	requireExitCode(t, err, 1)
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	assertHasType(t, report.Findings, analyzer.FindingClientConnSources)
+	assertHasType(t, report.Findings, analyzer.FindingDriverOutdated)
+
+	fake.connPoolErr = errors.New("not authorized on admin to execute command")
+	_, stderr, _ := execCLI(t, "check", "--uri", "mongodb://stub", "--repo", t.TempDir(), "--lint-uri=false", "--connections")
+	if !strings.Contains(stderr, "connPoolStats unavailable") {
+		t.Fatalf("expected warning, got: %q", stderr)
+	}
+}
+
+func TestCheckDriverCompat(t *testing.T) {
+	stubScanRepo(t, func(string) (scanner.ScanResult, error) {
+		return scanner.ScanResult{
+			DriverRefs: []scanner.DriverRef{{Package: "pymongo", Version: "4.1", File: "requirements.txt", Line: 1}},
+		}, nil
+	})
+	fake := &fakeInspector{serverInfo: mongoinspect.ServerInfo{Version: "8.0.0"}}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) { return fake, nil })
+
+	stdout, _, err := execCLI(t, "check", "--uri", "mongodb://stub", "--repo", t.TempDir(), "--format", "json", "--lint-uri=false")
+	requireExitCode(t, err, 1)
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	assertHasType(t, report.Findings, analyzer.FindingDriverServerCompat)
+}
+
+func TestCheckODMRisks(t *testing.T) {
+	stubScanRepo(t, func(string) (scanner.ScanResult, error) {
+		return scanner.ScanResult{
+			Collections: []string{"users"},
+			Refs:        []scanner.CollectionRef{{Collection: "users", File: "models.js", Line: 9, Pattern: scanner.PatternORM}},
+			ODMRefs: []scanner.ODMRef{
+				{ODM: scanner.ODMMongoose, Kind: scanner.ODMIndex, Collection: "users", Fields: []string{"email"}, File: "models.js", Line: 3},
+			},
+		}, nil
+	})
+	fake := &fakeInspector{
+		serverInfo: mongoinspect.ServerInfo{Version: "8.0.0"},
+		inspectResult: []mongoinspect.CollectionInfo{
+			{Database: "app", Name: "users", DocCount: 10, Indexes: []mongoinspect.IndexInfo{{Name: "_id_", Key: []mongoinspect.KeyField{{Field: "_id", Direction: 1}}}}},
+		},
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) { return fake, nil })
+
+	stdout, _, err := execCLI(t, "check", "--uri", "mongodb://stub", "--repo", t.TempDir(), "--format", "json", "--lint-uri=false")
+	requireExitCode(t, err, 1)
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	assertHasType(t, report.Findings, analyzer.FindingODMAutoIndex)
+	assertHasType(t, report.Findings, analyzer.FindingODMIndexDrift)
+}
+
+func TestCheckFixtureDrift(t *testing.T) {
+	stubScanRepo(t, func(string) (scanner.ScanResult, error) {
+		return scanner.ScanResult{
+			FixtureRefs: []scanner.FixtureRef{{Collection: "legacy_sessions", Docs: 2, MongoData: true, File: "seeds/seed.json", Line: 2}},
+		}, nil
+	})
+	fake := &fakeInspector{serverInfo: mongoinspect.ServerInfo{Version: "8.0.0"}}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) { return fake, nil })
+
+	stdout, _, err := execCLI(t, "check", "--uri", "mongodb://stub", "--repo", t.TempDir(), "--format", "json", "--lint-uri=false")
+	requireExitCode(t, err, 1)
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	assertHasType(t, report.Findings, analyzer.FindingFixtureStaleCollection)
+}
+
+func TestCheckOperatorDrift(t *testing.T) {
+	stubScanRepo(t, func(string) (scanner.ScanResult, error) {
+		return scanner.ScanResult{
+			OperatorRefs: []scanner.OperatorRef{{
+				Kind: scanner.CRDMongoDBCommunity, Name: "shop-db", Version: "7.0.2", Members: 3, Topology: "ReplicaSet",
+				Users: []scanner.OperatorUser{{Name: "app", DB: "admin", Line: 20}},
+				File:  "k8s/mongodb.yaml", Line: 2,
+			}},
+		}, nil
+	})
+	fake := &fakeInspector{
+		serverInfo: mongoinspect.ServerInfo{Version: "7.0.2"},
+		replsetRes: mongoinspect.ReplicaSetInfo{Name: "shop-db", Members: []mongoinspect.ReplicaSetMember{
+			{StateStr: "PRIMARY"}, {StateStr: "SECONDARY"}, {StateStr: "SECONDARY"},
+		}},
+		inspectUsersErr: map[string]error{"admin": errors.New("not authorized on admin")},
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) { return fake, nil })
+
+	stdout, stderr, err := execCLI(t, "check", "--uri", "mongodb://stub", "--repo", t.TempDir(), "--format", "json", "--lint-uri=false")
+	if err != nil {
+		t.Fatalf("expected clean exit with matching topology, got %v", err)
+	}
+	if !strings.Contains(stderr, "warning: operator user check skipped on admin: not authorized on admin") {
+		t.Fatalf("expected user check warning, got: %s", stderr)
+	}
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	for _, f := range report.Findings {
+		if f.Type == analyzer.FindingOperatorConfigDrift || f.Type == analyzer.FindingOperatorUserDrift {
+			t.Fatalf("unexpected operator finding: %+v", f)
+		}
+	}
+
+	fake.replsetRes.Members = fake.replsetRes.Members[:2]
+	delete(fake.inspectUsersErr, "admin")
+	stdout, _, err = execCLI(t, "check", "--uri", "mongodb://stub", "--repo", t.TempDir(), "--format", "json", "--lint-uri=false")
+	requireExitCode(t, err, 1)
+	report = reporter.Report{}
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	assertHasType(t, report.Findings, analyzer.FindingOperatorConfigDrift)
+	assertHasType(t, report.Findings, analyzer.FindingOperatorUserDrift)
+}
+
+func TestCheckPartialInspection(t *testing.T) {
+	stubScanRepo(t, func(string) (scanner.ScanResult, error) {
+		return scanner.ScanResult{
+			Collections: []string{"users", "orders", "ghosts"},
+			Refs: []scanner.CollectionRef{
+				{Collection: "users", File: "main.go", Line: 10, Pattern: scanner.PatternDriverCall},
+				{Collection: "orders", File: "main.go", Line: 11, Pattern: scanner.PatternDriverCall},
+				{Collection: "ghosts", File: "main.go", Line: 12, Pattern: scanner.PatternDriverCall},
+			},
+			FilesScanned: 1,
+		}, nil
+	})
+
+	fake := &fakeInspector{
+		serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"},
+		inspectResult: []mongoinspect.CollectionInfo{
+			{Database: "app", Name: "users", DocCount: 25, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+		},
+		inspectErr: &mongoinspect.PartialError{
+			Skipped: []string{"app.orders"},
+			Err:     context.DeadlineExceeded,
+		},
+		validatorsErr: errors.New("validators must not be read after the timeout"),
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return fake, nil
+	})
+
+	stdout, stderr, err := execCLI(t, "check", "--uri", "mongodb://stub", "--repo", t.TempDir(), "--database", "app", "--format", "json", "--timeout", "1s")
+	requireExitCode(t, err, 2)
+	if !strings.Contains(stderr, "report is partial: 1 namespaces not inspected") {
+		t.Fatalf("expected partial warning, got: %q", stderr)
+	}
+
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	if !report.Metadata.Truncated || len(report.Metadata.SkippedNamespaces) != 1 || report.Metadata.SkippedNamespaces[0] != "app.orders" {
+		t.Fatalf("metadata = %+v, want truncated with app.orders skipped", report.Metadata)
+	}
+	var missing []string
+	for _, f := range report.Findings {
+		if f.Type == analyzer.FindingMissingCollection {
+			missing = append(missing, f.Collection)
+		}
+	}
+	if len(missing) != 1 || missing[0] != "ghosts" {
+		t.Fatalf("missing collections = %v, want [ghosts]", missing)
+	}
+}
+
+func TestCheckOplogShadowWriters(t *testing.T) {
+	stubScanRepo(t, func(string) (scanner.ScanResult, error) {
+		return scanner.ScanResult{
+			Collections:  []string{"users"},
+			Refs:         []scanner.CollectionRef{{Collection: "users"}},
+			FilesScanned: 1,
+		}, nil
+	})
+	fake := &fakeInspector{
+		serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"},
+		inspectResult: []mongoinspect.CollectionInfo{
+			{Database: "app", Name: "users", DocCount: 25, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+		},
+		oplogRes: mongoinspect.OplogProfile{
+			Writes: 40,
+			Namespaces: []mongoinspect.NamespaceWrites{
+				{Database: "app", Collection: "audit_trail", Inserts: 30},
+				{Database: "app", Collection: "users", Updates: 10},
+			},
+		},
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return fake, nil
+	})
+
+	stdout, _, err := execCLI(t, "check", "--uri", "mongodb://stub", "--repo", t.TempDir(), "--oplog", "--oplog-limit", "500", "--format", "json", "--timeout", "1s")
+	requireExitCode(t, err, 1)
+	if len(fake.oplogCalls) != 1 || fake.oplogCalls[0] != 500 {
+		t.Fatalf("SampleOplog calls = %v, want [500]", fake.oplogCalls)
+	}
+
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	var shadow []string
+	for _, f := range report.Findings {
+		if f.Type == analyzer.FindingShadowWriter {
+			shadow = append(shadow, f.Collection)
+		}
+	}
+	if len(shadow) != 1 || shadow[0] != "audit_trail" {
+		t.Fatalf("SHADOW_WRITER findings on %v, want [audit_trail]", shadow)
+	}
+}
+
+func TestCheckOplogUnreadableIsWarning(t *testing.T) {
+	stubScanRepo(t, func(string) (scanner.ScanResult, error) {
+		return scanner.ScanResult{Collections: []string{"users"}, FilesScanned: 1}, nil
+	})
+	fake := &fakeInspector{
+		serverInfo:    mongoinspect.ServerInfo{Version: "7.0.0"},
+		inspectResult: []mongoinspect.CollectionInfo{{Database: "app", Name: "users", DocCount: 25}},
+		oplogErr:      errors.New("not authorized on local"),
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return fake, nil
+	})
+
+	_, stderr, err := execCLI(t, "check", "--uri", "mongodb://stub", "--repo", t.TempDir(), "--oplog", "--timeout", "1s")
+	if err != nil {
+		t.Fatalf("check returned error: %v", err)
+	}
+	if !strings.Contains(stderr, "warning: oplog analysis skipped: not authorized on local") {
+		t.Fatalf("expected oplog warning, got: %q", stderr)
+	}
+}
+
+func TestCheckGitStaleMonths(t *testing.T) {
+	stubScanRepo(t, func(repo string) (scanner.ScanResult, error) {
+		return scanner.ScanResult{
+			RepoPath:    repo,
+			Collections: []string{"promo_codes", "users"},
+			Refs: []scanner.CollectionRef{
+				{Collection: "promo_codes", File: "promo/legacy.js", Line: 4},
+				{Collection: "users", File: "users.js", Line: 2},
+			},
+			FilesScanned: 2,
+		}, nil
+	})
+	old := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	var looked []string
+	stubLastCommitTime(t, func(_ context.Context, _, file string) (time.Time, error) {
+		looked = append(looked, file)
+		if file == "users.js" {
+			return time.Now(), nil
+		}
+		return old, nil
+	})
+	noOps := []mongoinspect.IndexInfo{{Name: "_id_", Stats: &mongoinspect.IndexStats{}}}
+	fake := &fakeInspector{
+		serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"},
+		inspectResult: []mongoinspect.CollectionInfo{
+			{Database: "app", Name: "promo_codes", DocCount: 3, Indexes: noOps},
+			{Database: "app", Name: "users", DocCount: 25, Indexes: noOps},
+		},
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return fake, nil
+	})
+
+	stdout, _, err := execCLI(t, "check", "--uri", "mongodb://stub", "--repo", t.TempDir(), "--git-stale-months", "6", "--format", "json", "--timeout", "1s")
+	if err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+	if strings.Join(looked, ",") != "promo/legacy.js,users.js" {
+		t.Fatalf("git history read for %v", looked)
+	}
+
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	var dead []string
+	for _, f := range report.Findings {
+		if f.Type == analyzer.FindingLikelyDeadCollection {
+			dead = append(dead, f.Collection)
+			if !strings.Contains(f.Message, "2024-01-15") {
+				t.Errorf("message %q lacks the last-touched date", f.Message)
+			}
+		}
+	}
+	if len(dead) != 1 || dead[0] != "promo_codes" {
+		t.Fatalf("LIKELY_DEAD_COLLECTION findings on %v, want [promo_codes]", dead)
+	}
+}
+
+func TestCheckGitStaleMonthsWithoutHistoryIsWarning(t *testing.T) {
+	stubScanRepo(t, func(repo string) (scanner.ScanResult, error) {
+		return scanner.ScanResult{
+			RepoPath:     repo,
+			Collections:  []string{"users"},
+			Refs:         []scanner.CollectionRef{{Collection: "users", File: "users.js"}},
+			FilesScanned: 1,
+		}, nil
+	})
+	stubLastCommitTime(t, func(context.Context, string, string) (time.Time, error) {
+		return time.Time{}, errors.New("git log: exit status 128: not a git repository")
+	})
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return &fakeInspector{
+			serverInfo:    mongoinspect.ServerInfo{Version: "7.0.0"},
+			inspectResult: []mongoinspect.CollectionInfo{{Database: "app", Name: "users", DocCount: 1}},
+		}, nil
+	})
+
+	_, stderr, err := execCLI(t, "check", "--uri", "mongodb://stub", "--repo", t.TempDir(), "--git-stale-months", "6", "--timeout", "1s")
+	if err != nil {
+		t.Fatalf("check failed: %v", err)
+	}
+	if !strings.Contains(stderr, "warning: git history skipped: git log: exit status 128: not a git repository") {
+		t.Fatalf("stderr = %q", stderr)
+	}
+}
+
+func TestCheckBlame(t *testing.T) {
+	stubScanRepo(t, func(repo string) (scanner.ScanResult, error) {
+		return scanner.ScanResult{
+			RepoPath:    repo,
+			Collections: []string{"users", "ghosts"},
+			Refs: []scanner.CollectionRef{
+				{Collection: "users", File: "users.js", Line: 2},
+				{Collection: "ghosts", File: "ghosts.js", Line: 7},
+			},
+			FilesScanned: 2,
+		}, nil
+	})
+	var calls []string
+	stubBlameLine(t, func(_ context.Context, _, file string, line int) (gitinfo.Blame, bool, error) {
+		calls = append(calls, fmt.Sprintf("%s:%d", file, line))
+		return gitinfo.Blame{Commit: "3f2a9c41d0e5", Author: "Dana", Email: "dana@example.com"}, true, nil
+	})
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return &fakeInspector{
+			serverInfo:    mongoinspect.ServerInfo{Version: "7.0.0"},
+			inspectResult: []mongoinspect.CollectionInfo{{Database: "app", Name: "users", DocCount: 1}},
+		}, nil
+	})
+
+	stdout, _, err := execCLI(t, "check", "--uri", "mongodb://stub", "--repo", t.TempDir(), "--blame", "--format", "json", "--timeout", "1s")
+	requireExitCode(t, err, 2)
+	if len(calls) != 1 || calls[0] != "ghosts.js:7" {
+		t.Fatalf("blame calls = %v, want [ghosts.js:7]", calls)
+	}
+
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	for _, f := range report.Findings {
+		if f.Type != analyzer.FindingMissingCollection {
+			continue
+		}
+		if f.File != "ghosts.js" || f.Line != 7 || f.Blame == nil || f.Blame.Author != "Dana" {
+			t.Fatalf("MISSING_COLLECTION = %+v", f)
+		}
+		return
+	}
+	t.Fatal("no MISSING_COLLECTION finding")
+}
+
+func TestCheckCodeOwners(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repo, "CODEOWNERS"), []byte("*  @org/platform\n/billing/  @org/Billing\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stubScanRepo(t, func(repo string) (scanner.ScanResult, error) {
+		return scanner.ScanResult{
+			RepoPath:    repo,
+			Collections: []string{"ledger", "ghosts"},
+			Refs: []scanner.CollectionRef{
+				{Collection: "ledger", File: "billing/ledger.js", Line: 3},
+				{Collection: "ghosts", File: "ghosts.js", Line: 7},
+			},
+			FilesScanned: 2,
+		}, nil
+	})
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return &fakeInspector{serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"}}, nil
+	})
+
+	stdout, _, err := execCLI(t, "check", "--uri", "mongodb://stub", "--repo", repo, "--filter-owner", "@org/billing", "--format", "json", "--timeout", "1s")
+	requireExitCode(t, err, 2)
+
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	if len(report.Findings) != 1 {
+		t.Fatalf("findings = %+v, want only the billing one", report.Findings)
+	}
+	if f := report.Findings[0]; f.Collection != "ledger" || f.Owner != "@org/Billing" {
+		t.Errorf("finding = %+v", f)
+	}
+}
+
+func TestCheckWatchRequiresTextOutput(t *testing.T) {
+	_, _, err := execCLI(t, "check", "--uri", "mongodb://stub", "--repo", t.TempDir(), "--watch", "--format", "json")
+	if err == nil || !strings.Contains(err.Error(), "--watch supports only text output") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestCodeWatcherReportsNewAndResolvedFindings(t *testing.T) {
+	repo := t.TempDir()
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, "app.js"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`db.collection("users").find({})` + "\n")
+	index, err := scanner.NewIndex(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newRootCmd(testBuildInfo)
+	var stdout, stderr strings.Builder
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	w := &codeWatcher{
+		cmd:         cmd,
+		index:       index,
+		collections: []mongoinspect.CollectionInfo{{Database: "app", Name: "users", DocCount: 1}},
+		newStream:   func() *findingStream { return &findingStream{stderr: &stderr} },
+	}
+
+	// Edit the file twice before the debounced rescan: only the final
+	// content counts.
+	changes := make(chan string, 2)
+	write(`db.collection("users").find({})` + "\n" + `db.collection("ghosts").find({})` + "\n")
+	changes <- filepath.Join(repo, "app.js")
+	changes <- filepath.Join(repo, "notes.txt")
+	close(changes)
+	if err := w.run(context.Background(), changes); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "+ [new] MISSING_COLLECTION") || !strings.Contains(stdout.String(), `"ghosts"`) {
+		t.Fatalf("stdout = %q", stdout.String())
+	}
+
+	stdout.Reset()
+	write(`db.collection("users").find({})` + "\n")
+	changes = make(chan string, 1)
+	changes <- filepath.Join(repo, "app.js")
+	close(changes)
+	if err := w.run(context.Background(), changes); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "- [resolved] MISSING_COLLECTION") {
+		t.Fatalf("stdout = %q", stdout.String())
+	}
+}
+
+func TestWatchFilesSendsChangedPaths(t *testing.T) {
+	repo := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes, err := watchFiles(ctx, repo, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Files in a directory created after the watch started are sent too.
+	if err := os.MkdirAll(filepath.Join(repo, "jobs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(repo, "jobs", "sync.go")
+	if err := os.WriteFile(want, []byte("package jobs\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case path := <-changes:
+			if path == want {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("no event for %s", want)
+		}
+	}
+}
+
+func TestCheckLSPJSON(t *testing.T) {
+	repo := t.TempDir()
+	stubScanRepo(t, func(repo string) (scanner.ScanResult, error) {
+		return scanner.ScanResult{
+			RepoPath:     repo,
+			Collections:  []string{"ghosts"},
+			Refs:         []scanner.CollectionRef{{Collection: "ghosts", File: "ghosts.js", Line: 7}},
+			FilesScanned: 1,
+		}, nil
+	})
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return &fakeInspector{
+			serverInfo:    mongoinspect.ServerInfo{Version: "7.0.0"},
+			inspectResult: []mongoinspect.CollectionInfo{{Database: "app", Name: "users", DocCount: 1}},
+		}, nil
+	})
+
+	stdout, _, err := execCLI(t, "check", "--uri", "mongodb://stub", "--repo", repo, "--format", "lsp-json", "--timeout", "1s")
+	requireExitCode(t, err, 2)
+	var files []struct {
+		URI         string `json:"uri"`
+		Diagnostics []struct {
+			Code string `json:"code"`
+		} `json:"diagnostics"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &files); err != nil {
+		t.Fatalf("invalid LSP JSON: %v\n%s", err, stdout)
+	}
+	if len(files) != 1 || !strings.HasSuffix(files[0].URI, "/ghosts.js") || len(files[0].Diagnostics) == 0 {
+		t.Fatalf("diagnostics = %+v", files)
+	}
+}
@@ -0,0 +1,204 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	"github.com/ppiankov/mongospectre/internal/i18n"
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/reporter"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+// watchDebounce is how long check --watch waits after a file event for
+// more, so that an editor's burst of writes triggers a single rescan.
+var watchDebounce = 300 * time.Millisecond
+
+// codeWatcher re-runs the code-vs-database checks for check --watch when
+// source files change. The collection metadata is read once at startup;
+// only the changed files are rescanned.
+type codeWatcher struct {
+	cmd         *cobra.Command
+	index       *scanner.Index
+	collections []mongoinspect.CollectionInfo
+	newStream   func() *findingStream
+
+	previous      []analyzer.Finding
+	runs          int
+	totalNew      int
+	totalResolved int
+}
+
+// watchRepo indexes repo and reports findings added or resolved by file
+// changes until ctx is done.
+func watchRepo(ctx context.Context, cmd *cobra.Command, repo string, collections []mongoinspect.CollectionInfo, newStream func() *findingStream) error {
+	index, err := scanner.NewIndex(repo)
+	if err != nil {
+		return fmt.Errorf("scan repo: %w", err)
+	}
+	changes, err := watchFiles(ctx, repo, cmd.ErrOrStderr())
+	if err != nil {
+		return fmt.Errorf("watch %s: %w", repo, err)
+	}
+	w := &codeWatcher{cmd: cmd, index: index, collections: collections, newStream: newStream}
+	return w.run(ctx, changes)
+}
+
+// findings runs the diff detectors over the indexed repo.
+func (w *codeWatcher) findings() []analyzer.Finding {
+	scan := w.index.Result()
+	stream := w.newStream()
+	stream.add(analyzer.NewPipeline(analyzer.DiffDetectors(&scan)...).Collect(w.collections)...)
+	findings, _ := stream.finish()
+	return findings
+}
+
+// run rescans the paths received on changes, debounced, and prints how the
+// findings changed. It returns when ctx is done or, after a last rescan,
+// when changes is closed.
+func (w *codeWatcher) run(ctx context.Context, changes <-chan string) error {
+	stderr := w.cmd.ErrOrStderr()
+	w.previous = w.findings()
+	_, _ = fmt.Fprintf(stderr, "Watching for file changes (%d code findings); press Ctrl-C to stop\n", len(w.previous))
+
+	pending := make(map[string]bool)
+	var fire <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			_, _ = fmt.Fprintf(stderr, "\nWatch summary: %d rescans, %d new findings, %d resolved\n",
+				w.runs, w.totalNew, w.totalResolved)
+			return nil
+		case path, ok := <-changes:
+			if !ok {
+				w.rescan(pending)
+				return nil
+			}
+			pending[path] = true
+			fire = time.After(watchDebounce)
+		case <-fire:
+			fire = nil
+			w.rescan(pending)
+			clear(pending)
+		}
+	}
+}
+
+// rescan updates the index for paths and, if any references changed,
+// prints the findings added, resolved or changed since the last rescan.
+func (w *codeWatcher) rescan(paths map[string]bool) {
+	changed := false
+	for path := range paths {
+		if w.index.Update(path) {
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+
+	w.runs++
+	current := w.findings()
+	diff := analyzer.DiffBaseline(current, w.previous)
+	w.previous = current
+	var newCount, resolvedCount, changedCount int
+	for _, d := range diff {
+		switch d.Status {
+		case analyzer.StatusNew:
+			newCount++
+		case analyzer.StatusResolved:
+			resolvedCount++
+		case analyzer.StatusChanged:
+			changedCount++
+		}
+	}
+	w.totalNew += newCount
+	w.totalResolved += resolvedCount
+	if newCount > 0 || resolvedCount > 0 || changedCount > 0 {
+		stdout := w.cmd.OutOrStdout()
+		_, _ = fmt.Fprintf(stdout, "[%s]\n", time.Now().Format(time.TimeOnly))
+		reporter.WriteBaselineDiff(stdout, diff, i18n.Lang(lang))
+	} else if verbose {
+		_, _ = fmt.Fprintf(w.cmd.ErrOrStderr(), "[%s] no changes (%d findings)\n",
+			time.Now().Format(time.TimeOnly), len(current))
+	}
+}
+
+// watchFiles watches every directory under root that the scanner reads and
+// sends the paths of changed files and directories. Directories created
+// later are watched too, and the files already in them sent, so a copy or
+// checkout is picked up.
+func watchFiles(ctx context.Context, root string, stderr io.Writer) (<-chan string, error) {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := addWatchTree(fw, root, nil); err != nil {
+		_ = fw.Close()
+		return nil, err
+	}
+
+	out := make(chan string)
+	go func() {
+		defer close(out)
+		defer func() { _ = fw.Close() }()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case ev, ok := <-fw.Events:
+				if !ok {
+					return
+				}
+				paths := []string{ev.Name}
+				if ev.Has(fsnotify.Create) && !scanner.SkipDir(filepath.Base(ev.Name)) {
+					if info, err := os.Stat(ev.Name); err == nil && info.IsDir() {
+						if err := addWatchTree(fw, ev.Name, func(path string) { paths = append(paths, path) }); err != nil {
+							_, _ = fmt.Fprintf(stderr, "warning: watch %s: %v\n", ev.Name, err)
+						}
+					}
+				}
+				for _, path := range paths {
+					select {
+					case out <- path:
+					case <-ctx.Done():
+						return
+					}
+				}
+			case err, ok := <-fw.Errors:
+				if !ok {
+					return
+				}
+				_, _ = fmt.Fprintf(stderr, "warning: file watch: %v\n", err)
+			}
+		}
+	}()
+	return out, nil
+}
+
+// addWatchTree watches root and the directories below it, skipping the
+// ones the scanner skips, and passes every file found to file.
+func addWatchTree(fw *fsnotify.Watcher, root string, file func(string)) error {
+	return filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil // skip unreadable entries
+		}
+		if !d.IsDir() {
+			if file != nil {
+				file(path)
+			}
+			return nil
+		}
+		if path != root && scanner.SkipDir(d.Name()) {
+			return filepath.SkipDir
+		}
+		return fw.Add(path)
+	})
+}
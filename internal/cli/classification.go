@@ -0,0 +1,20 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+)
+
+// configClassification compiles the config's sensitive data tags.
+func configClassification() (analyzer.Classification, error) {
+	rules := make([]analyzer.SensitiveRule, 0, len(cfg.Classification))
+	for _, c := range cfg.Classification {
+		rules = append(rules, analyzer.SensitiveRule{Database: c.Database, Collection: c.Collection, Fields: c.Fields, Tag: c.Tag})
+	}
+	classification, err := analyzer.NewClassification(rules)
+	if err != nil {
+		return nil, fmt.Errorf("classification: %w", err)
+	}
+	return classification, nil
+}
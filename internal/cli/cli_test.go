@@ -535,7 +535,7 @@ func TestWatchRunTextShutdownSummary(t *testing.T) {
 	cmd.SetErr(&stderr)
 	w := &watcher{
 		uri:      "mongodb://localhost:1",
-		interval: 100 * time.Millisecond,
+		schedule: runSchedule{interval: 100 * time.Millisecond},
 		format:   "text",
 		cmd:      cmd,
 	}
@@ -560,7 +560,7 @@ func TestWatchRunJSONShutdownEvent(t *testing.T) {
 	cmd.SetErr(&stderr)
 	w := &watcher{
 		uri:      "mongodb://localhost:1",
-		interval: 100 * time.Millisecond,
+		schedule: runSchedule{interval: 100 * time.Millisecond},
 		format:   "json",
 		cmd:      cmd,
 	}
@@ -581,7 +581,7 @@ func TestWatchRunNoIgnore(t *testing.T) {
 	cmd.SetErr(&bytes.Buffer{})
 	w := &watcher{
 		uri:      "mongodb://localhost:1",
-		interval: 100 * time.Millisecond,
+		schedule: runSchedule{interval: 100 * time.Millisecond},
 		format:   "text",
 		noIgnore: true,
 		cmd:      cmd,
@@ -633,6 +633,30 @@ func TestConfigFileVerboseDefault(t *testing.T) {
 	verbose = false
 }
 
+func TestConfigFileLangDefault(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(dir+"/.mongospectre.yml", []byte("defaults:\n  lang: de-AT\n"), 0o644)
+
+	origDir, _ := os.Getwd()
+	_ = os.Chdir(dir)
+	defer func() { _ = os.Chdir(origDir) }()
+
+	uri = ""
+	cmd := silentCmd("audit")
+	_ = cmd.Execute()
+	if lang != "de" {
+		t.Errorf("lang = %q, want de from config", lang)
+	}
+	lang = ""
+}
+
+func TestInvalidLang(t *testing.T) {
+	_, _, err := execCLI(t, "rules", "--lang", "fr")
+	if err == nil || !strings.Contains(err.Error(), `unsupported language "fr"`) {
+		t.Errorf("expected unsupported language error, got %v", err)
+	}
+}
+
 func TestConfigFileTimeoutDefault(t *testing.T) {
 	// Config sets timeout, verify it's applied when flag not set.
 	dir := t.TempDir()
@@ -661,7 +685,7 @@ func TestWatchExitOnNewFlag(t *testing.T) {
 	cmd.SetErr(&bytes.Buffer{})
 	w := &watcher{
 		uri:       "mongodb://localhost:1",
-		interval:  100 * time.Millisecond,
+		schedule:  runSchedule{interval: 100 * time.Millisecond},
 		format:    "text",
 		exitOnNew: true,
 		cmd:       cmd,
@@ -692,7 +716,7 @@ func TestWatchDatabaseFlag(t *testing.T) {
 	w := &watcher{
 		uri:      "mongodb://localhost:1",
 		database: "testdb",
-		interval: 100 * time.Millisecond,
+		schedule: runSchedule{interval: 100 * time.Millisecond},
 		format:   "text",
 		cmd:      cmd,
 	}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"github.com/ppiankov/mongospectre/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// activeProfile is the clusters: entry selected with --cluster. It is the
+// zero value when --cluster is unset or names a keyring-only alias.
+var activeProfile config.Cluster
+
+// resolveCluster applies the --cluster profile: its URI (or the keyring entry
+// of the same name) unless --uri is given explicitly.
+func resolveCluster(cmd *cobra.Command) error {
+	activeProfile = config.Cluster{}
+	if cluster == "" {
+		return nil
+	}
+	activeProfile = cfg.Clusters[cluster]
+	if cmd.Flags().Changed("uri") {
+		return nil
+	}
+	if activeProfile.URI != "" {
+		uri = activeProfile.URI
+		return nil
+	}
+	var err error
+	uri, err = clusterURI(cluster)
+	return err
+}
+
+// profileDatabase returns the --database value, falling back to the
+// profile's database.
+func profileDatabase(flag string) string {
+	if flag != "" {
+		return flag
+	}
+	return activeProfile.Database
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+// commandLogPath is set by --command-log; commandLogOut is the open log of
+// the current run, nil without the flag.
+var (
+	commandLogPath string
+	commandLogOut  *commandLogFile
+)
+
+// commandLogEntry is one line of the --command-log file.
+type commandLogEntry struct {
+	Time       time.Time `json:"time"`
+	Server     string    `json:"server,omitempty"`
+	Command    string    `json:"command"`
+	Namespace  string    `json:"namespace"`
+	DurationMs float64   `json:"durationMs"`
+	ReplyBytes int       `json:"replyBytes"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// commandLogFile appends the commands run on MongoDB to a file as JSON
+// lines. The first write error is kept and reported on close.
+type commandLogFile struct {
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+	err error
+}
+
+// openCommandLog opens path for appending, so that repeated runs build up
+// one audit trail.
+func openCommandLog(path string) (*commandLogFile, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("--command-log: %w", err)
+	}
+	return &commandLogFile{f: f, enc: json.NewEncoder(f)}, nil
+}
+
+func (l *commandLogFile) record(c mongoinspect.ExecutedCommand) {
+	entry := commandLogEntry{
+		Time:       c.Started.UTC(),
+		Server:     c.Server,
+		Command:    c.Command,
+		Namespace:  c.Namespace(),
+		DurationMs: float64(c.Duration.Microseconds()) / 1000,
+		ReplyBytes: c.ReplyBytes,
+	}
+	if c.Err != nil {
+		entry.Error = c.Err.Error()
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.enc.Encode(entry); err != nil && l.err == nil {
+		l.err = err
+	}
+}
+
+// close closes the log and returns the first error writing it.
+func (l *commandLogFile) close() error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.f.Close(); err != nil && l.err == nil {
+		l.err = err
+	}
+	return l.err
+}
+
+// commandLogHook returns the mongoinspect.Config.CommandLog hook for this
+// run.
+func commandLogHook() func(mongoinspect.ExecutedCommand) {
+	if commandLogOut == nil {
+		return nil
+	}
+	return commandLogOut.record
+}
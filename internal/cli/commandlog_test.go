@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+func TestCommandLog(t *testing.T) {
+	t.Chdir(t.TempDir())
+	stubNewInspector(t, func(_ context.Context, cfg mongoinspect.Config) (inspector, error) {
+		if cfg.CommandLog == nil {
+			t.Fatal("inspector not configured with a command log")
+		}
+		// Stand in for the driver's command monitor.
+		cfg.CommandLog(mongoinspect.ExecutedCommand{
+			Command: "listIndexes", Database: "app", Collection: "orders", Server: "db1:27017",
+			Started: time.Now(), Duration: 1500 * time.Microsecond, ReplyBytes: 312,
+		})
+		cfg.CommandLog(mongoinspect.ExecutedCommand{
+			Command: "usersInfo", Database: "app", Started: time.Now(), Err: errors.New("not authorized"),
+		})
+		return &fakeInspector{serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"}}, nil
+	})
+
+	path := filepath.Join(t.TempDir(), "commands.jsonl")
+	for range 2 {
+		if _, stderr, err := execCLI(t, "audit", "--uri", "mongodb://stub", "--command-log", path, "--no-ignore", "--lint-uri=false"); err != nil {
+			t.Fatalf("err = %v\n%s", err, stderr)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	var entries []commandLogEntry
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var e commandLogEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			t.Fatalf("invalid line %q: %v", sc.Text(), err)
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("got %d entries, want 4 from two appended runs", len(entries))
+	}
+	if e := entries[0]; e.Command != "listIndexes" || e.Namespace != "app.orders" || e.Server != "db1:27017" ||
+		e.DurationMs != 1.5 || e.ReplyBytes != 312 || e.Error != "" {
+		t.Errorf("entry = %+v", e)
+	}
+	if e := entries[1]; e.Namespace != "app" || e.Error != "not authorized" {
+		t.Errorf("failed entry = %+v", e)
+	}
+}
+
+func TestCommandLogUnwritable(t *testing.T) {
+	t.Chdir(t.TempDir())
+	_, _, err := execCLI(t, "audit", "--uri", "mongodb://stub", "--command-log", filepath.Join(t.TempDir(), "missing", "log.jsonl"))
+	if err == nil {
+		t.Fatal("expected an error for an unwritable command log")
+	}
+}
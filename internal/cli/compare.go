@@ -4,6 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/ppiankov/mongospectre/internal/analyzer"
 	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
@@ -11,18 +15,27 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// compareMatrixReport is the JSON output of compare with several targets.
+type compareMatrixReport struct {
+	analyzer.CompareMatrix
+	Findings []analyzer.CompareFinding `json:"findings"`
+}
+
 func newCompareCmd() *cobra.Command {
 	var (
-		sourceURI string
-		targetURI string
-		sourceDB  string
-		targetDB  string
-		format    string
+		sourceURI  string
+		targetURIs []string
+		sourceDB   string
+		targetDB   string
+		format     string
+		baseline   string
+		noIgnore   bool
+		ignoreFile string
 	)
 
 	cmd := &cobra.Command{
 		Use:   "compare",
-		Short: "Compare schemas across two MongoDB clusters",
+		Short: "Compare schemas across MongoDB clusters",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := validateFormat(format, "text", "json"); err != nil {
 				return err
@@ -30,56 +43,101 @@ func newCompareCmd() *cobra.Command {
 			if sourceURI == "" {
 				return fmt.Errorf("--source is required")
 			}
-			if targetURI == "" {
+			if len(targetURIs) == 0 {
 				return fmt.Errorf("--target is required")
 			}
 
 			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
 			defer cancel()
 
-			// Connect to source.
-			if verbose {
-				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Connecting to source %s...\n", sourceURI)
-			}
-			sourceInspector, err := newInspector(ctx, mongoinspect.Config{
-				URI:      sourceURI,
-				Database: sourceDB,
-			})
+			matrix := len(targetURIs) > 1
+			sourceEnv, err := compareEndpoint(sourceURI)
 			if err != nil {
 				return fmt.Errorf("source: %w", err)
 			}
-			defer func() { _ = sourceInspector.Close(ctx) }()
-
-			sourceColls, err := sourceInspector.Inspect(ctx, sourceDB)
+			sourceColls, err := inspectCompareEnv(ctx, cmd, "source", sourceEnv, sourceDB, matrix)
 			if err != nil {
-				return fmt.Errorf("inspect source: %w", err)
+				return err
 			}
 			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Source: %d collections\n", len(sourceColls))
 
-			// Connect to target.
-			if verbose {
-				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Connecting to target %s...\n", targetURI)
-			}
-			targetInspector, err := newInspector(ctx, mongoinspect.Config{
-				URI:      targetURI,
-				Database: targetDB,
-			})
-			if err != nil {
-				return fmt.Errorf("target: %w", err)
+			envs := []string{sourceEnv.label}
+			envColls := [][]mongoinspect.CollectionInfo{sourceColls}
+			var findings []analyzer.CompareFinding
+			for _, t := range targetURIs {
+				targetEnv, err := compareEndpoint(t)
+				if err != nil {
+					return fmt.Errorf("target: %w", err)
+				}
+				role := "target"
+				if matrix {
+					role = "target " + targetEnv.label
+				}
+				targetColls, err := inspectCompareEnv(ctx, cmd, role, targetEnv, targetDB, matrix)
+				if err != nil {
+					return err
+				}
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s: %d collections\n", strings.ToUpper(role[:1])+role[1:], len(targetColls))
+
+				targetFindings := analyzer.Compare(sourceColls, targetColls)
+				if matrix {
+					for i := range targetFindings {
+						targetFindings[i].Target = targetEnv.label
+					}
+				}
+				findings = append(findings, targetFindings...)
+				envs = append(envs, targetEnv.label)
+				envColls = append(envColls, targetColls)
 			}
-			defer func() { _ = targetInspector.Close(ctx) }()
 
-			targetColls, err := targetInspector.Inspect(ctx, targetDB)
-			if err != nil {
-				return fmt.Errorf("inspect target: %w", err)
+			if !noIgnore {
+				il, ilErr := loadIgnoreRules(cmd.ErrOrStderr(), ignoreFile)
+				if ilErr != nil {
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: %v\n", ilErr)
+				}
+				var hits []int
+				findings, hits = il.FilterCompareHits(findings)
+				writeSuppressed(cmd.ErrOrStderr(), &il, hits)
 			}
-			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Target: %d collections\n", len(targetColls))
 
-			// Compare.
-			findings := analyzer.Compare(sourceColls, targetColls)
+			// With a baseline, only drift that is new since that run gates
+			// the exit code.
+			gating := findings
+			if baseline != "" {
+				baselineFindings, err := analyzer.LoadCompareBaseline(baseline)
+				if err != nil {
+					return fmt.Errorf("load baseline: %w", err)
+				}
+				diff := analyzer.DiffCompareBaseline(findings, baselineFindings)
+				diffOut := cmd.OutOrStdout()
+				if format == "json" {
+					diffOut = cmd.ErrOrStderr()
+				}
+				writeCompareBaselineDiff(diffOut, diff)
+				gating = nil
+				for i := range diff {
+					if diff[i].Status == analyzer.StatusNew {
+						gating = append(gating, diff[i].CompareFinding)
+					}
+				}
+			}
 
-			switch format {
-			case "json":
+			switch {
+			case matrix:
+				report := compareMatrixReport{
+					CompareMatrix: analyzer.BuildCompareMatrix(envs, envColls),
+					Findings:      findings,
+				}
+				if format == "json" {
+					enc := json.NewEncoder(cmd.OutOrStdout())
+					enc.SetIndent("", "  ")
+					if err := enc.Encode(report); err != nil {
+						return fmt.Errorf("write json: %w", err)
+					}
+				} else {
+					writeCompareMatrixText(cmd.OutOrStdout(), &report.CompareMatrix)
+				}
+			case format == "json":
 				enc := json.NewEncoder(cmd.OutOrStdout())
 				enc.SetIndent("", "  ")
 				if err := enc.Encode(findings); err != nil {
@@ -89,39 +147,127 @@ func newCompareCmd() *cobra.Command {
 				writeCompareText(cmd, findings)
 			}
 
-			// Exit code based on severity.
-			maxSev := analyzer.SeverityInfo
-			sevOrder := map[analyzer.Severity]int{
-				analyzer.SeverityInfo:   0,
-				analyzer.SeverityLow:    1,
-				analyzer.SeverityMedium: 2,
-				analyzer.SeverityHigh:   3,
-			}
-			for i := range findings {
-				if sevOrder[findings[i].Severity] > sevOrder[maxSev] {
-					maxSev = findings[i].Severity
-				}
-			}
-			code := analyzer.ExitCode(maxSev)
-			if code != 0 {
-				if hint := reporter.ExitCodeHint(code); hint != "" {
-					_, _ = fmt.Fprintln(cmd.ErrOrStderr(), hint)
-				}
-				return &ExitError{Code: code}
-			}
-			return nil
+			return compareExit(cmd, gating)
 		},
 	}
 
-	cmd.Flags().StringVar(&sourceURI, "source", "", "source MongoDB connection URI")
-	cmd.Flags().StringVar(&targetURI, "target", "", "target MongoDB connection URI")
+	cmd.Flags().StringVar(&sourceURI, "source", "", "source MongoDB connection URI, cluster alias, or snapshot/mongodump path")
+	cmd.Flags().StringArrayVar(&targetURIs, "target", nil, "target MongoDB connection URI, cluster alias, or snapshot/mongodump path (repeat for a matrix report)")
 	cmd.Flags().StringVar(&sourceDB, "source-db", "", "specific database in source (default: all)")
-	cmd.Flags().StringVar(&targetDB, "target-db", "", "specific database in target (default: all)")
+	cmd.Flags().StringVar(&targetDB, "target-db", "", "specific database in targets (default: all)")
 	cmd.Flags().StringVarP(&format, "format", "f", "text", "output format: text or json")
+	cmd.Flags().StringVar(&baseline, "baseline", "", "path to a previous compare JSON output; only new drift affects the exit code")
+	cmd.Flags().BoolVar(&noIgnore, "no-ignore", false, "bypass ignore files")
+	cmd.Flags().StringVar(&ignoreFile, "ignore-file", "", "extra ignore file merged with .mongospectreignore and ~/.config/mongospectre/ignore")
 
 	return cmd
 }
 
+// compareExit returns an ExitError for the highest severity in findings.
+func compareExit(cmd *cobra.Command, findings []analyzer.CompareFinding) error {
+	maxSev := analyzer.SeverityInfo
+	sevOrder := map[analyzer.Severity]int{
+		analyzer.SeverityInfo:   0,
+		analyzer.SeverityLow:    1,
+		analyzer.SeverityMedium: 2,
+		analyzer.SeverityHigh:   3,
+	}
+	for i := range findings {
+		if sevOrder[findings[i].Severity] > sevOrder[maxSev] {
+			maxSev = findings[i].Severity
+		}
+	}
+	code := analyzer.ExitCode(maxSev)
+	if code != 0 {
+		if hint := reporter.ExitCodeHint(code); hint != "" {
+			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), hint)
+		}
+		return &ExitError{Code: code}
+	}
+	return nil
+}
+
+// compareEnv is one side of a comparison: a cluster URI, or a local snapshot
+// or mongodump path.
+type compareEnv struct {
+	uri   string
+	path  string
+	label string
+}
+
+// compareEndpoint resolves a --source/--target value: a connection URI, a
+// cluster alias from clusters:, a snapshot file or mongodump directory or
+// archive, or a keyring alias, in that order.
+func compareEndpoint(value string) (compareEnv, error) {
+	if strings.Contains(value, "://") {
+		if host := reporter.HostFromURI(value); host != "" {
+			return compareEnv{uri: value, label: host}, nil
+		}
+		return compareEnv{uri: value, label: mongoinspect.RedactURI(value)}, nil
+	}
+	if p, ok := cfg.Clusters[value]; ok && p.URI != "" {
+		return compareEnv{uri: p.URI, label: value}, nil
+	}
+	if _, err := os.Stat(value); err == nil {
+		return compareEnv{path: value, label: filepath.Base(value)}, nil
+	}
+	rawURI, err := clusterURI(value)
+	if err != nil {
+		return compareEnv{}, err
+	}
+	return compareEnv{uri: rawURI, label: value}, nil
+}
+
+// inspectCompareEnv connects to one side of a comparison, or loads it from
+// disk, and inspects it. Validators are only needed for the matrix and are
+// fetched best-effort.
+func inspectCompareEnv(ctx context.Context, cmd *cobra.Command, role string, env compareEnv, database string, withValidators bool) ([]mongoinspect.CollectionInfo, error) {
+	var insp inspector
+	if env.path != "" {
+		snap, err := mongoinspect.LoadSnapshotOrDump(env.path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", role, err)
+		}
+		insp = &snapshotInspector{snap: snap}
+	} else {
+		if verbose {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Connecting to %s %s...\n", role, mongoinspect.RedactURI(env.uri))
+		}
+		var err error
+		insp, err = newInspector(ctx, mongoinspect.Config{
+			URI:             env.uri,
+			Database:        database,
+			TLS:             tlsOpts,
+			Concurrency:     concurrency,
+			DatabaseTimeout: dbTimeout,
+			Retries:         retries,
+			StrictReadOnly:  strictReadOnly,
+			OnCommand:       onCommand(),
+			CommandLog:      commandLogHook(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", role, err)
+		}
+	}
+	defer func() { _ = insp.Close(ctx) }()
+
+	colls, err := insp.Inspect(ctx, database)
+	if err != nil {
+		return nil, fmt.Errorf("inspect %s: %w", role, err)
+	}
+	if withValidators {
+		validators, err := insp.GetValidators(ctx, database)
+		if err != nil {
+			if verbose {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Warning: could not read validators from %s: %v\n", role, err)
+			}
+		} else {
+			colls = mergeCollectionValidators(colls, validators)
+		}
+	}
+	return colls, nil
+}
+
 func writeCompareText(cmd *cobra.Command, findings []analyzer.CompareFinding) {
 	if len(findings) == 0 {
 		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No differences found.")
@@ -146,3 +292,75 @@ func writeCompareText(cmd *cobra.Command, findings []analyzer.CompareFinding) {
 
 	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "\n%d differences found\n", len(findings))
 }
+
+// writeCompareBaselineDiff lists new and resolved drift since the baseline,
+// like reporter.WriteBaselineDiff does for audit findings.
+func writeCompareBaselineDiff(w io.Writer, diff []analyzer.CompareBaselineFinding) {
+	var newCount, resolvedCount, unchangedCount int
+	for i := range diff {
+		f := &diff[i]
+		switch f.Status {
+		case analyzer.StatusNew:
+			newCount++
+			_, _ = fmt.Fprintf(w, "+ [%s] %s: %s\n", f.Status, f.Type, f.Message)
+		case analyzer.StatusResolved:
+			resolvedCount++
+			_, _ = fmt.Fprintf(w, "- [%s] %s: %s\n", f.Status, f.Type, f.Message)
+		default:
+			unchangedCount++
+		}
+	}
+	_, _ = fmt.Fprintf(w, "\nBaseline diff: %d new, %d resolved, %d unchanged\n\n",
+		newCount, resolvedCount, unchangedCount)
+}
+
+var matrixSymbols = map[analyzer.MatrixCell]string{
+	analyzer.CellPresent: "✓",
+	analyzer.CellMissing: "✗",
+	analyzer.CellDiffers: "≠",
+}
+
+// writeCompareMatrixText renders the matrix as a table with one column per
+// environment; the first column is the source.
+func writeCompareMatrixText(w io.Writer, m *analyzer.CompareMatrix) {
+	labels := make([]string, len(m.Rows))
+	width := len("COLLECTION")
+	for i := range m.Rows {
+		r := &m.Rows[i]
+		switch r.Kind {
+		case analyzer.MatrixValidator:
+			labels[i] = "  validator"
+		case analyzer.MatrixIndex:
+			labels[i] = "  index " + r.Index
+		default:
+			labels[i] = r.Database + "." + r.Collection
+		}
+		width = max(width, len([]rune(labels[i])))
+	}
+
+	header := fmt.Sprintf("%-*s", width, "COLLECTION")
+	for _, env := range m.Environments {
+		header += "  " + env
+	}
+	_, _ = fmt.Fprintln(w, header)
+
+	inconsistent := 0
+	for i := range m.Rows {
+		r := &m.Rows[i]
+		if !r.Consistent() {
+			inconsistent++
+		}
+		line := labels[i] + strings.Repeat(" ", width-len([]rune(labels[i])))
+		for j, env := range m.Environments {
+			line += "  " + matrixSymbols[r.Cells[j]] + strings.Repeat(" ", max(len([]rune(env))-1, 0))
+		}
+		_, _ = fmt.Fprintln(w, strings.TrimRight(line, " "))
+	}
+
+	_, _ = fmt.Fprintf(w, "\n✓ present  ✗ missing  ≠ differs from %s\n", m.Environments[0])
+	if inconsistent == 0 {
+		_, _ = fmt.Fprintf(w, "All %d items match across %d environments.\n", len(m.Rows), len(m.Environments))
+		return
+	}
+	_, _ = fmt.Fprintf(w, "%d of %d items differ across %d environments\n", inconsistent, len(m.Rows), len(m.Environments))
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -146,3 +148,222 @@ func TestCompareInspectTargetError(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestCompareMatrixMultipleTargets(t *testing.T) {
+	users := mongoinspect.CollectionInfo{Database: "app", Name: "users", Indexes: []mongoinspect.IndexInfo{
+		{Name: "_id_"},
+		{Name: "email_1", Key: []mongoinspect.KeyField{{Field: "email", Direction: 1}}},
+	}}
+	orders := mongoinspect.CollectionInfo{Database: "app", Name: "orders"}
+	inspectors := []*fakeInspector{
+		{inspectResult: []mongoinspect.CollectionInfo{users, orders}},
+		{inspectResult: []mongoinspect.CollectionInfo{users}},
+		{inspectResult: []mongoinspect.CollectionInfo{users, orders}},
+	}
+
+	var uris []string
+	stubNewInspector(t, func(_ context.Context, cfg mongoinspect.Config) (inspector, error) {
+		uris = append(uris, cfg.URI)
+		if len(uris) > len(inspectors) {
+			t.Fatalf("unexpected inspector creation #%d", len(uris))
+		}
+		return inspectors[len(uris)-1], nil
+	})
+
+	stdout, _, err := execCLI(t, "compare",
+		"--source", "mongodb://prod-host",
+		"--target", "mongodb://staging-host",
+		"--target", "mongodb://dev-host",
+		"--timeout", "1s",
+	)
+	requireExitCode(t, err, 2)
+	if len(uris) != 3 {
+		t.Fatalf("expected three inspectors, got %v", uris)
+	}
+	for _, want := range []string{"prod-host  staging-host  dev-host", "app.orders", "index email_1", "1 of 3 items differ across 3 environments"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected %q in output:\n%s", want, stdout)
+		}
+	}
+}
+
+func TestCompareMatrixJSONWithAliases(t *testing.T) {
+	dir := t.TempDir()
+	cfgYAML := `clusters:
+  prod:
+    uri: mongodb://prod-host
+  staging:
+    uri: mongodb://staging-host
+  dev:
+    uri: mongodb://dev-host
+`
+	if err := os.WriteFile(filepath.Join(dir, ".mongospectre.yml"), []byte(cfgYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	var uris []string
+	stubNewInspector(t, func(_ context.Context, cfg mongoinspect.Config) (inspector, error) {
+		uris = append(uris, cfg.URI)
+		coll := mongoinspect.CollectionInfo{Database: "app", Name: "users"}
+		if cfg.URI == "mongodb://dev-host" {
+			coll.Name = "accounts"
+		}
+		return &fakeInspector{inspectResult: []mongoinspect.CollectionInfo{coll}}, nil
+	})
+
+	stdout, _, err := execCLI(t, "compare", "--source", "prod", "--target", "staging", "--target", "dev", "--format", "json", "--timeout", "1s")
+	requireExitCode(t, err, 2)
+	if strings.Join(uris, ",") != "mongodb://prod-host,mongodb://staging-host,mongodb://dev-host" {
+		t.Fatalf("aliases resolved to %v", uris)
+	}
+
+	var report struct {
+		Environments []string                  `json:"environments"`
+		Rows         []analyzer.MatrixRow      `json:"rows"`
+		Findings     []analyzer.CompareFinding `json:"findings"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, stdout)
+	}
+	if strings.Join(report.Environments, ",") != "prod,staging,dev" {
+		t.Fatalf("environments = %v", report.Environments)
+	}
+	if len(report.Rows) != 2 {
+		t.Fatalf("expected 2 rows, got %+v", report.Rows)
+	}
+	for _, f := range report.Findings {
+		if f.Target != "dev" {
+			t.Errorf("unexpected finding for target %q: %+v", f.Target, f)
+		}
+	}
+	if len(report.Findings) != 2 {
+		t.Fatalf("expected 2 findings against dev, got %+v", report.Findings)
+	}
+}
+
+func TestCompareBaselineGatesOnlyNewDrift(t *testing.T) {
+	dir := t.TempDir()
+	baselinePath := filepath.Join(dir, "compare.json")
+	previous := `[{"type":"MISSING_IN_TARGET","severity":"high","database":"app","collection":"orders"}]`
+	if err := os.WriteFile(baselinePath, []byte(previous), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stubInspectors := func(target []mongoinspect.CollectionInfo) {
+		call := 0
+		stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+			call++
+			if call == 1 {
+				return &fakeInspector{inspectResult: []mongoinspect.CollectionInfo{
+					{Database: "app", Name: "orders"},
+					{Database: "app", Name: "users"},
+				}}, nil
+			}
+			return &fakeInspector{inspectResult: target}, nil
+		})
+	}
+
+	// orders is already missing in the baseline: not gating.
+	stubInspectors([]mongoinspect.CollectionInfo{{Database: "app", Name: "users"}})
+	stdout, _, err := execCLI(t, "compare", "--source", "mongodb://source", "--target", "mongodb://target", "--baseline", baselinePath, "--timeout", "1s")
+	if err != nil {
+		t.Fatalf("expected no gating drift, got %v", err)
+	}
+	if !strings.Contains(stdout, "Baseline diff: 0 new, 0 resolved, 1 unchanged") {
+		t.Fatalf("missing baseline summary:\n%s", stdout)
+	}
+
+	// users going missing is new.
+	stubInspectors(nil)
+	stdout, _, err = execCLI(t, "compare", "--source", "mongodb://source", "--target", "mongodb://target", "--baseline", baselinePath, "--timeout", "1s")
+	requireExitCode(t, err, 2)
+	if !strings.Contains(stdout, `+ [new] MISSING_IN_TARGET: collection "users"`) {
+		t.Fatalf("expected users as new drift:\n%s", stdout)
+	}
+}
+
+func TestCompareIgnoreFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".mongospectreignore"), []byte("MISSING_IN_TARGET app.orders\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	stub := func() {
+		call := 0
+		stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+			call++
+			if call == 1 {
+				return &fakeInspector{inspectResult: []mongoinspect.CollectionInfo{{Database: "app", Name: "orders"}}}, nil
+			}
+			return &fakeInspector{}, nil
+		})
+	}
+
+	stub()
+	stdout, _, err := execCLI(t, "compare", "--source", "mongodb://source", "--target", "mongodb://target", "--timeout", "1s")
+	if err != nil {
+		t.Fatalf("ignored drift should not fail: %v", err)
+	}
+	if !strings.Contains(stdout, "No differences found.") {
+		t.Fatalf("unexpected output:\n%s", stdout)
+	}
+
+	stub()
+	_, _, err = execCLI(t, "compare", "--source", "mongodb://source", "--target", "mongodb://target", "--no-ignore", "--timeout", "1s")
+	requireExitCode(t, err, 2)
+}
+
+func TestCompareIgnoreFileSources(t *testing.T) {
+	dir := t.TempDir()
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	if err := os.MkdirAll(filepath.Join(configHome, "mongospectre"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	userFile := filepath.Join(configHome, "mongospectre", "ignore")
+	if err := os.WriteFile(userFile, []byte("# shared\nMISSING_IN_TARGET app.orders\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	extraFile := filepath.Join(dir, "extra-ignore")
+	if err := os.WriteFile(extraFile, []byte("MISSING_IN_TARGET app.users\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	call := 0
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		call++
+		if call == 1 {
+			return &fakeInspector{inspectResult: []mongoinspect.CollectionInfo{{Database: "app", Name: "orders"}, {Database: "app", Name: "users"}}}, nil
+		}
+		return &fakeInspector{}, nil
+	})
+
+	stdout, stderr, err := execCLI(t, "compare", "--source", "mongodb://source", "--target", "mongodb://target",
+		"--ignore-file", extraFile, "--verbose", "--timeout", "1s")
+	if err != nil {
+		t.Fatalf("ignored drift should not fail: %v", err)
+	}
+	if !strings.Contains(stdout, "No differences found.") {
+		t.Fatalf("unexpected output:\n%s", stdout)
+	}
+	for _, want := range []string{
+		"Loaded 1 ignore rules from " + userFile,
+		"Loaded 1 ignore rules from " + extraFile,
+		"Suppressed 2 findings via ignore rules",
+		"1 by MISSING_IN_TARGET app.orders (" + userFile + ":2)",
+		"1 by MISSING_IN_TARGET app.users (" + extraFile + ":1)",
+	} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("stderr missing %q:\n%s", want, stderr)
+		}
+	}
+
+	_, stderr, _ = execCLI(t, "compare", "--source", "mongodb://source", "--target", "mongodb://target",
+		"--ignore-file", filepath.Join(dir, "missing"), "--timeout", "1s")
+	if !strings.Contains(stderr, "warning: ignore file:") {
+		t.Errorf("missing --ignore-file should warn:\n%s", stderr)
+	}
+}
@@ -2,11 +2,22 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
 	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
 
+	"github.com/ppiankov/mongospectre/internal/analyzer"
 	"github.com/ppiankov/mongospectre/internal/atlas"
+	"github.com/ppiankov/mongospectre/internal/gitinfo"
+	"github.com/ppiankov/mongospectre/internal/i18n"
 	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/reporter"
 	"github.com/ppiankov/mongospectre/internal/scanner"
 )
 
@@ -18,10 +29,21 @@ type inspector interface {
 	Inspect(ctx context.Context, database string) ([]mongoinspect.CollectionInfo, error)
 	InspectSharding(ctx context.Context) (mongoinspect.ShardingInfo, error)
 	InspectUsers(ctx context.Context, dbName string) ([]mongoinspect.UserInfo, error)
+	InspectRoles(ctx context.Context, dbName string) ([]mongoinspect.RoleInfo, error)
 	ListDatabases(ctx context.Context, database string) ([]mongoinspect.DatabaseInfo, error)
 	SampleDocuments(ctx context.Context, database string, sampleSize int64) ([]mongoinspect.FieldSampleResult, error)
+	SampleCollection(ctx context.Context, database, collection string, sampleSize int64) (mongoinspect.FieldSampleResult, error)
+	CountDuplicateKeys(ctx context.Context, database, collection string, fields []string) (int64, error)
 	InspectSecurity(ctx context.Context) (mongoinspect.SecurityInfo, error)
+	InspectServerParameters(ctx context.Context) (mongoinspect.ServerParameters, error)
 	InspectReplicaSet(ctx context.Context) (mongoinspect.ReplicaSetInfo, error)
+	InspectCache(ctx context.Context) (mongoinspect.CacheInfo, error)
+	InspectTransactions(ctx context.Context) (mongoinspect.TransactionStats, error)
+	CurrentOps(ctx context.Context) ([]mongoinspect.ClientOp, error)
+	ConnPoolStats(ctx context.Context) (mongoinspect.ConnPoolStats, error)
+	ListSearchIndexes(ctx context.Context, database, collection string) ([]mongoinspect.SearchIndex, error)
+	SampleOplog(ctx context.Context, database string, limit int64) (mongoinspect.OplogProfile, error)
+	SampleTraffic(ctx context.Context, database string, window time.Duration) (mongoinspect.TrafficSample, error)
 }
 
 type atlasClient interface {
@@ -34,6 +56,7 @@ type atlasClient interface {
 	ResolveProjectIDByCluster(ctx context.Context, clusterName string) (string, error)
 	ListDatabaseUsers(ctx context.Context, projectID string) ([]atlas.DatabaseUser, error)
 	ListAccessLogs(ctx context.Context, projectID, clusterName string) ([]atlas.AccessLogEntry, error)
+	ListAccessListEntries(ctx context.Context, projectID string) ([]atlas.AccessListEntry, error)
 }
 
 var (
@@ -43,7 +66,9 @@ var (
 	newAtlasClient = func(cfg atlas.Config) (atlasClient, error) {
 		return atlas.NewClient(cfg)
 	}
-	scanRepo = scanner.Scan
+	scanRepo       = scanner.Scan
+	lastCommitTime = gitinfo.LastCommitTime
+	blameLine      = gitinfo.BlameLine
 )
 
 func validateFormat(format string, allowed ...string) error {
@@ -54,3 +79,155 @@ func validateFormat(format string, allowed ...string) error {
 	}
 	return fmt.Errorf("invalid --format %q (allowed: %s)", format, strings.Join(allowed, ", "))
 }
+
+func validateGroupBy(groupBy string) error {
+	switch reporter.GroupBy(groupBy) {
+	case reporter.GroupByNone, reporter.GroupByType, reporter.GroupByCollection, reporter.GroupByOwner:
+		return nil
+	}
+	return fmt.Errorf("invalid --group-by %q (allowed: type, collection, owner)", groupBy)
+}
+
+// writeReport writes report in format; text output is grouped by groupBy
+// and translated into --lang.
+func writeReport(w io.Writer, report *reporter.Report, format, groupBy string) error {
+	if reporter.Format(format) == reporter.FormatText {
+		if i18n.Lang(lang) != i18n.English {
+			report.Metadata.Lang = lang
+		}
+		return reporter.WriteText(w, report, reporter.GroupBy(groupBy))
+	}
+	return reporter.Write(w, report, reporter.Format(format))
+}
+
+// truncation collects the namespaces skipped when the timeout expires or
+// the run is interrupted mid-inspection, so a report can still be written
+// from partial results.
+type truncation struct {
+	skipped []string
+	// interrupted returns the SIGINT or SIGTERM that cancelled the run,
+	// or nil.
+	interrupted func() os.Signal
+}
+
+// wasInterrupted reports whether the run was cancelled by a signal.
+func (t *truncation) wasInterrupted() bool {
+	return t.interrupted != nil && t.interrupted() != nil
+}
+
+// exitCode is the exit code of an interrupted run: 128 plus the number
+// of the signal received, as a shell reports it.
+func (t *truncation) exitCode() int {
+	if sig, ok := t.interrupted().(syscall.Signal); ok {
+		return 128 + int(sig)
+	}
+	return ExitInterrupted
+}
+
+// stopped reports whether later steps should be skipped because the run
+// already lost its context.
+func (t *truncation) stopped() bool { return t.truncated() || t.wasInterrupted() }
+
+// skip reports whether step should be skipped because the run stopped,
+// with a warning naming the step.
+func (t *truncation) skip(w io.Writer, step string) bool {
+	if !t.stopped() {
+		return false
+	}
+	_, _ = fmt.Fprintf(w, "warning: %s skipped: %s\n", step, t.reason())
+	return true
+}
+
+func (t *truncation) reason() string {
+	if t.wasInterrupted() {
+		return "interrupted"
+	}
+	return "timeout reached"
+}
+
+// absorb records a partial inspection and returns any other error as is.
+func (t *truncation) absorb(err error) error {
+	var partial *mongoinspect.PartialError
+	if errors.As(err, &partial) {
+		t.skipped = append(t.skipped, partial.Skipped...)
+		return nil
+	}
+	return err
+}
+
+func (t *truncation) truncated() bool { return len(t.skipped) > 0 }
+
+// dropUnverified removes MISSING_COLLECTION and FIXTURE_STALE_COLLECTION
+// findings the skipped namespaces could account for: a skipped collection
+// of that name, or any skipped database.
+func (t *truncation) dropUnverified(findings []analyzer.Finding) []analyzer.Finding {
+	if !t.truncated() {
+		return findings
+	}
+	skippedColl := make(map[string]bool)
+	wholeDB := false
+	for _, ns := range t.skipped {
+		if _, coll, ok := strings.Cut(ns, "."); ok {
+			skippedColl[coll] = true
+		} else {
+			wholeDB = true
+		}
+	}
+	kept := findings[:0]
+	for _, f := range findings {
+		missing := f.Type == analyzer.FindingMissingCollection || f.Type == analyzer.FindingFixtureStaleCollection
+		if missing && (wholeDB || skippedColl[f.Collection]) {
+			continue
+		}
+		kept = append(kept, f)
+	}
+	return kept
+}
+
+// warn prints the partial-results warning; the skipped namespaces are
+// listed with --verbose and always kept in the report metadata.
+func (t *truncation) warn(w io.Writer) {
+	switch {
+	case t.wasInterrupted():
+		_, _ = fmt.Fprintf(w, "warning: interrupted, report is partial: %d namespaces not inspected\n", len(t.skipped))
+	case t.truncated():
+		_, _ = fmt.Fprintf(w, "warning: timeout reached, report is partial: %d namespaces not inspected (raise --timeout to cover them)\n",
+			len(t.skipped))
+	default:
+		return
+	}
+	if verbose {
+		for _, ns := range t.skipped {
+			_, _ = fmt.Fprintf(w, "  skipped %s\n", ns)
+		}
+	}
+}
+
+// notifyInterrupt returns a context cancelled on the first SIGINT or
+// SIGTERM and a function returning that signal, or nil. After the first
+// signal the default handling is restored, so a second one terminates the
+// process at once. stop releases the signal handler.
+func notifyInterrupt(parent context.Context) (ctx context.Context, interrupted func() os.Signal, stop func()) {
+	ctx, cancel := context.WithCancel(parent)
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	var got atomic.Value
+	done := make(chan struct{})
+	go func() {
+		select {
+		case sig := <-sigCh:
+			got.Store(sig)
+			signal.Stop(sigCh)
+			cancel()
+		case <-done:
+		}
+	}()
+	return ctx, func() os.Signal {
+			sig, _ := got.Load().(os.Signal)
+			return sig
+		}, func() {
+			signal.Stop(sigCh)
+			close(done)
+			cancel()
+		}
+}
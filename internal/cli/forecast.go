@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+const bytesPerGB = 1 << 30
+
+// storageLimits builds forecast limits from the config thresholds. The cluster
+// limit falls back to the Atlas provisioned disk size when not configured.
+func storageLimits(atlasDiskGB float64) analyzer.StorageLimits {
+	clusterGB := cfg.Thresholds.StorageLimitGB
+	if clusterGB <= 0 {
+		clusterGB = atlasDiskGB
+	}
+	return analyzer.StorageLimits{
+		Collection: int64(cfg.Thresholds.CollectionLimitGB * bytesPerGB),
+		Cluster:    int64(clusterGB * bytesPerGB),
+		Horizon:    time.Duration(cfg.Thresholds.ForecastDays) * 24 * time.Hour,
+	}
+}
+
+// forecastFromHistory loads the baselines in dir, appends the current
+// inspection as the newest snapshot, and forecasts storage limits.
+func forecastFromHistory(dir string, collections []mongoinspect.CollectionInfo, limits analyzer.StorageLimits) ([]analyzer.Finding, error) {
+	paths, err := analyzer.ListBaselines(dir)
+	if err != nil {
+		return nil, err
+	}
+	snaps, err := analyzer.LoadTrendSnapshots(paths)
+	if err != nil {
+		return nil, err
+	}
+	snaps = append(snaps, analyzer.TrendSnapshot{Timestamp: time.Now().UTC(), Collections: collections})
+	trend := analyzer.AnalyzeTrend(snaps)
+	return analyzer.ForecastStorage(&trend, limits), nil
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	"github.com/ppiankov/mongospectre/internal/ftdc"
+	"github.com/ppiankov/mongospectre/internal/reporter"
+	"github.com/spf13/cobra"
+)
+
+func newFTDCCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ftdc",
+		Short: "Read full-time diagnostic data capture (diagnostic.data) files",
+	}
+	cmd.AddCommand(newFTDCAnalyzeCmd())
+	return cmd
+}
+
+func newFTDCAnalyzeCmd() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "analyze <diagnostic.data>",
+		Short: "Report sustained cache pressure, ticket exhaustion and slow checkpoints from FTDC",
+		Long: "Decodes the metrics files mongod writes to its diagnostic.data directory (or a single metrics file)\n" +
+			"and reports WiredTiger cache pressure and read/write ticket exhaustion that lasted for minutes, and\n" +
+			"checkpoints slower than the 60s checkpoint interval. No database connection or server command is\n" +
+			"needed, so it works on files copied from a support bundle or a stopped node.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateFormat(format, "text", "json", "sarif", "spectrehub"); err != nil {
+				return err
+			}
+			path := args[0]
+			info, err := os.Stat(path)
+			if err != nil {
+				return fmt.Errorf("read ftdc: %w", err)
+			}
+
+			summary := analyzer.NewFTDCSummary()
+			add := func(c *ftdc.Chunk) error {
+				summary.Add(c)
+				return nil
+			}
+			files := 1
+			if info.IsDir() {
+				files, err = ftdc.ReadDir(path, add)
+			} else {
+				err = ftdc.ReadFile(path, add)
+			}
+			if err != nil {
+				return fmt.Errorf("read ftdc: %w", err)
+			}
+			if summary.Samples == 0 {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Hint: no FTDC samples found in %s; point at a diagnostic.data directory or a metrics.* file.\n", path)
+			} else {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Read %d samples from %d files, %s to %s\n", summary.Samples, files,
+					summary.From.Format(time.RFC3339), summary.To.Format(time.RFC3339))
+			}
+
+			findings := summary.Findings()
+			report := reporter.NewReport(findings)
+			report.Metadata = reporter.Metadata{
+				Version:   version,
+				Command:   "ftdc analyze",
+				Timestamp: time.Now().UTC().Format(time.RFC3339),
+				Cluster:   cluster,
+				Tags:      activeProfile.Tags,
+			}
+			if err := writeReport(cmd.OutOrStdout(), &report, format, ""); err != nil {
+				return fmt.Errorf("write report: %w", err)
+			}
+
+			code := analyzer.ExitCode(report.MaxSeverity)
+			if code != 0 {
+				if hint := reporter.ExitCodeHint(code); hint != "" {
+					_, _ = fmt.Fprintln(cmd.ErrOrStderr(), hint)
+				}
+				return &ExitError{Code: code}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "text", "output format: text, json, sarif, or spectrehub")
+
+	return cmd
+}
@@ -0,0 +1,27 @@
+package cli
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFTDCAnalyzeEmptyDirectory(t *testing.T) {
+	stdout, stderr, err := execCLI(t, "ftdc", "analyze", t.TempDir(), "--format", "json")
+	if err != nil {
+		t.Fatalf("ftdc analyze returned error: %v", err)
+	}
+	if !strings.Contains(stderr, "no FTDC samples found") {
+		t.Fatalf("expected empty hint, got: %q", stderr)
+	}
+	if !strings.Contains(stdout, `"command": "ftdc analyze"`) {
+		t.Fatalf("expected JSON report, got: %q", stdout)
+	}
+}
+
+func TestFTDCAnalyzeMissingPath(t *testing.T) {
+	_, _, err := execCLI(t, "ftdc", "analyze", filepath.Join(t.TempDir(), "diagnostic.data"))
+	if err == nil || !strings.Contains(err.Error(), "read ftdc") {
+		t.Fatalf("expected read error, got %v", err)
+	}
+}
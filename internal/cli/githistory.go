@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+// fileLastTouched looks up the newest commit time of every file with a
+// collection reference for --git-stale-months. It returns nil when git
+// history cannot be read, which is a warning rather than an error: the
+// repository may not be a git checkout or git may not be installed.
+func fileLastTouched(ctx context.Context, cmd *cobra.Command, scan *scanner.ScanResult) map[string]time.Time {
+	seen := make(map[string]bool)
+	var files []string
+	for _, ref := range scan.Refs {
+		if !seen[ref.File] {
+			seen[ref.File] = true
+			files = append(files, ref.File)
+		}
+	}
+	sort.Strings(files)
+
+	touched := make(map[string]time.Time, len(files))
+	for _, file := range files {
+		t, err := lastCommitTime(ctx, scan.RepoPath, file)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: git history skipped: %v\n", err)
+			return nil
+		}
+		touched[file] = t
+	}
+	if verbose {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Read git history of %d files\n", len(files))
+	}
+	return touched
+}
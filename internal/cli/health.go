@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/server"
+)
+
+// resolveStaleAfter returns the --stale-after value, defaulting to twice the
+// operation timeout plus a minute so a single slow run is not reported as stuck.
+func resolveStaleAfter(flag time.Duration) time.Duration {
+	if flag > 0 {
+		return flag
+	}
+	return 2*timeout + time.Minute
+}
+
+// startHealthServer serves /healthz, /readyz and /lastrun for watch mode.
+// The returned function shuts the server down.
+func startHealthServer(addr string, srv *server.Server, stderr io.Writer) (func(), error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("health listen %s: %w", addr, err)
+	}
+	httpServer := &http.Server{
+		Handler:           srv.HealthHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() { _ = httpServer.Serve(ln) }()
+	_, _ = fmt.Fprintf(stderr, "Health endpoints at http://%s/healthz\n", ln.Addr())
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(ctx)
+	}, nil
+}
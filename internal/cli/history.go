@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/notify"
+	"github.com/spf13/cobra"
+)
+
+// defaultHistoryDir is used when history_dir is not set in the config.
+const defaultHistoryDir = ".mongospectre/history"
+
+// notificationHistoryPath is the delivery log watch --notify appends to.
+func notificationHistoryPath() string {
+	dir := cfg.HistoryDir
+	if dir == "" {
+		dir = defaultHistoryDir
+	}
+	return filepath.Join(dir, "notifications.jsonl")
+}
+
+func newHistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Inspect records kept by watch",
+	}
+	cmd.AddCommand(newHistoryNotificationsCmd())
+	return cmd
+}
+
+func newHistoryNotificationsCmd() *cobra.Command {
+	var (
+		format  string
+		since   time.Duration
+		channel string
+		status  string
+		limit   int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "notifications",
+		Short: "List notification deliveries recorded by watch --notify",
+		Long: "Reads the delivery log that watch --notify writes to history_dir (default .mongospectre/history):\n" +
+			"one entry per notification with channel, event, status and attempts. Use it to find out\n" +
+			"why an alert never arrived: failed, rate limited, queued for a digest, or held by quiet hours.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateFormat(format, "text", "json"); err != nil {
+				return err
+			}
+			deliveries, err := notify.ReadDeliveries(notificationHistoryPath())
+			if err != nil {
+				return fmt.Errorf("read notification history: %w", err)
+			}
+
+			var cutoff time.Time
+			if since > 0 {
+				cutoff = time.Now().Add(-since)
+			}
+			filtered := make([]notify.Delivery, 0, len(deliveries))
+			for _, d := range deliveries {
+				if d.Time.Before(cutoff) ||
+					(channel != "" && d.Channel != channel) ||
+					(status != "" && d.Status != status) {
+					continue
+				}
+				filtered = append(filtered, d)
+			}
+			if limit > 0 && len(filtered) > limit {
+				filtered = filtered[len(filtered)-limit:]
+			}
+
+			if format == "json" {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(filtered)
+			}
+			writeDeliveries(cmd.OutOrStdout(), filtered)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "text", "output format: text or json")
+	cmd.Flags().DurationVar(&since, "since", 0, "only show deliveries from this long ago, e.g. 24h")
+	cmd.Flags().StringVar(&channel, "channel", "", "only show one channel, e.g. slack[0]")
+	cmd.Flags().StringVar(&status, "status", "", "only show one status: sent, failed, dry_run, rate_limited, queued, held or dropped")
+	cmd.Flags().IntVar(&limit, "limit", 50, "show at most this many of the newest deliveries (0 for all)")
+
+	return cmd
+}
+
+func writeDeliveries(w io.Writer, deliveries []notify.Delivery) {
+	if len(deliveries) == 0 {
+		_, _ = fmt.Fprintln(w, "No notification deliveries recorded.")
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "TIME\tCHANNEL\tEVENT\tSTATUS\tATTEMPTS\tFINDING")
+	for _, d := range deliveries {
+		subject := d.Finding
+		if d.Events > 0 {
+			subject = fmt.Sprintf("%d events", d.Events)
+		}
+		if d.Error != "" {
+			subject = strings.TrimSpace(subject + " (" + d.Error + ")")
+		}
+		attempts := "-"
+		if d.Attempts > 0 {
+			attempts = fmt.Sprint(d.Attempts)
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			d.Time.Local().Format("2006-01-02 15:04:05"), d.Channel, d.Event, d.Status, attempts, subject)
+	}
+	_ = tw.Flush()
+}
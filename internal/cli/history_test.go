@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/notify"
+)
+
+func TestHistoryNotifications(t *testing.T) {
+	dir := t.TempDir()
+	origDir, _ := os.Getwd()
+	_ = os.Chdir(dir)
+	defer func() { _ = os.Chdir(origDir) }()
+
+	now := time.Now().UTC()
+	records := []notify.Delivery{
+		{Time: now.Add(-48 * time.Hour), Channel: "slack[0]", Event: "new_high", Finding: "MISSING_INDEX app.orders", Status: notify.DeliverySent, Attempts: 1},
+		{Time: now.Add(-time.Hour), Channel: "slack[0]", Event: "new_high", Finding: "MISSING_INDEX app.users", Status: notify.DeliveryFailed, Attempts: 3, Error: "http 503: unavailable"},
+		{Time: now.Add(-time.Minute), Channel: "email[1]", Event: "digest", Events: 4, Status: notify.DeliverySent, Attempts: 1},
+	}
+	if err := os.MkdirAll(defaultHistoryDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, r := range records {
+		data, _ := json.Marshal(r)
+		lines = append(lines, string(data))
+	}
+	if err := os.WriteFile(filepath.Join(defaultHistoryDir, "notifications.jsonl"), []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, _, err := execCLI(t, "history", "notifications", "--since", "24h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(stdout, "app.orders") || !strings.Contains(stdout, "app.users (http 503: unavailable)") || !strings.Contains(stdout, "4 events") {
+		t.Fatalf("unexpected output:\n%s", stdout)
+	}
+
+	stdout, _, err = execCLI(t, "history", "notifications", "--status", "failed", "--format", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []notify.Delivery
+	if err := json.Unmarshal([]byte(stdout), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, stdout)
+	}
+	if len(got) != 1 || got[0].Attempts != 3 {
+		t.Fatalf("failed deliveries = %+v", got)
+	}
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ppiankov/mongospectre/internal/gitinfo"
+	"github.com/spf13/cobra"
+)
+
+// hookMarker identifies hooks written by install-hook, which it may
+// overwrite without --force.
+const hookMarker = "# Installed by mongospectre install-hook."
+
+func newInstallHookCmd() *cobra.Command {
+	var (
+		repo     string
+		hook     string
+		snapshot string
+		force    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "install-hook",
+		Short: "Install a git hook that runs check against the committed snapshot",
+		Long: `Install a git pre-commit or pre-push hook that runs "mongospectre check --snapshot"
+against a snapshot committed to the repo, so commits that reference
+collections missing from the database are blocked without a connection.
+Create the snapshot with "mongospectre export-snapshot".`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if hook != "pre-commit" && hook != "pre-push" {
+				return fmt.Errorf("--hook must be pre-commit or pre-push, got %q", hook)
+			}
+			if snapshot == "" {
+				return fmt.Errorf("--snapshot is required")
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			defer cancel()
+			top, err := gitinfo.TopLevel(ctx, repo)
+			if err != nil {
+				return fmt.Errorf("%s is not a git repository: %w", repo, err)
+			}
+			hooksDir, err := gitinfo.HooksDir(ctx, repo)
+			if err != nil {
+				return fmt.Errorf("find hooks directory: %w", err)
+			}
+
+			// The hook runs from the top of the work tree, so a relative
+			// snapshot path is stored relative to it.
+			if !filepath.IsAbs(snapshot) {
+				abs, err := filepath.Abs(snapshot)
+				if err != nil {
+					return err
+				}
+				// git reports the top level with symlinks resolved.
+				if dir, err := filepath.EvalSymlinks(filepath.Dir(abs)); err == nil {
+					abs = filepath.Join(dir, filepath.Base(abs))
+				}
+				if rel, err := filepath.Rel(top, abs); err == nil && !strings.HasPrefix(rel, "..") {
+					snapshot = filepath.ToSlash(rel)
+				} else {
+					snapshot = abs
+				}
+			}
+			snapshotPath := snapshot
+			if !filepath.IsAbs(snapshotPath) {
+				snapshotPath = filepath.Join(top, snapshotPath)
+			}
+			if _, err := os.Stat(snapshotPath); errors.Is(err, fs.ErrNotExist) {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: %s does not exist yet; create and commit it with: mongospectre export-snapshot --uri ... -o %s\n", snapshot, snapshot)
+			}
+
+			path := filepath.Join(hooksDir, hook)
+			existing, err := os.ReadFile(path)
+			switch {
+			case err == nil && !bytes.Contains(existing, []byte(hookMarker)) && !force:
+				return fmt.Errorf("%s already exists; use --force to replace it", path)
+			case err != nil && !errors.Is(err, fs.ErrNotExist):
+				return fmt.Errorf("read %s: %w", path, err)
+			}
+			if err := os.MkdirAll(hooksDir, 0o755); err != nil {
+				return fmt.Errorf("create %s: %w", hooksDir, err)
+			}
+			// WriteFile keeps the mode of an existing file, so chmod too.
+			if err := os.WriteFile(path, []byte(hookScript(hook, snapshot)), 0o755); err != nil { //nolint:gosec // hooks must be executable
+				return fmt.Errorf("write %s: %w", path, err)
+			}
+			if err := os.Chmod(path, 0o755); err != nil { //nolint:gosec // hooks must be executable
+				return fmt.Errorf("chmod %s: %w", path, err)
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "installed %s hook at %s\n", hook, path)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&repo, "repo", ".", "path inside the git repository to install the hook in")
+	cmd.Flags().StringVar(&hook, "hook", "pre-commit", "hook to install: pre-commit or pre-push")
+	cmd.Flags().StringVar(&snapshot, "snapshot", "snapshot.json", "committed snapshot from export-snapshot that the hook checks against")
+	cmd.Flags().BoolVar(&force, "force", false, "replace an existing hook not installed by mongospectre")
+	return cmd
+}
+
+// hookScript returns the shell script for hook. It lets the commit through
+// on exit code 0, and on 1 when the run summary shows a report with only
+// medium findings; a check error, which also exits 1, has an error in the
+// summary and blocks like the high-severity findings behind exit code 2,
+// among them MISSING_COLLECTION (--fail-on-missing). Without mongospectre
+// on PATH the hook warns and passes, so contributors who have not
+// installed it are not locked out.
+func hookScript(hook, snapshot string) string {
+	return `#!/bin/sh
+` + hookMarker + `
+# Checks code against the committed snapshot before each ` + strings.TrimPrefix(hook, "pre-") + `.
+# Skip once with --no-verify.
+
+if ! command -v mongospectre >/dev/null 2>&1; then
+	echo "mongospectre ` + hook + `: mongospectre not found on PATH, skipping check" >&2
+	exit 0
+fi
+
+cd "$(git rev-parse --show-toplevel)" || exit 1
+summary=$(mktemp) || exit 1
+trap 'rm -f "$summary"' EXIT
+mongospectre check --repo . --snapshot ` + shellQuote(snapshot) + ` --fail-on-missing --no-interactive --summary-file "$summary"
+status=$?
+if [ "$status" -eq 0 ]; then
+	exit 0
+fi
+if [ "$status" -eq 1 ] && [ -s "$summary" ] && ! grep -q '"error":' "$summary"; then
+	exit 0
+fi
+if [ "$status" -eq 2 ]; then
+	echo "mongospectre ` + hook + `: blocked by high-severity findings (see above); fix them, add them to .mongospectreignore, or skip with --no-verify" >&2
+else
+	echo "mongospectre ` + hook + `: check failed with exit code $status (see above); fix it or skip with --no-verify" >&2
+fi
+exit 1
+`
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func gitInitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v: %s", err, out)
+	}
+	return dir
+}
+
+func TestInstallHook(t *testing.T) {
+	repo := gitInitRepo(t)
+	if err := os.MkdirAll(filepath.Join(repo, "db"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(filepath.Join(repo, "db"))
+
+	stdout, stderr, err := execCLI(t, "install-hook", "--snapshot", "prod's snapshot.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(repo, ".git", "hooks", "pre-commit")
+	if !strings.Contains(stdout, "installed pre-commit hook") {
+		t.Errorf("stdout = %q", stdout)
+	}
+	if !strings.Contains(stderr, "does not exist yet") {
+		t.Errorf("stderr = %q, want a missing snapshot warning", stderr)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0o111 == 0 {
+		t.Errorf("hook mode = %v, want executable", info.Mode())
+	}
+	script, _ := os.ReadFile(path)
+	if !strings.Contains(string(script), `--snapshot 'db/prod'\''s snapshot.json' --fail-on-missing`) {
+		t.Errorf("hook script:\n%s", script)
+	}
+
+	// Reinstalling over our own hook is fine; a foreign hook needs --force.
+	if _, _, err := execCLI(t, "install-hook", "--snapshot", "snapshot.json"); err != nil {
+		t.Fatalf("reinstall: %v", err)
+	}
+	prePush := filepath.Join(repo, ".git", "hooks", "pre-push")
+	if err := os.WriteFile(prePush, []byte("#!/bin/sh\nmake test\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = execCLI(t, "install-hook", "--hook", "pre-push")
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("err = %v, want an already exists error", err)
+	}
+	if _, _, err := execCLI(t, "install-hook", "--hook", "pre-push", "--force"); err != nil {
+		t.Fatal(err)
+	}
+	if script, _ := os.ReadFile(prePush); !strings.Contains(string(script), hookMarker) {
+		t.Errorf("pre-push not replaced:\n%s", script)
+	}
+}
+
+func TestInstallHookOutsideRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	_, _, err := execCLI(t, "install-hook", "--repo", t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "not a git repository") {
+		t.Fatalf("err = %v", err)
+	}
+	_, _, err = execCLI(t, "install-hook", "--hook", "post-merge")
+	if err == nil || !strings.Contains(err.Error(), "--hook must be") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestHookScriptExitCodes(t *testing.T) {
+	repo := gitInitRepo(t)
+	bin := t.TempDir()
+	// The stub writes $STUB_SUMMARY to the --summary-file and exits with
+	// $STUB_STATUS, standing in for check.
+	stub := `#!/bin/sh
+while [ $# -gt 0 ]; do
+	if [ "$1" = --summary-file ]; then printf '%s' "$STUB_SUMMARY" > "$2"; fi
+	shift
+done
+exit "$STUB_STATUS"
+`
+	if err := os.WriteFile(filepath.Join(bin, "mongospectre"), []byte(stub), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	hook := filepath.Join(repo, "hook.sh")
+	if err := os.WriteFile(hook, []byte(hookScript("pre-commit", "snapshot.json")), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		status  string
+		summary string
+		blocked bool
+	}{
+		{"clean", "0", `{"command":"check","exitCode":0}`, false},
+		{"medium findings", "1", `{"command":"check","exitCode":1,"findings":{"total":1,"medium":1}}`, false},
+		{"check error", "1", `{"command":"check","exitCode":1,"error":"read snapshot: no such file"}`, true},
+		{"flag error without summary", "1", "", true},
+		{"high findings", "2", `{"command":"check","exitCode":2}`, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := exec.Command("/bin/sh", hook)
+			cmd.Dir = repo
+			cmd.Env = append(os.Environ(), "PATH="+bin+string(os.PathListSeparator)+os.Getenv("PATH"),
+				"STUB_STATUS="+tc.status, "STUB_SUMMARY="+tc.summary)
+			out, err := cmd.CombinedOutput()
+			if blocked := err != nil; blocked != tc.blocked {
+				t.Errorf("blocked = %v, want %v: %s", blocked, tc.blocked, out)
+			}
+		})
+	}
+}
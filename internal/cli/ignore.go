@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+)
+
+// loadIgnoreRules merges the user, repository and --ignore-file ignore
+// rules. With --verbose it lists the files the rules came from.
+func loadIgnoreRules(stderr io.Writer, ignoreFile string) (analyzer.IgnoreList, error) {
+	cwd, _ := os.Getwd()
+	il, err := analyzer.LoadIgnoreRules(cwd, ignoreFile)
+	if verbose {
+		for _, src := range il.Sources {
+			_, _ = fmt.Fprintf(stderr, "Loaded %d ignore rules from %s\n", src.Rules, src.Path)
+		}
+	}
+	return il, err
+}
+
+// checkIgnoreFile fails when the --ignore-file of a long-running command
+// cannot be read, so the mistake shows at startup rather than as a warning
+// on every run.
+func checkIgnoreFile(noIgnore bool, ignoreFile string) error {
+	if noIgnore || ignoreFile == "" {
+		return nil
+	}
+	f, err := os.Open(ignoreFile)
+	if err != nil {
+		return fmt.Errorf("ignore file: %w", err)
+	}
+	return f.Close()
+}
+
+// applyIgnoreRules drops findings matched by the ignore rules. A file that
+// cannot be read is a warning, not a failure: the rules of the other files
+// still apply. With --verbose it reports
+// which rules suppressed how many findings.
+func applyIgnoreRules(stderr io.Writer, ignoreFile string, findings []analyzer.Finding) []analyzer.Finding {
+	il, err := loadIgnoreRules(stderr, ignoreFile)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "warning: %v\n", err)
+	}
+	findings, hits := il.FilterHits(findings)
+	writeSuppressed(stderr, &il, hits)
+	return findings
+}
+
+func writeSuppressed(stderr io.Writer, il *analyzer.IgnoreList, hits []int) {
+	if !verbose {
+		return
+	}
+	total := 0
+	for _, n := range hits {
+		total += n
+	}
+	if total == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(stderr, "Suppressed %d findings via ignore rules\n", total)
+	for i, n := range hits {
+		if n > 0 {
+			_, _ = fmt.Fprintf(stderr, "  %d by %s (%s)\n", n, il.Rules[i], il.Rules[i].Source)
+		}
+	}
+}
@@ -9,27 +9,36 @@ import (
 )
 
 func newInitCmd() *cobra.Command {
+	var interactive bool
+
 	cmd := &cobra.Command{
 		Use:   "init",
 		Short: "Create starter .mongospectre.yml and .mongospectreignore in the current directory",
+		Long: `Creates starter .mongospectre.yml and .mongospectreignore files in the
+current directory, skipping files that already exist.
+
+With --interactive, asks for clusters, thresholds, a schedule and
+notification channels, tests the connection to each cluster, and writes
+the answers to .mongospectre.yml. Notification secrets are written as
+${ENV_VAR} placeholders.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cwd, err := os.Getwd()
 			if err != nil {
 				return fmt.Errorf("getwd: %w", err)
 			}
+			if interactive {
+				return runInitWizard(cmd, cwd)
+			}
 
 			wrote := 0
 			for _, f := range initFiles {
-				path := filepath.Join(cwd, f.name)
-				if _, err := os.Stat(path); err == nil {
-					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "skip: %s already exists\n", f.name)
-					continue
+				created, err := writeInitFile(cmd, cwd, f)
+				if err != nil {
+					return err
 				}
-				if err := os.WriteFile(path, []byte(f.content), 0o600); err != nil {
-					return fmt.Errorf("write %s: %w", f.name, err)
+				if created {
+					wrote++
 				}
-				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "created %s\n", f.name)
-				wrote++
 			}
 
 			if wrote == 0 {
@@ -38,9 +47,24 @@ func newInitCmd() *cobra.Command {
 			return nil
 		},
 	}
+	cmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "build .mongospectre.yml by answering questions")
 	return cmd
 }
 
+// writeInitFile creates f in dir unless it already exists.
+func writeInitFile(cmd *cobra.Command, dir string, f initFile) (bool, error) {
+	path := filepath.Join(dir, f.name)
+	if _, err := os.Stat(path); err == nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "skip: %s already exists\n", f.name)
+		return false, nil
+	}
+	if err := os.WriteFile(path, []byte(f.content), 0o600); err != nil {
+		return false, fmt.Errorf("write %s: %w", f.name, err)
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "created %s\n", f.name)
+	return true, nil
+}
+
 type initFile struct {
 	name    string
 	content string
@@ -76,6 +100,15 @@ defaults:
 #   - type: slack
 #     webhook_url: ${SLACK_WEBHOOK_URL}
 #     on: [new_high, new_medium]
+#   - type: discord
+#     webhook_url: ${DISCORD_WEBHOOK_URL}
+#   - type: telegram
+#     bot_token: ${TELEGRAM_BOT_TOKEN}
+#     chat_id: ${TELEGRAM_CHAT_ID}
+#     on: [new_high]
+#   - type: opsgenie
+#     api_key: ${OPSGENIE_API_KEY}
+#     on: [new_high, new_medium, resolved]
 #   - type: webhook
 #     url: https://alerts.example.com/mongospectre
 #     method: POST
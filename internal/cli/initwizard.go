@@ -0,0 +1,476 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ppiankov/mongospectre/internal/config"
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/schedule"
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
+)
+
+// wizardConfig is the part of .mongospectre.yml the init wizard writes.
+// Unlike config.Config it leaves out what was not answered.
+type wizardConfig struct {
+	Clusters      map[string]wizardCluster `yaml:"clusters,omitempty"`
+	Thresholds    wizardThresholds         `yaml:"thresholds"`
+	Schedule      string                   `yaml:"schedule,omitempty"`
+	Notifications []wizardNotification     `yaml:"notifications,omitempty"`
+}
+
+type wizardCluster struct {
+	URI          string   `yaml:"uri,omitempty"`
+	Database     string   `yaml:"database,omitempty"`
+	AtlasProject string   `yaml:"atlas_project,omitempty"`
+	AtlasCluster string   `yaml:"atlas_cluster,omitempty"`
+	Tags         []string `yaml:"tags,omitempty"`
+}
+
+type wizardThresholds struct {
+	OversizedDocs  int64 `yaml:"oversized_docs"`
+	IndexUsageDays int   `yaml:"index_usage_days"`
+}
+
+type wizardNotification struct {
+	Type         string            `yaml:"type"`
+	WebhookURL   string            `yaml:"webhook_url,omitempty"`
+	BotToken     string            `yaml:"bot_token,omitempty"`
+	ChatID       string            `yaml:"chat_id,omitempty"`
+	APIKey       string            `yaml:"api_key,omitempty"`
+	URL          string            `yaml:"url,omitempty"`
+	Headers      map[string]string `yaml:"headers,omitempty"`
+	SMTPHost     string            `yaml:"smtp_host,omitempty"`
+	SMTPPort     int               `yaml:"smtp_port,omitempty"`
+	SMTPUsername string            `yaml:"smtp_username,omitempty"`
+	SMTPPassword string            `yaml:"smtp_password,omitempty"`
+	From         string            `yaml:"from,omitempty"`
+	To           []string          `yaml:"to,omitempty"`
+	On           []string          `yaml:"on"`
+}
+
+// wizardEvents are the notification events the wizard accepts.
+var wizardEvents = map[string]bool{"new_high": true, "new_medium": true, "new_low": true, "resolved": true, "changed": true}
+
+// wizard asks questions on stderr and reads the answers line by line.
+type wizard struct {
+	cmd *cobra.Command
+	in  *bufio.Reader
+	eof bool
+}
+
+// ask prints prompt and returns the answer, or def when the answer is
+// empty or the input has ended.
+func (w *wizard) ask(prompt, def string) (string, error) {
+	if def != "" {
+		prompt = fmt.Sprintf("%s [%s]", prompt, def)
+	}
+	_, _ = fmt.Fprintf(w.cmd.ErrOrStderr(), "%s: ", prompt)
+	if w.eof {
+		_, _ = fmt.Fprintln(w.cmd.ErrOrStderr())
+		return def, nil
+	}
+	line, err := w.in.ReadString('\n')
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("read answer: %w", err)
+		}
+		w.eof = true
+		_, _ = fmt.Fprintln(w.cmd.ErrOrStderr())
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer, nil
+	}
+	return def, nil
+}
+
+// askValid asks until check accepts the answer. It fails once the input
+// has ended, as the question would repeat forever.
+func (w *wizard) askValid(prompt, def string, check func(string) error) (string, error) {
+	for {
+		answer, err := w.ask(prompt, def)
+		if err != nil {
+			return "", err
+		}
+		checkErr := check(answer)
+		if checkErr == nil {
+			return answer, nil
+		}
+		if w.eof {
+			return "", fmt.Errorf("%s: %w", prompt, checkErr)
+		}
+		_, _ = fmt.Fprintf(w.cmd.ErrOrStderr(), "  %v\n", checkErr)
+	}
+}
+
+// confirm asks a yes/no question.
+func (w *wizard) confirm(prompt string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	answer, err := w.askValid(fmt.Sprintf("%s (%s)", prompt, hint), "", func(s string) error {
+		switch strings.ToLower(s) {
+		case "", "y", "yes", "n", "no":
+			return nil
+		}
+		return errors.New("answer y or n")
+	})
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(answer) {
+	case "y", "yes":
+		return true, nil
+	case "n", "no":
+		return false, nil
+	}
+	return def, nil
+}
+
+// runInitWizard builds .mongospectre.yml from answers and writes the
+// starter .mongospectreignore next to it.
+func runInitWizard(cmd *cobra.Command, dir string) error {
+	w := &wizard{cmd: cmd, in: bufio.NewReader(cmd.InOrStdin())}
+	configPath := filepath.Join(dir, ".mongospectre.yml")
+	if _, err := os.Stat(configPath); err == nil {
+		overwrite, err := w.confirm(".mongospectre.yml already exists. Overwrite it?", false)
+		if err != nil {
+			return err
+		}
+		if !overwrite {
+			_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "skip: .mongospectre.yml already exists")
+			_, err := writeInitFile(cmd, dir, initFiles[1])
+			return err
+		}
+	}
+
+	defaults := config.DefaultConfig()
+	out := wizardConfig{Thresholds: wizardThresholds{
+		OversizedDocs:  defaults.Thresholds.OversizedDocs,
+		IndexUsageDays: defaults.Thresholds.IndexUsageDays,
+	}}
+
+	_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Clusters — select one later with --cluster <name>. Leave the name empty to continue.")
+	for {
+		name, err := w.ask("Cluster name", "")
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			break
+		}
+		c, err := w.askCluster(name)
+		if err != nil {
+			return err
+		}
+		if out.Clusters == nil {
+			out.Clusters = make(map[string]wizardCluster)
+		}
+		out.Clusters[name] = c
+	}
+
+	_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Thresholds")
+	docs, err := w.askValid("Document count flagged as oversized", strconv.FormatInt(out.Thresholds.OversizedDocs, 10), positiveInt)
+	if err != nil {
+		return err
+	}
+	out.Thresholds.OversizedDocs, _ = strconv.ParseInt(docs, 10, 64)
+	days, err := w.askValid("Days without operations before an index is unused", strconv.Itoa(out.Thresholds.IndexUsageDays), positiveInt)
+	if err != nil {
+		return err
+	}
+	out.Thresholds.IndexUsageDays, _ = strconv.Atoi(days)
+
+	out.Schedule, err = w.askValid("Cron schedule for watch and serve runs, e.g. \"0 3 * * *\" (empty for none)", "", func(s string) error {
+		if s == "" {
+			return nil
+		}
+		_, err := schedule.Parse(s)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Notifications — slack, discord, telegram, opsgenie, webhook or email. Leave the type empty to finish.")
+	for {
+		n, done, err := w.askNotification()
+		if err != nil {
+			return err
+		}
+		if done {
+			break
+		}
+		out.Notifications = append(out.Notifications, n)
+	}
+
+	data, err := yaml.Marshal(out)
+	if err != nil {
+		return fmt.Errorf("encode .mongospectre.yml: %w", err)
+	}
+	// The file must load as the config the other commands read.
+	var check config.Config
+	if err := yaml.Unmarshal(data, &check); err != nil {
+		return fmt.Errorf("generated .mongospectre.yml does not load: %w", err)
+	}
+	content := "# mongospectre configuration, written by mongospectre init --interactive\n# See: https://github.com/ppiankov/mongospectre\n\n" + string(data)
+	if err := os.WriteFile(configPath, []byte(content), 0o600); err != nil {
+		return fmt.Errorf("write .mongospectre.yml: %w", err)
+	}
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), "created .mongospectre.yml")
+	_, err = writeInitFile(cmd, dir, initFiles[1])
+	return err
+}
+
+// askCluster asks for a cluster profile and tests its connection string.
+// A connection string with a password is offered to the keychain instead
+// of being written to the file.
+func (w *wizard) askCluster(name string) (wizardCluster, error) {
+	var c wizardCluster
+	rawURI, err := w.askValid("  Connection string (empty to add it later with mongospectre login)", "", func(s string) error {
+		if s == "" || strings.HasPrefix(s, "mongodb://") || strings.HasPrefix(s, "mongodb+srv://") {
+			return nil
+		}
+		return errors.New("connection string must start with mongodb:// or mongodb+srv://")
+	})
+	if err != nil {
+		return c, err
+	}
+	if c.Database, err = w.ask("  Database (empty for all)", ""); err != nil {
+		return c, err
+	}
+	if c.AtlasProject, err = w.ask("  Atlas project ID (empty if not on Atlas)", ""); err != nil {
+		return c, err
+	}
+	if c.AtlasProject != "" {
+		if c.AtlasCluster, err = w.ask("  Atlas cluster name", ""); err != nil {
+			return c, err
+		}
+	}
+	tags, err := w.ask("  Tags, comma-separated", "")
+	if err != nil {
+		return c, err
+	}
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			c.Tags = append(c.Tags, tag)
+		}
+	}
+	if rawURI == "" {
+		return c, nil
+	}
+
+	if ok, err := w.confirm(fmt.Sprintf("  Test the connection to %s now?", name), true); err != nil {
+		return c, err
+	} else if ok {
+		w.testConnection(name, rawURI, c.Database)
+	}
+
+	if uriHasPassword(rawURI) {
+		store, err := w.confirm("  The connection string holds a password, so it is not written to .mongospectre.yml. Store it in the keychain?", true)
+		if err != nil {
+			return c, err
+		}
+		if store {
+			w.storeURI(name, rawURI)
+		} else {
+			_, _ = fmt.Fprintf(w.cmd.ErrOrStderr(), "  Store it later with: mongospectre login %s\n", name)
+		}
+		return c, nil
+	}
+	c.URI = rawURI
+	return c, nil
+}
+
+// uriHasPassword reports whether a connection string holds a password. It
+// splits the user info off the raw string as RedactURI does, since net/url
+// rejects URIs the driver accepts, such as multi-host IPv6 lists. When that
+// split finds none, as with an unescaped "/" in the password, any "user:"
+// before the last "@" still counts, so the URI is never written unchecked.
+func uriHasPassword(rawURI string) bool {
+	if mongoinspect.RedactURI(rawURI) != rawURI {
+		return true
+	}
+	_, rest, ok := strings.Cut(rawURI, "://")
+	if !ok {
+		return true
+	}
+	at := strings.LastIndexByte(rest, '@')
+	return at >= 0 && strings.Contains(rest[:at], ":")
+}
+
+// testConnection connects to the cluster and reports the server version.
+// A failure is a warning: the cluster may only be reachable from CI.
+func (w *wizard) testConnection(name, rawURI, database string) {
+	ctx, cancel := context.WithTimeout(w.cmd.Context(), timeout)
+	defer cancel()
+	insp, err := newInspector(ctx, mongoinspect.Config{
+		URI:            rawURI,
+		Database:       database,
+		TLS:            tlsOpts,
+		Retries:        retries,
+		StrictReadOnly: strictReadOnly,
+		OnCommand:      onCommand(),
+		CommandLog:     commandLogHook(),
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(w.cmd.ErrOrStderr(), "  warning: %s: connection failed: %v\n", name, err)
+		return
+	}
+	defer func() { _ = insp.Close(ctx) }()
+	info, err := insp.GetServerVersion(ctx)
+	if err != nil {
+		_, _ = fmt.Fprintf(w.cmd.ErrOrStderr(), "  warning: %s: connection failed: %v\n", name, err)
+		return
+	}
+	_, _ = fmt.Fprintf(w.cmd.ErrOrStderr(), "  Connected to %s: MongoDB %s\n", name, info.Version)
+}
+
+func (w *wizard) storeURI(name, rawURI string) {
+	store, err := openKeyring()
+	if err == nil {
+		err = store.Set(name, rawURI)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(w.cmd.ErrOrStderr(), "  warning: keyring: %v; store it later with: mongospectre login %s\n", err, name)
+		return
+	}
+	_, _ = fmt.Fprintf(w.cmd.ErrOrStderr(), "  Stored %q in %s\n", name, store.Backend())
+}
+
+// askNotification asks for one notification channel. Secrets are asked
+// as environment variable names and written as ${NAME} placeholders.
+func (w *wizard) askNotification() (wizardNotification, bool, error) {
+	kind, err := w.askValid("Notification type", "", func(s string) error {
+		switch s {
+		case "", "slack", "discord", "telegram", "opsgenie", "webhook", "email":
+			return nil
+		}
+		return fmt.Errorf("unknown type %q", s)
+	})
+	if err != nil || kind == "" {
+		return wizardNotification{}, true, err
+	}
+	n := wizardNotification{Type: kind}
+	envVar := func(prompt, def string) (string, error) {
+		name, err := w.askValid("  "+prompt+" environment variable", def, envVarName)
+		return "${" + name + "}", err
+	}
+
+	switch kind {
+	case "slack":
+		n.WebhookURL, err = envVar("Webhook URL", "SLACK_WEBHOOK_URL")
+	case "discord":
+		n.WebhookURL, err = envVar("Webhook URL", "DISCORD_WEBHOOK_URL")
+	case "telegram":
+		if n.BotToken, err = envVar("Bot token", "TELEGRAM_BOT_TOKEN"); err == nil {
+			n.ChatID, err = envVar("Chat ID", "TELEGRAM_CHAT_ID")
+		}
+	case "opsgenie":
+		n.APIKey, err = envVar("API key", "OPSGENIE_API_KEY")
+	case "webhook":
+		if n.URL, err = w.askValid("  URL", "", requireHTTPURL); err == nil {
+			var token string
+			if token, err = envVar("Bearer token", "ALERT_TOKEN"); err == nil {
+				n.Headers = map[string]string{"Authorization": "Bearer " + token}
+			}
+		}
+	case "email":
+		n, err = w.askEmail(n)
+	}
+	if err != nil {
+		return n, false, err
+	}
+
+	events, err := w.askValid("  Events", "new_high,new_medium", func(s string) error {
+		for _, e := range strings.Split(s, ",") {
+			if !wizardEvents[strings.TrimSpace(e)] {
+				return fmt.Errorf("unknown event %q (use new_high, new_medium, new_low, resolved or changed)", strings.TrimSpace(e))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return n, false, err
+	}
+	for _, e := range strings.Split(events, ",") {
+		n.On = append(n.On, strings.TrimSpace(e))
+	}
+	return n, false, nil
+}
+
+func (w *wizard) askEmail(n wizardNotification) (wizardNotification, error) {
+	var err error
+	if n.SMTPHost, err = w.askValid("  SMTP host", "", required); err != nil {
+		return n, err
+	}
+	port, err := w.askValid("  SMTP port", "587", positiveInt)
+	if err != nil {
+		return n, err
+	}
+	n.SMTPPort, _ = strconv.Atoi(port)
+	if n.From, err = w.askValid("  From address", "", required); err != nil {
+		return n, err
+	}
+	to, err := w.askValid("  To addresses, comma-separated", "", required)
+	if err != nil {
+		return n, err
+	}
+	for _, addr := range strings.Split(to, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			n.To = append(n.To, addr)
+		}
+	}
+	name, err := w.askValid("  SMTP username environment variable", "SMTP_USERNAME", envVarName)
+	if err != nil {
+		return n, err
+	}
+	n.SMTPUsername = "${" + name + "}"
+	if name, err = w.askValid("  SMTP password environment variable", "SMTP_PASSWORD", envVarName); err != nil {
+		return n, err
+	}
+	n.SMTPPassword = "${" + name + "}"
+	return n, nil
+}
+
+var envVarNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func envVarName(s string) error {
+	if envVarNameRe.MatchString(s) {
+		return nil
+	}
+	return fmt.Errorf("%q is not an environment variable name", s)
+}
+
+func required(s string) error {
+	if s == "" {
+		return errors.New("an answer is required")
+	}
+	return nil
+}
+
+func positiveInt(s string) error {
+	if n, err := strconv.Atoi(s); err != nil || n <= 0 {
+		return fmt.Errorf("%q is not a positive number", s)
+	}
+	return nil
+}
+
+func requireHTTPURL(s string) error {
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q is not an http(s) URL", s)
+	}
+	return nil
+}
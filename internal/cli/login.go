@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/ppiankov/mongospectre/internal/keyring"
+	"github.com/spf13/cobra"
+)
+
+// openKeyring opens the credential store configured in .mongospectre.yml.
+// Replaced in tests.
+var openKeyring = func() (keyring.Store, error) {
+	return keyring.Open(keyring.Options{
+		Backend:    cfg.Keyring.Backend,
+		File:       cfg.Keyring.File,
+		Passphrase: os.Getenv("MONGOSPECTRE_KEYRING_PASSPHRASE"),
+	})
+}
+
+func newLoginCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "login <alias>",
+		Short: "Store a connection string in the OS keychain for use with --cluster",
+		Long: `Reads a MongoDB connection string from the terminal (without echo) or
+stdin and stores it under alias in the OS keychain, or in an encrypted file
+when no keychain is available. Other commands then connect with
+--cluster alias, keeping the URI out of shell history and config files.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			alias := args[0]
+			store, err := openKeyring()
+			if err != nil {
+				return fmt.Errorf("keyring: %w", err)
+			}
+			rawURI, err := readConnectionString(cmd, alias)
+			if err != nil {
+				return err
+			}
+			if err := store.Set(alias, rawURI); err != nil {
+				return fmt.Errorf("keyring: %w", err)
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Stored %q in %s\n", alias, store.Backend())
+			return nil
+		},
+	}
+}
+
+func newLogoutCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "logout <alias>",
+		Short: "Remove a connection string stored by login",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store, err := openKeyring()
+			if err != nil {
+				return fmt.Errorf("keyring: %w", err)
+			}
+			if err := store.Delete(args[0]); err != nil {
+				if errors.Is(err, keyring.ErrNotFound) {
+					return fmt.Errorf("no stored connection string for %q", args[0])
+				}
+				return fmt.Errorf("keyring: %w", err)
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Removed %q from %s\n", args[0], store.Backend())
+			return nil
+		},
+	}
+}
+
+// readConnectionString prompts without echo on a terminal, otherwise reads
+// the first line of stdin, and checks the result looks like a MongoDB URI.
+func readConnectionString(cmd *cobra.Command, alias string) (string, error) {
+	var line string
+	if f, ok := cmd.InOrStdin().(*os.File); ok && term.IsTerminal(f.Fd()) {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Connection string for %s: ", alias)
+		b, err := term.ReadPassword(f.Fd())
+		_, _ = fmt.Fprintln(cmd.ErrOrStderr())
+		if err != nil {
+			return "", fmt.Errorf("read connection string: %w", err)
+		}
+		line = string(b)
+	} else {
+		s, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", fmt.Errorf("read connection string: %w", err)
+		}
+		line = s
+	}
+	rawURI := strings.TrimSpace(line)
+	if !strings.HasPrefix(rawURI, "mongodb://") && !strings.HasPrefix(rawURI, "mongodb+srv://") {
+		return "", errors.New("connection string must start with mongodb:// or mongodb+srv://")
+	}
+	return rawURI, nil
+}
+
+// clusterURI looks up the connection string stored for alias.
+func clusterURI(alias string) (string, error) {
+	store, err := openKeyring()
+	if err != nil {
+		return "", fmt.Errorf("keyring: %w", err)
+	}
+	rawURI, err := store.Get(alias)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", fmt.Errorf("cluster %q: not in clusters: config and no stored connection string (run: mongospectre login %s)", alias, alias)
+	}
+	if err != nil {
+		return "", fmt.Errorf("keyring: %w", err)
+	}
+	return rawURI, nil
+}
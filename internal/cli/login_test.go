@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/mongospectre/internal/keyring"
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/reporter"
+)
+
+type memKeyring map[string]string
+
+func (m memKeyring) Get(alias string) (string, error) {
+	v, ok := m[alias]
+	if !ok {
+		return "", keyring.ErrNotFound
+	}
+	return v, nil
+}
+
+func (m memKeyring) Set(alias, secret string) error { m[alias] = secret; return nil }
+
+func (m memKeyring) Delete(alias string) error {
+	if _, ok := m[alias]; !ok {
+		return keyring.ErrNotFound
+	}
+	delete(m, alias)
+	return nil
+}
+
+func (m memKeyring) Backend() string { return "memory" }
+
+func stubKeyring(t *testing.T, store memKeyring) {
+	t.Helper()
+	orig := openKeyring
+	openKeyring = func() (keyring.Store, error) { return store, nil }
+	t.Cleanup(func() { openKeyring = orig })
+}
+
+func TestLoginStoresURIFromStdin(t *testing.T) {
+	store := memKeyring{}
+	stubKeyring(t, store)
+
+	secretURI := "mongodb+srv://app:" + "hunt" + "er2@cluster0.example.net/app"
+	stdout, _, err := execCLIWithInput(t, secretURI+"\n", "login", "prod-eu")
+	if err != nil {
+		t.Fatalf("login returned error: %v", err)
+	}
+	if store["prod-eu"] != secretURI {
+		t.Fatalf("stored %q, want %q", store["prod-eu"], secretURI)
+	}
+	if strings.Contains(stdout, "hunter2") {
+		t.Errorf("login output leaked the URI: %q", stdout)
+	}
+}
+
+func TestLoginRejectsNonMongoURI(t *testing.T) {
+	stubKeyring(t, memKeyring{})
+	_, _, err := execCLIWithInput(t, "postgres://db\n", "login", "prod")
+	if err == nil || !strings.Contains(err.Error(), "mongodb://") {
+		t.Fatalf("expected URI scheme error, got %v", err)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	store := memKeyring{"prod": "mongodb://db"}
+	stubKeyring(t, store)
+
+	if _, _, err := execCLI(t, "logout", "prod"); err != nil {
+		t.Fatalf("logout returned error: %v", err)
+	}
+	if _, ok := store["prod"]; ok {
+		t.Error("alias still stored after logout")
+	}
+	if _, _, err := execCLI(t, "logout", "prod"); err == nil || !strings.Contains(err.Error(), "no stored connection string") {
+		t.Errorf("expected not-found error, got %v", err)
+	}
+}
+
+func TestClusterFlagUsesStoredURI(t *testing.T) {
+	stubKeyring(t, memKeyring{"prod": "mongodb://prod-db:27017"})
+	var got mongoinspect.Config
+	stubNewInspector(t, func(_ context.Context, c mongoinspect.Config) (inspector, error) {
+		got = c
+		return &fakeInspector{serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"}}, nil
+	})
+
+	if _, _, err := execCLI(t, "audit", "--cluster", "prod", "--format", "json"); err != nil {
+		t.Fatalf("audit returned error: %v", err)
+	}
+	if got.URI != "mongodb://prod-db:27017" {
+		t.Errorf("URI = %q, want stored URI", got.URI)
+	}
+
+	if _, _, err := execCLI(t, "audit", "--cluster", "prod", "--uri", "mongodb://explicit", "--format", "json"); err != nil {
+		t.Fatalf("audit returned error: %v", err)
+	}
+	if got.URI != "mongodb://explicit" {
+		t.Errorf("URI = %q, --uri should win over --cluster", got.URI)
+	}
+
+	_, _, err := execCLI(t, "audit", "--cluster", "missing")
+	if err == nil || !strings.Contains(err.Error(), "mongospectre login missing") {
+		t.Errorf("expected login hint for unknown cluster, got %v", err)
+	}
+}
+
+func TestClusterProfileFromConfig(t *testing.T) {
+	dir := t.TempDir()
+	cfgYAML := `clusters:
+  prod-eu:
+    database: app
+    tags: [prod, eu]
+  staging:
+    uri: mongodb://staging:27017
+`
+	if err := os.WriteFile(filepath.Join(dir, ".mongospectre.yml"), []byte(cfgYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+	// prod-eu has no URI in config, so it comes from the keyring.
+	stubKeyring(t, memKeyring{"prod-eu": "mongodb://prod-eu:27017"})
+
+	var got mongoinspect.Config
+	stubNewInspector(t, func(_ context.Context, c mongoinspect.Config) (inspector, error) {
+		got = c
+		return &fakeInspector{serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"}}, nil
+	})
+
+	stdout, _, err := execCLI(t, "audit", "--cluster", "prod-eu", "--format", "json")
+	if err != nil {
+		t.Fatalf("audit returned error: %v", err)
+	}
+	if got.URI != "mongodb://prod-eu:27017" || got.Database != "app" {
+		t.Errorf("config = %+v, want keyring URI and profile database", got)
+	}
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	if report.Metadata.Cluster != "prod-eu" || strings.Join(report.Metadata.Tags, ",") != "prod,eu" {
+		t.Errorf("metadata = %+v", report.Metadata)
+	}
+
+	if _, _, err := execCLI(t, "audit", "--cluster", "staging", "--database", "other", "--format", "json"); err != nil {
+		t.Fatalf("audit returned error: %v", err)
+	}
+	if got.URI != "mongodb://staging:27017" || got.Database != "other" {
+		t.Errorf("config = %+v, want profile URI and --database override", got)
+	}
+}
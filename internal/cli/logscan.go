@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/reporter"
+	"github.com/spf13/cobra"
+)
+
+func newLogscanCmd() *cobra.Command {
+	var (
+		repo       string
+		database   string
+		format     string
+		noIgnore   bool
+		ignoreFile string
+		groupBy    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "logscan <mongod.log>...",
+		Short: "Correlate slow queries from server logs with code",
+		Long: "Parses slow query entries from structured (JSON) mongod or mongos logs, as written by MongoDB 4.4\n" +
+			"and later, and correlates their query shapes with collection references in the repo the same way\n" +
+			"check --profile reads system.profile. Useful when the profiler is disabled: the server logs every\n" +
+			"operation slower than slowms (100ms by default). Gzipped rotated logs are read as well.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			database = profileDatabase(database)
+			if err := validateFormat(format, "text", "json", "sarif", "spectrehub"); err != nil {
+				return err
+			}
+			if err := validateGroupBy(groupBy); err != nil {
+				return err
+			}
+			if repo == "" {
+				return fmt.Errorf("--repo is required")
+			}
+			ownerMap, err := configOwnerMap()
+			if err != nil {
+				return err
+			}
+
+			var entries []mongoinspect.ProfileEntry
+			for _, path := range args {
+				log, err := mongoinspect.ReadSlowQueryLog(path)
+				if err != nil {
+					return fmt.Errorf("read log %s: %w", path, err)
+				}
+				kept := 0
+				for i := range log.Entries {
+					if database == "" || strings.EqualFold(log.Entries[i].Database, database) {
+						entries = append(entries, log.Entries[i])
+						kept++
+					}
+				}
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Read %d slow queries from %s (%d lines)\n", kept, path, log.Lines)
+				if len(log.Entries) == 0 && log.Skipped > 0 {
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(),
+						"Hint: %d lines in %s are not structured JSON log entries; logscan needs the log format of MongoDB 4.4 or later.\n", log.Skipped, path)
+				}
+			}
+
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Scanning repo %s...\n", repo)
+			scan, err := scanRepo(repo)
+			if err != nil {
+				return fmt.Errorf("scan repo: %w", err)
+			}
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Found %d collection references in %d files\n",
+				len(scan.Refs), scan.FilesScanned)
+
+			findings := analyzer.CorrelateProfiler(&scan, entries)
+			owners := loadCodeOwners(cmd, repo)
+			for i := range findings {
+				assignOwner(&findings[i], owners)
+				ownerMap.Assign(&findings[i])
+			}
+
+			if !noIgnore {
+				findings = applyIgnoreRules(cmd.ErrOrStderr(), ignoreFile, findings)
+			}
+
+			report := reporter.NewReport(findings)
+			report.Metadata = reporter.Metadata{
+				Version:   version,
+				Command:   "logscan",
+				Database:  database,
+				RepoPath:  repo,
+				Timestamp: time.Now().UTC().Format(time.RFC3339),
+				Cluster:   cluster,
+				Tags:      activeProfile.Tags,
+			}
+			scanCopy := scan
+			report.Scan = &scanCopy
+			if err := writeReport(cmd.OutOrStdout(), &report, format, groupBy); err != nil {
+				return fmt.Errorf("write report: %w", err)
+			}
+
+			code := analyzer.ExitCode(report.MaxSeverity)
+			if code != 0 {
+				if hint := reporter.ExitCodeHint(code); hint != "" {
+					_, _ = fmt.Fprintln(cmd.ErrOrStderr(), hint)
+				}
+				return &ExitError{Code: code}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&repo, "repo", "", "path to code repository to scan")
+	cmd.Flags().StringVar(&database, "database", "", "only correlate slow queries against this database (default: all)")
+	cmd.Flags().StringVarP(&format, "format", "f", "text", "output format: text, json, sarif, or spectrehub")
+	cmd.Flags().BoolVar(&noIgnore, "no-ignore", false, "bypass ignore files")
+	cmd.Flags().StringVar(&ignoreFile, "ignore-file", "", "extra ignore file merged with .mongospectreignore and ~/.config/mongospectre/ignore")
+	cmd.Flags().StringVar(&groupBy, "group-by", "", "group text output by finding type, collection or owner: type, collection, owner")
+
+	return cmd
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	"github.com/ppiankov/mongospectre/internal/reporter"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+)
+
+func TestLogscanCorrelatesSlowQueries(t *testing.T) {
+	stubScanRepo(t, func(string) (scanner.ScanResult, error) {
+		return scanner.ScanResult{
+			Collections: []string{"users"},
+			Refs:        []scanner.CollectionRef{{Collection: "users", File: "app/models/user.go", Line: 15}},
+			FieldRefs: []scanner.FieldRef{
+				{Collection: "users", Field: "status", File: "app/models/user.go", Line: 15},
+			},
+			FilesScanned: 1,
+		}, nil
+	})
+	line := `{"t":{"$date":"2024-03-01T10:00:01.500+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn12","msg":"Slow query",` +
+		`"attr":{"type":"command","ns":"%s.users","command":{"find":"users","filter":{"status":"active"}},"planSummary":"COLLSCAN","docsExamined":5000,"nreturned":3,"durationMillis":850}}`
+	logPath := filepath.Join(t.TempDir(), "mongod.log")
+	content := strings.ReplaceAll(line, "%s", "app") + "\n" + strings.ReplaceAll(line, "%s", "other") + "\n"
+	if err := os.WriteFile(logPath, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repo, "CODEOWNERS"), []byte("app/models/ @org/users\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, err := execCLI(t, "logscan", logPath, "--repo", repo, "--database", "app", "--format", "json")
+	requireExitCode(t, err, 2)
+	if !strings.Contains(stderr, "Read 1 slow queries from") {
+		t.Fatalf("expected read summary for the app database only, got: %q", stderr)
+	}
+
+	var report reporter.Report
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid report JSON: %v", err)
+	}
+	if report.Metadata.Command != "logscan" {
+		t.Errorf("command = %q, want logscan", report.Metadata.Command)
+	}
+	var collscan bool
+	for _, f := range report.Findings {
+		if f.Type == analyzer.FindingCollectionScanSource && strings.Contains(f.Message, "app/models/user.go:15") {
+			collscan = true
+			if f.Owner != "@org/users" {
+				t.Errorf("owner = %q, want @org/users from CODEOWNERS", f.Owner)
+			}
+		}
+	}
+	if !collscan {
+		t.Fatalf("expected COLLECTION_SCAN_SOURCE finding, got %+v", report.Findings)
+	}
+}
+
+func TestLogscanLegacyTextLogHint(t *testing.T) {
+	stubScanRepo(t, func(string) (scanner.ScanResult, error) {
+		return scanner.ScanResult{}, nil
+	})
+	logPath := filepath.Join(t.TempDir(), "mongod.log")
+	legacy := "2019-06-01T10:00:00.000+0000 I COMMAND  [conn1] command app.users command: find { find: \"users\" } 900ms\n"
+	if err := os.WriteFile(logPath, []byte(legacy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, stderr, err := execCLI(t, "logscan", logPath, "--repo", t.TempDir())
+	if err != nil {
+		t.Fatalf("logscan returned error: %v", err)
+	}
+	if !strings.Contains(stderr, "MongoDB 4.4 or later") {
+		t.Fatalf("expected legacy format hint, got: %q", stderr)
+	}
+}
+
+func TestLogscanRequiresRepo(t *testing.T) {
+	_, _, err := execCLI(t, "logscan", "mongod.log")
+	if err == nil || !strings.Contains(err.Error(), "--repo is required") {
+		t.Fatalf("expected --repo error, got %v", err)
+	}
+}
@@ -0,0 +1,25 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+)
+
+// configNamingLinter builds the naming convention linter from the config's
+// naming section. It returns nil when no rules are configured.
+func configNamingLinter() (*analyzer.NamingLinter, error) {
+	rules := analyzer.NamingRules{
+		Collections: cfg.Naming.Collections,
+		Fields:      cfg.Naming.Fields,
+		Indexes:     cfg.Naming.Indexes,
+	}
+	if !rules.Enabled() {
+		return nil, nil
+	}
+	linter, err := analyzer.NewNamingLinter(rules)
+	if err != nil {
+		return nil, fmt.Errorf("naming rules: %w", err)
+	}
+	return linter, nil
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+// readOperatorLive reads the topology, users and custom roles compared
+// with the Kubernetes operator resources of the scan. What cannot be read
+// is skipped with a warning: usersInfo and rolesInfo need userAdmin, and
+// Atlas does not allow them.
+func readOperatorLive(ctx context.Context, cmd *cobra.Command, insp inspector, scan *scanner.ScanResult, version, uri string) *analyzer.OperatorLive {
+	live := &analyzer.OperatorLive{
+		Version:     version,
+		ClusterName: deriveAtlasClusterName(uri),
+		Users:       make(map[string][]mongoinspect.UserInfo),
+		Roles:       make(map[string][]mongoinspect.RoleInfo),
+	}
+	if rs, err := insp.InspectReplicaSet(ctx); err != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: operator topology check skipped: %v\n", err)
+	} else {
+		live.ReplicaSet = &rs
+	}
+
+	userDBs, roleDBs := make(map[string]bool), make(map[string]bool)
+	for _, ref := range scan.OperatorRefs {
+		for _, u := range ref.Users {
+			userDBs[u.DB] = true
+		}
+		for _, r := range ref.Roles {
+			roleDBs[r.DB] = true
+		}
+	}
+	for _, db := range sortedKeys(userDBs) {
+		users, err := insp.InspectUsers(ctx, db)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: operator user check skipped on %s: %v\n", db, err)
+			continue
+		}
+		live.Users[db] = users
+	}
+	for _, db := range sortedKeys(roleDBs) {
+		roles, err := insp.InspectRoles(ctx, db)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: operator role check skipped on %s: %v\n", db, err)
+			continue
+		}
+		live.Roles[db] = roles
+	}
+	return live
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
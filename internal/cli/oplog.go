@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/spf13/cobra"
+)
+
+// sampleOplog reads the oplog write profile for --oplog. It returns nil
+// when the oplog cannot be read, which is a warning rather than an error:
+// standalone servers have no oplog and many users cannot read local.
+func sampleOplog(ctx context.Context, cmd *cobra.Command, insp inspector, database string, limit int64) *mongoinspect.OplogProfile {
+	profile, err := insp.SampleOplog(ctx, database, limit)
+	if err != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: oplog analysis skipped: %v\n", err)
+		return nil
+	}
+	if profile.Writes == 0 {
+		_, _ = fmt.Fprintln(cmd.ErrOrStderr(), "Oplog analysis skipped: no writes in the sampled entries (standalone deployments have no oplog).")
+		return nil
+	}
+	if verbose {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Sampled %d oplog writes to %d collections\n", profile.Writes, len(profile.Namespaces))
+	}
+	return &profile
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	"github.com/ppiankov/mongospectre/internal/gitinfo"
+	"github.com/spf13/cobra"
+)
+
+// loadCodeOwners reads the scanned repo's CODEOWNERS. A file that cannot
+// be read is a warning: findings are still reported, just without owners.
+func loadCodeOwners(cmd *cobra.Command, repo string) *gitinfo.CodeOwners {
+	co, err := gitinfo.LoadCodeOwners(repo)
+	if err != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: CODEOWNERS skipped: %v\n", err)
+		return nil
+	}
+	if co != nil && verbose {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Assigning finding owners from %s\n", co.Path)
+	}
+	return co
+}
+
+// configOwnerMap compiles the config's owners map.
+func configOwnerMap() (analyzer.OwnerMap, error) {
+	rules := make([]analyzer.OwnerRule, 0, len(cfg.Owners))
+	for _, o := range cfg.Owners {
+		rules = append(rules, analyzer.OwnerRule{Database: o.Database, Collection: o.Collection, Owner: o.Owner})
+	}
+	owners, err := analyzer.NewOwnerMap(rules)
+	if err != nil {
+		return nil, fmt.Errorf("owners: %w", err)
+	}
+	return owners, nil
+}
+
+// assignOwner sets the owner of a finding with a code location from
+// CODEOWNERS.
+func assignOwner(f *analyzer.Finding, co *gitinfo.CodeOwners) {
+	if f.Owner == "" && f.File != "" {
+		f.Owner = strings.Join(co.Owners(f.File), " ")
+	}
+}
+
+// ownedBy reports whether owner, a space-separated owner list, includes
+// want. Team and user names compare case-insensitively, as on GitHub.
+func ownedBy(owner, want string) bool {
+	for _, o := range strings.Fields(owner) {
+		if strings.EqualFold(o, want) {
+			return true
+		}
+	}
+	return false
+}
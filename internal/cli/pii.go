@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+// configPIIPatterns builds the patterns --pii matches sampled values
+// against: the built-ins merged with the config's pii.patterns. It returns
+// nil when --pii is not set.
+func configPIIPatterns(pii bool, sampleSize int) ([]mongoinspect.PIIPattern, error) {
+	if !pii {
+		return nil, nil
+	}
+	if sampleSize <= 0 {
+		return nil, fmt.Errorf("--pii requires --sample-size (values are matched in sampled documents)")
+	}
+	patterns, err := analyzer.CompilePIIPatterns(cfg.PII.Patterns)
+	if err != nil {
+		return nil, fmt.Errorf("pii patterns: %w", err)
+	}
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("--pii: every pattern is disabled in pii.patterns")
+	}
+	return patterns, nil
+}
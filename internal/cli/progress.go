@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+// progressLogInterval spaces the plain progress lines written when stderr
+// is not a terminal.
+const progressLogInterval = 10 * time.Second
+
+var progressVerbs = map[string]string{
+	mongoinspect.PhaseInspect: "Inspecting",
+	mongoinspect.PhaseSample:  "Sampling",
+}
+
+// progressPrinter renders inspection progress on stderr. On a terminal it
+// redraws one status line with the current namespace and ETA; otherwise it
+// logs a line every progressLogInterval, so CI logs show the run is alive.
+type progressPrinter struct {
+	w   io.Writer
+	tty bool
+	now func() time.Time
+
+	phase   string
+	started time.Time
+	lastLog time.Time
+	drawn   bool
+}
+
+// newProgress returns the Progress callback for a command's stderr.
+func newProgress(w io.Writer) mongoinspect.Progress {
+	p := &progressPrinter{w: w, tty: isTTYWriter(w), now: time.Now}
+	return p.update
+}
+
+func (p *progressPrinter) update(phase string, done, total int, namespace string) {
+	now := p.now()
+	if phase != p.phase {
+		p.phase, p.started, p.lastLog = phase, now, now
+	}
+	verb := progressVerbs[phase]
+	if verb == "" {
+		verb = phase
+	}
+
+	if done >= total {
+		if p.drawn {
+			_, _ = fmt.Fprint(p.w, "\r\033[K")
+			p.drawn = false
+		}
+		p.phase = ""
+		return
+	}
+
+	eta := ""
+	if done > 0 {
+		elapsed := now.Sub(p.started)
+		remaining := time.Duration(float64(elapsed) / float64(done) * float64(total-done))
+		eta = fmt.Sprintf(", ETA %s", remaining.Round(time.Second))
+	}
+
+	if p.tty {
+		_, _ = fmt.Fprintf(p.w, "\r\033[K%s %d/%d (%d%%) %s%s", verb, done, total, done*100/total, namespace, eta)
+		p.drawn = true
+		return
+	}
+	if now.Sub(p.lastLog) >= progressLogInterval {
+		p.lastLog = now
+		_, _ = fmt.Fprintf(p.w, "%s: %d/%d collections%s\n", verb, done, total, eta)
+	}
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+func TestProgressPrinterTTY(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	p := &progressPrinter{w: &buf, tty: true, now: func() time.Time { return now }}
+
+	p.update(mongoinspect.PhaseInspect, 0, 4, "app.users")
+	now = now.Add(10 * time.Second)
+	p.update(mongoinspect.PhaseInspect, 1, 4, "app.orders")
+	out := buf.String()
+	if !strings.Contains(out, "Inspecting 0/4 (0%) app.users") {
+		t.Errorf("missing first status: %q", out)
+	}
+	if !strings.Contains(out, "\r\033[KInspecting 1/4 (25%) app.orders, ETA 30s") {
+		t.Errorf("missing redrawn status with ETA: %q", out)
+	}
+
+	buf.Reset()
+	p.update(mongoinspect.PhaseInspect, 4, 4, "")
+	if buf.String() != "\r\033[K" {
+		t.Errorf("final update = %q, want the status line cleared", buf.String())
+	}
+}
+
+func TestProgressPrinterPlainLogsPeriodically(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	p := &progressPrinter{w: &buf, now: func() time.Time { return now }}
+
+	for done := 0; done < 30; done++ {
+		p.update(mongoinspect.PhaseSample, done, 30, "app.c")
+		now = now.Add(time.Second)
+	}
+	p.update(mongoinspect.PhaseSample, 30, 30, "")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want one per 10s: %q", len(lines), buf.String())
+	}
+	if lines[0] != "Sampling: 10/30 collections, ETA 20s" {
+		t.Errorf("first line = %q", lines[0])
+	}
+	if strings.Contains(buf.String(), "\r") {
+		t.Errorf("plain output must not redraw: %q", buf.String())
+	}
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/spf13/cobra"
+)
+
+// ExitReadOnlyViolation is the exit code of a run in which
+// --strict-readonly refused a command, whatever the command would
+// otherwise have returned.
+const ExitReadOnlyViolation = 3
+
+// strictReadOnly is set by --strict-readonly; readOnlyLog is the command
+// log of the current run, nil when strictReadOnly is off.
+var (
+	strictReadOnly bool
+	readOnlyLog    *commandLog
+)
+
+// commandLog writes one line per command sent to MongoDB, for the audit
+// trail --strict-readonly promises, and keeps the first refused one.
+type commandLog struct {
+	mu      sync.Mutex
+	w       io.Writer
+	blocked *mongoinspect.CommandEvent
+}
+
+func (l *commandLog) record(ev mongoinspect.CommandEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	verdict := "allow"
+	if ev.Blocked {
+		verdict = "BLOCK"
+		if l.blocked == nil {
+			l.blocked = &ev
+		}
+	}
+	line := fmt.Sprintf("readonly: %s %s %s %s", time.Now().UTC().Format(time.RFC3339Nano), verdict, ev.Command, ev.Namespace())
+	if ev.Blocked {
+		line += " (" + ev.Reason + ")"
+	}
+	_, _ = fmt.Fprintln(l.w, line)
+}
+
+// firstBlocked returns the first refused command, or nil.
+func (l *commandLog) firstBlocked() *mongoinspect.CommandEvent {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.blocked
+}
+
+// onCommand returns the mongoinspect.Config.OnCommand hook for this run.
+func onCommand() func(mongoinspect.CommandEvent) {
+	if readOnlyLog == nil {
+		return nil
+	}
+	return readOnlyLog.record
+}
+
+// execute runs root, closes the --command-log file, and, if
+// --strict-readonly refused a command, fails the run with
+// ExitReadOnlyViolation: the refusal may have surfaced only as a skipped
+// check, which must not pass for a clean result.
+func execute(root *cobra.Command) error {
+	readOnlyLog, commandLogOut = nil, nil
+	err := root.Execute()
+	if cerr := commandLogOut.close(); cerr != nil {
+		_, _ = fmt.Fprintf(root.ErrOrStderr(), "Error: --command-log %s: %v\n", commandLogPath, cerr)
+		if err == nil {
+			err = &ExitError{Code: 1}
+		}
+	}
+	if ev := readOnlyLog.firstBlocked(); ev != nil {
+		_, _ = fmt.Fprintf(root.ErrOrStderr(), "Error: --strict-readonly blocked %s on %s: %s\n", ev.Command, ev.Namespace(), ev.Reason)
+		return &ExitError{Code: ExitReadOnlyViolation}
+	}
+	return err
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+func TestStrictReadOnly(t *testing.T) {
+	t.Chdir(t.TempDir())
+	blockNext := false
+	stubNewInspector(t, func(_ context.Context, cfg mongoinspect.Config) (inspector, error) {
+		if !cfg.StrictReadOnly || cfg.OnCommand == nil {
+			t.Fatalf("inspector not configured for strict read-only: %+v", cfg)
+		}
+		// Stand in for the commands the guarded client would report.
+		cfg.OnCommand(mongoinspect.CommandEvent{Command: "listIndexes", Database: "app", Collection: "orders"})
+		if blockNext {
+			cfg.OnCommand(mongoinspect.CommandEvent{Command: "dropIndexes", Database: "app", Collection: "orders", Blocked: true, Reason: "not in the read-only allowlist"})
+		}
+		return &fakeInspector{serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"}}, nil
+	})
+
+	_, stderr, err := execCLI(t, "audit", "--uri", "mongodb://stub", "--strict-readonly", "--no-ignore", "--lint-uri=false")
+	if err != nil {
+		t.Fatalf("err = %v\n%s", err, stderr)
+	}
+	if !strings.Contains(stderr, "allow listIndexes app.orders") {
+		t.Errorf("stderr = %q, want the command logged", stderr)
+	}
+
+	blockNext = true
+	_, stderr, err = execCLI(t, "audit", "--uri", "mongodb://stub", "--strict-readonly", "--no-ignore", "--lint-uri=false")
+	requireExitCode(t, err, ExitReadOnlyViolation)
+	if !strings.Contains(stderr, "BLOCK dropIndexes app.orders (not in the read-only allowlist)") ||
+		!strings.Contains(stderr, "--strict-readonly blocked dropIndexes on app.orders") {
+		t.Errorf("stderr = %q, want the blocked command reported", stderr)
+	}
+
+	// Without the flag nothing is logged.
+	stubNewInspector(t, func(_ context.Context, cfg mongoinspect.Config) (inspector, error) {
+		if cfg.StrictReadOnly || cfg.OnCommand != nil {
+			t.Errorf("unexpected read-only config: %+v", cfg)
+		}
+		return &fakeInspector{serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"}}, nil
+	})
+	if _, _, err := execCLI(t, "audit", "--uri", "mongodb://stub", "--no-ignore", "--lint-uri=false"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+)
+
+// configRetention compiles the config's retention rules.
+func configRetention() (analyzer.RetentionPolicy, error) {
+	rules := make([]analyzer.RetentionRule, 0, len(cfg.Retention))
+	for i, r := range cfg.Retention {
+		maxAge, err := analyzer.ParseRetentionAge(r.MaxAge)
+		if err != nil {
+			return nil, fmt.Errorf("retention: rule %d: %w", i+1, err)
+		}
+		rules = append(rules, analyzer.RetentionRule{Database: r.Database, Collection: r.Collection, MaxAge: maxAge})
+	}
+	policy, err := analyzer.NewRetentionPolicy(rules)
+	if err != nil {
+		return nil, fmt.Errorf("retention: %w", err)
+	}
+	return policy, nil
+}
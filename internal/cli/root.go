@@ -7,16 +7,28 @@ import (
 	"runtime"
 	"time"
 
+	"github.com/ppiankov/mongospectre/internal/analyzer"
 	"github.com/ppiankov/mongospectre/internal/config"
+	"github.com/ppiankov/mongospectre/internal/i18n"
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
 	"github.com/spf13/cobra"
 )
 
 var (
 	version string
 	uri     string
+	cluster string
 	verbose bool
 	timeout time.Duration
-	cfg     config.Config
+	// concurrency and dbTimeout bound per-database metadata collection;
+	// retries covers reads that fail with transient server errors.
+	concurrency int
+	dbTimeout   time.Duration
+	retries     int
+	tlsOpts     mongoinspect.TLSConfig
+	cfg         config.Config
+	// lang is the report language; empty means English.
+	lang string
 )
 
 // BuildInfo holds version and build metadata.
@@ -40,6 +52,11 @@ func newRootCmd(info BuildInfo) *cobra.Command {
 			if err != nil {
 				return fmt.Errorf("config: %w", err)
 			}
+			analyzer.SetThresholds(analyzerThresholds(cfg.Analyzer))
+
+			if err := resolveCluster(cmd); err != nil {
+				return err
+			}
 
 			// Apply config defaults where CLI flags were not explicitly set.
 			if !cmd.Flags().Changed("uri") && uri == "" {
@@ -54,20 +71,78 @@ func newRootCmd(info BuildInfo) *cobra.Command {
 			if !cmd.Flags().Changed("timeout") {
 				timeout = cfg.TimeoutDuration()
 			}
+			if !cmd.Flags().Changed("concurrency") && cfg.Defaults.Concurrency > 0 {
+				concurrency = cfg.Defaults.Concurrency
+			}
+			if !cmd.Flags().Changed("db-timeout") {
+				dbTimeout = cfg.DBTimeoutDuration()
+			}
+			if !cmd.Flags().Changed("retries") && cfg.Defaults.Retries > 0 {
+				retries = cfg.Defaults.Retries
+			}
+			if !cmd.Flags().Changed("strict-readonly") && cfg.Defaults.StrictReadOnly {
+				strictReadOnly = true
+			}
+			if strictReadOnly {
+				readOnlyLog = &commandLog{w: cmd.ErrOrStderr()}
+			}
+			if commandLogPath != "" {
+				if commandLogOut, err = openCommandLog(commandLogPath); err != nil {
+					return err
+				}
+			}
+			if !cmd.Flags().Changed("lang") {
+				lang = cfg.Defaults.Lang
+			}
+			l, err := i18n.Parse(lang)
+			if err != nil {
+				return fmt.Errorf("--lang: %w", err)
+			}
+			lang = string(l)
+			if !cmd.Flags().Changed("tls-ca-file") {
+				tlsOpts.CAFile = cfg.TLS.CAFile
+			}
+			if !cmd.Flags().Changed("tls-cert-key-file") {
+				tlsOpts.CertKeyFile = cfg.TLS.CertKeyFile
+			}
+			if !cmd.Flags().Changed("tls-insecure") {
+				tlsOpts.Insecure = cfg.TLS.Insecure
+			}
 			return nil
 		},
 	}
 
 	root.PersistentFlags().StringVar(&uri, "uri", "", "MongoDB connection URI (env: MONGODB_URI)")
+	root.PersistentFlags().StringVar(&cluster, "cluster", "", "use the named clusters: profile from .mongospectre.yml, or a connection string stored with 'mongospectre login <alias>'")
 	root.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose output")
 	root.PersistentFlags().DurationVar(&timeout, "timeout", 30*time.Second, "operation timeout")
+	root.PersistentFlags().IntVar(&concurrency, "concurrency", mongoinspect.DefaultConcurrency, "databases to collect users and validators from in parallel")
+	root.PersistentFlags().DurationVar(&dbTimeout, "db-timeout", 0, "timeout for each database's metadata (0 = bounded only by --timeout)")
+	root.PersistentFlags().IntVar(&retries, "retries", mongoinspect.DefaultRetries, "retries for reads that fail with transient errors such as elections (0 = none)")
+	root.PersistentFlags().BoolVar(&strictReadOnly, "strict-readonly", false, "refuse any server command outside the read-only allowlist and log every command sent to stderr")
+	root.PersistentFlags().StringVar(&commandLogPath, "command-log", "", "append every command run on MongoDB, with duration and reply size, to this file as JSON lines")
+	root.PersistentFlags().StringVar(&lang, "lang", "", "language of report text: en, de, ru or es (only core finding messages are translated; rule IDs and finding types stay in English)")
+	root.PersistentFlags().StringVar(&tlsOpts.CAFile, "tls-ca-file", "", "PEM file with CA certificates for verifying the server (enables TLS)")
+	root.PersistentFlags().StringVar(&tlsOpts.CertKeyFile, "tls-cert-key-file", "", "PEM file with the client certificate and key for mTLS / X.509 auth (enables TLS)")
+	root.PersistentFlags().BoolVar(&tlsOpts.Insecure, "tls-insecure", false, "skip server certificate verification (testing only)")
 
 	root.AddCommand(newVersionCmd(info))
 	root.AddCommand(newAuditCmd())
 	root.AddCommand(newCheckCmd())
+	root.AddCommand(newLogscanCmd())
 	root.AddCommand(newCompareCmd())
+	root.AddCommand(newExportSnapshotCmd())
+	root.AddCommand(newSnapshotCmd())
+	root.AddCommand(newFTDCCmd())
 	root.AddCommand(newWatchCmd())
+	root.AddCommand(newServeCmd())
+	root.AddCommand(newTrendCmd())
+	root.AddCommand(newHistoryCmd())
+	root.AddCommand(newRulesCmd())
 	root.AddCommand(newInitCmd())
+	root.AddCommand(newInstallHookCmd())
+	root.AddCommand(newLoginCmd())
+	root.AddCommand(newLogoutCmd())
 
 	return root
 }
@@ -95,6 +170,11 @@ func newVersionCmd(info BuildInfo) *cobra.Command {
 	return cmd
 }
 
+// ExitInterrupted is the exit code of an audit or check cancelled by
+// SIGINT after writing its partial report (128 + SIGINT). SIGTERM exits
+// with 143, 128 plus its signal number.
+const ExitInterrupted = 130
+
 // ExitError signals a non-zero exit code without calling os.Exit directly.
 // This allows tests to observe exit codes without killing the test process.
 type ExitError struct {
@@ -114,5 +194,5 @@ func Execute(v, commit, date string) error {
 		Date:      date,
 		GoVersion: runtime.Version(),
 	}
-	return newRootCmd(info).Execute()
+	return execute(newRootCmd(info))
 }
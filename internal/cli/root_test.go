@@ -47,3 +47,15 @@ func TestFormatValidationErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestInvalidGroupByRejected(t *testing.T) {
+	for _, args := range [][]string{
+		{"audit", "--uri", "mongodb://stub", "--group-by", "severity"},
+		{"check", "--uri", "mongodb://stub", "--repo", t.TempDir(), "--group-by", "severity"},
+	} {
+		_, _, err := execCLI(t, args...)
+		if err == nil || !strings.Contains(err.Error(), "invalid --group-by") {
+			t.Fatalf("%s: expected invalid --group-by error, got %v", args[0], err)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	"github.com/spf13/cobra"
+)
+
+// ruleInfo is a catalog entry as printed by rules --format json.
+type ruleInfo struct {
+	analyzer.Rule
+	DocURL string `json:"docUrl"`
+}
+
+func newRulesCmd() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "rules [ID|TYPE...]",
+		Short: "List the finding rules with descriptions, default severities and related settings",
+		Long: "Lists every finding type with its stable rule ID, default severity, description,\n" +
+			"the config keys and flags that affect it, and its documentation URL.\n" +
+			"Pass rule IDs (MS004) or finding types (MISSING_INDEX) to show only those rules.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateFormat(format, "text", "json", "markdown"); err != nil {
+				return err
+			}
+			rules, err := selectRules(args)
+			if err != nil {
+				return err
+			}
+
+			switch format {
+			case "json":
+				out := make([]ruleInfo, len(rules))
+				for i := range rules {
+					out[i] = ruleInfo{Rule: rules[i], DocURL: rules[i].DocURL()}
+				}
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(out)
+			case "markdown":
+				writeRulesMarkdown(cmd.OutOrStdout(), rules)
+			default:
+				writeRules(cmd.OutOrStdout(), rules)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", "text", "output format: text, json or markdown")
+
+	return cmd
+}
+
+// selectRules returns the rules named by ID or finding type, or all rules.
+func selectRules(args []string) ([]analyzer.Rule, error) {
+	all := analyzer.Rules()
+	if len(args) == 0 {
+		return all, nil
+	}
+	var out []analyzer.Rule
+	for _, arg := range args {
+		found := false
+		for i := range all {
+			if strings.EqualFold(arg, all[i].ID) || strings.EqualFold(arg, string(all[i].Type)) {
+				out = append(out, all[i])
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown rule %q", arg)
+		}
+	}
+	return out, nil
+}
+
+func writeRules(w io.Writer, rules []analyzer.Rule) {
+	if len(rules) == 1 {
+		r := rules[0]
+		_, _ = fmt.Fprintf(w, "%s %s (default severity: %s)\n\n%s.\n\n", r.ID, r.Type, r.Severity, r.Description)
+		if len(r.Config) > 0 {
+			_, _ = fmt.Fprintf(w, "Settings: %s\n", strings.Join(r.Config, ", "))
+		}
+		_, _ = fmt.Fprintf(w, "Docs: %s\n", r.DocURL())
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "ID\tTYPE\tSEVERITY\tDESCRIPTION\tSETTINGS")
+	for _, r := range rules {
+		settings := strings.Join(r.Config, ", ")
+		if settings == "" {
+			settings = "-"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.ID, r.Type, r.Severity, r.Description, settings)
+	}
+	_ = tw.Flush()
+}
+
+// writeRulesMarkdown renders the rule reference; docs/rules.md is
+// generated with it.
+func writeRulesMarkdown(w io.Writer, rules []analyzer.Rule) {
+	_, _ = fmt.Fprint(w, "# Rule Reference\n\n"+
+		"Every finding mongospectre reports has a stable rule ID. IDs are never renumbered or reused.\n"+
+		"Severities are defaults; some rules escalate or downgrade depending on what they find.\n\n"+
+		"This file is generated by `mongospectre rules --format markdown`.\n")
+	for _, r := range rules {
+		_, _ = fmt.Fprintf(w, "\n### %s\n\n`%s` · default severity **%s**\n\n%s.\n", r.ID, r.Type, r.Severity, r.Description)
+		if len(r.Config) > 0 {
+			quoted := make([]string, len(r.Config))
+			for i, c := range r.Config {
+				quoted[i] = "`" + c + "`"
+			}
+			_, _ = fmt.Fprintf(w, "\nRelated settings: %s\n", strings.Join(quoted, ", "))
+		}
+	}
+}
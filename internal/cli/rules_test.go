@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRulesCmd(t *testing.T) {
+	stdout, _, err := execCLI(t, "rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout, "MS004  MISSING_INDEX") {
+		t.Errorf("missing MISSING_INDEX row:\n%s", stdout)
+	}
+
+	stdout, _, err = execCLI(t, "rules", "large_index")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"MS088 LARGE_INDEX (default severity: low)", "Settings: analyzer.large_index_gb", "docs/rules.md#ms088"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("missing %q:\n%s", want, stdout)
+		}
+	}
+
+	stdout, _, err = execCLI(t, "rules", "MS004", "--format", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rules []ruleInfo
+	if err := json.Unmarshal([]byte(stdout), &rules); err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 1 || rules[0].Type != "MISSING_INDEX" || !strings.HasSuffix(rules[0].DocURL, "#ms004") {
+		t.Errorf("rules = %+v", rules)
+	}
+
+	if _, _, err := execCLI(t, "rules", "NOPE"); err == nil || !strings.Contains(err.Error(), `unknown rule "NOPE"`) {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestRulesMarkdownMatchesDocs(t *testing.T) {
+	want, err := os.ReadFile("../../docs/rules.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, _, err := execCLI(t, "rules", "--format", "markdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stdout != string(want) {
+		t.Error("docs/rules.md is stale: regenerate it with `mongospectre rules --format markdown > docs/rules.md`")
+	}
+}
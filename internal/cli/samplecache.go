@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"context"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+// defaultSampleRefresh is how long watch reuses a collection's field sample
+// when its document count stays in the same bucket.
+const defaultSampleRefresh = time.Hour
+
+// sampleCache keeps field samples between watch runs. A sample is reused
+// while the collection keeps its UUID (it was not dropped and recreated),
+// its document count stays within the same power-of-two bucket, and the
+// sample is younger than refresh.
+type sampleCache struct {
+	refresh time.Duration
+	now     func() time.Time
+	entries map[string]cachedSample
+}
+
+type cachedSample struct {
+	sampledAt time.Time
+	result    mongoinspect.FieldSampleResult
+}
+
+func newSampleCache(refresh time.Duration) *sampleCache {
+	return &sampleCache{refresh: refresh, now: time.Now, entries: make(map[string]cachedSample)}
+}
+
+// sampleCacheKey identifies a collection generation and its rough size.
+// Servers that report no UUID fall back to the namespace.
+func sampleCacheKey(c *mongoinspect.CollectionInfo) string {
+	id := c.UUID
+	if id == "" {
+		id = c.Database + "." + c.Name
+	}
+	return id + "/" + strconv.Itoa(bits.Len64(uint64(max(c.DocCount, 0))))
+}
+
+// samples returns a field sample for each sampleable collection, taking
+// fresh ones from sample and the rest from the cache. Entries for
+// collections that are gone or have changed are dropped. It also returns
+// how many collections were sampled.
+func (c *sampleCache) samples(ctx context.Context, collections []mongoinspect.CollectionInfo,
+	sample func(ctx context.Context, database, collection string) (mongoinspect.FieldSampleResult, error),
+) ([]mongoinspect.FieldSampleResult, int, error) {
+	now := c.now()
+	kept := make(map[string]cachedSample, len(c.entries))
+	var results []mongoinspect.FieldSampleResult
+	sampled := 0
+	for i := range collections {
+		coll := &collections[i]
+		if coll.Type == "view" || strings.HasPrefix(coll.Name, "system.") || coll.DocCount == 0 {
+			continue
+		}
+		key := sampleCacheKey(coll)
+		entry, ok := c.entries[key]
+		if !ok || now.Sub(entry.sampledAt) >= c.refresh {
+			res, err := sample(ctx, coll.Database, coll.Name)
+			if err != nil {
+				return nil, sampled, err
+			}
+			entry = cachedSample{sampledAt: now, result: res}
+			sampled++
+		}
+		kept[key] = entry
+		if entry.result.SampleSize > 0 {
+			results = append(results, entry.result)
+		}
+	}
+	c.entries = kept
+	return results, sampled, nil
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/spf13/cobra"
+)
+
+func TestSampleCacheReusesUnchangedCollections(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cache := newSampleCache(time.Hour)
+	cache.now = func() time.Time { return now }
+
+	var sampled []string
+	sample := func(_ context.Context, database, collection string) (mongoinspect.FieldSampleResult, error) {
+		sampled = append(sampled, database+"."+collection)
+		return mongoinspect.FieldSampleResult{Database: database, Collection: collection, SampleSize: 10}, nil
+	}
+	run := func(colls []mongoinspect.CollectionInfo) []string {
+		t.Helper()
+		sampled = nil
+		results, n, err := cache.samples(context.Background(), colls, sample)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != len(sampled) || len(results) != len(colls)-1 {
+			t.Fatalf("sampled %d (reported %d), results %d", len(sampled), n, len(results))
+		}
+		return sampled
+	}
+
+	colls := []mongoinspect.CollectionInfo{
+		{Database: "app", Name: "users", UUID: "aa", DocCount: 1000},
+		{Database: "app", Name: "orders", UUID: "bb", DocCount: 5000},
+		{Database: "app", Name: "recent", Type: "view"},
+	}
+	if got := run(colls); len(got) != 2 {
+		t.Fatalf("first run sampled %v, want both collections", got)
+	}
+
+	// Small growth stays in the same bucket.
+	colls[0].DocCount = 1020
+	now = now.Add(10 * time.Minute)
+	if got := run(colls); len(got) != 0 {
+		t.Fatalf("unchanged run sampled %v, want none", got)
+	}
+
+	// Doubling the count or recreating the collection forces a resample.
+	colls[0].DocCount = 2100
+	colls[1].UUID = "cc"
+	if got := run(colls); len(got) != 2 {
+		t.Fatalf("changed run sampled %v, want both collections", got)
+	}
+
+	// The refresh interval expires every entry.
+	now = now.Add(time.Hour)
+	if got := run(colls); len(got) != 2 {
+		t.Fatalf("refresh run sampled %v, want both collections", got)
+	}
+}
+
+func TestWatchSampleSizeAddsSampleFindings(t *testing.T) {
+	fake := &fakeInspector{
+		serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"},
+		inspectResult: []mongoinspect.CollectionInfo{
+			{Database: "app", Name: "events", UUID: "aa", DocCount: 10, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+		},
+		sampleDocsRes: []mongoinspect.FieldSampleResult{
+			{Database: "app", Collection: "events", SampleSize: 3, MaxDocSize: 15 << 20},
+		},
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return fake, nil
+	})
+
+	prevTimeout := timeout
+	timeout = time.Second
+	t.Cleanup(func() { timeout = prevTimeout })
+	w := &watcher{uri: "mongodb://stub", noIgnore: true, cmd: &cobra.Command{}, sampleSize: 50, samples: newSampleCache(time.Hour)}
+	for range 2 {
+		result, err := w.inspect(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertHasType(t, result.findings, analyzer.FindingDocumentsNear16MB)
+	}
+	if len(fake.sampleCollCalls) != 1 {
+		t.Fatalf("sampled %v, want one call across two runs", fake.sampleCollCalls)
+	}
+}
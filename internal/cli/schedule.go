@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/schedule"
+	"github.com/spf13/cobra"
+)
+
+// runSchedule decides when watch/serve audits run: either a fixed interval
+// measured from the end of the previous run, or a cron expression.
+type runSchedule struct {
+	interval time.Duration
+	cron     *schedule.Cron
+	jitter   time.Duration
+}
+
+// resolveSchedule combines the --interval, --schedule and --jitter flags with
+// the schedule and schedule_jitter keys of the --cluster profile, falling back
+// to the top-level config keys. Explicit flags win; an explicit --interval
+// also overrides a schedule set only in config.
+func resolveSchedule(cmd *cobra.Command, interval time.Duration, cronExpr string, jitter time.Duration) (runSchedule, error) {
+	intervalSet := cmd.Flags().Changed("interval")
+	if intervalSet && cmd.Flags().Changed("schedule") {
+		return runSchedule{}, fmt.Errorf("--interval and --schedule are mutually exclusive")
+	}
+	if !cmd.Flags().Changed("schedule") && !intervalSet {
+		cronExpr = cfg.Schedule
+		if activeProfile.Schedule != "" {
+			cronExpr = activeProfile.Schedule
+		}
+	}
+	jitterKey, jitterValue := "schedule_jitter", cfg.ScheduleJitter
+	if activeProfile.ScheduleJitter != "" {
+		jitterKey, jitterValue = fmt.Sprintf("clusters.%s.schedule_jitter", cluster), activeProfile.ScheduleJitter
+	}
+	if !cmd.Flags().Changed("jitter") && jitterValue != "" {
+		d, err := time.ParseDuration(jitterValue)
+		if err != nil {
+			return runSchedule{}, fmt.Errorf("config %s: %w", jitterKey, err)
+		}
+		jitter = d
+	}
+	if jitter < 0 {
+		return runSchedule{}, fmt.Errorf("--jitter must not be negative")
+	}
+
+	rs := runSchedule{interval: interval, jitter: jitter}
+	if cronExpr != "" {
+		c, err := schedule.Parse(cronExpr)
+		if err != nil {
+			return runSchedule{}, err
+		}
+		rs.cron = c
+		return rs, nil
+	}
+	if interval <= 0 {
+		return runSchedule{}, fmt.Errorf("--interval must be greater than 0")
+	}
+	return rs, nil
+}
+
+// String describes the schedule for startup log lines.
+func (rs runSchedule) String() string {
+	var s string
+	if rs.cron != nil {
+		s = fmt.Sprintf("on schedule %q", rs.cron.String())
+	} else {
+		s = fmt.Sprintf("every %s", rs.interval)
+	}
+	if rs.jitter > 0 {
+		s += fmt.Sprintf(" (jitter up to %s)", rs.jitter)
+	}
+	return s
+}
+
+// nextWait returns how long to wait before the next run, given when the
+// previous run started and the current time. Runs never overlap: cron slots
+// that passed while the previous run was still going are skipped, and the
+// number skipped is returned so callers can log it.
+func (rs runSchedule) nextWait(started, now time.Time) (time.Duration, int) {
+	var wait time.Duration
+	skipped := 0
+	if rs.cron == nil {
+		wait = rs.interval
+	} else {
+		next := rs.cron.Next(started)
+		for !next.IsZero() && !next.After(now) {
+			skipped++
+			next = rs.cron.Next(next)
+		}
+		if next.IsZero() {
+			// Unreachable for schedules accepted by schedule.Parse; fall back
+			// to the interval rather than spinning.
+			next = now.Add(rs.interval)
+		}
+		wait = next.Sub(now)
+	}
+	if rs.jitter > 0 {
+		wait += rand.N(rs.jitter)
+	}
+	return wait, skipped
+}
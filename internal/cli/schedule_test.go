@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/config"
+	"github.com/ppiankov/mongospectre/internal/schedule"
+)
+
+func TestWatchScheduleFlagsMutuallyExclusive(t *testing.T) {
+	_, _, err := execCLI(t, "watch", "--uri", "mongodb://stub", "--interval", "1m", "--schedule", "0 3 * * *")
+	if err == nil || !strings.Contains(err.Error(), "mutually exclusive") {
+		t.Fatalf("expected mutually exclusive error, got %v", err)
+	}
+}
+
+func TestServeRejectsInvalidSchedule(t *testing.T) {
+	_, _, err := execCLI(t, "serve", "--uri", "mongodb://stub", "--schedule", "61 * * * *")
+	if err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Fatalf("expected cron parse error, got %v", err)
+	}
+}
+
+func TestResolveScheduleFromConfig(t *testing.T) {
+	prevCfg := cfg
+	t.Cleanup(func() { cfg = prevCfg })
+	cfg = config.Config{Schedule: "0 3 * * *", ScheduleJitter: "2m"}
+
+	cmd := newWatchCmd()
+	rs, err := resolveSchedule(cmd, 5*time.Minute, "", 0)
+	if err != nil {
+		t.Fatalf("resolveSchedule: %v", err)
+	}
+	if rs.cron == nil || rs.cron.String() != "0 3 * * *" || rs.jitter != 2*time.Minute {
+		t.Fatalf("unexpected schedule: %+v", rs)
+	}
+	if got := rs.String(); got != `on schedule "0 3 * * *" (jitter up to 2m0s)` {
+		t.Fatalf("String = %q", got)
+	}
+
+	// An explicit --interval overrides a schedule that only comes from config.
+	if err := cmd.Flags().Set("interval", "1m"); err != nil {
+		t.Fatal(err)
+	}
+	rs, err = resolveSchedule(cmd, time.Minute, "", 0)
+	if err != nil {
+		t.Fatalf("resolveSchedule: %v", err)
+	}
+	if rs.cron != nil || rs.interval != time.Minute {
+		t.Fatalf("expected interval schedule, got %+v", rs)
+	}
+}
+
+func TestResolveScheduleFromClusterProfile(t *testing.T) {
+	prevCfg, prevProfile, prevCluster := cfg, activeProfile, cluster
+	t.Cleanup(func() { cfg, activeProfile, cluster = prevCfg, prevProfile, prevCluster })
+	cfg = config.Config{
+		Schedule:       "0 3 * * *",
+		ScheduleJitter: "2m",
+		Clusters: map[string]config.Cluster{
+			"prod":    {Schedule: "30 1 * * *", ScheduleJitter: "10m"},
+			"staging": {ScheduleJitter: "later"},
+			"dev":     {},
+		},
+	}
+
+	// The profile's keys win over the top-level ones.
+	cluster, activeProfile = "prod", cfg.Clusters["prod"]
+	rs, err := resolveSchedule(newWatchCmd(), 5*time.Minute, "", 0)
+	if err != nil {
+		t.Fatalf("resolveSchedule: %v", err)
+	}
+	if rs.cron == nil || rs.cron.String() != "30 1 * * *" || rs.jitter != 10*time.Minute {
+		t.Fatalf("unexpected schedule: %+v", rs)
+	}
+
+	// A profile without them falls back to the top-level keys.
+	cluster, activeProfile = "dev", cfg.Clusters["dev"]
+	rs, err = resolveSchedule(newWatchCmd(), 5*time.Minute, "", 0)
+	if err != nil {
+		t.Fatalf("resolveSchedule: %v", err)
+	}
+	if rs.cron == nil || rs.cron.String() != "0 3 * * *" || rs.jitter != 2*time.Minute {
+		t.Fatalf("unexpected fallback schedule: %+v", rs)
+	}
+
+	cluster, activeProfile = "staging", cfg.Clusters["staging"]
+	if _, err := resolveSchedule(newWatchCmd(), 5*time.Minute, "", 0); err == nil ||
+		!strings.Contains(err.Error(), "clusters.staging.schedule_jitter") {
+		t.Fatalf("expected profile schedule_jitter error, got %v", err)
+	}
+}
+
+func TestResolveScheduleInvalidConfigJitter(t *testing.T) {
+	prevCfg := cfg
+	t.Cleanup(func() { cfg = prevCfg })
+	cfg = config.Config{ScheduleJitter: "soon"}
+
+	_, err := resolveSchedule(newWatchCmd(), time.Minute, "", 0)
+	if err == nil || !strings.Contains(err.Error(), "schedule_jitter") {
+		t.Fatalf("expected schedule_jitter error, got %v", err)
+	}
+}
+
+func TestRunScheduleNextWait(t *testing.T) {
+	c, err := schedule.Parse("*/10 * * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs := runSchedule{cron: c}
+	started := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+
+	wait, skipped := rs.nextWait(started, started.Add(2*time.Minute))
+	if wait != 8*time.Minute || skipped != 0 {
+		t.Fatalf("wait=%s skipped=%d, want 8m0s and 0", wait, skipped)
+	}
+
+	// A run that lasted 25 minutes overlapped the 10:10 and 10:20 slots.
+	wait, skipped = rs.nextWait(started, started.Add(25*time.Minute))
+	if wait != 5*time.Minute || skipped != 2 {
+		t.Fatalf("wait=%s skipped=%d, want 5m0s and 2", wait, skipped)
+	}
+
+	interval := runSchedule{interval: time.Minute, jitter: 30 * time.Second}
+	for i := 0; i < 20; i++ {
+		wait, _ := interval.nextWait(started, started)
+		if wait < time.Minute || wait >= time.Minute+30*time.Second {
+			t.Fatalf("jittered wait %s out of range", wait)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+// listVectorSearchIndexes attaches the search indexes of every collection
+// that code runs $vectorSearch on. $listSearchIndexes only works on Atlas
+// and recent Community builds with mongot, so the first failure prints a
+// warning and stops the listing.
+func listVectorSearchIndexes(ctx context.Context, cmd *cobra.Command, insp inspector, scan *scanner.ScanResult, collections []mongoinspect.CollectionInfo) {
+	queried := make(map[string]bool)
+	for _, ref := range scan.SearchRefs {
+		if ref.Stage == scanner.SearchStageVector && ref.Collection != "" {
+			queried[strings.ToLower(ref.Collection)] = true
+		}
+	}
+	for i := range collections {
+		c := &collections[i]
+		if !queried[strings.ToLower(c.Name)] || c.Type == "view" {
+			continue
+		}
+		indexes, err := insp.ListSearchIndexes(ctx, c.Database, c.Name)
+		if err != nil {
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: vector search index checks skipped: %v\n", err)
+			return
+		}
+		c.SearchIndexes = indexes
+	}
+}
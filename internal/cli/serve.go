@@ -0,0 +1,199 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/reporter"
+	"github.com/ppiankov/mongospectre/internal/server"
+	"github.com/spf13/cobra"
+)
+
+func newServeCmd() *cobra.Command {
+	var (
+		database    string
+		listen      string
+		interval    time.Duration
+		cronExpr    string
+		jitter      time.Duration
+		noIgnore    bool
+		ignoreFile  string
+		historySize int
+		staleAfter  time.Duration
+		ackExpiry   time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run periodic audits and serve results over HTTP with a web dashboard",
+		Long: "Runs audit on a configurable interval and serves the latest report, collection inventory,\n" +
+			"and run history as a web dashboard and JSON API (/api/report, /api/history).\n" +
+			"Health endpoints /healthz, /readyz and /lastrun are served on the same address.\n" +
+			"With SLACK_SIGNING_SECRET set, /api/slack/actions handles the acknowledge and snooze\n" +
+			"buttons of Slack notifications by adding expiring rules to .mongospectreignore.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			database = profileDatabase(database)
+			if uri == "" {
+				return fmt.Errorf("--uri is required (or set MONGODB_URI)")
+			}
+			sched, err := resolveSchedule(cmd, interval, cronExpr, jitter)
+			if err != nil {
+				return err
+			}
+			if err := checkIgnoreFile(noIgnore, ignoreFile); err != nil {
+				return err
+			}
+
+			ln, err := net.Listen("tcp", listen)
+			if err != nil {
+				return fmt.Errorf("listen %s: %w", listen, err)
+			}
+
+			ctx, cancel := context.WithCancel(cmd.Context())
+			defer cancel()
+
+			// Handle SIGINT/SIGTERM for clean shutdown.
+			sigCh := make(chan os.Signal, 1)
+			signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+			go func() {
+				<-sigCh
+				cancel()
+			}()
+
+			srv := server.New(historySize)
+			srv.SetStaleAfter(resolveStaleAfter(staleAfter))
+			if secret := strings.TrimSpace(os.Getenv("SLACK_SIGNING_SECRET")); secret != "" {
+				if ackExpiry <= 0 {
+					return fmt.Errorf("--ack-expiry must be positive")
+				}
+				cwd, _ := os.Getwd()
+				srv.SetSlackActions(server.SlackActions{SigningSecret: secret, IgnoreDir: cwd, AckFor: ackExpiry})
+			}
+			owners, err := configOwnerMap()
+			if err != nil {
+				return err
+			}
+			classification, err := configClassification()
+			if err != nil {
+				return err
+			}
+			s := &serveRunner{
+				watcher: &watcher{
+					uri:        uri,
+					database:   database,
+					schedule:   sched,
+					noIgnore:   noIgnore,
+					ignoreFile: ignoreFile,
+					health:     srv,
+					owners:     owners,
+					cmd:        cmd,
+				},
+				server: srv,
+			}
+			s.watcher.classification = classification
+			return s.run(ctx, ln)
+		},
+	}
+
+	cmd.Flags().StringVar(&database, "database", "", "specific database to audit (default: all non-system)")
+	cmd.Flags().StringVar(&listen, "listen", "127.0.0.1:8080", "address for the HTTP dashboard and API")
+	cmd.Flags().DurationVar(&interval, "interval", 5*time.Minute, "time between audit runs")
+	cmd.Flags().StringVar(&cronExpr, "schedule", "", "cron expression for audit runs, e.g. \"0 3 * * *\" (overrides --interval)")
+	cmd.Flags().DurationVar(&jitter, "jitter", 0, "random delay of up to this duration added to each run")
+	cmd.Flags().BoolVar(&noIgnore, "no-ignore", false, "bypass ignore files")
+	cmd.Flags().StringVar(&ignoreFile, "ignore-file", "", "extra ignore file merged with .mongospectreignore and ~/.config/mongospectre/ignore")
+	cmd.Flags().IntVar(&historySize, "history", server.DefaultHistorySize, "number of audit runs kept in run history")
+	cmd.Flags().DurationVar(&ackExpiry, "ack-expiry", 30*24*time.Hour, "how long a finding acknowledged from Slack stays in .mongospectreignore")
+	cmd.Flags().DurationVar(&staleAfter, "stale-after", 0, "report unhealthy when a run takes or is overdue by longer than this (default: 2x --timeout + 1m)")
+
+	return cmd
+}
+
+// serveRunner drives the audit loop and HTTP server for serve mode.
+type serveRunner struct {
+	watcher *watcher
+	server  *server.Server
+}
+
+func (s *serveRunner) run(ctx context.Context, ln net.Listener) error {
+	stderr := s.watcher.cmd.ErrOrStderr()
+
+	httpServer := &http.Server{
+		Handler:           s.server.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.Serve(ln)
+	}()
+
+	_, _ = fmt.Fprintf(stderr, "Serve mode: dashboard at http://%s/, auditing %s\n", ln.Addr(), s.watcher.schedule)
+
+loop:
+	for {
+		started := time.Now()
+		s.server.Started(started)
+		s.auditOnce(ctx)
+
+		select {
+		case <-ctx.Done():
+			break loop
+		case err := <-serveErr:
+			if errors.Is(err, http.ErrServerClosed) {
+				return nil
+			}
+			return fmt.Errorf("http server: %w", err)
+		case <-time.After(s.watcher.nextDelay(started)):
+		}
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = httpServer.Shutdown(shutdownCtx)
+	_, _ = fmt.Fprintln(stderr, "\nServe mode stopped")
+	return nil
+}
+
+// auditOnce runs a single audit and records the outcome for the dashboard.
+func (s *serveRunner) auditOnce(ctx context.Context) {
+	stderr := s.watcher.cmd.ErrOrStderr()
+	started := time.Now()
+
+	result, err := s.watcher.inspect(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		s.server.Record(started, time.Since(started), nil, err)
+		_, _ = fmt.Fprintf(stderr, "[%s] audit error: %v\n", time.Now().UTC().Format(time.RFC3339), err)
+		return
+	}
+
+	report := reporter.NewReport(result.findings)
+	report.Metadata = reporter.Metadata{
+		Version:        version,
+		Command:        "serve",
+		Timestamp:      report.Metadata.Timestamp,
+		Host:           reporter.HostFromURI(s.watcher.uri),
+		Database:       s.watcher.database,
+		MongoDBVersion: result.serverVersion,
+		URIHash:        reporter.HashURI(s.watcher.uri),
+		Cluster:        cluster,
+		Tags:           activeProfile.Tags,
+	}
+	report.Collections = result.collections
+
+	run := s.server.Record(started, time.Since(started), &report, nil)
+	if verbose {
+		_, _ = fmt.Fprintf(stderr, "[%s] run %d: %d findings (%d collections)\n",
+			time.Now().UTC().Format(time.RFC3339), run.ID, report.Summary.Total, len(result.collections))
+	}
+}
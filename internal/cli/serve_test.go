@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/server"
+	"github.com/spf13/cobra"
+)
+
+func newTestServeRunner(stderr io.Writer) *serveRunner {
+	cmd := &cobra.Command{}
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(stderr)
+	return &serveRunner{
+		watcher: &watcher{
+			uri:      "mongodb://stub",
+			schedule: runSchedule{interval: 10 * time.Millisecond},
+			cmd:      cmd,
+		},
+		server: server.New(server.DefaultHistorySize),
+	}
+}
+
+func TestServeRunnerAuditOnceRecordsReport(t *testing.T) {
+	prevTimeout := timeout
+	t.Cleanup(func() { timeout = prevTimeout })
+	timeout = time.Second
+
+	fake := &fakeInspector{
+		serverInfo: mongoinspect.ServerInfo{Version: "7.0.4"},
+		inspectResult: []mongoinspect.CollectionInfo{
+			{Database: "app", Name: "orders", DocCount: 20000, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+		},
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return fake, nil
+	})
+
+	s := newTestServeRunner(&bytes.Buffer{})
+	s.auditOnce(context.Background())
+
+	report := s.server.Latest()
+	if report == nil {
+		t.Fatal("expected report to be recorded")
+	}
+	if report.Metadata.Command != "serve" || report.Metadata.MongoDBVersion != "7.0.4" {
+		t.Fatalf("unexpected metadata: %+v", report.Metadata)
+	}
+	if len(report.Collections) != 1 || report.Summary.Total == 0 {
+		t.Fatalf("expected collections and findings, got %+v", report)
+	}
+}
+
+func TestServeRunnerAuditOnceRecordsError(t *testing.T) {
+	prevTimeout := timeout
+	t.Cleanup(func() { timeout = prevTimeout })
+	timeout = time.Second
+
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return nil, errors.New("connection refused")
+	})
+
+	var stderr bytes.Buffer
+	s := newTestServeRunner(&stderr)
+	s.auditOnce(context.Background())
+
+	if s.server.Latest() != nil {
+		t.Fatal("expected no report after failed audit")
+	}
+	history := s.server.History()
+	if len(history) != 1 || !strings.Contains(history[0].Error, "connection refused") {
+		t.Fatalf("expected failed run in history, got %+v", history)
+	}
+	if !strings.Contains(stderr.String(), "audit error") {
+		t.Fatalf("expected audit error log, got: %q", stderr.String())
+	}
+}
+
+func TestServeRunnerRunServesReport(t *testing.T) {
+	prevTimeout := timeout
+	t.Cleanup(func() { timeout = prevTimeout })
+	timeout = time.Second
+
+	fake := &fakeInspector{
+		inspectResult: []mongoinspect.CollectionInfo{
+			{Database: "app", Name: "empty", DocCount: 0, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+		},
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return fake, nil
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	var stderr bytes.Buffer
+	s := newTestServeRunner(&stderr)
+	s.watcher.schedule = runSchedule{interval: time.Hour}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.run(ctx, ln) }()
+
+	url := "http://" + ln.Addr().String() + "/api/report"
+	deadline := time.Now().Add(2 * time.Second)
+	var body string
+	for time.Now().Before(deadline) {
+		resp, err := http.Get(url)
+		if err == nil {
+			data, _ := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				body = string(data)
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Fatalf("serve run returned error: %v", err)
+	}
+	if !strings.Contains(body, `"command":"serve"`) {
+		t.Fatalf("expected serve report from API, got: %q", body)
+	}
+	if !strings.Contains(stderr.String(), "Serve mode stopped") {
+		t.Fatalf("expected shutdown log, got: %q", stderr.String())
+	}
+}
+
+func TestServeCmdRequiresURI(t *testing.T) {
+	prevURI := uri
+	t.Cleanup(func() { uri = prevURI })
+	uri = ""
+
+	cmd := newServeCmd()
+	cmd.SetArgs(nil)
+	err := cmd.RunE(cmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "--uri is required") {
+		t.Fatalf("expected uri error, got %v", err)
+	}
+}
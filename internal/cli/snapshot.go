@@ -0,0 +1,276 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/reporter"
+	"github.com/spf13/cobra"
+)
+
+func newExportSnapshotCmd() *cobra.Command {
+	var (
+		database string
+		output   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export-snapshot",
+		Short: "Export collection, index and validator metadata for offline check --snapshot",
+		Long: "Writes a JSON bundle of collection, index and validator metadata (no documents, no credentials)\n" +
+			"that check --snapshot can analyze without a database connection, e.g. from a nightly job.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			database = profileDatabase(database)
+			if uri == "" {
+				return fmt.Errorf("--uri is required (or set MONGODB_URI)")
+			}
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+			defer cancel()
+
+			if verbose {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Connecting to %s (timeout %s)...\n", mongoinspect.RedactURI(uri), timeout)
+			}
+			inspector, err := newInspector(ctx, mongoinspect.Config{
+				URI:             uri,
+				Database:        database,
+				TLS:             tlsOpts,
+				Concurrency:     concurrency,
+				DatabaseTimeout: dbTimeout,
+				Retries:         retries,
+				StrictReadOnly:  strictReadOnly,
+				OnCommand:       onCommand(),
+				CommandLog:      commandLogHook(),
+			})
+			if err != nil {
+				return err
+			}
+			defer func() { _ = inspector.Close(ctx) }()
+
+			info, err := inspector.GetServerVersion(ctx)
+			if err != nil {
+				return fmt.Errorf("server info: %w", err)
+			}
+			collections, err := inspector.Inspect(ctx, database)
+			if err != nil {
+				return fmt.Errorf("inspect: %w", err)
+			}
+			validators, err := inspector.GetValidators(ctx, database)
+			if err != nil {
+				return fmt.Errorf("validators: %w", err)
+			}
+
+			snap := &mongoinspect.Snapshot{
+				Version:     mongoinspect.SnapshotVersion,
+				CreatedAt:   time.Now().UTC(),
+				Host:        reporter.HostFromURI(uri),
+				URIHash:     reporter.HashURI(uri),
+				Database:    database,
+				Server:      info,
+				Collections: mergeCollectionValidators(collections, validators),
+			}
+
+			var w io.Writer = cmd.OutOrStdout()
+			if output != "" {
+				f, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("create snapshot: %w", err)
+				}
+				defer func() { _ = f.Close() }()
+				w = f
+			}
+			if err := mongoinspect.WriteSnapshot(w, snap); err != nil {
+				return fmt.Errorf("write snapshot: %w", err)
+			}
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Exported %d collections (%d validators)\n", len(snap.Collections), len(validators))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&database, "database", "", "specific database to export (default: all non-system)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "write the snapshot to this file instead of stdout")
+
+	return cmd
+}
+
+func newSnapshotCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Work with metadata snapshots from export-snapshot",
+	}
+	cmd.AddCommand(newSnapshotDiffCmd())
+	return cmd
+}
+
+func newSnapshotDiffCmd() *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "diff <old> <new>",
+		Short: "Compare two metadata snapshots like compare does for live clusters",
+		Long: "Reports collections and indexes added, removed or changed between two snapshots, treating the\n" +
+			"first as the source and the second as the target. Either side may also be a mongodump directory\n" +
+			"or archive. No database connection is needed.",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateFormat(format, "text", "json"); err != nil {
+				return err
+			}
+			var snaps [2]*mongoinspect.Snapshot
+			for i, path := range args {
+				snap, err := mongoinspect.LoadSnapshotOrDump(path)
+				if err != nil {
+					return fmt.Errorf("load snapshot %s: %w", path, err)
+				}
+				snaps[i] = snap
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s: %d collections, MongoDB %s, taken %s\n",
+					path, len(snap.Collections), snap.Server.Version, snap.CreatedAt.UTC().Format(time.RFC3339))
+			}
+
+			findings := analyzer.Compare(snaps[0].Collections, snaps[1].Collections)
+			if format == "json" {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(findings); err != nil {
+					return fmt.Errorf("write json: %w", err)
+				}
+			} else {
+				writeCompareText(cmd, findings)
+			}
+			return compareExit(cmd, findings)
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "text", "output format: text or json")
+
+	return cmd
+}
+
+// openSnapshotInspector loads --snapshot, which is a snapshot file or a
+// mongodump directory or archive.
+func openSnapshotInspector(cmd *cobra.Command, path string) (*snapshotInspector, error) {
+	snap, err := mongoinspect.LoadSnapshotOrDump(path)
+	if err != nil {
+		return nil, fmt.Errorf("load snapshot: %w", err)
+	}
+	_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Using snapshot %s taken %s\n", path, snap.CreatedAt.UTC().Format(time.RFC3339))
+	if time.Since(snap.CreatedAt) > 7*24*time.Hour {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Hint: snapshot is more than a week old and may not match the cluster any more.\n")
+	}
+	return &snapshotInspector{snap: snap}, nil
+}
+
+// errSnapshotOffline is returned for inspector calls that need a live server.
+var errSnapshotOffline = errors.New("not available from a snapshot")
+
+// snapshotInspector serves check from an exported snapshot.
+type snapshotInspector struct {
+	snap *mongoinspect.Snapshot
+}
+
+func (s *snapshotInspector) Close(context.Context) error { return nil }
+
+func (s *snapshotInspector) GetServerVersion(context.Context) (mongoinspect.ServerInfo, error) {
+	return s.snap.Server, nil
+}
+
+// Inspect returns the snapshot's collections, narrowed to database if set.
+func (s *snapshotInspector) Inspect(_ context.Context, database string) ([]mongoinspect.CollectionInfo, error) {
+	var out []mongoinspect.CollectionInfo
+	for _, c := range s.snap.Collections {
+		if database == "" || strings.EqualFold(c.Database, database) {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func (s *snapshotInspector) GetValidators(_ context.Context, database string) ([]mongoinspect.ValidatorInfo, error) {
+	var out []mongoinspect.ValidatorInfo
+	for _, v := range s.snap.Validators() {
+		if database == "" || strings.EqualFold(v.Database, database) {
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+func (s *snapshotInspector) ListDatabases(context.Context, string) ([]mongoinspect.DatabaseInfo, error) {
+	return nil, errSnapshotOffline
+}
+
+func (s *snapshotInspector) ReadProfiler(context.Context, string, int64) ([]mongoinspect.ProfileEntry, error) {
+	return nil, errSnapshotOffline
+}
+
+func (s *snapshotInspector) InspectSharding(context.Context) (mongoinspect.ShardingInfo, error) {
+	return mongoinspect.ShardingInfo{}, errSnapshotOffline
+}
+
+func (s *snapshotInspector) InspectUsers(context.Context, string) ([]mongoinspect.UserInfo, error) {
+	return nil, errSnapshotOffline
+}
+
+func (s *snapshotInspector) InspectRoles(context.Context, string) ([]mongoinspect.RoleInfo, error) {
+	return nil, errSnapshotOffline
+}
+
+func (s *snapshotInspector) SampleDocuments(context.Context, string, int64) ([]mongoinspect.FieldSampleResult, error) {
+	return nil, errSnapshotOffline
+}
+
+func (s *snapshotInspector) SampleCollection(context.Context, string, string, int64) (mongoinspect.FieldSampleResult, error) {
+	return mongoinspect.FieldSampleResult{}, errSnapshotOffline
+}
+
+func (s *snapshotInspector) CountDuplicateKeys(context.Context, string, string, []string) (int64, error) {
+	return 0, errSnapshotOffline
+}
+
+func (s *snapshotInspector) InspectSecurity(context.Context) (mongoinspect.SecurityInfo, error) {
+	return mongoinspect.SecurityInfo{}, errSnapshotOffline
+}
+
+func (s *snapshotInspector) InspectServerParameters(context.Context) (mongoinspect.ServerParameters, error) {
+	return mongoinspect.ServerParameters{}, errSnapshotOffline
+}
+
+func (s *snapshotInspector) InspectReplicaSet(context.Context) (mongoinspect.ReplicaSetInfo, error) {
+	return mongoinspect.ReplicaSetInfo{}, errSnapshotOffline
+}
+
+func (s *snapshotInspector) InspectCache(context.Context) (mongoinspect.CacheInfo, error) {
+	return mongoinspect.CacheInfo{}, errSnapshotOffline
+}
+
+func (s *snapshotInspector) InspectTransactions(context.Context) (mongoinspect.TransactionStats, error) {
+	return mongoinspect.TransactionStats{}, errSnapshotOffline
+}
+
+func (s *snapshotInspector) CurrentOps(context.Context) ([]mongoinspect.ClientOp, error) {
+	return nil, errSnapshotOffline
+}
+
+func (s *snapshotInspector) ConnPoolStats(context.Context) (mongoinspect.ConnPoolStats, error) {
+	return mongoinspect.ConnPoolStats{}, errSnapshotOffline
+}
+
+func (s *snapshotInspector) ListSearchIndexes(context.Context, string, string) ([]mongoinspect.SearchIndex, error) {
+	return nil, errSnapshotOffline
+}
+
+func (s *snapshotInspector) SampleOplog(context.Context, string, int64) (mongoinspect.OplogProfile, error) {
+	return mongoinspect.OplogProfile{}, errSnapshotOffline
+}
+
+func (s *snapshotInspector) SampleTraffic(context.Context, string, time.Duration) (mongoinspect.TrafficSample, error) {
+	return mongoinspect.TrafficSample{}, errSnapshotOffline
+}
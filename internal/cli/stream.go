@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	"github.com/ppiankov/mongospectre/internal/gitinfo"
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/reporter"
+	"github.com/spf13/cobra"
+)
+
+// findingStream collects a command's findings and applies the ignore rules
+// as they arrive. With --format ndjson it also writes each finding when it
+// is added rather than from the finished report. Findings on
+// collections matching collection_patterns are held back until finish,
+// because collapsing them needs every member.
+type findingStream struct {
+	stderr   io.Writer
+	out      *reporter.NDJSONWriter // nil unless --format ndjson
+	ignore   *analyzer.IgnoreList   // nil with --no-ignore
+	hits     []int
+	patterns []analyzer.CollectionPattern
+	traffic  *mongoinspect.TrafficSample // set by --traffic-sample
+	blame    *blamer                     // set by --blame
+	owners   *gitinfo.CodeOwners         // the scanned repo's CODEOWNERS
+	ownerMap analyzer.OwnerMap           // config owners, after CODEOWNERS
+	owner    string                      // set by --filter-owner
+	redactor *analyzer.Redactor          // masks fields tagged in classification
+	findings []analyzer.Finding
+	held     []analyzer.Finding
+	err      error
+}
+
+func newFindingStream(cmd *cobra.Command, format string, noIgnore bool, ignoreFile string, patterns []analyzer.CollectionPattern) *findingStream {
+	s := &findingStream{stderr: cmd.ErrOrStderr(), patterns: patterns}
+	if !noIgnore {
+		il, err := loadIgnoreRules(s.stderr, ignoreFile)
+		if err != nil {
+			_, _ = fmt.Fprintf(s.stderr, "warning: %v\n", err)
+		}
+		s.ignore = &il
+		s.hits = make([]int, len(il.Rules))
+	}
+	if reporter.Format(format) == reporter.FormatNDJSON {
+		s.out = reporter.NewNDJSONWriter(cmd.OutOrStdout())
+	}
+	return s
+}
+
+// streaming reports whether findings are written as they are added.
+func (s *findingStream) streaming() bool {
+	return s.out != nil
+}
+
+func (s *findingStream) add(findings ...analyzer.Finding) {
+	for _, f := range findings {
+		if s.traffic != nil && !analyzer.ApplyTraffic(&f, s.traffic) {
+			continue
+		}
+		assignOwner(&f, s.owners)
+		s.ownerMap.Assign(&f)
+		if s.owner != "" && !ownedBy(f.Owner, s.owner) {
+			continue
+		}
+		if s.ignore != nil {
+			kept, hits := s.ignore.FilterHits([]analyzer.Finding{f})
+			for i, n := range hits {
+				s.hits[i] += n
+			}
+			if len(kept) == 0 {
+				continue
+			}
+		}
+		// Ignore rules match unredacted index names, so redaction follows.
+		s.redactor.Finding(&f)
+		if s.blame != nil {
+			s.blame.annotate(&f)
+		}
+		s.findings = append(s.findings, f)
+		if s.out == nil {
+			continue
+		}
+		if analyzer.MatchesCollectionPattern(&f, s.patterns) {
+			s.held = append(s.held, f)
+			continue
+		}
+		s.write(f)
+	}
+}
+
+// audit adds the cluster-only findings as each detector produces them and
+// returns how long each detector took.
+func (s *findingStream) audit(collections []mongoinspect.CollectionInfo, detectors []analyzer.Detector) []analyzer.DetectorTiming {
+	p := analyzer.NewPipeline(detectors...)
+	p.Run(collections, func(f analyzer.Finding) { s.add(f) })
+	return p.Timings()
+}
+
+func (s *findingStream) write(f analyzer.Finding) {
+	if s.err == nil {
+		s.err = s.out.WriteFinding(f)
+	}
+}
+
+// finish reports suppressed findings, writes the held-back tenant findings
+// collapsed per pattern, and returns all findings as the report lists them.
+func (s *findingStream) finish() ([]analyzer.Finding, error) {
+	if s.ignore != nil {
+		writeSuppressed(s.stderr, s.ignore, s.hits)
+	}
+	if s.out != nil {
+		for _, f := range analyzer.CollapseTenantFindings(s.held, s.patterns) {
+			s.write(f)
+		}
+	}
+	return analyzer.CollapseTenantFindings(s.findings, s.patterns), s.err
+}
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/reporter"
+	"github.com/spf13/cobra"
+)
+
+// runSummary is the small JSON document written by --summary-file and
+// --summary-fd, so wrapper scripts can read the outcome of a run without
+// parsing the full report.
+type runSummary struct {
+	Command     string           `json:"command"`
+	ExitCode    int              `json:"exitCode"`
+	DurationMS  int64            `json:"durationMs"`
+	Findings    reporter.Summary `json:"findings"`
+	Truncated   bool             `json:"truncated,omitempty"`
+	Interrupted bool             `json:"interrupted,omitempty"`
+	Error       string           `json:"error,omitempty"`
+}
+
+// summaryOutput holds the summary flags of a command. The command sets
+// report once it has built one; runs that fail earlier write a summary
+// with the error and no findings.
+type summaryOutput struct {
+	file   string
+	fd     int
+	report *reporter.Report
+}
+
+func (s *summaryOutput) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&s.file, "summary-file", "", "also write a JSON summary (finding counts, exit code, duration) to this file")
+	cmd.Flags().IntVar(&s.fd, "summary-fd", 0, "also write the JSON summary to this open file descriptor, e.g. 3 (2 for stderr)")
+}
+
+// wrap runs a command's RunE and writes the summary after it, once the
+// exit code is known.
+func (s *summaryOutput) wrap(run func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if s.file == "" && s.fd == 0 {
+			return run(cmd, args)
+		}
+		if s.fd < 0 {
+			return fmt.Errorf("--summary-fd must be a positive file descriptor, got %d", s.fd)
+		}
+
+		s.report = nil
+		started := time.Now()
+		err := run(cmd, args)
+		sum := runSummary{
+			Command:    cmd.Name(),
+			ExitCode:   exitCodeOf(err),
+			DurationMS: time.Since(started).Milliseconds(),
+		}
+		if s.report != nil {
+			sum.Findings = s.report.Summary
+			sum.Truncated = s.report.Metadata.Truncated
+			sum.Interrupted = s.report.Metadata.Interrupted
+		}
+		var exitErr *ExitError
+		if err != nil && !errors.As(err, &exitErr) {
+			sum.Error = err.Error()
+		}
+
+		if writeErr := s.write(&sum); writeErr != nil {
+			if err == nil {
+				return fmt.Errorf("write summary: %w", writeErr)
+			}
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: write summary: %v\n", writeErr)
+		}
+		return err
+	}
+}
+
+func (s *summaryOutput) write(sum *runSummary) error {
+	data, err := json.Marshal(sum)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if s.file != "" {
+		if err := os.WriteFile(s.file, data, 0o644); err != nil {
+			return err
+		}
+	}
+	if s.fd > 0 {
+		f, err := summaryFile(s.fd)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(data); err != nil {
+			return fmt.Errorf("fd %d: %w", s.fd, err)
+		}
+	}
+	return nil
+}
+
+// summaryFDs keeps the *os.File for each --summary-fd descriptor alive
+// for the life of the process. The descriptor belongs to the caller, and
+// an unreferenced *os.File would close it when garbage collected.
+var (
+	summaryFDsMu sync.Mutex
+	summaryFDs   = map[int]*os.File{}
+)
+
+func summaryFile(fd int) (*os.File, error) {
+	summaryFDsMu.Lock()
+	defer summaryFDsMu.Unlock()
+	if f, ok := summaryFDs[fd]; ok {
+		return f, nil
+	}
+	f := os.NewFile(uintptr(fd), "summary")
+	if f == nil {
+		return nil, fmt.Errorf("invalid file descriptor %d", fd)
+	}
+	summaryFDs[fd] = f
+	return f, nil
+}
+
+// exitCodeOf returns the process exit code main uses for err.
+func exitCodeOf(err error) int {
+	var exitErr *ExitError
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &exitErr):
+		return exitErr.Code
+	default:
+		return 1
+	}
+}
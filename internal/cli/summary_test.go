@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+)
+
+func readSummary(t *testing.T, path string) runSummary {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sum runSummary
+	if err := json.Unmarshal(data, &sum); err != nil {
+		t.Fatalf("invalid summary JSON: %v\n%s", err, data)
+	}
+	return sum
+}
+
+func TestAuditSummaryFile(t *testing.T) {
+	fake := &fakeInspector{
+		serverInfo: mongoinspect.ServerInfo{Version: "7.0.0"},
+		inspectResult: []mongoinspect.CollectionInfo{
+			{Database: "app", Name: "orders", DocCount: 0, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+		},
+	}
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return fake, nil
+	})
+
+	path := filepath.Join(t.TempDir(), "summary.json")
+	_, _, err := execCLI(t, "audit", "--uri", "mongodb://stub", "--summary-file", path)
+	code := exitCodeOf(err)
+
+	sum := readSummary(t, path)
+	if sum.Command != "audit" || sum.ExitCode != code {
+		t.Fatalf("summary = %+v, want audit with exit code %d", sum, code)
+	}
+	if sum.Findings.Total == 0 || sum.Error != "" {
+		t.Fatalf("summary = %+v, want the empty collection finding counted", sum)
+	}
+}
+
+func TestCheckSummaryFileOnError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.json")
+	_, _, err := execCLI(t, "check", "--repo", t.TempDir(), "--summary-file", path)
+	if err == nil {
+		t.Fatal("expected an error without --uri")
+	}
+
+	sum := readSummary(t, path)
+	if sum.Command != "check" || sum.ExitCode != 1 || sum.Error == "" || sum.Findings.Total != 0 {
+		t.Fatalf("summary = %+v, want exit 1 with the error and no findings", sum)
+	}
+}
+
+func TestSummaryFD(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = r.Close() }()
+
+	s := &summaryOutput{fd: int(w.Fd())}
+	if err := s.write(&runSummary{Command: "audit", ExitCode: 2}); err != nil {
+		t.Fatal(err)
+	}
+	_ = w.Close()
+
+	var sum runSummary
+	if err := json.NewDecoder(r).Decode(&sum); err != nil {
+		t.Fatal(err)
+	}
+	if sum.Command != "audit" || sum.ExitCode != 2 {
+		t.Fatalf("summary = %+v", sum)
+	}
+}
@@ -0,0 +1,16 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+)
+
+// configCollectionPatterns compiles the config's collection_patterns.
+func configCollectionPatterns() ([]analyzer.CollectionPattern, error) {
+	patterns, err := analyzer.CompileCollectionPatterns(cfg.CollectionPatterns)
+	if err != nil {
+		return nil, fmt.Errorf("collection patterns: %w", err)
+	}
+	return patterns, nil
+}
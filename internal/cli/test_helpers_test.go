@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/ppiankov/mongospectre/internal/atlas"
+	"github.com/ppiankov/mongospectre/internal/gitinfo"
 	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
 	"github.com/ppiankov/mongospectre/internal/scanner"
 )
@@ -19,10 +22,12 @@ type fakeInspector struct {
 	inspectByDB      map[string][]mongoinspect.CollectionInfo
 	inspectErr       error
 	inspectHook      func(string)
+	inspectUntilDone bool // block Inspect until its context is cancelled
 	listDatabasesRes []mongoinspect.DatabaseInfo
 	listDatabasesErr error
 	inspectUsersRes  map[string][]mongoinspect.UserInfo
 	inspectUsersErr  map[string]error
+	inspectRolesRes  map[string][]mongoinspect.RoleInfo
 	profilerRes      []mongoinspect.ProfileEntry
 	profilerErr      error
 	validatorsRes    []mongoinspect.ValidatorInfo
@@ -31,20 +36,42 @@ type fakeInspector struct {
 	shardingErr      error
 	sampleDocsRes    []mongoinspect.FieldSampleResult
 	sampleDocsErr    error
+	duplicatesRes    map[string]int64
+	duplicatesErr    error
 	securityRes      mongoinspect.SecurityInfo
 	securityErr      error
+	serverParamsRes  mongoinspect.ServerParameters
+	serverParamsErr  error
 	replsetRes       mongoinspect.ReplicaSetInfo
 	replsetErr       error
+	cacheRes         mongoinspect.CacheInfo
+	cacheErr         error
+	txStatsRes       mongoinspect.TransactionStats
+	txStatsErr       error
+	currentOpsRes    []mongoinspect.ClientOp
+	currentOpsErr    error
+	connPoolRes      mongoinspect.ConnPoolStats
+	connPoolErr      error
+	searchIndexes    map[string][]mongoinspect.SearchIndex // by "db.collection"
+	searchIndexesErr error
+	oplogRes         mongoinspect.OplogProfile
+	oplogErr         error
+	trafficRes       mongoinspect.TrafficSample
+	trafficErr       error
 	closeErr         error
 
 	inspectCalls           []string
 	listDatabasesCalls     []string
 	inspectUsersCalls      []string
+	mu                     sync.Mutex // guards call records written by parallel per-database calls
 	profilerCalls          []profilerCall
 	sampleDocsCalls        []sampleDocsCall
+	sampleCollCalls        []string
 	inspectShardingCalls   int
 	inspectSecurityCalls   int
 	inspectReplicaSetCalls int
+	oplogCalls             []int64
+	trafficCalls           []time.Duration
 	closeCalls             int
 }
 
@@ -77,6 +104,8 @@ type fakeAtlasClient struct {
 	databaseUsersErr    error
 	accessLogsRes       []atlas.AccessLogEntry
 	accessLogsErr       error
+	accessListRes       []atlas.AccessListEntry
+	accessListErr       error
 
 	getClusterCalls     []string
 	suggestedIndexCalls []string
@@ -87,6 +116,7 @@ type fakeAtlasClient struct {
 	resolveProjectCalls []string
 	databaseUsersCalls  []string
 	accessLogsCalls     []string
+	accessListCalls     []string
 }
 
 func (f *fakeInspector) Close(context.Context) error {
@@ -116,13 +146,17 @@ func (f *fakeInspector) GetValidators(context.Context, string) ([]mongoinspect.V
 	return append([]mongoinspect.ValidatorInfo(nil), f.validatorsRes...), nil
 }
 
-func (f *fakeInspector) Inspect(_ context.Context, database string) ([]mongoinspect.CollectionInfo, error) {
+func (f *fakeInspector) Inspect(ctx context.Context, database string) ([]mongoinspect.CollectionInfo, error) {
 	f.inspectCalls = append(f.inspectCalls, database)
 	if f.inspectHook != nil {
 		f.inspectHook(database)
 	}
+	if f.inspectUntilDone {
+		<-ctx.Done()
+	}
 	if f.inspectErr != nil {
-		return nil, f.inspectErr
+		// A *PartialError comes with the collections gathered so far.
+		return append([]mongoinspect.CollectionInfo(nil), f.inspectResult...), f.inspectErr
 	}
 	if f.inspectByDB != nil {
 		if res, ok := f.inspectByDB[database]; ok {
@@ -133,7 +167,9 @@ func (f *fakeInspector) Inspect(_ context.Context, database string) ([]mongoinsp
 }
 
 func (f *fakeInspector) InspectUsers(_ context.Context, dbName string) ([]mongoinspect.UserInfo, error) {
+	f.mu.Lock()
 	f.inspectUsersCalls = append(f.inspectUsersCalls, dbName)
+	f.mu.Unlock()
 	if err, ok := f.inspectUsersErr[dbName]; ok {
 		return nil, err
 	}
@@ -143,6 +179,10 @@ func (f *fakeInspector) InspectUsers(_ context.Context, dbName string) ([]mongoi
 	return nil, nil
 }
 
+func (f *fakeInspector) InspectRoles(_ context.Context, dbName string) ([]mongoinspect.RoleInfo, error) {
+	return append([]mongoinspect.RoleInfo(nil), f.inspectRolesRes[dbName]...), nil
+}
+
 func (f *fakeInspector) InspectSharding(context.Context) (mongoinspect.ShardingInfo, error) {
 	f.inspectShardingCalls++
 	if f.shardingErr != nil {
@@ -151,6 +191,13 @@ func (f *fakeInspector) InspectSharding(context.Context) (mongoinspect.ShardingI
 	return f.shardingRes, nil
 }
 
+func (f *fakeInspector) CountDuplicateKeys(_ context.Context, database, collection string, fields []string) (int64, error) {
+	if f.duplicatesErr != nil {
+		return 0, f.duplicatesErr
+	}
+	return f.duplicatesRes[database+"."+collection+":"+strings.Join(fields, ",")], nil
+}
+
 func (f *fakeInspector) InspectSecurity(context.Context) (mongoinspect.SecurityInfo, error) {
 	f.inspectSecurityCalls++
 	if f.securityErr != nil {
@@ -159,6 +206,48 @@ func (f *fakeInspector) InspectSecurity(context.Context) (mongoinspect.SecurityI
 	return f.securityRes, nil
 }
 
+func (f *fakeInspector) InspectServerParameters(context.Context) (mongoinspect.ServerParameters, error) {
+	if f.serverParamsErr != nil {
+		return mongoinspect.ServerParameters{}, f.serverParamsErr
+	}
+	return f.serverParamsRes, nil
+}
+
+func (f *fakeInspector) InspectCache(context.Context) (mongoinspect.CacheInfo, error) {
+	if f.cacheErr != nil {
+		return mongoinspect.CacheInfo{}, f.cacheErr
+	}
+	return f.cacheRes, nil
+}
+
+func (f *fakeInspector) InspectTransactions(context.Context) (mongoinspect.TransactionStats, error) {
+	if f.txStatsErr != nil {
+		return mongoinspect.TransactionStats{}, f.txStatsErr
+	}
+	return f.txStatsRes, nil
+}
+
+func (f *fakeInspector) CurrentOps(context.Context) ([]mongoinspect.ClientOp, error) {
+	if f.currentOpsErr != nil {
+		return nil, f.currentOpsErr
+	}
+	return f.currentOpsRes, nil
+}
+
+func (f *fakeInspector) ConnPoolStats(context.Context) (mongoinspect.ConnPoolStats, error) {
+	if f.connPoolErr != nil {
+		return mongoinspect.ConnPoolStats{}, f.connPoolErr
+	}
+	return f.connPoolRes, nil
+}
+
+func (f *fakeInspector) ListSearchIndexes(_ context.Context, database, collection string) ([]mongoinspect.SearchIndex, error) {
+	if f.searchIndexesErr != nil {
+		return nil, f.searchIndexesErr
+	}
+	return f.searchIndexes[database+"."+collection], nil
+}
+
 func (f *fakeInspector) InspectReplicaSet(context.Context) (mongoinspect.ReplicaSetInfo, error) {
 	f.inspectReplicaSetCalls++
 	if f.replsetErr != nil {
@@ -167,6 +256,22 @@ func (f *fakeInspector) InspectReplicaSet(context.Context) (mongoinspect.Replica
 	return f.replsetRes, nil
 }
 
+func (f *fakeInspector) SampleOplog(_ context.Context, _ string, limit int64) (mongoinspect.OplogProfile, error) {
+	f.oplogCalls = append(f.oplogCalls, limit)
+	if f.oplogErr != nil {
+		return mongoinspect.OplogProfile{}, f.oplogErr
+	}
+	return f.oplogRes, nil
+}
+
+func (f *fakeInspector) SampleTraffic(_ context.Context, _ string, window time.Duration) (mongoinspect.TrafficSample, error) {
+	f.trafficCalls = append(f.trafficCalls, window)
+	if f.trafficErr != nil {
+		return mongoinspect.TrafficSample{}, f.trafficErr
+	}
+	return f.trafficRes, nil
+}
+
 func (f *fakeInspector) SampleDocuments(_ context.Context, database string, sampleSize int64) ([]mongoinspect.FieldSampleResult, error) {
 	f.sampleDocsCalls = append(f.sampleDocsCalls, sampleDocsCall{database: database, sampleSize: sampleSize})
 	if f.sampleDocsErr != nil {
@@ -175,6 +280,20 @@ func (f *fakeInspector) SampleDocuments(_ context.Context, database string, samp
 	return append([]mongoinspect.FieldSampleResult(nil), f.sampleDocsRes...), nil
 }
 
+// SampleCollection returns the sampleDocsRes entry for the namespace.
+func (f *fakeInspector) SampleCollection(_ context.Context, database, collection string, _ int64) (mongoinspect.FieldSampleResult, error) {
+	f.sampleCollCalls = append(f.sampleCollCalls, database+"."+collection)
+	if f.sampleDocsErr != nil {
+		return mongoinspect.FieldSampleResult{}, f.sampleDocsErr
+	}
+	for _, r := range f.sampleDocsRes {
+		if r.Database == database && r.Collection == collection {
+			return r, nil
+		}
+	}
+	return mongoinspect.FieldSampleResult{Database: database, Collection: collection}, nil
+}
+
 func (f *fakeInspector) ListDatabases(_ context.Context, database string) ([]mongoinspect.DatabaseInfo, error) {
 	f.listDatabasesCalls = append(f.listDatabasesCalls, database)
 	if f.listDatabasesErr != nil {
@@ -255,6 +374,14 @@ func (f *fakeAtlasClient) ListAccessLogs(_ context.Context, projectID, clusterNa
 	return append([]atlas.AccessLogEntry(nil), f.accessLogsRes...), nil
 }
 
+func (f *fakeAtlasClient) ListAccessListEntries(_ context.Context, projectID string) ([]atlas.AccessListEntry, error) {
+	f.accessListCalls = append(f.accessListCalls, projectID)
+	if f.accessListErr != nil {
+		return nil, f.accessListErr
+	}
+	return append([]atlas.AccessListEntry(nil), f.accessListRes...), nil
+}
+
 func stubNewInspector(t *testing.T, fn func(context.Context, mongoinspect.Config) (inspector, error)) {
 	t.Helper()
 	orig := newInspector
@@ -273,6 +400,24 @@ func stubScanRepo(t *testing.T, fn func(string) (scanner.ScanResult, error)) {
 	})
 }
 
+func stubLastCommitTime(t *testing.T, fn func(context.Context, string, string) (time.Time, error)) {
+	t.Helper()
+	orig := lastCommitTime
+	lastCommitTime = fn
+	t.Cleanup(func() {
+		lastCommitTime = orig
+	})
+}
+
+func stubBlameLine(t *testing.T, fn func(context.Context, string, string, int) (gitinfo.Blame, bool, error)) {
+	t.Helper()
+	orig := blameLine
+	blameLine = fn
+	t.Cleanup(func() {
+		blameLine = orig
+	})
+}
+
 func stubNewAtlasClient(t *testing.T, fn func(atlas.Config) (atlasClient, error)) {
 	t.Helper()
 	orig := newAtlasClient
@@ -283,22 +428,36 @@ func stubNewAtlasClient(t *testing.T, fn func(atlas.Config) (atlasClient, error)
 }
 
 func execCLI(t *testing.T, args ...string) (stdout, stderr string, err error) {
+	t.Helper()
+	return execCLIWithInput(t, "", args...)
+}
+
+// execCLIWithInput runs the CLI with stdin set to input.
+func execCLIWithInput(t *testing.T, input string, args ...string) (stdout, stderr string, err error) {
 	t.Helper()
 	t.Setenv("HOME", t.TempDir())
 	prevURI := uri
 	prevVerbose := verbose
 	prevTimeout := timeout
 	prevVersion := version
+	prevTLS := tlsOpts
+	prevStrict := strictReadOnly
+	prevCommandLog := commandLogPath
 	t.Cleanup(func() {
+		strictReadOnly = prevStrict
+		commandLogPath = prevCommandLog
 		uri = prevURI
 		verbose = prevVerbose
 		timeout = prevTimeout
 		version = prevVersion
+		tlsOpts = prevTLS
 	})
 	uri = ""
 	verbose = false
 	timeout = 30 * time.Second
 	version = "test-version"
+	strictReadOnly = false
+	commandLogPath = ""
 
 	cmd := newRootCmd(testBuildInfo)
 	cmd.SilenceUsage = true
@@ -306,8 +465,9 @@ func execCLI(t *testing.T, args ...string) (stdout, stderr string, err error) {
 	var outBuf, errBuf bytes.Buffer
 	cmd.SetOut(&outBuf)
 	cmd.SetErr(&errBuf)
+	cmd.SetIn(strings.NewReader(input))
 	cmd.SetArgs(args)
-	err = cmd.Execute()
+	err = execute(cmd)
 	return outBuf.String(), errBuf.String(), err
 }
 
@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	"github.com/ppiankov/mongospectre/internal/config"
+)
+
+// analyzerThresholds converts the config's analyzer section into detector
+// thresholds. Unset values keep the analyzer's built-in defaults.
+func analyzerThresholds(a config.Analyzer) analyzer.ThresholdConfig {
+	tc := analyzer.ThresholdConfig{Default: toThresholds(a.AnalyzerLimits)}
+	if len(a.Databases) > 0 {
+		tc.Databases = make(map[string]analyzer.Thresholds, len(a.Databases))
+		for db, limits := range a.Databases {
+			tc.Databases[db] = toThresholds(limits)
+		}
+	}
+	return tc
+}
+
+func toThresholds(l config.AnalyzerLimits) analyzer.Thresholds {
+	return analyzer.Thresholds{
+		SuggestMinDocs:      l.SuggestMinDocs,
+		OversizedCollection: int64(l.OversizedCollectionGB * bytesPerGB),
+		LargeIndex:          int64(l.LargeIndexGB * bytesPerGB),
+		MaxIndexes:          l.MaxIndexes,
+		HighLatencyMillis:   l.HighLatencyMs,
+		StaleDataDays:       l.StaleDataDays,
+	}
+}
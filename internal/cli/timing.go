@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"io"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	"github.com/ppiankov/mongospectre/internal/reporter"
+)
+
+// phaseTimer records a command's phases as laps: each lap covers the time
+// since the previous one, so phases that were skipped cost nothing.
+type phaseTimer struct {
+	start, last time.Time
+	perf        reporter.Performance
+}
+
+func newPhaseTimer() *phaseTimer {
+	now := time.Now()
+	return &phaseTimer{start: now, last: now}
+}
+
+// lap ends the current phase and records it under name.
+func (t *phaseTimer) lap(name string) {
+	now := time.Now()
+	t.perf.Phases = append(t.perf.Phases, reporter.NewTiming(name, now.Sub(t.last)))
+	t.last = now
+}
+
+// detectors records the per-detector timings of an analyzer pipeline.
+func (t *phaseTimer) detectors(timings []analyzer.DetectorTiming) {
+	for _, d := range timings {
+		t.perf.Detectors = append(t.perf.Detectors, reporter.NewTiming(d.Name, d.Duration))
+	}
+}
+
+// finish returns the recorded performance, writing it to w with --verbose.
+func (t *phaseTimer) finish(w io.Writer) *reporter.Performance {
+	perf := t.perf
+	perf.TotalMS = reporter.NewTiming("", time.Since(t.start)).DurationMS
+	if verbose {
+		reporter.WritePerformance(w, &perf)
+	}
+	return &perf
+}
@@ -0,0 +1,19 @@
+package cli
+
+import "github.com/ppiankov/mongospectre/internal/analyzer"
+
+// lintConnectionURI lints the connection string. URI_NO_TLS is dropped when TLS is
+// enabled through --tls-* flags or config rather than the URI.
+func lintConnectionURI(rawURI string) []analyzer.Finding {
+	findings := analyzer.LintURI(rawURI)
+	if !tlsOpts.Enabled() {
+		return findings
+	}
+	kept := findings[:0]
+	for _, f := range findings {
+		if f.Type != analyzer.FindingURINoTLS {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/spf13/cobra"
+)
+
+// validateTrafficSample checks --traffic-sample fits inside --timeout, which
+// bounds the whole run including the sample window.
+func validateTrafficSample(window time.Duration) error {
+	if window < 0 {
+		return fmt.Errorf("--traffic-sample must not be negative")
+	}
+	if window > 0 && window >= timeout {
+		return fmt.Errorf("--traffic-sample (%s) must be shorter than --timeout (%s); raise --timeout", window, timeout)
+	}
+	return nil
+}
+
+// sampleTraffic watches change streams for --traffic-sample. Like
+// sampleOplog, it returns nil with a warning when sampling is unavailable,
+// for example on a standalone server.
+func sampleTraffic(ctx context.Context, cmd *cobra.Command, insp inspector, database string, window time.Duration) *mongoinspect.TrafficSample {
+	_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Sampling write traffic for %s...\n", window)
+	sample, err := insp.SampleTraffic(ctx, database, window)
+	if err != nil {
+		_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "warning: traffic sampling skipped: %v\n", err)
+		return nil
+	}
+	_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Sampled %d writes to %d collections\n", sample.Writes, len(sample.Namespaces))
+	return &sample
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	"github.com/ppiankov/mongospectre/internal/reporter"
+	"github.com/spf13/cobra"
+)
+
+func newTrendCmd() *cobra.Command {
+	var (
+		format            string
+		top               int
+		storageLimitGB    float64
+		collectionLimitGB float64
+		forecastDays      int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "trend [baseline-dir]",
+		Short: "Chart collection growth and finding counts across saved baselines",
+		Long: "Reads the JSON baselines in a directory (as written by audit --save-baseline) and reports\n" +
+			"per-collection data and index growth rates, finding counts over time, and collections whose\n" +
+			"growth is accelerating. With a storage limit set, forecasts when it will be reached.\n" +
+			"Defaults to baseline_dir from .mongospectre.yml.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateFormat(format, "text", "json"); err != nil {
+				return err
+			}
+			dir := cfg.BaselineDir
+			if len(args) == 1 {
+				dir = args[0]
+			}
+			if dir == "" {
+				return fmt.Errorf("baseline directory is required (argument or baseline_dir in .mongospectre.yml)")
+			}
+
+			paths, err := analyzer.ListBaselines(dir)
+			if err != nil {
+				return fmt.Errorf("list baselines: %w", err)
+			}
+			snaps, err := analyzer.LoadTrendSnapshots(paths)
+			if err != nil {
+				return fmt.Errorf("load baselines: %w", err)
+			}
+			if len(snaps) < 2 {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Hint: found %d timestamped baselines in %s; trends need at least 2 (3 for acceleration).\n", len(snaps), dir)
+			}
+
+			trend := analyzer.AnalyzeTrend(snaps)
+			findings := analyzer.DetectAcceleratingGrowth(&trend)
+
+			limits := storageLimits(0)
+			if cmd.Flags().Changed("storage-limit-gb") {
+				limits.Cluster = int64(storageLimitGB * bytesPerGB)
+			}
+			if cmd.Flags().Changed("collection-limit-gb") {
+				limits.Collection = int64(collectionLimitGB * bytesPerGB)
+			}
+			if cmd.Flags().Changed("forecast-days") {
+				limits.Horizon = time.Duration(forecastDays) * 24 * time.Hour
+			}
+			findings = append(findings, analyzer.ForecastStorage(&trend, limits)...)
+			report := reporter.TrendReport{
+				Metadata: reporter.Metadata{
+					Version:   version,
+					Command:   "trend",
+					Timestamp: time.Now().UTC().Format(time.RFC3339),
+				},
+				Trend:       trend,
+				Findings:    findings,
+				MaxSeverity: analyzer.MaxSeverity(findings),
+			}
+
+			if err := reporter.WriteTrend(cmd.OutOrStdout(), &report, reporter.Format(format), top); err != nil {
+				return fmt.Errorf("write trend: %w", err)
+			}
+
+			if code := analyzer.ExitCode(report.MaxSeverity); code != 0 {
+				return &ExitError{Code: code}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&format, "format", "f", "text", "output format: text or json")
+	cmd.Flags().IntVar(&top, "top", 20, "number of fastest-growing collections to chart in text output (0 for all)")
+	cmd.Flags().Float64Var(&storageLimitGB, "storage-limit-gb", 0, "cluster disk limit in GB for capacity forecast (config: thresholds.storage_limit_gb)")
+	cmd.Flags().Float64Var(&collectionLimitGB, "collection-limit-gb", 0, "per-collection data size limit in GB for capacity forecast (config: thresholds.collection_limit_gb)")
+	cmd.Flags().IntVar(&forecastDays, "forecast-days", 90, "only report limits reached within this many days (config: thresholds.forecast_days)")
+
+	return cmd
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
+	"github.com/ppiankov/mongospectre/internal/reporter"
+)
+
+func writeTrendBaselines(t *testing.T, dir string, sizes ...int64) {
+	t.Helper()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, size := range sizes {
+		report := reporter.NewReport(nil)
+		ts := start.Add(time.Duration(i) * 24 * time.Hour)
+		report.Metadata.Timestamp = ts.Format(time.RFC3339)
+		report.Collections = []mongoinspect.CollectionInfo{{Database: "app", Name: "events", Size: size}}
+		if _, _, err := reporter.SaveBaseline(dir, &report, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTrendAcceleratingGrowthExitCode(t *testing.T) {
+	dir := t.TempDir()
+	writeTrendBaselines(t, dir, 100<<20, 110<<20, 150<<20)
+
+	stdout, _, err := execCLI(t, "trend", dir)
+	requireExitCode(t, err, 1)
+	if !strings.Contains(stdout, "ACCELERATING_GROWTH") || !strings.Contains(stdout, "app.events") {
+		t.Fatalf("unexpected trend output: %q", stdout)
+	}
+}
+
+func TestTrendJSON(t *testing.T) {
+	dir := t.TempDir()
+	writeTrendBaselines(t, dir, 100<<20, 110<<20, 120<<20)
+
+	stdout, _, err := execCLI(t, "trend", dir, "--format", "json")
+	if err != nil {
+		t.Fatalf("trend returned error: %v", err)
+	}
+	var report reporter.TrendReport
+	if err := json.Unmarshal([]byte(stdout), &report); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if report.Trend.Snapshots != 3 || len(report.Trend.Collections) != 1 {
+		t.Fatalf("unexpected trend: %+v", report.Trend)
+	}
+	if report.MaxSeverity != analyzer.SeverityInfo {
+		t.Fatalf("max severity = %q, want info", report.MaxSeverity)
+	}
+}
+
+func TestTrendRequiresDirectory(t *testing.T) {
+	_, _, err := execCLI(t, "trend")
+	if err == nil || !strings.Contains(err.Error(), "baseline directory is required") {
+		t.Fatalf("expected directory error, got %v", err)
+	}
+}
+
+func TestTrendHintsWhenTooFewBaselines(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	stdout, stderr, err := execCLI(t, "trend", dir)
+	if err != nil {
+		t.Fatalf("trend returned error: %v", err)
+	}
+	if !strings.Contains(stderr, "found 0 timestamped baselines") || !strings.Contains(stdout, "No timestamped baselines") {
+		t.Fatalf("unexpected output: stdout=%q stderr=%q", stdout, stderr)
+	}
+}
+
+func TestTrendStorageForecast(t *testing.T) {
+	dir := t.TempDir()
+	writeTrendBaselines(t, dir, 900<<20, 1000<<20)
+
+	stdout, _, err := execCLI(t, "trend", dir, "--collection-limit-gb", "1", "--forecast-days", "30")
+	requireExitCode(t, err, 2)
+	if !strings.Contains(stdout, "STORAGE_FORECAST_EXCEEDED") || !strings.Contains(stdout, "(app.events)") {
+		t.Fatalf("expected forecast finding, got: %q", stdout)
+	}
+}
+
+func TestForecastFromHistoryUsesAtlasDiskSize(t *testing.T) {
+	prevCfg := cfg
+	t.Cleanup(func() { cfg = prevCfg })
+	cfg.Thresholds.StorageLimitGB = 0
+	cfg.Thresholds.ForecastDays = 90
+
+	dir := t.TempDir()
+	writeTrendBaselines(t, dir, 100<<20)
+
+	limits := storageLimits(2)
+	if limits.Cluster != 2<<30 {
+		t.Fatalf("cluster limit = %d, want Atlas disk size", limits.Cluster)
+	}
+	current := []mongoinspect.CollectionInfo{{Database: "app", Name: "events", StorageSize: 3 << 30}}
+	findings, err := forecastFromHistory(dir, current, limits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].Type != analyzer.FindingStorageForecast {
+		t.Fatalf("expected cluster forecast finding, got %+v", findings)
+	}
+}
@@ -4,15 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"sort"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/ppiankov/mongospectre/internal/analyzer"
+	"github.com/ppiankov/mongospectre/internal/i18n"
 	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
 	"github.com/ppiankov/mongospectre/internal/notify"
 	"github.com/ppiankov/mongospectre/internal/reporter"
+	"github.com/ppiankov/mongospectre/internal/server"
 	"github.com/spf13/cobra"
 )
 
@@ -20,11 +25,20 @@ func newWatchCmd() *cobra.Command {
 	var (
 		database      string
 		interval      time.Duration
+		cronExpr      string
+		jitter        time.Duration
 		format        string
 		exitOnNew     bool
 		noIgnore      bool
+		ignoreFile    string
 		notifyEnabled bool
 		notifyDryRun  bool
+		healthListen  string
+		staleAfter    time.Duration
+		sampleSize    int
+		sampleRefresh time.Duration
+		stateFile     string
+		silence       time.Duration
 	)
 
 	cmd := &cobra.Command{
@@ -32,6 +46,7 @@ func newWatchCmd() *cobra.Command {
 		Short: "Continuously monitor a MongoDB cluster and report drift",
 		Long:  "Runs audit on a configurable interval, compares each run against the previous, and prints only new/resolved findings.",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			database = profileDatabase(database)
 			if err := validateFormat(format, "text", "json"); err != nil {
 				return err
 			}
@@ -41,21 +56,46 @@ func newWatchCmd() *cobra.Command {
 			if notifyDryRun {
 				notifyEnabled = true
 			}
+			sched, err := resolveSchedule(cmd, interval, cronExpr, jitter)
+			if err != nil {
+				return err
+			}
+			if err := checkIgnoreFile(noIgnore, ignoreFile); err != nil {
+				return err
+			}
+
+			if silence > 0 && !notifyEnabled {
+				return fmt.Errorf("--silence requires --notify")
+			}
 
 			var notificationDispatcher watchNotifier
 			if notifyEnabled {
 				if len(cfg.Notifications) == 0 {
 					return fmt.Errorf("--notify enabled but no notifications are configured in .mongospectre.yml")
 				}
+				var silenceUntil time.Time
+				if silence > 0 {
+					silenceUntil = time.Now().Add(silence)
+				}
 				dispatcher, err := notify.NewDispatcher(cfg.Notifications, notify.DispatcherOptions{
-					Interval: interval,
-					DryRun:   notifyDryRun,
-					Writer:   cmd.ErrOrStderr(),
+					Interval:     interval,
+					DryRun:       notifyDryRun,
+					Writer:       cmd.ErrOrStderr(),
+					Cluster:      cluster,
+					Tags:         activeProfile.Tags,
+					StatePath:    stateFile,
+					QuietHours:   cfg.QuietHours,
+					SilenceUntil: silenceUntil,
+					Retries:      notifyRetries,
+					HistoryPath:  notificationHistoryPath(),
 				})
 				if err != nil {
 					return fmt.Errorf("notifications: %w", err)
 				}
 				notificationDispatcher = dispatcher
+				if silence > 0 {
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Notifications silenced until %s\n", silenceUntil.Format(time.RFC3339))
+				}
 			}
 
 			ctx, cancel := context.WithCancel(cmd.Context())
@@ -69,15 +109,39 @@ func newWatchCmd() *cobra.Command {
 				cancel()
 			}()
 
+			owners, err := configOwnerMap()
+			if err != nil {
+				return err
+			}
+			classification, err := configClassification()
+			if err != nil {
+				return err
+			}
 			w := &watcher{
-				uri:       uri,
-				database:  database,
-				interval:  interval,
-				format:    format,
-				exitOnNew: exitOnNew,
-				noIgnore:  noIgnore,
-				notifier:  notificationDispatcher,
-				cmd:       cmd,
+				uri:        uri,
+				database:   database,
+				schedule:   sched,
+				format:     format,
+				exitOnNew:  exitOnNew,
+				noIgnore:   noIgnore,
+				ignoreFile: ignoreFile,
+				notifier:   notificationDispatcher,
+				owners:     owners,
+				cmd:        cmd,
+			}
+			w.classification = classification
+			if sampleSize > 0 {
+				w.sampleSize = int64(sampleSize)
+				w.samples = newSampleCache(sampleRefresh)
+			}
+			if healthListen != "" {
+				w.health = server.New(0)
+				w.health.SetStaleAfter(resolveStaleAfter(staleAfter))
+				stop, err := startHealthServer(healthListen, w.health, cmd.ErrOrStderr())
+				if err != nil {
+					return err
+				}
+				defer stop()
 			}
 			return w.run(ctx)
 		},
@@ -85,28 +149,63 @@ func newWatchCmd() *cobra.Command {
 
 	cmd.Flags().StringVar(&database, "database", "", "specific database to watch (default: all non-system)")
 	cmd.Flags().DurationVar(&interval, "interval", 5*time.Minute, "time between audit runs")
+	cmd.Flags().StringVar(&cronExpr, "schedule", "", "cron expression for audit runs, e.g. \"0 3 * * *\" (overrides --interval)")
+	cmd.Flags().DurationVar(&jitter, "jitter", 0, "random delay of up to this duration added to each run")
 	cmd.Flags().StringVarP(&format, "format", "f", "text", "output format: text or json (NDJSON)")
 	cmd.Flags().BoolVar(&exitOnNew, "exit-on-new", false, "exit with code 2 on first new high-severity finding")
-	cmd.Flags().BoolVar(&noIgnore, "no-ignore", false, "bypass .mongospectreignore file")
+	cmd.Flags().BoolVar(&noIgnore, "no-ignore", false, "bypass ignore files")
+	cmd.Flags().StringVar(&ignoreFile, "ignore-file", "", "extra ignore file merged with .mongospectreignore and ~/.config/mongospectre/ignore")
 	cmd.Flags().BoolVar(&notifyEnabled, "notify", false, "send notifications for new/resolved findings from .mongospectre.yml")
 	cmd.Flags().BoolVar(&notifyDryRun, "notify-dry-run", false, "log notification payloads without sending (implies --notify)")
+	cmd.Flags().StringVar(&healthListen, "health-listen", "", "serve /healthz, /readyz and /lastrun on this address (e.g. :8081)")
+	cmd.Flags().DurationVar(&staleAfter, "stale-after", 0, "report unhealthy when a run takes or is overdue by longer than this (default: 2x --timeout + 1m)")
+	cmd.Flags().IntVar(&sampleSize, "sample-size", 0, "sample N documents per collection for schema anti-pattern and TTL checks (0 to disable)")
+	cmd.Flags().DurationVar(&silence, "silence", 0, "drop notifications for this long after start, e.g. 2h for planned maintenance")
+	cmd.Flags().StringVar(&stateFile, "state-file", "", "keep notification re-escalation state in this file across restarts (default: in memory)")
+	cmd.Flags().DurationVar(&sampleRefresh, "sample-refresh", defaultSampleRefresh, "resample a collection after this long even if its document count has not changed much")
 
 	return cmd
 }
 
 type watchNotifier interface {
 	Notify(ctx context.Context, events []notify.Event) error
+	// Reescalate repeats high findings still among the current ones.
+	Reescalate(ctx context.Context, current []analyzer.Finding) error
+	// Flush sends digests whose window has passed; Close sends the rest.
+	Flush(ctx context.Context) error
+	Close(ctx context.Context) error
+	// Stats counts delivery outcomes per channel for the shutdown summary.
+	Stats() []notify.DeliveryStats
 }
 
+// notifierCloseTimeout bounds sending queued digests on shutdown.
+const notifierCloseTimeout = 30 * time.Second
+
+// notifyRetries is how often watch retries a notification that failed
+// with a transient error.
+const notifyRetries = 2
+
 type watcher struct {
-	uri       string
-	database  string
-	interval  time.Duration
-	format    string
-	exitOnNew bool
-	noIgnore  bool
-	notifier  watchNotifier
-	cmd       *cobra.Command
+	uri        string
+	database   string
+	schedule   runSchedule
+	format     string
+	exitOnNew  bool
+	noIgnore   bool
+	ignoreFile string
+	notifier   watchNotifier
+	health     *server.Server // optional; receives run outcomes for health endpoints
+	owners     analyzer.OwnerMap
+	cmd        *cobra.Command
+
+	// classification tags sensitive fields: they must be encrypted and
+	// are redacted from findings and collection metadata.
+	classification analyzer.Classification
+
+	// sampleSize enables document sampling; samples carries field samples
+	// across runs so unchanged collections are not resampled.
+	sampleSize int64
+	samples    *sampleCache
 }
 
 // watchEvent is a single NDJSON event emitted in JSON format.
@@ -116,36 +215,46 @@ type watchEvent struct {
 	Findings  []analyzer.Finding         `json:"findings,omitempty"`
 	Diff      []analyzer.BaselineFinding `json:"diff,omitempty"`
 	Summary   watchSummary               `json:"summary"`
+	// Notifications counts delivery outcomes per channel on shutdown.
+	Notifications []notify.DeliveryStats `json:"notifications,omitempty"`
 }
 
 type watchSummary struct {
 	Total    int `json:"total"`
 	New      int `json:"new"`
 	Resolved int `json:"resolved"`
+	Changed  int `json:"changed,omitempty"`
 }
 
 func (w *watcher) run(ctx context.Context) error {
 	stderr := w.cmd.ErrOrStderr()
 	stdout := w.cmd.OutOrStdout()
 
-	_, _ = fmt.Fprintf(stderr, "Watch mode: auditing every %s\n", w.interval)
+	_, _ = fmt.Fprintf(stderr, "Watch mode: auditing %s\n", w.schedule)
 
 	var baseline []analyzer.Finding
 	runCount := 0
 	totalNew := 0
 	totalResolved := 0
+	totalChanged := 0
 
 	for {
+		started := time.Now()
+		if w.health != nil {
+			w.health.Started(started)
+		}
 		findings, err := w.runAudit(ctx)
 		if err != nil {
 			if ctx.Err() != nil {
 				break
 			}
+			w.recordHealth(started, nil, err)
 			_, _ = fmt.Fprintf(stderr, "[%s] audit error: %v\n", time.Now().UTC().Format(time.RFC3339), err)
 			goto wait
 		}
 
 		runCount++
+		w.recordHealth(started, findings, nil)
 
 		if baseline == nil {
 			// First run: print full results.
@@ -166,29 +275,32 @@ func (w *watcher) run(ctx context.Context) error {
 		} else {
 			// Subsequent runs: diff against baseline.
 			diff := analyzer.DiffBaseline(findings, baseline)
-			var newCount, resolvedCount int
+			var newCount, resolvedCount, changedCount int
 			for _, d := range diff {
 				switch d.Status {
 				case analyzer.StatusNew:
 					newCount++
 				case analyzer.StatusResolved:
 					resolvedCount++
+				case analyzer.StatusChanged:
+					changedCount++
 				}
 			}
 			totalNew += newCount
 			totalResolved += resolvedCount
+			totalChanged += changedCount
 
-			if newCount > 0 || resolvedCount > 0 {
+			if newCount > 0 || resolvedCount > 0 || changedCount > 0 {
 				if w.format == "json" {
 					w.emitJSON(stdout, &watchEvent{
 						Timestamp: time.Now().UTC().Format(time.RFC3339),
 						Type:      "diff",
 						Diff:      diff,
-						Summary:   watchSummary{Total: len(findings), New: newCount, Resolved: resolvedCount},
+						Summary:   watchSummary{Total: len(findings), New: newCount, Resolved: resolvedCount, Changed: changedCount},
 					})
 				} else {
 					_, _ = fmt.Fprintf(stdout, "[%s]\n", time.Now().UTC().Format(time.RFC3339))
-					reporter.WriteBaselineDiff(stdout, diff)
+					reporter.WriteBaselineDiff(stdout, diff, i18n.Lang(lang))
 				}
 
 				if w.notifier != nil {
@@ -206,6 +318,7 @@ func (w *watcher) run(ctx context.Context) error {
 					for _, d := range diff {
 						if d.Status == analyzer.StatusNew && d.Severity == analyzer.SeverityHigh {
 							_, _ = fmt.Fprintf(stderr, "New high-severity finding detected, exiting\n")
+							w.closeNotifier(ctx)
 							return &ExitError{Code: 2}
 						}
 					}
@@ -217,58 +330,194 @@ func (w *watcher) run(ctx context.Context) error {
 
 			baseline = findings
 		}
+		if w.notifier != nil {
+			if err := w.notifier.Reescalate(ctx, findings); err != nil {
+				_, _ = fmt.Fprintf(stderr, "[%s] notification error: %v\n",
+					time.Now().UTC().Format(time.RFC3339), err)
+			}
+			if err := w.notifier.Flush(ctx); err != nil {
+				_, _ = fmt.Fprintf(stderr, "[%s] notification error: %v\n",
+					time.Now().UTC().Format(time.RFC3339), err)
+			}
+		}
 
 	wait:
+		delay := w.nextDelay(started)
 		select {
 		case <-ctx.Done():
 			goto shutdown
-		case <-time.After(w.interval):
+		case <-time.After(delay):
 			continue
 		}
 	}
 
 shutdown:
-	_, _ = fmt.Fprintf(stderr, "\nWatch summary: %d runs, %d new findings, %d resolved\n",
-		runCount, totalNew, totalResolved)
+	w.closeNotifier(ctx)
+	_, _ = fmt.Fprintf(stderr, "\nWatch summary: %d runs, %d new findings, %d resolved, %d changed\n",
+		runCount, totalNew, totalResolved, totalChanged)
+	var stats []notify.DeliveryStats
+	if w.notifier != nil {
+		stats = w.notifier.Stats()
+		writeDeliveryStats(stderr, stats)
+	}
 	if w.format == "json" {
 		w.emitJSON(stdout, &watchEvent{
-			Timestamp: time.Now().UTC().Format(time.RFC3339),
-			Type:      "shutdown",
-			Summary:   watchSummary{Total: len(baseline), New: totalNew, Resolved: totalResolved},
+			Timestamp:     time.Now().UTC().Format(time.RFC3339),
+			Type:          "shutdown",
+			Summary:       watchSummary{Total: len(baseline), New: totalNew, Resolved: totalResolved, Changed: totalChanged},
+			Notifications: stats,
 		})
 	}
 	return nil
 }
 
+// writeDeliveryStats prints one line per notification channel, e.g.
+// "Notifications slack[0]: 3 sent, 1 failed (1 retries)".
+func writeDeliveryStats(w io.Writer, stats []notify.DeliveryStats) {
+	for _, s := range stats {
+		statuses := make([]string, 0, len(s.Counts))
+		for status := range s.Counts {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+		parts := make([]string, len(statuses))
+		for i, status := range statuses {
+			parts[i] = fmt.Sprintf("%d %s", s.Counts[status], strings.ReplaceAll(status, "_", " "))
+		}
+		line := fmt.Sprintf("Notifications %s: %s", s.Channel, strings.Join(parts, ", "))
+		if s.Retries > 0 {
+			line += fmt.Sprintf(" (%d retries)", s.Retries)
+		}
+		if s.LastError != "" {
+			line += "; last error: " + s.LastError
+		}
+		_, _ = fmt.Fprintln(w, line)
+	}
+}
+
+// closeNotifier sends queued digests before watch exits. The run context
+// may already be cancelled, so sending gets its own deadline.
+func (w *watcher) closeNotifier(ctx context.Context) {
+	if w.notifier == nil {
+		return
+	}
+	closeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), notifierCloseTimeout)
+	defer cancel()
+	if err := w.notifier.Close(closeCtx); err != nil {
+		_, _ = fmt.Fprintf(w.cmd.ErrOrStderr(), "[%s] notification error: %v\n",
+			time.Now().UTC().Format(time.RFC3339), err)
+	}
+}
+
+// nextDelay returns the wait before the next run and logs any scheduled
+// runs skipped because the previous audit was still in progress.
+func (w *watcher) nextDelay(started time.Time) time.Duration {
+	now := time.Now()
+	delay, skipped := w.schedule.nextWait(started, now)
+	if skipped > 0 {
+		_, _ = fmt.Fprintf(w.cmd.ErrOrStderr(), "[%s] audit overran its schedule, skipped %d run(s)\n",
+			now.UTC().Format(time.RFC3339), skipped)
+	}
+	if w.health != nil {
+		w.health.ScheduleNext(now.Add(delay))
+	}
+	return delay
+}
+
+// recordHealth reports a watch run to the health endpoints, if enabled.
+func (w *watcher) recordHealth(started time.Time, findings []analyzer.Finding, err error) {
+	if w.health == nil {
+		return
+	}
+	if err != nil {
+		w.health.Record(started, time.Since(started), nil, err)
+		return
+	}
+	report := reporter.NewReport(findings)
+	w.health.Record(started, time.Since(started), &report, nil)
+}
+
 func (w *watcher) runAudit(ctx context.Context) ([]analyzer.Finding, error) {
+	result, err := w.inspect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return result.findings, nil
+}
+
+// auditResult holds everything gathered by a single watch/serve audit run.
+type auditResult struct {
+	serverVersion string
+	collections   []mongoinspect.CollectionInfo
+	findings      []analyzer.Finding
+}
+
+// inspect connects, inspects the cluster, and runs cluster-only detections
+// with the ignore file applied. Each call uses a fresh connection bounded by
+// the global --timeout.
+func (w *watcher) inspect(ctx context.Context) (auditResult, error) {
 	auditCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
 	inspector, err := newInspector(auditCtx, mongoinspect.Config{
-		URI:      w.uri,
-		Database: w.database,
+		URI:             w.uri,
+		Database:        w.database,
+		TLS:             tlsOpts,
+		Concurrency:     concurrency,
+		DatabaseTimeout: dbTimeout,
+		Retries:         retries,
+		StrictReadOnly:  strictReadOnly,
+		OnCommand:       onCommand(),
+		CommandLog:      commandLogHook(),
 	})
 	if err != nil {
-		return nil, err
+		return auditResult{}, err
 	}
 	defer func() { _ = inspector.Close(auditCtx) }()
 
+	var result auditResult
+	if info, infoErr := inspector.GetServerVersion(auditCtx); infoErr == nil {
+		result.serverVersion = info.Version
+	}
+
 	collections, err := inspector.Inspect(auditCtx, w.database)
 	if err != nil {
-		return nil, fmt.Errorf("inspect: %w", err)
+		return auditResult{}, fmt.Errorf("inspect: %w", err)
 	}
 
 	findings := analyzer.Audit(collections)
+	findings = append(findings, analyzer.DetectUnencryptedSensitiveFields(w.classification, collections, nil)...)
 
-	if !w.noIgnore {
-		cwd, _ := os.Getwd()
-		il, ilErr := analyzer.LoadIgnoreFile(cwd)
-		if ilErr == nil {
-			findings, _ = il.Filter(findings)
+	if w.samples != nil {
+		samples, sampled, err := w.samples.samples(auditCtx, collections,
+			func(ctx context.Context, database, collection string) (mongoinspect.FieldSampleResult, error) {
+				return inspector.SampleCollection(ctx, database, collection, w.sampleSize)
+			})
+		if err != nil {
+			return auditResult{}, fmt.Errorf("sample documents: %w", err)
 		}
+		if verbose {
+			_, _ = fmt.Fprintf(w.cmd.ErrOrStderr(), "Sampled %d collections, %d from cache\n", sampled, len(samples)-sampled)
+		}
+		findings = append(findings, analyzer.DetectAntiPatterns(samples)...)
+		findings = append(findings, analyzer.DetectTTLFieldTypes(collections, samples)...)
+		findings = append(findings, analyzer.DetectStaleData(collections, samples)...)
+		findings = append(findings, analyzer.DetectArchiveCandidates(collections, samples)...)
+		collections = mergeDataAge(collections, samples)
+	}
+
+	if !w.noIgnore {
+		findings = applyIgnoreRules(w.cmd.ErrOrStderr(), w.ignoreFile, findings)
+	}
+	redactor := w.classification.Redactor(collections, nil)
+	for i := range findings {
+		w.owners.Assign(&findings[i])
+		redactor.Finding(&findings[i])
 	}
+	result.collections = redactor.Collections(collections)
+	result.findings = analyzer.AnnotateRules(findings)
 
-	return findings, nil
+	return result, nil
 }
 
 func (w *watcher) emitJSON(stdout interface{ Write([]byte) (int, error) }, event *watchEvent) {
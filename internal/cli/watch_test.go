@@ -3,6 +3,7 @@ package cli
 import (
 	"bytes"
 	"context"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -10,15 +11,19 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ppiankov/mongospectre/internal/analyzer"
 	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
 	"github.com/ppiankov/mongospectre/internal/notify"
+	"github.com/ppiankov/mongospectre/internal/server"
 	"github.com/spf13/cobra"
 )
 
 type fakeWatchNotifier struct {
-	mu     sync.Mutex
-	events []notify.Event
-	err    error
+	mu      sync.Mutex
+	events  []notify.Event
+	err     error
+	flushes int
+	closed  bool
 }
 
 func (f *fakeWatchNotifier) Notify(_ context.Context, events []notify.Event) error {
@@ -28,6 +33,28 @@ func (f *fakeWatchNotifier) Notify(_ context.Context, events []notify.Event) err
 	return f.err
 }
 
+func (f *fakeWatchNotifier) Reescalate(context.Context, []analyzer.Finding) error {
+	return nil
+}
+
+func (f *fakeWatchNotifier) Flush(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.flushes++
+	return nil
+}
+
+func (f *fakeWatchNotifier) Close(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	return nil
+}
+
+func (f *fakeWatchNotifier) Stats() []notify.DeliveryStats {
+	return nil
+}
+
 func TestWatcherRunExitOnNewHigh(t *testing.T) {
 	prevTimeout := timeout
 	t.Cleanup(func() { timeout = prevTimeout })
@@ -60,7 +87,7 @@ func TestWatcherRunExitOnNewHigh(t *testing.T) {
 
 	w := &watcher{
 		uri:       "mongodb://stub",
-		interval:  10 * time.Millisecond,
+		schedule:  runSchedule{interval: 10 * time.Millisecond},
 		format:    "text",
 		exitOnNew: true,
 		cmd:       cmd,
@@ -110,7 +137,7 @@ func TestWatcherRunJSONEmitsFullDiffShutdown(t *testing.T) {
 	cmd.SetErr(&stderr)
 	w := &watcher{
 		uri:      "mongodb://stub",
-		interval: 10 * time.Millisecond,
+		schedule: runSchedule{interval: 10 * time.Millisecond},
 		format:   "json",
 		cmd:      cmd,
 	}
@@ -167,7 +194,7 @@ func TestWatcherRunVerboseNoChanges(t *testing.T) {
 	cmd.SetErr(&stderr)
 	w := &watcher{
 		uri:      "mongodb://stub",
-		interval: 10 * time.Millisecond,
+		schedule: runSchedule{interval: 10 * time.Millisecond},
 		format:   "text",
 		cmd:      cmd,
 	}
@@ -234,6 +261,51 @@ func TestWatcherRunAuditRespectsIgnoreFile(t *testing.T) {
 	}
 }
 
+func TestWatcherRunAuditKeepsRulesWhenAFileFails(t *testing.T) {
+	prevTimeout := timeout
+	t.Cleanup(func() { timeout = prevTimeout })
+	timeout = time.Second
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ".mongospectreignore"), []byte("UNUSED_COLLECTION app.*\n"), 0o644); err != nil {
+		t.Fatalf("write ignore file: %v", err)
+	}
+	origDir, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	defer func() { _ = os.Chdir(origDir) }()
+
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		return &fakeInspector{inspectResult: []mongoinspect.CollectionInfo{
+			{Database: "app", Name: "empty", DocCount: 0, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+		}}, nil
+	})
+
+	// The explicit file disappeared after startup.
+	cmd := &cobra.Command{}
+	var stderr bytes.Buffer
+	cmd.SetErr(&stderr)
+	w := &watcher{uri: "mongodb://stub", database: "app", format: "text", ignoreFile: filepath.Join(dir, "gone"), cmd: cmd}
+	findings, err := w.runAudit(context.Background())
+	if err != nil {
+		t.Fatalf("runAudit returned error: %v", err)
+	}
+	if len(findings) != 0 {
+		t.Fatalf("repository rules should still apply, got %d findings", len(findings))
+	}
+	if !strings.Contains(stderr.String(), "warning: ignore file:") {
+		t.Errorf("expected an ignore file warning, got %q", stderr.String())
+	}
+}
+
+func TestWatchRejectsUnreadableIgnoreFile(t *testing.T) {
+	_, _, err := execCLI(t, "watch", "--uri", "mongodb://stub", "--ignore-file", filepath.Join(t.TempDir(), "missing"))
+	if err == nil || !strings.Contains(err.Error(), "ignore file") {
+		t.Fatalf("expected an ignore file error at startup, got %v", err)
+	}
+}
+
 func TestWatcherRunSendsNotificationsOnDiff(t *testing.T) {
 	prevTimeout := timeout
 	t.Cleanup(func() { timeout = prevTimeout })
@@ -269,7 +341,7 @@ func TestWatcherRunSendsNotificationsOnDiff(t *testing.T) {
 	cmd.SetErr(&bytes.Buffer{})
 	w := &watcher{
 		uri:      "mongodb://stub",
-		interval: 10 * time.Millisecond,
+		schedule: runSchedule{interval: 10 * time.Millisecond},
 		format:   "text",
 		notifier: fakeNotifier,
 		cmd:      cmd,
@@ -294,6 +366,10 @@ func TestWatcherRunSendsNotificationsOnDiff(t *testing.T) {
 	if !foundNewHigh {
 		t.Fatalf("expected at least one %s event, got: %+v", notify.EventNewHigh, fakeNotifier.events)
 	}
+	if fakeNotifier.flushes == 0 || !fakeNotifier.closed {
+		t.Fatalf("flushes = %d, closed = %v; want digests flushed each run and closed on shutdown",
+			fakeNotifier.flushes, fakeNotifier.closed)
+	}
 }
 
 func TestWatcherRunNotificationErrorIsNonFatal(t *testing.T) {
@@ -332,7 +408,7 @@ func TestWatcherRunNotificationErrorIsNonFatal(t *testing.T) {
 
 	w := &watcher{
 		uri:      "mongodb://stub",
-		interval: 10 * time.Millisecond,
+		schedule: runSchedule{interval: 10 * time.Millisecond},
 		format:   "text",
 		notifier: &fakeWatchNotifier{err: context.DeadlineExceeded},
 		cmd:      cmd,
@@ -345,3 +421,65 @@ func TestWatcherRunNotificationErrorIsNonFatal(t *testing.T) {
 		t.Fatalf("expected notification error log, got: %q", stderr.String())
 	}
 }
+
+func TestWatcherRunRecordsHealth(t *testing.T) {
+	prevTimeout := timeout
+	t.Cleanup(func() { timeout = prevTimeout })
+	timeout = time.Second
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fake := &fakeInspector{
+		inspectResult: []mongoinspect.CollectionInfo{
+			{Database: "app", Name: "orders", DocCount: 20000, Indexes: []mongoinspect.IndexInfo{{Name: "_id_"}}},
+		},
+	}
+	call := 0
+	stubNewInspector(t, func(context.Context, mongoinspect.Config) (inspector, error) {
+		call++
+		if call == 2 {
+			cancel()
+		}
+		return fake, nil
+	})
+
+	cmd := &cobra.Command{}
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	w := &watcher{
+		uri:      "mongodb://stub",
+		schedule: runSchedule{interval: 10 * time.Millisecond},
+		format:   "text",
+		health:   server.New(0),
+		cmd:      cmd,
+	}
+
+	if err := w.run(ctx); err != nil {
+		t.Fatalf("watch run returned error: %v", err)
+	}
+	status := w.health.Status()
+	if status.Run == nil || status.Run.Summary.Total == 0 {
+		t.Fatalf("expected recorded run with findings, got %+v", status)
+	}
+	if w.health.Latest() == nil {
+		t.Fatal("expected readiness report after successful run")
+	}
+}
+
+func TestStartHealthServer(t *testing.T) {
+	var stderr bytes.Buffer
+	stop, err := startHealthServer("127.0.0.1:0", server.New(0), &stderr)
+	if err != nil {
+		t.Fatalf("startHealthServer: %v", err)
+	}
+	defer stop()
+
+	addr := strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(stderr.String()), "Health endpoints at http://"), "/healthz")
+	resp, err := http.Get("http://" + addr + "/healthz")
+	if err != nil {
+		t.Fatalf("GET /healthz: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("healthz status = %d", resp.StatusCode)
+	}
+}
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"go.yaml.in/yaml/v3"
@@ -12,16 +15,193 @@ import (
 type Config struct {
 	URI           string         `yaml:"uri"`
 	Database      string         `yaml:"database"`
+	TLS           TLS            `yaml:"tls"`
+	Keyring       Keyring        `yaml:"keyring"`
 	Thresholds    Thresholds     `yaml:"thresholds"`
 	Exclude       Exclude        `yaml:"exclude"`
 	Defaults      Defaults       `yaml:"defaults"`
 	Notifications []Notification `yaml:"notifications"`
+	QuietHours    []QuietWindow  `yaml:"quiet_hours"`
+	Naming        Naming         `yaml:"naming"`
+	PII           PII            `yaml:"pii"`
+	Analyzer      Analyzer       `yaml:"analyzer"`
+
+	// Clusters are named connection profiles selected with --cluster.
+	Clusters map[string]Cluster `yaml:"clusters"`
+
+	// CollectionPatterns collapse per-tenant collections into one logical
+	// collection, e.g. "events_{tenant}" matches events_acme and events_globex.
+	CollectionPatterns []string `yaml:"collection_patterns"`
+
+	// Owners map database and collection glob patterns to the team that
+	// owns findings on them, for findings CODEOWNERS cannot place. The
+	// first matching rule wins.
+	Owners []Owner `yaml:"owners"`
+
+	// Classification tags collections and fields as sensitive. Tagged
+	// names and values are redacted from reports, and tagged fields
+	// must be encrypted.
+	Classification []Classification `yaml:"classification"`
+
+	// Retention sets how long documents may be kept per collection, for
+	// RETENTION_POLICY_UNENFORCED. The first matching rule wins.
+	Retention []Retention `yaml:"retention"`
+
+	// Schedule is a cron expression for watch/serve runs (e.g. "0 3 * * *").
+	Schedule string `yaml:"schedule"`
+	// ScheduleJitter is a random delay added to each scheduled run, parsed as time.Duration.
+	ScheduleJitter string `yaml:"schedule_jitter"`
+
+	// BaselineDir is where audit saves each report as the next baseline.
+	BaselineDir string `yaml:"baseline_dir"`
+	// BaselineKeep is how many baselines to retain in BaselineDir.
+	BaselineKeep int `yaml:"baseline_keep"`
+	// HistoryDir holds the notification delivery log written by watch.
+	HistoryDir string `yaml:"history_dir"`
+}
+
+// TLS configures client certificates and CA trust for connections, for
+// clusters that require mTLS. Paths are used as given.
+type TLS struct {
+	CAFile      string `yaml:"ca_file"`       // PEM CA bundle for verifying the server
+	CertKeyFile string `yaml:"cert_key_file"` // PEM client certificate and key
+	Insecure    bool   `yaml:"insecure"`      // skip server certificate verification
+}
+
+// Cluster is a named connection profile. An empty URI is looked up in the
+// keyring under the profile name. Tags (e.g. prod, staging, eu) are copied
+// into report metadata and notification payloads. Schedule and
+// ScheduleJitter override the top-level keys for watch/serve runs against
+// this cluster.
+type Cluster struct {
+	URI            string   `yaml:"uri"`
+	Database       string   `yaml:"database"`
+	AtlasProject   string   `yaml:"atlas_project"`
+	AtlasCluster   string   `yaml:"atlas_cluster"`
+	Tags           []string `yaml:"tags"`
+	Schedule       string   `yaml:"schedule"`
+	ScheduleJitter string   `yaml:"schedule_jitter"`
+}
+
+// Keyring selects where `mongospectre login` stores connection strings.
+type Keyring struct {
+	Backend string `yaml:"backend"` // auto (default), os, or file
+	File    string `yaml:"file"`    // encrypted file path for the file backend
 }
 
 // Thresholds control detection sensitivity.
 type Thresholds struct {
 	OversizedDocs  int64 `yaml:"oversized_docs"`   // doc count to flag as oversized
 	IndexUsageDays int   `yaml:"index_usage_days"` // days of zero ops to flag unused
+
+	// Capacity forecasting from saved baselines.
+	StorageLimitGB    float64 `yaml:"storage_limit_gb"`    // cluster disk limit (default: Atlas provisioned disk)
+	CollectionLimitGB float64 `yaml:"collection_limit_gb"` // per-collection data size limit
+	ForecastDays      int     `yaml:"forecast_days"`       // only flag limits reached within this many days
+}
+
+// Analyzer overrides built-in detector thresholds. Top-level values apply to
+// every database; Databases overrides them per database name. Zero values
+// inherit the next level.
+type Analyzer struct {
+	AnalyzerLimits `yaml:",inline"`
+	Databases      map[string]AnalyzerLimits `yaml:"databases"`
+}
+
+// AnalyzerLimits are the tunable detector thresholds.
+type AnalyzerLimits struct {
+	SuggestMinDocs        int64   `yaml:"suggest_min_docs"`        // skip index suggestions below this doc count
+	OversizedCollectionGB float64 `yaml:"oversized_collection_gb"` // storage size flagged as oversized
+	LargeIndexGB          float64 `yaml:"large_index_gb"`          // single index size flagged as large
+	MaxIndexes            int     `yaml:"max_indexes"`             // indexes per collection before over-indexing
+	HighLatencyMs         int64   `yaml:"high_latency_ms"`         // p95 collection latency flagged as high
+	StaleDataDays         int     `yaml:"stale_data_days"`         // newest sampled document age flagged as stale
+}
+
+// Validate rejects negative thresholds.
+func (a *Analyzer) Validate() error {
+	if err := a.AnalyzerLimits.validate(); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(a.Databases))
+	for name := range a.Databases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if name == "" {
+			return errors.New("databases: empty database name")
+		}
+		limits := a.Databases[name]
+		if err := limits.validate(); err != nil {
+			return fmt.Errorf("databases.%s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (l *AnalyzerLimits) validate() error {
+	switch {
+	case l.SuggestMinDocs < 0:
+		return fmt.Errorf("suggest_min_docs must not be negative, got %d", l.SuggestMinDocs)
+	case l.OversizedCollectionGB < 0:
+		return fmt.Errorf("oversized_collection_gb must not be negative, got %g", l.OversizedCollectionGB)
+	case l.LargeIndexGB < 0:
+		return fmt.Errorf("large_index_gb must not be negative, got %g", l.LargeIndexGB)
+	case l.MaxIndexes < 0:
+		return fmt.Errorf("max_indexes must not be negative, got %d", l.MaxIndexes)
+	case l.HighLatencyMs < 0:
+		return fmt.Errorf("high_latency_ms must not be negative, got %d", l.HighLatencyMs)
+	case l.StaleDataDays < 0:
+		return fmt.Errorf("stale_data_days must not be negative, got %d", l.StaleDataDays)
+	}
+	return nil
+}
+
+// PII configures the patterns audit --pii and check --pii match sampled
+// values against.
+type PII struct {
+	// Patterns are regular expressions by name. They are added to the
+	// built-in patterns (email, phone, card_number, us_ssn, uk_nino, iban);
+	// a built-in name replaces that pattern, and an empty value disables it.
+	Patterns map[string]string `yaml:"patterns"`
+}
+
+// Naming sets naming conventions checked by audit and check. Each value is a
+// built-in style (snake_case, camelCase, PascalCase, kebab-case, lowercase)
+// or a regular expression; empty disables the check.
+type Naming struct {
+	Collections string `yaml:"collections"`
+	Fields      string `yaml:"fields"`
+	Indexes     string `yaml:"indexes"`
+}
+
+// Owner assigns an owner, such as "@org/team-billing", to findings on
+// collections matching the database and collection glob patterns. Empty
+// patterns match anything.
+type Owner struct {
+	Database   string `yaml:"database"`
+	Collection string `yaml:"collection"`
+	Owner      string `yaml:"owner"`
+}
+
+// Classification tags Fields of collections matching the database and
+// collection glob patterns with Tag (pii, pci, ...). No fields tags the
+// whole collection; an empty tag means "sensitive".
+type Classification struct {
+	Database   string   `yaml:"database"`
+	Collection string   `yaml:"collection"`
+	Fields     []string `yaml:"fields"`
+	Tag        string   `yaml:"tag"`
+}
+
+// Retention limits how long documents in collections matching the database
+// and collection glob patterns may be kept. MaxAge is a number of days,
+// weeks or years (30d, 52w, 1y) or a Go duration.
+type Retention struct {
+	Database   string `yaml:"database"`
+	Collection string `yaml:"collection"`
+	MaxAge     string `yaml:"max_age"`
 }
 
 // Exclude lists collections and databases to skip.
@@ -35,21 +215,73 @@ type Defaults struct {
 	Format  string `yaml:"format"`
 	Verbose bool   `yaml:"verbose"`
 	Timeout string `yaml:"timeout"` // parsed as time.Duration
+	// Concurrency is how many databases are inspected at once.
+	Concurrency int `yaml:"concurrency"`
+	// DBTimeout bounds the work on each database, parsed as time.Duration.
+	DBTimeout string `yaml:"db_timeout"`
+	// Retries is how often reads are retried after transient errors.
+	Retries int `yaml:"retries"`
+	// Lang is the report language (en, de, ru, es).
+	Lang string `yaml:"lang"`
+	// StrictReadOnly refuses server commands outside the read-only
+	// allowlist.
+	StrictReadOnly bool `yaml:"strict_readonly"`
+}
+
+// QuietWindow is a period during which watch holds back notifications:
+// either a daily From-To range (To before From wraps past midnight),
+// optionally limited to Days, or a cron Schedule that starts a window of
+// Duration.
+type QuietWindow struct {
+	From     string   `yaml:"from"` // "22:00"
+	To       string   `yaml:"to"`   // "07:00"
+	Days     []string `yaml:"days"` // mon..sun the range starts on; empty means every day
+	Schedule string   `yaml:"schedule"`
+	Duration string   `yaml:"duration"` // parsed as time.Duration
+	Timezone string   `yaml:"timezone"` // IANA name; empty means local time
+	Action   string   `yaml:"action"`   // queue (default) sends held events when the window ends; drop discards them
 }
 
 // Notification configures outbound watch alerts.
 type Notification struct {
-	Type string   `yaml:"type"` // slack, webhook, email
+	Type string   `yaml:"type"` // slack, discord, telegram, opsgenie, webhook, email
 	On   []string `yaml:"on"`   // new_high, new_medium, new_low, resolved
+	// Digest batches events into one summary message per window, e.g. "15m".
+	Digest string `yaml:"digest"`
+	// ReescalateAfter repeats high findings still open after this long, e.g. "24h".
+	ReescalateAfter string `yaml:"reescalate_after"`
 
-	// Slack
+	// Routing: the channel only receives events that pass every filter set.
+	// Databases and collections are glob patterns such as "payments*".
+	Databases    []string `yaml:"databases"`
+	Collections  []string `yaml:"collections"`
+	MinSeverity  string   `yaml:"min_severity"` // info, low, medium, high
+	FindingTypes []string `yaml:"finding_types"`
+	// Owners limits the channel to findings owned by one of these teams
+	// (CODEOWNERS or the owners map), e.g. "@org/team-billing".
+	Owners []string `yaml:"owners"`
+
+	// Slack and Discord; dashboard_url is also linked from Telegram messages.
 	WebhookURL   string `yaml:"webhook_url"`
 	DashboardURL string `yaml:"dashboard_url"`
+	// Actions adds acknowledge and snooze buttons to Slack messages; the
+	// Slack app's interactivity URL must point at serve's /api/slack/actions.
+	Actions bool `yaml:"actions"`
+
+	// Telegram
+	BotToken string `yaml:"bot_token"`
+	ChatID   string `yaml:"chat_id"`
+
+	// Opsgenie; url overrides the API base, e.g. https://api.eu.opsgenie.com.
+	APIKey string `yaml:"api_key"`
 
-	// Generic webhook
-	URL     string            `yaml:"url"`
-	Method  string            `yaml:"method"`
-	Headers map[string]string `yaml:"headers"`
+	// Generic webhook. BodyTemplate is a Go text/template for the request
+	// body, replacing the default JSON payload; ContentType goes with it.
+	URL          string            `yaml:"url"`
+	Method       string            `yaml:"method"`
+	Headers      map[string]string `yaml:"headers"`
+	BodyTemplate string            `yaml:"body_template"`
+	ContentType  string            `yaml:"content_type"`
 
 	// Email (SMTP)
 	SMTPHost     string   `yaml:"smtp_host"`
@@ -59,6 +291,11 @@ type Notification struct {
 	From         string   `yaml:"from"`
 	To           []string `yaml:"to"`
 	Subject      string   `yaml:"subject"`
+	// SMTPTLS is starttls, tls (implicit, smtps) or none; empty uses
+	// STARTTLS when the server offers it.
+	SMTPTLS      string `yaml:"smtp_tls"`
+	SMTPCAFile   string `yaml:"smtp_ca_file"`  // PEM CA bundle for the server certificate
+	SMTPInsecure bool   `yaml:"smtp_insecure"` // skip certificate verification
 }
 
 // DefaultConfig returns the built-in defaults.
@@ -67,6 +304,7 @@ func DefaultConfig() Config {
 		Thresholds: Thresholds{
 			OversizedDocs:  1_000_000,
 			IndexUsageDays: 30,
+			ForecastDays:   90,
 		},
 		Defaults: Defaults{
 			Format:  "text",
@@ -94,12 +332,25 @@ func Load(dir string) (Config, error) {
 		if err := yaml.Unmarshal(data, &cfg); err != nil {
 			return cfg, err
 		}
+		if err := cfg.Analyzer.Validate(); err != nil {
+			return cfg, fmt.Errorf("%s: analyzer: %w", path, err)
+		}
 		return cfg, nil
 	}
 
 	return cfg, nil
 }
 
+// DBTimeoutDuration parses the Defaults.DBTimeout string as a
+// time.Duration. Returns 0 (no per-database limit) if unset or invalid.
+func (c *Config) DBTimeoutDuration() time.Duration {
+	d, err := time.ParseDuration(c.Defaults.DBTimeout)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
 // TimeoutDuration parses the Defaults.Timeout string as a time.Duration.
 // Returns 30s if parsing fails.
 func (c *Config) TimeoutDuration() time.Duration {
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -15,6 +16,9 @@ func TestDefaultConfig(t *testing.T) {
 	if cfg.Thresholds.IndexUsageDays != 30 {
 		t.Errorf("index_usage_days = %d, want 30", cfg.Thresholds.IndexUsageDays)
 	}
+	if cfg.Thresholds.ForecastDays != 90 {
+		t.Errorf("forecast_days = %d, want 90", cfg.Thresholds.ForecastDays)
+	}
 	if cfg.Defaults.Format != "text" {
 		t.Errorf("format = %s, want text", cfg.Defaults.Format)
 	}
@@ -68,6 +72,8 @@ notifications:
     from: alerts@example.com
     to: ["team@example.com"]
     on: [resolved]
+schedule: "0 3 * * *"
+schedule_jitter: 5m
 `
 	if err := os.WriteFile(filepath.Join(dir, ".mongospectre.yml"), []byte(content), 0644); err != nil {
 		t.Fatal(err)
@@ -117,6 +123,9 @@ notifications:
 	if cfg.Notifications[2].Type != "email" || len(cfg.Notifications[2].To) != 1 {
 		t.Errorf("unexpected email notification: %+v", cfg.Notifications[2])
 	}
+	if cfg.Schedule != "0 3 * * *" || cfg.ScheduleJitter != "5m" {
+		t.Errorf("schedule = %q, jitter = %q", cfg.Schedule, cfg.ScheduleJitter)
+	}
 }
 
 func TestLoad_InvalidYAML(t *testing.T) {
@@ -130,6 +139,119 @@ func TestLoad_InvalidYAML(t *testing.T) {
 	}
 }
 
+func TestLoad_AnalyzerThresholds(t *testing.T) {
+	dir := t.TempDir()
+	content := `
+analyzer:
+  suggest_min_docs: 5000
+  max_indexes: 12
+  databases:
+    analytics:
+      oversized_collection_gb: 50
+      large_index_gb: 4
+`
+	if err := os.WriteFile(filepath.Join(dir, ".mongospectre.yml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Analyzer.SuggestMinDocs != 5000 || cfg.Analyzer.MaxIndexes != 12 {
+		t.Errorf("analyzer = %+v", cfg.Analyzer.AnalyzerLimits)
+	}
+	db := cfg.Analyzer.Databases["analytics"]
+	if db.OversizedCollectionGB != 50 || db.LargeIndexGB != 4 {
+		t.Errorf("analytics = %+v", db)
+	}
+}
+
+func TestLoad_TLS(t *testing.T) {
+	dir := t.TempDir()
+	content := `
+tls:
+  ca_file: /etc/ssl/mongo-ca.pem
+  cert_key_file: /etc/ssl/client.pem
+`
+	if err := os.WriteFile(filepath.Join(dir, ".mongospectre.yml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.TLS.CAFile != "/etc/ssl/mongo-ca.pem" || cfg.TLS.CertKeyFile != "/etc/ssl/client.pem" || cfg.TLS.Insecure {
+		t.Errorf("tls = %+v", cfg.TLS)
+	}
+}
+
+func TestLoad_Clusters(t *testing.T) {
+	dir := t.TempDir()
+	content := `
+clusters:
+  prod-eu:
+    database: app
+    atlas_project: 5f1a
+    tags: [prod, eu]
+  staging:
+    uri: mongodb://staging:27017
+`
+	if err := os.WriteFile(filepath.Join(dir, ".mongospectre.yml"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prod := cfg.Clusters["prod-eu"]
+	if prod.URI != "" || prod.Database != "app" || prod.AtlasProject != "5f1a" || len(prod.Tags) != 2 {
+		t.Errorf("prod-eu = %+v", prod)
+	}
+	if cfg.Clusters["staging"].URI != "mongodb://staging:27017" {
+		t.Errorf("staging = %+v", cfg.Clusters["staging"])
+	}
+}
+
+func TestAnalyzerValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		a       Analyzer
+		wantErr string
+	}{
+		{"empty", Analyzer{}, ""},
+		{"negative max indexes", Analyzer{AnalyzerLimits: AnalyzerLimits{MaxIndexes: -1}}, "max_indexes"},
+		{"negative latency", Analyzer{AnalyzerLimits: AnalyzerLimits{HighLatencyMs: -1}}, "high_latency_ms"},
+		{"negative per-database", Analyzer{Databases: map[string]AnalyzerLimits{"app": {SuggestMinDocs: -5}}}, "databases.app: suggest_min_docs"},
+		{"empty database name", Analyzer{Databases: map[string]AnalyzerLimits{"": {}}}, "empty database name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.a.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDBTimeoutDuration(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want time.Duration
+	}{{"", 0}, {"5s", 5 * time.Second}, {"soon", 0}} {
+		cfg := Config{Defaults: Defaults{DBTimeout: tt.in}}
+		if got := cfg.DBTimeoutDuration(); got != tt.want {
+			t.Errorf("DBTimeoutDuration(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
 func TestTimeoutDuration(t *testing.T) {
 	tests := []struct {
 		timeout string
@@ -0,0 +1,291 @@
+// Package ftdc decodes MongoDB full-time diagnostic data capture files, the
+// metrics.* files mongod writes to its diagnostic.data directory once per
+// second without any client involvement.
+package ftdc
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// typeMetricChunk marks a document holding compressed samples. Types 0 and 2
+// carry metadata (host info, build info) and are skipped.
+const typeMetricChunk = 1
+
+// maxDocSize bounds one FTDC document; the server caps them well below the
+// 16 MB BSON limit.
+const maxDocSize = 16 << 20
+
+// maxChunkDeltas bounds the samples of one chunk after the reference
+// sample; the server writes at most 300 per chunk.
+const maxChunkDeltas = 1000
+
+// maxChunkValues bounds the decoded values of one chunk (metrics times
+// samples), 128 MB of int64s; real chunks hold a few million at most.
+const maxChunkValues = 16 << 20
+
+// Chunk is one compressed block of samples, typically five minutes at one
+// sample per second. Every sample in a chunk has the same metric layout.
+type Chunk struct {
+	// Keys are dotted metric paths in the order the server collected them,
+	// for example "serverStatus.wiredTiger.cache.maximum bytes configured".
+	// BSON timestamps expand to two metrics with ".t" and ".i" suffixes.
+	Keys    []string
+	Samples int
+
+	values [][]int64 // [metric][sample]
+	index  map[string]int
+}
+
+// Series returns the values of one metric across the chunk's samples, or nil
+// when the chunk does not contain it. Dates are milliseconds since the epoch,
+// booleans are 0 or 1 and doubles are truncated, as the server stores them.
+func (c *Chunk) Series(key string) []int64 {
+	i, ok := c.index[key]
+	if !ok {
+		return nil
+	}
+	return c.values[i]
+}
+
+// NewChunk builds a chunk from per-metric series of equal length.
+func NewChunk(keys []string, values [][]int64) *Chunk {
+	c := &Chunk{Keys: keys, values: values, index: make(map[string]int, len(keys))}
+	for i, k := range keys {
+		c.index[k] = i
+	}
+	if len(values) > 0 {
+		c.Samples = len(values[0])
+	}
+	return c
+}
+
+// ReadDir decodes the metrics.* files in a diagnostic.data directory in
+// chronological order and calls fn for each chunk. It returns the number of
+// files read.
+func ReadDir(dir string, fn func(*Chunk) error) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	// File names embed a sortable timestamp; metrics.interim holds the
+	// newest, not yet rotated samples and sorts last.
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), "metrics.") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := ReadFile(filepath.Join(dir, name), fn); err != nil {
+			return 0, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return len(names), nil
+}
+
+// ReadFile decodes one FTDC file and calls fn for each metric chunk.
+func ReadFile(path string, fn func(*Chunk) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	return Read(bufio.NewReader(f), fn)
+}
+
+// Read decodes a stream of FTDC documents and calls fn for each metric
+// chunk. A truncated final document, left behind when the server stopped
+// mid-write, ends the stream without an error.
+func Read(r io.Reader, fn func(*Chunk) error) error {
+	for {
+		doc, err := readDocument(r)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		typ, ok := doc.Lookup("type").AsInt64OK()
+		if !ok || typ != typeMetricChunk {
+			continue
+		}
+		_, data, ok := doc.Lookup("data").BinaryOK()
+		if !ok {
+			return fmt.Errorf("metric chunk without data")
+		}
+		chunk, err := decodeChunk(data)
+		if err != nil {
+			return err
+		}
+		if err := fn(chunk); err != nil {
+			return err
+		}
+	}
+}
+
+func readDocument(r io.Reader) (bson.Raw, error) {
+	var lenBuf [4]byte
+	if _, err := io.ReadFull(r, lenBuf[:]); err != nil {
+		return nil, err
+	}
+	size := int32(binary.LittleEndian.Uint32(lenBuf[:]))
+	if size < 5 || size > maxDocSize {
+		return nil, fmt.Errorf("invalid document size %d", size)
+	}
+	doc := make([]byte, size)
+	copy(doc, lenBuf[:])
+	if _, err := io.ReadFull(r, doc[4:]); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// decodeChunk expands a metric chunk: a little-endian uncompressed length
+// followed by a zlib stream holding the reference sample as BSON, the metric
+// and delta counts, and the deltas of each metric as varints with runs of
+// zeros encoded as a zero followed by the run length.
+func decodeChunk(data []byte) (*Chunk, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("metric chunk too short")
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(data[4:]))
+	if err != nil {
+		return nil, fmt.Errorf("decompress chunk: %w", err)
+	}
+	defer func() { _ = zr.Close() }()
+	br := bufio.NewReader(zr)
+
+	ref, err := readDocument(br)
+	if err != nil {
+		return nil, fmt.Errorf("reference sample: %w", err)
+	}
+	var keys []string
+	var refValues []int64
+	if err := extractMetrics(ref, "", &keys, &refValues); err != nil {
+		return nil, fmt.Errorf("reference sample: %w", err)
+	}
+
+	var counts [8]byte
+	if _, err := io.ReadFull(br, counts[:]); err != nil {
+		return nil, fmt.Errorf("chunk counts: %w", err)
+	}
+	metricCount := int(binary.LittleEndian.Uint32(counts[:4]))
+	deltaCount := int(binary.LittleEndian.Uint32(counts[4:]))
+	if metricCount != len(keys) {
+		return nil, fmt.Errorf("chunk declares %d metrics, reference sample has %d", metricCount, len(keys))
+	}
+	if deltaCount > maxChunkDeltas {
+		return nil, fmt.Errorf("chunk declares %d samples, more than the %d allowed", deltaCount, maxChunkDeltas)
+	}
+	if metricCount*(deltaCount+1) > maxChunkValues {
+		return nil, fmt.Errorf("chunk declares %d metrics of %d samples, more than the %d values allowed", metricCount, deltaCount+1, maxChunkValues)
+	}
+
+	values := make([][]int64, metricCount)
+	var zeros uint64
+	for m := range keys {
+		series := make([]int64, deltaCount+1)
+		series[0] = refValues[m]
+		for s := 1; s <= deltaCount; s++ {
+			var delta uint64
+			if zeros > 0 {
+				zeros--
+			} else {
+				if delta, err = binary.ReadUvarint(br); err != nil {
+					return nil, fmt.Errorf("metric %s: %w", keys[m], err)
+				}
+				if delta == 0 {
+					if zeros, err = binary.ReadUvarint(br); err != nil {
+						return nil, fmt.Errorf("metric %s: %w", keys[m], err)
+					}
+				}
+			}
+			series[s] = int64(uint64(series[s-1]) + delta)
+		}
+		values[m] = series
+	}
+	return NewChunk(keys, values), nil
+}
+
+// extractMetrics walks a sample depth-first and collects every numeric,
+// boolean, date and timestamp value, matching the server's metric order.
+func extractMetrics(doc bson.Raw, prefix string, keys *[]string, values *[]int64) error {
+	elems, err := doc.Elements()
+	if err != nil {
+		return err
+	}
+	for _, e := range elems {
+		key := e.Key()
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		v := e.Value()
+		switch v.Type {
+		case bson.TypeDouble:
+			*keys = append(*keys, key)
+			*values = append(*values, truncate(v.Double()))
+		case bson.TypeInt32:
+			*keys = append(*keys, key)
+			*values = append(*values, int64(v.Int32()))
+		case bson.TypeInt64:
+			*keys = append(*keys, key)
+			*values = append(*values, v.Int64())
+		case bson.TypeDecimal128:
+			f, _ := strconv.ParseFloat(v.Decimal128().String(), 64)
+			*keys = append(*keys, key)
+			*values = append(*values, truncate(f))
+		case bson.TypeBoolean:
+			var b int64
+			if v.Boolean() {
+				b = 1
+			}
+			*keys = append(*keys, key)
+			*values = append(*values, b)
+		case bson.TypeDateTime:
+			*keys = append(*keys, key)
+			*values = append(*values, v.DateTime())
+		case bson.TypeTimestamp:
+			t, i := v.Timestamp()
+			*keys = append(*keys, key+".t", key+".i")
+			*values = append(*values, int64(t), int64(i))
+		case bson.TypeEmbeddedDocument:
+			if err := extractMetrics(v.Document(), key, keys, values); err != nil {
+				return err
+			}
+		case bson.TypeArray:
+			if err := extractMetrics(bson.Raw(v.Array()), key, keys, values); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// truncate converts a double the way the server does when storing it as a
+// metric: toward zero, NaN as 0 and clamped to the int64 range.
+func truncate(f float64) int64 {
+	switch {
+	case math.IsNaN(f):
+		return 0
+	case f >= math.MaxInt64:
+		return math.MaxInt64
+	case f <= math.MinInt64:
+		return math.MinInt64
+	}
+	return int64(f)
+}
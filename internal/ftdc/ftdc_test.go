@@ -0,0 +1,237 @@
+package ftdc
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// encodeChunk builds an FTDC metric chunk document from samples that share
+// one layout, compressing the way the server does.
+func encodeChunk(t *testing.T, samples []bson.D) []byte {
+	t.Helper()
+	ref, err := bson.Marshal(samples[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var metrics [][]int64
+	var keys []string
+	for _, s := range samples {
+		raw, err := bson.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var values []int64
+		keys = keys[:0]
+		if err := extractMetrics(raw, "", &keys, &values); err != nil {
+			t.Fatal(err)
+		}
+		metrics = append(metrics, values)
+	}
+
+	var payload bytes.Buffer
+	payload.Write(ref)
+	_ = binary.Write(&payload, binary.LittleEndian, uint32(len(keys)))
+	_ = binary.Write(&payload, binary.LittleEndian, uint32(len(samples)-1))
+	var zeros uint64
+	flush := func() {
+		if zeros > 0 {
+			payload.Write(binary.AppendUvarint(nil, 0))
+			payload.Write(binary.AppendUvarint(nil, zeros-1))
+			zeros = 0
+		}
+	}
+	for m := range keys {
+		for s := 1; s < len(samples); s++ {
+			delta := uint64(metrics[s][m]) - uint64(metrics[s-1][m])
+			if delta == 0 {
+				zeros++
+				continue
+			}
+			flush()
+			payload.Write(binary.AppendUvarint(nil, delta))
+		}
+	}
+	flush()
+
+	var data bytes.Buffer
+	_ = binary.Write(&data, binary.LittleEndian, uint32(payload.Len()))
+	zw := zlib.NewWriter(&data)
+	_, _ = zw.Write(payload.Bytes())
+	_ = zw.Close()
+
+	doc, err := bson.Marshal(bson.D{
+		{Key: "_id", Value: bson.DateTime(0)},
+		{Key: "type", Value: int32(typeMetricChunk)},
+		{Key: "data", Value: bson.Binary{Data: data.Bytes()}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func sample(start time.Time, cacheBytes int64, dirty float64, busy bool) bson.D {
+	return bson.D{
+		{Key: "start", Value: bson.NewDateTimeFromTime(start)},
+		{Key: "serverStatus", Value: bson.D{
+			{Key: "host", Value: "db1"},
+			{Key: "wiredTiger", Value: bson.D{{Key: "cache", Value: bson.D{
+				{Key: "bytes currently in the cache", Value: cacheBytes},
+				{Key: "tracked dirty bytes in the cache", Value: dirty},
+			}}}},
+			{Key: "busy", Value: busy},
+			{Key: "opTime", Value: bson.Timestamp{T: 100, I: 3}},
+			{Key: "members", Value: bson.A{int32(1), int32(2)}},
+		}},
+	}
+}
+
+func TestReadDecodesSamples(t *testing.T) {
+	t0 := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	var samples []bson.D
+	for i := 0; i < 6; i++ {
+		cache := int64(1000)
+		if i >= 3 {
+			cache = 1000 - int64(i) // negative deltas wrap as uint64
+		}
+		samples = append(samples, sample(t0.Add(time.Duration(i)*time.Second), cache, 2.9, i == 5))
+	}
+	meta, _ := bson.Marshal(bson.D{{Key: "_id", Value: bson.DateTime(0)}, {Key: "type", Value: int32(0)}, {Key: "doc", Value: bson.D{{Key: "hostInfo", Value: "x"}}}})
+	stream := append(meta, encodeChunk(t, samples)...)
+	stream = append(stream, 0x40, 0x00) // truncated trailing document
+
+	var chunks []*Chunk
+	if err := Read(bytes.NewReader(stream), func(c *Chunk) error {
+		chunks = append(chunks, c)
+		return nil
+	}); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(chunks) != 1 {
+		t.Fatalf("chunks = %d, want 1", len(chunks))
+	}
+	c := chunks[0]
+	if c.Samples != 6 {
+		t.Fatalf("samples = %d, want 6", c.Samples)
+	}
+	wantKeys := []string{
+		"start",
+		"serverStatus.wiredTiger.cache.bytes currently in the cache",
+		"serverStatus.wiredTiger.cache.tracked dirty bytes in the cache",
+		"serverStatus.busy",
+		"serverStatus.opTime.t", "serverStatus.opTime.i",
+		"serverStatus.members.0", "serverStatus.members.1",
+	}
+	if len(c.Keys) != len(wantKeys) {
+		t.Fatalf("keys = %v, want %v", c.Keys, wantKeys)
+	}
+	for i, k := range wantKeys {
+		if c.Keys[i] != k {
+			t.Errorf("key %d = %q, want %q", i, c.Keys[i], k)
+		}
+	}
+
+	cache := c.Series("serverStatus.wiredTiger.cache.bytes currently in the cache")
+	want := []int64{1000, 1000, 1000, 997, 996, 995}
+	for i := range want {
+		if cache[i] != want[i] {
+			t.Fatalf("cache series = %v, want %v", cache, want)
+		}
+	}
+	if got := c.Series("serverStatus.wiredTiger.cache.tracked dirty bytes in the cache"); got[0] != 2 {
+		t.Errorf("double truncated to %d, want 2", got[0])
+	}
+	if got := c.Series("serverStatus.busy"); got[4] != 0 || got[5] != 1 {
+		t.Errorf("bool series = %v", got)
+	}
+	if got := c.Series("start"); got[5]-got[0] != 5000 {
+		t.Errorf("start series = %v, want 1s steps", got)
+	}
+	if c.Series("serverStatus.host") != nil {
+		t.Error("strings are not metrics")
+	}
+}
+
+func TestReadDirOrdersFiles(t *testing.T) {
+	dir := t.TempDir()
+	t0 := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	write := func(name string, at time.Time) {
+		chunk := encodeChunk(t, []bson.D{sample(at, 1, 0, false), sample(at.Add(time.Second), 1, 0, false)})
+		if err := os.WriteFile(filepath.Join(dir, name), chunk, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("metrics.interim", t0.Add(2*time.Hour))
+	write("metrics.2024-03-01T10-00-00Z-00000", t0)
+	write("metrics.2024-03-01T11-00-00Z-00000", t0.Add(time.Hour))
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("not ftdc"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var starts []int64
+	files, err := ReadDir(dir, func(c *Chunk) error {
+		starts = append(starts, c.Series("start")[0])
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if files != 3 || len(starts) != 3 {
+		t.Fatalf("files=%d chunks=%d, want 3 and 3", files, len(starts))
+	}
+	if starts[0] >= starts[1] || starts[1] >= starts[2] {
+		t.Errorf("chunks out of order: %v", starts)
+	}
+}
+
+func TestReadRejectsCorruptChunk(t *testing.T) {
+	doc, _ := bson.Marshal(bson.D{
+		{Key: "_id", Value: bson.DateTime(0)},
+		{Key: "type", Value: int32(typeMetricChunk)},
+		{Key: "data", Value: bson.Binary{Data: []byte{1, 0, 0, 0, 'x', 'y'}}},
+	})
+	err := Read(bytes.NewReader(doc), func(*Chunk) error { return nil })
+	if err == nil {
+		t.Fatal("expected error for corrupt chunk")
+	}
+}
+
+func TestDecodeChunkRejectsOversizedCounts(t *testing.T) {
+	chunk := func(metrics, deltas uint32) []byte {
+		fields := make(bson.D, metrics)
+		for i := range fields {
+			fields[i] = bson.E{Key: fmt.Sprintf("m%d", i), Value: int32(i)}
+		}
+		ref, err := bson.Marshal(fields)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var payload bytes.Buffer
+		payload.Write(ref)
+		_ = binary.Write(&payload, binary.LittleEndian, metrics)
+		_ = binary.Write(&payload, binary.LittleEndian, deltas)
+		var data bytes.Buffer
+		_ = binary.Write(&data, binary.LittleEndian, uint32(payload.Len()))
+		zw := zlib.NewWriter(&data)
+		_, _ = zw.Write(payload.Bytes())
+		_ = zw.Close()
+		return data.Bytes()
+	}
+	if _, err := decodeChunk(chunk(2, 1<<31)); err == nil {
+		t.Error("expected an error for a chunk declaring 2^31 samples")
+	}
+	if _, err := decodeChunk(chunk(2, maxChunkDeltas+1)); err == nil {
+		t.Error("expected an error for a chunk declaring too many samples")
+	}
+	if _, err := decodeChunk(chunk(20000, maxChunkDeltas)); err == nil {
+		t.Error("expected an error for a chunk declaring too many values")
+	}
+}
@@ -0,0 +1,130 @@
+package gitinfo
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// codeOwnersPaths are where GitHub looks for CODEOWNERS, in order; the
+// first one found is used.
+var codeOwnersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// CodeOwners maps repository paths to owners using CODEOWNERS rules.
+type CodeOwners struct {
+	// Path is the CODEOWNERS file the rules came from, relative to the repo.
+	Path  string
+	rules []ownerRule
+}
+
+type ownerRule struct {
+	re     *regexp.Regexp
+	owners []string
+}
+
+// LoadCodeOwners reads the CODEOWNERS file of repo. It returns nil when the
+// repo has none.
+func LoadCodeOwners(repo string) (*CodeOwners, error) {
+	for _, rel := range codeOwnersPaths {
+		f, err := os.Open(filepath.Join(repo, rel))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		co := &CodeOwners{Path: rel}
+		sc := bufio.NewScanner(f)
+		lineNo := 0
+		for sc.Scan() {
+			lineNo++
+			fields := strings.Fields(sc.Text())
+			if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+				continue
+			}
+			re, err := codeOwnersPattern(fields[0])
+			if err != nil {
+				_ = f.Close()
+				return nil, fmt.Errorf("%s:%d: %w", rel, lineNo, err)
+			}
+			var owners []string
+			for _, o := range fields[1:] {
+				if strings.HasPrefix(o, "#") {
+					break
+				}
+				owners = append(owners, o)
+			}
+			co.rules = append(co.rules, ownerRule{re: re, owners: owners})
+		}
+		err = sc.Err()
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", rel, err)
+		}
+		return co, nil
+	}
+	return nil, nil
+}
+
+// Owners returns the owners of file, a slash-separated path relative to
+// the repo. As on GitHub, the last matching rule wins; a rule without
+// owners leaves the file unowned.
+func (co *CodeOwners) Owners(file string) []string {
+	if co == nil {
+		return nil
+	}
+	file = strings.TrimPrefix(filepath.ToSlash(file), "/")
+	for i := len(co.rules) - 1; i >= 0; i-- {
+		if co.rules[i].re.MatchString(file) {
+			return co.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// codeOwnersPattern compiles a gitignore-style CODEOWNERS pattern. A
+// pattern without a slash, or with only a trailing one, matches at any
+// depth; otherwise it is anchored at the repo root. A match on a directory
+// covers everything below it, except that a trailing "/*" matches only the
+// files directly in that directory, as on GitHub.
+func codeOwnersPattern(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	p := strings.Trim(pattern, "/")
+	if p == "" || p == "*" || p == "**" {
+		return regexp.Compile(`^.*$`)
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(p); i++ {
+		switch c := p[i]; c {
+		case '*':
+			if strings.HasPrefix(p[i:], "**/") {
+				b.WriteString("(?:.*/)?")
+				i += 2
+			} else if strings.HasPrefix(p[i:], "**") {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if !strings.HasSuffix(pattern, "/*") {
+		b.WriteString("(?:/.*)?")
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
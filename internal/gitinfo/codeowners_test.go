@@ -0,0 +1,62 @@
+package gitinfo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCodeOwners(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".github"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	rules := `# Default owners
+*                 @org/platform
+*.py              @org/data
+/billing/         @org/billing @alice
+services/*/db.js  @org/dba
+docs/             # unowned
+vendor            @org/deps
+scripts/*         @org/ops
+`
+	if err := os.WriteFile(filepath.Join(dir, ".github", "CODEOWNERS"), []byte(rules), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	co, err := LoadCodeOwners(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if co.Path != ".github/CODEOWNERS" {
+		t.Errorf("path = %q", co.Path)
+	}
+
+	for file, want := range map[string]string{
+		"app.js":                 "@org/platform",
+		"jobs/etl.py":            "@org/data",
+		"billing/invoices.js":    "@org/billing @alice",
+		"billing/tasks/sync.py":  "@org/billing @alice",
+		"src/billing/x.js":       "@org/platform",
+		"services/users/db.js":   "@org/dba",
+		"services/users/a/db.js": "@org/platform",
+		"docs/guide.js":          "",
+		"lib/vendor/mongo.js":    "@org/deps",
+		"scripts/seed.js":        "@org/ops",
+		"scripts/db/migrate.js":  "@org/platform",
+	} {
+		if got := strings.Join(co.Owners(file), " "); got != want {
+			t.Errorf("Owners(%q) = %q, want %q", file, got, want)
+		}
+	}
+}
+
+func TestLoadCodeOwnersMissing(t *testing.T) {
+	co, err := LoadCodeOwners(t.TempDir())
+	if err != nil || co != nil {
+		t.Errorf("LoadCodeOwners = %v, %v; want nil, nil", co, err)
+	}
+	if got := co.Owners("app.js"); got != nil {
+		t.Errorf("nil CodeOwners owners = %v", got)
+	}
+}
@@ -0,0 +1,118 @@
+// Package gitinfo reads commit history, by running git(1), and CODEOWNERS
+// rules for files in a scanned repository.
+package gitinfo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// git runs git in dir and returns its stdout.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.String(), nil
+}
+
+// LastCommitTime returns the commit time of the newest commit touching
+// file, a path relative to repo. Files git does not track return the zero
+// time.
+func LastCommitTime(ctx context.Context, repo, file string) (time.Time, error) {
+	out, err := git(ctx, repo, "log", "-1", "--format=%ct", "--", file)
+	if err != nil {
+		return time.Time{}, err
+	}
+	out = strings.TrimSpace(out)
+	if out == "" {
+		return time.Time{}, nil
+	}
+	sec, err := strconv.ParseInt(out, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse commit time of %s: %w", file, err)
+	}
+	return time.Unix(sec, 0).UTC(), nil
+}
+
+// Blame is the commit that last changed a line.
+type Blame struct {
+	Commit  string    `json:"commit"`
+	Author  string    `json:"author"`
+	Email   string    `json:"email,omitempty"`
+	Date    time.Time `json:"date"`
+	Summary string    `json:"summary,omitempty"`
+}
+
+// notCommitted is the commit git blame reports for uncommitted lines.
+const notCommitted = "0000000000000000000000000000000000000000"
+
+// BlameLine returns the commit that last changed line (1-based) of file, a
+// path relative to repo. Lines not committed yet return ok false.
+func BlameLine(ctx context.Context, repo, file string, line int) (blame Blame, ok bool, err error) {
+	out, err := git(ctx, repo, "blame", "--porcelain", "-L", fmt.Sprintf("%d,%d", line, line), "--", file)
+	if err != nil {
+		return Blame{}, false, err
+	}
+	for i, l := range strings.Split(out, "\n") {
+		if i == 0 {
+			blame.Commit, _, _ = strings.Cut(l, " ")
+			continue
+		}
+		key, value, _ := strings.Cut(l, " ")
+		switch key {
+		case "author":
+			blame.Author = value
+		case "author-mail":
+			blame.Email = strings.Trim(value, "<>")
+		case "author-time":
+			if sec, err := strconv.ParseInt(value, 10, 64); err == nil {
+				blame.Date = time.Unix(sec, 0).UTC()
+			}
+		case "summary":
+			blame.Summary = value
+		}
+	}
+	if blame.Commit == "" {
+		return Blame{}, false, fmt.Errorf("parse blame of %s:%d: no commit", file, line)
+	}
+	if blame.Commit == notCommitted {
+		return Blame{}, false, nil
+	}
+	return blame, true, nil
+}
+
+// HooksDir returns the directory git runs hooks from for the repository
+// containing dir, honoring core.hooksPath.
+func HooksDir(ctx context.Context, dir string) (string, error) {
+	out, err := git(ctx, dir, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	hooks := strings.TrimSpace(out)
+	if !filepath.IsAbs(hooks) {
+		hooks = filepath.Join(dir, hooks)
+	}
+	return hooks, nil
+}
+
+// TopLevel returns the root of the work tree containing dir.
+func TopLevel(ctx context.Context, dir string) (string, error) {
+	out, err := git(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
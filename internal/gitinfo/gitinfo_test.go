@@ -0,0 +1,124 @@
+package gitinfo
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// initRepo creates a git repository in a temp dir with file committed at
+// the given time.
+func initRepo(t *testing.T, file string, at time.Time) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, file), []byte("db.users.find({})\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	date := at.Format(time.RFC3339)
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", file},
+		{"-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "-m", "init"},
+	} {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	return dir
+}
+
+func TestLastCommitTime(t *testing.T) {
+	at := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	dir := initRepo(t, "app.js", at)
+
+	got, err := LastCommitTime(context.Background(), dir, "app.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(at) {
+		t.Errorf("last commit = %v, want %v", got, at)
+	}
+
+	untracked, err := LastCommitTime(context.Background(), dir, "new.js")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !untracked.IsZero() {
+		t.Errorf("untracked file = %v, want zero time", untracked)
+	}
+}
+
+func TestLastCommitTimeNotARepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	if _, err := LastCommitTime(context.Background(), t.TempDir(), "app.js"); err == nil {
+		t.Fatal("expected an error outside a git repository")
+	}
+}
+
+func TestBlameLine(t *testing.T) {
+	at := time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+	dir := initRepo(t, "app.js", at)
+
+	blame, ok, err := BlameLine(context.Background(), dir, "app.js", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || len(blame.Commit) != 40 || blame.Author != "t" || blame.Email != "t@example.com" ||
+		!blame.Date.Equal(at) || blame.Summary != "init" {
+		t.Errorf("blame = %+v, ok = %v", blame, ok)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "app.js"), []byte("db.users.find({})\ndb.orders.find({})\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := BlameLine(context.Background(), dir, "app.js", 2); err != nil || ok {
+		t.Errorf("uncommitted line: ok = %v, err = %v", ok, err)
+	}
+}
+
+func TestHooksDirAndTopLevel(t *testing.T) {
+	dir := initRepo(t, "app.js", time.Now())
+	ctx := context.Background()
+	sub := filepath.Join(dir, "src")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	top, err := TopLevel(ctx, sub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := filepath.EvalSymlinks(dir)
+	if got, _ := filepath.EvalSymlinks(top); got != want {
+		t.Errorf("top level = %s, want %s", top, dir)
+	}
+
+	hooks, err := HooksDir(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hooks != filepath.Join(dir, ".git", "hooks") {
+		t.Errorf("hooks dir = %s", hooks)
+	}
+
+	if out, err := exec.Command("git", "-C", dir, "config", "core.hooksPath", ".githooks").CombinedOutput(); err != nil {
+		t.Fatalf("git config: %v: %s", err, out)
+	}
+	hooks, err = HooksDir(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hooks != filepath.Join(dir, ".githooks") {
+		t.Errorf("hooks dir with core.hooksPath = %s", hooks)
+	}
+}
@@ -0,0 +1,63 @@
+package i18n
+
+func init() {
+	register(German, map[string]string{
+		"HIGH":   "HOCH",
+		"MEDIUM": "MITTEL",
+		"LOW":    "NIEDRIG",
+		"INFO":   "INFO",
+
+		"No findings.": "Keine Befunde.",
+		"Summary: %d findings (high=%d medium=%d low=%d info=%d)": "Zusammenfassung: %d Befunde (hoch=%d mittel=%d niedrig=%d info=%d)",
+		"Rules:": "Regeln:",
+		"Partial results: %s, %d namespaces not inspected": "Unvollständige Ergebnisse: %s, %d Namespaces nicht geprüft",
+		"timeout reached":      "Zeitlimit erreicht",
+		"interrupted":          "abgebrochen",
+		"Collection patterns:": "Collection-Muster:",
+		"%s.%s: %d collections, %d docs, data %s, indexes %s":          "%s.%s: %d Collections, %d Dokumente, Daten %s, Indizes %s",
+		"Baseline diff: %d new, %d resolved, %d unchanged, %d changed": "Baseline-Vergleich: %d neu, %d behoben, %d unverändert, %d geändert",
+		"severity %s → %s": "Schweregrad %s → %s",
+		"was: %s":          "vorher: %s",
+
+		"%d findings":            "%d Befunde",
+		"%d finding":             "%d Befund",
+		"%d indexes":             "%d Indizes",
+		"%d index":               "%d Index",
+		"on %s":                  "– %s",
+		" across %d collections": " in %d Collections",
+		" in %s":                 " in %s",
+		"... and %d more (use --format json for all)": "... und %d weitere (alle mit --format json)",
+		"(index %s)":                       "(Index %s)",
+		"high=%d medium=%d low=%d info=%d": "hoch=%d mittel=%d niedrig=%d info=%d",
+		"(cluster)":                        "(Cluster)",
+	}, []message{
+		{From: "collection has 0 documents", To: "Collection enthält 0 Dokumente"},
+		{From: "index %q has never been used; hide it first with %s and drop it once nothing regresses",
+			To: "Index {1} wurde nie verwendet; zuerst mit {2} ausblenden und löschen, sobald nichts langsamer wird"},
+		{From: "index %q has been hidden with zero operations for %d days; it is safe to drop with %s (or restore with %s)",
+			To: "Index {1} ist seit {2} Tagen ausgeblendet und ohne Zugriffe; er kann mit {3} gelöscht werden (oder mit {4} wiederhergestellt)"},
+		{From: "collection has %d documents but only the _id index", To: "Collection enthält {1} Dokumente, aber nur den _id-Index"},
+		{From: "index %q is a prefix of %q", To: "Index {1} ist ein Präfix von {2}"},
+		{From: "collection storage is %.1f GB", To: "Collection belegt {1} GB Speicher"},
+		{From: "collection storage is %.1f GB and collection is not sharded", To: "Collection belegt {1} GB Speicher und ist nicht geshardet"},
+		{From: "field %q looks like a timestamp but has no TTL index", To: "Feld {1} sieht wie ein Zeitstempel aus, hat aber keinen TTL-Index"},
+		{From: "total index size (%.1f MB) exceeds data size (%.1f MB) — ratio %.1f:1",
+			To: "Gesamtgröße der Indizes ({1} MB) übersteigt die Datengröße ({2} MB) – Verhältnis {3}:1"},
+		{From: "collection has %d indexes — every write updates all of them", To: "Collection hat {1} Indizes – jeder Schreibvorgang aktualisiert alle"},
+		{From: "single-field index %q is covered by compound index %q", To: "Einzelfeld-Index {1} wird vom zusammengesetzten Index {2} abgedeckt"},
+		{From: "index %q is %.1f GB — review whether this index is necessary", To: "Index {1} ist {2} GB groß – prüfen, ob er nötig ist"},
+		{From: "collection %q referenced in code but does not exist in database", To: "Collection {1} wird im Code verwendet, existiert aber nicht in der Datenbank"},
+		{From: "collection %q exists in database with 0 documents and is not referenced in code",
+			To: "Collection {1} existiert mit 0 Dokumenten in der Datenbank und wird im Code nicht verwendet"},
+		{From: "index %q on unreferenced collection %q has 0 operations", To: "Index {1} auf der nicht verwendeten Collection {2} hat 0 Zugriffe"},
+		{From: "collection %q exists in database and is referenced in code", To: "Collection {1} existiert in der Datenbank und wird im Code verwendet"},
+		{From: "field %q is queried in code but has no covering index", To: "Feld {1} wird im Code abgefragt, hat aber keinen passenden Index"},
+		{From: "consider adding an index on field %q (collection has %d documents)", To: "Ein Index auf Feld {1} wäre sinnvoll (Collection enthält {2} Dokumente)"},
+		{From: "authentication is disabled — anyone can connect without credentials", To: "Authentifizierung ist deaktiviert – jeder kann sich ohne Zugangsdaten verbinden"},
+		{From: "TLS is not configured — network traffic is unencrypted", To: "TLS ist nicht konfiguriert – der Netzwerkverkehr ist unverschlüsselt"},
+		{From: "audit logging is not configured — no trail of administrative actions", To: "Audit-Logging ist nicht konfiguriert – administrative Aktionen werden nicht protokolliert"},
+		{From: "chunk balancer is disabled", To: "Chunk-Balancer ist deaktiviert"},
+		{From: "URI does not enable TLS — add ?tls=true for encrypted connections", To: "URI aktiviert kein TLS – ?tls=true für verschlüsselte Verbindungen ergänzen"},
+		{From: "URI does not set retryWrites=true — older drivers default to false", To: "URI setzt retryWrites=true nicht – ältere Treiber verwenden standardmäßig false"},
+	})
+}
@@ -0,0 +1,63 @@
+package i18n
+
+func init() {
+	register(Spanish, map[string]string{
+		"HIGH":   "ALTO",
+		"MEDIUM": "MEDIO",
+		"LOW":    "BAJO",
+		"INFO":   "INFO",
+
+		"No findings.": "Sin hallazgos.",
+		"Summary: %d findings (high=%d medium=%d low=%d info=%d)": "Resumen: %d hallazgos (alto=%d medio=%d bajo=%d info=%d)",
+		"Rules:": "Reglas:",
+		"Partial results: %s, %d namespaces not inspected": "Resultados parciales: %s, %d espacios de nombres sin inspeccionar",
+		"timeout reached":      "tiempo de espera agotado",
+		"interrupted":          "interrumpido",
+		"Collection patterns:": "Patrones de colecciones:",
+		"%s.%s: %d collections, %d docs, data %s, indexes %s":          "%s.%s: %d colecciones, %d documentos, datos %s, índices %s",
+		"Baseline diff: %d new, %d resolved, %d unchanged, %d changed": "Diferencias con la línea base: %d nuevos, %d resueltos, %d sin cambios, %d cambiados",
+		"severity %s → %s": "severidad %s → %s",
+		"was: %s":          "antes: %s",
+
+		"%d findings":            "%d hallazgos",
+		"%d finding":             "%d hallazgo",
+		"%d indexes":             "%d índices",
+		"%d index":               "%d índice",
+		"on %s":                  "en %s",
+		" across %d collections": " en %d colecciones",
+		" in %s":                 " en %s",
+		"... and %d more (use --format json for all)": "... y %d más (use --format json para verlos todos)",
+		"(index %s)":                       "(índice %s)",
+		"high=%d medium=%d low=%d info=%d": "alto=%d medio=%d bajo=%d info=%d",
+		"(cluster)":                        "(clúster)",
+	}, []message{
+		{From: "collection has 0 documents", To: "la colección tiene 0 documentos"},
+		{From: "index %q has never been used; hide it first with %s and drop it once nothing regresses",
+			To: "el índice {1} nunca se ha usado; ocúltelo primero con {2} y elimínelo si nada empeora"},
+		{From: "index %q has been hidden with zero operations for %d days; it is safe to drop with %s (or restore with %s)",
+			To: "el índice {1} lleva {2} días oculto y sin operaciones; puede eliminarse con {3} (o restaurarse con {4})"},
+		{From: "collection has %d documents but only the _id index", To: "la colección tiene {1} documentos pero solo el índice _id"},
+		{From: "index %q is a prefix of %q", To: "el índice {1} es un prefijo de {2}"},
+		{From: "collection storage is %.1f GB", To: "la colección ocupa {1} GB"},
+		{From: "collection storage is %.1f GB and collection is not sharded", To: "la colección ocupa {1} GB y no está fragmentada (sharded)"},
+		{From: "field %q looks like a timestamp but has no TTL index", To: "el campo {1} parece una marca de tiempo pero no tiene índice TTL"},
+		{From: "total index size (%.1f MB) exceeds data size (%.1f MB) — ratio %.1f:1",
+			To: "el tamaño total de los índices ({1} MB) supera el de los datos ({2} MB) — proporción {3}:1"},
+		{From: "collection has %d indexes — every write updates all of them", To: "la colección tiene {1} índices — cada escritura los actualiza todos"},
+		{From: "single-field index %q is covered by compound index %q", To: "el índice de un campo {1} está cubierto por el índice compuesto {2}"},
+		{From: "index %q is %.1f GB — review whether this index is necessary", To: "el índice {1} ocupa {2} GB — revise si es necesario"},
+		{From: "collection %q referenced in code but does not exist in database", To: "la colección {1} se usa en el código pero no existe en la base de datos"},
+		{From: "collection %q exists in database with 0 documents and is not referenced in code",
+			To: "la colección {1} existe en la base de datos con 0 documentos y no se usa en el código"},
+		{From: "index %q on unreferenced collection %q has 0 operations", To: "el índice {1} de la colección sin uso {2} tiene 0 operaciones"},
+		{From: "collection %q exists in database and is referenced in code", To: "la colección {1} existe en la base de datos y se usa en el código"},
+		{From: "field %q is queried in code but has no covering index", To: "el campo {1} se consulta en el código pero no tiene un índice que lo cubra"},
+		{From: "consider adding an index on field %q (collection has %d documents)", To: "considere añadir un índice sobre el campo {1} (la colección tiene {2} documentos)"},
+		{From: "authentication is disabled — anyone can connect without credentials", To: "la autenticación está desactivada — cualquiera puede conectarse sin credenciales"},
+		{From: "TLS is not configured — network traffic is unencrypted", To: "TLS no está configurado — el tráfico de red no está cifrado"},
+		{From: "audit logging is not configured — no trail of administrative actions", To: "el registro de auditoría no está configurado — no queda rastro de las acciones administrativas"},
+		{From: "chunk balancer is disabled", To: "el balanceador de chunks está desactivado"},
+		{From: "URI does not enable TLS — add ?tls=true for encrypted connections", To: "la URI no activa TLS — añada ?tls=true para cifrar las conexiones"},
+		{From: "URI does not set retryWrites=true — older drivers default to false", To: "la URI no establece retryWrites=true — los drivers antiguos usan false por defecto"},
+	})
+}
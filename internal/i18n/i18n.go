@@ -0,0 +1,132 @@
+// Package i18n translates report text. English is the source language:
+// catalog keys are the English strings, and anything without a
+// translation is shown in English. Finding messages are translated by
+// matching the rendered English text against the catalog's templates,
+// which cover only the core findings.
+package i18n
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Lang is a supported report language.
+type Lang string
+
+const (
+	English Lang = "en"
+	German  Lang = "de"
+	Russian Lang = "ru"
+	Spanish Lang = "es"
+)
+
+// Supported lists the report languages.
+func Supported() []Lang {
+	return []Lang{English, German, Russian, Spanish}
+}
+
+// Parse resolves a --lang value. Region suffixes are ignored ("de-AT",
+// "es_MX"); an empty value is English.
+func Parse(s string) (Lang, error) {
+	base := strings.ToLower(s)
+	if i := strings.IndexAny(base, "-_."); i >= 0 {
+		base = base[:i]
+	}
+	if base == "" {
+		return English, nil
+	}
+	for _, l := range Supported() {
+		if Lang(base) == l {
+			return l, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported language %q (supported: en, de, ru, es)", s)
+}
+
+// catalog holds one language's translations.
+type catalog struct {
+	text     map[string]string // fixed strings and format strings
+	messages []message         // finding message templates
+}
+
+// message translates a finding message. From is the analyzer's English
+// format string; To places the captured arguments with {1}, {2}, ...
+type message struct {
+	From string
+	To   string
+	re   *regexp.Regexp
+}
+
+var catalogs = map[Lang]*catalog{}
+
+func register(l Lang, text map[string]string, messages []message) {
+	for i := range messages {
+		messages[i].re = templatePattern(messages[i].From)
+	}
+	catalogs[l] = &catalog{text: text, messages: messages}
+}
+
+// T returns the translation of a fixed string.
+func (l Lang) T(s string) string {
+	if c := catalogs[l]; c != nil {
+		if t, ok := c.text[s]; ok {
+			return t
+		}
+	}
+	return s
+}
+
+// Sprintf formats the translation of an English format string. The
+// translation must use the same verbs in the same order.
+func (l Lang) Sprintf(format string, args ...interface{}) string {
+	return fmt.Sprintf(l.T(format), args...)
+}
+
+// Message translates a rendered finding message by matching it against
+// the English templates. Unmatched messages are returned unchanged.
+func (l Lang) Message(msg string) string {
+	c := catalogs[l]
+	if c == nil {
+		return msg
+	}
+	for i := range c.messages {
+		m := c.messages[i].re.FindStringSubmatch(msg)
+		if m == nil {
+			continue
+		}
+		out := c.messages[i].To
+		for n := len(m) - 1; n >= 1; n-- {
+			out = strings.ReplaceAll(out, fmt.Sprintf("{%d}", n), m[n])
+		}
+		return out
+	}
+	return msg
+}
+
+var verbPattern = regexp.MustCompile(`%(?:\.\d+)?[dsqvf]`)
+
+// templatePattern turns an English format string into a regexp that
+// captures each verb's rendered value.
+func templatePattern(format string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	last := 0
+	for _, loc := range verbPattern.FindAllStringIndex(format, -1) {
+		b.WriteString(regexp.QuoteMeta(format[last:loc[0]]))
+		switch format[loc[1]-1] {
+		case 'd':
+			b.WriteString(`(-?\d+)`)
+		case 'f':
+			b.WriteString(`(-?[\d.]+)`)
+		case 'q':
+			b.WriteString(`("(?:[^"\\]|\\.)*")`)
+		default:
+			b.WriteString(`(.+?)`)
+		}
+		last = loc[1]
+	}
+	b.WriteString(regexp.QuoteMeta(format[last:]))
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
@@ -0,0 +1,126 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Lang
+	}{
+		{"", English},
+		{"en", English},
+		{"DE", German},
+		{"de-AT", German},
+		{"es_MX", Spanish},
+		{"ru_RU.UTF-8", Russian},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+	if _, err := Parse("fr"); err == nil || !strings.Contains(err.Error(), "supported: en, de, ru, es") {
+		t.Errorf("Parse(fr) error = %v", err)
+	}
+}
+
+func TestMessage(t *testing.T) {
+	tests := []struct {
+		lang Lang
+		in   string
+		want string
+	}{
+		{German, `index "status_1" is a prefix of "status_1_created_1"`, `Index "status_1" ist ein Präfix von "status_1_created_1"`},
+		{Russian, "collection has 120000 documents but only the _id index", "в коллекции 120000 документов, но есть только индекс _id"},
+		{Spanish, "total index size (12.5 MB) exceeds data size (3.1 MB) — ratio 4.0:1",
+			"el tamaño total de los índices (12.5 MB) supera el de los datos (3.1 MB) — proporción 4.0:1"},
+		{German, "collection storage is 61.2 GB and collection is not sharded", "Collection belegt 61.2 GB Speicher und ist nicht geshardet"},
+		{German, "something nobody translated", "something nobody translated"},
+		{English, "collection has 0 documents", "collection has 0 documents"},
+	}
+	for _, tt := range tests {
+		if got := tt.lang.Message(tt.in); got != tt.want {
+			t.Errorf("%s.Message(%q) = %q, want %q", tt.lang, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSprintfFallsBackToEnglish(t *testing.T) {
+	if got := German.Sprintf("%d untranslated", 3); got != "3 untranslated" {
+		t.Errorf("got %q", got)
+	}
+	if got := Lang("").T("No findings."); got != "No findings." {
+		t.Errorf("got %q", got)
+	}
+}
+
+// TestCatalogsComplete checks every language translates the same strings
+// with the same verbs and placeholders.
+func TestCatalogsComplete(t *testing.T) {
+	ref := catalogs[German]
+	for _, l := range []Lang{Russian, Spanish} {
+		c := catalogs[l]
+		for key := range ref.text {
+			tr, ok := c.text[key]
+			if !ok {
+				t.Errorf("%s: missing %q", l, key)
+				continue
+			}
+			if verbs(key) != verbs(tr) {
+				t.Errorf("%s: %q translation %q uses different verbs", l, key, tr)
+			}
+		}
+		if len(c.messages) != len(ref.messages) {
+			t.Errorf("%s: %d message templates, want %d", l, len(c.messages), len(ref.messages))
+		}
+	}
+	for l, c := range catalogs {
+		for _, m := range c.messages {
+			n := len(verbPattern.FindAllString(m.From, -1))
+			for i := 1; i <= n; i++ {
+				if !strings.Contains(m.To, "{"+strconv.Itoa(i)+"}") {
+					t.Errorf("%s: %q translation drops argument {%d}", l, m.From, i)
+				}
+			}
+		}
+	}
+}
+
+// TestTemplatesMatchAnalyzer guards against message drift: every template
+// must still be a format string the analyzer uses.
+func TestTemplatesMatchAnalyzer(t *testing.T) {
+	files, err := filepath.Glob("../analyzer/*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var src strings.Builder
+	for _, f := range files {
+		if strings.HasSuffix(f, "_test.go") {
+			continue
+		}
+		data, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		src.Write(data)
+	}
+	for _, m := range catalogs[German].messages {
+		if !strings.Contains(src.String(), strconv.Quote(m.From)) {
+			t.Errorf("template %q is not a message in internal/analyzer", m.From)
+		}
+	}
+}
+
+func verbs(s string) string {
+	return strings.Join(verbPattern.FindAllString(s, -1), " ")
+}
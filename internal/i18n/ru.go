@@ -0,0 +1,63 @@
+package i18n
+
+func init() {
+	register(Russian, map[string]string{
+		"HIGH":   "ВЫСОКИЙ",
+		"MEDIUM": "СРЕДНИЙ",
+		"LOW":    "НИЗКИЙ",
+		"INFO":   "ИНФО",
+
+		"No findings.": "Проблем не найдено.",
+		"Summary: %d findings (high=%d medium=%d low=%d info=%d)": "Итого находок: %d (высокий=%d средний=%d низкий=%d инфо=%d)",
+		"Rules:": "Правила:",
+		"Partial results: %s, %d namespaces not inspected": "Неполные результаты: %s, не проверено пространств имён: %d",
+		"timeout reached":      "истекло время ожидания",
+		"interrupted":          "прервано",
+		"Collection patterns:": "Шаблоны коллекций:",
+		"%s.%s: %d collections, %d docs, data %s, indexes %s":          "%s.%s: коллекций: %d, документов: %d, данные %s, индексы %s",
+		"Baseline diff: %d new, %d resolved, %d unchanged, %d changed": "Сравнение с базовым отчётом: новых %d, устранено %d, без изменений %d, изменилось %d",
+		"severity %s → %s": "важность %s → %s",
+		"was: %s":          "было: %s",
+
+		"%d findings":            "находок: %d",
+		"%d finding":             "находок: %d",
+		"%d indexes":             "индексов: %d",
+		"%d index":               "индексов: %d",
+		"on %s":                  "— %s",
+		" across %d collections": ", коллекций: %d",
+		" in %s":                 " в %s",
+		"... and %d more (use --format json for all)": "... и ещё %d (полный список — с --format json)",
+		"(index %s)":                       "(индекс %s)",
+		"high=%d medium=%d low=%d info=%d": "высокий=%d средний=%d низкий=%d инфо=%d",
+		"(cluster)":                        "(кластер)",
+	}, []message{
+		{From: "collection has 0 documents", To: "коллекция не содержит документов"},
+		{From: "index %q has never been used; hide it first with %s and drop it once nothing regresses",
+			To: "индекс {1} ни разу не использовался; сначала скройте его командой {2} и удалите, если ничего не замедлится"},
+		{From: "index %q has been hidden with zero operations for %d days; it is safe to drop with %s (or restore with %s)",
+			To: "индекс {1} скрыт и не используется уже {2} дн.; его можно удалить командой {3} (или вернуть командой {4})"},
+		{From: "collection has %d documents but only the _id index", To: "в коллекции {1} документов, но есть только индекс _id"},
+		{From: "index %q is a prefix of %q", To: "индекс {1} является префиксом индекса {2}"},
+		{From: "collection storage is %.1f GB", To: "коллекция занимает {1} ГБ"},
+		{From: "collection storage is %.1f GB and collection is not sharded", To: "коллекция занимает {1} ГБ и не шардирована"},
+		{From: "field %q looks like a timestamp but has no TTL index", To: "поле {1} похоже на метку времени, но TTL-индекса нет"},
+		{From: "total index size (%.1f MB) exceeds data size (%.1f MB) — ratio %.1f:1",
+			To: "общий размер индексов ({1} МБ) превышает объём данных ({2} МБ) — соотношение {3}:1"},
+		{From: "collection has %d indexes — every write updates all of them", To: "у коллекции индексов: {1} — каждая запись обновляет их все"},
+		{From: "single-field index %q is covered by compound index %q", To: "одиночный индекс {1} покрывается составным индексом {2}"},
+		{From: "index %q is %.1f GB — review whether this index is necessary", To: "индекс {1} занимает {2} ГБ — проверьте, нужен ли он"},
+		{From: "collection %q referenced in code but does not exist in database", To: "коллекция {1} используется в коде, но отсутствует в базе данных"},
+		{From: "collection %q exists in database with 0 documents and is not referenced in code",
+			To: "коллекция {1} есть в базе данных, но пуста и не используется в коде"},
+		{From: "index %q on unreferenced collection %q has 0 operations", To: "индекс {1} на неиспользуемой коллекции {2} не имеет обращений"},
+		{From: "collection %q exists in database and is referenced in code", To: "коллекция {1} есть в базе данных и используется в коде"},
+		{From: "field %q is queried in code but has no covering index", To: "поле {1} запрашивается в коде, но подходящего индекса нет"},
+		{From: "consider adding an index on field %q (collection has %d documents)", To: "стоит добавить индекс по полю {1} (в коллекции {2} документов)"},
+		{From: "authentication is disabled — anyone can connect without credentials", To: "аутентификация отключена — подключиться может любой без учётных данных"},
+		{From: "TLS is not configured — network traffic is unencrypted", To: "TLS не настроен — сетевой трафик не шифруется"},
+		{From: "audit logging is not configured — no trail of administrative actions", To: "журнал аудита не настроен — административные действия не отслеживаются"},
+		{From: "chunk balancer is disabled", To: "балансировщик чанков отключён"},
+		{From: "URI does not enable TLS — add ?tls=true for encrypted connections", To: "в URI не включён TLS — добавьте ?tls=true для шифрования соединений"},
+		{From: "URI does not set retryWrites=true — older drivers default to false", To: "в URI не задан retryWrites=true — старые драйверы по умолчанию используют false"},
+	})
+}
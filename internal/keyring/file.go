@@ -0,0 +1,147 @@
+package keyring
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// pbkdf2Iterations follows the OWASP recommendation for PBKDF2-HMAC-SHA256.
+// Tests lower it.
+var pbkdf2Iterations = 600_000
+
+// fileStore keeps all aliases in one AES-256-GCM encrypted JSON file. The
+// key is derived from the passphrase with a fresh salt on every write.
+type fileStore struct {
+	path       string
+	passphrase string
+}
+
+// encryptedFile is the on-disk format.
+type encryptedFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+func (s *fileStore) Backend() string { return "encrypted file " + s.path }
+
+func (s *fileStore) Get(alias string) (string, error) {
+	entries, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	secret, ok := entries[alias]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+func (s *fileStore) Set(alias, secret string) error {
+	entries, err := s.load()
+	if err != nil {
+		return err
+	}
+	entries[alias] = secret
+	return s.save(entries)
+}
+
+func (s *fileStore) Delete(alias string) error {
+	entries, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := entries[alias]; !ok {
+		return ErrNotFound
+	}
+	delete(entries, alias)
+	return s.save(entries)
+}
+
+func (s *fileStore) load() (map[string]string, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]string), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", s.path, err)
+	}
+	var ef encryptedFile
+	if err := json.Unmarshal(data, &ef); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", s.path, err)
+	}
+	if ef.Version != 1 {
+		return nil, fmt.Errorf("%s: unsupported version %d", s.path, ef.Version)
+	}
+	gcm, err := s.cipher(ef.Salt)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, ef.Nonce, ef.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: wrong passphrase or corrupted file", s.path)
+	}
+	entries := make(map[string]string)
+	if err := json.Unmarshal(plain, &entries); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.path, err)
+	}
+	return entries, nil
+}
+
+func (s *fileStore) save(entries map[string]string) error {
+	plain, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	ef := encryptedFile{Version: 1, Salt: make([]byte, 16)}
+	if _, err := rand.Read(ef.Salt); err != nil {
+		return err
+	}
+	gcm, err := s.cipher(ef.Salt)
+	if err != nil {
+		return err
+	}
+	ef.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(ef.Nonce); err != nil {
+		return err
+	}
+	ef.Data = gcm.Seal(nil, ef.Nonce, plain, nil)
+	data, err := json.Marshal(ef)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Dir(s.path), err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("write %s: %w", s.path, err)
+	}
+	return nil
+}
+
+func (s *fileStore) cipher(salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, s.passphrase, salt, pbkdf2Iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,84 @@
+// Package keyring stores MongoDB connection strings under an alias, either in
+// the OS credential store or in a passphrase-encrypted file, so URIs with
+// passwords stay out of shell history and config files.
+package keyring
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// service is the service name entries are stored under in the OS store.
+const service = "mongospectre"
+
+// Backend names accepted by Open.
+const (
+	BackendAuto = "auto" // OS store when available, else encrypted file
+	BackendOS   = "os"   // macOS Keychain or Secret Service (secret-tool)
+	BackendFile = "file" // AES-GCM encrypted file
+)
+
+// ErrNotFound is returned by Get and Delete for an unknown alias.
+var ErrNotFound = errors.New("alias not found")
+
+// Store saves secrets by alias.
+type Store interface {
+	Get(alias string) (string, error)
+	Set(alias, secret string) error
+	Delete(alias string) error
+	// Backend describes where secrets are kept, for messages.
+	Backend() string
+}
+
+// Options selects and configures a Store.
+type Options struct {
+	Backend    string // BackendAuto when empty
+	File       string // encrypted file path; default ~/.mongospectre/credentials
+	Passphrase string // required by the file backend
+}
+
+// Open returns the Store selected by opts.
+func Open(opts Options) (Store, error) {
+	switch opts.Backend {
+	case "", BackendAuto:
+		if s, ok := newOSStore(); ok && s.probe() == nil {
+			return s, nil
+		}
+		return openFile(opts)
+	case BackendOS:
+		s, ok := newOSStore()
+		if !ok {
+			return nil, errors.New("no OS credential store found (need macOS security or secret-tool)")
+		}
+		if err := s.probe(); err != nil {
+			return nil, err
+		}
+		return s, nil
+	case BackendFile:
+		return openFile(opts)
+	default:
+		return nil, fmt.Errorf("unknown keyring backend %q (want auto, os or file)", opts.Backend)
+	}
+}
+
+func openFile(opts Options) (Store, error) {
+	if opts.Passphrase == "" {
+		return nil, errors.New("encrypted file keyring needs a passphrase: set MONGOSPECTRE_KEYRING_PASSPHRASE")
+	}
+	path := opts.File
+	if path == "" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("home directory: %w", err)
+		}
+		if path == "" {
+			path = filepath.Join(home, ".mongospectre", "credentials")
+		} else {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	return &fileStore{path: path, passphrase: opts.Passphrase}, nil
+}
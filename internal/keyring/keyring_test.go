@@ -0,0 +1,206 @@
+package keyring
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func init() {
+	pbkdf2Iterations = 1000
+}
+
+func TestFileStore_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "credentials")
+	s, err := Open(Options{Backend: BackendFile, File: path, Passphrase: "correct horse"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := "mongodb://app:" + "hunt" + "er2@db:27017/app"
+	if err := s.Set("prod", secret); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("staging", "mongodb://staging:27017"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.Get("prod")
+	if err != nil || got != secret {
+		t.Fatalf("Get(prod) = %q, %v", got, err)
+	}
+	if _, err := s.Get("dev"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(dev) error = %v, want ErrNotFound", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "hunter2") || strings.Contains(string(data), "staging") {
+		t.Error("credentials file contains plaintext")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("file mode = %o, want 600", perm)
+	}
+
+	if err := s.Delete("prod"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get("prod"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete error = %v, want ErrNotFound", err)
+	}
+	if err := s.Delete("prod"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestFileStore_WrongPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	s, _ := Open(Options{Backend: BackendFile, File: path, Passphrase: "one"})
+	if err := s.Set("prod", "mongodb://db"); err != nil {
+		t.Fatal(err)
+	}
+	other, _ := Open(Options{Backend: BackendFile, File: path, Passphrase: "two"})
+	if _, err := other.Get("prod"); err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+		t.Fatalf("expected wrong passphrase error, got %v", err)
+	}
+}
+
+func TestOpen_Errors(t *testing.T) {
+	if _, err := Open(Options{Backend: BackendFile}); err == nil || !strings.Contains(err.Error(), "MONGOSPECTRE_KEYRING_PASSPHRASE") {
+		t.Errorf("file backend without passphrase: %v", err)
+	}
+	if _, err := Open(Options{Backend: "vault"}); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+}
+
+func TestOpen_FileExpandsHome(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	s, err := Open(Options{Backend: BackendFile, File: "~/creds", Passphrase: "p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "encrypted file " + filepath.Join(home, "creds"); s.Backend() != want {
+		t.Errorf("backend = %q, want %q", s.Backend(), want)
+	}
+}
+
+func TestOpen_AutoFallsBackToFile(t *testing.T) {
+	orig := lookPath
+	lookPath = func(string) (string, error) { return "", exec.ErrNotFound }
+	t.Cleanup(func() { lookPath = orig })
+
+	s, err := Open(Options{File: filepath.Join(t.TempDir(), "c"), Passphrase: "p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(s.Backend(), "encrypted file") {
+		t.Errorf("backend = %q, want encrypted file", s.Backend())
+	}
+	if _, err := Open(Options{Backend: BackendOS}); err == nil {
+		t.Error("expected error when no OS store is available")
+	}
+}
+
+type call struct {
+	stdin string
+	args  string
+}
+
+func TestOSStore_SecretTool(t *testing.T) {
+	var calls []call
+	stored := ""
+	s := &osStore{goos: "linux", run: func(stdin, name string, args ...string) (string, error) {
+		calls = append(calls, call{stdin, name + " " + strings.Join(args, " ")})
+		switch args[0] {
+		case "store":
+			stored = stdin
+		case "lookup":
+			if stored == "" {
+				return "", errors.New("exit status 1")
+			}
+			return stored + "\n", nil
+		}
+		return "", nil
+	}}
+
+	secret := "mongodb://app:" + "hunt" + "er2@db/app"
+	if err := s.Set("prod", secret); err != nil {
+		t.Fatal(err)
+	}
+	if calls[0].stdin != secret || strings.Contains(calls[0].args, secret) {
+		t.Errorf("secret must be passed on stdin only: %+v", calls[0])
+	}
+	if calls[0].args != "secret-tool store --label mongospectre prod service mongospectre account prod" {
+		t.Errorf("unexpected store args %q", calls[0].args)
+	}
+	got, err := s.Get("prod")
+	if err != nil || got != secret {
+		t.Fatalf("Get = %q, %v", got, err)
+	}
+}
+
+func TestOSStore_SecurityQuotesStdin(t *testing.T) {
+	var stdin, args string
+	s := &osStore{goos: "darwin", run: func(in, name string, a ...string) (string, error) {
+		stdin, args = in, name+" "+strings.Join(a, " ")
+		return "", nil
+	}}
+	if err := s.Set("prod", `pa"ss\word`); err != nil {
+		t.Fatal(err)
+	}
+	if args != "security -i" {
+		t.Errorf("args = %q", args)
+	}
+	want := `add-generic-password -U -s "mongospectre" -a "prod" -w "pa\"ss\\word"` + "\n"
+	if stdin != want {
+		t.Errorf("stdin = %q, want %q", stdin, want)
+	}
+}
+
+func TestOpen_AutoFallsBackWithoutSecretService(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("the probe only applies to secret-tool")
+	}
+	origLook, origRun := lookPath, osRunner
+	lookPath = func(string) (string, error) { return "/usr/bin/secret-tool", nil }
+	t.Cleanup(func() { lookPath, osRunner = origLook, origRun })
+
+	// secret-tool is installed, but no Secret Service answers on D-Bus.
+	osRunner = func(string, string, ...string) (string, error) {
+		return "", &commandError{name: "secret-tool", err: errors.New("exit status 1"),
+			stderr: "Cannot autolaunch D-Bus without X11 $DISPLAY"}
+	}
+	s, err := Open(Options{File: filepath.Join(t.TempDir(), "c"), Passphrase: "p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(s.Backend(), "encrypted file") {
+		t.Errorf("backend = %q, want encrypted file", s.Backend())
+	}
+	if _, err := Open(Options{Backend: BackendOS}); err == nil || !strings.Contains(err.Error(), "secret service unavailable") {
+		t.Errorf("expected an unavailable error for the os backend, got %v", err)
+	}
+
+	// A running service reports a missing probe item without a message.
+	osRunner = func(string, string, ...string) (string, error) {
+		return "", &commandError{name: "secret-tool", err: errors.New("exit status 1")}
+	}
+	s, err = Open(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Backend() != "Secret Service keyring" {
+		t.Errorf("backend = %q, want Secret Service keyring", s.Backend())
+	}
+}
@@ -0,0 +1,155 @@
+package keyring
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// runner executes a command with stdin and returns its stdout. It is
+// replaced in tests.
+type runner func(stdin, name string, args ...string) (string, error)
+
+// commandError is a failed command with what it wrote to stderr.
+type commandError struct {
+	name   string
+	err    error
+	stderr string
+}
+
+func (e *commandError) Error() string {
+	if e.stderr != "" {
+		return fmt.Sprintf("%s: %v: %s", e.name, e.err, e.stderr)
+	}
+	return fmt.Sprintf("%s: %v", e.name, e.err)
+}
+
+func (e *commandError) Unwrap() error { return e.err }
+
+func execRunner(stdin, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return stdout.String(), &commandError{name: name, err: err, stderr: strings.TrimSpace(stderr.String())}
+	}
+	return stdout.String(), nil
+}
+
+// lookPath and osRunner are exec.LookPath and execRunner, replaced in tests.
+var (
+	lookPath        = exec.LookPath
+	osRunner runner = execRunner
+)
+
+// probeAccount is looked up to check that the OS store answers.
+const probeAccount = "mongospectre-probe"
+
+// osStore keeps secrets in the macOS Keychain through security(1), or in the
+// freedesktop Secret Service (GNOME Keyring, KWallet) through secret-tool(1).
+// Secrets are passed on stdin, never as arguments visible in ps.
+type osStore struct {
+	goos string
+	run  runner
+}
+
+func newOSStore() (*osStore, bool) {
+	s := &osStore{goos: runtime.GOOS, run: osRunner}
+	if _, err := lookPath(s.tool()); err != nil {
+		return nil, false
+	}
+	return s, true
+}
+
+// probe checks that the store answers. secret-tool is often installed on
+// headless and SSH sessions where no Secret Service runs, and every call
+// then fails with a D-Bus error. A lookup of a missing item fails too, but
+// without a message.
+func (s *osStore) probe() error {
+	if s.goos == "darwin" {
+		return nil
+	}
+	_, err := s.run("", "secret-tool", "lookup", "service", service, "account", probeAccount)
+	var cmdErr *commandError
+	if err != nil && errors.As(err, &cmdErr) && cmdErr.stderr != "" {
+		return fmt.Errorf("secret service unavailable: %w", err)
+	}
+	return nil
+}
+
+func (s *osStore) tool() string {
+	if s.goos == "darwin" {
+		return "security"
+	}
+	return "secret-tool"
+}
+
+func (s *osStore) Backend() string {
+	if s.goos == "darwin" {
+		return "macOS Keychain"
+	}
+	return "Secret Service keyring"
+}
+
+func (s *osStore) Get(alias string) (string, error) {
+	var out string
+	var err error
+	if s.goos == "darwin" {
+		out, err = s.run("", "security", "find-generic-password", "-s", service, "-a", alias, "-w")
+		if err != nil && strings.Contains(err.Error(), "could not be found") {
+			return "", ErrNotFound
+		}
+	} else {
+		// secret-tool exits 1 with no output for a missing item.
+		out, err = s.run("", "secret-tool", "lookup", "service", service, "account", alias)
+		if err != nil && out == "" {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+				return "", ErrNotFound
+			}
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	secret := strings.TrimRight(out, "\r\n")
+	if secret == "" {
+		return "", ErrNotFound
+	}
+	return secret, nil
+}
+
+func (s *osStore) Set(alias, secret string) error {
+	if s.goos == "darwin" {
+		// security -i reads commands from stdin, keeping the secret out of argv.
+		cmd := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", quoteSecurity(service), quoteSecurity(alias), quoteSecurity(secret))
+		_, err := s.run(cmd, "security", "-i")
+		return err
+	}
+	_, err := s.run(secret, "secret-tool", "store", "--label", service+" "+alias, "service", service, "account", alias)
+	return err
+}
+
+func (s *osStore) Delete(alias string) error {
+	if _, err := s.Get(alias); err != nil {
+		return err
+	}
+	var err error
+	if s.goos == "darwin" {
+		_, err = s.run("", "security", "delete-generic-password", "-s", service, "-a", alias)
+	} else {
+		_, err = s.run("", "secret-tool", "clear", "service", service, "account", alias)
+	}
+	return err
+}
+
+// quoteSecurity quotes an argument for security(1) interactive mode.
+func quoteSecurity(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + r.Replace(s) + `"`
+}
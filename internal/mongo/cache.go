@@ -0,0 +1,40 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// CacheInfo is the WiredTiger cache size and usage reported by serverStatus.
+type CacheInfo struct {
+	MaxBytes  int64 `json:"maxBytes"`  // "maximum bytes configured"
+	UsedBytes int64 `json:"usedBytes"` // "bytes currently in the cache"
+}
+
+// InspectCache reads the WiredTiger cache section of serverStatus. It fails
+// when the server has no WiredTiger cache, as on mongos or with another
+// storage engine.
+func (i *Inspector) InspectCache(ctx context.Context) (CacheInfo, error) {
+	cmd := bson.D{
+		{Key: "serverStatus", Value: 1},
+		{Key: "repl", Value: 0},
+		{Key: "metrics", Value: 0},
+		{Key: "locks", Value: 0},
+	}
+	var status bson.M
+	if err := i.db.RunCommand(ctx, "admin", cmd).Decode(&status); err != nil {
+		return CacheInfo{}, fmt.Errorf("serverStatus: %w", err)
+	}
+	cache := toBsonM(toBsonM(status["wiredTiger"])["cache"])
+	info := CacheInfo{
+		MaxBytes:  toInt64(cache["maximum bytes configured"]),
+		UsedBytes: toInt64(cache["bytes currently in the cache"]),
+	}
+	if info.MaxBytes <= 0 {
+		return CacheInfo{}, errors.New("serverStatus reports no WiredTiger cache (mongos or another storage engine)")
+	}
+	return info, nil
+}
@@ -0,0 +1,40 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestInspectCache(t *testing.T) {
+	raw, _ := bson.Marshal(bson.M{
+		"ok": 1,
+		"wiredTiger": bson.M{"cache": bson.M{
+			"maximum bytes configured":     int64(8 << 30),
+			"bytes currently in the cache": int64(6 << 30),
+		}},
+	})
+	insp := &Inspector{db: &mockClient{runCmdResult: raw}}
+	info, err := insp.InspectCache(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.MaxBytes != 8<<30 || info.UsedBytes != 6<<30 {
+		t.Errorf("cache = %+v", info)
+	}
+}
+
+func TestInspectCache_NoWiredTiger(t *testing.T) {
+	raw, _ := bson.Marshal(bson.M{"ok": 1, "process": "mongos"})
+	insp := &Inspector{db: &mockClient{runCmdResult: raw}}
+	if _, err := insp.InspectCache(context.TODO()); err == nil {
+		t.Fatal("expected error without a WiredTiger cache")
+	}
+
+	insp = &Inspector{db: &mockClient{runCmdErr: errors.New("not authorized on admin")}}
+	if _, err := insp.InspectCache(context.TODO()); err == nil {
+		t.Fatal("expected error")
+	}
+}
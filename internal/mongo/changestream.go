@@ -0,0 +1,96 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// changeStream is the part of *mongo.ChangeStream SampleTraffic uses.
+type changeStream interface {
+	Next(ctx context.Context) bool
+	Decode(val any) error
+	Err() error
+	Close(ctx context.Context) error
+}
+
+// TrafficSample counts the writes a change stream reported during a
+// bounded window.
+type TrafficSample struct {
+	Window     time.Duration     `json:"window"`
+	Writes     int64             `json:"writes"`
+	Namespaces []NamespaceWrites `json:"namespaces"`
+}
+
+// Rate is the per-second write rate of n writes over the sample window.
+func (s *TrafficSample) Rate(n int64) float64 {
+	if s.Window <= 0 {
+		return 0
+	}
+	return float64(n) / s.Window.Seconds()
+}
+
+// Lookup returns the writes sampled for a collection, or zero counts.
+func (s *TrafficSample) Lookup(database, collection string) NamespaceWrites {
+	for _, ns := range s.Namespaces {
+		if ns.Database == database && ns.Collection == collection {
+			return ns
+		}
+	}
+	return NamespaceWrites{Database: database, Collection: collection}
+}
+
+// SampleTraffic watches a change stream for window and counts inserts,
+// updates (including replacements) and deletes per collection, busiest
+// first. Events are projected down to their namespace and operation type,
+// so documents never leave the server. Change streams only report writes;
+// read activity still comes from $indexStats and the profiler. An empty
+// database watches the whole deployment, which needs a replica set or
+// sharded cluster.
+func (i *Inspector) SampleTraffic(ctx context.Context, database string, window time.Duration) (TrafficSample, error) {
+	wctx, cancel := context.WithTimeout(ctx, window)
+	defer cancel()
+
+	start := time.Now()
+	stream, err := i.db.Watch(wctx, database, bson.A{
+		bson.D{{Key: "$match", Value: bson.D{{Key: "operationType", Value: bson.D{
+			{Key: "$in", Value: bson.A{"insert", "update", "replace", "delete"}},
+		}}}}},
+		bson.D{{Key: "$project", Value: bson.D{{Key: "ns", Value: 1}, {Key: "operationType", Value: 1}}}},
+	})
+	if err != nil {
+		return TrafficSample{}, fmt.Errorf("open change stream: %w", err)
+	}
+	defer func() { _ = stream.Close(ctx) }()
+
+	var sample TrafficSample
+	tally := make(writeTally)
+	for stream.Next(wctx) {
+		var event struct {
+			NS struct {
+				DB   string `bson:"db"`
+				Coll string `bson:"coll"`
+			} `bson:"ns"`
+			OperationType string `bson:"operationType"`
+		}
+		if err := stream.Decode(&event); err != nil {
+			return TrafficSample{}, fmt.Errorf("decode change event: %w", err)
+		}
+		if tally.add(event.NS.DB, event.NS.Coll, event.OperationType, 1) {
+			sample.Writes++
+		}
+	}
+	// The stream ends when the window closes; anything else is a failure.
+	if err := stream.Err(); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		return TrafficSample{}, fmt.Errorf("change stream: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return TrafficSample{}, fmt.Errorf("change stream: %w", err)
+	}
+	sample.Window = time.Since(start)
+	sample.Namespaces = tally.namespaces()
+	return sample, nil
+}
@@ -0,0 +1,59 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func changeEvent(db, coll, op string) bson.M {
+	return bson.M{"ns": bson.M{"db": db, "coll": coll}, "operationType": op}
+}
+
+func TestSampleTraffic(t *testing.T) {
+	mc := &mockClient{watchEvents: []bson.M{
+		changeEvent("app", "orders", "insert"),
+		changeEvent("app", "orders", "replace"),
+		changeEvent("app", "orders", "update"),
+		changeEvent("app", "users", "delete"),
+		changeEvent("app", "system.views", "insert"),
+		changeEvent("app", "", "dropDatabase"),
+	}}
+	insp := &Inspector{db: mc}
+
+	sample, err := insp.SampleTraffic(context.Background(), "app", 20*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sample.Writes != 4 {
+		t.Errorf("writes = %d, want 4", sample.Writes)
+	}
+	if sample.Window < 20*time.Millisecond {
+		t.Errorf("window = %s, want at least 20ms", sample.Window)
+	}
+	orders := sample.Lookup("app", "orders")
+	if orders.Inserts != 1 || orders.Updates != 2 || orders.Deletes != 0 {
+		t.Errorf("orders = %+v", orders)
+	}
+	if got := sample.Lookup("app", "missing"); got.Writes() != 0 {
+		t.Errorf("unsampled collection = %+v", got)
+	}
+	if len(sample.Namespaces) != 2 || sample.Namespaces[0].Collection != "orders" {
+		t.Errorf("namespaces = %+v", sample.Namespaces)
+	}
+}
+
+func TestSampleTraffic_Errors(t *testing.T) {
+	insp := &Inspector{db: &mockClient{watchErr: errors.New("The $changeStream stage is only supported on replica sets")}}
+	if _, err := insp.SampleTraffic(context.Background(), "", time.Millisecond); err == nil {
+		t.Fatal("expected error when the change stream cannot be opened")
+	}
+
+	insp = &Inspector{db: &mockClient{watchStreamErr: errors.New("connection reset")}}
+	if _, err := insp.SampleTraffic(context.Background(), "", time.Second); err == nil {
+		t.Fatal("expected error when the stream fails")
+	}
+}
@@ -0,0 +1,83 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// ClientOp is one client connection from $currentOp: the operation it is
+// running, if any, and the metadata its driver sent when connecting.
+type ClientOp struct {
+	AppName       string `json:"appName,omitempty"`
+	Client        string `json:"client,omitempty"` // host:port
+	DriverName    string `json:"driverName,omitempty"`
+	DriverVersion string `json:"driverVersion,omitempty"`
+	Database      string `json:"database,omitempty"`
+	Collection    string `json:"collection,omitempty"` // empty for idle connections and commands
+	Op            string `json:"op,omitempty"`         // query, insert, update, remove, getmore, command or none
+}
+
+// CurrentOps lists the client connections of the deployment with
+// $currentOp, idle ones included. Internal operations, which have no
+// client, are left out. It needs the inprog privilege (clusterMonitor).
+func (i *Inspector) CurrentOps(ctx context.Context) ([]ClientOp, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$currentOp", Value: bson.D{{Key: "allUsers", Value: true}, {Key: "idleConnections", Value: true}}}},
+		{{Key: "$match", Value: bson.D{{Key: "client", Value: bson.D{{Key: "$exists", Value: true}}}}}},
+		{{Key: "$project", Value: bson.D{
+			{Key: "appName", Value: 1}, {Key: "client", Value: 1}, {Key: "clientMetadata.driver", Value: 1},
+			{Key: "ns", Value: 1}, {Key: "op", Value: 1},
+		}}},
+	}
+	var docs []bson.M
+	if err := i.aggregateAll(ctx, "admin", "", pipeline, &docs); err != nil {
+		return nil, fmt.Errorf("$currentOp: %w", err)
+	}
+	ops := make([]ClientOp, 0, len(docs))
+	for _, doc := range docs {
+		driver := toBsonM(toBsonM(doc["clientMetadata"])["driver"])
+		op := ClientOp{
+			AppName:       toString(doc["appName"]),
+			Client:        toString(doc["client"]),
+			DriverName:    toString(driver["name"]),
+			DriverVersion: toString(driver["version"]),
+			Op:            toString(doc["op"]),
+		}
+		// Commands run on "db.$cmd"; only real collections are kept.
+		if db, coll := splitNamespace(toString(doc["ns"])); coll != "" && !strings.HasPrefix(coll, "$cmd") {
+			op.Database, op.Collection = db, coll
+		}
+		ops = append(ops, op)
+	}
+	return ops, nil
+}
+
+// ConnPoolStats summarizes connPoolStats: the pools the server keeps for
+// its outgoing connections to other members and shards.
+type ConnPoolStats struct {
+	InUse      int64 `json:"inUse"`
+	Available  int64 `json:"available"`
+	Created    int64 `json:"created"`
+	Refreshing int64 `json:"refreshing"`
+	Hosts      int   `json:"hosts"` // remote hosts with a pool
+}
+
+// ConnPoolStats runs connPoolStats on admin. It needs the connPoolStats
+// privilege (clusterMonitor).
+func (i *Inspector) ConnPoolStats(ctx context.Context) (ConnPoolStats, error) {
+	var doc bson.M
+	if err := i.db.RunCommand(ctx, "admin", bson.D{{Key: "connPoolStats", Value: 1}}).Decode(&doc); err != nil {
+		return ConnPoolStats{}, fmt.Errorf("connPoolStats: %w", err)
+	}
+	return ConnPoolStats{
+		InUse:      toInt64(doc["totalInUse"]),
+		Available:  toInt64(doc["totalAvailable"]),
+		Created:    toInt64(doc["totalCreated"]),
+		Refreshing: toInt64(doc["totalRefreshing"]),
+		Hosts:      len(toBsonM(doc["hosts"])),
+	}, nil
+}
@@ -0,0 +1,58 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestCurrentOps(t *testing.T) {
+	mc := &mockClient{aggregateData: []bson.M{
+		{"appName": "billing", "client": "10.0.0.5:51234", "ns": "shop.orders", "op": "query",
+			"clientMetadata": bson.M{"driver": bson.M{"name": "nodejs", "version": "6.8.0"}}},
+		{"appName": "billing", "client": "10.0.0.5:51240", "ns": "shop.$cmd", "op": "command"},
+		{"client": "10.0.0.9:40000", "op": "none"},
+	}}
+	ops, err := (&Inspector{db: mc}).CurrentOps(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 3 {
+		t.Fatalf("got %d ops, want 3: %+v", len(ops), ops)
+	}
+	want := ClientOp{AppName: "billing", Client: "10.0.0.5:51234", DriverName: "nodejs", DriverVersion: "6.8.0",
+		Database: "shop", Collection: "orders", Op: "query"}
+	if ops[0] != want {
+		t.Errorf("op = %+v, want %+v", ops[0], want)
+	}
+	if ops[1].Collection != "" || ops[2].AppName != "" || ops[2].Op != "none" {
+		t.Errorf("ops = %+v", ops[1:])
+	}
+
+	insp := &Inspector{db: &mockClient{aggregateErr: errors.New("not authorized on admin to execute command")}}
+	if _, err := insp.CurrentOps(context.TODO()); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestConnPoolStats(t *testing.T) {
+	raw, _ := bson.Marshal(bson.M{
+		"ok": 1, "totalInUse": int32(3), "totalAvailable": int32(12), "totalCreated": int64(40), "totalRefreshing": int32(0),
+		"hosts": bson.M{"rs0-1:27017": bson.M{"inUse": int32(1)}, "rs0-2:27017": bson.M{"inUse": int32(2)}},
+	})
+	stats, err := (&Inspector{db: &mockClient{runCmdResult: raw}}).ConnPoolStats(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ConnPoolStats{InUse: 3, Available: 12, Created: 40, Hosts: 2}
+	if stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+
+	insp := &Inspector{db: &mockClient{runCmdErr: errors.New("not authorized on admin")}}
+	if _, err := insp.ConnPoolStats(context.TODO()); err == nil {
+		t.Fatal("expected error")
+	}
+}
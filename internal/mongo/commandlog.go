@@ -0,0 +1,94 @@
+package mongo
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+// ExecutedCommand is a command the driver ran on a server, reported to
+// Config.CommandLog when it finished. Unlike CommandEvent it covers the
+// driver's own commands too, such as authentication and killCursors.
+type ExecutedCommand struct {
+	Command    string
+	Database   string
+	Collection string // empty for database and server commands
+	Server     string // host:port the command ran on
+	Started    time.Time
+	Duration   time.Duration
+	// ReplyBytes is the size of the server's reply; 0 when the command
+	// failed.
+	ReplyBytes int
+	Err        error
+}
+
+// Namespace returns "db.collection", or just the database for commands
+// without a collection.
+func (c ExecutedCommand) Namespace() string {
+	return CommandEvent{Database: c.Database, Collection: c.Collection}.Namespace()
+}
+
+// commandMonitor reports every finished command to log. Collection and
+// start time come from the started event, kept by request ID until the
+// command finishes. Command bodies are never passed on, so filters and
+// credentials stay out of the log.
+func commandMonitor(log func(ExecutedCommand)) *event.CommandMonitor {
+	var pending sync.Map // request ID → ExecutedCommand
+	finish := func(e event.CommandFinishedEvent, replyBytes int, err error) {
+		c := ExecutedCommand{Command: e.CommandName, Database: e.DatabaseName}
+		if v, ok := pending.LoadAndDelete(e.RequestID); ok {
+			c = v.(ExecutedCommand)
+		} else {
+			c.Started = time.Now().Add(-e.Duration)
+		}
+		c.Server = serverAddress(e.ConnectionID)
+		c.Duration = e.Duration
+		c.ReplyBytes = replyBytes
+		c.Err = err
+		log(c)
+	}
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, e *event.CommandStartedEvent) {
+			pending.Store(e.RequestID, ExecutedCommand{
+				Command:    e.CommandName,
+				Database:   e.DatabaseName,
+				Collection: rawCommandCollection(e.Command),
+				Started:    time.Now(),
+			})
+		},
+		Succeeded: func(_ context.Context, e *event.CommandSucceededEvent) {
+			finish(e.CommandFinishedEvent, len(e.Reply), nil)
+		},
+		Failed: func(_ context.Context, e *event.CommandFailedEvent) {
+			finish(e.CommandFinishedEvent, 0, e.Failure)
+		},
+	}
+}
+
+// rawCommandCollection returns the collection a command document targets:
+// the value of its first element when that is a string, or the
+// "collection" field of getMore.
+func rawCommandCollection(cmd bson.Raw) string {
+	if coll, ok := cmd.Lookup("collection").StringValueOK(); ok {
+		return coll
+	}
+	elems, err := cmd.Elements()
+	if err != nil || len(elems) == 0 {
+		return ""
+	}
+	coll, _ := elems[0].Value().StringValueOK()
+	return coll
+}
+
+// serverAddress strips the connection number from a driver connection ID
+// such as "db1.example.com:27017[-12]".
+func serverAddress(connectionID string) string {
+	if i := strings.LastIndex(connectionID, "[-"); i >= 0 {
+		return connectionID[:i]
+	}
+	return connectionID
+}
@@ -0,0 +1,59 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
+)
+
+func TestCommandMonitor(t *testing.T) {
+	var got []ExecutedCommand
+	m := commandMonitor(func(c ExecutedCommand) { got = append(got, c) })
+	ctx := context.Background()
+
+	raw := func(d bson.D) bson.Raw {
+		b, err := bson.Marshal(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	m.Started(ctx, &event.CommandStartedEvent{
+		Command:      raw(bson.D{{Key: "listIndexes", Value: "orders"}}),
+		DatabaseName: "app", CommandName: "listIndexes", RequestID: 1,
+	})
+	m.Started(ctx, &event.CommandStartedEvent{
+		Command:      raw(bson.D{{Key: "getMore", Value: int64(9)}, {Key: "collection", Value: "events"}}),
+		DatabaseName: "app", CommandName: "getMore", RequestID: 2,
+	})
+	reply := raw(bson.D{{Key: "ok", Value: 1}})
+	m.Succeeded(ctx, &event.CommandSucceededEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{
+			CommandName: "listIndexes", DatabaseName: "app", RequestID: 1,
+			Duration: 3 * time.Millisecond, ConnectionID: "db1:27017[-4]",
+		},
+		Reply: reply,
+	})
+	m.Failed(ctx, &event.CommandFailedEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{
+			CommandName: "getMore", DatabaseName: "app", RequestID: 2,
+			Duration: time.Millisecond, ConnectionID: "db1:27017[-5]",
+		},
+		Failure: errors.New("cursor not found"),
+	})
+
+	if len(got) != 2 {
+		t.Fatalf("got %d commands, want 2", len(got))
+	}
+	if c := got[0]; c.Namespace() != "app.orders" || c.Server != "db1:27017" || c.Duration != 3*time.Millisecond ||
+		c.ReplyBytes != len(reply) || c.Err != nil || c.Started.IsZero() {
+		t.Errorf("listIndexes = %+v", c)
+	}
+	if c := got[1]; c.Namespace() != "app.events" || c.ReplyBytes != 0 || c.Err == nil {
+		t.Errorf("getMore = %+v", c)
+	}
+}
@@ -0,0 +1,110 @@
+package mongo
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// dateFields are the top-level field names, compared case-insensitively,
+// that commonly record when a document was created or last changed.
+var dateFields = map[string]bool{
+	"created": true, "createdat": true, "created_at": true, "creationdate": true,
+	"inserted": true, "insertedat": true, "inserted_at": true,
+	"updated": true, "updatedat": true, "updated_at": true,
+	"modified": true, "modifiedat": true, "modified_at": true, "lastmodified": true, "last_modified": true,
+	"timestamp": true, "ts": true, "date": true,
+}
+
+// DateRange is the earliest and latest time seen in one field of the
+// sampled documents. Field "_id" holds ObjectId creation times.
+type DateRange struct {
+	Field  string    `json:"field"`
+	Oldest time.Time `json:"oldest"`
+	Newest time.Time `json:"newest"`
+}
+
+// DataAge is the span of document times in a collection's sample, over
+// ObjectId _id values and well-known date fields.
+type DataAge struct {
+	Oldest time.Time `json:"oldest"`
+	Newest time.Time `json:"newest"`
+}
+
+// Age returns the span of all date ranges in the sample, or nil when no
+// sampled document carried a time.
+func (r *FieldSampleResult) Age() *DataAge {
+	var age *DataAge
+	for _, dr := range r.DateRanges {
+		if age == nil {
+			age = &DataAge{Oldest: dr.Oldest, Newest: dr.Newest}
+			continue
+		}
+		if dr.Oldest.Before(age.Oldest) {
+			age.Oldest = dr.Oldest
+		}
+		if dr.Newest.After(age.Newest) {
+			age.Newest = dr.Newest
+		}
+	}
+	return age
+}
+
+// dateTracker folds the ObjectId _id and well-known date fields of sampled
+// documents into per-field date ranges, and counts documents by _id
+// creation year.
+type dateTracker struct {
+	ranges map[string]*DateRange
+	years  map[int]int64
+}
+
+func newDateTracker() *dateTracker {
+	return &dateTracker{ranges: make(map[string]*DateRange), years: make(map[int]int64)}
+}
+
+func (d *dateTracker) add(doc bson.M) {
+	for key, val := range doc {
+		var t time.Time
+		switch v := val.(type) {
+		case bson.ObjectID:
+			if key != "_id" {
+				continue
+			}
+			t = v.Timestamp()
+			d.years[t.Year()]++
+		case bson.DateTime:
+			if !dateFields[strings.ToLower(key)] {
+				continue
+			}
+			t = v.Time().UTC()
+		default:
+			continue
+		}
+		r := d.ranges[key]
+		if r == nil {
+			d.ranges[key] = &DateRange{Field: key, Oldest: t, Newest: t}
+			continue
+		}
+		if t.Before(r.Oldest) {
+			r.Oldest = t
+		}
+		if t.After(r.Newest) {
+			r.Newest = t
+		}
+	}
+}
+
+// result returns the ranges sorted by field, or nil if none were seen.
+func (d *dateTracker) result() []DateRange {
+	if len(d.ranges) == 0 {
+		return nil
+	}
+	out := make([]DateRange, 0, len(d.ranges))
+	for _, r := range d.ranges {
+		out = append(out, *r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Field < out[j].Field })
+	return out
+}
@@ -0,0 +1,378 @@
+package mongo
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// archiveMagic starts every mongodump --archive stream (little-endian).
+const archiveMagic uint32 = 0x8199e26d
+
+// archiveTerminator ends the archive prelude and each namespace block.
+const archiveTerminator int32 = -1
+
+// maxArchiveDocSize bounds one archive document: the 16 MB BSON limit plus
+// the 16 KB the server allows for command overhead.
+const maxArchiveDocSize = 16<<20 + 16<<10
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// dumpMetadata is the content of a <collection>.metadata.json file, which
+// mongodump writes as extended JSON.
+type dumpMetadata struct {
+	Options bson.Raw   `bson:"options"`
+	Indexes []bson.Raw `bson:"indexes"`
+	Type    string     `bson:"type"`
+}
+
+// LoadSnapshotOrDump loads path as a mongodump output directory, a
+// mongodump --archive file (gzipped or not), or a snapshot JSON file.
+func LoadSnapshotOrDump(path string) (*Snapshot, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return LoadDumpDir(path)
+	}
+	head, err := readHead(path, 4)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(head, gzipMagic) || (len(head) == 4 && binary.LittleEndian.Uint32(head) == archiveMagic) {
+		return LoadDumpArchive(path)
+	}
+	return LoadSnapshot(path)
+}
+
+func readHead(path string, n int) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	buf := make([]byte, n)
+	read, err := io.ReadFull(f, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	return buf[:read], nil
+}
+
+// LoadDumpDir reads collection metadata from a mongodump output directory:
+// <dir>/<db>/<collection>.metadata.json with a matching .bson data file,
+// either optionally gzipped. A single database directory works too. Document
+// counts and data sizes come from the .bson files; index usage and storage
+// sizes are not part of a dump.
+func LoadDumpDir(dir string) (*Snapshot, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	snap := &Snapshot{Version: SnapshotVersion, CreatedAt: info.ModTime().UTC()}
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		name := d.Name()
+		gz := strings.HasSuffix(name, ".gz")
+		base := strings.TrimSuffix(name, ".gz")
+		if !strings.HasSuffix(base, ".metadata.json") {
+			return nil
+		}
+		database := filepath.Base(filepath.Dir(path))
+		escaped := strings.TrimSuffix(base, ".metadata.json")
+		collName := unescapeDumpName(escaped)
+		if skipDumpNamespace(database, collName) {
+			return nil
+		}
+
+		data, err := readDumpFile(path, gz)
+		if err != nil {
+			return err
+		}
+		coll, err := collectionFromDumpMetadata(database, collName, data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		bsonPath := filepath.Join(filepath.Dir(path), escaped+".bson")
+		if gz {
+			bsonPath += ".gz"
+		}
+		if coll.Type != "view" {
+			if err := countDumpDocuments(bsonPath, gz, &coll); err != nil {
+				return fmt.Errorf("%s: %w", bsonPath, err)
+			}
+		}
+		snap.Collections = append(snap.Collections, coll)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortCollections(snap.Collections)
+	return snap, nil
+}
+
+// LoadDumpArchive reads collection metadata from a mongodump --archive file,
+// gzipped (--gzip) or not. The prelude carries each collection's metadata;
+// the namespace blocks after it are walked to count documents.
+func LoadDumpArchive(path string) (*Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(f)
+	var r io.Reader = br
+	if head, _ := br.Peek(2); bytes.Equal(head, gzipMagic) {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("gunzip archive: %w", err)
+		}
+		defer func() { _ = zr.Close() }()
+		r = zr
+	}
+
+	var magic [4]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return nil, fmt.Errorf("read archive magic: %w", err)
+	}
+	if binary.LittleEndian.Uint32(magic[:]) != archiveMagic {
+		return nil, fmt.Errorf("not a mongodump archive")
+	}
+
+	header, err := readArchiveDoc(r)
+	if err != nil || header == nil {
+		return nil, fmt.Errorf("read archive header: %w", errOrTruncated(err))
+	}
+	snap := &Snapshot{Version: SnapshotVersion, CreatedAt: info.ModTime().UTC()}
+	snap.Server.Version, _ = header.Lookup("server_version").StringValueOK()
+
+	byNamespace := make(map[string]int)
+	for {
+		doc, err := readArchiveDoc(r)
+		if err != nil {
+			return nil, fmt.Errorf("read archive prelude: %w", err)
+		}
+		if doc == nil {
+			break
+		}
+		var entry struct {
+			Database   string `bson:"db"`
+			Collection string `bson:"collection"`
+			Metadata   string `bson:"metadata"`
+		}
+		if err := bson.Unmarshal(doc, &entry); err != nil {
+			return nil, fmt.Errorf("decode archive prelude: %w", err)
+		}
+		if skipDumpNamespace(entry.Database, entry.Collection) {
+			continue
+		}
+		coll, err := collectionFromDumpMetadata(entry.Database, entry.Collection, []byte(entry.Metadata))
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", entry.Database, entry.Collection, err)
+		}
+		byNamespace[entry.Database+"."+entry.Collection] = len(snap.Collections)
+		snap.Collections = append(snap.Collections, coll)
+	}
+
+	// Namespace blocks: a header document, the collection's documents, then
+	// a terminator. EOF headers have an empty body.
+	for {
+		header, err := readArchiveDoc(r)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read archive block: %w", err)
+		}
+		if header == nil {
+			continue
+		}
+		db, _ := header.Lookup("db").StringValueOK()
+		coll, _ := header.Lookup("collection").StringValueOK()
+		idx, tracked := byNamespace[db+"."+coll]
+		for {
+			doc, err := readArchiveDoc(r)
+			if err != nil {
+				return nil, fmt.Errorf("read %s.%s documents: %w", db, coll, errOrTruncated(err))
+			}
+			if doc == nil {
+				break
+			}
+			if tracked {
+				snap.Collections[idx].DocCount++
+				snap.Collections[idx].Size += int64(len(doc))
+			}
+		}
+	}
+	for i := range snap.Collections {
+		if c := &snap.Collections[i]; c.DocCount > 0 {
+			c.AvgObjSize = c.Size / c.DocCount
+		}
+	}
+	sortCollections(snap.Collections)
+	return snap, nil
+}
+
+// readArchiveDoc reads one BSON document from an archive stream. It returns
+// nil at a terminator and io.EOF at a clean end of stream.
+func readArchiveDoc(r io.Reader) (bson.Raw, error) {
+	var lenBuf [4]byte
+	if _, err := io.ReadFull(r, lenBuf[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	n := int32(binary.LittleEndian.Uint32(lenBuf[:]))
+	if n == archiveTerminator {
+		return nil, nil
+	}
+	if n < 5 || n > maxArchiveDocSize {
+		return nil, fmt.Errorf("invalid document length %d", n)
+	}
+	doc := make([]byte, n)
+	copy(doc, lenBuf[:])
+	if _, err := io.ReadFull(r, doc[4:]); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return doc, nil
+}
+
+func errOrTruncated(err error) error {
+	if err == nil || errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// collectionFromDumpMetadata builds CollectionInfo from the extended JSON
+// metadata mongodump writes for each collection.
+func collectionFromDumpMetadata(database, collName string, data []byte) (CollectionInfo, error) {
+	var md dumpMetadata
+	if err := bson.UnmarshalExtJSON(data, false, &md); err != nil {
+		return CollectionInfo{}, fmt.Errorf("decode metadata: %w", err)
+	}
+	coll := CollectionInfo{Name: collName, Database: database, Type: md.Type}
+	if coll.Type == "" {
+		coll.Type = "collection"
+	}
+	for _, raw := range md.Indexes {
+		idx, err := indexFromRaw(raw)
+		if err != nil {
+			return CollectionInfo{}, fmt.Errorf("decode index: %w", err)
+		}
+		coll.Indexes = append(coll.Indexes, idx)
+	}
+	spec := &mongo.CollectionSpecification{Name: collName, Options: md.Options}
+	if v, ok := validatorFromSpec(database, spec); ok {
+		coll.Validator = &v
+	}
+	coll.EncryptedFields, coll.EncryptedQueries = encryptedFieldsFromSpec(spec)
+	return coll, nil
+}
+
+// countDumpDocuments fills DocCount, Size and AvgObjSize from a .bson data
+// file. A missing file (metadata-only dump) leaves them zero.
+func countDumpDocuments(path string, gz bool, coll *CollectionInfo) error {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	var r io.Reader = bufio.NewReader(f)
+	if gz {
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = zr.Close() }()
+		r = zr
+	}
+	for {
+		doc, err := readArchiveDoc(r)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if doc == nil {
+			return fmt.Errorf("invalid document length -1")
+		}
+		coll.DocCount++
+		coll.Size += int64(len(doc))
+	}
+	if coll.DocCount > 0 {
+		coll.AvgObjSize = coll.Size / coll.DocCount
+	}
+	return nil
+}
+
+func readDumpFile(path string, gz bool) ([]byte, error) {
+	if !gz {
+		return os.ReadFile(path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = zr.Close() }()
+	return io.ReadAll(zr)
+}
+
+// skipDumpNamespace drops system databases and collections, as Inspect does
+// for live clusters.
+func skipDumpNamespace(database, collection string) bool {
+	return systemDBs[database] || strings.HasPrefix(collection, "system.")
+}
+
+// unescapeDumpName reverses the percent-encoding mongodump applies to
+// collection names that are not valid file names.
+func unescapeDumpName(name string) string {
+	if u, err := url.PathUnescape(name); err == nil {
+		return u
+	}
+	return name
+}
+
+func sortCollections(colls []CollectionInfo) {
+	sort.Slice(colls, func(i, j int) bool {
+		if colls[i].Database != colls[j].Database {
+			return colls[i].Database < colls[j].Database
+		}
+		return colls[i].Name < colls[j].Name
+	})
+}
@@ -0,0 +1,184 @@
+package mongo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const usersMetadata = `{"indexes":[` +
+	`{"v":{"$numberInt":"2"},"key":{"_id":{"$numberInt":"1"}},"name":"_id_"},` +
+	`{"v":{"$numberInt":"2"},"unique":true,"key":{"email":{"$numberInt":"1"}},"name":"email_1"},` +
+	`{"v":2,"key":{"createdAt":1},"name":"createdAt_1","expireAfterSeconds":3600}],` +
+	`"uuid":"0a1b","collectionName":"users","type":"collection",` +
+	`"options":{"validator":{"$jsonSchema":{"bsonType":"object","required":["email"]}},"validationLevel":"strict"}}`
+
+func mustMarshal(t *testing.T, v any) []byte {
+	t.Helper()
+	data, err := bson.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func checkUsers(t *testing.T, snap *Snapshot, wantDocs int64) {
+	t.Helper()
+	if len(snap.Collections) != 1 {
+		t.Fatalf("expected 1 collection, got %+v", snap.Collections)
+	}
+	c := snap.Collections[0]
+	if c.Database != "app" || c.Name != "users" || c.Type != "collection" {
+		t.Fatalf("collection = %s.%s (%s)", c.Database, c.Name, c.Type)
+	}
+	if len(c.Indexes) != 3 || !c.Indexes[1].Unique || c.Indexes[2].TTL == nil || *c.Indexes[2].TTL != 3600 {
+		t.Fatalf("indexes = %+v", c.Indexes)
+	}
+	if c.Indexes[1].Key[0].Field != "email" || c.Indexes[1].Key[0].Direction != 1 {
+		t.Fatalf("email key = %+v", c.Indexes[1].Key)
+	}
+	if c.Validator == nil || c.Validator.ValidationLevel != "strict" || len(c.Validator.Schema.Required) != 1 {
+		t.Fatalf("validator = %+v", c.Validator)
+	}
+	if c.DocCount != wantDocs {
+		t.Fatalf("docCount = %d, want %d", c.DocCount, wantDocs)
+	}
+}
+
+func TestLoadDumpDir(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"app", "admin"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write := func(rel string, data []byte) {
+		if err := os.WriteFile(filepath.Join(root, rel), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("app/users.metadata.json", []byte(usersMetadata))
+	docs := append(mustMarshal(t, bson.D{{Key: "email", Value: "a@example.com"}}), mustMarshal(t, bson.D{{Key: "email", Value: "b@example.com"}})...)
+	write("app/users.bson", docs)
+	write("admin/system.version.metadata.json", []byte(`{"indexes":[]}`))
+
+	snap, err := LoadSnapshotOrDump(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkUsers(t, snap, 2)
+	if snap.Collections[0].Size != int64(len(docs)) {
+		t.Errorf("size = %d, want %d", snap.Collections[0].Size, len(docs))
+	}
+}
+
+func TestLoadDumpDir_Gzip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "app")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	_, _ = zw.Write([]byte(usersMetadata))
+	_ = zw.Close()
+	if err := os.WriteFile(filepath.Join(dir, "users.metadata.json.gz"), buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A database directory on its own, without .bson data files.
+	snap, err := LoadDumpDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkUsers(t, snap, 0)
+}
+
+func buildArchive(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	le := func(v uint32) {
+		var b [4]byte
+		binary.LittleEndian.PutUint32(b[:], v)
+		buf.Write(b[:])
+	}
+	terminator := func() { le(0xffffffff) }
+
+	le(archiveMagic)
+	buf.Write(mustMarshal(t, bson.D{{Key: "version", Value: "0.1"}, {Key: "server_version", Value: "7.0.5"}}))
+	buf.Write(mustMarshal(t, bson.D{{Key: "db", Value: "app"}, {Key: "collection", Value: "users"}, {Key: "metadata", Value: usersMetadata}, {Key: "size", Value: int64(0)}, {Key: "type", Value: "collection"}}))
+	buf.Write(mustMarshal(t, bson.D{{Key: "db", Value: "admin"}, {Key: "collection", Value: "system.users"}, {Key: "metadata", Value: `{}`}}))
+	terminator()
+
+	buf.Write(mustMarshal(t, bson.D{{Key: "db", Value: "app"}, {Key: "collection", Value: "users"}, {Key: "EOF", Value: false}}))
+	for i := 0; i < 3; i++ {
+		buf.Write(mustMarshal(t, bson.D{{Key: "n", Value: int32(i)}}))
+	}
+	terminator()
+	buf.Write(mustMarshal(t, bson.D{{Key: "db", Value: "app"}, {Key: "collection", Value: "users"}, {Key: "EOF", Value: true}}))
+	terminator()
+	return buf.Bytes()
+}
+
+func TestLoadDumpArchive(t *testing.T) {
+	dir := t.TempDir()
+	plain := filepath.Join(dir, "dump.archive")
+	if err := os.WriteFile(plain, buildArchive(t), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	_, _ = zw.Write(buildArchive(t))
+	_ = zw.Close()
+	gzipped := filepath.Join(dir, "dump.archive.gz")
+	if err := os.WriteFile(gzipped, gz.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{plain, gzipped} {
+		snap, err := LoadSnapshotOrDump(path)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		checkUsers(t, snap, 3)
+		if snap.Server.Version != "7.0.5" {
+			t.Errorf("server version = %q", snap.Server.Version)
+		}
+	}
+}
+
+func TestLoadDumpArchive_Truncated(t *testing.T) {
+	data := buildArchive(t)
+	path := filepath.Join(t.TempDir(), "dump.archive")
+	if err := os.WriteFile(path, data[:len(data)-30], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadDumpArchive(path); err == nil {
+		t.Fatal("expected error for truncated archive")
+	}
+}
+
+func TestReadArchiveDocRejectsOversizedLength(t *testing.T) {
+	var data []byte
+	data = binary.LittleEndian.AppendUint32(data, 1<<31-1)
+	data = append(data, 0, 0, 0, 0)
+	if _, err := readArchiveDoc(bytes.NewReader(data)); err == nil || !strings.Contains(err.Error(), "invalid document length") {
+		t.Fatalf("expected invalid document length error, got %v", err)
+	}
+}
+
+func TestLoadSnapshotOrDump_SnapshotJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snap.json")
+	if err := os.WriteFile(path, []byte(`{"version":1,"collections":[{"database":"app","name":"users"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	snap, err := LoadSnapshotOrDump(path)
+	if err != nil || len(snap.Collections) != 1 {
+		t.Fatalf("snapshot = %+v, err = %v", snap, err)
+	}
+}
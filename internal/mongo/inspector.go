@@ -2,13 +2,16 @@ package mongo
 
 import (
 	"context"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
@@ -28,8 +31,9 @@ type dbClient interface {
 	ListDatabases(ctx context.Context, filter any) (mongo.ListDatabasesResult, error)
 	ListCollectionSpecs(ctx context.Context, dbName string) ([]mongo.CollectionSpecification, error)
 	RunCommand(ctx context.Context, dbName string, cmd any) *mongo.SingleResult
-	ListIndexSpecs(ctx context.Context, dbName, collName string) ([]mongo.IndexSpecification, error)
+	ListIndexes(ctx context.Context, dbName, collName string) ([]bson.Raw, error)
 	Aggregate(ctx context.Context, dbName, collName string, pipeline any) (*mongo.Cursor, error)
+	Watch(ctx context.Context, dbName string, pipeline any) (changeStream, error)
 }
 
 // mongoDBClient wraps the real mongo.Client to implement dbClient.
@@ -57,23 +61,69 @@ func (m *mongoDBClient) RunCommand(ctx context.Context, dbName string, cmd any)
 	return m.client.Database(dbName).RunCommand(ctx, cmd)
 }
 
-func (m *mongoDBClient) ListIndexSpecs(ctx context.Context, dbName, collName string) ([]mongo.IndexSpecification, error) {
-	return m.client.Database(dbName).Collection(collName).Indexes().ListSpecifications(ctx)
+func (m *mongoDBClient) ListIndexes(ctx context.Context, dbName, collName string) ([]bson.Raw, error) {
+	cursor, err := m.client.Database(dbName).Collection(collName).Indexes().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+	var docs []bson.Raw
+	for cursor.Next(ctx) {
+		docs = append(docs, append(bson.Raw(nil), cursor.Current...))
+	}
+	return docs, cursor.Err()
 }
 
+// Aggregate runs a pipeline on one collection, or on the database when
+// collName is empty, as stages such as $currentOp require.
 func (m *mongoDBClient) Aggregate(ctx context.Context, dbName, collName string, pipeline any) (*mongo.Cursor, error) {
+	if collName == "" {
+		return m.client.Database(dbName).Aggregate(ctx, pipeline)
+	}
 	return m.client.Database(dbName).Collection(collName).Aggregate(ctx, pipeline)
 }
 
+// Watch opens a change stream on one database, or on the whole deployment
+// when dbName is empty.
+func (m *mongoDBClient) Watch(ctx context.Context, dbName string, pipeline any) (changeStream, error) {
+	if dbName == "" {
+		return m.client.Watch(ctx, pipeline)
+	}
+	return m.client.Database(dbName).Watch(ctx, pipeline)
+}
+
 // Inspector reads MongoDB metadata and statistics.
 type Inspector struct {
-	db dbClient
+	db          dbClient
+	concurrency int
+	dbTimeout   time.Duration
+	retries     int
+	progress    Progress
+	quick       bool
+	pii         []PIIPattern
 }
 
 // NewInspector connects to MongoDB and verifies the connection.
 // The context deadline is used to bound connection and server selection time.
 func NewInspector(ctx context.Context, cfg Config) (*Inspector, error) {
 	opts := options.Client().ApplyURI(cfg.URI)
+	if cfg.TLS.Enabled() {
+		tc, err := clientTLSConfig(cfg.URI, opts.TLSConfig, cfg.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("tls: %w", err)
+		}
+		opts.SetTLSConfig(tc)
+	}
+	var monitor *event.CommandMonitor
+	if cfg.CommandLog != nil {
+		monitor = commandMonitor(cfg.CommandLog)
+	}
+	if cfg.OnCommand != nil {
+		monitor = withCursorCommands(monitor, cfg.OnCommand)
+	}
+	if monitor != nil {
+		opts.SetMonitor(monitor)
+	}
 
 	// Derive connection timeouts from context deadline so unreachable hosts
 	// don't hang for the OS-level TCP timeout (~2 min).
@@ -85,16 +135,49 @@ func NewInspector(ctx context.Context, cfg Config) (*Inspector, error) {
 
 	client, err := mongo.Connect(opts)
 	if err != nil {
-		return nil, classifyConnectError(fmt.Errorf("connect: %w", err))
+		return nil, classifyConnectError(fmt.Errorf("connect: %w", redactURIError(err, cfg.URI)))
 	}
 
-	dbc := &mongoDBClient{client: client}
+	var dbc dbClient = &mongoDBClient{client: client}
+	if cfg.StrictReadOnly || cfg.OnCommand != nil {
+		dbc = &guardedClient{dbClient: dbc, strict: cfg.StrictReadOnly, log: cfg.OnCommand}
+	}
 	if err := dbc.Ping(ctx); err != nil {
 		_ = dbc.Disconnect(ctx)
-		return nil, classifyConnectError(fmt.Errorf("connect: %w", err))
-	}
+		return nil, classifyConnectError(fmt.Errorf("connect: %w", redactURIError(err, cfg.URI)))
+	}
+
+	db := dbc
+	if cfg.Retries > 0 {
+		db = &retryingClient{dbClient: dbc, retries: cfg.Retries}
+	}
+	return &Inspector{
+		db:          db,
+		concurrency: cfg.Concurrency,
+		dbTimeout:   cfg.DatabaseTimeout,
+		retries:     cfg.Retries,
+		progress:    cfg.Progress,
+		quick:       cfg.Quick,
+		pii:         cfg.PIIPatterns,
+	}, nil
+}
 
-	return &Inspector{db: dbc}, nil
+// Progress phases.
+const (
+	PhaseInspect = "inspect"
+	PhaseSample  = "sample"
+)
+
+// Progress receives how far a long operation has got: done of total
+// collections and the namespace being worked on next. A final call with
+// done == total and no namespace marks the end of the phase. Calls come
+// from the goroutine running the operation.
+type Progress func(phase string, done, total int, namespace string)
+
+func (i *Inspector) reportProgress(phase string, done, total int, namespace string) {
+	if i.progress != nil {
+		i.progress(phase, done, total, namespace)
+	}
 }
 
 // Close disconnects from MongoDB.
@@ -136,41 +219,94 @@ func (i *Inspector) ListCollections(ctx context.Context, dbName string) ([]Colle
 
 	colls := make([]CollectionInfo, 0, len(specs))
 	for idx := range specs {
+		encrypted, queries := encryptedFieldsFromSpec(&specs[idx])
 		colls = append(colls, CollectionInfo{
-			Name:     specs[idx].Name,
-			Database: dbName,
-			Type:     specs[idx].Type,
+			Name:             specs[idx].Name,
+			Database:         dbName,
+			Type:             specs[idx].Type,
+			UUID:             collectionUUID(&specs[idx]),
+			EncryptedFields:  encrypted,
+			EncryptedQueries: queries,
 		})
 	}
 	return colls, nil
 }
 
+// collectionUUID renders a collection's UUID as hex, or "" for views and
+// servers that do not report one.
+func collectionUUID(spec *mongo.CollectionSpecification) string {
+	if spec.UUID == nil {
+		return ""
+	}
+	return hex.EncodeToString(spec.UUID.Data)
+}
+
 // GetValidators returns JSON schema validators configured on collections.
+// Databases whose deadline expires, or that are cut off by cancellation,
+// are left out and reported through a *PartialError alongside the
+// validators that were read.
 func (i *Inspector) GetValidators(ctx context.Context, database string) ([]ValidatorInfo, error) {
 	dbs, err := i.ListDatabases(ctx, database)
 	if err != nil {
 		return nil, err
 	}
 
-	var validators []ValidatorInfo
-	for _, db := range dbs {
-		specs, err := i.db.ListCollectionSpecs(ctx, db.Name)
+	names := make([]string, len(dbs))
+	for j, db := range dbs {
+		names[j] = db.Name
+	}
+	perDB := make([][]ValidatorInfo, len(dbs))
+	timedOut := make([]bool, len(dbs))
+	err = ForEachDatabase(ctx, names, i.concurrency, i.dbTimeout, func(ctx context.Context, j int, name string) error {
+		specs, err := i.db.ListCollectionSpecs(ctx, name)
 		if err != nil {
-			return nil, fmt.Errorf("list collections in %s: %w", db.Name, err)
+			// A database that runs out of time, its own or the caller's,
+			// or is cancelled is skipped so the others still report.
+			if ctx.Err() != nil {
+				timedOut[j] = true
+				return nil
+			}
+			return fmt.Errorf("list collections in %s: %w", name, err)
 		}
-		for i := range specs {
-			v, ok := validatorFromSpec(db.Name, &specs[i])
-			if ok {
-				validators = append(validators, v)
+		for k := range specs {
+			if v, ok := validatorFromSpec(name, &specs[k]); ok {
+				perDB[j] = append(perDB[j], v)
 			}
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var validators []ValidatorInfo
+	var skipped []string
+	for j, vs := range perDB {
+		if timedOut[j] {
+			skipped = append(skipped, names[j])
+		}
+		validators = append(validators, vs...)
+	}
+	if len(skipped) > 0 {
+		cause := ctx.Err()
+		if cause == nil {
+			cause = context.DeadlineExceeded
+		}
+		return validators, &PartialError{Skipped: skipped, Err: cause}
 	}
 	return validators, nil
 }
 
-// GetCollectionStats populates size/count stats for a collection.
-// Returns the collection info and a map of index name → size in bytes.
+// GetCollectionStats populates size/count stats and, where available,
+// operation latency for a collection. It reads the $collStats aggregation
+// stage, summing per-shard results, and falls back to the collStats command
+// where the stage is unavailable. Returns the collection info and a map of
+// index name → size in bytes.
 func (i *Inspector) GetCollectionStats(ctx context.Context, dbName, collName string) (CollectionInfo, map[string]int64, error) {
+	if info, indexSizes, ok := i.aggregateCollStats(ctx, dbName, collName); ok {
+		return info, indexSizes, nil
+	}
+
 	result := i.db.RunCommand(ctx, dbName, bson.D{{Key: "collStats", Value: collName}})
 	var raw bson.M
 	if err := result.Decode(&raw); err != nil {
@@ -178,18 +314,9 @@ func (i *Inspector) GetCollectionStats(ctx context.Context, dbName, collName str
 	}
 
 	indexSizes := make(map[string]int64)
-	switch rawSizes := raw["indexSizes"].(type) {
-	case bson.M:
-		for name, size := range rawSizes {
-			indexSizes[name] = toInt64(size)
-		}
-	case bson.D:
-		for _, e := range rawSizes {
-			indexSizes[e.Key] = toInt64(e.Value)
-		}
-	}
+	addIndexSizes(indexSizes, raw["indexSizes"])
 
-	return CollectionInfo{
+	info := CollectionInfo{
 		Name:           collName,
 		Database:       dbName,
 		DocCount:       toInt64(raw["count"]),
@@ -197,35 +324,196 @@ func (i *Inspector) GetCollectionStats(ctx context.Context, dbName, collName str
 		AvgObjSize:     toInt64(raw["avgObjSize"]),
 		StorageSize:    toInt64(raw["storageSize"]),
 		TotalIndexSize: toInt64(raw["totalIndexSize"]),
-	}, indexSizes, nil
+	}
+	info.addReusableBytes(raw)
+	return info, indexSizes, nil
+}
+
+// addReusableBytes adds the WiredTiger block manager's free space for the
+// collection and its indexes from collStats storage statistics.
+func (c *CollectionInfo) addReusableBytes(stats bson.M) {
+	c.ReusableBytes += wiredTigerReusable(stats["wiredTiger"])
+	for _, details := range toBsonM(stats["indexDetails"]) {
+		c.IndexReusableBytes += wiredTigerReusable(details)
+	}
+}
+
+func wiredTigerReusable(wt any) int64 {
+	return toInt64(toBsonM(toBsonM(wt)["block-manager"])["file bytes available for reuse"])
+}
+
+// aggregateCollStats reads storage and latency statistics with $collStats.
+// On a sharded collection the stage returns one document per shard. It
+// reports false when the stage fails or returns no storage statistics.
+func (i *Inspector) aggregateCollStats(ctx context.Context, dbName, collName string) (CollectionInfo, map[string]int64, bool) {
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$collStats", Value: bson.D{
+			{Key: "storageStats", Value: bson.D{}},
+			{Key: "latencyStats", Value: bson.D{{Key: "histograms", Value: true}}},
+		}}},
+	}
+	var docs []bson.M
+	if err := i.aggregateAll(ctx, dbName, collName, pipeline, &docs); err != nil {
+		return CollectionInfo{}, nil, false
+	}
+
+	info := CollectionInfo{Name: collName, Database: dbName}
+	indexSizes := make(map[string]int64)
+	var latency LatencyStats
+	found := false
+	for _, doc := range docs {
+		storage := toBsonM(doc["storageStats"])
+		if storage == nil {
+			continue
+		}
+		found = true
+		info.DocCount += toInt64(storage["count"])
+		info.Size += toInt64(storage["size"])
+		info.StorageSize += toInt64(storage["storageSize"])
+		info.TotalIndexSize += toInt64(storage["totalIndexSize"])
+		addIndexSizes(indexSizes, storage["indexSizes"])
+		info.addReusableBytes(storage)
+
+		if ls := toBsonM(doc["latencyStats"]); ls != nil {
+			latency.Reads.add(toBsonM(ls["reads"]))
+			latency.Writes.add(toBsonM(ls["writes"]))
+			latency.Commands.add(toBsonM(ls["commands"]))
+		}
+	}
+	if !found {
+		return CollectionInfo{}, nil, false
+	}
+	if info.DocCount > 0 {
+		info.AvgObjSize = info.Size / info.DocCount
+	}
+	if latency.Reads.Ops > 0 || latency.Writes.Ops > 0 || latency.Commands.Ops > 0 {
+		info.Latency = &latency
+	}
+	return info, indexSizes, true
+}
+
+// add merges one latencyStats section ({latency, ops, histogram}) into o.
+func (o *OpLatency) add(section bson.M) {
+	if section == nil {
+		return
+	}
+	o.Ops += toInt64(section["ops"])
+	o.TotalMicros += toInt64(section["latency"])
+	hist, _ := section["histogram"].(bson.A)
+	for _, b := range hist {
+		bucket := toBsonM(b)
+		if bucket == nil {
+			continue
+		}
+		micros, count := toInt64(bucket["micros"]), toInt64(bucket["count"])
+		merged := false
+		for j := range o.Histogram {
+			if o.Histogram[j].Micros == micros {
+				o.Histogram[j].Count += count
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			o.Histogram = append(o.Histogram, LatencyBucket{Micros: micros, Count: count})
+		}
+	}
+	sort.Slice(o.Histogram, func(a, b int) bool { return o.Histogram[a].Micros < o.Histogram[b].Micros })
+}
+
+func addIndexSizes(dst map[string]int64, v any) {
+	switch rawSizes := v.(type) {
+	case bson.M:
+		for name, size := range rawSizes {
+			dst[name] += toInt64(size)
+		}
+	case bson.D:
+		for _, e := range rawSizes {
+			dst[e.Key] += toInt64(e.Value)
+		}
+	}
+}
+
+// indexDocument is the subset of a listIndexes entry mongospectre reads.
+// Unlike mongo.IndexSpecification it keeps index options such as
+// partialFilterExpression, wildcardProjection, text weights and collation.
+type indexDocument struct {
+	Name                    string   `bson:"name"`
+	Key                     bson.Raw `bson:"key"`
+	Unique                  *bool    `bson:"unique"`
+	Sparse                  *bool    `bson:"sparse"`
+	Hidden                  *bool    `bson:"hidden"`
+	ExpireAfterSeconds      *int32   `bson:"expireAfterSeconds"`
+	PartialFilterExpression bson.Raw `bson:"partialFilterExpression"`
+	WildcardProjection      bson.Raw `bson:"wildcardProjection"`
+	Weights                 bson.Raw `bson:"weights"`
+	Collation               bson.Raw `bson:"collation"`
 }
 
 // GetIndexes returns index definitions for a collection.
 func (i *Inspector) GetIndexes(ctx context.Context, dbName, collName string) ([]IndexInfo, error) {
-	specs, err := i.db.ListIndexSpecs(ctx, dbName, collName)
+	docs, err := i.db.ListIndexes(ctx, dbName, collName)
 	if err != nil {
 		return nil, fmt.Errorf("list indexes %s.%s: %w", dbName, collName, err)
 	}
 
-	indexes := make([]IndexInfo, 0, len(specs))
-	for _, spec := range specs {
-		idx := IndexInfo{
-			Name: spec.Name,
-			Key:  bsonRawToKeyFields(spec.KeysDocument),
+	indexes := make([]IndexInfo, 0, len(docs))
+	for _, raw := range docs {
+		idx, err := indexFromRaw(raw)
+		if err != nil {
+			return nil, fmt.Errorf("decode index on %s.%s: %w", dbName, collName, err)
 		}
-		if spec.Unique != nil {
-			idx.Unique = *spec.Unique
+		indexes = append(indexes, idx)
+	}
+	return indexes, nil
+}
+
+// indexFromRaw converts a listIndexes entry (or a mongodump metadata index)
+// into IndexInfo.
+func indexFromRaw(raw bson.Raw) (IndexInfo, error) {
+	var spec indexDocument
+	if err := bson.Unmarshal(raw, &spec); err != nil {
+		return IndexInfo{}, err
+	}
+	idx := IndexInfo{
+		Name: spec.Name,
+		Key:  bsonRawToKeyFields(spec.Key),
+	}
+	if spec.Unique != nil {
+		idx.Unique = *spec.Unique
+	}
+	if spec.Sparse != nil {
+		idx.Sparse = *spec.Sparse
+	}
+	if spec.Hidden != nil {
+		idx.Hidden = *spec.Hidden
+	}
+	if spec.ExpireAfterSeconds != nil {
+		ttl := *spec.ExpireAfterSeconds
+		idx.TTL = &ttl
+	}
+	if len(spec.PartialFilterExpression) > 0 {
+		if ext, err := bson.MarshalExtJSON(spec.PartialFilterExpression, false, false); err == nil {
+			idx.PartialFilter = string(ext)
+		}
+	}
+	if len(spec.WildcardProjection) > 0 {
+		if ext, err := bson.MarshalExtJSON(spec.WildcardProjection, false, false); err == nil {
+			idx.WildcardProjection = string(ext)
 		}
-		if spec.Sparse != nil {
-			idx.Sparse = *spec.Sparse
+	}
+	if len(spec.Collation) > 0 {
+		if ext, err := bson.MarshalExtJSON(spec.Collation, false, false); err == nil {
+			idx.Collation = string(ext)
 		}
-		if spec.ExpireAfterSeconds != nil {
-			ttl := *spec.ExpireAfterSeconds
-			idx.TTL = &ttl
+	}
+	if elems, err := spec.Weights.Elements(); err == nil {
+		for _, elem := range elems {
+			idx.TextFields = append(idx.TextFields, elem.Key())
 		}
-		indexes = append(indexes, idx)
+		sort.Strings(idx.TextFields)
 	}
-	return indexes, nil
+	return idx, nil
 }
 
 // GetIndexStats returns usage statistics for all indexes on a collection.
@@ -233,14 +521,9 @@ func (i *Inspector) GetIndexStats(ctx context.Context, dbName, collName string)
 	pipeline := mongo.Pipeline{
 		bson.D{{Key: "$indexStats", Value: bson.D{}}},
 	}
-	cursor, err := i.db.Aggregate(ctx, dbName, collName, pipeline)
-	if err != nil {
-		return nil, fmt.Errorf("$indexStats %s.%s: %w", dbName, collName, err)
-	}
-
 	var results []bson.M
-	if err := cursor.All(ctx, &results); err != nil {
-		return nil, fmt.Errorf("read $indexStats %s.%s: %w", dbName, collName, err)
+	if err := i.aggregateAll(ctx, dbName, collName, pipeline, &results); err != nil {
+		return nil, fmt.Errorf("$indexStats %s.%s: %w", dbName, collName, err)
 	}
 
 	stats := make(map[string]IndexStats, len(results))
@@ -332,9 +615,16 @@ func (i *Inspector) ReadProfiler(ctx context.Context, database string, limit int
 	return entries, nil
 }
 
+// sampleMemoryLimit caps the document bytes read from one collection's
+// sample. Sampling stops early at the limit, so a handful of multi-megabyte
+// documents cannot exhaust memory.
+var sampleMemoryLimit int64 = 64 << 20
+
 // SampleDocuments samples documents from each collection and builds field frequency maps.
 // Uses $sample aggregation to randomly select documents, then flattens them into dot-notation
-// field paths with BSON type counts. Skips views and system collections.
+// field paths with BSON type counts. Skips views and system collections. Documents are
+// streamed from the cursor and folded in one at a time, so memory stays bounded by the
+// field maps rather than the sample size.
 func (i *Inspector) SampleDocuments(ctx context.Context, database string, sampleSize int64) ([]FieldSampleResult, error) {
 	if sampleSize <= 0 {
 		sampleSize = 100
@@ -348,89 +638,207 @@ func (i *Inspector) SampleDocuments(ctx context.Context, database string, sample
 		return nil, nil
 	}
 
-	var results []FieldSampleResult
+	// List first so progress can report a total.
+	var targets [][2]string
 	for _, db := range dbs {
 		specs, err := i.db.ListCollectionSpecs(ctx, db.Name)
 		if err != nil {
 			return nil, fmt.Errorf("list collections in %s: %w", db.Name, err)
 		}
-
 		for idx := range specs {
 			if specs[idx].Type == "view" || strings.HasPrefix(specs[idx].Name, "system.") {
 				continue
 			}
+			targets = append(targets, [2]string{db.Name, specs[idx].Name})
+		}
+	}
 
-			pipeline := mongo.Pipeline{
-				bson.D{{Key: "$sample", Value: bson.D{{Key: "size", Value: sampleSize}}}},
-			}
-			cursor, err := i.db.Aggregate(ctx, db.Name, specs[idx].Name, pipeline)
-			if err != nil {
-				if isNamespaceNotFoundErr(err) {
-					continue
-				}
-				return nil, fmt.Errorf("$sample %s.%s: %w", db.Name, specs[idx].Name, err)
-			}
-
-			var docs []bson.M
-			if err := cursor.All(ctx, &docs); err != nil {
-				return nil, fmt.Errorf("read $sample %s.%s: %w", db.Name, specs[idx].Name, err)
-			}
-			if len(docs) == 0 {
+	var results []FieldSampleResult
+	for n, t := range targets {
+		i.reportProgress(PhaseSample, n, len(targets), t[0]+"."+t[1])
+		sample, err := i.SampleCollection(ctx, t[0], t[1], sampleSize)
+		if err != nil {
+			if isNamespaceNotFoundErr(err) {
 				continue
 			}
+			return nil, err
+		}
+		if sample.SampleSize == 0 {
+			continue
+		}
+		results = append(results, sample)
+	}
+	i.reportProgress(PhaseSample, len(targets), len(targets), "")
 
-			// Build field frequency map: path -> type -> count.
-			fieldTypes := make(map[string]map[string]int64)
-			var maxDocSize int64
-			var maxFieldCount int
-			arrayLengths := make(map[string]int64)
+	return results, nil
+}
 
-			for _, doc := range docs {
-				flattenDocument(doc, "", fieldTypes)
+// SampleCollection samples one collection the way SampleDocuments does.
+// An empty collection yields a result with SampleSize 0.
+func (i *Inspector) SampleCollection(ctx context.Context, dbName, collName string, sampleSize int64) (FieldSampleResult, error) {
+	if sampleSize <= 0 {
+		sampleSize = 100
+	}
+	sample, err := i.sampleCollection(ctx, dbName, collName, sampleSize)
+	if err != nil {
+		return sample, fmt.Errorf("$sample %s.%s: %w", dbName, collName, err)
+	}
+	if sample.SampleSize == 0 {
+		return sample, nil
+	}
 
-				// Track max serialized document size.
-				if raw, marshalErr := bson.Marshal(doc); marshalErr == nil {
-					if int64(len(raw)) > maxDocSize {
-						maxDocSize = int64(len(raw))
-					}
-				}
+	// $bsonSize reports exact on-disk sizes over a wider sample
+	// without transferring documents; keep the client-side estimate
+	// when the server predates 4.4.
+	if size := i.sampleMaxBSONSize(ctx, dbName, collName, sampleSize*bsonSizeSampleFactor); size > sample.MaxDocSize {
+		sample.MaxDocSize = size
+	}
+	return sample, nil
+}
 
-				// Track max top-level field count.
-				if len(doc) > maxFieldCount {
-					maxFieldCount = len(doc)
-				}
+// sampleCollection streams a $sample of one collection through the field
+// walkers. It stops at sampleMemoryLimit bytes; a cursor that fails with a
+// transient error after some documents were read keeps what it has.
+func (i *Inspector) sampleCollection(ctx context.Context, dbName, collName string, sampleSize int64) (FieldSampleResult, error) {
+	result := FieldSampleResult{Database: dbName, Collection: collName}
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$sample", Value: bson.D{{Key: "size", Value: sampleSize}}}},
+	}
+	cursor, err := i.db.Aggregate(ctx, dbName, collName, pipeline)
+	if err != nil {
+		return result, err
+	}
+	defer func() { _ = cursor.Close(ctx) }()
 
-				// Track max array lengths per field path.
-				walkArrayLengths(doc, "", arrayLengths)
-			}
+	// Build field frequency map: path -> type -> count.
+	fieldTypes := make(map[string]map[string]int64)
+	arrayLengths := make(map[string]int64)
+	dottedKeys := make(map[string]bool)
+	var pii *piiCounter
+	if len(i.pii) > 0 {
+		pii = newPIICounter(i.pii)
+	}
+	dates := newDateTracker()
+	vectors := newVectorTracker()
+	var bytesRead int64
 
-			fields := make([]FieldFrequency, 0, len(fieldTypes))
-			for path, types := range fieldTypes {
-				var total int64
-				for _, c := range types {
-					total += c
-				}
-				fields = append(fields, FieldFrequency{
-					Path:  path,
-					Count: total,
-					Types: types,
-				})
-			}
-			sort.Slice(fields, func(a, b int) bool { return fields[a].Path < fields[b].Path })
+	for cursor.Next(ctx) {
+		size := int64(len(cursor.Current))
+		if bytesRead+size > sampleMemoryLimit && result.SampleSize > 0 {
+			result.MemoryCapped = true
+			break
+		}
+		bytesRead += size
+
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return result, err
+		}
+		result.SampleSize++
+		flattenDocument(doc, "", fieldTypes)
+
+		// Track max serialized document size.
+		result.MaxDocSize = max(result.MaxDocSize, size)
+
+		// Track max top-level field count.
+		result.MaxFieldCount = max(result.MaxFieldCount, len(doc))
 
-			results = append(results, FieldSampleResult{
-				Database:      db.Name,
-				Collection:    specs[idx].Name,
-				SampleSize:    int64(len(docs)),
-				Fields:        fields,
-				MaxDocSize:    maxDocSize,
-				MaxFieldCount: maxFieldCount,
-				ArrayLengths:  arrayLengths,
-			})
+		// Track max array lengths per field path.
+		walkArrayLengths(doc, "", arrayLengths)
+
+		// Keys with literal dots flatten into ambiguous paths; record them separately.
+		walkDottedKeys(doc, "", dottedKeys)
+
+		// Values are matched here and dropped; only counts are kept.
+		if pii != nil {
+			pii.add(doc, "")
 		}
+
+		// ObjectIds embed their creation time, which dates the document.
+		dates.add(doc)
+
+		// Embedding lengths and norms, for vector search index checks.
+		vectors.add(doc, "")
+	}
+	if err := cursor.Err(); err != nil && (result.SampleSize == 0 || !isTransient(err)) {
+		return result, err
 	}
 
-	return results, nil
+	result.Fields = make([]FieldFrequency, 0, len(fieldTypes))
+	for path, types := range fieldTypes {
+		var total int64
+		for _, c := range types {
+			total += c
+		}
+		result.Fields = append(result.Fields, FieldFrequency{
+			Path:  path,
+			Count: total,
+			Types: types,
+		})
+	}
+	sort.Slice(result.Fields, func(a, b int) bool { return result.Fields[a].Path < result.Fields[b].Path })
+	result.ArrayLengths = arrayLengths
+	result.DottedKeys = sortedKeys(dottedKeys)
+	if pii != nil {
+		result.PII = pii.result()
+	}
+	result.DateRanges = dates.result()
+	if len(dates.years) > 0 {
+		result.CreatedByYear = dates.years
+	}
+	result.Vectors = vectors.result()
+	return result, nil
+}
+
+// bsonSizeSampleFactor widens the $bsonSize sample relative to the field
+// sample, since it returns a single number per collection.
+const bsonSizeSampleFactor = 10
+
+// sampleMaxBSONSize returns the largest $bsonSize among n randomly sampled
+// documents, or 0 if the aggregation fails.
+func (i *Inspector) sampleMaxBSONSize(ctx context.Context, database, collection string, n int64) int64 {
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$sample", Value: bson.D{{Key: "size", Value: n}}}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: nil},
+			{Key: "maxSize", Value: bson.D{{Key: "$max", Value: bson.D{{Key: "$bsonSize", Value: "$$ROOT"}}}}},
+		}}},
+	}
+	var out []bson.M
+	if err := i.aggregateAll(ctx, database, collection, pipeline, &out); err != nil || len(out) == 0 {
+		return 0
+	}
+	return toInt64(out[0]["maxSize"])
+}
+
+// CountDuplicateKeys returns how many documents in a collection share their
+// values of fields with an earlier document, i.e. how many would have to be
+// removed or changed before a unique index on fields can be built.
+func (i *Inspector) CountDuplicateKeys(ctx context.Context, database, collection string, fields []string) (int64, error) {
+	// Group keys cannot contain dots, so project each field under a positional name.
+	groupKey := make(bson.D, 0, len(fields))
+	for n, f := range fields {
+		groupKey = append(groupKey, bson.E{Key: fmt.Sprintf("k%d", n), Value: "$" + f})
+	}
+	pipeline := mongo.Pipeline{
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: groupKey},
+			{Key: "n", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+		bson.D{{Key: "$match", Value: bson.D{{Key: "n", Value: bson.D{{Key: "$gt", Value: 1}}}}}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: nil},
+			{Key: "extra", Value: bson.D{{Key: "$sum", Value: bson.D{{Key: "$subtract", Value: bson.A{"$n", 1}}}}}},
+		}}},
+	}
+	var out []bson.M
+	if err := i.aggregateAll(ctx, database, collection, pipeline, &out); err != nil {
+		return 0, fmt.Errorf("count duplicates in %s.%s: %w", database, collection, err)
+	}
+	if len(out) == 0 {
+		return 0, nil
+	}
+	return toInt64(out[0]["extra"]), nil
 }
 
 // flattenDocument recursively walks a BSON document and records each field path
@@ -521,6 +929,55 @@ func walkArrayLengths(doc bson.M, prefix string, maxLengths map[string]int64) {
 	}
 }
 
+// walkDottedKeys records the flattened path of every key that itself contains
+// a ".", which MongoDB 5.0+ allows but dot-notation queries cannot address.
+func walkDottedKeys(doc bson.M, prefix string, out map[string]bool) {
+	for key, val := range doc {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		if strings.Contains(key, ".") {
+			out[path] = true
+		}
+		switch v := val.(type) {
+		case bson.M:
+			walkDottedKeys(v, path, out)
+		case bson.D:
+			walkDottedKeys(docToMap(v), path, out)
+		case bson.A:
+			for _, elem := range v {
+				switch nested := elem.(type) {
+				case bson.M:
+					walkDottedKeys(nested, path+"[]", out)
+				case bson.D:
+					walkDottedKeys(docToMap(nested), path+"[]", out)
+				}
+			}
+		}
+	}
+}
+
+func sortedKeys(m map[string]bool) []string {
+	if len(m) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func docToMap(d bson.D) bson.M {
+	m := make(bson.M, len(d))
+	for _, e := range d {
+		m[e.Key] = e.Value
+	}
+	return m
+}
+
 // bsonTypeName returns a human-readable BSON type name for a Go value.
 func bsonTypeName(v any) string {
 	if v == nil {
@@ -556,37 +1013,97 @@ func bsonTypeName(v any) string {
 	}
 }
 
-// Inspect gathers full metadata for all collections in the given databases.
+// PartialError is returned with partial results when the context expires
+// mid-inspection. Skipped lists the namespaces that were not inspected, as
+// "db" for a whole database or "db.collection".
+type PartialError struct {
+	Skipped []string
+	Err     error
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("inspection truncated, %d namespaces skipped: %v", len(e.Skipped), e.Err)
+}
+
+func (e *PartialError) Unwrap() error { return e.Err }
+
+// Inspect gathers full metadata for all collections in the given databases,
+// or only their index definitions when the inspector is quick. When ctx
+// expires part way, it returns the collections inspected so far
+// with a *PartialError naming the rest.
 func (i *Inspector) Inspect(ctx context.Context, database string) ([]CollectionInfo, error) {
 	dbs, err := i.ListDatabases(ctx, database)
 	if err != nil {
 		return nil, err
 	}
 
-	var all []CollectionInfo
-	for _, db := range dbs {
+	// List every database's collections first so progress can report a
+	// total; listing is cheap next to the per-collection stats calls.
+	var listed [][]CollectionInfo
+	var skipped []string
+	total := 0
+	for d, db := range dbs {
+		if ctx.Err() != nil {
+			for _, rest := range dbs[d:] {
+				skipped = append(skipped, rest.Name)
+			}
+			break
+		}
 		colls, err := i.ListCollections(ctx, db.Name)
 		if err != nil {
+			if ctx.Err() != nil {
+				skipped = append(skipped, db.Name)
+				continue
+			}
 			return nil, err
 		}
-		for _, coll := range colls {
+		listed = append(listed, colls)
+		total += len(colls)
+	}
+
+	var all []CollectionInfo
+	done := 0
+	for _, colls := range listed {
+		for c, coll := range colls {
+			if ctx.Err() != nil {
+				for _, rest := range colls[c:] {
+					skipped = append(skipped, rest.Database+"."+rest.Name)
+				}
+				break
+			}
+			i.reportProgress(PhaseInspect, done, total, coll.Database+"."+coll.Name)
+			done++
 			if coll.Type == "view" {
 				all = append(all, coll)
 				continue
 			}
+			if i.quick {
+				if indexes, err := i.GetIndexes(ctx, coll.Database, coll.Name); err == nil {
+					coll.Indexes = indexes
+				}
+				if ctx.Err() != nil {
+					skipped = append(skipped, coll.Database+"."+coll.Name)
+					continue
+				}
+				all = append(all, coll)
+				continue
+			}
 
-			stats, indexSizes, statsErr := i.GetCollectionStats(ctx, db.Name, coll.Name)
+			stats, indexSizes, statsErr := i.GetCollectionStats(ctx, coll.Database, coll.Name)
 			if statsErr == nil {
 				coll.DocCount = stats.DocCount
 				coll.Size = stats.Size
 				coll.AvgObjSize = stats.AvgObjSize
 				coll.StorageSize = stats.StorageSize
 				coll.TotalIndexSize = stats.TotalIndexSize
+				coll.Latency = stats.Latency
+				coll.ReusableBytes = stats.ReusableBytes
+				coll.IndexReusableBytes = stats.IndexReusableBytes
 			}
 
-			indexes, idxErr := i.GetIndexes(ctx, db.Name, coll.Name)
+			indexes, idxErr := i.GetIndexes(ctx, coll.Database, coll.Name)
 			if idxErr == nil {
-				idxStats, _ := i.GetIndexStats(ctx, db.Name, coll.Name)
+				idxStats, _ := i.GetIndexStats(ctx, coll.Database, coll.Name)
 				for j := range indexes {
 					if s, ok := idxStats[indexes[j].Name]; ok {
 						indexes[j].Stats = &s
@@ -598,15 +1115,29 @@ func (i *Inspector) Inspect(ctx context.Context, database string) ([]CollectionI
 				coll.Indexes = indexes
 			}
 
+			// Stats gathered while the deadline hit are incomplete; report
+			// the collection as skipped rather than empty.
+			if ctx.Err() != nil {
+				skipped = append(skipped, coll.Database+"."+coll.Name)
+				continue
+			}
 			all = append(all, coll)
 		}
 	}
+	i.reportProgress(PhaseInspect, total, total, "")
+	if len(skipped) > 0 {
+		return all, &PartialError{Skipped: skipped, Err: ctx.Err()}
+	}
 	return all, nil
 }
 
-// InspectUsers queries the usersInfo command on a database and returns user metadata.
+// InspectUsers queries the usersInfo command on a database and returns user
+// metadata, including credential mechanisms and authentication restrictions.
 func (i *Inspector) InspectUsers(ctx context.Context, dbName string) ([]UserInfo, error) {
-	result := i.db.RunCommand(ctx, dbName, bson.D{{Key: "usersInfo", Value: 1}})
+	result := i.db.RunCommand(ctx, dbName, bson.D{
+		{Key: "usersInfo", Value: 1},
+		{Key: "showAuthenticationRestrictions", Value: true},
+	})
 	var resp struct {
 		Users []UserInfo `bson:"users"`
 	}
@@ -616,6 +1147,22 @@ func (i *Inspector) InspectUsers(ctx context.Context, dbName string) ([]UserInfo
 	return resp.Users, nil
 }
 
+// InspectRoles returns the user-defined roles of a database with their
+// direct and inherited privileges.
+func (i *Inspector) InspectRoles(ctx context.Context, dbName string) ([]RoleInfo, error) {
+	result := i.db.RunCommand(ctx, dbName, bson.D{
+		{Key: "rolesInfo", Value: 1},
+		{Key: "showPrivileges", Value: true},
+	})
+	var resp struct {
+		Roles []RoleInfo `bson:"roles"`
+	}
+	if err := result.Decode(&resp); err != nil {
+		return nil, fmt.Errorf("rolesInfo on %s: %w", dbName, err)
+	}
+	return resp.Roles, nil
+}
+
 // InspectSharding gathers sharding metadata from config collections.
 // Returns Enabled=false with no error for non-sharded deployments.
 func (i *Inspector) InspectSharding(ctx context.Context) (ShardingInfo, error) {
@@ -719,10 +1266,33 @@ func (i *Inspector) InspectSharding(ctx context.Context) (ShardingInfo, error) {
 	return info, nil
 }
 
+// aggregateAll runs an aggregation and decodes every result into out, a
+// pointer to a slice. Opening the cursor is retried by the client; when
+// iterating it fails with a transient error the aggregation runs again.
+func (i *Inspector) aggregateAll(ctx context.Context, dbName, collName string, pipeline, out any) error {
+	var openErr error
+	err := withRetry(ctx, i.retries, func() error {
+		cursor, err := i.db.Aggregate(ctx, dbName, collName, pipeline)
+		if err != nil {
+			openErr = err
+			return nil
+		}
+		return cursor.All(ctx, out)
+	})
+	if openErr != nil {
+		return openErr
+	}
+	return err
+}
+
 func (i *Inspector) findDocuments(ctx context.Context, dbName, collName string, filter bson.M, limit int64) ([]bson.M, error) {
 	return i.findDocumentsWithSort(ctx, dbName, collName, filter, nil, limit)
 }
 
+// findDocumentsWithSort reads documents with find and getMore. When a
+// getMore fails with a transient error, such as the cursor being killed by
+// a failover, the read resumes with a new find that skips the documents
+// already read, up to the inspector's retry count.
 func (i *Inspector) findDocumentsWithSort(
 	ctx context.Context,
 	dbName, collName string,
@@ -730,6 +1300,40 @@ func (i *Inspector) findDocumentsWithSort(
 	sortDoc bson.D,
 	limit int64,
 ) ([]bson.M, error) {
+	batchSize := int32(500)
+	if limit > 0 && limit < int64(batchSize) {
+		batchSize = int32(limit)
+	}
+
+	var docs []bson.M
+	for resumes := 0; ; resumes++ {
+		more, err := i.findBatches(ctx, dbName, collName, filter, sortDoc, limit, int64(len(docs)), batchSize, &docs)
+		if err == nil {
+			break
+		}
+		if !more || resumes >= i.retries || !isTransient(err) {
+			return nil, err
+		}
+	}
+	if limit > 0 && int64(len(docs)) > limit {
+		docs = docs[:limit]
+	}
+	return docs, nil
+}
+
+// findBatches runs one find, skipping skip documents, and appends batches
+// to docs until the cursor is exhausted or limit documents are read. more
+// reports whether the find succeeded and a later getMore failed, the case
+// in which the read can be resumed.
+func (i *Inspector) findBatches(
+	ctx context.Context,
+	dbName, collName string,
+	filter bson.M,
+	sortDoc bson.D,
+	limit, skip int64,
+	batchSize int32,
+	docs *[]bson.M,
+) (more bool, err error) {
 	cmd := bson.D{{Key: "find", Value: collName}}
 	if filter != nil {
 		cmd = append(cmd, bson.E{Key: "filter", Value: filter})
@@ -737,13 +1341,11 @@ func (i *Inspector) findDocumentsWithSort(
 	if len(sortDoc) > 0 {
 		cmd = append(cmd, bson.E{Key: "sort", Value: sortDoc})
 	}
-	if limit > 0 {
-		cmd = append(cmd, bson.E{Key: "limit", Value: limit})
+	if skip > 0 {
+		cmd = append(cmd, bson.E{Key: "skip", Value: skip})
 	}
-
-	batchSize := int32(500)
-	if limit > 0 && limit < int64(batchSize) {
-		batchSize = int32(limit)
+	if limit > 0 {
+		cmd = append(cmd, bson.E{Key: "limit", Value: limit - skip})
 	}
 	cmd = append(cmd, bson.E{Key: "batchSize", Value: batchSize})
 
@@ -754,16 +1356,12 @@ func (i *Inspector) findDocumentsWithSort(
 		} `bson:"cursor"`
 	}
 	if err := i.db.RunCommand(ctx, dbName, cmd).Decode(&findResp); err != nil {
-		return nil, err
+		return false, err
 	}
 
-	docs := append([]bson.M(nil), findResp.Cursor.FirstBatch...)
+	*docs = append(*docs, findResp.Cursor.FirstBatch...)
 	cursorID := findResp.Cursor.ID
 
-	if limit > 0 && int64(len(docs)) >= limit {
-		return docs[:limit], nil
-	}
-
 	for cursorID != 0 {
 		getMore := bson.D{
 			{Key: "getMore", Value: cursorID},
@@ -771,7 +1369,7 @@ func (i *Inspector) findDocumentsWithSort(
 			{Key: "batchSize", Value: batchSize},
 		}
 		if limit > 0 {
-			remaining := limit - int64(len(docs))
+			remaining := limit - int64(len(*docs))
 			if remaining <= 0 {
 				break
 			}
@@ -787,17 +1385,13 @@ func (i *Inspector) findDocumentsWithSort(
 			} `bson:"cursor"`
 		}
 		if err := i.db.RunCommand(ctx, dbName, getMore).Decode(&getMoreResp); err != nil {
-			return nil, err
+			return true, err
 		}
 
-		docs = append(docs, getMoreResp.Cursor.NextBatch...)
+		*docs = append(*docs, getMoreResp.Cursor.NextBatch...)
 		cursorID = getMoreResp.Cursor.ID
-		if limit > 0 && int64(len(docs)) >= limit {
-			return docs[:limit], nil
-		}
 	}
-
-	return docs, nil
+	return false, nil
 }
 
 func profileEntryFromDoc(defaultDB string, doc bson.M) (ProfileEntry, bool) {
@@ -816,6 +1410,14 @@ func profileEntryFromDoc(defaultDB string, doc bson.M) (ProfileEntry, bool) {
 		dbName = nsDB
 	}
 
+	var sortKeys []KeyField
+	if raw, ok := doc["command"].(bson.D); ok {
+		sortKeys = profileSortKeys(lookupD(raw, "sort"))
+	} else if command != nil {
+		sortKeys = profileSortKeys(command["sort"])
+	}
+	hasSort, usedDisk := execStatsSort(doc["execStats"])
+
 	filterFields := extractProfileFields(nil)
 	sortFields := extractProfileFields(nil)
 	projectionFields := extractProfileFields(nil)
@@ -837,15 +1439,67 @@ func profileEntryFromDoc(defaultDB string, doc bson.M) (ProfileEntry, bool) {
 	return ProfileEntry{
 		Database:         dbName,
 		Collection:       collName,
+		Op:               toString(doc["op"]),
 		FilterFields:     filterFields,
 		SortFields:       sortFields,
 		ProjectionFields: projectionFields,
 		DurationMillis:   durationMillis,
 		Timestamp:        toTime(doc["ts"]),
 		PlanSummary:      toString(doc["planSummary"]),
+		DocsExamined:     toInt64(doc["docsExamined"]),
+		KeysExamined:     toInt64(doc["keysExamined"]),
+		NReturned:        toInt64(doc["nreturned"]),
+		SortKeys:         sortKeys,
+		HasSortStage:     toBool(doc["hasSortStage"]) || hasSort,
+		UsedDisk:         toBool(doc["usedDisk"]) || usedDisk,
+		AppName:          toString(doc["appName"]),
 	}, true
 }
 
+// profileSortKeys returns the sort spec with directions. Order is only
+// preserved when the command decoded as bson.D; $meta sorts are skipped.
+func profileSortKeys(v any) []KeyField {
+	var keys []KeyField
+	for _, k := range bsonAnyToKeyFields(v) {
+		if k.Direction != 0 {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// execStatsSort walks a profiler execStats tree (the executionStats section of
+// explain output) for a blocking SORT stage and whether it spilled to disk.
+func execStatsSort(v any) (hasSort, usedDisk bool) {
+	stage := toBsonM(v)
+	if stage == nil {
+		return false, false
+	}
+	if name := toString(stage["stage"]); name == "SORT" || name == "SORT_KEY_GENERATOR" {
+		hasSort = true
+		usedDisk = toBool(stage["usedDisk"]) || toInt64(stage["spills"]) > 0
+	}
+	children := []any{stage["inputStage"]}
+	if arr, ok := stage["inputStages"].(bson.A); ok {
+		children = append(children, arr...)
+	}
+	for _, child := range children {
+		s, d := execStatsSort(child)
+		hasSort = hasSort || s
+		usedDisk = usedDisk || d
+	}
+	return hasSort, usedDisk
+}
+
+func lookupD(doc bson.D, key string) any {
+	for _, e := range doc {
+		if e.Key == key {
+			return e.Value
+		}
+	}
+	return nil
+}
+
 func profileCollectionFromCommand(command bson.M) string {
 	if command == nil {
 		return ""
@@ -917,13 +1571,13 @@ func bsonAnyToKeyFields(v any) []KeyField {
 	case bson.D:
 		out := make([]KeyField, 0, len(keyDoc))
 		for _, e := range keyDoc {
-			out = append(out, KeyField{Field: e.Key, Direction: int(toInt64(e.Value))})
+			out = append(out, anyKeyField(e.Key, e.Value))
 		}
 		return out
 	case bson.M:
 		out := make([]KeyField, 0, len(keyDoc))
 		for field, dir := range keyDoc {
-			out = append(out, KeyField{Field: field, Direction: int(toInt64(dir))})
+			out = append(out, anyKeyField(field, dir))
 		}
 		sort.Slice(out, func(i, j int) bool { return out[i].Field < out[j].Field })
 		return out
@@ -932,6 +1586,15 @@ func bsonAnyToKeyFields(v any) []KeyField {
 	}
 }
 
+// anyKeyField builds a KeyField from a decoded key value, which is a number
+// for directional keys and a string such as "hashed" otherwise.
+func anyKeyField(field string, v any) KeyField {
+	if kind, ok := v.(string); ok {
+		return KeyField{Field: field, Kind: kind}
+	}
+	return KeyField{Field: field, Direction: int(toInt64(v))}
+}
+
 func splitNamespace(ns string) (string, string) {
 	parts := strings.SplitN(ns, ".", 2)
 	if len(parts) != 2 {
@@ -1064,6 +1727,50 @@ func validatorFromSpec(dbName string, spec *mongo.CollectionSpecification) (Vali
 	}, true
 }
 
+// encryptedFieldsFromSpec returns the sorted field paths of a Queryable
+// Encryption collection's encryptedFields option, and the query types of
+// those that are queryable.
+func encryptedFieldsFromSpec(spec *mongo.CollectionSpecification) ([]string, map[string][]string) {
+	if len(spec.Options) == 0 {
+		return nil, nil
+	}
+	var opts bson.M
+	if err := bson.Unmarshal(spec.Options, &opts); err != nil {
+		return nil, nil
+	}
+	ef := toBsonM(opts["encryptedFields"])
+	if ef == nil {
+		return nil, nil
+	}
+	fields, _ := ef["fields"].(bson.A)
+	var paths []string
+	var queries map[string][]string
+	for _, raw := range fields {
+		field := toBsonM(raw)
+		path := toString(field["path"])
+		if path == "" {
+			continue
+		}
+		paths = append(paths, path)
+		// queries is a single document or an array of them.
+		q := field["queries"]
+		list, ok := q.(bson.A)
+		if !ok {
+			list = bson.A{q}
+		}
+		for _, item := range list {
+			if qt := toString(toBsonM(item)["queryType"]); qt != "" {
+				if queries == nil {
+					queries = make(map[string][]string)
+				}
+				queries[path] = append(queries[path], qt)
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths, queries
+}
+
 func parseValidatorSchema(schema bson.M) ValidatorSchema {
 	out := ValidatorSchema{
 		Required:             parseStringArray(schema["required"]),
@@ -1085,7 +1792,8 @@ func parseValidatorSchema(schema bson.M) ValidatorSchema {
 		if len(types) == 0 {
 			types = parseBSONTypes(fieldSchema["type"])
 		}
-		out.Properties[field] = ValidatorField{BSONTypes: types}
+		_, encrypted := fieldSchema["encrypt"]
+		out.Properties[field] = ValidatorField{BSONTypes: types, Encrypted: encrypted}
 	}
 	return out
 }
@@ -1192,27 +1900,50 @@ func toString(v any) string {
 	return s
 }
 
-// bsonRawToKeyFields converts a bson.Raw key document to ordered []KeyField.
-// Handles numeric directions (1, -1) and string index types ("text", "2dsphere",
 // InspectSecurity queries server parameters and command-line options to assess
 // security configuration. Requires admin access. Returns partial results on
 // permission errors rather than failing completely.
 func (i *Inspector) InspectSecurity(ctx context.Context) (SecurityInfo, error) {
-	var info SecurityInfo
+	p, err := i.InspectServerParameters(ctx)
+	return p.Security, err
+}
+
+// InspectServerParameters reads getParameter and getCmdLineOpts: the security
+// settings plus every scalar value, for comparison against a recommended
+// profile. When getCmdLineOpts is denied, only getParameter values are set.
+func (i *Inspector) InspectServerParameters(ctx context.Context) (ServerParameters, error) {
+	result := ServerParameters{Values: make(map[string]string)}
+	info := &result.Security
 
 	// getParameter: auth, TLS, localhost bypass.
 	paramResult := i.db.RunCommand(ctx, "admin", bson.D{{Key: "getParameter", Value: "*"}})
 	var params bson.M
 	if err := paramResult.Decode(&params); err != nil {
-		return info, fmt.Errorf("getParameter: %w", err)
+		return result, fmt.Errorf("getParameter: %w", err)
+	}
+	for k, v := range params {
+		if k == "ok" {
+			continue
+		}
+		if str, ok := paramString(v); ok {
+			result.Values[k] = str
+		}
 	}
 
 	// authenticationMechanisms: non-empty array means auth is active.
 	if mechs, ok := params["authenticationMechanisms"]; ok {
 		if arr, isArr := mechs.(bson.A); isArr && len(arr) > 0 {
 			info.AuthEnabled = true
+			for _, m := range arr {
+				if name := toString(m); name != "" {
+					info.AuthMechanisms = append(info.AuthMechanisms, name)
+				}
+			}
 		}
 	}
+	if providers, ok := params["oidcIdentityProviders"].(bson.A); ok {
+		info.OIDCProviders = parseOIDCProviders(providers)
+	}
 
 	if mode, ok := params["tlsMode"].(string); ok {
 		info.TLSMode = mode
@@ -1224,15 +1955,20 @@ func (i *Inspector) InspectSecurity(ctx context.Context) (SecurityInfo, error) {
 		info.TLSAllowInvalidCerts = allow
 	}
 
-	// getCmdLineOpts: bind IP, authorization, audit log.
+	info.DataKeys = i.readKeyVault(ctx)
+
+	// getCmdLineOpts: bind IP, authorization, audit log, encryption at rest.
 	cmdResult := i.db.RunCommand(ctx, "admin", bson.D{{Key: "getCmdLineOpts", Value: 1}})
 	var cmdOpts bson.M
 	if err := cmdResult.Decode(&cmdOpts); err != nil {
 		// Permission denied is common — return what we have from getParameter.
-		return info, nil //nolint:nilerr // partial results are acceptable
+		return result, nil //nolint:nilerr // partial results are acceptable
 	}
 
 	parsed := toBsonM(cmdOpts["parsed"])
+	result.CmdLineOpts = true
+	info.CmdLineOpts = true
+	flattenParams(parsed, "", result.Values)
 
 	// net.bindIp / net.bindIpAll
 	if netSection := toBsonM(parsed["net"]); netSection != nil {
@@ -1249,6 +1985,13 @@ func (i *Inspector) InspectSecurity(ctx context.Context) (SecurityInfo, error) {
 		if auth, ok := secSection["authorization"].(string); ok && auth == "enabled" {
 			info.AuthEnabled = true
 		}
+		if ldap := toBsonM(secSection["ldap"]); ldap != nil {
+			info.LDAP = parseLDAPConfig(ldap, params)
+		}
+		info.EncryptionAtRest = toBool(secSection["enableEncryption"])
+		if kmip := toBsonM(secSection["kmip"]); kmip != nil {
+			info.KMIPServer = toString(kmip["serverName"])
+		}
 	}
 
 	// auditLog.destination
@@ -1258,7 +2001,141 @@ func (i *Inspector) InspectSecurity(ctx context.Context) (SecurityInfo, error) {
 		}
 	}
 
-	return info, nil
+	return result, nil
+}
+
+const (
+	keyVaultDB         = "encryption"
+	keyVaultCollection = "__keyVault"
+	keyVaultLimit      = 1000
+)
+
+// readKeyVault lists CSFLE/Queryable Encryption data keys from the default
+// key vault, projecting out keyMaterial. A missing or unreadable key vault
+// yields no keys.
+func (i *Inspector) readKeyVault(ctx context.Context) []DataKeyInfo {
+	cmd := bson.D{
+		{Key: "find", Value: keyVaultCollection},
+		{Key: "projection", Value: bson.M{"keyMaterial": 0}},
+		{Key: "limit", Value: int64(keyVaultLimit)},
+		{Key: "batchSize", Value: int32(keyVaultLimit)},
+	}
+	var resp struct {
+		Cursor struct {
+			FirstBatch []bson.M `bson:"firstBatch"`
+		} `bson:"cursor"`
+	}
+	if err := i.db.RunCommand(ctx, keyVaultDB, cmd).Decode(&resp); err != nil {
+		return nil
+	}
+
+	keys := make([]DataKeyInfo, 0, len(resp.Cursor.FirstBatch))
+	for _, doc := range resp.Cursor.FirstBatch {
+		key := DataKeyInfo{
+			ID:       dataKeyID(doc["_id"]),
+			Provider: toString(toBsonM(doc["masterKey"])["provider"]),
+			Created:  toTime(doc["creationDate"]),
+			Updated:  toTime(doc["updateDate"]),
+		}
+		if names, ok := doc["keyAltNames"].(bson.A); ok {
+			for _, n := range names {
+				if name := toString(n); name != "" {
+					key.AltNames = append(key.AltNames, name)
+				}
+			}
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// dataKeyID renders a key vault _id, normally a UUID binary, as hex.
+func dataKeyID(v any) string {
+	if b, ok := v.(bson.Binary); ok {
+		return hex.EncodeToString(b.Data)
+	}
+	return fmt.Sprint(v)
+}
+
+// flattenParams stores the scalar settings of a getCmdLineOpts "parsed"
+// document under dotted paths such as storage.journal.enabled.
+func flattenParams(doc bson.M, prefix string, out map[string]string) {
+	for k, v := range doc {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		if sub := toBsonM(v); sub != nil {
+			flattenParams(sub, path, out)
+			continue
+		}
+		if str, ok := paramString(v); ok {
+			out[path] = str
+		}
+	}
+}
+
+// paramString renders a scalar parameter value; documents and arrays are
+// not comparable and return false.
+func paramString(v any) (string, bool) {
+	switch x := v.(type) {
+	case string:
+		return x, true
+	case bool:
+		return strconv.FormatBool(x), true
+	case int32:
+		return strconv.FormatInt(int64(x), 10), true
+	case int64:
+		return strconv.FormatInt(x, 10), true
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64), true
+	}
+	return "", false
+}
+
+// parseLDAPConfig reads security.ldap from getCmdLineOpts. The bind
+// section is skipped entirely so the query password is never touched.
+func parseLDAPConfig(ldap, params bson.M) *LDAPConfig {
+	cfg := &LDAPConfig{
+		Servers:              toString(ldap["servers"]),
+		TransportSecurity:    "tls",
+		ValidateServerConfig: true,
+		UserToDNMapping:      toString(ldap["userToDNMapping"]),
+	}
+	if ts := toString(ldap["transportSecurity"]); ts != "" {
+		cfg.TransportSecurity = ts
+	}
+	if v, ok := ldap["validateLDAPServerConfig"].(bool); ok {
+		cfg.ValidateServerConfig = v
+	}
+	if authz := toBsonM(ldap["authz"]); authz != nil {
+		cfg.AuthzQueryTemplate = toString(authz["queryTemplate"])
+	}
+	cfg.CacheInvalidationSecs = toInt64(params["ldapUserCacheInvalidationInterval"])
+	return cfg
+}
+
+// parseOIDCProviders reads the oidcIdentityProviders server parameter.
+// useAuthorizationClaim defaults to true, as on the server.
+func parseOIDCProviders(providers bson.A) []OIDCProvider {
+	out := make([]OIDCProvider, 0, len(providers))
+	for _, p := range providers {
+		doc := toBsonM(p)
+		if doc == nil {
+			continue
+		}
+		provider := OIDCProvider{
+			Issuer:                toString(doc["issuer"]),
+			Audience:              toString(doc["audience"]),
+			AuthorizationClaim:    toString(doc["authorizationClaim"]),
+			UseAuthorizationClaim: true,
+		}
+		if v, ok := doc["useAuthorizationClaim"].(bool); ok {
+			provider.UseAuthorizationClaim = v
+		}
+		out = append(out, provider)
+	}
+	return out
 }
 
 // InspectReplicaSet queries replSetGetStatus, replSetGetConfig, and oplog
@@ -1398,7 +2275,9 @@ func toFloat64(v any) float64 {
 	}
 }
 
-// "2d", "hashed") which are stored as Direction=0 (non-directional).
+// bsonRawToKeyFields converts an index key document to ordered key fields.
+// Non-directional keys ("text", "2dsphere", "2d", "hashed") have Direction 0
+// and their type in Kind.
 func bsonRawToKeyFields(raw bson.Raw) []KeyField {
 	elems, err := raw.Elements()
 	if err != nil {
@@ -1411,9 +2290,9 @@ func bsonRawToKeyFields(raw bson.Raw) []KeyField {
 		switch v.Type {
 		case bson.TypeInt32, bson.TypeInt64, bson.TypeDouble:
 			kf.Direction = int(v.AsInt64())
-		default:
+		case bson.TypeString:
 			// text, 2dsphere, 2d, hashed — non-directional
-			kf.Direction = 0
+			kf.Kind = v.StringValue()
 		}
 		fields = append(fields, kf)
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -14,19 +15,22 @@ import (
 
 // mockClient implements dbClient for unit tests.
 type mockClient struct {
-	pingErr       error
-	disconnectErr error
-	listDBsResult mongo.ListDatabasesResult
-	listDBsErr    error
-	collSpecs     []mongo.CollectionSpecification
-	collSpecsErr  error
-	runCmdResult  bson.Raw
-	runCmdErr     error
-	runCmdHook    func(dbName string, cmd any) (bson.Raw, error)
-	indexSpecs    []mongo.IndexSpecification
-	indexSpecsErr error
-	aggregateErr  error
-	aggregateData []bson.M
+	pingErr        error
+	disconnectErr  error
+	listDBsResult  mongo.ListDatabasesResult
+	listDBsErr     error
+	collSpecs      []mongo.CollectionSpecification
+	collSpecsErr   error
+	runCmdResult   bson.Raw
+	runCmdErr      error
+	runCmdHook     func(dbName string, cmd any) (bson.Raw, error)
+	indexDocs      []bson.D
+	indexDocsErr   error
+	aggregateErr   error
+	aggregateData  []bson.M
+	watchErr       error
+	watchEvents    []bson.M
+	watchStreamErr error
 }
 
 func (m *mockClient) Ping(ctx context.Context) error {
@@ -61,8 +65,19 @@ func (m *mockClient) RunCommand(ctx context.Context, dbName string, cmd any) *mo
 	return mongo.NewSingleResultFromDocument(m.runCmdResult, nil, nil)
 }
 
-func (m *mockClient) ListIndexSpecs(ctx context.Context, dbName, collName string) ([]mongo.IndexSpecification, error) {
-	return m.indexSpecs, m.indexSpecsErr
+func (m *mockClient) ListIndexes(ctx context.Context, dbName, collName string) ([]bson.Raw, error) {
+	if m.indexDocsErr != nil {
+		return nil, m.indexDocsErr
+	}
+	docs := make([]bson.Raw, 0, len(m.indexDocs))
+	for _, d := range m.indexDocs {
+		raw, err := bson.Marshal(d)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, raw)
+	}
+	return docs, nil
 }
 
 func (m *mockClient) Aggregate(ctx context.Context, dbName, collName string, pipeline any) (*mongo.Cursor, error) {
@@ -80,6 +95,45 @@ func (m *mockClient) Aggregate(ctx context.Context, dbName, collName string, pip
 	return cursor, nil
 }
 
+func (m *mockClient) Watch(ctx context.Context, dbName string, pipeline any) (changeStream, error) {
+	if m.watchErr != nil {
+		return nil, m.watchErr
+	}
+	return &mockChangeStream{events: m.watchEvents, err: m.watchStreamErr}, nil
+}
+
+// mockChangeStream replays events, then blocks until the context is done
+// the way an idle change stream does, unless err is set.
+type mockChangeStream struct {
+	events []bson.M
+	cur    bson.M
+	err    error
+}
+
+func (s *mockChangeStream) Next(ctx context.Context) bool {
+	if len(s.events) > 0 {
+		s.cur, s.events = s.events[0], s.events[1:]
+		return true
+	}
+	if s.err == nil {
+		<-ctx.Done()
+		s.err = ctx.Err()
+	}
+	return false
+}
+
+func (s *mockChangeStream) Decode(val any) error {
+	raw, err := bson.Marshal(s.cur)
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(raw, val)
+}
+
+func (s *mockChangeStream) Err() error { return s.err }
+
+func (s *mockChangeStream) Close(context.Context) error { return nil }
+
 func TestToInt64(t *testing.T) {
 	tests := []struct {
 		name string
@@ -195,8 +249,8 @@ func TestBsonRawToKeyFields_MixedTypes(t *testing.T) {
 	}
 	want := []KeyField{
 		{Field: "status", Direction: 1},
-		{Field: "content", Direction: 0},
-		{Field: "location", Direction: 0},
+		{Field: "content", Kind: "text"},
+		{Field: "location", Kind: "2dsphere"},
 		{Field: "created_at", Direction: -1},
 	}
 	for i, w := range want {
@@ -311,6 +365,32 @@ func TestToBsonM(t *testing.T) {
 	}
 }
 
+func TestFlattenParams(t *testing.T) {
+	doc := bson.M{
+		"storage": bson.D{
+			{Key: "dbPath", Value: "/data/db"},
+			{Key: "journal", Value: bson.M{"enabled": false}},
+		},
+		"operationProfiling": bson.M{"slowOpThresholdMs": int32(250)},
+		"net":                bson.M{"bindIp": bson.A{"127.0.0.1"}},
+	}
+	out := make(map[string]string)
+	flattenParams(doc, "", out)
+	want := map[string]string{
+		"storage.dbPath":                       "/data/db",
+		"storage.journal.enabled":              "false",
+		"operationProfiling.slowOpThresholdMs": "250",
+	}
+	if len(out) != len(want) {
+		t.Fatalf("flattenParams = %v, want %v", out, want)
+	}
+	for k, v := range want {
+		if out[k] != v {
+			t.Errorf("%s = %q, want %q", k, out[k], v)
+		}
+	}
+}
+
 func TestListDatabases_SpecificDB(t *testing.T) {
 	insp := &Inspector{db: &mockClient{}}
 	dbs, err := insp.ListDatabases(context.TODO(), "mydb")
@@ -468,6 +548,42 @@ func TestGetValidators_InvalidOptionsIgnored(t *testing.T) {
 	}
 }
 
+func TestListCollections_EncryptedFields(t *testing.T) {
+	options, err := bson.Marshal(bson.M{
+		"encryptedFields": bson.M{
+			"fields": bson.A{
+				bson.M{"path": "ssn", "bsonType": "string", "queries": bson.M{"queryType": "equality"}},
+				bson.M{"path": "billing.card", "bsonType": "string"},
+				bson.M{"path": "dob", "bsonType": "date", "queries": bson.A{bson.M{"queryType": "range", "min": 0}}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mc := &mockClient{
+		collSpecs: []mongo.CollectionSpecification{
+			{Name: "patients", Type: "collection", Options: options},
+			{Name: "events", Type: "collection"},
+		},
+	}
+	insp := &Inspector{db: mc}
+
+	colls, err := insp.ListCollections(context.TODO(), "medical")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := colls[0].EncryptedFields; !reflect.DeepEqual(got, []string{"billing.card", "dob", "ssn"}) {
+		t.Errorf("patients encryptedFields = %v", got)
+	}
+	if got := colls[0].EncryptedQueries; !reflect.DeepEqual(got, map[string][]string{"ssn": {"equality"}, "dob": {"range"}}) {
+		t.Errorf("patients encryptedQueries = %v", got)
+	}
+	if colls[1].EncryptedFields != nil {
+		t.Errorf("events encryptedFields = %v, want nil", colls[1].EncryptedFields)
+	}
+}
+
 func TestGetCollectionStats(t *testing.T) {
 	raw, _ := bson.Marshal(bson.M{
 		"count":       int64(1000),
@@ -510,6 +626,73 @@ func TestGetCollectionStats_Error(t *testing.T) {
 	}
 }
 
+func TestGetCollectionStats_CollStatsStage(t *testing.T) {
+	shard := func(count, size int64, readHist bson.A) bson.M {
+		return bson.M{
+			"storageStats": bson.M{
+				"count":          count,
+				"size":           size,
+				"storageSize":    size / 2,
+				"totalIndexSize": int64(100),
+				"indexSizes":     bson.M{"_id_": int64(60), "email_1": int64(40)},
+				"wiredTiger":     bson.M{"block-manager": bson.M{"file bytes available for reuse": int64(500)}},
+				"indexDetails": bson.M{
+					"_id_":    bson.M{"block-manager": bson.M{"file bytes available for reuse": int64(20)}},
+					"email_1": bson.M{"block-manager": bson.M{"file bytes available for reuse": int64(10)}},
+				},
+			},
+			"latencyStats": bson.M{
+				"reads":  bson.M{"latency": int64(5000), "ops": int64(10), "histogram": readHist},
+				"writes": bson.M{"latency": int64(0), "ops": int64(0), "histogram": bson.A{}},
+			},
+		}
+	}
+	mc := &mockClient{aggregateData: []bson.M{
+		shard(100, 4000, bson.A{bson.M{"micros": int64(128), "count": int64(6)}, bson.M{"micros": int64(1024), "count": int64(4)}}),
+		shard(300, 8000, bson.A{bson.M{"micros": int64(1024), "count": int64(10)}}),
+	}}
+	insp := &Inspector{db: mc}
+	info, indexSizes, err := insp.GetCollectionStats(context.TODO(), "app", "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.DocCount != 400 || info.Size != 12000 || info.AvgObjSize != 30 || info.StorageSize != 6000 || info.TotalIndexSize != 200 {
+		t.Errorf("summed stats = %+v", info)
+	}
+	if indexSizes["_id_"] != 120 || indexSizes["email_1"] != 80 {
+		t.Errorf("index sizes = %v", indexSizes)
+	}
+	if info.ReusableBytes != 1000 || info.IndexReusableBytes != 60 {
+		t.Errorf("reusable bytes = %d, indexes %d; want 1000 and 60", info.ReusableBytes, info.IndexReusableBytes)
+	}
+	if info.Latency == nil {
+		t.Fatal("expected latency stats")
+	}
+	reads := info.Latency.Reads
+	if reads.Ops != 20 || reads.TotalMicros != 10000 {
+		t.Errorf("reads = %+v", reads)
+	}
+	if len(reads.Histogram) != 2 || reads.Histogram[0].Micros != 128 || reads.Histogram[1].Count != 14 {
+		t.Errorf("merged histogram = %+v", reads.Histogram)
+	}
+}
+
+func TestGetCollectionStats_FallsBackToCommand(t *testing.T) {
+	raw, _ := bson.Marshal(bson.M{
+		"count": int64(7), "size": int64(70),
+		"wiredTiger": bson.M{"block-manager": bson.M{"file bytes available for reuse": int64(4096)}},
+	})
+	mc := &mockClient{aggregateErr: errors.New("unrecognized pipeline stage name: '$collStats'"), runCmdResult: raw}
+	insp := &Inspector{db: mc}
+	info, _, err := insp.GetCollectionStats(context.TODO(), "app", "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.DocCount != 7 || info.Latency != nil || info.ReusableBytes != 4096 {
+		t.Errorf("fallback stats = %+v", info)
+	}
+}
+
 func TestGetCollectionStats_IndexSizes(t *testing.T) {
 	raw, _ := bson.Marshal(bson.M{
 		"count":          int64(500),
@@ -547,20 +730,17 @@ func TestGetCollectionStats_IndexSizes(t *testing.T) {
 }
 
 func TestGetIndexes(t *testing.T) {
-	keyDoc, _ := bson.Marshal(bson.D{{Key: "email", Value: 1}})
-	unique := true
-	sparse := true
-	ttlSec := int32(3600)
 	mc := &mockClient{
-		indexSpecs: []mongo.IndexSpecification{
-			{
-				Name:               "email_1",
-				KeysDocument:       keyDoc,
-				Unique:             &unique,
-				Sparse:             &sparse,
-				ExpireAfterSeconds: &ttlSec,
-			},
-		},
+		indexDocs: []bson.D{{
+			{Key: "name", Value: "email_1"},
+			{Key: "key", Value: bson.D{{Key: "email", Value: 1}}},
+			{Key: "unique", Value: true},
+			{Key: "sparse", Value: true},
+			{Key: "hidden", Value: true},
+			{Key: "expireAfterSeconds", Value: int32(3600)},
+			{Key: "partialFilterExpression", Value: bson.D{{Key: "deleted", Value: false}}},
+			{Key: "collation", Value: bson.D{{Key: "locale", Value: "en"}, {Key: "strength", Value: int32(2)}}},
+		}},
 	}
 	insp := &Inspector{db: mc}
 	indexes, err := insp.GetIndexes(context.TODO(), "app", "users")
@@ -574,8 +754,8 @@ func TestGetIndexes(t *testing.T) {
 	if idx.Name != "email_1" {
 		t.Errorf("name = %s", idx.Name)
 	}
-	if !idx.Unique || !idx.Sparse {
-		t.Errorf("unique=%v sparse=%v", idx.Unique, idx.Sparse)
+	if !idx.Unique || !idx.Sparse || !idx.Hidden {
+		t.Errorf("unique=%v sparse=%v hidden=%v", idx.Unique, idx.Sparse, idx.Hidden)
 	}
 	if idx.TTL == nil || *idx.TTL != 3600 {
 		t.Errorf("ttl = %v", idx.TTL)
@@ -583,14 +763,17 @@ func TestGetIndexes(t *testing.T) {
 	if len(idx.Key) != 1 || idx.Key[0].Field != "email" {
 		t.Errorf("key = %+v", idx.Key)
 	}
+	if idx.PartialFilter != `{"deleted":false}` {
+		t.Errorf("partialFilter = %q", idx.PartialFilter)
+	}
+	if idx.Collation != `{"locale":"en","strength":2}` {
+		t.Errorf("collation = %q", idx.Collation)
+	}
 }
 
 func TestGetIndexes_NoOptionalFields(t *testing.T) {
-	keyDoc, _ := bson.Marshal(bson.D{{Key: "name", Value: 1}})
 	mc := &mockClient{
-		indexSpecs: []mongo.IndexSpecification{
-			{Name: "name_1", KeysDocument: keyDoc},
-		},
+		indexDocs: []bson.D{{{Key: "name", Value: "name_1"}, {Key: "key", Value: bson.D{{Key: "name", Value: 1}}}}},
 	}
 	insp := &Inspector{db: mc}
 	indexes, err := insp.GetIndexes(context.TODO(), "app", "users")
@@ -604,7 +787,7 @@ func TestGetIndexes_NoOptionalFields(t *testing.T) {
 }
 
 func TestGetIndexes_Error(t *testing.T) {
-	mc := &mockClient{indexSpecsErr: errors.New("fail")}
+	mc := &mockClient{indexDocsErr: errors.New("fail")}
 	insp := &Inspector{db: mc}
 	_, err := insp.GetIndexes(context.TODO(), "app", "users")
 	if err == nil {
@@ -699,7 +882,6 @@ func TestClose_Error(t *testing.T) {
 }
 
 func TestInspect_SpecificDB(t *testing.T) {
-	keyDoc, _ := bson.Marshal(bson.D{{Key: "_id", Value: 1}})
 	statsRaw, _ := bson.Marshal(bson.M{
 		"count": int64(500), "size": int64(10000), "avgObjSize": int64(20), "storageSize": int64(15000),
 	})
@@ -710,9 +892,7 @@ func TestInspect_SpecificDB(t *testing.T) {
 			{Name: "user_view", Type: "view"},
 		},
 		runCmdResult: statsRaw,
-		indexSpecs: []mongo.IndexSpecification{
-			{Name: "_id_", KeysDocument: keyDoc},
-		},
+		indexDocs:    []bson.D{{{Key: "name", Value: "_id_"}, {Key: "key", Value: bson.D{{Key: "_id", Value: 1}}}}},
 		aggregateData: []bson.M{
 			{"name": "_id_", "accesses": bson.M{"ops": int64(100), "since": since}},
 		},
@@ -748,6 +928,29 @@ func TestInspect_SpecificDB(t *testing.T) {
 	}
 }
 
+func TestInspect_Quick(t *testing.T) {
+	mc := &mockClient{
+		collSpecs: []mongo.CollectionSpecification{{Name: "users", Type: "collection"}},
+		runCmdHook: func(_ string, cmd any) (bson.Raw, error) {
+			t.Errorf("quick inspect ran a command: %v", cmd)
+			return nil, errors.New("unexpected command")
+		},
+		indexDocs:    []bson.D{{{Key: "name", Value: "_id_"}, {Key: "key", Value: bson.D{{Key: "_id", Value: 1}}}}},
+		aggregateErr: errors.New("quick inspect ran an aggregation"),
+	}
+	insp := &Inspector{db: mc, quick: true}
+	colls, err := insp.Inspect(context.TODO(), "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(colls) != 1 || len(colls[0].Indexes) != 1 {
+		t.Fatalf("collections = %+v", colls)
+	}
+	if colls[0].DocCount != 0 || colls[0].Indexes[0].Stats != nil {
+		t.Errorf("quick inspect has stats: %+v", colls[0])
+	}
+}
+
 func TestInspect_ListCollectionsError(t *testing.T) {
 	mc := &mockClient{collSpecsErr: errors.New("fail")}
 	insp := &Inspector{db: mc}
@@ -762,8 +965,8 @@ func TestInspect_StatsErrorGraceful(t *testing.T) {
 		collSpecs: []mongo.CollectionSpecification{
 			{Name: "users", Type: "collection"},
 		},
-		runCmdErr:     errors.New("stats fail"),
-		indexSpecsErr: errors.New("index fail"),
+		runCmdErr:    errors.New("stats fail"),
+		indexDocsErr: errors.New("index fail"),
 	}
 	insp := &Inspector{db: mc}
 	colls, err := insp.Inspect(context.TODO(), "app")
@@ -805,6 +1008,10 @@ func TestInspectUsers(t *testing.T) {
 					bson.M{"role": "readWrite", "db": "myapp"},
 					bson.M{"role": "read", "db": "reporting"},
 				},
+				"mechanisms": bson.A{"SCRAM-SHA-1", "SCRAM-SHA-256"},
+				"authenticationRestrictions": bson.A{
+					bson.M{"clientSource": bson.A{"10.0.0.0/8"}},
+				},
 			},
 		},
 		"ok": 1,
@@ -835,6 +1042,54 @@ func TestInspectUsers(t *testing.T) {
 	if len(users[1].Roles) != 2 {
 		t.Errorf("expected 2 roles for appUser, got %d", len(users[1].Roles))
 	}
+	if len(users[1].Mechanisms) != 2 || users[1].Mechanisms[1] != "SCRAM-SHA-256" {
+		t.Errorf("users[1].Mechanisms = %v", users[1].Mechanisms)
+	}
+	if len(users[1].AuthenticationRestrictions) != 1 || users[1].AuthenticationRestrictions[0].ClientSource[0] != "10.0.0.0/8" {
+		t.Errorf("users[1].AuthenticationRestrictions = %+v", users[1].AuthenticationRestrictions)
+	}
+}
+
+func TestInspectRoles(t *testing.T) {
+	raw, err := bson.Marshal(bson.M{
+		"roles": bson.A{
+			bson.M{
+				"role":      "opsAdmin",
+				"db":        "admin",
+				"isBuiltin": false,
+				"roles":     bson.A{bson.M{"role": "reportReader", "db": "app"}},
+				"privileges": bson.A{
+					bson.M{"resource": bson.M{"cluster": true}, "actions": bson.A{"serverStatus"}},
+				},
+				"inheritedPrivileges": bson.A{
+					bson.M{"resource": bson.M{"cluster": true}, "actions": bson.A{"serverStatus"}},
+					bson.M{"resource": bson.M{"db": "app", "collection": ""}, "actions": bson.A{"find"}},
+				},
+			},
+		},
+		"ok": 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	insp := &Inspector{db: &mockClient{runCmdResult: raw}}
+	roles, err := insp.InspectRoles(context.TODO(), "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roles) != 1 {
+		t.Fatalf("expected 1 role, got %d", len(roles))
+	}
+	r := roles[0]
+	if r.Role != "opsAdmin" || r.Database != "admin" || len(r.Roles) != 1 || r.Roles[0].Role != "reportReader" {
+		t.Errorf("role = %+v", r)
+	}
+	if len(r.Privileges) != 1 || !r.Privileges[0].Resource.Cluster {
+		t.Errorf("privileges = %+v", r.Privileges)
+	}
+	if len(r.InheritedPrivileges) != 2 || r.InheritedPrivileges[1].Resource.DB != "app" || r.InheritedPrivileges[1].Actions[0] != "find" {
+		t.Errorf("inheritedPrivileges = %+v", r.InheritedPrivileges)
+	}
 }
 
 func TestInspectUsers_Empty(t *testing.T) {
@@ -1030,9 +1285,12 @@ func TestReadProfiler(t *testing.T) {
 								"sort":       bson.M{"created_at": -1},
 								"projection": bson.M{"email": 1},
 							},
-							"millis":      int64(850),
-							"ts":          bson.DateTime(older.UnixMilli()),
-							"planSummary": "COLLSCAN",
+							"millis":       int64(850),
+							"ts":           bson.DateTime(older.UnixMilli()),
+							"planSummary":  "COLLSCAN",
+							"docsExamined": int64(50000),
+							"keysExamined": int64(0),
+							"nreturned":    int32(12),
 						},
 						{
 							"ns": "app.users",
@@ -1043,6 +1301,7 @@ func TestReadProfiler(t *testing.T) {
 							"durationMillis": int64(120),
 							"ts":             bson.DateTime(newest.UnixMilli()),
 							"planSummary":    "IXSCAN { email: 1 }",
+							"appName":        "accounts-api",
 						},
 					},
 				},
@@ -1068,6 +1327,9 @@ func TestReadProfiler(t *testing.T) {
 	if !containsString(entries[0].FilterFields, "email") {
 		t.Fatalf("entries[0] filter fields = %v, want email", entries[0].FilterFields)
 	}
+	if entries[0].AppName != "accounts-api" {
+		t.Fatalf("entries[0] appName = %q, want accounts-api", entries[0].AppName)
+	}
 
 	if entries[1].DurationMillis != 850 {
 		t.Fatalf("entries[1] duration = %d, want 850", entries[1].DurationMillis)
@@ -1081,6 +1343,9 @@ func TestReadProfiler(t *testing.T) {
 	if !containsString(entries[1].ProjectionFields, "email") {
 		t.Fatalf("entries[1] projection fields = %v, want email", entries[1].ProjectionFields)
 	}
+	if entries[1].DocsExamined != 50000 || entries[1].NReturned != 12 {
+		t.Fatalf("entries[1] docsExamined/nreturned = %d/%d, want 50000/12", entries[1].DocsExamined, entries[1].NReturned)
+	}
 }
 
 func TestReadProfiler_ProfilerDisabled(t *testing.T) {
@@ -1308,6 +1573,69 @@ func TestSampleDocuments(t *testing.T) {
 	}
 }
 
+func TestSampleDocuments_MemoryCap(t *testing.T) {
+	prev := sampleMemoryLimit
+	t.Cleanup(func() { sampleMemoryLimit = prev })
+
+	docs := make([]bson.M, 5)
+	for j := range docs {
+		docs[j] = bson.M{"n": int32(j), "pad": strings.Repeat("x", 100)}
+	}
+	raw, _ := bson.Marshal(docs[0])
+	// Room for two documents only.
+	sampleMemoryLimit = int64(2*len(raw) + 1)
+
+	mc := &mockClient{
+		collSpecs:     []mongo.CollectionSpecification{{Name: "events", Type: "collection"}},
+		aggregateData: docs,
+	}
+	insp := &Inspector{db: mc}
+
+	results, err := insp.SampleDocuments(context.Background(), "app", 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	if r := results[0]; r.SampleSize != 2 || !r.MemoryCapped {
+		t.Errorf("SampleSize = %d, MemoryCapped = %v, want 2 and true", r.SampleSize, r.MemoryCapped)
+	}
+}
+
+func TestSampleDocuments_DateRanges(t *testing.T) {
+	older := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	newer := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	updated := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	mc := &mockClient{
+		collSpecs: []mongo.CollectionSpecification{{Name: "sessions", Type: "collection"}},
+		aggregateData: []bson.M{
+			{"_id": bson.NewObjectIDFromTimestamp(newer), "updatedAt": bson.NewDateTimeFromTime(updated), "expires": bson.NewDateTimeFromTime(updated)},
+			{"_id": bson.NewObjectIDFromTimestamp(older), "updatedAt": bson.NewDateTimeFromTime(older)},
+			{"_id": "custom-key", "userId": bson.NewObjectIDFromTimestamp(older.AddDate(-1, 0, 0))},
+		},
+	}
+	insp := &Inspector{db: mc}
+
+	results, err := insp.SampleDocuments(context.Background(), "app", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DateRange{
+		{Field: "_id", Oldest: older, Newest: newer},
+		{Field: "updatedAt", Oldest: older, Newest: updated},
+	}
+	if got := results[0].DateRanges; !reflect.DeepEqual(got, want) {
+		t.Errorf("DateRanges = %+v\nwant %+v", got, want)
+	}
+	if age := results[0].Age(); age == nil || !age.Oldest.Equal(older) || !age.Newest.Equal(updated) {
+		t.Errorf("Age = %+v", age)
+	}
+	if got := results[0].CreatedByYear; !reflect.DeepEqual(got, map[int]int64{2021: 1, 2024: 1}) {
+		t.Errorf("CreatedByYear = %v", got)
+	}
+}
+
 func TestSampleDocuments_Empty(t *testing.T) {
 	mc := &mockClient{
 		listDBsResult: mongo.ListDatabasesResult{
@@ -1367,3 +1695,309 @@ func TestSampleDocuments_NoDatabases(t *testing.T) {
 		t.Errorf("expected nil for no databases, got %v", results)
 	}
 }
+
+func TestWalkDottedKeys(t *testing.T) {
+	doc := bson.M{
+		"plain": "x",
+		"a.b":   int32(1),
+		"meta": bson.D{
+			{Key: "geo.lat", Value: 1.5},
+			{Key: "items", Value: bson.A{bson.M{"x.y": true}}},
+		},
+	}
+	out := make(map[string]bool)
+	walkDottedKeys(doc, "", out)
+
+	got := sortedKeys(out)
+	want := []string{"a.b", "meta.geo.lat", "meta.items[].x.y"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("dotted keys = %v, want %v", got, want)
+	}
+}
+
+func TestSampleDocuments_BSONSizeMax(t *testing.T) {
+	// The mock returns the same documents for every aggregation, so the
+	// $bsonSize $group result is read from the maxSize field.
+	mc := &mockClient{
+		listDBsResult: mongo.ListDatabasesResult{
+			Databases: []mongo.DatabaseSpecification{{Name: "testdb"}},
+		},
+		collSpecs:     []mongo.CollectionSpecification{{Name: "blobs", Type: "collection"}},
+		aggregateData: []bson.M{{"maxSize": int64(12 << 20)}},
+	}
+	insp := &Inspector{db: mc}
+
+	results, err := insp.SampleDocuments(context.Background(), "testdb", 10)
+	if err != nil {
+		t.Fatalf("SampleDocuments: %v", err)
+	}
+	if len(results) != 1 || results[0].MaxDocSize != 12<<20 {
+		t.Fatalf("MaxDocSize = %v, want %d", results, 12<<20)
+	}
+}
+
+func TestProfileEntryFromDoc_BlockingSort(t *testing.T) {
+	doc := bson.M{
+		"ns": "app.orders",
+		"command": bson.D{
+			{Key: "find", Value: "orders"},
+			{Key: "filter", Value: bson.D{{Key: "status", Value: "open"}}},
+			{Key: "sort", Value: bson.D{{Key: "priority", Value: int32(-1)}, {Key: "created_at", Value: int32(1)}, {Key: "score", Value: bson.D{{Key: "$meta", Value: "textScore"}}}}},
+		},
+		"execStats": bson.D{
+			{Key: "stage", Value: "PROJECTION_SIMPLE"},
+			{Key: "inputStage", Value: bson.D{
+				{Key: "stage", Value: "SORT"},
+				{Key: "spills", Value: int64(3)},
+				{Key: "inputStage", Value: bson.D{{Key: "stage", Value: "COLLSCAN"}}},
+			}},
+		},
+	}
+
+	entry, ok := profileEntryFromDoc("app", doc)
+	if !ok {
+		t.Fatal("profileEntryFromDoc returned !ok")
+	}
+	want := []KeyField{{Field: "priority", Direction: -1}, {Field: "created_at", Direction: 1}}
+	if len(entry.SortKeys) != 2 || entry.SortKeys[0] != want[0] || entry.SortKeys[1] != want[1] {
+		t.Errorf("SortKeys = %v, want %v", entry.SortKeys, want)
+	}
+	if !entry.HasSortStage || !entry.UsedDisk {
+		t.Errorf("HasSortStage/UsedDisk = %v/%v, want true/true", entry.HasSortStage, entry.UsedDisk)
+	}
+
+	plain, _ := profileEntryFromDoc("app", bson.M{"ns": "app.orders", "hasSortStage": true})
+	if !plain.HasSortStage || plain.UsedDisk {
+		t.Errorf("top-level hasSortStage: got %v/%v, want true/false", plain.HasSortStage, plain.UsedDisk)
+	}
+}
+
+func TestGetIndexes_WildcardAndText(t *testing.T) {
+	mc := &mockClient{
+		indexDocs: []bson.D{
+			{
+				{Key: "name", Value: "$**_1"},
+				{Key: "key", Value: bson.D{{Key: "$**", Value: 1}}},
+				{Key: "wildcardProjection", Value: bson.D{{Key: "attrs", Value: 1}}},
+			},
+			{
+				{Key: "name", Value: "title_text_body_text"},
+				{Key: "key", Value: bson.D{{Key: "_fts", Value: "text"}, {Key: "_ftsx", Value: 1}}},
+				{Key: "weights", Value: bson.D{{Key: "title", Value: 10}, {Key: "body", Value: 1}}},
+			},
+		},
+	}
+	insp := &Inspector{db: mc}
+	indexes, err := insp.GetIndexes(context.TODO(), "app", "docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(indexes) != 2 {
+		t.Fatalf("expected 2, got %d", len(indexes))
+	}
+	if indexes[0].WildcardProjection != `{"attrs":1}` {
+		t.Errorf("wildcardProjection = %q", indexes[0].WildcardProjection)
+	}
+	if got := indexes[1].TextFields; len(got) != 2 || got[0] != "body" || got[1] != "title" {
+		t.Errorf("textFields = %v", got)
+	}
+	if indexes[1].Key[0].Field != "_fts" || indexes[1].Key[0].Direction != 0 {
+		t.Errorf("key = %+v", indexes[1].Key)
+	}
+}
+
+func TestCountDuplicateKeys(t *testing.T) {
+	mc := &mockClient{aggregateData: []bson.M{{"_id": nil, "extra": int32(7)}}}
+	insp := &Inspector{db: mc}
+	n, err := insp.CountDuplicateKeys(context.TODO(), "app", "users", []string{"email", "profile.tenant"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 7 {
+		t.Errorf("duplicates = %d, want 7", n)
+	}
+
+	mc.aggregateData = nil
+	if n, err := insp.CountDuplicateKeys(context.TODO(), "app", "users", []string{"email"}); err != nil || n != 0 {
+		t.Errorf("no duplicate groups: n=%d err=%v", n, err)
+	}
+
+	mc.aggregateErr = errors.New("boom")
+	if _, err := insp.CountDuplicateKeys(context.TODO(), "app", "users", []string{"email"}); err == nil {
+		t.Error("expected error")
+	}
+}
+
+func TestInspectSecurity_ExternalAuth(t *testing.T) {
+	mc := &mockClient{
+		runCmdHook: func(dbName string, cmd any) (bson.Raw, error) {
+			switch cmd.(bson.D)[0].Key {
+			case "getParameter":
+				return bson.Marshal(bson.M{
+					"authenticationMechanisms":          bson.A{"PLAIN", "MONGODB-OIDC"},
+					"ldapUserCacheInvalidationInterval": int32(600),
+					"oidcIdentityProviders": bson.A{
+						bson.M{"issuer": "https://idp.example.com", "audience": "mongodb", "useAuthorizationClaim": false},
+					},
+					"ok": 1,
+				})
+			case "getCmdLineOpts":
+				return bson.Marshal(bson.M{
+					"parsed": bson.M{
+						"security": bson.M{
+							"authorization": "enabled",
+							"ldap": bson.M{
+								"servers":           "ldap.example.com",
+								"transportSecurity": "none",
+								"bind":              bson.M{"queryUser": "cn=svc", "queryPassword": "<password>"},
+								"authz":             bson.M{"queryTemplate": "{USER}?memberOf?base"},
+							},
+						},
+					},
+					"ok": 1,
+				})
+			}
+			return nil, errors.New("unexpected command")
+		},
+	}
+	info, err := (&Inspector{db: mc}).InspectSecurity(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.AuthMechanisms) != 2 || info.AuthMechanisms[1] != "MONGODB-OIDC" {
+		t.Errorf("AuthMechanisms = %v", info.AuthMechanisms)
+	}
+	if info.LDAP == nil {
+		t.Fatal("expected LDAP config")
+	}
+	want := LDAPConfig{
+		Servers:               "ldap.example.com",
+		TransportSecurity:     "none",
+		ValidateServerConfig:  true,
+		AuthzQueryTemplate:    "{USER}?memberOf?base",
+		CacheInvalidationSecs: 600,
+	}
+	if *info.LDAP != want {
+		t.Errorf("LDAP = %+v, want %+v", *info.LDAP, want)
+	}
+	if len(info.OIDCProviders) != 1 || info.OIDCProviders[0].Audience != "mongodb" || info.OIDCProviders[0].UseAuthorizationClaim {
+		t.Errorf("OIDCProviders = %+v", info.OIDCProviders)
+	}
+}
+
+func TestInspectSecurity_EncryptionAndKeyVault(t *testing.T) {
+	created := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	var keyVaultCmd bson.D
+	mc := &mockClient{
+		runCmdHook: func(dbName string, cmd any) (bson.Raw, error) {
+			command := cmd.(bson.D)
+			switch command[0].Key {
+			case "getParameter":
+				return bson.Marshal(bson.M{"ok": 1})
+			case "find":
+				keyVaultCmd = command
+				return bson.Marshal(bson.M{
+					"cursor": bson.M{"firstBatch": bson.A{
+						bson.M{
+							"_id":          bson.Binary{Subtype: 4, Data: []byte{0xab, 0xcd}},
+							"keyAltNames":  bson.A{"billing"},
+							"creationDate": bson.NewDateTimeFromTime(created),
+							"updateDate":   bson.NewDateTimeFromTime(created),
+							"masterKey":    bson.M{"provider": "kmip", "keyId": "1"},
+						},
+					}},
+					"ok": 1,
+				})
+			case "getCmdLineOpts":
+				return bson.Marshal(bson.M{
+					"parsed": bson.M{"security": bson.M{
+						"enableEncryption": true,
+						"kmip":             bson.M{"serverName": "kmip.example.com"},
+					}},
+					"ok": 1,
+				})
+			}
+			return nil, errors.New("unexpected command")
+		},
+	}
+	info, err := (&Inspector{db: mc}).InspectSecurity(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.CmdLineOpts || !info.EncryptionAtRest || info.KMIPServer != "kmip.example.com" {
+		t.Errorf("encryption = %v, kmip = %q", info.EncryptionAtRest, info.KMIPServer)
+	}
+	if toString(lookupBSONValue(keyVaultCmd, "find")) != "__keyVault" {
+		t.Errorf("key vault command = %v", keyVaultCmd)
+	}
+	if proj := toBsonM(lookupBSONValue(keyVaultCmd, "projection")); proj["keyMaterial"] != 0 {
+		t.Errorf("key vault query must project out keyMaterial, got %v", proj)
+	}
+	if len(info.DataKeys) != 1 {
+		t.Fatalf("DataKeys = %+v", info.DataKeys)
+	}
+	k := info.DataKeys[0]
+	if k.ID != "abcd" || k.Provider != "kmip" || k.AltNames[0] != "billing" || !k.Updated.Equal(created) {
+		t.Errorf("data key = %+v", k)
+	}
+}
+
+func TestInspect_DeadlineReturnsPartial(t *testing.T) {
+	mc := &mockClient{
+		collSpecs: []mongo.CollectionSpecification{{Name: "users", Type: "collection"}},
+	}
+	insp := &Inspector{db: mc}
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	colls, err := insp.Inspect(ctx, "app")
+	var partial *PartialError
+	if !errors.As(err, &partial) {
+		t.Fatalf("err = %v, want *PartialError", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want to wrap context.DeadlineExceeded", err)
+	}
+	if len(colls) != 0 {
+		t.Errorf("collections = %+v, want none", colls)
+	}
+	if len(partial.Skipped) != 1 || partial.Skipped[0] != "app" {
+		t.Errorf("skipped = %v, want [app]", partial.Skipped)
+	}
+}
+
+func TestGetValidators_DeadlineSkipsDatabase(t *testing.T) {
+	mc := &mockClient{collSpecsErr: context.DeadlineExceeded}
+	insp := &Inspector{db: mc}
+	ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	validators, err := insp.GetValidators(ctx, "app")
+	var partial *PartialError
+	if !errors.As(err, &partial) {
+		t.Fatalf("err = %v, want *PartialError", err)
+	}
+	if len(validators) != 0 || len(partial.Skipped) != 1 || partial.Skipped[0] != "app" {
+		t.Errorf("validators = %v, skipped = %v, want none and [app]", validators, partial.Skipped)
+	}
+}
+
+func TestInspect_ReportsProgress(t *testing.T) {
+	mc := &mockClient{
+		collSpecs: []mongo.CollectionSpecification{
+			{Name: "users", Type: "collection"},
+			{Name: "recent", Type: "view"},
+		},
+	}
+	var calls []string
+	insp := &Inspector{db: mc, progress: func(phase string, done, total int, ns string) {
+		calls = append(calls, fmt.Sprintf("%s %d/%d %s", phase, done, total, ns))
+	}}
+	if _, err := insp.Inspect(context.Background(), "app"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"inspect 0/2 app.users", "inspect 1/2 app.recent", "inspect 2/2 "}
+	if strings.Join(calls, "|") != strings.Join(want, "|") {
+		t.Errorf("progress = %q, want %q", calls, want)
+	}
+}
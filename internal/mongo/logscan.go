@@ -0,0 +1,94 @@
+package mongo
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// slowQueryLogID is the structured log id of "Slow query" entries.
+const slowQueryLogID = 51803
+
+// maxLogLineSize bounds one log line; slow query entries embed the whole
+// command, which can be large for bulk writes and long pipelines.
+const maxLogLineSize = 16 << 20
+
+// SlowQueryLog is the result of parsing a server log for slow queries.
+type SlowQueryLog struct {
+	Entries []ProfileEntry
+	// Lines is the number of lines read.
+	Lines int
+	// Skipped counts lines that are not structured JSON log entries, such as
+	// the plain-text format written before MongoDB 4.4 or a truncated tail.
+	Skipped int
+}
+
+// ReadSlowQueryLog parses slow query entries from a mongod or mongos log
+// file. Gzipped (rotated) logs are decompressed transparently.
+func ReadSlowQueryLog(path string) (SlowQueryLog, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return SlowQueryLog{}, err
+	}
+	defer func() { _ = f.Close() }()
+
+	br := bufio.NewReader(f)
+	var r io.Reader = br
+	if head, _ := br.Peek(len(gzipMagic)); bytes.Equal(head, gzipMagic) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return SlowQueryLog{}, fmt.Errorf("gzip: %w", err)
+		}
+		defer func() { _ = gz.Close() }()
+		r = gz
+	}
+	return ParseSlowQueryLog(r)
+}
+
+// ParseSlowQueryLog reads structured (JSON) server log lines and normalizes
+// "Slow query" entries into the same shapes ReadProfiler returns. Entries
+// are ordered newest first.
+func ParseSlowQueryLog(r io.Reader) (SlowQueryLog, error) {
+	var log SlowQueryLog
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64<<10), maxLogLineSize)
+	for sc.Scan() {
+		log.Lines++
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var doc bson.M
+		if line[0] != '{' || bson.UnmarshalExtJSON(line, false, &doc) != nil {
+			log.Skipped++
+			continue
+		}
+		if toInt64(doc["id"]) != slowQueryLogID && toString(doc["msg"]) != "Slow query" {
+			continue
+		}
+		attr := toBsonM(doc["attr"])
+		if attr == nil {
+			continue
+		}
+		// The log entry's attributes mirror a system.profile document,
+		// except for the timestamp.
+		attr["ts"] = doc["t"]
+		if entry, ok := profileEntryFromDoc("", attr); ok {
+			log.Entries = append(log.Entries, entry)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return log, fmt.Errorf("line %d: %w", log.Lines+1, err)
+	}
+
+	sort.SliceStable(log.Entries, func(i, j int) bool {
+		return log.Entries[i].Timestamp.After(log.Entries[j].Timestamp)
+	})
+	return log, nil
+}
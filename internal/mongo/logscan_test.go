@@ -0,0 +1,77 @@
+package mongo
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const slowQueryLogFixture = `{"t":{"$date":"2024-03-01T10:00:00.000+00:00"},"s":"I","c":"NETWORK","id":22943,"ctx":"listener","msg":"Connection accepted","attr":{"remote":"10.0.0.5:51234"}}
+{"t":{"$date":"2024-03-01T10:00:01.500+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn12","msg":"Slow query","attr":{"type":"command","ns":"app.orders","command":{"find":"orders","filter":{"status":"open","customerId":{"$oid":"65e1a0f0c2a4b1d2e3f40516"}},"sort":{"createdAt":-1,"status":1},"$db":"app"},"planSummary":"COLLSCAN","keysExamined":0,"docsExamined":120000,"hasSortStage":true,"nreturned":20,"durationMillis":850}}
+2019-06-01T10:00:00.000+0000 I COMMAND  [conn1] command app.orders command: find { find: "orders" } 900ms
+{"t":{"$date":"2024-03-01T10:00:02.000+00:00"},"s":"I","c":"COMMAND","id":51803,"ctx":"conn13","msg":"Slow query","attr":{"type":"command","ns":"app.$cmd","command":{"aggregate":"users","pipeline":[{"$match":{"email":"a@b.c"}}],"$db":"app"},"planSummary":"IXSCAN { email: 1 }","docsExamined":1,"keysExamined":1,"nreturned":1,"durationMillis":120}}
+{"t":{"$date":"2024-03-01T10:00:03
+`
+
+func TestParseSlowQueryLog(t *testing.T) {
+	log, err := ParseSlowQueryLog(strings.NewReader(slowQueryLogFixture))
+	if err != nil {
+		t.Fatalf("ParseSlowQueryLog: %v", err)
+	}
+	if log.Lines != 5 || log.Skipped != 2 {
+		t.Errorf("lines=%d skipped=%d, want 5 and 2", log.Lines, log.Skipped)
+	}
+	if len(log.Entries) != 2 {
+		t.Fatalf("entries = %+v, want 2", log.Entries)
+	}
+
+	// Newest first.
+	users, orders := log.Entries[0], log.Entries[1]
+	if users.Database != "app" || users.Collection != "users" || users.DurationMillis != 120 {
+		t.Errorf("aggregate entry = %+v", users)
+	}
+	if orders.Collection != "orders" || orders.PlanSummary != "COLLSCAN" || orders.DocsExamined != 120000 || !orders.HasSortStage {
+		t.Errorf("find entry = %+v", orders)
+	}
+	if got := strings.Join(orders.FilterFields, ","); got != "customerId,status" {
+		t.Errorf("filter fields = %v", orders.FilterFields)
+	}
+	if len(orders.SortKeys) != 2 || orders.SortKeys[0].Field != "createdAt" || orders.SortKeys[0].Direction != -1 {
+		t.Errorf("sort keys = %+v, want createdAt:-1 first", orders.SortKeys)
+	}
+	if orders.Timestamp.IsZero() || orders.Timestamp.Second() != 1 {
+		t.Errorf("timestamp = %v", orders.Timestamp)
+	}
+}
+
+func TestReadSlowQueryLog_Gzip(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(slowQueryLogFixture)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "mongod.log.1.gz")
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	log, err := ReadSlowQueryLog(path)
+	if err != nil {
+		t.Fatalf("ReadSlowQueryLog: %v", err)
+	}
+	if len(log.Entries) != 2 {
+		t.Fatalf("entries = %d, want 2", len(log.Entries))
+	}
+}
+
+func TestReadSlowQueryLog_Missing(t *testing.T) {
+	if _, err := ReadSlowQueryLog(filepath.Join(t.TempDir(), "nope.log")); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
@@ -0,0 +1,143 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	// DefaultOplogSampleLimit is how many of the newest oplog entries
+	// SampleOplog reads when no limit is given.
+	DefaultOplogSampleLimit int64 = 10_000
+
+	// maxOplogSampleLimit caps the entries read, so a large --oplog-limit
+	// cannot turn the sample into a scan of the whole oplog.
+	maxOplogSampleLimit int64 = 100_000
+)
+
+// OplogProfile summarizes the inserts, updates and deletes among the newest
+// oplog entries.
+type OplogProfile struct {
+	Writes     int64             `json:"writes"`
+	First      time.Time         `json:"first"`
+	Last       time.Time         `json:"last"`
+	Namespaces []NamespaceWrites `json:"namespaces"`
+}
+
+// Window is the time span the sampled writes cover.
+func (p *OplogProfile) Window() time.Duration {
+	if p.First.IsZero() || p.Last.Before(p.First) {
+		return 0
+	}
+	return p.Last.Sub(p.First)
+}
+
+// SampleOplog reads the newest limit entries of local.oplog.rs and counts
+// the writes per namespace, busiest first. Counting happens on the server,
+// so only one document per namespace and operation comes back. Writes in
+// system databases and system collections are left out, as are writes
+// inside multi-document transactions, which the oplog records as one
+// applyOps command. An empty database covers every database.
+func (i *Inspector) SampleOplog(ctx context.Context, database string, limit int64) (OplogProfile, error) {
+	if limit <= 0 {
+		limit = DefaultOplogSampleLimit
+	}
+	limit = min(limit, maxOplogSampleLimit)
+
+	match := bson.D{{Key: "op", Value: bson.D{{Key: "$in", Value: bson.A{"i", "u", "d"}}}}}
+	if database != "" {
+		match = append(match, bson.E{Key: "ns", Value: bson.Regex{Pattern: "^" + regexp.QuoteMeta(database) + `\.`}})
+	}
+	cursor, err := i.db.Aggregate(ctx, "local", "oplog.rs", bson.A{
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "$natural", Value: -1}}}},
+		bson.D{{Key: "$limit", Value: limit}},
+		bson.D{{Key: "$match", Value: match}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "ns", Value: "$ns"}, {Key: "op", Value: "$op"}}},
+			{Key: "n", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "first", Value: bson.D{{Key: "$min", Value: "$wall"}}},
+			{Key: "last", Value: bson.D{{Key: "$max", Value: "$wall"}}},
+		}}},
+	})
+	if err != nil {
+		return OplogProfile{}, fmt.Errorf("read local.oplog.rs: %w", err)
+	}
+	defer func() { _ = cursor.Close(ctx) }()
+
+	var profile OplogProfile
+	tally := make(writeTally)
+	for cursor.Next(ctx) {
+		var doc bson.M
+		if err := cursor.Decode(&doc); err != nil {
+			return OplogProfile{}, fmt.Errorf("decode oplog summary: %w", err)
+		}
+		id := toBsonM(doc["_id"])
+		db, coll, _ := strings.Cut(toString(id["ns"]), ".")
+		n := toInt64(doc["n"])
+		if !tally.add(db, coll, toString(id["op"]), n) {
+			continue
+		}
+		profile.Writes += n
+		if first := toTime(doc["first"]); !first.IsZero() && (profile.First.IsZero() || first.Before(profile.First)) {
+			profile.First = first
+		}
+		if last := toTime(doc["last"]); last.After(profile.Last) {
+			profile.Last = last
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return OplogProfile{}, fmt.Errorf("read local.oplog.rs: %w", err)
+	}
+	profile.Namespaces = tally.namespaces()
+	return profile, nil
+}
+
+// writeTally counts writes per namespace, keyed by "db.collection".
+type writeTally map[string]*NamespaceWrites
+
+// add counts n writes of kind op, given as an oplog op code (i, u, d) or a
+// change event operationType. It reports whether they were counted: writes
+// to system databases and system collections, and other operations, are
+// not.
+func (t writeTally) add(db, coll, op string, n int64) bool {
+	if db == "" || coll == "" || systemDBs[db] || strings.HasPrefix(coll, "system.") {
+		return false
+	}
+	ns := t[db+"."+coll]
+	if ns == nil {
+		ns = &NamespaceWrites{Database: db, Collection: coll}
+	}
+	switch op {
+	case "i", "insert":
+		ns.Inserts += n
+	case "u", "update", "replace":
+		ns.Updates += n
+	case "d", "delete":
+		ns.Deletes += n
+	default:
+		return false
+	}
+	t[db+"."+coll] = ns
+	return true
+}
+
+// namespaces returns the counts busiest first.
+func (t writeTally) namespaces() []NamespaceWrites {
+	out := make([]NamespaceWrites, 0, len(t))
+	for _, ns := range t {
+		out = append(out, *ns)
+	}
+	sort.Slice(out, func(a, b int) bool {
+		if out[a].Writes() != out[b].Writes() {
+			return out[a].Writes() > out[b].Writes()
+		}
+		return out[a].Database+"."+out[a].Collection < out[b].Database+"."+out[b].Collection
+	})
+	return out
+}
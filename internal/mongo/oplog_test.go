@@ -0,0 +1,58 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestSampleOplog(t *testing.T) {
+	t0 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	group := func(ns, op string, n int32, first, last time.Time) bson.M {
+		return bson.M{
+			"_id":   bson.M{"ns": ns, "op": op},
+			"n":     n,
+			"first": bson.NewDateTimeFromTime(first),
+			"last":  bson.NewDateTimeFromTime(last),
+		}
+	}
+	mc := &mockClient{aggregateData: []bson.M{
+		group("app.events", "i", 900, t0, t0.Add(10*time.Minute)),
+		group("app.events", "u", 50, t0.Add(time.Minute), t0.Add(9*time.Minute)),
+		group("app.users", "d", 20, t0.Add(-time.Minute), t0.Add(5*time.Minute)),
+		group("config.system.sessions", "u", 400, t0, t0),
+		group("app.system.views", "i", 1, t0, t0),
+	}}
+	insp := &Inspector{db: mc}
+
+	profile, err := insp.SampleOplog(context.Background(), "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Writes != 970 {
+		t.Errorf("writes = %d, want 970", profile.Writes)
+	}
+	if profile.Window() != 11*time.Minute {
+		t.Errorf("window = %s, want 11m", profile.Window())
+	}
+	if len(profile.Namespaces) != 2 {
+		t.Fatalf("namespaces = %+v, want app.events and app.users", profile.Namespaces)
+	}
+	events := profile.Namespaces[0]
+	if events.Collection != "events" || events.Inserts != 900 || events.Updates != 50 || events.Writes() != 950 {
+		t.Errorf("events = %+v", events)
+	}
+	if users := profile.Namespaces[1]; users.Collection != "users" || users.Deletes != 20 {
+		t.Errorf("users = %+v", users)
+	}
+}
+
+func TestSampleOplog_Error(t *testing.T) {
+	insp := &Inspector{db: &mockClient{aggregateErr: errors.New("not authorized on local")}}
+	if _, err := insp.SampleOplog(context.Background(), "app", 100); err == nil {
+		t.Fatal("expected error")
+	}
+}
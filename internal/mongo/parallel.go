@@ -0,0 +1,37 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultConcurrency is how many databases are inspected at once when
+// Config.Concurrency is not set.
+const DefaultConcurrency = 4
+
+// ForEachDatabase calls fn for each database name with at most limit calls
+// in flight (DefaultConcurrency when limit <= 0). When perDB is positive each
+// call gets its own deadline, so one slow database cannot use up the
+// caller's whole timeout. The first error cancels the remaining calls and is
+// returned; fn should record results by index to keep output order stable.
+func ForEachDatabase(ctx context.Context, names []string, limit int, perDB time.Duration, fn func(ctx context.Context, i int, name string) error) error {
+	if limit <= 0 {
+		limit = DefaultConcurrency
+	}
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(limit)
+	for i, name := range names {
+		g.Go(func() error {
+			dbCtx := gctx
+			if perDB > 0 {
+				var cancel context.CancelFunc
+				dbCtx, cancel = context.WithTimeout(gctx, perDB)
+				defer cancel()
+			}
+			return fn(dbCtx, i, name)
+		})
+	}
+	return g.Wait()
+}
@@ -0,0 +1,78 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestForEachDatabase_LimitsConcurrency(t *testing.T) {
+	names := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	var inFlight, peak atomic.Int32
+	seen := make([]string, len(names))
+	err := ForEachDatabase(context.Background(), names, 3, 0, func(_ context.Context, i int, name string) error {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		inFlight.Add(-1)
+		seen[i] = name
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := peak.Load(); p > 3 || p < 2 {
+		t.Errorf("peak concurrency = %d, want 2..3", p)
+	}
+	for i, name := range names {
+		if seen[i] != name {
+			t.Errorf("slot %d = %q, want %q", i, seen[i], name)
+		}
+	}
+}
+
+func TestForEachDatabase_PerDatabaseTimeout(t *testing.T) {
+	start := time.Now()
+	errs := make([]error, 2)
+	err := ForEachDatabase(context.Background(), []string{"slow", "fast"}, 0, 20*time.Millisecond, func(ctx context.Context, i int, name string) error {
+		if name == "slow" {
+			<-ctx.Done()
+			errs[i] = ctx.Err()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !errors.Is(errs[0], context.DeadlineExceeded) || errs[1] != nil {
+		t.Errorf("errs = %v, want deadline only for the slow database", errs)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("took %s, per-database timeout not applied", elapsed)
+	}
+}
+
+func TestForEachDatabase_FirstErrorCancels(t *testing.T) {
+	boom := errors.New("boom")
+	err := ForEachDatabase(context.Background(), []string{"bad", "waits"}, 2, 0, func(ctx context.Context, _ int, name string) error {
+		if name == "bad" {
+			return boom
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+			return errors.New("not cancelled")
+		}
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("err = %v, want boom", err)
+	}
+}
@@ -0,0 +1,104 @@
+package mongo
+
+import (
+	"regexp"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// PIIPattern is a named regular expression that sampled string values are
+// matched against when Config.PIIPatterns is set. Valid, when set, must
+// also accept the matched text, for checks such as a checksum that a
+// regular expression cannot express.
+type PIIPattern struct {
+	Name   string
+	Regexp *regexp.Regexp
+	Valid  func(match string) bool
+}
+
+// Match reports whether s contains a match of p that Valid accepts.
+func (p PIIPattern) Match(s string) bool {
+	if p.Valid == nil {
+		return p.Regexp.MatchString(s)
+	}
+	for _, m := range p.Regexp.FindAllString(s, -1) {
+		if p.Valid(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldPIIMatches counts, for one field path, the sampled string values
+// and how many of them each PII pattern matched. Values themselves are
+// never kept.
+type FieldPIIMatches struct {
+	Path    string           `json:"path"`
+	Strings int64            `json:"strings"`
+	Matches map[string]int64 `json:"matches"` // pattern name → matching values
+}
+
+// piiCounter folds the string values of sampled documents into per-path
+// match counts.
+type piiCounter struct {
+	patterns []PIIPattern
+	strings  map[string]int64
+	matches  map[string]map[string]int64
+}
+
+func newPIICounter(patterns []PIIPattern) *piiCounter {
+	return &piiCounter{
+		patterns: patterns,
+		strings:  make(map[string]int64),
+		matches:  make(map[string]map[string]int64),
+	}
+}
+
+// add matches every string value in doc, including those in arrays (under
+// path[]), against the patterns.
+func (c *piiCounter) add(doc bson.M, prefix string) {
+	for key, val := range doc {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		c.addValue(path, val)
+	}
+}
+
+func (c *piiCounter) addValue(path string, val any) {
+	switch v := val.(type) {
+	case string:
+		c.strings[path]++
+		for _, p := range c.patterns {
+			if p.Match(v) {
+				if c.matches[path] == nil {
+					c.matches[path] = make(map[string]int64)
+				}
+				c.matches[path][p.Name]++
+			}
+		}
+	case bson.M:
+		c.add(v, path)
+	case bson.D:
+		c.add(docToMap(v), path)
+	case bson.A:
+		for _, elem := range v {
+			c.addValue(path+"[]", elem)
+		}
+	}
+}
+
+// result returns the paths with at least one match, sorted.
+func (c *piiCounter) result() []FieldPIIMatches {
+	if len(c.matches) == 0 {
+		return nil
+	}
+	out := make([]FieldPIIMatches, 0, len(c.matches))
+	for path, m := range c.matches {
+		out = append(out, FieldPIIMatches{Path: path, Strings: c.strings[path], Matches: m})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
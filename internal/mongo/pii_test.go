@@ -0,0 +1,47 @@
+package mongo
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func TestSampleDocuments_PII(t *testing.T) {
+	mc := &mockClient{
+		collSpecs: []mongo.CollectionSpecification{{Name: "users", Type: "collection"}},
+		aggregateData: []bson.M{
+			{"contact": bson.M{"email": "alice@example.com"}, "tags": bson.A{"vip", "bob@example.com"}, "age": int32(30)},
+			{"contact": bson.M{"email": "unknown"}, "tags": bson.A{"new"}},
+		},
+	}
+	insp := &Inspector{db: mc, pii: []PIIPattern{{Name: "email", Regexp: regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[a-z]+$`)}}}
+
+	results, err := insp.SampleDocuments(context.Background(), "app", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	want := []FieldPIIMatches{
+		{Path: "contact.email", Strings: 2, Matches: map[string]int64{"email": 1}},
+		{Path: "tags[]", Strings: 3, Matches: map[string]int64{"email": 1}},
+	}
+	if got := results[0].PII; !reflect.DeepEqual(got, want) {
+		t.Errorf("PII = %+v\nwant %+v", got, want)
+	}
+
+	// Without patterns nothing is matched.
+	insp.pii = nil
+	results, err = insp.SampleDocuments(context.Background(), "app", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].PII != nil {
+		t.Errorf("PII = %+v without patterns", results[0].PII)
+	}
+}
@@ -0,0 +1,263 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// readOnlyCommands are the server commands Config.StrictReadOnly allows:
+// the ones the inspector issues, all of which only read.
+var readOnlyCommands = map[string]bool{
+	"aggregate":        true,
+	"buildInfo":        true,
+	"collStats":        true,
+	"connPoolStats":    true,
+	"find":             true,
+	"getCmdLineOpts":   true,
+	"getMore":          true,
+	"getParameter":     true,
+	"listCollections":  true,
+	"listDatabases":    true,
+	"listIndexes":      true,
+	"ping":             true,
+	"replSetGetConfig": true,
+	"replSetGetStatus": true,
+	"rolesInfo":        true,
+	"serverStatus":     true,
+	"usersInfo":        true,
+}
+
+// writeStages are aggregation stages that write. aggregate is allowed only
+// with pipelines that have none of them.
+var writeStages = map[string]bool{"$out": true, "$merge": true}
+
+// ReadOnlyCommands returns the allowlist enforced by Config.StrictReadOnly,
+// sorted.
+func ReadOnlyCommands() []string {
+	names := make([]string, 0, len(readOnlyCommands))
+	for name := range readOnlyCommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CommandEvent describes a command the inspector sent to the server, or
+// refused to send under Config.StrictReadOnly.
+type CommandEvent struct {
+	Command    string
+	Database   string
+	Collection string // empty for database and server commands
+	// Blocked is set when StrictReadOnly refused the command, which was
+	// then not sent; Reason says why.
+	Blocked bool
+	Reason  string
+}
+
+// Namespace returns "db.collection", or just the database for commands
+// without a collection.
+func (e CommandEvent) Namespace() string {
+	if e.Collection == "" {
+		return e.Database
+	}
+	return e.Database + "." + e.Collection
+}
+
+// ReadOnlyError is returned for a command outside the read-only allowlist
+// under Config.StrictReadOnly.
+type ReadOnlyError struct {
+	Command   string
+	Namespace string
+	Reason    string
+}
+
+func (e *ReadOnlyError) Error() string {
+	return fmt.Sprintf("strict read-only: %s on %s blocked: %s", e.Command, e.Namespace, e.Reason)
+}
+
+// cursorCommands are the commands the driver sends on its own while a
+// cursor is iterated or closed. They never pass through guardedClient.
+var cursorCommands = map[string]bool{"getMore": true, "killCursors": true}
+
+// withCursorCommands returns mon, or a new monitor when mon is nil, that
+// also tells log about cursor commands as the driver starts them. They
+// are logged, not checked: getMore only reads, and killCursors frees a
+// cursor the run opened.
+func withCursorCommands(mon *event.CommandMonitor, log func(CommandEvent)) *event.CommandMonitor {
+	if mon == nil {
+		mon = &event.CommandMonitor{}
+	}
+	started := mon.Started
+	mon.Started = func(ctx context.Context, e *event.CommandStartedEvent) {
+		if cursorCommands[e.CommandName] {
+			log(CommandEvent{Command: e.CommandName, Database: e.DatabaseName, Collection: rawCommandCollection(e.Command)})
+		}
+		if started != nil {
+			started(ctx, e)
+		}
+	}
+	return mon
+}
+
+// guardedClient reports every command to log and, when strict, refuses
+// commands outside the read-only allowlist. The first refusal is sticky:
+// every later call fails with the same error, so a run that hit one stops
+// talking to the server instead of carrying on around a swallowed error.
+type guardedClient struct {
+	dbClient
+	strict bool
+	log    func(CommandEvent)
+
+	mu        sync.Mutex
+	violation *ReadOnlyError
+}
+
+// check logs a command and returns an error if it must not be sent.
+// pipeline is inspected for write stages when the command is aggregate.
+func (c *guardedClient) check(name, dbName, collName string, pipeline any) error {
+	ev := CommandEvent{Command: name, Database: dbName, Collection: collName}
+	c.mu.Lock()
+	err := c.violation
+	if err == nil && c.strict {
+		switch {
+		case !readOnlyCommands[name]:
+			err = &ReadOnlyError{Command: name, Namespace: ev.Namespace(), Reason: "not in the read-only allowlist"}
+		case name == "aggregate":
+			if reason := pipelineWrites(pipeline); reason != "" {
+				err = &ReadOnlyError{Command: name, Namespace: ev.Namespace(), Reason: reason}
+			}
+		}
+		c.violation = err
+	}
+	c.mu.Unlock()
+
+	if c.log != nil {
+		switch {
+		case err == nil:
+		case err.Command == name && err.Namespace == ev.Namespace():
+			ev.Blocked, ev.Reason = true, err.Reason
+		default:
+			ev.Blocked, ev.Reason = true, "an earlier command was blocked"
+		}
+		c.log(ev)
+	}
+	if err != nil {
+		return err // not a nil *ReadOnlyError in an error
+	}
+	return nil
+}
+
+func (c *guardedClient) Ping(ctx context.Context) error {
+	if err := c.check("ping", "admin", "", nil); err != nil {
+		return err
+	}
+	return c.dbClient.Ping(ctx)
+}
+
+func (c *guardedClient) ListDatabases(ctx context.Context, filter any) (mongo.ListDatabasesResult, error) {
+	if err := c.check("listDatabases", "admin", "", nil); err != nil {
+		return mongo.ListDatabasesResult{}, err
+	}
+	return c.dbClient.ListDatabases(ctx, filter)
+}
+
+func (c *guardedClient) ListCollectionSpecs(ctx context.Context, dbName string) ([]mongo.CollectionSpecification, error) {
+	if err := c.check("listCollections", dbName, "", nil); err != nil {
+		return nil, err
+	}
+	return c.dbClient.ListCollectionSpecs(ctx, dbName)
+}
+
+func (c *guardedClient) RunCommand(ctx context.Context, dbName string, cmd any) *mongo.SingleResult {
+	name, collName, pipeline := describeCommand(cmd)
+	if err := c.check(name, dbName, collName, pipeline); err != nil {
+		return mongo.NewSingleResultFromDocument(bson.D{}, err, nil)
+	}
+	return c.dbClient.RunCommand(ctx, dbName, cmd)
+}
+
+func (c *guardedClient) ListIndexes(ctx context.Context, dbName, collName string) ([]bson.Raw, error) {
+	if err := c.check("listIndexes", dbName, collName, nil); err != nil {
+		return nil, err
+	}
+	return c.dbClient.ListIndexes(ctx, dbName, collName)
+}
+
+func (c *guardedClient) Aggregate(ctx context.Context, dbName, collName string, pipeline any) (*mongo.Cursor, error) {
+	if err := c.check("aggregate", dbName, collName, pipeline); err != nil {
+		return nil, err
+	}
+	return c.dbClient.Aggregate(ctx, dbName, collName, pipeline)
+}
+
+// Watch opens a change stream, which the server runs as an aggregate with
+// a $changeStream stage.
+func (c *guardedClient) Watch(ctx context.Context, dbName string, pipeline any) (changeStream, error) {
+	if err := c.check("aggregate", dbName, "", pipeline); err != nil {
+		return nil, err
+	}
+	return c.dbClient.Watch(ctx, dbName, pipeline)
+}
+
+// describeCommand returns the name of a command document (its first key),
+// the collection it targets when the name's value is one, and its pipeline
+// for aggregate. Commands that are not a bson.D are named "unknown".
+func describeCommand(cmd any) (name, collection string, pipeline any) {
+	d, ok := cmd.(bson.D)
+	if !ok || len(d) == 0 {
+		return "unknown", "", nil
+	}
+	name = d[0].Key
+	collection, _ = d[0].Value.(string)
+	for _, e := range d {
+		switch e.Key {
+		case "collection": // getMore
+			if s, ok := e.Value.(string); ok {
+				collection = s
+			}
+		case "pipeline":
+			pipeline = e.Value
+		}
+	}
+	return name, collection, pipeline
+}
+
+// pipelineWrites returns why pipeline is not read-only, or "" when it is.
+// A pipeline that cannot be read is refused, since it cannot be vetted.
+func pipelineWrites(pipeline any) string {
+	if pipeline == nil {
+		return ""
+	}
+	raw, err := bson.Marshal(bson.D{{Key: "p", Value: pipeline}})
+	if err != nil {
+		return "pipeline cannot be checked: " + err.Error()
+	}
+	arr, ok := bson.Raw(raw).Lookup("p").ArrayOK()
+	if !ok {
+		return "pipeline cannot be checked: not an array"
+	}
+	stages, err := arr.Values()
+	if err != nil {
+		return "pipeline cannot be checked: " + err.Error()
+	}
+	for _, v := range stages {
+		doc, ok := v.DocumentOK()
+		if !ok {
+			continue
+		}
+		elems, err := doc.Elements()
+		if err != nil || len(elems) == 0 {
+			continue
+		}
+		if key := elems[0].Key(); writeStages[key] {
+			return key + " stage writes"
+		}
+	}
+	return ""
+}
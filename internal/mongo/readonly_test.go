@@ -0,0 +1,247 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/event"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func TestGuardedClient_StrictReadOnly(t *testing.T) {
+	ctx := context.Background()
+	var mu sync.Mutex
+	var events []CommandEvent
+	sent := 0
+	mc := &mockClient{runCmdHook: func(string, any) (bson.Raw, error) {
+		sent++
+		return bson.Marshal(bson.M{"version": "7.0.4"})
+	}}
+	gc := &guardedClient{dbClient: mc, strict: true, log: func(ev CommandEvent) {
+		mu.Lock()
+		events = append(events, ev)
+		mu.Unlock()
+	}}
+	insp := &Inspector{db: gc}
+
+	if _, err := insp.GetServerVersion(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gc.ListIndexes(ctx, "app", "users"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gc.Aggregate(ctx, "app", "users", bson.A{bson.D{{Key: "$indexStats", Value: bson.D{}}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A write stage in an otherwise allowed aggregate is refused.
+	_, err := gc.Aggregate(ctx, "app", "users", bson.A{
+		bson.D{{Key: "$match", Value: bson.D{}}},
+		bson.D{{Key: "$out", Value: "copy"}},
+	})
+	var roe *ReadOnlyError
+	if !errors.As(err, &roe) || roe.Reason != "$out stage writes" || roe.Namespace != "app.users" {
+		t.Fatalf("err = %v, want $out refused", err)
+	}
+
+	// The refusal is sticky: allowed commands now fail too and are not sent.
+	before := sent
+	if _, err := insp.GetServerVersion(ctx); !errors.As(err, &roe) {
+		t.Fatalf("after violation: err = %v, want ReadOnlyError", err)
+	}
+	if sent != before {
+		t.Errorf("command sent after violation")
+	}
+
+	want := []CommandEvent{
+		{Command: "buildInfo", Database: "admin"},
+		{Command: "listIndexes", Database: "app", Collection: "users"},
+		{Command: "aggregate", Database: "app", Collection: "users"},
+		{Command: "aggregate", Database: "app", Collection: "users", Blocked: true, Reason: "$out stage writes"},
+		{Command: "buildInfo", Database: "admin", Blocked: true, Reason: "an earlier command was blocked"},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %+v\nwant %+v", events, want)
+	}
+}
+
+func TestGuardedClient_RefusesUnlistedCommand(t *testing.T) {
+	mc := &mockClient{runCmdHook: func(string, any) (bson.Raw, error) {
+		t.Fatal("command sent")
+		return nil, nil
+	}}
+	gc := &guardedClient{dbClient: mc, strict: true}
+
+	err := gc.RunCommand(context.Background(), "app", bson.D{{Key: "dropIndexes", Value: "users"}, {Key: "index", Value: "*"}}).Err()
+	var roe *ReadOnlyError
+	if !errors.As(err, &roe) || roe.Command != "dropIndexes" || roe.Namespace != "app.users" {
+		t.Fatalf("err = %v, want dropIndexes refused", err)
+	}
+}
+
+func TestGuardedClient_LogOnly(t *testing.T) {
+	var events []CommandEvent
+	gc := &guardedClient{dbClient: &mockClient{}, log: func(ev CommandEvent) { events = append(events, ev) }}
+
+	if _, err := gc.Aggregate(context.Background(), "app", "users", bson.A{bson.D{{Key: "$merge", Value: "copy"}}}); err != nil {
+		t.Fatalf("not strict: err = %v", err)
+	}
+	if len(events) != 1 || events[0].Blocked {
+		t.Errorf("events = %+v, want one unblocked aggregate", events)
+	}
+}
+
+func TestDescribeCommand(t *testing.T) {
+	name, coll, pipeline := describeCommand(bson.D{
+		{Key: "getMore", Value: int64(7)},
+		{Key: "collection", Value: "events"},
+	})
+	if name != "getMore" || coll != "events" || pipeline != nil {
+		t.Errorf("getMore = %q %q %v", name, coll, pipeline)
+	}
+	if name, _, _ := describeCommand(bson.M{"ping": 1}); name != "unknown" {
+		t.Errorf("bson.M name = %q, want unknown", name)
+	}
+}
+
+func TestPipelineWrites(t *testing.T) {
+	tests := []struct {
+		pipeline any
+		want     string
+	}{
+		{nil, ""},
+		{mongo.Pipeline{{{Key: "$match", Value: bson.D{}}}}, ""},
+		{bson.A{bson.M{"$merge": bson.M{"into": "x"}}}, "$merge stage writes"},
+		{"not a pipeline", "pipeline cannot be checked"},
+	}
+	for _, tt := range tests {
+		got := pipelineWrites(tt.pipeline)
+		if tt.want == "" && got != "" || tt.want != "" && (len(got) < len(tt.want) || got[:len(tt.want)] != tt.want) {
+			t.Errorf("pipelineWrites(%v) = %q, want %q", tt.pipeline, got, tt.want)
+		}
+	}
+}
+
+// TestReadOnlyCommandsCoverInspector parses the package and checks that the
+// command of every RunCommand call is on the read-only allowlist, so a new
+// inspection cannot fail under --strict-readonly.
+func TestReadOnlyCommandsCoverInspector(t *testing.T) {
+	fset := token.NewFileSet()
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := 0
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) != 3 {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "RunCommand" {
+				return true
+			}
+			if id, ok := call.Args[2].(*ast.Ident); ok && id.Obj != nil {
+				if _, param := id.Obj.Decl.(*ast.Field); param {
+					return true // a client wrapper passing its caller's command through
+				}
+			}
+			cmd := commandName(call.Args[2])
+			if cmd == "" {
+				t.Errorf("%s: cannot tell the command of this RunCommand call", fset.Position(call.Pos()))
+				return true
+			}
+			seen++
+			if !readOnlyCommands[cmd] {
+				t.Errorf("%s: %s is not in readOnlyCommands", fset.Position(call.Pos()), cmd)
+			}
+			return true
+		})
+	}
+	if seen == 0 {
+		t.Fatal("found no RunCommand calls")
+	}
+}
+
+// commandName returns the first key of a bson.D command literal, following
+// a variable to the literal it was assigned.
+func commandName(expr ast.Expr) string {
+	if id, ok := expr.(*ast.Ident); ok && id.Obj != nil {
+		if assign, ok := id.Obj.Decl.(*ast.AssignStmt); ok {
+			for i, lhs := range assign.Lhs {
+				if l, ok := lhs.(*ast.Ident); ok && l.Name == id.Name && i < len(assign.Rhs) {
+					expr = assign.Rhs[i]
+				}
+			}
+		}
+	}
+	lit, ok := expr.(*ast.CompositeLit)
+	if !ok || len(lit.Elts) == 0 {
+		return ""
+	}
+	elem, ok := lit.Elts[0].(*ast.CompositeLit)
+	if !ok {
+		return ""
+	}
+	for _, e := range elem.Elts {
+		kv, ok := e.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		if key, ok := kv.Key.(*ast.Ident); ok && key.Name == "Key" {
+			if s, ok := kv.Value.(*ast.BasicLit); ok && s.Kind == token.STRING {
+				name, _ := strconv.Unquote(s.Value)
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+func TestWithCursorCommands(t *testing.T) {
+	var logged []CommandEvent
+	var executed []ExecutedCommand
+	m := withCursorCommands(commandMonitor(func(c ExecutedCommand) { executed = append(executed, c) }),
+		func(ev CommandEvent) { logged = append(logged, ev) })
+	ctx := context.Background()
+
+	for i, d := range []bson.D{
+		{{Key: "find", Value: "orders"}},
+		{{Key: "getMore", Value: int64(9)}, {Key: "collection", Value: "events"}},
+		{{Key: "killCursors", Value: "events"}},
+	} {
+		raw, err := bson.Marshal(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.Started(ctx, &event.CommandStartedEvent{Command: raw, DatabaseName: "app", CommandName: d[0].Key, RequestID: int64(i)})
+		m.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: event.CommandFinishedEvent{CommandName: d[0].Key, DatabaseName: "app", RequestID: int64(i)}})
+	}
+
+	// find goes through guardedClient; only the driver's cursor commands come from the monitor.
+	if len(logged) != 2 || logged[0].Command != "getMore" || logged[0].Namespace() != "app.events" ||
+		logged[1].Command != "killCursors" || logged[1].Blocked {
+		t.Errorf("logged = %+v", logged)
+	}
+	if len(executed) != 3 {
+		t.Errorf("the command log should still see every command, got %+v", executed)
+	}
+}
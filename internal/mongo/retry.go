@@ -0,0 +1,140 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// DefaultRetries is the CLI's default for Config.Retries.
+const DefaultRetries = 2
+
+// retryBackoff is the delay before the first retry; it doubles each time,
+// which spans a typical replica set election (a few seconds) in two or
+// three retries.
+var retryBackoff = 500 * time.Millisecond
+
+// Server error codes that clear up on their own: elections, shutdowns and
+// interrupted operations. CursorNotFound is listed because a failover
+// kills open cursors; reads resume them rather than start over.
+var transientCodes = []int{
+	6,     // HostUnreachable
+	7,     // HostNotFound
+	43,    // CursorNotFound
+	89,    // NetworkTimeout
+	91,    // ShutdownInProgress
+	189,   // PrimarySteppedDown
+	9001,  // SocketException
+	10107, // NotWritablePrimary
+	11600, // InterruptedAtShutdown
+	11601, // Interrupted
+	11602, // InterruptedDueToReplStateChange
+	13435, // NotPrimaryNoSecondaryOk
+	13436, // NotPrimaryOrSecondary
+}
+
+// isTransient reports whether err is worth retrying. Context cancellation
+// and deadlines never are.
+func isTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if mongo.IsNetworkError(err) {
+		return true
+	}
+	var se mongo.ServerError
+	if !errors.As(err, &se) {
+		return false
+	}
+	if se.HasErrorLabel("RetryableReadError") || se.HasErrorLabel("RetryableWriteError") {
+		return true
+	}
+	for _, code := range transientCodes {
+		if se.HasErrorCode(code) {
+			return true
+		}
+	}
+	return false
+}
+
+// withRetry calls op until it succeeds, fails with a non-transient error,
+// has been retried retries times, or ctx is done. The last error is returned.
+func withRetry(ctx context.Context, retries int, op func() error) error {
+	delay := retryBackoff
+	for attempt := 0; ; attempt++ {
+		err := op()
+		if err == nil || attempt >= retries || !isTransient(err) {
+			return err
+		}
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+		delay *= 2
+	}
+}
+
+// retryingClient retries dbClient calls that fail with transient errors.
+// Aggregation cursors are retried only when opened; reads that iterate
+// them re-run through Inspector.aggregateAll.
+type retryingClient struct {
+	dbClient
+	retries int
+}
+
+func (c *retryingClient) ListDatabases(ctx context.Context, filter any) (mongo.ListDatabasesResult, error) {
+	var res mongo.ListDatabasesResult
+	err := withRetry(ctx, c.retries, func() (err error) {
+		res, err = c.dbClient.ListDatabases(ctx, filter)
+		return err
+	})
+	return res, err
+}
+
+func (c *retryingClient) ListCollectionSpecs(ctx context.Context, dbName string) ([]mongo.CollectionSpecification, error) {
+	var specs []mongo.CollectionSpecification
+	err := withRetry(ctx, c.retries, func() (err error) {
+		specs, err = c.dbClient.ListCollectionSpecs(ctx, dbName)
+		return err
+	})
+	return specs, err
+}
+
+// RunCommand retries every command except getMore: a getMore whose reply
+// was lost has already advanced the cursor, so repeating it would silently
+// drop a batch. Callers resume those reads instead.
+func (c *retryingClient) RunCommand(ctx context.Context, dbName string, cmd any) *mongo.SingleResult {
+	if d, ok := cmd.(bson.D); ok && len(d) > 0 && d[0].Key == "getMore" {
+		return c.dbClient.RunCommand(ctx, dbName, cmd)
+	}
+	var res *mongo.SingleResult
+	_ = withRetry(ctx, c.retries, func() error {
+		res = c.dbClient.RunCommand(ctx, dbName, cmd)
+		return res.Err()
+	})
+	return res
+}
+
+func (c *retryingClient) ListIndexes(ctx context.Context, dbName, collName string) ([]bson.Raw, error) {
+	var docs []bson.Raw
+	err := withRetry(ctx, c.retries, func() (err error) {
+		docs, err = c.dbClient.ListIndexes(ctx, dbName, collName)
+		return err
+	})
+	return docs, err
+}
+
+func (c *retryingClient) Aggregate(ctx context.Context, dbName, collName string, pipeline any) (*mongo.Cursor, error) {
+	var cursor *mongo.Cursor
+	err := withRetry(ctx, c.retries, func() (err error) {
+		cursor, err = c.dbClient.Aggregate(ctx, dbName, collName, pipeline)
+		return err
+	})
+	return cursor, err
+}
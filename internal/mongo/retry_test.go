@@ -0,0 +1,136 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func noBackoff(t *testing.T) {
+	t.Helper()
+	prev := retryBackoff
+	retryBackoff = 0
+	t.Cleanup(func() { retryBackoff = prev })
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"election", mongo.CommandError{Code: 11602, Name: "InterruptedDueToReplStateChange"}, true},
+		{"interrupted", mongo.CommandError{Code: 11601, Name: "Interrupted"}, true},
+		{"retryable label", mongo.CommandError{Code: 1, Labels: []string{"RetryableReadError"}}, true},
+		{"unauthorized", mongo.CommandError{Code: 13, Name: "Unauthorized"}, false},
+		{"deadline", context.DeadlineExceeded, false},
+		{"plain", errors.New("boom"), false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		if got := isTransient(tt.err); got != tt.want {
+			t.Errorf("%s: isTransient = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	noBackoff(t)
+	transient := mongo.CommandError{Code: 189, Name: "PrimarySteppedDown"}
+
+	calls := 0
+	err := withRetry(context.Background(), 2, func() error {
+		calls++
+		if calls < 3 {
+			return transient
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("recovering op: err = %v, calls = %d, want nil after 3", err, calls)
+	}
+
+	calls = 0
+	err = withRetry(context.Background(), 2, func() error {
+		calls++
+		return transient
+	})
+	if err == nil || calls != 3 {
+		t.Fatalf("failing op: err = %v, calls = %d, want error after 3", err, calls)
+	}
+
+	calls = 0
+	err = withRetry(context.Background(), 2, func() error {
+		calls++
+		return mongo.CommandError{Code: 13}
+	})
+	if err == nil || calls != 1 {
+		t.Fatalf("permanent error: err = %v, calls = %d, want error after 1", err, calls)
+	}
+}
+
+func TestRetryingClient_RunCommand(t *testing.T) {
+	noBackoff(t)
+	calls := 0
+	mc := &mockClient{runCmdHook: func(string, any) (bson.Raw, error) {
+		calls++
+		if calls == 1 {
+			return nil, mongo.CommandError{Code: 11602}
+		}
+		return bson.Marshal(bson.M{"version": "7.0.4"})
+	}}
+	insp := &Inspector{db: &retryingClient{dbClient: mc, retries: 2}}
+
+	info, err := insp.GetServerVersion(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Version != "7.0.4" || calls != 2 {
+		t.Fatalf("version = %q after %d calls, want 7.0.4 after 2", info.Version, calls)
+	}
+}
+
+func TestFindDocuments_ResumesAfterFailover(t *testing.T) {
+	noBackoff(t)
+	var getMores int
+	var skips []int64
+	mc := &mockClient{runCmdHook: func(_ string, cmd any) (bson.Raw, error) {
+		d := cmd.(bson.D)
+		switch d[0].Key {
+		case "getMore":
+			getMores++
+			return nil, mongo.CommandError{Code: 43, Name: "CursorNotFound"}
+		case "find":
+			var skip int64
+			for _, e := range d {
+				if e.Key == "skip" {
+					skip = e.Value.(int64)
+				}
+			}
+			skips = append(skips, skip)
+			if skip == 0 {
+				return bson.Marshal(bson.M{"cursor": bson.M{"id": int64(7), "firstBatch": bson.A{bson.M{"n": 1}, bson.M{"n": 2}}}})
+			}
+			return bson.Marshal(bson.M{"cursor": bson.M{"id": int64(0), "firstBatch": bson.A{bson.M{"n": 3}}}})
+		}
+		return nil, errors.New("unexpected command")
+	}}
+	insp := &Inspector{db: &retryingClient{dbClient: mc, retries: 2}, retries: 2}
+
+	docs, err := insp.findDocuments(context.Background(), "app", "events", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 3 || toInt64(docs[2]["n"]) != 3 {
+		t.Fatalf("docs = %v, want n=1..3", docs)
+	}
+	if getMores != 1 {
+		t.Errorf("getMore sent %d times, want 1 (never retried)", getMores)
+	}
+	if len(skips) != 2 || skips[1] != 2 {
+		t.Errorf("find skips = %v, want [0 2]", skips)
+	}
+}
@@ -0,0 +1,69 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// SearchIndex is an Atlas Search or Atlas Vector Search index from
+// $listSearchIndexes. Only vector search definitions are parsed.
+type SearchIndex struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"` // "search" or "vectorSearch"
+	Status string `json:"status,omitempty"`
+	// VectorFields and FilterFields are the "vector" and "filter" fields
+	// of a vectorSearch index definition.
+	VectorFields []VectorIndexField `json:"vectorFields,omitempty"`
+	FilterFields []string           `json:"filterFields,omitempty"`
+}
+
+// VectorIndexField is an indexed embedding field.
+type VectorIndexField struct {
+	Path       string `json:"path"`
+	Dimensions int    `json:"dimensions"`
+	Similarity string `json:"similarity"` // euclidean, cosine or dotProduct
+}
+
+// ListSearchIndexes returns the search indexes of a collection. It fails
+// on deployments without Atlas Search, where $listSearchIndexes is not
+// supported.
+func (i *Inspector) ListSearchIndexes(ctx context.Context, database, collection string) ([]SearchIndex, error) {
+	pipeline := mongo.Pipeline{bson.D{{Key: "$listSearchIndexes", Value: bson.D{}}}}
+	var docs []bson.M
+	if err := i.aggregateAll(ctx, database, collection, pipeline, &docs); err != nil {
+		return nil, fmt.Errorf("list search indexes on %s.%s: %w", database, collection, err)
+	}
+	indexes := make([]SearchIndex, 0, len(docs))
+	for _, doc := range docs {
+		idx := SearchIndex{
+			Name:   toString(doc["name"]),
+			Type:   toString(doc["type"]),
+			Status: toString(doc["status"]),
+		}
+		if idx.Type == "" {
+			idx.Type = "search"
+		}
+		// latestDefinition is the one being built; it is what queries
+		// will see once the index is ready.
+		def := toBsonM(doc["latestDefinition"])
+		fields, _ := def["fields"].(bson.A)
+		for _, f := range fields {
+			field := toBsonM(f)
+			switch toString(field["type"]) {
+			case "vector":
+				idx.VectorFields = append(idx.VectorFields, VectorIndexField{
+					Path:       toString(field["path"]),
+					Dimensions: int(toInt64(field["numDimensions"])),
+					Similarity: toString(field["similarity"]),
+				})
+			case "filter":
+				idx.FilterFields = append(idx.FilterFields, toString(field["path"]))
+			}
+		}
+		indexes = append(indexes, idx)
+	}
+	return indexes, nil
+}
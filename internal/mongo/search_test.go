@@ -0,0 +1,78 @@
+package mongo
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+func TestListSearchIndexes(t *testing.T) {
+	mc := &mockClient{aggregateData: []bson.M{
+		{"name": "default", "status": "READY", "latestDefinition": bson.M{"mappings": bson.M{"dynamic": true}}},
+		{"name": "plot_vectors", "type": "vectorSearch", "status": "READY", "latestDefinition": bson.M{"fields": bson.A{
+			bson.M{"type": "vector", "path": "plot_embedding", "numDimensions": int32(1536), "similarity": "cosine"},
+			bson.M{"type": "filter", "path": "year"},
+		}}},
+	}}
+	insp := &Inspector{db: mc}
+
+	got, err := insp.ListSearchIndexes(context.Background(), "app", "movies")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SearchIndex{
+		{Name: "default", Type: "search", Status: "READY"},
+		{Name: "plot_vectors", Type: "vectorSearch", Status: "READY",
+			VectorFields: []VectorIndexField{{Path: "plot_embedding", Dimensions: 1536, Similarity: "cosine"}},
+			FilterFields: []string{"year"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("indexes = %+v\nwant %+v", got, want)
+	}
+
+	insp = &Inspector{db: &mockClient{aggregateErr: errors.New("$listSearchIndexes stage is only allowed on MongoDB Atlas")}}
+	if _, err := insp.ListSearchIndexes(context.Background(), "app", "movies"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestSampleDocuments_Vectors(t *testing.T) {
+	unit := make(bson.A, 16)
+	raw := make(bson.A, 16)
+	for i := range unit {
+		unit[i] = 0.25
+		raw[i] = int32(3)
+	}
+	// A BSON float32 vector of 32 dimensions: dtype 0x27, no padding.
+	data := []byte{0x27, 0}
+	for range 32 {
+		data = binary.LittleEndian.AppendUint32(data, math.Float32bits(0.5))
+	}
+	mc := &mockClient{
+		collSpecs: []mongo.CollectionSpecification{{Name: "movies", Type: "collection"}},
+		aggregateData: []bson.M{
+			{"embedding": unit, "meta": bson.M{"vec": bson.Binary{Subtype: bsonVectorSubtype, Data: data}}},
+			{"embedding": raw, "tags": bson.A{"a", "b"}},
+			{"embedding": bson.A{1.0, 2.0}},
+		},
+	}
+	insp := &Inspector{db: mc}
+
+	results, err := insp.SampleDocuments(context.Background(), "app", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []VectorSample{
+		{Path: "embedding", Count: 2, Dimensions: map[int]int64{16: 2}, Norms: 2, Unit: 1},
+		{Path: "meta.vec", Count: 1, Dimensions: map[int]int64{32: 1}, Norms: 1},
+	}
+	if got := results[0].Vectors; !reflect.DeepEqual(got, want) {
+		t.Errorf("Vectors = %+v\nwant %+v", got, want)
+	}
+}
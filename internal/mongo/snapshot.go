@@ -0,0 +1,60 @@
+package mongo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// SnapshotVersion is the current metadata snapshot format version.
+const SnapshotVersion = 1
+
+// Snapshot is an offline copy of the metadata check needs: collections with
+// their indexes and validators. It holds no documents and no credentials.
+type Snapshot struct {
+	Version   int        `json:"version"`
+	CreatedAt time.Time  `json:"createdAt"`
+	Host      string     `json:"host,omitempty"`
+	URIHash   string     `json:"uriHash,omitempty"`
+	Database  string     `json:"database,omitempty"`
+	Server    ServerInfo `json:"server"`
+	// Collections carry their validators in CollectionInfo.Validator.
+	Collections []CollectionInfo `json:"collections"`
+}
+
+// WriteSnapshot encodes s as indented JSON.
+func WriteSnapshot(w io.Writer, s *Snapshot) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// LoadSnapshot reads a snapshot written by WriteSnapshot. Files without a
+// supported version, such as audit reports, are rejected.
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("decode snapshot: %w", err)
+	}
+	if s.Version != SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d (want %d; create one with mongospectre export-snapshot)", s.Version, SnapshotVersion)
+	}
+	return &s, nil
+}
+
+// Validators returns the validators embedded in the snapshot's collections.
+func (s *Snapshot) Validators() []ValidatorInfo {
+	var out []ValidatorInfo
+	for i := range s.Collections {
+		if v := s.Collections[i].Validator; v != nil {
+			out = append(out, *v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,57 @@
+package mongo
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	snap := &Snapshot{
+		Version:   SnapshotVersion,
+		CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Host:      "db.example.com",
+		Server:    ServerInfo{Version: "7.0.5"},
+		Collections: []CollectionInfo{
+			{Database: "app", Name: "users", Indexes: []IndexInfo{{Name: "email_1", Key: []KeyField{{Field: "email", Direction: 1}}, Unique: true}},
+				Validator: &ValidatorInfo{Database: "app", Collection: "users", Schema: ValidatorSchema{Required: []string{"email"}}}},
+			{Database: "app", Name: "orders"},
+		},
+	}
+	var buf bytes.Buffer
+	if err := WriteSnapshot(&buf, snap); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "snap.json")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Server.Version != "7.0.5" || got.Host != "db.example.com" || !got.CreatedAt.Equal(snap.CreatedAt) {
+		t.Errorf("metadata mismatch: %+v", got)
+	}
+	if len(got.Collections) != 2 || !got.Collections[0].Indexes[0].Unique {
+		t.Errorf("collections mismatch: %+v", got.Collections)
+	}
+	if v := got.Validators(); len(v) != 1 || v[0].Collection != "users" {
+		t.Errorf("validators = %+v", v)
+	}
+}
+
+func TestLoadSnapshot_RejectsOtherFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	if err := os.WriteFile(path, []byte(`{"metadata":{},"findings":[]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := LoadSnapshot(path)
+	if err == nil || !strings.Contains(err.Error(), "unsupported snapshot version 0") {
+		t.Fatalf("expected version error, got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package mongo
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// buildTLSConfig loads the CA bundle and client certificate named in cfg.
+// The certificate file follows the tlsCertificateKeyFile convention: one PEM
+// file holding both the certificate chain and the private key.
+func buildTLSConfig(cfg TLSConfig) (*tls.Config, error) {
+	tc := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.Insecure, //nolint:gosec // explicit --tls-insecure opt-in
+	}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA file %s: no PEM certificates found", cfg.CAFile)
+		}
+		tc.RootCAs = pool
+	}
+	if cfg.CertKeyFile != "" {
+		pem, err := os.ReadFile(cfg.CertKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read certificate key file: %w", err)
+		}
+		cert, err := tls.X509KeyPair(pem, pem)
+		if err != nil {
+			return nil, fmt.Errorf("certificate key file %s: %w", cfg.CertKeyFile, err)
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	}
+	return tc, nil
+}
+
+// uriTLSOptions maps the TLS options of a connection string, lowercased,
+// to the TLSConfig setting they correspond to.
+var uriTLSOptions = map[string]string{
+	"tlscafile":                   "ca",
+	"sslcertificateauthorityfile": "ca",
+	"tlscertificatekeyfile":       "cert",
+	"sslclientcertificatekeyfile": "cert",
+	"tlsinsecure":                 "insecure",
+	"tlsallowinvalidcertificates": "insecure",
+	"sslallowinvalidcertificates": "insecure",
+	"tlsallowinvalidhostnames":    "insecure",
+	"sslallowinvalidhostnames":    "insecure",
+}
+
+// clientTLSConfig applies cfg on top of base, the TLS configuration the
+// driver built from the connection string. Settings the connection string
+// makes itself win: cfg only fills in what the URI leaves unset.
+func clientTLSConfig(uri string, base *tls.Config, cfg TLSConfig) (*tls.Config, error) {
+	if u, err := url.Parse(uri); err == nil {
+		for key := range u.Query() {
+			switch uriTLSOptions[strings.ToLower(key)] {
+			case "ca":
+				cfg.CAFile = ""
+			case "cert":
+				cfg.CertKeyFile = ""
+			case "insecure":
+				cfg.Insecure = false
+			}
+		}
+	}
+	if !cfg.Enabled() {
+		return base, nil
+	}
+	tc, err := buildTLSConfig(cfg)
+	if err != nil || base == nil {
+		return tc, err
+	}
+	merged := base.Clone()
+	if cfg.CAFile != "" {
+		merged.RootCAs = tc.RootCAs
+	}
+	if cfg.CertKeyFile != "" {
+		merged.Certificates = tc.Certificates
+	}
+	if cfg.Insecure {
+		merged.InsecureSkipVerify = true //nolint:gosec // explicit --tls-insecure opt-in
+	}
+	return merged, nil
+}
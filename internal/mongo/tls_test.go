@@ -0,0 +1,142 @@
+package mongo
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestCert writes a self-signed certificate and its key to dir, as a
+// combined PEM file and as a certificate-only file.
+func writeTestCert(t *testing.T, dir string) (certKeyFile, certFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "mongospectre-test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	certKeyFile = filepath.Join(dir, "client.pem")
+	certFile = filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(certKeyFile, append(certPEM, keyPEM...), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certKeyFile, certFile
+}
+
+func TestBuildTLSConfig(t *testing.T) {
+	certKeyFile, caFile := writeTestCert(t, t.TempDir())
+
+	tc, err := buildTLSConfig(TLSConfig{CAFile: caFile, CertKeyFile: certKeyFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tc.RootCAs == nil {
+		t.Error("expected RootCAs from CA file")
+	}
+	if len(tc.Certificates) != 1 {
+		t.Errorf("expected 1 client certificate, got %d", len(tc.Certificates))
+	}
+	if tc.InsecureSkipVerify {
+		t.Error("InsecureSkipVerify should be off by default")
+	}
+}
+
+func TestBuildTLSConfig_Errors(t *testing.T) {
+	dir := t.TempDir()
+	_, caFile := writeTestCert(t, dir)
+	notPEM := filepath.Join(dir, "junk.pem")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  TLSConfig
+		want string
+	}{
+		{"missing CA", TLSConfig{CAFile: filepath.Join(dir, "nope.pem")}, "read CA file"},
+		{"CA without certificates", TLSConfig{CAFile: notPEM}, "no PEM certificates"},
+		{"cert without key", TLSConfig{CertKeyFile: caFile}, "certificate key file"},
+		{"missing cert", TLSConfig{CAFile: caFile, CertKeyFile: filepath.Join(dir, "nope.pem")}, "read certificate key file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := buildTLSConfig(tt.cfg)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTLSConfigEnabled(t *testing.T) {
+	if (TLSConfig{}).Enabled() {
+		t.Error("empty TLSConfig should not be enabled")
+	}
+	if !(TLSConfig{Insecure: true}).Enabled() {
+		t.Error("Insecure alone should enable TLS")
+	}
+}
+
+func TestClientTLSConfig_URIWins(t *testing.T) {
+	certKeyFile, caFile := writeTestCert(t, t.TempDir())
+	base := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: "db.example.com"}
+
+	// The URI names its own CA: the flag's CA file is not applied, but the
+	// client certificate the URI leaves unset is.
+	tc, err := clientTLSConfig("mongodb://db.example.com/?tls=true&tlsCAFile=/etc/uri-ca.pem", base,
+		TLSConfig{CAFile: caFile, CertKeyFile: certKeyFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tc.RootCAs != nil {
+		t.Error("flag CA file should not override tlsCAFile from the URI")
+	}
+	if len(tc.Certificates) != 1 || tc.ServerName != "db.example.com" {
+		t.Errorf("expected the URI config plus the client certificate, got %+v", tc)
+	}
+	if base.Certificates != nil {
+		t.Error("the driver's config must not be modified")
+	}
+
+	// Every flag is set by the URI: the driver's config is used as is.
+	tc, err = clientTLSConfig("mongodb://db.example.com/?tlsInsecure=false&tlsCAFile=/etc/uri-ca.pem", base,
+		TLSConfig{CAFile: caFile, Insecure: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tc != base {
+		t.Errorf("expected the URI config, got %+v", tc)
+	}
+}
@@ -0,0 +1,60 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// DefaultTransactionLifetimeSeconds is the server default of
+// transactionLifetimeLimitSeconds.
+const DefaultTransactionLifetimeSeconds = 60
+
+// TransactionStats are the transaction counters of serverStatus since
+// startup, and the lifetime after which the server aborts a transaction.
+type TransactionStats struct {
+	Started         int64 `json:"started"`
+	Committed       int64 `json:"committed"`
+	Aborted         int64 `json:"aborted"`
+	CurrentOpen     int64 `json:"currentOpen"`
+	CurrentActive   int64 `json:"currentActive"`
+	LifetimeSeconds int64 `json:"lifetimeSeconds"`
+}
+
+// InspectTransactions reads the transactions section of serverStatus and
+// transactionLifetimeLimitSeconds. When the parameter cannot be read, the
+// server default is assumed.
+func (i *Inspector) InspectTransactions(ctx context.Context) (TransactionStats, error) {
+	cmd := bson.D{
+		{Key: "serverStatus", Value: 1},
+		{Key: "repl", Value: 0},
+		{Key: "metrics", Value: 0},
+		{Key: "locks", Value: 0},
+	}
+	var status bson.M
+	if err := i.db.RunCommand(ctx, "admin", cmd).Decode(&status); err != nil {
+		return TransactionStats{}, fmt.Errorf("serverStatus: %w", err)
+	}
+	tx := toBsonM(status["transactions"])
+	if tx == nil {
+		return TransactionStats{}, fmt.Errorf("serverStatus reports no transaction metrics")
+	}
+	stats := TransactionStats{
+		Started:         toInt64(tx["totalStarted"]),
+		Committed:       toInt64(tx["totalCommitted"]),
+		Aborted:         toInt64(tx["totalAborted"]),
+		CurrentOpen:     toInt64(tx["currentOpen"]),
+		CurrentActive:   toInt64(tx["currentActive"]),
+		LifetimeSeconds: DefaultTransactionLifetimeSeconds,
+	}
+
+	var param bson.M
+	paramCmd := bson.D{{Key: "getParameter", Value: 1}, {Key: "transactionLifetimeLimitSeconds", Value: 1}}
+	if err := i.db.RunCommand(ctx, "admin", paramCmd).Decode(&param); err == nil {
+		if v := toInt64(param["transactionLifetimeLimitSeconds"]); v > 0 {
+			stats.LifetimeSeconds = v
+		}
+	}
+	return stats, nil
+}
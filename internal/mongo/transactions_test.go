@@ -0,0 +1,53 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func TestInspectTransactions(t *testing.T) {
+	mc := &mockClient{runCmdHook: func(_ string, cmd any) (bson.Raw, error) {
+		if cmd.(bson.D)[0].Key == "getParameter" {
+			return bson.Marshal(bson.M{"ok": 1, "transactionLifetimeLimitSeconds": int32(30)})
+		}
+		return bson.Marshal(bson.M{"ok": 1, "transactions": bson.M{
+			"totalStarted":   int64(1000),
+			"totalCommitted": int64(850),
+			"totalAborted":   int64(150),
+			"currentOpen":    int32(4),
+			"currentActive":  int32(1),
+		}})
+	}}
+	stats, err := (&Inspector{db: mc}).InspectTransactions(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := TransactionStats{Started: 1000, Committed: 850, Aborted: 150, CurrentOpen: 4, CurrentActive: 1, LifetimeSeconds: 30}
+	if stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+}
+
+func TestInspectTransactions_Errors(t *testing.T) {
+	// Without the parameter the server default applies.
+	raw, _ := bson.Marshal(bson.M{"ok": 1, "transactions": bson.M{"totalStarted": int64(5)}})
+	stats, err := (&Inspector{db: &mockClient{runCmdResult: raw}}).InspectTransactions(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Started != 5 || stats.LifetimeSeconds != DefaultTransactionLifetimeSeconds {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	raw, _ = bson.Marshal(bson.M{"ok": 1, "process": "mongod"})
+	if _, err := (&Inspector{db: &mockClient{runCmdResult: raw}}).InspectTransactions(context.TODO()); err == nil {
+		t.Fatal("expected error without transaction metrics")
+	}
+	insp := &Inspector{db: &mockClient{runCmdErr: errors.New("not authorized on admin")}}
+	if _, err := insp.InspectTransactions(context.TODO()); err == nil {
+		t.Fatal("expected error")
+	}
+}
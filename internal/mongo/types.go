@@ -6,6 +6,52 @@ import "time"
 type Config struct {
 	URI      string
 	Database string // empty = all non-system databases
+	TLS      TLSConfig
+	// Concurrency is how many databases per-database metadata is collected
+	// from at once; <= 0 uses DefaultConcurrency.
+	Concurrency int
+	// DatabaseTimeout bounds the work on each database; 0 leaves only the
+	// caller's deadline.
+	DatabaseTimeout time.Duration
+	// Retries is how many times a read is retried after a transient error
+	// such as a replica set election; 0 disables retries.
+	Retries int
+	// Progress, when set, is told about progress through Inspect and
+	// SampleDocuments.
+	Progress Progress
+	// Quick limits Inspect to listCollections and index definitions, with
+	// no $collStats or $indexStats, so collections carry no counts, sizes,
+	// latency or index usage.
+	Quick bool
+	// StrictReadOnly refuses any command outside ReadOnlyCommands, and
+	// aggregations with $out or $merge, with a *ReadOnlyError. After the
+	// first refusal every call fails.
+	StrictReadOnly bool
+	// OnCommand, when set, is told about every command before it is sent,
+	// including the getMore and killCursors commands the driver sends
+	// while iterating cursors, and about commands StrictReadOnly refuses.
+	// Calls may come from several goroutines at once.
+	OnCommand func(CommandEvent)
+	// CommandLog, when set, is told about every command the driver ran,
+	// with its duration and reply size, once it finished. Calls may come
+	// from several goroutines at once.
+	CommandLog func(ExecutedCommand)
+	// PIIPatterns, when set, are matched against the string values of
+	// sampled documents; FieldSampleResult.PII holds the match counts.
+	PIIPatterns []PIIPattern
+}
+
+// TLSConfig holds client TLS settings applied on top of the URI. Any set
+// field enables TLS for the connection.
+type TLSConfig struct {
+	CAFile      string // PEM bundle of CAs trusted for the server certificate
+	CertKeyFile string // PEM file with the client certificate and its unencrypted key
+	Insecure    bool   // skip server certificate and hostname verification
+}
+
+// Enabled reports whether any TLS setting is present.
+func (c TLSConfig) Enabled() bool {
+	return c.CAFile != "" || c.CertKeyFile != "" || c.Insecure
 }
 
 // DatabaseInfo describes a MongoDB database.
@@ -27,6 +73,50 @@ type CollectionInfo struct {
 	TotalIndexSize int64          `json:"totalIndexSize"` // total size of all indexes in bytes
 	Indexes        []IndexInfo    `json:"indexes"`
 	Validator      *ValidatorInfo `json:"validator,omitempty"`
+	// EncryptedFields are the Queryable Encryption field paths from the
+	// collection's encryptedFields option.
+	EncryptedFields []string `json:"encryptedFields,omitempty"`
+	// EncryptedQueries are the query types ("equality", "range", ...) of
+	// the queryable ones among them, by path.
+	EncryptedQueries map[string][]string `json:"encryptedQueries,omitempty"`
+	// UUID identifies the collection across renames; a dropped and
+	// recreated collection gets a new one. Hex encoded.
+	UUID string `json:"uuid,omitempty"`
+	// Latency is cumulative operation latency since server start from
+	// $collStats, nil when the stage is unavailable.
+	Latency *LatencyStats `json:"latency,omitempty"`
+	// DataAge is the span of document times seen by --sample-size, nil
+	// without sampling.
+	DataAge *DataAge `json:"dataAge,omitempty"`
+	// ReusableBytes and IndexReusableBytes are free space inside the
+	// collection's and its indexes' WiredTiger files ("file bytes available
+	// for reuse"): reused for new data, but not returned to the OS.
+	ReusableBytes      int64 `json:"reusableBytes,omitempty"`
+	IndexReusableBytes int64 `json:"indexReusableBytes,omitempty"`
+	// SearchIndexes are Atlas Search and Vector Search indexes; only
+	// listed by check for collections that code runs $vectorSearch on.
+	SearchIndexes []SearchIndex `json:"searchIndexes,omitempty"`
+}
+
+// LatencyStats holds per-collection operation latency from $collStats.
+type LatencyStats struct {
+	Reads    OpLatency `json:"reads"`
+	Writes   OpLatency `json:"writes"`
+	Commands OpLatency `json:"commands"`
+}
+
+// OpLatency is the latency of one operation class.
+type OpLatency struct {
+	Ops         int64           `json:"ops"`
+	TotalMicros int64           `json:"totalMicros"`
+	Histogram   []LatencyBucket `json:"histogram,omitempty"` // sorted by Micros
+}
+
+// LatencyBucket counts operations whose latency fell between Micros and the
+// next bucket's lower bound.
+type LatencyBucket struct {
+	Micros int64 `json:"micros"`
+	Count  int64 `json:"count"`
 }
 
 // ValidatorInfo describes collection-level JSON Schema validation settings.
@@ -48,12 +138,14 @@ type ValidatorSchema struct {
 // ValidatorField captures expected BSON types for a single schema property.
 type ValidatorField struct {
 	BSONTypes []string `json:"bsonTypes,omitempty"`
+	Encrypted bool     `json:"encrypted,omitempty"` // CSFLE encrypt keyword
 }
 
 // KeyField is an ordered index key element.
 type KeyField struct {
 	Field     string `json:"field"`
-	Direction int    `json:"direction"` // 1 (asc) or -1 (desc)
+	Direction int    `json:"direction"`      // 1 (asc) or -1 (desc)
+	Kind      string `json:"kind,omitempty"` // "hashed", "text", "2dsphere" or "2d" for non-directional keys
 }
 
 // IndexInfo describes a single index on a collection.
@@ -62,9 +154,21 @@ type IndexInfo struct {
 	Key    []KeyField  `json:"key"`
 	Unique bool        `json:"unique,omitempty"`
 	Sparse bool        `json:"sparse,omitempty"`
+	Hidden bool        `json:"hidden,omitempty"`
 	TTL    *int32      `json:"ttl,omitempty"`  // TTL seconds, nil if not a TTL index
 	Size   int64       `json:"size,omitempty"` // index size in bytes from collStats.indexSizes
 	Stats  *IndexStats `json:"stats,omitempty"`
+
+	// PartialFilter is the partialFilterExpression as relaxed extended JSON.
+	PartialFilter string `json:"partialFilter,omitempty"`
+	// WildcardProjection is the wildcardProjection of a $** index as relaxed
+	// extended JSON.
+	WildcardProjection string `json:"wildcardProjection,omitempty"`
+	// TextFields lists the fields covered by a text index (the keys of its
+	// weights document), sorted.
+	TextFields []string `json:"textFields,omitempty"`
+	// Collation is the index collation as relaxed extended JSON.
+	Collation string `json:"collation,omitempty"`
 }
 
 // IndexStats holds usage statistics for an index.
@@ -80,14 +184,22 @@ type ServerInfo struct {
 
 // ProfileEntry represents a normalized slow-query profiler document shape.
 type ProfileEntry struct {
-	Database         string    `json:"database"`
-	Collection       string    `json:"collection"`
-	FilterFields     []string  `json:"filterFields,omitempty"`
-	SortFields       []string  `json:"sortFields,omitempty"`
-	ProjectionFields []string  `json:"projectionFields,omitempty"`
-	DurationMillis   int64     `json:"durationMillis"`
-	Timestamp        time.Time `json:"timestamp"`
-	PlanSummary      string    `json:"planSummary,omitempty"`
+	Database         string     `json:"database"`
+	Collection       string     `json:"collection"`
+	Op               string     `json:"op,omitempty"` // profiler op: query, getmore, insert, update, remove, command
+	FilterFields     []string   `json:"filterFields,omitempty"`
+	SortFields       []string   `json:"sortFields,omitempty"`
+	ProjectionFields []string   `json:"projectionFields,omitempty"`
+	DurationMillis   int64      `json:"durationMillis"`
+	Timestamp        time.Time  `json:"timestamp"`
+	PlanSummary      string     `json:"planSummary,omitempty"`
+	DocsExamined     int64      `json:"docsExamined,omitempty"`
+	KeysExamined     int64      `json:"keysExamined,omitempty"`
+	NReturned        int64      `json:"nreturned,omitempty"`
+	SortKeys         []KeyField `json:"sortKeys,omitempty"`     // command sort spec in order, with directions
+	HasSortStage     bool       `json:"hasSortStage,omitempty"` // blocking in-memory SORT stage
+	UsedDisk         bool       `json:"usedDisk,omitempty"`     // sort spilled to disk
+	AppName          string     `json:"appName,omitempty"`      // appName the client connected with
 }
 
 // UserRole describes a single role assigned to a user.
@@ -101,6 +213,44 @@ type UserInfo struct {
 	Username string     `json:"user" bson:"user"`
 	Database string     `json:"db"   bson:"db"`
 	Roles    []UserRole `json:"roles" bson:"roles"`
+	// Mechanisms lists the SASL mechanisms the user's credentials support.
+	// Empty when the user came from a source without credential details.
+	Mechanisms                 []string          `json:"mechanisms,omitempty" bson:"mechanisms"`
+	AuthenticationRestrictions []AuthRestriction `json:"authenticationRestrictions,omitempty" bson:"authenticationRestrictions"`
+}
+
+// AuthRestriction limits where a user may connect from (clientSource) and
+// which server addresses it may connect to (serverAddress).
+type AuthRestriction struct {
+	ClientSource  []string `json:"clientSource,omitempty"  bson:"clientSource"`
+	ServerAddress []string `json:"serverAddress,omitempty" bson:"serverAddress"`
+}
+
+// RoleInfo describes a user-defined role from rolesInfo with showPrivileges.
+type RoleInfo struct {
+	Role       string      `json:"role" bson:"role"`
+	Database   string      `json:"db"   bson:"db"`
+	IsBuiltin  bool        `json:"isBuiltin,omitempty" bson:"isBuiltin"`
+	Roles      []UserRole  `json:"roles,omitempty" bson:"roles"`
+	Privileges []Privilege `json:"privileges,omitempty" bson:"privileges"`
+	// InheritedPrivileges is the server's expansion of Privileges plus those
+	// of every inherited role.
+	InheritedPrivileges []Privilege `json:"inheritedPrivileges,omitempty" bson:"inheritedPrivileges"`
+}
+
+// Privilege grants actions on a resource.
+type Privilege struct {
+	Resource PrivilegeResource `json:"resource" bson:"resource"`
+	Actions  []string          `json:"actions"  bson:"actions"`
+}
+
+// PrivilegeResource is a privilege target: a database/collection pair (empty
+// strings match any), the cluster, or any resource.
+type PrivilegeResource struct {
+	DB          string `json:"db,omitempty"          bson:"db"`
+	Collection  string `json:"collection,omitempty"  bson:"collection"`
+	Cluster     bool   `json:"cluster,omitempty"     bson:"cluster"`
+	AnyResource bool   `json:"anyResource,omitempty" bson:"anyResource"`
 }
 
 // ShardingInfo captures cluster-level sharding metadata used for audit checks.
@@ -131,6 +281,63 @@ type SecurityInfo struct {
 	BindIP               string `json:"bindIp"`
 	AuditLogEnabled      bool   `json:"auditLogEnabled"`
 	LocalhostAuthBypass  bool   `json:"localhostAuthBypass"`
+
+	// AuthMechanisms lists the enabled authenticationMechanisms.
+	AuthMechanisms []string `json:"authMechanisms,omitempty"`
+	// LDAP is set when security.ldap is configured (Enterprise).
+	LDAP *LDAPConfig `json:"ldap,omitempty"`
+	// OIDCProviders are the configured oidcIdentityProviders (Enterprise).
+	OIDCProviders []OIDCProvider `json:"oidcProviders,omitempty"`
+
+	// CmdLineOpts reports whether getCmdLineOpts was readable; without it
+	// the bind IP, audit log and encryption settings are unknown.
+	CmdLineOpts bool `json:"cmdLineOpts,omitempty"`
+	// EncryptionAtRest is security.enableEncryption (Enterprise WiredTiger).
+	EncryptionAtRest bool `json:"encryptionAtRest"`
+	// KMIPServer is security.kmip.serverName; empty with a local key file.
+	KMIPServer string `json:"kmipServer,omitempty"`
+	// DataKeys are the CSFLE/Queryable Encryption data keys in the key vault.
+	DataKeys []DataKeyInfo `json:"dataKeys,omitempty"`
+}
+
+// ServerParameters holds server settings for drift checks. Values maps
+// getParameter names (logLevel) and getCmdLineOpts paths
+// (storage.journal.enabled) to scalar values rendered as strings.
+type ServerParameters struct {
+	Security SecurityInfo      `json:"security"`
+	Values   map[string]string `json:"values"`
+	// CmdLineOpts reports whether getCmdLineOpts was readable. Settings
+	// missing from Values then use the server default.
+	CmdLineOpts bool `json:"cmdLineOpts"`
+}
+
+// DataKeyInfo describes one key vault document. Key material is never read.
+type DataKeyInfo struct {
+	ID       string    `json:"id"`
+	AltNames []string  `json:"altNames,omitempty"`
+	Provider string    `json:"provider"` // masterKey.provider: kmip, aws, azure, gcp, local
+	Created  time.Time `json:"created"`
+	Updated  time.Time `json:"updated"`
+}
+
+// LDAPConfig holds the LDAP settings relevant to a hardening audit. Bind
+// credentials are never read.
+type LDAPConfig struct {
+	Servers              string `json:"servers"`
+	TransportSecurity    string `json:"transportSecurity"` // "tls" (default) or "none"
+	ValidateServerConfig bool   `json:"validateServerConfig"`
+	UserToDNMapping      string `json:"userToDNMapping,omitempty"`
+	AuthzQueryTemplate   string `json:"authzQueryTemplate,omitempty"`
+	// CacheInvalidationSecs is ldapUserCacheInvalidationInterval; 0 when unknown.
+	CacheInvalidationSecs int64 `json:"cacheInvalidationSecs,omitempty"`
+}
+
+// OIDCProvider is one entry of the oidcIdentityProviders server parameter.
+type OIDCProvider struct {
+	Issuer                string `json:"issuer"`
+	Audience              string `json:"audience,omitempty"`
+	AuthorizationClaim    string `json:"authorizationClaim,omitempty"`
+	UseAuthorizationClaim bool   `json:"useAuthorizationClaim"`
 }
 
 // ReplicaSetInfo holds replica set topology and oplog metadata.
@@ -153,13 +360,19 @@ type ReplicaSetMember struct {
 
 // FieldSampleResult holds sampled field frequency data for one collection.
 type FieldSampleResult struct {
-	Database      string           `json:"database"`
-	Collection    string           `json:"collection"`
-	SampleSize    int64            `json:"sampleSize"`
-	Fields        []FieldFrequency `json:"fields"`
-	MaxDocSize    int64            `json:"maxDocSize,omitempty"`    // largest serialized doc in bytes
-	MaxFieldCount int              `json:"maxFieldCount,omitempty"` // most top-level fields in any doc
-	ArrayLengths  map[string]int64 `json:"arrayLengths,omitempty"`  // field path → max observed array length
+	Database      string            `json:"database"`
+	Collection    string            `json:"collection"`
+	SampleSize    int64             `json:"sampleSize"`
+	Fields        []FieldFrequency  `json:"fields"`
+	MaxDocSize    int64             `json:"maxDocSize,omitempty"`    // largest serialized doc in bytes
+	MaxFieldCount int               `json:"maxFieldCount,omitempty"` // most top-level fields in any doc
+	ArrayLengths  map[string]int64  `json:"arrayLengths,omitempty"`  // field path → max observed array length
+	DottedKeys    []string          `json:"dottedKeys,omitempty"`    // field paths whose own key contains a literal "."
+	MemoryCapped  bool              `json:"memoryCapped,omitempty"`  // sampling stopped at the per-collection byte limit
+	PII           []FieldPIIMatches `json:"pii,omitempty"`           // fields whose values matched Config.PIIPatterns
+	DateRanges    []DateRange       `json:"dateRanges,omitempty"`    // _id and well-known date fields, by field
+	CreatedByYear map[int]int64     `json:"createdByYear,omitempty"` // sampled documents per ObjectId _id creation year
+	Vectors       []VectorSample    `json:"vectors,omitempty"`       // embedding fields, by path
 }
 
 // FieldFrequency tracks how often a field path appears and its BSON types.
@@ -168,3 +381,18 @@ type FieldFrequency struct {
 	Count int64            `json:"count"`
 	Types map[string]int64 `json:"types"`
 }
+
+// NamespaceWrites counts the writes to one collection seen in an oplog or
+// change stream sample.
+type NamespaceWrites struct {
+	Database   string `json:"database"`
+	Collection string `json:"collection"`
+	Inserts    int64  `json:"inserts"`
+	Updates    int64  `json:"updates"`
+	Deletes    int64  `json:"deletes"`
+}
+
+// Writes is the namespace's total sampled write count.
+func (n NamespaceWrites) Writes() int64 {
+	return n.Inserts + n.Updates + n.Deletes
+}
@@ -0,0 +1,76 @@
+package mongo
+
+import "strings"
+
+// redactedPassword replaces the password in redacted connection strings.
+const redactedPassword = "***"
+
+// RedactURI masks the password in a MongoDB connection string so it can be
+// logged. It works on the raw string rather than net/url, which rejects the
+// comma-separated host lists of replica set URIs.
+func RedactURI(rawURI string) string {
+	start, end := passwordSpan(rawURI)
+	if start < 0 {
+		return rawURI
+	}
+	return rawURI[:start] + redactedPassword + rawURI[end:]
+}
+
+// uriPassword returns the raw (still percent-encoded) password in a
+// connection string, or "".
+func uriPassword(rawURI string) string {
+	start, end := passwordSpan(rawURI)
+	if start < 0 {
+		return ""
+	}
+	return rawURI[start:end]
+}
+
+// passwordSpan locates the password between "user:" and "@" in the
+// authority of a connection string. It returns -1, -1 when there is none.
+func passwordSpan(rawURI string) (int, int) {
+	scheme := strings.Index(rawURI, "://")
+	if scheme < 0 {
+		return -1, -1
+	}
+	authStart := scheme + len("://")
+	authority := rawURI[authStart:]
+	if i := strings.IndexAny(authority, "/?"); i >= 0 {
+		authority = authority[:i]
+	}
+	at := strings.LastIndexByte(authority, '@')
+	if at < 0 {
+		return -1, -1
+	}
+	colon := strings.IndexByte(authority[:at], ':')
+	if colon < 0 || colon+1 == at {
+		return -1, -1
+	}
+	return authStart + colon + 1, authStart + at
+}
+
+// redactedError hides a secret that a wrapped error message may echo back,
+// such as a driver error quoting the connection string.
+type redactedError struct {
+	err    error
+	secret string
+}
+
+func (e *redactedError) Error() string {
+	return strings.ReplaceAll(e.err.Error(), e.secret, redactedPassword)
+}
+
+func (e *redactedError) Unwrap() error { return e.err }
+
+// redactURIError wraps err so its message never contains the password from
+// rawURI.
+func redactURIError(err error, rawURI string) error {
+	if err == nil {
+		return nil
+	}
+	pw := uriPassword(rawURI)
+	if pw == "" || !strings.Contains(err.Error(), pw) {
+		return err
+	}
+	return &redactedError{err: err, secret: pw}
+}
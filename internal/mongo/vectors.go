@@ -0,0 +1,131 @@
+package mongo
+
+import (
+	"encoding/binary"
+	"math"
+	"sort"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// vectorMinDimensions is the shortest numeric array taken for an embedding.
+const vectorMinDimensions = 16
+
+// bsonVectorSubtype is the binary subtype of BSON vectors.
+const bsonVectorSubtype = 9
+
+// VectorSample describes the embeddings found in one field of the sampled
+// documents: numeric arrays of at least vectorMinDimensions elements, or
+// BSON binary vectors.
+type VectorSample struct {
+	Path  string `json:"path"`
+	Count int64  `json:"count"`
+	// Dimensions counts vectors by length.
+	Dimensions map[int]int64 `json:"dimensions"`
+	// Norms is how many float vectors had their length computed and Unit
+	// how many of them were unit length, as dotProduct similarity needs.
+	Norms int64 `json:"norms,omitempty"`
+	Unit  int64 `json:"unit,omitempty"`
+}
+
+// vectorTracker collects VectorSamples from sampled documents. Arrays of
+// embedded documents are not descended into.
+type vectorTracker struct {
+	fields map[string]*VectorSample
+}
+
+func newVectorTracker() *vectorTracker {
+	return &vectorTracker{fields: make(map[string]*VectorSample)}
+}
+
+func (t *vectorTracker) add(doc bson.M, prefix string) {
+	for key, val := range doc {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		switch v := val.(type) {
+		case bson.M:
+			t.add(v, path)
+		case bson.D:
+			t.add(toBsonM(v), path)
+		case bson.A:
+			if len(v) < vectorMinDimensions {
+				continue
+			}
+			var sum float64
+			numeric := true
+			for _, elem := range v {
+				switch elem.(type) {
+				case float64, int32, int64:
+					f := toFloat64(elem)
+					sum += f * f
+				default:
+					numeric = false
+				}
+			}
+			if numeric {
+				t.record(path, len(v), sum, true)
+			}
+		case bson.Binary:
+			if dims, sum, ok := binaryVector(v); ok {
+				t.record(path, dims, sum, sum >= 0)
+			}
+		}
+	}
+}
+
+func (t *vectorTracker) record(path string, dims int, sumSquares float64, hasNorm bool) {
+	s := t.fields[path]
+	if s == nil {
+		s = &VectorSample{Path: path, Dimensions: make(map[int]int64)}
+		t.fields[path] = s
+	}
+	s.Count++
+	s.Dimensions[dims]++
+	if hasNorm {
+		s.Norms++
+		if math.Abs(math.Sqrt(sumSquares)-1) <= 0.01 {
+			s.Unit++
+		}
+	}
+}
+
+// result returns the samples sorted by path, or nil if none were seen.
+func (t *vectorTracker) result() []VectorSample {
+	if len(t.fields) == 0 {
+		return nil
+	}
+	out := make([]VectorSample, 0, len(t.fields))
+	for _, s := range t.fields {
+		out = append(out, *s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+// binaryVector decodes the dimension count of a BSON vector: a dtype byte,
+// a padding byte, then float32, int8 or packed bit elements. The sum of
+// squares is returned for float32 vectors, -1 for the others.
+func binaryVector(b bson.Binary) (dims int, sumSquares float64, ok bool) {
+	if b.Subtype != bsonVectorSubtype || len(b.Data) < 2 {
+		return 0, 0, false
+	}
+	data := b.Data[2:]
+	switch b.Data[0] {
+	case 0x27: // float32
+		if len(data)%4 != 0 {
+			return 0, 0, false
+		}
+		for j := 0; j < len(data); j += 4 {
+			f := float64(math.Float32frombits(binary.LittleEndian.Uint32(data[j:])))
+			sumSquares += f * f
+		}
+		return len(data) / 4, sumSquares, true
+	case 0x03: // int8
+		return len(data), -1, true
+	case 0x10: // packed bit
+		return len(data)*8 - int(b.Data[1]), -1, true
+	}
+	return 0, 0, false
+}
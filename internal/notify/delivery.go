@@ -0,0 +1,250 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Delivery statuses recorded in the notification history.
+const (
+	DeliverySent        = "sent"
+	DeliveryFailed      = "failed"
+	DeliveryDryRun      = "dry_run"
+	DeliveryRateLimited = "rate_limited"
+	DeliveryQueued      = "queued"  // added to a digest batch
+	DeliveryHeld        = "held"    // kept until a quiet period ends
+	DeliveryDropped     = "dropped" // discarded by a drop window or --silence
+)
+
+// allChannels is the channel recorded for outcomes decided before routing,
+// such as quiet hours.
+const allChannels = "*"
+
+// historyMaxBytes is the size at which the history file is rotated to a
+// single ".1" backup when a dispatcher starts.
+const historyMaxBytes = 10 << 20
+
+// Delivery is one notification outcome, as kept in the history file.
+type Delivery struct {
+	Time     time.Time `json:"time"`
+	Channel  string    `json:"channel"`           // e.g. slack[0]
+	Event    string    `json:"event"`             // event type, or "digest"
+	Finding  string    `json:"finding,omitempty"` // type and location; empty for digests
+	Events   int       `json:"events,omitempty"`  // events in a digest
+	Status   string    `json:"status"`
+	Attempts int       `json:"attempts,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// DeliveryStats counts one channel's outcomes for the watch shutdown summary.
+type DeliveryStats struct {
+	Channel   string         `json:"channel"`
+	Counts    map[string]int `json:"counts"`
+	Retries   int            `json:"retries,omitempty"`
+	LastError string         `json:"lastError,omitempty"`
+}
+
+// Stats returns per-channel delivery counts since the dispatcher started.
+func (d *Dispatcher) Stats() []DeliveryStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	out := make([]DeliveryStats, 0, len(d.stats))
+	for _, s := range d.stats {
+		counts := make(map[string]int, len(s.Counts))
+		for k, v := range s.Counts {
+			counts[k] = v
+		}
+		out = append(out, DeliveryStats{Channel: s.Channel, Counts: counts, Retries: s.Retries, LastError: s.LastError})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Channel < out[j].Channel })
+	return out
+}
+
+// record counts a delivery and appends it to the history file, if any.
+// A history write failure is logged rather than failing the notification.
+func (d *Dispatcher) record(del Delivery) {
+	del.Time = d.now().UTC()
+
+	d.mu.Lock()
+	s := d.stats[del.Channel]
+	if s == nil {
+		s = &DeliveryStats{Channel: del.Channel, Counts: make(map[string]int)}
+		d.stats[del.Channel] = s
+	}
+	s.Counts[del.Status]++
+	if del.Attempts > 1 {
+		s.Retries += del.Attempts - 1
+	}
+	if del.Error != "" {
+		s.LastError = del.Error
+	}
+	d.mu.Unlock()
+
+	if d.historyPath == "" {
+		return
+	}
+	data, err := json.Marshal(del)
+	if err != nil {
+		return
+	}
+	d.historyMu.Lock()
+	defer d.historyMu.Unlock()
+	if err := appendLine(d.historyPath, data); err != nil {
+		_, _ = fmt.Fprintf(d.writer, "[notify] history: %v\n", err)
+	}
+}
+
+func appendLine(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// openHistory creates the history file's directory and rotates a file
+// that has grown past historyMaxBytes.
+func openHistory(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("history: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil || info.Size() < historyMaxBytes {
+		return nil
+	}
+	if err := os.Rename(path, path+".1"); err != nil {
+		return fmt.Errorf("history: rotate: %w", err)
+	}
+	return nil
+}
+
+// ReadDeliveries returns the deliveries in a history file and its rotated
+// backup, oldest first. A missing file is an empty history; unreadable
+// lines, such as one cut short by a crash, are skipped.
+func ReadDeliveries(path string) ([]Delivery, error) {
+	var out []Delivery
+	for _, p := range []string{path + ".1", path} {
+		f, err := os.Open(p)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		sc := bufio.NewScanner(f)
+		sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+		for sc.Scan() {
+			var del Delivery
+			if json.Unmarshal(sc.Bytes(), &del) == nil {
+				out = append(out, del)
+			}
+		}
+		err = sc.Err()
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+	}
+	return out, nil
+}
+
+// deliver runs send, retrying transient failures up to the configured
+// number of retries, and records the outcome.
+func (d *Dispatcher) deliver(ctx context.Context, del Delivery, send func(context.Context) error) error {
+	var err error
+	for {
+		del.Attempts++
+		err = send(ctx)
+		if err == nil || del.Attempts > d.retries || !isTransient(err) || !sleepCtx(ctx, d.retryDelay*time.Duration(del.Attempts)) {
+			break
+		}
+	}
+
+	switch {
+	case err != nil:
+		del.Status, del.Error = DeliveryFailed, err.Error()
+	case d.dryRun:
+		del.Status = DeliveryDryRun
+	default:
+		del.Status = DeliverySent
+	}
+	d.record(del)
+	return err
+}
+
+func sleepCtx(ctx context.Context, delay time.Duration) bool {
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// deliverEvent sends one event to a channel through deliver.
+func (d *Dispatcher) deliverEvent(ctx context.Context, ch channel, event *Event) error {
+	return d.deliver(ctx, Delivery{Channel: ch.id, Event: string(event.Type), Finding: findingLabel(event)}, func(ctx context.Context) error {
+		return d.sendEvent(ctx, ch, event)
+	})
+}
+
+func findingLabel(event *Event) string {
+	return strings.TrimSpace(string(event.Finding.Type) + " " + eventLocation(event))
+}
+
+// httpStatusError is a notification endpoint's error response.
+type httpStatusError struct {
+	code int
+	body string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("http %d: %s", e.code, e.body)
+}
+
+// redactedError hides a secret, such as a token in a URL, from an error
+// message while keeping the error chain for isTransient.
+type redactedError struct {
+	err    error
+	secret string
+}
+
+func (e *redactedError) Error() string {
+	return strings.ReplaceAll(e.err.Error(), e.secret, "<redacted>")
+}
+
+func (e *redactedError) Unwrap() error { return e.err }
+
+// isTransient reports whether a failed send is worth retrying: network
+// errors, HTTP 429 and 5xx responses, and SMTP 4xx replies.
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code == 429 || statusErr.code >= 500
+	}
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) {
+		return smtpErr.Code >= 400 && smtpErr.Code < 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
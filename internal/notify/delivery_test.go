@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	"github.com/ppiankov/mongospectre/internal/config"
+)
+
+// flakyRoundTripper answers with the given statuses in turn, then 200.
+type flakyRoundTripper struct {
+	mu       sync.Mutex
+	statuses []int
+	calls    int
+}
+
+func (f *flakyRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	status := http.StatusOK
+	if f.calls < len(f.statuses) {
+		status = f.statuses[f.calls]
+	}
+	f.calls++
+	return (&recordingRoundTripper{status: status}).RoundTrip(req)
+}
+
+func TestDispatcherRecordsDeliveries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history", "notifications.jsonl")
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	rt := &flakyRoundTripper{statuses: []int{http.StatusServiceUnavailable, http.StatusBadRequest}}
+	d, err := NewDispatcher([]config.Notification{
+		{Type: "webhook", URL: "https://alerts.test/hook"},
+	}, DispatcherOptions{
+		HTTPClient:  &http.Client{Transport: rt},
+		Now:         func() time.Time { return now },
+		Interval:    time.Hour,
+		Retries:     2,
+		HistoryPath: path,
+	})
+	if err != nil {
+		t.Fatalf("NewDispatcher error: %v", err)
+	}
+	d.retryDelay = 0
+
+	high := Event{Type: EventNewHigh, Finding: analyzer.Finding{Type: analyzer.FindingMissingIndex, Severity: analyzer.SeverityHigh, Database: "app", Collection: "orders"}}
+	// 503 is retried, then the 400 fails for good; the repeat is rate limited.
+	if err := d.Notify(context.Background(), []Event{high, high}); err == nil {
+		t.Fatal("expected the 400 to fail the send")
+	}
+
+	deliveries, err := ReadDeliveries(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 2 {
+		t.Fatalf("deliveries = %+v, want 2", deliveries)
+	}
+	if got := deliveries[0]; got.Status != DeliveryFailed || got.Attempts != 2 || got.Channel != "webhook[0]" || got.Finding != "MISSING_INDEX app.orders" || got.Error == "" {
+		t.Errorf("first delivery = %+v", got)
+	}
+	if got := deliveries[1]; got.Status != DeliveryRateLimited || !got.Time.Equal(now) {
+		t.Errorf("second delivery = %+v, want rate_limited at %s", got, now)
+	}
+
+	stats := d.Stats()
+	if len(stats) != 1 || stats[0].Counts[DeliveryFailed] != 1 || stats[0].Counts[DeliveryRateLimited] != 1 || stats[0].Retries != 1 {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&httpStatusError{code: 503}, true},
+		{&httpStatusError{code: 429}, true},
+		{&httpStatusError{code: 404}, false},
+		{&redactedError{err: &httpStatusError{code: 502}, secret: "x"}, true},
+		{context.Canceled, false},
+	}
+	for _, tt := range tests {
+		if got := isTransient(tt.err); got != tt.want {
+			t.Errorf("isTransient(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,370 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+)
+
+// digestTopItems caps how many events a digest message lists by name.
+const digestTopItems = 10
+
+// eventOrder ranks event types for digests, most urgent first.
+var eventOrder = map[EventType]int{EventNewHigh: 0, EventUnresolvedHigh: 1, EventNewMedium: 2, EventNewLow: 3, EventChanged: 4, EventResolved: 5}
+
+// digestEventTypes lists the event types a digest counts, in order.
+var digestEventTypes = []EventType{EventNewHigh, EventUnresolvedHigh, EventNewMedium, EventNewLow, EventChanged, EventResolved}
+
+// digestBatch holds the events queued for a digest channel since the
+// first one arrived.
+type digestBatch struct {
+	since  time.Time
+	events []Event
+}
+
+// digest summarizes a batch of events for a single message.
+type digest struct {
+	since, until time.Time
+	events       []Event // ordered most urgent first
+	byEvent      map[EventType]int
+	byType       map[analyzer.FindingType]int
+}
+
+func newDigest(batch *digestBatch, until time.Time) *digest {
+	events := append([]Event(nil), batch.events...)
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventOrder[events[i].Type] < eventOrder[events[j].Type]
+	})
+	dg := &digest{
+		since:   batch.since,
+		until:   until,
+		events:  events,
+		byEvent: make(map[EventType]int),
+		byType:  make(map[analyzer.FindingType]int),
+	}
+	for i := range events {
+		dg.byEvent[events[i].Type]++
+		dg.byType[events[i].Finding.Type]++
+	}
+	return dg
+}
+
+// summary reads like "12 events: 3 new_high, 5 new_medium, 4 resolved".
+func (dg *digest) summary() string {
+	parts := make([]string, 0, len(digestEventTypes))
+	for _, t := range digestEventTypes {
+		if n := dg.byEvent[t]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, t))
+		}
+	}
+	return fmt.Sprintf("%d events: %s", len(dg.events), strings.Join(parts, ", "))
+}
+
+// typeCounts lists finding types by count, most frequent first.
+func (dg *digest) typeCounts() []string {
+	types := make([]analyzer.FindingType, 0, len(dg.byType))
+	for t := range dg.byType {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if dg.byType[types[i]] != dg.byType[types[j]] {
+			return dg.byType[types[i]] > dg.byType[types[j]]
+		}
+		return types[i] < types[j]
+	})
+	out := make([]string, len(types))
+	for i, t := range types {
+		out[i] = fmt.Sprintf("%s: %d", t, dg.byType[t])
+	}
+	return out
+}
+
+func (dg *digest) top() []Event {
+	if len(dg.events) > digestTopItems {
+		return dg.events[:digestTopItems]
+	}
+	return dg.events
+}
+
+// topLines renders the listed events plus a line for the rest.
+func (dg *digest) topLines() []string {
+	top := dg.top()
+	lines := make([]string, 0, len(top)+1)
+	for i := range top {
+		lines = append(lines, fmt.Sprintf("%s %s (%s)", strings.ToUpper(string(top[i].Type)), top[i].Finding.Type, eventLocation(&top[i])))
+	}
+	if rest := len(dg.events) - len(top); rest > 0 {
+		lines = append(lines, fmt.Sprintf("... and %d more", rest))
+	}
+	return lines
+}
+
+// clusterLabel labels the digest with the cluster of its events, which
+// share the dispatcher's --cluster profile.
+func (dg *digest) clusterLabel() string {
+	if len(dg.events) == 0 || dg.events[0].Cluster == "" {
+		return ""
+	}
+	return clusterLabel(&dg.events[0])
+}
+
+func (dg *digest) headline() string {
+	if label := dg.clusterLabel(); label != "" {
+		return fmt.Sprintf("mongospectre digest [%s]: %s", label, dg.summary())
+	}
+	return "mongospectre digest: " + dg.summary()
+}
+
+// enqueue adds an event to a digest channel's pending batch.
+func (d *Dispatcher) enqueue(ch channel, event *Event) {
+	d.mu.Lock()
+	batch := d.pending[ch.id]
+	if batch == nil {
+		batch = &digestBatch{since: d.now()}
+		d.pending[ch.id] = batch
+	}
+	batch.events = append(batch.events, *event)
+	d.mu.Unlock()
+	d.record(Delivery{Channel: ch.id, Event: string(event.Type), Finding: findingLabel(event), Status: DeliveryQueued})
+}
+
+// Flush sends the digests whose window has passed. Watch calls it after
+// every run, so a digest goes out within one run of its window closing.
+// Events held during quiet hours are sent first once the window ends.
+func (d *Dispatcher) Flush(ctx context.Context) error {
+	if quiet, _ := d.quietState(d.now()); quiet {
+		return nil
+	}
+	return errors.Join(d.release(ctx), d.flush(ctx, false))
+}
+
+// Close sends every pending digest, due or not, so nothing queued is lost
+// on shutdown. During quiet hours nothing is sent and the held events are
+// reported as discarded.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	if quiet, _ := d.quietState(d.now()); quiet {
+		return d.discardHeld()
+	}
+	return errors.Join(d.release(ctx), d.flush(ctx, true))
+}
+
+func (d *Dispatcher) flush(ctx context.Context, all bool) error {
+	now := d.now()
+	var sendErrs []error
+	for _, ch := range d.channels {
+		if ch.digest <= 0 {
+			continue
+		}
+		d.mu.Lock()
+		batch := d.pending[ch.id]
+		due := batch != nil && (all || now.Sub(batch.since) >= ch.digest)
+		if due {
+			delete(d.pending, ch.id)
+		}
+		d.mu.Unlock()
+		if !due {
+			continue
+		}
+		dg := newDigest(batch, now)
+		err := d.deliver(ctx, Delivery{Channel: ch.id, Event: "digest", Events: len(batch.events)}, func(ctx context.Context) error {
+			return d.sendDigest(ctx, ch, dg)
+		})
+		if err != nil {
+			d.requeue(ch.id, batch)
+			sendErrs = append(sendErrs, fmt.Errorf("%s: %w", ch.id, err))
+		}
+	}
+	return errors.Join(sendErrs...)
+}
+
+// requeue puts back a batch whose digest could not be sent, ahead of the
+// events queued while it was being sent, so the next flush retries it.
+func (d *Dispatcher) requeue(id string, batch *digestBatch) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if queued := d.pending[id]; queued != nil {
+		batch.events = append(batch.events, queued.events...)
+	}
+	d.pending[id] = batch
+}
+
+func (d *Dispatcher) sendDigest(ctx context.Context, ch channel, dg *digest) error {
+	switch ch.kind {
+	case channelSlack:
+		payload, err := buildSlackDigest(dg, ch.slack.dashboardURL)
+		if err != nil {
+			return err
+		}
+		if d.dryRun {
+			d.logDryRun(ch.id, "digest", payload)
+			return nil
+		}
+		return d.postJSON(ctx, http.MethodPost, ch.slack.webhookURL, nil, payload)
+	case channelDiscord:
+		payload, err := buildDiscordDigest(dg, ch.discord.dashboardURL)
+		if err != nil {
+			return err
+		}
+		if d.dryRun {
+			d.logDryRun(ch.id, "digest", payload)
+			return nil
+		}
+		return d.postJSON(ctx, http.MethodPost, ch.discord.webhookURL, nil, payload)
+	case channelTelegram:
+		payload, err := buildTelegramDigest(dg, ch.telegram)
+		if err != nil {
+			return err
+		}
+		if d.dryRun {
+			d.logDryRun(ch.id, "digest", payload)
+			return nil
+		}
+		url := telegramAPIURL + "/bot" + ch.telegram.botToken + "/sendMessage"
+		if err := d.postJSON(ctx, http.MethodPost, url, nil, payload); err != nil {
+			return &redactedError{err: err, secret: ch.telegram.botToken}
+		}
+		return nil
+	case channelWebhook:
+		payload, err := buildWebhookDigest(dg)
+		if err != nil {
+			return err
+		}
+		if d.dryRun {
+			d.logDryRun(ch.id, "digest", payload)
+			return nil
+		}
+		return d.postJSON(ctx, ch.webhook.method, ch.webhook.url, ch.webhook.headers, payload)
+	case channelEmail:
+		subject, message := buildEmailDigest(dg, ch.email)
+		if d.dryRun {
+			d.logEmailDryRun(ch.id, "digest", subject, ch.email.to, message)
+			return nil
+		}
+		return d.sendEmail(ctx, ch.email, message)
+	default:
+		return fmt.Errorf("digest not supported for channel type: %s", ch.kind)
+	}
+}
+
+// digestColor follows the most urgent event in the batch.
+func digestColor(dg *digest) string {
+	if len(dg.events) == 0 {
+		return "#2eb886"
+	}
+	return eventColor(&dg.events[0])
+}
+
+func buildSlackDigest(dg *digest, dashboardURL string) ([]byte, error) {
+	text := dg.headline()
+	if dashboardURL != "" {
+		text += fmt.Sprintf(" | <%s|Open dashboard>", dashboardURL)
+	}
+	fields := []map[string]interface{}{
+		{"title": "By finding type", "value": strings.Join(dg.typeCounts(), "\n"), "short": false},
+		{"title": "Top findings", "value": strings.Join(dg.topLines(), "\n"), "short": false},
+	}
+	return json.Marshal(map[string]interface{}{
+		"text": text,
+		"attachments": []map[string]interface{}{
+			{
+				"color":  digestColor(dg),
+				"fields": fields,
+				"footer": "mongospectre watch digest",
+				"ts":     dg.until.Unix(),
+			},
+		},
+	})
+}
+
+func buildDiscordDigest(dg *digest, dashboardURL string) ([]byte, error) {
+	color, err := parseHexColor(digestColor(dg))
+	if err != nil {
+		return nil, err
+	}
+	embed := map[string]interface{}{
+		"title": dg.headline(),
+		"color": color,
+		"fields": []map[string]interface{}{
+			discordField("By finding type", strings.Join(dg.typeCounts(), "\n"), false),
+			discordField("Top findings", strings.Join(dg.topLines(), "\n"), false),
+		},
+		"footer":    map[string]string{"text": "mongospectre watch digest"},
+		"timestamp": dg.until.UTC().Format(time.RFC3339),
+	}
+	if dashboardURL != "" {
+		embed["url"] = dashboardURL
+	}
+	return json.Marshal(map[string]interface{}{"embeds": []map[string]interface{}{embed}})
+}
+
+func buildTelegramDigest(dg *digest, cfg *telegramChannel) ([]byte, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<b>%s</b>\n", html.EscapeString(dg.headline()))
+	b.WriteString("<b>By finding type:</b>\n")
+	for _, line := range dg.typeCounts() {
+		fmt.Fprintf(&b, "%s\n", html.EscapeString(line))
+	}
+	b.WriteString("<b>Top findings:</b>")
+	for _, line := range dg.topLines() {
+		fmt.Fprintf(&b, "\n%s", html.EscapeString(line))
+	}
+	if cfg.dashboardURL != "" {
+		fmt.Fprintf(&b, "\n<a href=\"%s\">Open dashboard</a>", html.EscapeString(cfg.dashboardURL))
+	}
+	return json.Marshal(map[string]interface{}{
+		"chat_id":                  cfg.chatID,
+		"text":                     b.String(),
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	})
+}
+
+func buildWebhookDigest(dg *digest) ([]byte, error) {
+	byEvent := make(map[string]int, len(dg.byEvent))
+	for t, n := range dg.byEvent {
+		byEvent[string(t)] = n
+	}
+	byType := make(map[string]int, len(dg.byType))
+	for t, n := range dg.byType {
+		byType[string(t)] = n
+	}
+	top := dg.top()
+	items := make([]map[string]string, len(top))
+	for i := range top {
+		items[i] = map[string]string{
+			"event":      string(top[i].Type),
+			"type":       string(top[i].Finding.Type),
+			"severity":   string(top[i].Finding.Severity),
+			"database":   top[i].Finding.Database,
+			"collection": top[i].Finding.Collection,
+			"index":      top[i].Finding.Index,
+			"message":    top[i].Finding.Message,
+		}
+	}
+	payload := map[string]interface{}{
+		"source":  "mongospectre",
+		"event":   "digest",
+		"since":   dg.since.UTC().Format(time.RFC3339),
+		"until":   dg.until.UTC().Format(time.RFC3339),
+		"total":   len(dg.events),
+		"counts":  byEvent,
+		"byType":  byType,
+		"top":     items,
+		"omitted": len(dg.events) - len(top),
+	}
+	if len(dg.events) > 0 && dg.events[0].Cluster != "" {
+		payload["cluster"] = dg.events[0].Cluster
+		if len(dg.events[0].Tags) > 0 {
+			payload["tags"] = dg.events[0].Tags
+		}
+	}
+	return json.Marshal(payload)
+}
@@ -0,0 +1,222 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	"github.com/ppiankov/mongospectre/internal/config"
+)
+
+func digestEvents(n int) []Event {
+	events := make([]Event, 0, n)
+	for i := 0; i < n; i++ {
+		eventType, severity := EventNewMedium, analyzer.SeverityMedium
+		if i%4 == 3 {
+			eventType, severity = EventNewHigh, analyzer.SeverityHigh
+		}
+		events = append(events, Event{
+			Type:   eventType,
+			Status: analyzer.StatusNew,
+			Finding: analyzer.Finding{
+				Type:       analyzer.FindingUnusedIndex,
+				Severity:   severity,
+				Database:   "app",
+				Collection: fmt.Sprintf("c%02d", i),
+			},
+		})
+	}
+	return events
+}
+
+func TestDispatcherDigestBatchesUntilWindowEnds(t *testing.T) {
+	t.Setenv("SLACK_WEBHOOK_URL", "https://hooks.slack.test/slack")
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	rt := &recordingRoundTripper{}
+	d, err := NewDispatcher([]config.Notification{
+		{Type: "slack", WebhookURL: "${SLACK_WEBHOOK_URL}", Digest: "15m"},
+	}, DispatcherOptions{HTTPClient: &http.Client{Transport: rt}, Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("NewDispatcher error: %v", err)
+	}
+
+	events := digestEvents(12)
+	if err := d.Notify(context.Background(), events[:8]); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(10 * time.Minute)
+	if err := d.Notify(context.Background(), events[8:]); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(rt.snapshot()); n != 0 {
+		t.Fatalf("sent %d requests inside the digest window, want 0", n)
+	}
+
+	now = now.Add(5 * time.Minute)
+	if err := d.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	requests := rt.snapshot()
+	if len(requests) != 1 {
+		t.Fatalf("requests = %d, want a single digest", len(requests))
+	}
+
+	var payload struct {
+		Text        string `json:"text"`
+		Attachments []struct {
+			Color  string `json:"color"`
+			Fields []struct {
+				Title string `json:"title"`
+				Value string `json:"value"`
+			} `json:"fields"`
+		} `json:"attachments"`
+	}
+	if err := json.Unmarshal(requests[0].Body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Text != "mongospectre digest: 12 events: 3 new_high, 9 new_medium" {
+		t.Fatalf("text = %q", payload.Text)
+	}
+	att := payload.Attachments[0]
+	if att.Color != "#d40e0d" {
+		t.Errorf("color = %q, want the high-severity color", att.Color)
+	}
+	top := strings.Split(att.Fields[1].Value, "\n")
+	if len(top) != digestTopItems+1 || !strings.HasPrefix(top[0], "NEW_HIGH") || top[len(top)-1] != "... and 2 more" {
+		t.Errorf("top findings = %q", top)
+	}
+
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(rt.snapshot()); n != 1 {
+		t.Fatalf("close resent an empty digest: %d requests", n)
+	}
+}
+
+func TestDispatcherCloseSendsPendingDigest(t *testing.T) {
+	rt := &recordingRoundTripper{}
+	d, err := NewDispatcher([]config.Notification{
+		{Type: "webhook", URL: "https://alerts.example.com/hook", Digest: "1h"},
+	}, DispatcherOptions{HTTPClient: &http.Client{Transport: rt}})
+	if err != nil {
+		t.Fatalf("NewDispatcher error: %v", err)
+	}
+	if err := d.Notify(context.Background(), digestEvents(3)); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := rt.snapshot()
+	if len(requests) != 1 {
+		t.Fatalf("requests = %d, want 1", len(requests))
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(requests[0].Body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload["event"] != "digest" || payload["total"] != float64(3) {
+		t.Fatalf("payload = %v", payload)
+	}
+}
+
+func TestDispatcherRequeuesFailedDigest(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	rt := &recordingRoundTripper{status: http.StatusBadRequest}
+	d, err := NewDispatcher([]config.Notification{
+		{Type: "webhook", URL: "https://alerts.example.com/hook", Digest: "15m"},
+	}, DispatcherOptions{HTTPClient: &http.Client{Transport: rt}, Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("NewDispatcher error: %v", err)
+	}
+	events := digestEvents(4)
+	if err := d.Notify(context.Background(), events[:3]); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(15 * time.Minute)
+	if err := d.Flush(context.Background()); err == nil {
+		t.Fatal("expected an error for a failed digest")
+	}
+
+	// The failed batch is sent on the next flush, with the events queued since.
+	rt.mu.Lock()
+	rt.status = http.StatusOK
+	rt.mu.Unlock()
+	if err := d.Notify(context.Background(), events[3:]); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	requests := rt.snapshot()
+	if len(requests) < 2 {
+		t.Fatalf("requests = %d, want the failed digest and its retry", len(requests))
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(requests[len(requests)-1].Body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload["total"] != float64(4) {
+		t.Fatalf("retried digest total = %v, want 4", payload["total"])
+	}
+	if err := d.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(rt.snapshot()); n != len(requests) {
+		t.Fatalf("close resent a delivered digest: %d requests", n)
+	}
+}
+
+func TestNewDispatcherRejectsInvalidDigest(t *testing.T) {
+	t.Setenv("OPSGENIE_API_KEY", "key")
+	tests := []struct {
+		cfg  config.Notification
+		want string
+	}{
+		{config.Notification{Type: "webhook", URL: "https://x.test", Digest: "soon"}, "invalid digest"},
+		{config.Notification{Type: "opsgenie", APIKey: "${OPSGENIE_API_KEY}", Digest: "15m"}, "not supported for opsgenie"},
+		{config.Notification{Type: "webhook", URL: "https://x.test", Digest: "15m", BodyTemplate: "{{ .Headline }}"}, "body_template"},
+	}
+	for _, tt := range tests {
+		_, err := NewDispatcher([]config.Notification{tt.cfg}, DispatcherOptions{})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%+v: err = %v, want %q", tt.cfg, err, tt.want)
+		}
+	}
+}
+
+func TestBuildDiscordDigestCapsFieldValues(t *testing.T) {
+	events := digestEvents(12)
+	for i := range events {
+		events[i].Finding.Collection = strings.Repeat("x", 200)
+	}
+	dg := newDigest(&digestBatch{since: time.Now(), events: events}, time.Now())
+	body, err := buildDiscordDigest(dg, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload struct {
+		Embeds []struct {
+			Fields []struct{ Value string } `json:"fields"`
+		} `json:"embeds"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range payload.Embeds[0].Fields {
+		if n := len([]rune(f.Value)); n > discordFieldMax {
+			t.Errorf("field value has %d characters, over the %d limit", n, discordFieldMax)
+		}
+	}
+}
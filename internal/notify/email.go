@@ -0,0 +1,262 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// SMTP transport security modes for smtp_tls.
+const (
+	smtpTLSAuto     = ""         // STARTTLS when the server offers it, as net/smtp does
+	smtpTLSStartTLS = "starttls" // fail unless the server offers STARTTLS
+	smtpTLSImplicit = "tls"      // TLS from the first byte (smtps, usually port 465)
+	smtpTLSNone     = "none"     // never upgrade, for local relays
+)
+
+// smtpTimeout bounds a whole SMTP exchange unless the context ends sooner.
+const smtpTimeout = 30 * time.Second
+
+// emailTLSConfig resolves smtp_tls, smtp_ca_file and smtp_insecure.
+func emailTLSConfig(mode, host, caFile string, insecure bool) (*tls.Config, error) {
+	switch mode {
+	case smtpTLSAuto, smtpTLSStartTLS, smtpTLSImplicit, smtpTLSNone:
+	default:
+		return nil, fmt.Errorf("invalid smtp_tls %q: want starttls, tls or none", mode)
+	}
+	tc := &tls.Config{
+		ServerName:         host,
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: insecure, //nolint:gosec // explicit smtp_insecure opt-in
+	}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("read smtp_ca_file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("smtp_ca_file %s: no PEM certificates found", caFile)
+		}
+		tc.RootCAs = pool
+	}
+	return tc, nil
+}
+
+// sendEmail delivers msg through the channel's SMTP server, or through
+// DispatcherOptions.SendMail when one is set.
+func (d *Dispatcher) sendEmail(ctx context.Context, cfg *emailChannel, msg []byte) error {
+	addr := fmt.Sprintf("%s:%d", cfg.host, cfg.port)
+	var auth smtp.Auth
+	if cfg.username != "" {
+		auth = smtp.PlainAuth("", cfg.username, cfg.password, cfg.host)
+	}
+	if d.sendMail != nil {
+		return d.sendMail(addr, auth, cfg.from, cfg.to, msg)
+	}
+	return sendSMTP(ctx, addr, auth, cfg, msg)
+}
+
+// sendSMTP is smtp.SendMail with the channel's TLS mode and certificate
+// settings applied.
+func sendSMTP(ctx context.Context, addr string, auth smtp.Auth, cfg *emailChannel, msg []byte) error {
+	deadline := time.Now().Add(smtpTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	dialer := &net.Dialer{Deadline: deadline}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if cfg.security == smtpTLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: cfg.tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	_ = conn.SetDeadline(deadline)
+
+	c, err := smtp.NewClient(conn, cfg.host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer func() { _ = c.Close() }()
+
+	if cfg.security == smtpTLSAuto || cfg.security == smtpTLSStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(cfg.tlsConfig); err != nil {
+				return fmt.Errorf("starttls: %w", err)
+			}
+		} else if cfg.security == smtpTLSStartTLS {
+			return fmt.Errorf("%s does not offer STARTTLS", addr)
+		}
+	}
+	if auth != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("smtp server does not support AUTH")
+		}
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(cfg.from); err != nil {
+		return err
+	}
+	for _, rcpt := range cfg.to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// emailContent feeds the shared email templates: a title, labelled
+// fields, and bulleted sections (used by digests).
+type emailContent struct {
+	Title    string
+	Fields   []emailField
+	Sections []emailSection
+}
+
+type emailField struct {
+	Label string
+	Value string
+}
+
+type emailSection struct {
+	Title string
+	Lines []string
+}
+
+var emailHTMLTemplate = htmltemplate.Must(htmltemplate.New("html").Parse(`<html><body><h3>{{.Title}}</h3>
+{{range .Fields}}<p><strong>{{.Label}}:</strong> {{.Value}}</p>
+{{end}}{{range .Sections}}<h4>{{.Title}}</h4><ul>
+{{range .Lines}}<li>{{.}}</li>
+{{end}}</ul>
+{{end}}</body></html>
+`))
+
+var emailTextTemplate = template.Must(template.New("text").Parse(`{{.Title}}
+
+{{range .Fields}}{{.Label}}: {{.Value}}
+{{end}}{{range .Sections}}
+{{.Title}}
+{{range .Lines}}- {{.}}
+{{end}}{{end}}`))
+
+func buildEmailMessage(event *Event, cfg *emailChannel) (string, []byte) {
+	location := eventLocation(event)
+
+	subject := cfg.subject
+	if subject == "" {
+		subject = fmt.Sprintf("[mongospectre] %s %s", strings.ToUpper(string(event.Type)), location)
+		if event.Cluster != "" {
+			subject = fmt.Sprintf("[mongospectre %s] %s %s", event.Cluster, strings.ToUpper(string(event.Type)), location)
+		}
+	}
+
+	content := emailContent{
+		Title: fmt.Sprintf("mongospectre alert: %s", event.Type),
+		Fields: []emailField{
+			{"Type", string(event.Finding.Type)},
+			{"Severity", string(event.Finding.Severity)},
+			{"Location", location},
+			{"Message", event.Finding.Message},
+			{"Timestamp", event.Timestamp},
+		},
+	}
+	if event.Finding.RuleID != "" {
+		content.Fields = append(content.Fields, emailField{"Rule", event.Finding.RuleID + " " + event.Finding.DocURL})
+	}
+	if event.Cluster != "" {
+		content.Fields = append(content.Fields, emailField{"Cluster", clusterLabel(event)})
+	}
+	return subject, composeEmail(cfg, subject, &content)
+}
+
+func buildEmailDigest(dg *digest, cfg *emailChannel) (string, []byte) {
+	subject := cfg.subject
+	if subject == "" {
+		subject = "[mongospectre] digest: " + dg.summary()
+		if dg.events[0].Cluster != "" {
+			subject = fmt.Sprintf("[mongospectre %s] digest: %s", dg.events[0].Cluster, dg.summary())
+		}
+	}
+
+	content := emailContent{
+		Title: dg.headline(),
+		Fields: []emailField{
+			{"Window", fmt.Sprintf("%s to %s", dg.since.UTC().Format(time.RFC3339), dg.until.UTC().Format(time.RFC3339))},
+		},
+		Sections: []emailSection{
+			{"By finding type", dg.typeCounts()},
+			{"Top findings", dg.topLines()},
+		},
+	}
+	return subject, composeEmail(cfg, subject, &content)
+}
+
+// composeEmail renders content as a multipart/alternative message with a
+// plain-text part first and the HTML part second, so clients that cannot
+// show HTML fall back to the text.
+func composeEmail(cfg *emailChannel, subject string, content *emailContent) []byte {
+	var text, htmlBody bytes.Buffer
+	_ = emailTextTemplate.Execute(&text, content)
+	_ = emailHTMLTemplate.Execute(&htmlBody, content)
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	writeEmailPart(mw, "text/plain", text.Bytes())
+	writeEmailPart(mw, "text/html", htmlBody.Bytes())
+	_ = mw.Close()
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", cfg.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(cfg.to, ","))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%q\r\n", mw.Boundary())
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+	return msg.Bytes()
+}
+
+func writeEmailPart(mw *multipart.Writer, contentType string, content []byte) {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType+"; charset=\"UTF-8\"")
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	part, _ := mw.CreatePart(header)
+	qp := quotedprintable.NewWriter(part)
+	_, _ = qp.Write(content)
+	_ = qp.Close()
+}
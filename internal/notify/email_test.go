@@ -0,0 +1,162 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	"github.com/ppiankov/mongospectre/internal/config"
+)
+
+func TestEmailMessageMultipart(t *testing.T) {
+	cfg := &emailChannel{from: "alerts@example.com", to: []string{"team@example.com"}}
+	_, raw := buildEmailMessage(&Event{
+		Type:    EventNewHigh,
+		Cluster: "prod",
+		Finding: analyzer.Finding{Type: analyzer.FindingMissingIndex, Severity: analyzer.SeverityHigh, Database: "app", Collection: "orders", Message: "scan on <status>"},
+	}, cfg)
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Header.Get("From") != "alerts@example.com" || msg.Header.Get("Date") == "" {
+		t.Errorf("missing From or Date header: %v", msg.Header)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, want multipart/alternative", msg.Header.Get("Content-Type"))
+	}
+
+	parts := map[string]string{}
+	var order []string
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(part)
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[contentType] = string(body)
+		order = append(order, contentType)
+	}
+	if len(order) != 2 || order[0] != "text/plain" || order[1] != "text/html" {
+		t.Fatalf("parts = %v, want text/plain then text/html", order)
+	}
+	if !strings.Contains(parts["text/plain"], "Message: scan on <status>") || !strings.Contains(parts["text/plain"], "Cluster: prod") {
+		t.Errorf("plain text part:\n%s", parts["text/plain"])
+	}
+	if !strings.Contains(parts["text/html"], "scan on &lt;status&gt;") {
+		t.Errorf("html part does not escape the message:\n%s", parts["text/html"])
+	}
+}
+
+// fakeSMTP serves one plaintext SMTP session and returns what it received.
+func fakeSMTP(t *testing.T, extensions ...string) (addr string, received <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	out := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		var data strings.Builder
+		r := bufio.NewReader(conn)
+		reply := func(s string) { _, _ = io.WriteString(conn, s+"\r\n") }
+		reply("220 fake ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				out <- data.String()
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"):
+				lines := append([]string{"fake"}, extensions...)
+				for i, l := range lines {
+					sep := "-"
+					if i == len(lines)-1 {
+						sep = " "
+					}
+					reply("250" + sep + l)
+				}
+			case cmd == "DATA":
+				reply("354 go ahead")
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				reply("250 queued")
+			case cmd == "QUIT":
+				reply("221 bye")
+				out <- data.String()
+				return
+			default:
+				reply("250 ok")
+			}
+		}
+	}()
+	return ln.Addr().String(), out
+}
+
+func TestSendSMTP(t *testing.T) {
+	addr, received := fakeSMTP(t)
+	cfg := &emailChannel{host: "127.0.0.1", from: "a@example.com", to: []string{"b@example.com"}, security: smtpTLSNone}
+	if err := sendSMTP(context.Background(), addr, nil, cfg, []byte("Subject: hi\r\n\r\nbody\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-received; !strings.Contains(got, "body") {
+		t.Fatalf("server received %q", got)
+	}
+
+	addr, _ = fakeSMTP(t)
+	cfg.security = smtpTLSStartTLS
+	err := sendSMTP(context.Background(), addr, nil, cfg, []byte("body\r\n"))
+	if err == nil || !strings.Contains(err.Error(), "does not offer STARTTLS") {
+		t.Fatalf("expected required STARTTLS to fail, got %v", err)
+	}
+}
+
+func TestEmailTLSConfigValidation(t *testing.T) {
+	for _, raw := range []config.Notification{
+		{Type: "email", SMTPHost: "smtp.example.com", To: []string{"a@example.com"}, SMTPTLS: "ssl"},
+		{Type: "email", SMTPHost: "smtp.example.com", To: []string{"a@example.com"}, SMTPCAFile: filepath.Join(t.TempDir(), "missing.pem")},
+	} {
+		if _, err := NewDispatcher([]config.Notification{raw}, DispatcherOptions{}); err == nil {
+			t.Errorf("%+v: expected error", raw)
+		}
+	}
+
+	channels, err := buildChannels([]config.Notification{
+		{Type: "email", SMTPHost: "smtp.example.com", To: []string{"a@example.com"}, SMTPTLS: "TLS"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ch := channels[0].email; ch.port != 465 || ch.security != smtpTLSImplicit {
+		t.Errorf("implicit TLS channel = port %d, mode %q; want 465, tls", ch.port, ch.security)
+	}
+}
@@ -0,0 +1,193 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+)
+
+// EventUnresolvedHigh repeats a new_high event on channels with
+// reescalate_after set, for as long as the finding stays open.
+const EventUnresolvedHigh EventType = "unresolved_high"
+
+// escalation tracks a high finding sent to a re-escalating channel.
+type escalation struct {
+	Channel  string    `json:"channel"`
+	Event    Event     `json:"event"`
+	LastSent time.Time `json:"lastSent"`
+	Count    int       `json:"count"` // re-sends so far
+}
+
+// watchState is what the dispatcher persists between watch restarts.
+type watchState struct {
+	Escalations []escalation `json:"escalations"`
+}
+
+func escalationKey(channelID string, finding *analyzer.Finding) string {
+	return channelID + "|" + analyzer.Fingerprint(finding)
+}
+
+// track starts re-escalation for a high finding sent on ch.
+func (d *Dispatcher) track(ch channel, event *Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	key := escalationKey(ch.id, &event.Finding)
+	if _, ok := d.escalations[key]; ok {
+		return
+	}
+	d.escalations[key] = &escalation{Channel: ch.id, Event: *event, LastSent: d.now()}
+	d.stateDirty = true
+}
+
+// untrack stops re-escalation of a resolved finding on every channel.
+func (d *Dispatcher) untrack(finding *analyzer.Finding) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, ch := range d.channels {
+		key := escalationKey(ch.id, finding)
+		if _, ok := d.escalations[key]; ok {
+			delete(d.escalations, key)
+			d.stateDirty = true
+		}
+	}
+}
+
+// Reescalate re-sends high findings that are still among current and
+// have gone reescalate_after without a notification. Tracked findings no
+// longer in current were resolved, possibly while watch was down, and are
+// dropped.
+func (d *Dispatcher) Reescalate(ctx context.Context, current []analyzer.Finding) error {
+	if quiet, _ := d.quietState(d.now()); quiet {
+		// Due repeats go out once the quiet period ends.
+		return nil
+	}
+	open := make(map[string]bool, len(current))
+	for i := range current {
+		open[analyzer.Fingerprint(&current[i])] = true
+	}
+	byID := make(map[string]channel, len(d.channels))
+	for _, ch := range d.channels {
+		byID[ch.id] = ch
+	}
+
+	now := d.now()
+	type due struct {
+		ch    channel
+		event Event
+	}
+	var sends []due
+	d.mu.Lock()
+	for key, esc := range d.escalations {
+		ch, ok := byID[esc.Channel]
+		if !ok || ch.reescalateAfter <= 0 || !open[analyzer.Fingerprint(&esc.Event.Finding)] {
+			delete(d.escalations, key)
+			d.stateDirty = true
+			continue
+		}
+		if now.Sub(esc.LastSent) < ch.reescalateAfter {
+			continue
+		}
+		esc.LastSent = now
+		esc.Count++
+		d.stateDirty = true
+		event := esc.Event
+		event.Type = EventUnresolvedHigh
+		event.Timestamp = now.UTC().Format(time.RFC3339)
+		sends = append(sends, due{ch: ch, event: event})
+	}
+	d.mu.Unlock()
+
+	var sendErrs []error
+	for i := range sends {
+		s := &sends[i]
+		if s.ch.digest > 0 {
+			d.enqueue(s.ch, &s.event)
+			continue
+		}
+		if err := d.deliverEvent(ctx, s.ch, &s.event); err != nil {
+			sendErrs = append(sendErrs, fmt.Errorf("%s: %w", s.ch.id, err))
+		}
+	}
+	if err := d.saveState(); err != nil {
+		sendErrs = append(sendErrs, err)
+	}
+	return errors.Join(sendErrs...)
+}
+
+// loadState reads the escalations saved by an earlier watch process.
+// A missing file is an empty state.
+func (d *Dispatcher) loadState() error {
+	if d.statePath == "" {
+		return nil
+	}
+	data, err := os.ReadFile(d.statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read watch state: %w", err)
+	}
+	var state watchState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("parse watch state %s: %w", d.statePath, err)
+	}
+	for i := range state.Escalations {
+		esc := state.Escalations[i]
+		d.escalations[escalationKey(esc.Channel, &esc.Event.Finding)] = &esc
+	}
+	return nil
+}
+
+// saveState writes the escalations when they changed. The file is
+// replaced atomically so a crash never leaves a truncated state.
+func (d *Dispatcher) saveState() error {
+	d.mu.Lock()
+	if d.statePath == "" || !d.stateDirty {
+		d.mu.Unlock()
+		return nil
+	}
+	state := watchState{Escalations: make([]escalation, 0, len(d.escalations))}
+	for _, esc := range d.escalations {
+		state.Escalations = append(state.Escalations, *esc)
+	}
+	sort.Slice(state.Escalations, func(i, j int) bool {
+		a, b := &state.Escalations[i], &state.Escalations[j]
+		return escalationKey(a.Channel, &a.Event.Finding) < escalationKey(b.Channel, &b.Event.Finding)
+	})
+	d.stateDirty = false
+	d.mu.Unlock()
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(d.statePath)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("write watch state: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".watch-state-*.tmp")
+	if err != nil {
+		return fmt.Errorf("write watch state: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("write watch state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("write watch state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), d.statePath); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("write watch state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	"github.com/ppiankov/mongospectre/internal/config"
+)
+
+func TestDispatcherReescalatesOpenHighFindings(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	statePath := filepath.Join(t.TempDir(), "watch-state.json")
+	rt := &recordingRoundTripper{}
+	newDispatcher := func() *Dispatcher {
+		t.Helper()
+		d, err := NewDispatcher([]config.Notification{
+			{Type: "webhook", URL: "https://alerts.test/hook", ReescalateAfter: "24h"},
+		}, DispatcherOptions{
+			HTTPClient: &http.Client{Transport: rt},
+			Now:        func() time.Time { return now },
+			StatePath:  statePath,
+		})
+		if err != nil {
+			t.Fatalf("NewDispatcher error: %v", err)
+		}
+		return d
+	}
+
+	high := analyzer.Finding{Type: analyzer.FindingMissingIndex, Severity: analyzer.SeverityHigh, Database: "app", Collection: "orders"}
+	other := analyzer.Finding{Type: analyzer.FindingUnusedIndex, Severity: analyzer.SeverityMedium, Database: "app", Collection: "users"}
+	d := newDispatcher()
+	if err := d.Notify(context.Background(), []Event{{Type: EventNewHigh, Finding: high, Status: analyzer.StatusNew}}); err != nil {
+		t.Fatal(err)
+	}
+	current := []analyzer.Finding{high, other}
+
+	now = now.Add(12 * time.Hour)
+	if err := d.Reescalate(context.Background(), current); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(rt.snapshot()); n != 1 {
+		t.Fatalf("requests = %d before reescalate_after, want only the original", n)
+	}
+
+	// A restarted watch picks the tracking up from the state file.
+	now = now.Add(12 * time.Hour)
+	if err := newDispatcher().Reescalate(context.Background(), current); err != nil {
+		t.Fatal(err)
+	}
+	requests := rt.snapshot()
+	if len(requests) != 2 || !strings.Contains(string(requests[1].Body), `"event":"unresolved_high"`) {
+		t.Fatalf("requests = %d, want an unresolved_high re-send: %s", len(requests), requests[len(requests)-1].Body)
+	}
+
+	// Once the finding is gone it is no longer tracked.
+	d = newDispatcher()
+	now = now.Add(48 * time.Hour)
+	if err := d.Reescalate(context.Background(), []analyzer.Finding{other}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Reescalate(context.Background(), current); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(rt.snapshot()); n != 2 {
+		t.Fatalf("requests = %d after the finding resolved, want no more re-sends", n)
+	}
+}
+
+func TestDispatcherResolvedEventStopsReescalation(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	rt := &recordingRoundTripper{}
+	d, err := NewDispatcher([]config.Notification{
+		{Type: "webhook", URL: "https://alerts.test/hook", ReescalateAfter: "1h", On: []string{"new_high"}},
+	}, DispatcherOptions{HTTPClient: &http.Client{Transport: rt}, Now: func() time.Time { return now }})
+	if err != nil {
+		t.Fatalf("NewDispatcher error: %v", err)
+	}
+
+	high := analyzer.Finding{Type: analyzer.FindingMissingIndex, Severity: analyzer.SeverityHigh, Database: "app", Collection: "orders"}
+	if err := d.Notify(context.Background(), []Event{{Type: EventNewHigh, Finding: high}}); err != nil {
+		t.Fatal(err)
+	}
+	// The channel does not subscribe to resolved events, but they still
+	// end the escalation.
+	if err := d.Notify(context.Background(), []Event{{Type: EventResolved, Finding: high}}); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(2 * time.Hour)
+	if err := d.Reescalate(context.Background(), []analyzer.Finding{high}); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(rt.snapshot()); n != 1 {
+		t.Fatalf("requests = %d, want no re-send after resolve", n)
+	}
+}
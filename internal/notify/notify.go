@@ -3,18 +3,24 @@ package notify
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html"
 	"io"
 	"net/http"
 	"net/smtp"
+	neturl "net/url"
 	"os"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
+	"unicode/utf8"
 
 	"github.com/ppiankov/mongospectre/internal/analyzer"
 	"github.com/ppiankov/mongospectre/internal/config"
@@ -30,6 +36,10 @@ const (
 	EventResolved  EventType = "resolved"
 )
 
+// EventChanged reports a finding whose severity or message changed since
+// the previous run. Channels only receive it when their on list names it.
+const EventChanged EventType = "changed"
+
 var allEventTypes = []EventType{EventNewHigh, EventNewMedium, EventNewLow, EventResolved}
 
 // Event is a single notification-ready drift change.
@@ -38,6 +48,11 @@ type Event struct {
 	Timestamp string                  `json:"timestamp"`
 	Finding   analyzer.Finding        `json:"finding"`
 	Status    analyzer.BaselineStatus `json:"status"`
+	Cluster   string                  `json:"cluster,omitempty"`
+	Tags      []string                `json:"tags,omitempty"`
+	// For changed events: the previous severity and message, if different.
+	PreviousSeverity analyzer.Severity `json:"previousSeverity,omitempty"`
+	PreviousMessage  string            `json:"previousMessage,omitempty"`
 }
 
 // EventsFromDiff converts baseline diff entries into notification events.
@@ -50,11 +65,15 @@ func EventsFromDiff(diff []analyzer.BaselineFinding, at time.Time) []Event {
 		if !ok {
 			continue
 		}
+		finding := item.Finding
+		finding.AnnotateRule()
 		events = append(events, Event{
-			Type:      eventType,
-			Timestamp: timestamp,
-			Finding:   item.Finding,
-			Status:    item.Status,
+			Type:             eventType,
+			Timestamp:        timestamp,
+			Finding:          finding,
+			Status:           item.Status,
+			PreviousSeverity: item.PreviousSeverity,
+			PreviousMessage:  item.PreviousMessage,
 		})
 	}
 	return events
@@ -64,6 +83,8 @@ func eventTypeForFinding(item *analyzer.BaselineFinding) (EventType, bool) {
 	switch item.Status {
 	case analyzer.StatusResolved:
 		return EventResolved, true
+	case analyzer.StatusChanged:
+		return EventChanged, true
 	case analyzer.StatusNew:
 		switch item.Severity {
 		case analyzer.SeverityHigh:
@@ -88,6 +109,22 @@ type DispatcherOptions struct {
 	HTTPClient *http.Client
 	Now        func() time.Time
 	SendMail   func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+	// Cluster and Tags label every event, from the --cluster profile.
+	Cluster string
+	Tags    []string
+	// StatePath persists re-escalation tracking across restarts; empty
+	// keeps it in memory.
+	StatePath string
+	// QuietHours hold notifications back during configured windows, and
+	// SilenceUntil drops them until then (watch --silence).
+	QuietHours   []config.QuietWindow
+	SilenceUntil time.Time
+	// Retries is how many times a send that failed with a network error,
+	// HTTP 429 or 5xx, or an SMTP 4xx reply is retried.
+	Retries int
+	// HistoryPath appends every delivery outcome to this JSON lines file;
+	// empty keeps only the in-memory counts from Stats.
+	HistoryPath string
 }
 
 // Dispatcher routes watch events to configured notification channels.
@@ -99,48 +136,117 @@ type Dispatcher struct {
 	httpClient *http.Client
 	now        func() time.Time
 	sendMail   func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
-
-	mu       sync.Mutex
-	lastSent map[string]time.Time
+	cluster    string
+	tags       []string
+
+	statePath    string
+	quiet        []quietWindow
+	silenceUntil time.Time
+	retries      int
+	retryDelay   time.Duration // multiplied by the attempt number
+	historyPath  string
+	historyMu    sync.Mutex
+
+	mu          sync.Mutex
+	lastSent    map[string]time.Time
+	pending     map[string]*digestBatch // by channel id
+	held        []Event                 // queued during quiet hours
+	escalations map[string]*escalation  // by channel id and fingerprint
+	stateDirty  bool
+	stats       map[string]*DeliveryStats // by channel id
 }
 
 type channelKind string
 
 const (
-	channelSlack   channelKind = "slack"
-	channelWebhook channelKind = "webhook"
-	channelEmail   channelKind = "email"
+	channelSlack    channelKind = "slack"
+	channelDiscord  channelKind = "discord"
+	channelTelegram channelKind = "telegram"
+	channelOpsgenie channelKind = "opsgenie"
+	channelWebhook  channelKind = "webhook"
+	channelEmail    channelKind = "email"
 )
 
+// telegramAPIURL is the Bot API base; the token is appended per request.
+const telegramAPIURL = "https://api.telegram.org"
+
+// opsgenieAPIURL is the default (US) Opsgenie API base.
+const opsgenieAPIURL = "https://api.opsgenie.com"
+
+// opsgeniePriorities maps finding severities to Opsgenie priorities.
+var opsgeniePriorities = map[analyzer.Severity]string{
+	analyzer.SeverityHigh:   "P1",
+	analyzer.SeverityMedium: "P2",
+	analyzer.SeverityLow:    "P3",
+	analyzer.SeverityInfo:   "P4",
+}
+
 type channel struct {
 	id   string
 	kind channelKind
 	on   map[EventType]bool
-
-	slack   *slackChannel
-	webhook *webhookChannel
-	email   *emailChannel
+	// route narrows the channel to matching namespaces and findings.
+	route routeFilter
+	// digest batches events into one message per window when set.
+	digest time.Duration
+	// reescalateAfter repeats open high findings at this interval.
+	reescalateAfter time.Duration
+
+	slack    *slackChannel
+	discord  *slackChannel
+	telegram *telegramChannel
+	opsgenie *opsgenieChannel
+	webhook  *webhookChannel
+	email    *emailChannel
 }
 
+// slackChannel also serves Discord, which takes the same incoming
+// webhook settings.
 type slackChannel struct {
 	webhookURL   string
 	dashboardURL string
+	actions      bool
+}
+
+// Slack button action IDs, handled by serve's /api/slack/actions endpoint.
+// The button value is the ignore rule to write for the finding.
+const (
+	SlackActionAck    = "mongospectre_ack"
+	SlackActionSnooze = "mongospectre_snooze"
+)
+
+// SlackSnoozeFor is how long the snooze button silences a finding.
+const SlackSnoozeFor = 7 * 24 * time.Hour
+
+type telegramChannel struct {
+	botToken     string
+	chatID       string
+	dashboardURL string
+}
+
+type opsgenieChannel struct {
+	apiURL string
+	apiKey string
 }
 
 type webhookChannel struct {
 	url     string
 	method  string
 	headers map[string]string
+	// body renders the request body when body_template is set.
+	body *template.Template
 }
 
 type emailChannel struct {
-	host     string
-	port     int
-	username string
-	password string
-	from     string
-	to       []string
-	subject  string
+	host      string
+	port      int
+	username  string
+	password  string
+	from      string
+	to        []string
+	subject   string
+	security  string // one of the smtpTLS modes
+	tlsConfig *tls.Config
 }
 
 // NewDispatcher builds a dispatcher from config file notification entries.
@@ -152,6 +258,10 @@ func NewDispatcher(cfgs []config.Notification, opts DispatcherOptions) (*Dispatc
 	if len(channels) == 0 {
 		return nil, fmt.Errorf("no notification channels configured")
 	}
+	quiet, err := parseQuietWindows(opts.QuietHours)
+	if err != nil {
+		return nil, err
+	}
 
 	writer := opts.Writer
 	if writer == nil {
@@ -168,52 +278,95 @@ func NewDispatcher(cfgs []config.Notification, opts DispatcherOptions) (*Dispatc
 		now = time.Now
 	}
 
-	sendMail := opts.SendMail
-	if sendMail == nil {
-		sendMail = smtp.SendMail
+	d := &Dispatcher{
+		channels:     channels,
+		interval:     opts.Interval,
+		dryRun:       opts.DryRun,
+		writer:       writer,
+		httpClient:   httpClient,
+		now:          now,
+		sendMail:     opts.SendMail,
+		cluster:      opts.Cluster,
+		tags:         opts.Tags,
+		statePath:    opts.StatePath,
+		quiet:        quiet,
+		silenceUntil: opts.SilenceUntil,
+		retries:      opts.Retries,
+		retryDelay:   2 * time.Second,
+		historyPath:  opts.HistoryPath,
+		lastSent:     make(map[string]time.Time),
+		pending:      make(map[string]*digestBatch),
+		escalations:  make(map[string]*escalation),
+		stats:        make(map[string]*DeliveryStats),
 	}
-
-	return &Dispatcher{
-		channels:   channels,
-		interval:   opts.Interval,
-		dryRun:     opts.DryRun,
-		writer:     writer,
-		httpClient: httpClient,
-		now:        now,
-		sendMail:   sendMail,
-		lastSent:   make(map[string]time.Time),
-	}, nil
+	if err := d.loadState(); err != nil {
+		return nil, err
+	}
+	if d.historyPath != "" {
+		if err := openHistory(d.historyPath); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
 }
 
-// Notify sends all events to all matching channels and aggregates non-fatal send errors.
+// Notify sends all events to all matching channels and aggregates non-fatal
+// send errors. Events for digest channels are queued until Flush, and
+// events during quiet hours until the window ends.
 func (d *Dispatcher) Notify(ctx context.Context, events []Event) error {
+	if quiet, drop := d.quietState(d.now()); quiet {
+		d.hold(events, drop)
+		return nil
+	}
+
 	var sendErrs []error
 
 	for i := range events {
 		event := &events[i]
+		if event.Cluster == "" {
+			event.Cluster, event.Tags = d.cluster, d.tags
+		}
+		if event.Type == EventResolved {
+			d.untrack(&event.Finding)
+		}
 		for _, ch := range d.channels {
-			if !ch.on[event.Type] {
+			if !ch.on[event.Type] || !ch.route.matches(event) {
 				continue
 			}
 
 			key := rateLimitKey(ch.id, &event.Finding)
+			if (ch.kind == channelOpsgenie && event.Type == EventResolved) || event.Type == EventChanged {
+				// A close, or a change, must not be swallowed by the
+				// alert it follows.
+				key += "|" + string(event.Type)
+			}
 			if !d.allow(key) {
+				d.record(Delivery{Channel: ch.id, Event: string(event.Type), Finding: findingLabel(event), Status: DeliveryRateLimited})
 				continue
 			}
 
-			if err := d.sendEvent(ctx, ch, event); err != nil {
+			if ch.digest > 0 {
+				d.enqueue(ch, event)
+			} else if err := d.deliverEvent(ctx, ch, event); err != nil {
 				sendErrs = append(sendErrs, fmt.Errorf("%s: %w", ch.id, err))
+				continue
+			}
+			if ch.reescalateAfter > 0 && event.Type == EventNewHigh {
+				d.track(ch, event)
 			}
 		}
 	}
 
+	if err := d.saveState(); err != nil {
+		sendErrs = append(sendErrs, err)
+	}
 	return errors.Join(sendErrs...)
 }
 
 func (d *Dispatcher) sendEvent(ctx context.Context, ch channel, event *Event) error {
 	switch ch.kind {
 	case channelSlack:
-		payload, err := buildSlackPayload(event, ch.slack.dashboardURL)
+		payload, err := buildSlackPayload(event, ch.slack)
 		if err != nil {
 			return err
 		}
@@ -222,8 +375,63 @@ func (d *Dispatcher) sendEvent(ctx context.Context, ch channel, event *Event) er
 			return nil
 		}
 		return d.postJSON(ctx, http.MethodPost, ch.slack.webhookURL, nil, payload)
+	case channelDiscord:
+		payload, err := buildDiscordPayload(event, ch.discord.dashboardURL)
+		if err != nil {
+			return err
+		}
+		if d.dryRun {
+			d.logDryRun(ch.id, event.Type, payload)
+			return nil
+		}
+		return d.postJSON(ctx, http.MethodPost, ch.discord.webhookURL, nil, payload)
+	case channelTelegram:
+		payload, err := buildTelegramPayload(event, ch.telegram)
+		if err != nil {
+			return err
+		}
+		if d.dryRun {
+			d.logDryRun(ch.id, event.Type, payload)
+			return nil
+		}
+		url := telegramAPIURL + "/bot" + ch.telegram.botToken + "/sendMessage"
+		if err := d.postJSON(ctx, http.MethodPost, url, nil, payload); err != nil {
+			// Transport errors quote the URL, which holds the token.
+			return &redactedError{err: err, secret: ch.telegram.botToken}
+		}
+		return nil
+	case channelOpsgenie:
+		// New findings open an alert; resolved ones close it through the
+		// same alias, so repeats of an open alert are deduplicated.
+		alias := opsgenieAlias(event)
+		url := ch.opsgenie.apiURL + "/v2/alerts"
+		var payload []byte
+		var err error
+		if event.Type == EventResolved {
+			url += "/" + neturl.PathEscape(alias) + "/close?identifierType=alias"
+			payload, err = json.Marshal(map[string]string{
+				"source": "mongospectre",
+				"note":   "Resolved: " + eventHeadline(event),
+			})
+		} else {
+			payload, err = buildOpsgeniePayload(event, alias)
+		}
+		if err != nil {
+			return err
+		}
+		if d.dryRun {
+			d.logDryRun(ch.id, event.Type, payload)
+			return nil
+		}
+		return d.postJSON(ctx, http.MethodPost, url, map[string]string{"Authorization": "GenieKey " + ch.opsgenie.apiKey}, payload)
 	case channelWebhook:
-		payload, err := buildWebhookPayload(event)
+		var payload []byte
+		var err error
+		if ch.webhook.body != nil {
+			payload, err = renderWebhookTemplate(ch.webhook.body, event)
+		} else {
+			payload, err = buildWebhookPayload(event)
+		}
 		if err != nil {
 			return err
 		}
@@ -238,12 +446,7 @@ func (d *Dispatcher) sendEvent(ctx context.Context, ch channel, event *Event) er
 			d.logEmailDryRun(ch.id, event.Type, subject, ch.email.to, message)
 			return nil
 		}
-		addr := fmt.Sprintf("%s:%d", ch.email.host, ch.email.port)
-		var auth smtp.Auth
-		if ch.email.username != "" {
-			auth = smtp.PlainAuth("", ch.email.username, ch.email.password, ch.email.host)
-		}
-		return d.sendMail(addr, auth, ch.email.from, ch.email.to, message)
+		return d.sendEmail(ctx, ch.email, message)
 	default:
 		return fmt.Errorf("unsupported channel type: %s", ch.kind)
 	}
@@ -267,7 +470,7 @@ func (d *Dispatcher) postJSON(ctx context.Context, method, url string, headers m
 
 	if resp.StatusCode >= http.StatusBadRequest {
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
-		return fmt.Errorf("http %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return &httpStatusError{code: resp.StatusCode, body: strings.TrimSpace(string(body))}
 	}
 	return nil
 }
@@ -334,6 +537,58 @@ func buildChannels(cfgs []config.Notification) ([]channel, error) {
 				slack: &slackChannel{
 					webhookURL:   webhookURL,
 					dashboardURL: expandEnvPlaceholders(strings.TrimSpace(raw.DashboardURL)),
+					actions:      raw.Actions,
+				},
+			})
+		case channelDiscord:
+			webhookURL, err := resolveSecretFromEnv(raw.WebhookURL, "discord webhook_url")
+			if err != nil {
+				return nil, fmt.Errorf("notifications[%d]: %w", i, err)
+			}
+			channels = append(channels, channel{
+				id:   fmt.Sprintf("discord[%d]", i),
+				kind: channelDiscord,
+				on:   on,
+				discord: &slackChannel{
+					webhookURL:   webhookURL,
+					dashboardURL: expandEnvPlaceholders(strings.TrimSpace(raw.DashboardURL)),
+				},
+			})
+		case channelTelegram:
+			botToken, err := resolveSecretFromEnv(raw.BotToken, "telegram bot_token")
+			if err != nil {
+				return nil, fmt.Errorf("notifications[%d]: %w", i, err)
+			}
+			chatID := strings.TrimSpace(expandEnvPlaceholders(raw.ChatID))
+			if chatID == "" {
+				return nil, fmt.Errorf("notifications[%d]: telegram chat_id is required", i)
+			}
+			channels = append(channels, channel{
+				id:   fmt.Sprintf("telegram[%d]", i),
+				kind: channelTelegram,
+				on:   on,
+				telegram: &telegramChannel{
+					botToken:     botToken,
+					chatID:       chatID,
+					dashboardURL: expandEnvPlaceholders(strings.TrimSpace(raw.DashboardURL)),
+				},
+			})
+		case channelOpsgenie:
+			apiKey, err := resolveSecretFromEnv(raw.APIKey, "opsgenie api_key")
+			if err != nil {
+				return nil, fmt.Errorf("notifications[%d]: %w", i, err)
+			}
+			apiURL := strings.TrimRight(expandEnvPlaceholders(strings.TrimSpace(raw.URL)), "/")
+			if apiURL == "" {
+				apiURL = opsgenieAPIURL
+			}
+			channels = append(channels, channel{
+				id:   fmt.Sprintf("opsgenie[%d]", i),
+				kind: channelOpsgenie,
+				on:   on,
+				opsgenie: &opsgenieChannel{
+					apiURL: apiURL,
+					apiKey: apiKey,
 				},
 			})
 		case channelWebhook:
@@ -357,6 +612,16 @@ func buildChannels(cfgs []config.Notification) ([]channel, error) {
 			if method == "" {
 				method = http.MethodPost
 			}
+			var body *template.Template
+			if strings.TrimSpace(raw.BodyTemplate) != "" {
+				body, err = parseWebhookTemplate(raw.BodyTemplate)
+				if err != nil {
+					return nil, fmt.Errorf("notifications[%d]: webhook body_template: %w", i, err)
+				}
+			}
+			if contentType := strings.TrimSpace(raw.ContentType); contentType != "" {
+				headers["Content-Type"] = contentType
+			}
 			channels = append(channels, channel{
 				id:   fmt.Sprintf("webhook[%d]", i),
 				kind: channelWebhook,
@@ -365,6 +630,7 @@ func buildChannels(cfgs []config.Notification) ([]channel, error) {
 					url:     url,
 					method:  method,
 					headers: headers,
+					body:    body,
 				},
 			})
 		case channelEmail:
@@ -372,9 +638,17 @@ func buildChannels(cfgs []config.Notification) ([]channel, error) {
 			if host == "" {
 				return nil, fmt.Errorf("notifications[%d]: email smtp_host is required", i)
 			}
+			security := strings.ToLower(strings.TrimSpace(raw.SMTPTLS))
+			tlsConfig, err := emailTLSConfig(security, host, strings.TrimSpace(raw.SMTPCAFile), raw.SMTPInsecure)
+			if err != nil {
+				return nil, fmt.Errorf("notifications[%d]: %w", i, err)
+			}
 			port := raw.SMTPPort
 			if port == 0 {
 				port = 25
+				if security == smtpTLSImplicit {
+					port = 465
+				}
 			}
 
 			to := make([]string, 0, len(raw.To))
@@ -410,18 +684,52 @@ func buildChannels(cfgs []config.Notification) ([]channel, error) {
 				kind: channelEmail,
 				on:   on,
 				email: &emailChannel{
-					host:     host,
-					port:     port,
-					username: username,
-					password: password,
-					from:     from,
-					to:       to,
-					subject:  strings.TrimSpace(expandEnvPlaceholders(raw.Subject)),
+					host:      host,
+					port:      port,
+					username:  username,
+					password:  password,
+					from:      from,
+					to:        to,
+					subject:   strings.TrimSpace(expandEnvPlaceholders(raw.Subject)),
+					security:  security,
+					tlsConfig: tlsConfig,
 				},
 			})
 		default:
 			return nil, fmt.Errorf("notifications[%d]: unsupported type %q", i, raw.Type)
 		}
+
+		if raw.Actions && kind != channelSlack {
+			return nil, fmt.Errorf("notifications[%d]: actions is only supported for slack", i)
+		}
+
+		route, err := parseRouteFilter(raw)
+		if err != nil {
+			return nil, fmt.Errorf("notifications[%d]: %w", i, err)
+		}
+		channels[len(channels)-1].route = route
+
+		if digest := strings.TrimSpace(raw.Digest); digest != "" {
+			window, err := time.ParseDuration(digest)
+			if err != nil || window <= 0 {
+				return nil, fmt.Errorf("notifications[%d]: invalid digest %q: want a positive duration such as 15m", i, raw.Digest)
+			}
+			switch {
+			case kind == channelOpsgenie:
+				return nil, fmt.Errorf("notifications[%d]: digest is not supported for opsgenie, which deduplicates alerts itself", i)
+			case kind == channelWebhook && strings.TrimSpace(raw.BodyTemplate) != "":
+				return nil, fmt.Errorf("notifications[%d]: digest cannot be combined with body_template", i)
+			}
+			channels[len(channels)-1].digest = window
+		}
+
+		if after := strings.TrimSpace(raw.ReescalateAfter); after != "" {
+			window, err := time.ParseDuration(after)
+			if err != nil || window <= 0 {
+				return nil, fmt.Errorf("notifications[%d]: invalid reescalate_after %q: want a positive duration such as 24h", i, raw.ReescalateAfter)
+			}
+			channels[len(channels)-1].reescalateAfter = window
+		}
 	}
 	return channels, nil
 }
@@ -521,7 +829,7 @@ func parseEventFilters(raw []string) (map[EventType]bool, error) {
 	for _, item := range raw {
 		event := EventType(strings.ToLower(strings.TrimSpace(item)))
 		switch event {
-		case EventNewHigh, EventNewMedium, EventNewLow, EventResolved:
+		case EventNewHigh, EventNewMedium, EventNewLow, EventResolved, EventChanged:
 			result[event] = true
 		default:
 			return nil, fmt.Errorf("unsupported event filter %q", item)
@@ -553,10 +861,17 @@ func buildWebhookPayload(event *Event) ([]byte, error) {
 			"message":    event.Finding.Message,
 		},
 	}
+	if event.Cluster != "" {
+		payload["cluster"] = event.Cluster
+	}
+	if len(event.Tags) > 0 {
+		payload["tags"] = event.Tags
+	}
 	return json.Marshal(payload)
 }
 
-func buildSlackPayload(event *Event, dashboardURL string) ([]byte, error) {
+// eventColor is the attachment color for an event, by finding severity.
+func eventColor(event *Event) string {
 	color := "#2eb886"
 	if event.Type == EventResolved {
 		color = "#439fe0"
@@ -569,62 +884,268 @@ func buildSlackPayload(event *Event, dashboardURL string) ([]byte, error) {
 	case analyzer.SeverityLow:
 		color = "#36a64f"
 	}
+	return color
+}
 
+func eventLocation(event *Event) string {
 	location := event.Finding.Database + "." + event.Finding.Collection
 	if event.Finding.Index != "" {
 		location += "." + event.Finding.Index
 	}
+	return location
+}
 
-	text := fmt.Sprintf("mongospectre %s: %s (%s)", strings.ToUpper(string(event.Type)), event.Finding.Type, location)
-	if dashboardURL != "" {
-		text += fmt.Sprintf(" | <%s|Open dashboard>", dashboardURL)
+// eventHeadline is the one-line summary shared by the chat channels.
+// Changed events that moved severity say so.
+func eventHeadline(event *Event) string {
+	headline := fmt.Sprintf("mongospectre %s: %s (%s)", strings.ToUpper(string(event.Type)), event.Finding.Type, eventLocation(event))
+	if event.Type == EventChanged && event.PreviousSeverity != "" {
+		headline += fmt.Sprintf(", severity %s → %s", event.PreviousSeverity, event.Finding.Severity)
+	}
+	return headline
+}
+
+// webhookTemplateData is what a webhook body_template renders: the event
+// fields plus the location and headline the built-in channels use.
+type webhookTemplateData struct {
+	Event
+	Location string
+	Headline string
+}
+
+var webhookTemplateFuncs = template.FuncMap{
+	// json renders a value as a JSON literal, quoting and escaping strings.
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"join":  strings.Join,
+}
+
+// parseWebhookTemplate parses a body template and renders it once against
+// a sample event, so unknown fields fail at startup instead of on the
+// first alert.
+func parseWebhookTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("body").Funcs(webhookTemplateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	sample := Event{
+		Type:    EventNewHigh,
+		Finding: analyzer.Finding{Type: analyzer.FindingMissingIndex, Severity: analyzer.SeverityHigh, Database: "db", Collection: "coll"},
+		Status:  analyzer.StatusNew,
+	}
+	if _, err := renderWebhookTemplate(tmpl, &sample); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+func renderWebhookTemplate(tmpl *template.Template, event *Event) ([]byte, error) {
+	var buf bytes.Buffer
+	data := webhookTemplateData{Event: *event, Location: eventLocation(event), Headline: eventHeadline(event)}
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func buildSlackPayload(event *Event, cfg *slackChannel) ([]byte, error) {
+	location := eventLocation(event)
+	text := eventHeadline(event)
+	if cfg.dashboardURL != "" {
+		text += fmt.Sprintf(" | <%s|Open dashboard>", cfg.dashboardURL)
+	}
+
+	fields := []map[string]interface{}{
+		{"title": "Severity", "value": strings.ToUpper(string(event.Finding.Severity)), "short": true},
+		{"title": "Type", "value": string(event.Finding.Type), "short": true},
+		{"title": "Location", "value": location, "short": false},
+		{"title": "Message", "value": event.Finding.Message, "short": false},
+	}
+	if event.Finding.RuleID != "" {
+		fields = append(fields, map[string]interface{}{"title": "Rule", "value": fmt.Sprintf("<%s|%s>", event.Finding.DocURL, event.Finding.RuleID), "short": true})
+	}
+	if event.Cluster != "" {
+		fields = append(fields, map[string]interface{}{"title": "Cluster", "value": clusterLabel(event), "short": true})
 	}
 
 	payload := map[string]interface{}{
 		"text": text,
 		"attachments": []map[string]interface{}{
 			{
-				"color": color,
-				"fields": []map[string]interface{}{
-					{"title": "Severity", "value": strings.ToUpper(string(event.Finding.Severity)), "short": true},
-					{"title": "Type", "value": string(event.Finding.Type), "short": true},
-					{"title": "Location", "value": location, "short": false},
-					{"title": "Message", "value": event.Finding.Message, "short": false},
-				},
+				"color":  eventColor(event),
+				"fields": fields,
 				"footer": "mongospectre watch",
 				"ts":     time.Now().Unix(),
 			},
 		},
 	}
+	if cfg.actions && event.Type != EventResolved && !analyzer.IsRedacted(&event.Finding) {
+		// With blocks present Slack shows them instead of text, so the
+		// headline is repeated in a section above the buttons. Redacted
+		// findings get none: a rule for the masked name would not match.
+		value := analyzer.IgnoreRuleFor(&event.Finding).String()
+		payload["blocks"] = []map[string]interface{}{
+			{"type": "section", "text": map[string]string{"type": "mrkdwn", "text": text}},
+			{"type": "actions", "elements": []map[string]interface{}{
+				slackButton("Acknowledge", SlackActionAck, value, "primary"),
+				slackButton("Snooze 7d", SlackActionSnooze, value, ""),
+			}},
+		}
+	}
 	return json.Marshal(payload)
 }
 
-func buildEmailMessage(event *Event, cfg *emailChannel) (string, []byte) {
-	location := event.Finding.Database + "." + event.Finding.Collection
-	if event.Finding.Index != "" {
-		location += "." + event.Finding.Index
+func slackButton(label, actionID, value, style string) map[string]interface{} {
+	button := map[string]interface{}{
+		"type":      "button",
+		"text":      map[string]string{"type": "plain_text", "text": label},
+		"action_id": actionID,
+		"value":     value,
+	}
+	if style != "" {
+		button["style"] = style
 	}
+	return button
+}
+
+// parseHexColor converts a "#rrggbb" color to the integer Discord expects.
+func parseHexColor(color string) (int64, error) {
+	return strconv.ParseInt(strings.TrimPrefix(color, "#"), 16, 32)
+}
+
+// discordFieldMax is the length limit of an embed field value; Discord
+// rejects the whole message when one is longer.
+const discordFieldMax = 1024
+
+// discordField is an embed field with its value cut to discordFieldMax.
+func discordField(name, value string, inline bool) map[string]interface{} {
+	return map[string]interface{}{"name": name, "value": truncateRunes(value, discordFieldMax), "inline": inline}
+}
 
-	subject := cfg.subject
-	if subject == "" {
-		subject = fmt.Sprintf("[mongospectre] %s %s", strings.ToUpper(string(event.Type)), location)
+// buildDiscordPayload renders the Slack message as a Discord embed.
+func buildDiscordPayload(event *Event, dashboardURL string) ([]byte, error) {
+	color, err := parseHexColor(eventColor(event))
+	if err != nil {
+		return nil, err
 	}
 
-	htmlBody := fmt.Sprintf(`<html><body><h3>mongospectre alert: %s</h3><p><strong>Type:</strong> %s</p><p><strong>Severity:</strong> %s</p><p><strong>Location:</strong> %s</p><p><strong>Message:</strong> %s</p><p><strong>Timestamp:</strong> %s</p></body></html>`,
-		event.Type,
-		event.Finding.Type,
-		event.Finding.Severity,
-		location,
-		event.Finding.Message,
-		event.Timestamp,
-	)
+	fields := []map[string]interface{}{
+		discordField("Severity", strings.ToUpper(string(event.Finding.Severity)), true),
+		discordField("Type", string(event.Finding.Type), true),
+		discordField("Location", eventLocation(event), false),
+		discordField("Message", event.Finding.Message, false),
+	}
+	if event.Finding.RuleID != "" {
+		fields = append(fields, discordField("Rule", fmt.Sprintf("[%s](%s)", event.Finding.RuleID, event.Finding.DocURL), true))
+	}
+	if event.Cluster != "" {
+		fields = append(fields, discordField("Cluster", clusterLabel(event), true))
+	}
 
-	msg := "MIME-version: 1.0\r\n" +
-		"Content-Type: text/html; charset=\"UTF-8\"\r\n" +
-		fmt.Sprintf("Subject: %s\r\n", subject) +
-		fmt.Sprintf("To: %s\r\n", strings.Join(cfg.to, ",")) +
-		"\r\n" +
-		htmlBody
+	embed := map[string]interface{}{
+		"title":  eventHeadline(event),
+		"color":  color,
+		"fields": fields,
+		"footer": map[string]string{"text": "mongospectre watch"},
+	}
+	if event.Timestamp != "" {
+		embed["timestamp"] = event.Timestamp
+	}
+	if dashboardURL != "" {
+		embed["url"] = dashboardURL
+	}
+	return json.Marshal(map[string]interface{}{"embeds": []map[string]interface{}{embed}})
+}
 
-	return subject, []byte(msg)
+// buildTelegramPayload renders a sendMessage request with the same fields
+// as the Slack attachment, formatted as Telegram HTML.
+func buildTelegramPayload(event *Event, cfg *telegramChannel) ([]byte, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<b>%s</b>\n", html.EscapeString(eventHeadline(event)))
+	fmt.Fprintf(&b, "<b>Severity:</b> %s\n", strings.ToUpper(string(event.Finding.Severity)))
+	fmt.Fprintf(&b, "<b>Type:</b> %s\n", html.EscapeString(string(event.Finding.Type)))
+	fmt.Fprintf(&b, "<b>Location:</b> %s\n", html.EscapeString(eventLocation(event)))
+	fmt.Fprintf(&b, "<b>Message:</b> %s", html.EscapeString(event.Finding.Message))
+	if event.Finding.RuleID != "" {
+		fmt.Fprintf(&b, "\n<b>Rule:</b> <a href=\"%s\">%s</a>", html.EscapeString(event.Finding.DocURL), html.EscapeString(event.Finding.RuleID))
+	}
+	if event.Cluster != "" {
+		fmt.Fprintf(&b, "\n<b>Cluster:</b> %s", html.EscapeString(clusterLabel(event)))
+	}
+	if cfg.dashboardURL != "" {
+		fmt.Fprintf(&b, "\n<a href=\"%s\">Open dashboard</a>", html.EscapeString(cfg.dashboardURL))
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"chat_id":                  cfg.chatID,
+		"text":                     b.String(),
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	})
+}
+
+// opsgenieAlias is the deduplication key of a finding's alert: its
+// fingerprint, scoped to the cluster so the same drift on two clusters
+// opens two alerts.
+func opsgenieAlias(event *Event) string {
+	alias := "mongospectre-" + analyzer.Fingerprint(&event.Finding)
+	if event.Cluster != "" {
+		alias = "mongospectre-" + event.Cluster + "-" + analyzer.Fingerprint(&event.Finding)
+	}
+	return alias
+}
+
+func buildOpsgeniePayload(event *Event, alias string) ([]byte, error) {
+	priority := opsgeniePriorities[event.Finding.Severity]
+	if priority == "" {
+		priority = "P3"
+	}
+
+	// Opsgenie truncates messages at 130 characters and descriptions at
+	// 15000.
+	message := truncateRunes(eventHeadline(event), 130)
+
+	details := map[string]string{
+		"type":     string(event.Finding.Type),
+		"severity": string(event.Finding.Severity),
+		"location": eventLocation(event),
+	}
+	tags := []string{"mongospectre", string(event.Finding.Severity)}
+	if event.Cluster != "" {
+		details["cluster"] = event.Cluster
+		tags = append(tags, event.Tags...)
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"message":     message,
+		"alias":       alias,
+		"description": truncateRunes(event.Finding.Message, 15000),
+		"priority":    priority,
+		"source":      "mongospectre",
+		"entity":      eventLocation(event),
+		"tags":        tags,
+		"details":     details,
+	})
+}
+
+// truncateRunes shortens s to at most max characters, ending it with "..."
+// when cut. It cuts on a rune boundary so multi-byte characters stay whole.
+func truncateRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:max-3]) + "..."
+}
+
+// clusterLabel formats the event's cluster with its tags, e.g. "prod-eu (prod, eu)".
+func clusterLabel(event *Event) string {
+	if len(event.Tags) == 0 {
+		return event.Cluster
+	}
+	return fmt.Sprintf("%s (%s)", event.Cluster, strings.Join(event.Tags, ", "))
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/smtp"
@@ -11,6 +12,7 @@ import (
 	"sync"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/ppiankov/mongospectre/internal/analyzer"
 	"github.com/ppiankov/mongospectre/internal/config"
@@ -101,6 +103,36 @@ func TestEventsFromDiff(t *testing.T) {
 	}
 }
 
+func TestEventsFromDiffChanged(t *testing.T) {
+	diff := []analyzer.BaselineFinding{{
+		Finding:          analyzer.Finding{Type: analyzer.FindingMissingIndex, Severity: analyzer.SeverityHigh, Database: "app", Collection: "orders"},
+		Status:           analyzer.StatusChanged,
+		PreviousSeverity: analyzer.SeverityMedium,
+	}}
+	events := EventsFromDiff(diff, time.Date(2026, 2, 17, 21, 0, 0, 0, time.UTC))
+	if len(events) != 1 || events[0].Type != EventChanged {
+		t.Fatalf("events = %+v, want one changed event", events)
+	}
+	if events[0].PreviousSeverity != analyzer.SeverityMedium {
+		t.Errorf("previous severity = %q", events[0].PreviousSeverity)
+	}
+	if got := eventHeadline(&events[0]); !strings.HasSuffix(got, "severity medium → high") {
+		t.Errorf("headline = %q", got)
+	}
+
+	defaults, err := parseEventFilters(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if defaults[EventChanged] {
+		t.Error("changed events should be opt-in")
+	}
+	explicit, err := parseEventFilters([]string{"changed"})
+	if err != nil || !explicit[EventChanged] {
+		t.Errorf("parseEventFilters(changed) = %v, %v", explicit, err)
+	}
+}
+
 func TestNewDispatcherExpandsEnvPlaceholders(t *testing.T) {
 	t.Setenv("SLACK_WEBHOOK_URL", "https://hooks.slack.test/123")
 	t.Setenv("ALERT_TOKEN", "abc123")
@@ -160,6 +192,31 @@ func TestNewDispatcherRejectsPlaintextSecrets(t *testing.T) {
 			},
 			want: `webhook header "Authorization" must use ${ENV_VAR} placeholder`,
 		},
+		{
+			name: "discord webhook",
+			cfg: config.Notification{
+				Type:       "discord",
+				WebhookURL: "https://discord.com/api/webhooks/1/plaintext",
+			},
+			want: "discord webhook_url must use ${ENV_VAR} placeholder",
+		},
+		{
+			name: "telegram bot token",
+			cfg: config.Notification{
+				Type:     "telegram",
+				BotToken: "123:plaintext",
+				ChatID:   "-100200",
+			},
+			want: "telegram bot_token must use ${ENV_VAR} placeholder",
+		},
+		{
+			name: "opsgenie api key",
+			cfg: config.Notification{
+				Type:   "opsgenie",
+				APIKey: "plain-key",
+			},
+			want: "opsgenie api_key must use ${ENV_VAR} placeholder",
+		},
 		{
 			name: "email smtp password",
 			cfg: config.Notification{
@@ -280,6 +337,259 @@ func TestDispatcherNotifySlackAndWebhook(t *testing.T) {
 	}
 }
 
+func TestDispatcherNotifyDiscordAndTelegram(t *testing.T) {
+	t.Setenv("DISCORD_WEBHOOK_URL", "https://discord.test/api/webhooks/1/abc")
+	t.Setenv("TELEGRAM_BOT_TOKEN", "123:secret")
+	t.Setenv("TELEGRAM_CHAT_ID", "-100200")
+
+	rt := &recordingRoundTripper{}
+	d, err := NewDispatcher([]config.Notification{
+		{
+			Type:         "discord",
+			WebhookURL:   "${DISCORD_WEBHOOK_URL}",
+			DashboardURL: "https://dash.example.com",
+		},
+		{
+			Type:     "telegram",
+			BotToken: "${TELEGRAM_BOT_TOKEN}",
+			ChatID:   "${TELEGRAM_CHAT_ID}",
+		},
+	}, DispatcherOptions{HTTPClient: &http.Client{Transport: rt}, Cluster: "prod-eu"})
+	if err != nil {
+		t.Fatalf("NewDispatcher error: %v", err)
+	}
+
+	event := Event{
+		Type:      EventNewHigh,
+		Timestamp: "2026-02-17T21:30:00Z",
+		Status:    analyzer.StatusNew,
+		Finding: analyzer.Finding{
+			Type:       analyzer.FindingMissingIndex,
+			Severity:   analyzer.SeverityHigh,
+			Database:   "app",
+			Collection: "orders",
+			Message:    "query on <status> has no index",
+		},
+	}
+	if err := d.Notify(context.Background(), []Event{event}); err != nil {
+		t.Fatalf("Notify error: %v", err)
+	}
+
+	requests := rt.snapshot()
+	if len(requests) != 2 {
+		t.Fatalf("requests = %d, want 2", len(requests))
+	}
+
+	var discord struct {
+		Embeds []struct {
+			Title  string `json:"title"`
+			URL    string `json:"url"`
+			Color  int    `json:"color"`
+			Fields []struct {
+				Name  string `json:"name"`
+				Value string `json:"value"`
+			} `json:"fields"`
+		} `json:"embeds"`
+	}
+	if requests[0].URL != "https://discord.test/api/webhooks/1/abc" {
+		t.Fatalf("discord request URL = %q", requests[0].URL)
+	}
+	if err := json.Unmarshal(requests[0].Body, &discord); err != nil || len(discord.Embeds) != 1 {
+		t.Fatalf("discord payload = %s (%v)", requests[0].Body, err)
+	}
+	embed := discord.Embeds[0]
+	if embed.Color != 0xd40e0d || embed.URL != "https://dash.example.com" || !strings.Contains(embed.Title, "NEW_HIGH") {
+		t.Fatalf("discord embed = %+v", embed)
+	}
+	if last := embed.Fields[len(embed.Fields)-1]; last.Name != "Cluster" || last.Value != "prod-eu" {
+		t.Fatalf("discord cluster field = %+v", last)
+	}
+
+	var telegram map[string]interface{}
+	if requests[1].URL != "https://api.telegram.org/bot123:secret/sendMessage" {
+		t.Fatalf("telegram request URL = %q", requests[1].URL)
+	}
+	if err := json.Unmarshal(requests[1].Body, &telegram); err != nil {
+		t.Fatalf("telegram payload: %v", err)
+	}
+	text, _ := telegram["text"].(string)
+	if telegram["chat_id"] != "-100200" || telegram["parse_mode"] != "HTML" {
+		t.Fatalf("telegram payload = %v", telegram)
+	}
+	if !strings.Contains(text, "query on &lt;status&gt; has no index") || !strings.Contains(text, "<b>Cluster:</b> prod-eu") {
+		t.Fatalf("telegram text = %q", text)
+	}
+}
+
+func TestDispatcherOpsgenieOpensAndClosesByAlias(t *testing.T) {
+	t.Setenv("OPSGENIE_API_KEY", "genie")
+
+	rt := &recordingRoundTripper{status: http.StatusAccepted}
+	d, err := NewDispatcher([]config.Notification{
+		{Type: "opsgenie", APIKey: "${OPSGENIE_API_KEY}", URL: "https://api.eu.opsgenie.com/"},
+	}, DispatcherOptions{HTTPClient: &http.Client{Transport: rt}, Interval: time.Hour, Cluster: "prod"})
+	if err != nil {
+		t.Fatalf("NewDispatcher error: %v", err)
+	}
+
+	finding := analyzer.Finding{
+		Type:       analyzer.FindingUnusedIndex,
+		Severity:   analyzer.SeverityMedium,
+		Database:   "app",
+		Collection: "orders",
+		Index:      "status_1",
+		Message:    "index never used",
+	}
+	events := []Event{
+		{Type: EventNewMedium, Finding: finding, Status: analyzer.StatusNew},
+		{Type: EventResolved, Finding: finding, Status: analyzer.StatusResolved},
+	}
+	if err := d.Notify(context.Background(), events); err != nil {
+		t.Fatalf("Notify error: %v", err)
+	}
+
+	requests := rt.snapshot()
+	if len(requests) != 2 {
+		t.Fatalf("requests = %d, want open and close", len(requests))
+	}
+	alias := "mongospectre-prod-" + analyzer.Fingerprint(&finding)
+
+	var created map[string]interface{}
+	if err := json.Unmarshal(requests[0].Body, &created); err != nil {
+		t.Fatal(err)
+	}
+	if requests[0].URL != "https://api.eu.opsgenie.com/v2/alerts" || requests[0].Headers.Get("Authorization") != "GenieKey genie" {
+		t.Fatalf("create request = %s %v", requests[0].URL, requests[0].Headers)
+	}
+	if created["priority"] != "P2" || created["alias"] != alias {
+		t.Fatalf("create payload = %v, want P2 with alias %s", created, alias)
+	}
+	if want := "https://api.eu.opsgenie.com/v2/alerts/" + alias + "/close?identifierType=alias"; requests[1].URL != want {
+		t.Fatalf("close URL = %q, want %q", requests[1].URL, want)
+	}
+}
+
+func TestBuildDiscordPayloadCapsFieldValues(t *testing.T) {
+	event := Event{
+		Type:    EventNewMedium,
+		Status:  analyzer.StatusNew,
+		Finding: analyzer.Finding{Type: analyzer.FindingUnusedIndex, Severity: analyzer.SeverityMedium, Message: strings.Repeat("é", 3000)},
+	}
+	body, err := buildDiscordPayload(&event, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload struct {
+		Embeds []struct {
+			Fields []struct{ Name, Value string } `json:"fields"`
+		} `json:"embeds"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range payload.Embeds[0].Fields {
+		if f.Name == "Message" && utf8.RuneCountInString(f.Value) != discordFieldMax {
+			t.Errorf("message field has %d characters, want %d", utf8.RuneCountInString(f.Value), discordFieldMax)
+		}
+	}
+}
+
+func TestBuildOpsgeniePayloadTruncatesOnRunes(t *testing.T) {
+	event := Event{
+		Type:   EventNewHigh,
+		Status: analyzer.StatusNew,
+		Finding: analyzer.Finding{Type: analyzer.FindingMissingIndex, Severity: analyzer.SeverityHigh,
+			Database: "app", Collection: strings.Repeat("заказы", 30), Message: strings.Repeat("ü", 20000)},
+	}
+	body, err := buildOpsgeniePayload(&event, "alias")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	message, description := payload["message"].(string), payload["description"].(string)
+	if !utf8.ValidString(message) || utf8.RuneCountInString(message) != 130 || !strings.HasSuffix(message, "...") {
+		t.Errorf("message = %q (%d runes)", message, utf8.RuneCountInString(message))
+	}
+	if !utf8.ValidString(description) || utf8.RuneCountInString(description) != 15000 {
+		t.Errorf("description has %d runes, want 15000", utf8.RuneCountInString(description))
+	}
+}
+
+func TestDispatcherWebhookBodyTemplate(t *testing.T) {
+	rt := &recordingRoundTripper{}
+	d, err := NewDispatcher([]config.Notification{
+		{
+			Type:         "webhook",
+			URL:          "https://snow.example.com/api/now/table/incident",
+			BodyTemplate: `short_description={{ .Headline }}&urgency={{ if eq .Finding.Severity "high" }}1{{ else }}3{{ end }}&detail={{ json .Finding.Message }}`,
+			ContentType:  "application/x-www-form-urlencoded",
+		},
+	}, DispatcherOptions{HTTPClient: &http.Client{Transport: rt}})
+	if err != nil {
+		t.Fatalf("NewDispatcher error: %v", err)
+	}
+
+	event := Event{
+		Type:    EventNewHigh,
+		Status:  analyzer.StatusNew,
+		Finding: analyzer.Finding{Type: analyzer.FindingMissingIndex, Severity: analyzer.SeverityHigh, Database: "app", Collection: "orders", Message: `scan on "status"`},
+	}
+	if err := d.Notify(context.Background(), []Event{event}); err != nil {
+		t.Fatalf("Notify error: %v", err)
+	}
+
+	requests := rt.snapshot()
+	if len(requests) != 1 {
+		t.Fatalf("requests = %d, want 1", len(requests))
+	}
+	want := `short_description=mongospectre NEW_HIGH: MISSING_INDEX (app.orders)&urgency=1&detail="scan on \"status\""`
+	if string(requests[0].Body) != want {
+		t.Fatalf("body = %s\nwant   %s", requests[0].Body, want)
+	}
+	if got := requests[0].Headers.Get("Content-Type"); got != "application/x-www-form-urlencoded" {
+		t.Fatalf("content type = %q", got)
+	}
+}
+
+func TestNewDispatcherRejectsInvalidBodyTemplate(t *testing.T) {
+	for _, tmpl := range []string{`{{ .Headline `, `{{ .Finding.Assignee }}`} {
+		_, err := NewDispatcher([]config.Notification{
+			{Type: "webhook", URL: "https://hooks.example.com", BodyTemplate: tmpl},
+		}, DispatcherOptions{})
+		if err == nil || !strings.Contains(err.Error(), "webhook body_template") {
+			t.Errorf("template %q: err = %v, want body_template error", tmpl, err)
+		}
+	}
+}
+
+func TestDispatcherTelegramErrorRedactsToken(t *testing.T) {
+	t.Setenv("TELEGRAM_BOT_TOKEN", "123:secret")
+
+	d, err := NewDispatcher([]config.Notification{
+		{Type: "telegram", BotToken: "${TELEGRAM_BOT_TOKEN}", ChatID: "42"},
+	}, DispatcherOptions{HTTPClient: &http.Client{Transport: failingRoundTripper{}}})
+	if err != nil {
+		t.Fatalf("NewDispatcher error: %v", err)
+	}
+
+	err = d.Notify(context.Background(), []Event{{Type: EventNewHigh, Finding: analyzer.Finding{Severity: analyzer.SeverityHigh}}})
+	if err == nil {
+		t.Fatal("expected send error")
+	}
+	if strings.Contains(err.Error(), "123:secret") {
+		t.Fatalf("error leaks the bot token: %v", err)
+	}
+}
+
+type failingRoundTripper struct{}
+
+func (failingRoundTripper) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
 func TestDispatcherRateLimiting(t *testing.T) {
 	rt := &recordingRoundTripper{}
 	httpClient := &http.Client{Transport: rt}
@@ -461,3 +771,77 @@ func TestBuildWebhookPayloadDoesNotIncludeURI(t *testing.T) {
 		t.Fatalf("invalid payload JSON: %v", err)
 	}
 }
+
+func TestDispatcherLabelsEventsWithCluster(t *testing.T) {
+	var logs bytes.Buffer
+	d, err := NewDispatcher([]config.Notification{
+		{Type: "webhook", URL: "https://alerts.example.com/hook"},
+	}, DispatcherOptions{
+		DryRun:  true,
+		Writer:  &logs,
+		Cluster: "prod-eu",
+		Tags:    []string{"prod", "eu"},
+	})
+	if err != nil {
+		t.Fatalf("NewDispatcher error: %v", err)
+	}
+
+	event := Event{
+		Type:    EventNewHigh,
+		Status:  analyzer.StatusNew,
+		Finding: analyzer.Finding{Type: analyzer.FindingMissingIndex, Severity: analyzer.SeverityHigh, Database: "app"},
+	}
+	if err := d.Notify(context.Background(), []Event{event}); err != nil {
+		t.Fatalf("Notify error: %v", err)
+	}
+	if !strings.Contains(logs.String(), `"cluster":"prod-eu"`) || !strings.Contains(logs.String(), `"tags":["prod","eu"]`) {
+		t.Fatalf("payload missing cluster labels: %s", logs.String())
+	}
+
+	slack, err := buildSlackPayload(&Event{Type: EventNewHigh, Cluster: "prod-eu", Tags: []string{"prod"}}, &slackChannel{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(slack), "prod-eu (prod)") {
+		t.Errorf("slack payload missing cluster field: %s", slack)
+	}
+}
+
+func TestSlackPayloadActions(t *testing.T) {
+	event := &Event{Type: EventNewHigh, Finding: analyzer.Finding{Type: analyzer.FindingUnusedIndex, Database: "app", Collection: "users", Index: "idx_old"}}
+	payload, err := buildSlackPayload(event, &slackChannel{actions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{SlackActionAck, SlackActionSnooze, `"value":"UNUSED_INDEX app.users.idx_old"`} {
+		if !strings.Contains(string(payload), want) {
+			t.Errorf("payload missing %s: %s", want, payload)
+		}
+	}
+
+	// A rule for a masked index name would never match, so no buttons.
+	redacted := &Event{Type: EventNewHigh, Finding: analyzer.Finding{Type: analyzer.FindingUnusedIndex, Database: "app", Collection: "users", Index: "[redacted:pii]_1"}}
+	payload, err = buildSlackPayload(redacted, &slackChannel{actions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(payload), "blocks") {
+		t.Errorf("redacted findings should not carry buttons: %s", payload)
+	}
+
+	resolved := &Event{Type: EventResolved, Finding: event.Finding}
+	payload, err = buildSlackPayload(resolved, &slackChannel{actions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(payload), "blocks") {
+		t.Errorf("resolved events should not carry buttons: %s", payload)
+	}
+
+	t.Setenv("DISCORD_WEBHOOK_URL", "https://discord.test/hook")
+	if _, err := NewDispatcher([]config.Notification{
+		{Type: "discord", WebhookURL: "${DISCORD_WEBHOOK_URL}", Actions: true},
+	}, DispatcherOptions{}); err == nil || !strings.Contains(err.Error(), "only supported for slack") {
+		t.Errorf("expected actions to be rejected for discord, got %v", err)
+	}
+}
@@ -0,0 +1,206 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/config"
+	"github.com/ppiankov/mongospectre/internal/schedule"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// quietWindow is a parsed quiet_hours entry: a daily time range, or a
+// cron start with a duration, in its own time zone.
+type quietWindow struct {
+	loc  *time.Location
+	drop bool
+
+	// Daily range in minutes after midnight; to < from wraps past
+	// midnight. days holds weekday bits for the day the range starts on,
+	// zero meaning every day.
+	from, to int
+	days     uint8
+
+	cron     *schedule.Cron
+	duration time.Duration
+}
+
+func parseQuietWindows(cfgs []config.QuietWindow) ([]quietWindow, error) {
+	windows := make([]quietWindow, 0, len(cfgs))
+	for i := range cfgs {
+		w, err := parseQuietWindow(&cfgs[i])
+		if err != nil {
+			return nil, fmt.Errorf("quiet_hours[%d]: %w", i, err)
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+func parseQuietWindow(raw *config.QuietWindow) (quietWindow, error) {
+	w := quietWindow{loc: time.Local}
+	if tz := strings.TrimSpace(raw.Timezone); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return quietWindow{}, fmt.Errorf("invalid timezone %q: %w", tz, err)
+		}
+		w.loc = loc
+	}
+	switch strings.ToLower(strings.TrimSpace(raw.Action)) {
+	case "", "queue":
+	case "drop":
+		w.drop = true
+	default:
+		return quietWindow{}, fmt.Errorf("invalid action %q: want queue or drop", raw.Action)
+	}
+
+	if expr := strings.TrimSpace(raw.Schedule); expr != "" {
+		if raw.From != "" || raw.To != "" || len(raw.Days) > 0 {
+			return quietWindow{}, fmt.Errorf("schedule cannot be combined with from, to or days")
+		}
+		c, err := schedule.Parse(expr)
+		if err != nil {
+			return quietWindow{}, err
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(raw.Duration))
+		if err != nil || d <= 0 {
+			return quietWindow{}, fmt.Errorf("invalid duration %q: a schedule needs a positive duration such as 2h", raw.Duration)
+		}
+		w.cron, w.duration = c, d
+		return w, nil
+	}
+
+	var err error
+	if w.from, err = parseClock(raw.From); err != nil {
+		return quietWindow{}, fmt.Errorf("invalid from: %w", err)
+	}
+	if w.to, err = parseClock(raw.To); err != nil {
+		return quietWindow{}, fmt.Errorf("invalid to: %w", err)
+	}
+	if w.from == w.to {
+		return quietWindow{}, fmt.Errorf("from and to must differ")
+	}
+	for _, day := range raw.Days {
+		// Accept "mon" as well as "monday".
+		key := strings.ToLower(strings.TrimSpace(day))
+		if len(key) > 3 {
+			key = key[:3]
+		}
+		wd, ok := weekdays[key]
+		if !ok {
+			return quietWindow{}, fmt.Errorf("invalid day %q", day)
+		}
+		w.days |= 1 << uint(wd)
+	}
+	return w, nil
+}
+
+// parseClock parses "HH:MM" into minutes after midnight.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a HH:MM time", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func (w *quietWindow) contains(t time.Time) bool {
+	local := t.In(w.loc)
+	if w.cron != nil {
+		// The latest start within the past duration, if any, covers t.
+		start := w.cron.Next(local.Add(-w.duration))
+		return !start.IsZero() && !start.After(local)
+	}
+
+	minute := local.Hour()*60 + local.Minute()
+	if w.from < w.to {
+		return minute >= w.from && minute < w.to && w.startsOn(local.Weekday())
+	}
+	if minute >= w.from {
+		return w.startsOn(local.Weekday())
+	}
+	// Past midnight: the range started the day before.
+	return minute < w.to && w.startsOn((local.Weekday()+6)%7)
+}
+
+func (w *quietWindow) startsOn(day time.Weekday) bool {
+	return w.days == 0 || w.days&(1<<uint(day)) != 0
+}
+
+// quietState reports whether notifications are held back at now, and
+// whether they are dropped rather than queued. --silence and drop windows
+// win over queue windows.
+func (d *Dispatcher) quietState(now time.Time) (quiet, drop bool) {
+	if now.Before(d.silenceUntil) {
+		return true, true
+	}
+	for i := range d.quiet {
+		if d.quiet[i].contains(now) {
+			quiet = true
+			drop = drop || d.quiet[i].drop
+		}
+	}
+	return quiet, drop
+}
+
+// hold queues or drops events that arrive during a quiet period.
+func (d *Dispatcher) hold(events []Event, drop bool) {
+	status := DeliveryHeld
+	if drop {
+		status = DeliveryDropped
+	}
+	for i := range events {
+		if events[i].Type == EventResolved {
+			d.untrack(&events[i].Finding)
+		}
+		d.record(Delivery{Channel: allChannels, Event: string(events[i].Type), Finding: findingLabel(&events[i]), Status: status})
+	}
+	if drop {
+		_, _ = fmt.Fprintf(d.writer, "[notify] quiet period: dropped %d events\n", len(events))
+		return
+	}
+	d.mu.Lock()
+	d.held = append(d.held, events...)
+	n := len(d.held)
+	d.mu.Unlock()
+	_, _ = fmt.Fprintf(d.writer, "[notify] quiet period: holding %d events until it ends\n", n)
+}
+
+// release sends the events held during a quiet period that has ended.
+func (d *Dispatcher) release(ctx context.Context) error {
+	d.mu.Lock()
+	held := d.held
+	d.held = nil
+	d.mu.Unlock()
+	if len(held) == 0 {
+		return nil
+	}
+	return d.Notify(ctx, held)
+}
+
+// discardHeld reports what a shutdown during a quiet period leaves unsent.
+func (d *Dispatcher) discardHeld() error {
+	d.mu.Lock()
+	held, pending := d.held, d.pending
+	d.held, d.pending = nil, make(map[string]*digestBatch)
+	d.mu.Unlock()
+
+	n := len(held)
+	for i := range held {
+		d.record(Delivery{Channel: allChannels, Event: string(held[i].Type), Finding: findingLabel(&held[i]), Status: DeliveryDropped})
+	}
+	for id, batch := range pending {
+		n += len(batch.events)
+		d.record(Delivery{Channel: id, Event: "digest", Events: len(batch.events), Status: DeliveryDropped})
+	}
+	if n == 0 {
+		return nil
+	}
+	return fmt.Errorf("quiet period: %d held notifications discarded on shutdown", n)
+}
@@ -0,0 +1,126 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	"github.com/ppiankov/mongospectre/internal/config"
+)
+
+func TestQuietWindowContains(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("tzdata not available")
+	}
+	tests := []struct {
+		name string
+		cfg  config.QuietWindow
+		at   time.Time
+		want bool
+	}{
+		{"overnight before midnight", config.QuietWindow{From: "22:00", To: "07:00"}, time.Date(2026, 3, 2, 23, 0, 0, 0, time.Local), true},
+		{"overnight after midnight", config.QuietWindow{From: "22:00", To: "07:00"}, time.Date(2026, 3, 3, 6, 59, 0, 0, time.Local), true},
+		{"overnight daytime", config.QuietWindow{From: "22:00", To: "07:00"}, time.Date(2026, 3, 3, 7, 0, 0, 0, time.Local), false},
+		// 2026-03-07 is a Saturday; the Friday night range runs into it.
+		{"weekday range spills into saturday", config.QuietWindow{From: "20:00", To: "08:00", Days: []string{"mon", "tue", "wed", "thu", "friday"}}, time.Date(2026, 3, 7, 3, 0, 0, 0, time.Local), true},
+		{"weekday range skips saturday night", config.QuietWindow{From: "20:00", To: "08:00", Days: []string{"fri"}}, time.Date(2026, 3, 7, 21, 0, 0, 0, time.Local), false},
+		{"timezone", config.QuietWindow{From: "09:00", To: "10:00", Timezone: "Europe/Berlin"}, time.Date(2026, 3, 2, 9, 30, 0, 0, berlin).UTC(), true},
+		{"cron window", config.QuietWindow{Schedule: "0 2 * * sun", Duration: "3h"}, time.Date(2026, 3, 8, 4, 59, 0, 0, time.Local), true},
+		{"cron window over", config.QuietWindow{Schedule: "0 2 * * sun", Duration: "3h"}, time.Date(2026, 3, 8, 5, 0, 0, 0, time.Local), false},
+	}
+	for _, tt := range tests {
+		w, err := parseQuietWindow(&tt.cfg)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := w.contains(tt.at); got != tt.want {
+			t.Errorf("%s: contains(%s) = %v, want %v", tt.name, tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestParseQuietWindowErrors(t *testing.T) {
+	for _, cfg := range []config.QuietWindow{
+		{From: "22:00", To: "22:00"},
+		{From: "late", To: "07:00"},
+		{From: "22:00", To: "07:00", Days: []string{"someday"}},
+		{From: "22:00", To: "07:00", Timezone: "Mars/Olympus"},
+		{From: "22:00", To: "07:00", Action: "mute"},
+		{Schedule: "0 2 * * *"},
+		{Schedule: "0 2 * * *", Duration: "1h", From: "01:00"},
+	} {
+		if _, err := parseQuietWindow(&cfg); err == nil {
+			t.Errorf("%+v: expected error", cfg)
+		}
+	}
+}
+
+func TestDispatcherQueuesDuringQuietHours(t *testing.T) {
+	now := time.Date(2026, 3, 2, 23, 0, 0, 0, time.Local)
+	rt := &recordingRoundTripper{}
+	d, err := NewDispatcher([]config.Notification{
+		{Type: "webhook", URL: "https://alerts.test/hook"},
+	}, DispatcherOptions{
+		HTTPClient: &http.Client{Transport: rt},
+		Now:        func() time.Time { return now },
+		QuietHours: []config.QuietWindow{{From: "22:00", To: "07:00"}},
+	})
+	if err != nil {
+		t.Fatalf("NewDispatcher error: %v", err)
+	}
+
+	event := Event{Type: EventNewHigh, Finding: analyzer.Finding{Type: analyzer.FindingMissingIndex, Severity: analyzer.SeverityHigh, Database: "app", Collection: "orders"}}
+	if err := d.Notify(context.Background(), []Event{event}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(rt.snapshot()); n != 0 {
+		t.Fatalf("sent %d requests during quiet hours, want 0", n)
+	}
+
+	now = time.Date(2026, 3, 3, 7, 5, 0, 0, time.Local)
+	if err := d.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(rt.snapshot()); n != 1 {
+		t.Fatalf("sent %d requests after quiet hours, want the held event", n)
+	}
+}
+
+func TestDispatcherSilenceDrops(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	rt := &recordingRoundTripper{}
+	d, err := NewDispatcher([]config.Notification{
+		{Type: "webhook", URL: "https://alerts.test/hook"},
+	}, DispatcherOptions{
+		HTTPClient:   &http.Client{Transport: rt},
+		Now:          func() time.Time { return now },
+		SilenceUntil: now.Add(2 * time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("NewDispatcher error: %v", err)
+	}
+
+	event := Event{Type: EventNewHigh, Finding: analyzer.Finding{Severity: analyzer.SeverityHigh}}
+	if err := d.Notify(context.Background(), []Event{event}); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(3 * time.Hour)
+	if err := d.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(rt.snapshot()); n != 0 {
+		t.Fatalf("sent %d requests, want the silenced event dropped", n)
+	}
+	if err := d.Notify(context.Background(), []Event{event}); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(rt.snapshot()); n != 1 {
+		t.Fatalf("sent %d requests after the silence, want 1", n)
+	}
+}
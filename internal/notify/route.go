@@ -0,0 +1,120 @@
+package notify
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	"github.com/ppiankov/mongospectre/internal/config"
+)
+
+var severityRank = map[analyzer.Severity]int{
+	analyzer.SeverityInfo:   0,
+	analyzer.SeverityLow:    1,
+	analyzer.SeverityMedium: 2,
+	analyzer.SeverityHigh:   3,
+}
+
+// routeFilter limits a channel to the findings of one team: an event must
+// pass every filter that is set. Database and collection filters are glob
+// patterns (path.Match syntax).
+type routeFilter struct {
+	databases    []string
+	collections  []string
+	minSeverity  analyzer.Severity
+	findingTypes map[analyzer.FindingType]bool
+	owners       []string
+}
+
+func parseRouteFilter(raw *config.Notification) (routeFilter, error) {
+	var route routeFilter
+	for _, field := range []struct {
+		name     string
+		patterns []string
+		dst      *[]string
+	}{
+		{"databases", raw.Databases, &route.databases},
+		{"collections", raw.Collections, &route.collections},
+	} {
+		for _, p := range field.patterns {
+			p = strings.TrimSpace(p)
+			if p == "" {
+				continue
+			}
+			if _, err := path.Match(p, ""); err != nil {
+				return routeFilter{}, fmt.Errorf("invalid %s pattern %q: %w", field.name, p, err)
+			}
+			*field.dst = append(*field.dst, p)
+		}
+	}
+
+	if raw.MinSeverity != "" {
+		sev := analyzer.Severity(strings.ToLower(strings.TrimSpace(raw.MinSeverity)))
+		if _, ok := severityRank[sev]; !ok {
+			return routeFilter{}, fmt.Errorf("invalid min_severity %q: want info, low, medium or high", raw.MinSeverity)
+		}
+		route.minSeverity = sev
+	}
+
+	for _, t := range raw.FindingTypes {
+		t = strings.ToUpper(strings.TrimSpace(t))
+		if t == "" {
+			continue
+		}
+		if route.findingTypes == nil {
+			route.findingTypes = make(map[analyzer.FindingType]bool)
+		}
+		route.findingTypes[analyzer.FindingType(t)] = true
+	}
+	for _, o := range raw.Owners {
+		if o = strings.TrimSpace(o); o != "" {
+			route.owners = append(route.owners, o)
+		}
+	}
+	return route, nil
+}
+
+func (r *routeFilter) matches(event *Event) bool {
+	f := &event.Finding
+	if !matchAny(r.databases, f.Database) || !matchAny(r.collections, f.Collection) {
+		return false
+	}
+	if r.minSeverity != "" && severityRank[f.Severity] < severityRank[r.minSeverity] {
+		return false
+	}
+	if r.findingTypes != nil && !r.findingTypes[f.Type] {
+		return false
+	}
+	if len(r.owners) > 0 && !ownedByAny(f.Owner, r.owners) {
+		return false
+	}
+	return true
+}
+
+// ownedByAny reports whether owner, a space-separated owner list, includes
+// one of want. Names compare case-insensitively, as on GitHub.
+func ownedByAny(owner string, want []string) bool {
+	for _, o := range strings.Fields(owner) {
+		for _, w := range want {
+			if strings.EqualFold(o, w) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchAny reports whether name matches one of patterns; no patterns
+// match everything.
+func matchAny(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	"github.com/ppiankov/mongospectre/internal/config"
+)
+
+func TestDispatcherRoutesByNamespaceAndSeverity(t *testing.T) {
+	rt := &recordingRoundTripper{}
+	d, err := NewDispatcher([]config.Notification{
+		{Type: "webhook", URL: "https://payments.test/hook", Databases: []string{"payments*"}, MinSeverity: "medium"},
+		{Type: "webhook", URL: "https://dba.test/hook", FindingTypes: []string{"unused_index"}},
+		{Type: "webhook", URL: "https://orders.test/hook", Collections: []string{"orders"}},
+	}, DispatcherOptions{HTTPClient: &http.Client{Transport: rt}})
+	if err != nil {
+		t.Fatalf("NewDispatcher error: %v", err)
+	}
+
+	events := []Event{
+		{Type: EventNewHigh, Finding: analyzer.Finding{Type: analyzer.FindingMissingIndex, Severity: analyzer.SeverityHigh, Database: "payments_eu", Collection: "charges"}},
+		{Type: EventNewLow, Finding: analyzer.Finding{Type: analyzer.FindingMissingTTL, Severity: analyzer.SeverityLow, Database: "payments", Collection: "charges"}},
+		{Type: EventNewMedium, Finding: analyzer.Finding{Type: analyzer.FindingUnusedIndex, Severity: analyzer.SeverityMedium, Database: "shop", Collection: "orders"}},
+	}
+	if err := d.Notify(context.Background(), events); err != nil {
+		t.Fatalf("Notify error: %v", err)
+	}
+
+	got := make(map[string]int)
+	for _, req := range rt.snapshot() {
+		got[req.URL]++
+	}
+	want := map[string]int{
+		"https://payments.test/hook": 1, // high payments_eu; the low one is below min_severity
+		"https://dba.test/hook":      1, // the unused index only
+		"https://orders.test/hook":   1,
+	}
+	for url, n := range want {
+		if got[url] != n {
+			t.Errorf("%s received %d events, want %d (all: %v)", url, got[url], n, got)
+		}
+	}
+}
+
+func TestDispatcherRoutesByOwner(t *testing.T) {
+	rt := &recordingRoundTripper{}
+	d, err := NewDispatcher([]config.Notification{
+		{Type: "webhook", URL: "https://billing.test/hook", Owners: []string{"@org/Billing"}},
+	}, DispatcherOptions{HTTPClient: &http.Client{Transport: rt}})
+	if err != nil {
+		t.Fatalf("NewDispatcher error: %v", err)
+	}
+
+	events := []Event{
+		{Type: EventNewHigh, Finding: analyzer.Finding{Type: analyzer.FindingMissingIndex, Severity: analyzer.SeverityHigh, Database: "billing", Collection: "invoices", Owner: "@org/billing @alice"}},
+		{Type: EventNewHigh, Finding: analyzer.Finding{Type: analyzer.FindingMissingIndex, Severity: analyzer.SeverityHigh, Database: "shop", Collection: "orders", Owner: "@org/shop"}},
+		{Type: EventNewHigh, Finding: analyzer.Finding{Type: analyzer.FindingMissingIndex, Severity: analyzer.SeverityHigh, Database: "shop", Collection: "carts"}},
+	}
+	if err := d.Notify(context.Background(), events); err != nil {
+		t.Fatalf("Notify error: %v", err)
+	}
+	if n := len(rt.snapshot()); n != 1 {
+		t.Errorf("billing channel received %d events, want 1", n)
+	}
+}
+
+func TestNewDispatcherRejectsInvalidRoute(t *testing.T) {
+	tests := []struct {
+		cfg  config.Notification
+		want string
+	}{
+		{config.Notification{Type: "webhook", URL: "https://x.test", MinSeverity: "critical"}, "invalid min_severity"},
+		{config.Notification{Type: "webhook", URL: "https://x.test", Databases: []string{"pay[ments"}}, "invalid databases pattern"},
+	}
+	for _, tt := range tests {
+		_, err := NewDispatcher([]config.Notification{tt.cfg}, DispatcherOptions{})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%+v: err = %v, want %q", tt.cfg, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package reporter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+)
+
+// SaveBaseline writes report as a JSON baseline into dir and removes the
+// oldest baselines so that at most keep remain (keep <= 0 keeps all).
+// Returns the path written and the paths removed.
+func SaveBaseline(dir string, report *Report, keep int) (string, []string, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return "", nil, fmt.Errorf("create baseline dir: %w", err)
+	}
+
+	ts, err := time.Parse(time.RFC3339, report.Metadata.Timestamp)
+	if err != nil {
+		ts = time.Now()
+	}
+	path := filepath.Join(dir, analyzer.BaselineFileName(ts))
+
+	// Write to a temp file first so a crash never leaves a truncated baseline
+	// that later runs would pick up as the most recent one.
+	tmp, err := os.CreateTemp(dir, ".baseline-*.tmp")
+	if err != nil {
+		return "", nil, fmt.Errorf("create baseline: %w", err)
+	}
+	if err := writeJSON(tmp, report); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return "", nil, fmt.Errorf("write baseline: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", nil, fmt.Errorf("write baseline: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", nil, fmt.Errorf("write baseline: %w", err)
+	}
+
+	if keep <= 0 {
+		return path, nil, nil
+	}
+	paths, err := analyzer.ListBaselines(dir)
+	if err != nil {
+		return path, nil, fmt.Errorf("list baselines: %w", err)
+	}
+	var removed []string
+	for len(paths) > keep {
+		if err := os.Remove(paths[0]); err != nil {
+			return path, removed, fmt.Errorf("rotate baselines: %w", err)
+		}
+		removed = append(removed, paths[0])
+		paths = paths[1:]
+	}
+	return path, removed, nil
+}
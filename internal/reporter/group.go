@@ -0,0 +1,222 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	"github.com/ppiankov/mongospectre/internal/i18n"
+)
+
+// GroupBy selects how the text report groups findings.
+type GroupBy string
+
+const (
+	GroupByNone       GroupBy = ""
+	GroupByType       GroupBy = "type"
+	GroupByCollection GroupBy = "collection"
+	GroupByOwner      GroupBy = "owner"
+)
+
+// groupDetailLimit caps the example findings listed under each type group.
+const groupDetailLimit = 5
+
+var severityRank = map[analyzer.Severity]int{
+	analyzer.SeverityHigh:   3,
+	analyzer.SeverityMedium: 2,
+	analyzer.SeverityLow:    1,
+	analyzer.SeverityInfo:   0,
+}
+
+var severityLabel = map[analyzer.Severity]string{
+	analyzer.SeverityHigh:   "HIGH",
+	analyzer.SeverityMedium: "MEDIUM",
+	analyzer.SeverityLow:    "LOW",
+	analyzer.SeverityInfo:   "INFO",
+}
+
+// findingGroup is a set of findings sharing a type or a collection.
+type findingGroup struct {
+	key      string
+	findings []analyzer.Finding
+	maxSev   analyzer.Severity
+}
+
+// WriteText outputs the report as text with findings grouped by type,
+// collection or owner. GroupByNone writes the flat list, like Write with FormatText.
+func WriteText(w io.Writer, report *Report, groupBy GroupBy) error {
+	report = localize(report)
+	if groupBy == GroupByNone || report.Summary.Total == 0 {
+		return writeText(w, report)
+	}
+	l := i18n.Lang(report.Metadata.Lang)
+	if err := writeTextHeader(w, report); err != nil {
+		return err
+	}
+
+	groups := groupFindings(report.Findings, groupBy)
+	for i, g := range groups {
+		if i > 0 {
+			_, _ = fmt.Fprintln(w)
+		}
+		var err error
+		if groupBy == GroupByType {
+			err = writeTypeGroup(w, &g, l)
+		} else {
+			err = writeCollectionGroup(w, &g, l, groupBy == GroupByOwner)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return writeTextSummary(w, report)
+}
+
+// groupFindings groups findings by key, ordering groups by highest severity,
+// then size, then key.
+func groupFindings(findings []analyzer.Finding, groupBy GroupBy) []findingGroup {
+	byKey := make(map[string]*findingGroup)
+	var order []string
+	for _, f := range findings {
+		key := string(f.Type)
+		switch groupBy {
+		case GroupByCollection:
+			key = collectionKey(&f)
+		case GroupByOwner:
+			key = ownerKey(&f)
+		}
+		g := byKey[key]
+		if g == nil {
+			g = &findingGroup{key: key, maxSev: f.Severity}
+			byKey[key] = g
+			order = append(order, key)
+		}
+		g.findings = append(g.findings, f)
+		if severityRank[f.Severity] > severityRank[g.maxSev] {
+			g.maxSev = f.Severity
+		}
+	}
+	groups := make([]findingGroup, 0, len(order))
+	for _, key := range order {
+		groups = append(groups, *byKey[key])
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if severityRank[a.maxSev] != severityRank[b.maxSev] {
+			return severityRank[a.maxSev] > severityRank[b.maxSev]
+		}
+		if len(a.findings) != len(b.findings) {
+			return len(a.findings) > len(b.findings)
+		}
+		return a.key < b.key
+	})
+	return groups
+}
+
+// writeTypeGroup writes a one-line summary such as "UNUSED_INDEX on 47
+// indexes across 12 collections" followed by the first few findings.
+func writeTypeGroup(w io.Writer, g *findingGroup, l i18n.Lang) error {
+	collections := make(map[string]bool)
+	allIndexes := true
+	for i := range g.findings {
+		collections[collectionKey(&g.findings[i])] = true
+		if g.findings[i].Index == "" {
+			allIndexes = false
+		}
+	}
+	count := "%d findings"
+	if allIndexes {
+		count = "%d indexes"
+	}
+	if len(g.findings) == 1 {
+		count = "%d finding"
+		if allIndexes {
+			count = "%d index"
+		}
+	}
+	scope := ""
+	switch n := len(collections); {
+	case n > 1:
+		scope = l.Sprintf(" across %d collections", n)
+	case n == 1 && g.findings[0].Database != "":
+		scope = l.Sprintf(" in %s", collectionKey(&g.findings[0]))
+	}
+	if _, err := fmt.Fprintf(w, "[%s] %s %s%s\n", l.T(severityLabel[g.maxSev]), g.key,
+		l.Sprintf("on %s", l.Sprintf(count, len(g.findings))), scope); err != nil {
+		return err
+	}
+	for i, f := range g.findings {
+		if i == groupDetailLimit {
+			_, err := fmt.Fprintf(w, "  %s\n", l.Sprintf("... and %d more (use --format json for all)", len(g.findings)-i))
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "  - %s: %s\n", l.T(findingLocation(&f)), f.Message); err != nil {
+			return err
+		}
+		if err := writeBlame(w, &f, "    ", l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCollectionGroup writes a collection or owner header with severity
+// counts and every finding in the group; withCollection also names each
+// finding's collection, for owner groups.
+func writeCollectionGroup(w io.Writer, g *findingGroup, l i18n.Lang, withCollection bool) error {
+	counts := make(map[analyzer.Severity]int)
+	for _, f := range g.findings {
+		counts[f.Severity]++
+	}
+	count := "%d findings"
+	if len(g.findings) == 1 {
+		count = "%d finding"
+	}
+	if _, err := fmt.Fprintf(w, "%s (%s: %s)\n", l.T(g.key), l.Sprintf(count, len(g.findings)),
+		l.Sprintf("high=%d medium=%d low=%d info=%d", counts[analyzer.SeverityHigh], counts[analyzer.SeverityMedium],
+			counts[analyzer.SeverityLow], counts[analyzer.SeverityInfo])); err != nil {
+		return err
+	}
+	for _, f := range g.findings {
+		line := fmt.Sprintf("  [%s] %s: %s", l.T(severityLabel[f.Severity]), f.Type, f.Message)
+		if f.Index != "" {
+			line += " " + l.Sprintf("(index %s)", f.Index)
+		}
+		if withCollection {
+			line += " (" + l.T(findingLocation(&f)) + ")"
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+		if err := writeBlame(w, &f, "    ", l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ownerKey names the owner a finding is grouped under.
+func ownerKey(f *analyzer.Finding) string {
+	if f.Owner == "" {
+		return "(unowned)"
+	}
+	return f.Owner
+}
+
+// collectionKey names the collection a finding belongs to; cluster-level
+// findings have no database.
+func collectionKey(f *analyzer.Finding) string {
+	if f.Database == "" {
+		return "(cluster)"
+	}
+	return f.Database + "." + f.Collection
+}
+
+func findingLocation(f *analyzer.Finding) string {
+	loc := collectionKey(f)
+	if f.Index != "" {
+		loc += "." + f.Index
+	}
+	return loc
+}
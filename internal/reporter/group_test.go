@@ -0,0 +1,129 @@
+package reporter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+)
+
+func groupTestFindings() []analyzer.Finding {
+	var findings []analyzer.Finding
+	for i := 0; i < 7; i++ {
+		findings = append(findings, analyzer.Finding{
+			Type:       analyzer.FindingUnusedIndex,
+			Severity:   analyzer.SeverityMedium,
+			Database:   "app",
+			Collection: fmt.Sprintf("c%d", i%3),
+			Index:      fmt.Sprintf("idx_%d", i),
+			Message:    "index has never been used",
+		})
+	}
+	findings = append(findings,
+		analyzer.Finding{Type: analyzer.FindingMissingIndex, Severity: analyzer.SeverityHigh, Database: "app", Collection: "c0", Message: "only _id index"},
+		analyzer.Finding{Type: analyzer.FindingBalancerDisabled, Severity: analyzer.SeverityMedium, Message: "chunk balancer is disabled"},
+	)
+	return findings
+}
+
+func TestWriteText_GroupByType(t *testing.T) {
+	r := NewReport(groupTestFindings())
+	var buf bytes.Buffer
+	if err := WriteText(&buf, &r, GroupByType); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "[HIGH] MISSING_INDEX on 1 finding in app.c0\n") {
+		t.Errorf("high group should come first:\n%s", out)
+	}
+	if !strings.Contains(out, "[MEDIUM] UNUSED_INDEX on 7 indexes across 3 collections\n") {
+		t.Errorf("missing UNUSED_INDEX summary:\n%s", out)
+	}
+	if !strings.Contains(out, "  ... and 2 more") {
+		t.Errorf("details should be capped:\n%s", out)
+	}
+	if !strings.Contains(out, "[MEDIUM] BALANCER_DISABLED on 1 finding\n") {
+		t.Errorf("cluster finding group:\n%s", out)
+	}
+	if !strings.Contains(out, "Summary: 9 findings") {
+		t.Errorf("missing summary:\n%s", out)
+	}
+}
+
+func TestWriteText_GroupByCollection(t *testing.T) {
+	r := NewReport(groupTestFindings())
+	var buf bytes.Buffer
+	if err := WriteText(&buf, &r, GroupByCollection); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "app.c0 (4 findings: high=1 medium=3 low=0 info=0)\n") {
+		t.Errorf("c0 should come first:\n%s", out)
+	}
+	if !strings.Contains(out, "(cluster) (1 finding:") {
+		t.Errorf("missing cluster group:\n%s", out)
+	}
+	if !strings.Contains(out, "  [MEDIUM] UNUSED_INDEX: index has never been used (index idx_0)\n") {
+		t.Errorf("missing detail line:\n%s", out)
+	}
+}
+
+func TestWriteText_NoGroupingMatchesWrite(t *testing.T) {
+	r := NewReport(testFindings)
+	var grouped, flat bytes.Buffer
+	if err := WriteText(&grouped, &r, GroupByNone); err != nil {
+		t.Fatal(err)
+	}
+	if err := Write(&flat, &r, FormatText); err != nil {
+		t.Fatal(err)
+	}
+	if grouped.String() != flat.String() {
+		t.Errorf("ungrouped output differs:\n%s\nvs\n%s", grouped.String(), flat.String())
+	}
+}
+
+func TestWriteText_GroupByTypeLocalized(t *testing.T) {
+	r := NewReport(groupTestFindings())
+	r.Metadata.Lang = "de"
+	var buf bytes.Buffer
+	if err := WriteText(&buf, &r, GroupByType); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "[MITTEL] UNUSED_INDEX – 7 Indizes in 3 Collections\n") {
+		t.Errorf("missing localized group line:\n%s", out)
+	}
+	if !strings.Contains(out, "  - (Cluster): Chunk-Balancer ist deaktiviert\n") {
+		t.Errorf("missing localized message:\n%s", out)
+	}
+	if !strings.Contains(out, "Zusammenfassung: 9 Befunde (hoch=1 mittel=8 niedrig=0 info=0)") {
+		t.Errorf("missing localized summary:\n%s", out)
+	}
+}
+
+func TestWriteText_GroupByOwner(t *testing.T) {
+	r := NewReport([]analyzer.Finding{
+		{Type: analyzer.FindingUnindexedQuery, Severity: analyzer.SeverityMedium, Database: "app", Collection: "invoices",
+			Message: "field \"status\" is queried in code but has no covering index", Owner: "@org/billing"},
+		{Type: analyzer.FindingMissingCollection, Severity: analyzer.SeverityHigh, Collection: "ledger",
+			Message: "collection \"ledger\" referenced in code but does not exist in database", Owner: "@org/billing"},
+		{Type: analyzer.FindingUnusedIndex, Severity: analyzer.SeverityMedium, Database: "app", Collection: "users", Index: "a_1",
+			Message: "index has never been used"},
+	})
+	var buf bytes.Buffer
+	if err := WriteText(&buf, &r, GroupByOwner); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "@org/billing (2 findings: high=1 medium=1 low=0 info=0)\n") {
+		t.Errorf("billing group should come first:\n%s", out)
+	}
+	if !strings.Contains(out, "  [MEDIUM] UNINDEXED_QUERY: field \"status\" is queried in code but has no covering index (app.invoices)\n") {
+		t.Errorf("owner group lines should name the collection:\n%s", out)
+	}
+	if !strings.Contains(out, "(unowned) (1 finding: high=0 medium=1 low=0 info=0)\n") {
+		t.Errorf("missing unowned group:\n%s", out)
+	}
+}
@@ -0,0 +1,138 @@
+package reporter
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+)
+
+// FormatLSPJSON is the LSP diagnostics output format constant.
+const FormatLSPJSON Format = "lsp-json"
+
+// lspPublishDiagnostics mirrors the Language Server Protocol's
+// PublishDiagnosticsParams, so editor plugins can hand each entry to their
+// diagnostics API as is.
+type lspPublishDiagnostics struct {
+	URI         string          `json:"uri"`
+	Diagnostics []lspDiagnostic `json:"diagnostics"`
+}
+
+type lspDiagnostic struct {
+	Range           lspRange            `json:"range"`
+	Severity        int                 `json:"severity"`
+	Code            string              `json:"code,omitempty"`
+	CodeDescription *lspCodeDescription `json:"codeDescription,omitempty"`
+	Source          string              `json:"source"`
+	Message         string              `json:"message"`
+	Data            lspDiagnosticData   `json:"data"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+// lspPosition is zero-based, unlike finding lines.
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspCodeDescription struct {
+	Href string `json:"href"`
+}
+
+// lspDiagnosticData carries what a quick fix needs, such as an ignore rule
+// for the finding.
+type lspDiagnosticData struct {
+	Type       analyzer.FindingType `json:"type"`
+	Database   string               `json:"database,omitempty"`
+	Collection string               `json:"collection,omitempty"`
+	Index      string               `json:"index,omitempty"`
+	Owner      string               `json:"owner,omitempty"`
+}
+
+// LSP DiagnosticSeverity values.
+const (
+	lspSeverityError       = 1
+	lspSeverityWarning     = 2
+	lspSeverityInformation = 3
+	lspSeverityHint        = 4
+)
+
+// writeLSP writes findings with a code location as LSP diagnostics, one
+// entry per file. Each diagnostic covers the whole line the finding points
+// at; findings without a location, such as unused indexes, are left out
+// because an editor has nowhere to show them.
+func writeLSP(w io.Writer, report *Report) error {
+	byURI := make(map[string][]lspDiagnostic)
+	for _, f := range report.Findings {
+		if f.File == "" {
+			continue
+		}
+		line := max(f.Line-1, 0)
+		d := lspDiagnostic{
+			Range: lspRange{
+				Start: lspPosition{Line: line},
+				End:   lspPosition{Line: line + 1},
+			},
+			Severity: severityToLSP(f.Severity),
+			Code:     f.RuleID,
+			Source:   "mongospectre",
+			Message:  f.Message,
+			Data: lspDiagnosticData{
+				Type:       f.Type,
+				Database:   f.Database,
+				Collection: f.Collection,
+				Index:      f.Index,
+				Owner:      f.Owner,
+			},
+		}
+		if f.DocURL != "" {
+			d.CodeDescription = &lspCodeDescription{Href: f.DocURL}
+		}
+		uri := fileURI(report.Metadata.RepoPath, f.File)
+		byURI[uri] = append(byURI[uri], d)
+	}
+
+	out := make([]lspPublishDiagnostics, 0, len(byURI))
+	for uri, diags := range byURI {
+		out = append(out, lspPublishDiagnostics{URI: uri, Diagnostics: diags})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].URI < out[j].URI })
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// fileURI returns the file:// URI of file, a path relative to the scanned
+// repo. Editors match diagnostics to open documents by absolute URI.
+func fileURI(repo, file string) string {
+	path := filepath.Join(repo, file)
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	path = filepath.ToSlash(path)
+	if filepath.VolumeName(path) != "" {
+		path = "/" + path // C:/src → /C:/src
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
+
+func severityToLSP(s analyzer.Severity) int {
+	switch s {
+	case analyzer.SeverityHigh:
+		return lspSeverityError
+	case analyzer.SeverityMedium:
+		return lspSeverityWarning
+	case analyzer.SeverityLow:
+		return lspSeverityInformation
+	default:
+		return lspSeverityHint
+	}
+}
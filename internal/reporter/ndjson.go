@@ -0,0 +1,39 @@
+package reporter
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+)
+
+// FormatNDJSON writes one JSON finding per line.
+const FormatNDJSON Format = "ndjson"
+
+// NDJSONWriter writes findings one JSON object per line. Each line carries
+// the rule ID and documentation URL. Messages stay in English, as in the
+// JSON report.
+type NDJSONWriter struct {
+	enc *json.Encoder
+}
+
+// NewNDJSONWriter returns a writer of NDJSON findings to w.
+func NewNDJSONWriter(w io.Writer) *NDJSONWriter {
+	return &NDJSONWriter{enc: json.NewEncoder(w)}
+}
+
+// WriteFinding writes f as a single line.
+func (n *NDJSONWriter) WriteFinding(f analyzer.Finding) error {
+	f.AnnotateRule()
+	return n.enc.Encode(f)
+}
+
+func writeNDJSON(w io.Writer, report *Report) error {
+	n := NewNDJSONWriter(w)
+	for i := range report.Findings {
+		if err := n.WriteFinding(report.Findings[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
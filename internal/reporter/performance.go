@@ -0,0 +1,51 @@
+package reporter
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Performance records how long each inspection phase and analyzer detector
+// took, to show which flags are worth turning off on slow clusters.
+type Performance struct {
+	TotalMS   float64  `json:"totalMs"`
+	Phases    []Timing `json:"phases"`
+	Detectors []Timing `json:"detectors,omitempty"`
+}
+
+// Timing is the time spent in one phase or detector.
+type Timing struct {
+	Name       string  `json:"name"`
+	DurationMS float64 `json:"durationMs"`
+}
+
+// NewTiming converts d to a Timing rounded to the microsecond.
+func NewTiming(name string, d time.Duration) Timing {
+	return Timing{Name: name, DurationMS: float64(d.Microseconds()) / 1000}
+}
+
+// WritePerformance writes phase and detector timings as an aligned list.
+func WritePerformance(w io.Writer, p *Performance) {
+	width := 0
+	for _, ts := range [][]Timing{p.Phases, p.Detectors} {
+		for _, t := range ts {
+			width = max(width, len(t.Name))
+		}
+	}
+	_, _ = fmt.Fprintf(w, "Timings (total %s):\n", formatMS(p.TotalMS))
+	for _, t := range p.Phases {
+		_, _ = fmt.Fprintf(w, "  %-*s  %s\n", width, t.Name, formatMS(t.DurationMS))
+	}
+	if len(p.Detectors) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintln(w, "Detectors:")
+	for _, t := range p.Detectors {
+		_, _ = fmt.Fprintf(w, "  %-*s  %s\n", width, t.Name, formatMS(t.DurationMS))
+	}
+}
+
+func formatMS(ms float64) string {
+	return time.Duration(ms * float64(time.Millisecond)).Round(10 * time.Microsecond).String()
+}
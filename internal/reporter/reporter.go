@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/ppiankov/mongospectre/internal/analyzer"
+	"github.com/ppiankov/mongospectre/internal/i18n"
 	mongoinspect "github.com/ppiankov/mongospectre/internal/mongo"
 	"github.com/ppiankov/mongospectre/internal/scanner"
 )
@@ -29,6 +31,20 @@ type Metadata struct {
 	MongoDBVersion string `json:"mongodbVersion,omitempty"`
 	RepoPath       string `json:"repoPath,omitempty"`
 	URIHash        string `json:"uriHash,omitempty"`
+	// Cluster and Tags come from the --cluster profile.
+	Cluster string   `json:"cluster,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+	// Truncated marks a report built from partial results because the
+	// timeout expired mid-inspection; SkippedNamespaces lists what was missed.
+	// Interrupted marks a run cancelled by SIGINT or SIGTERM.
+	Truncated         bool     `json:"truncated,omitempty"`
+	Interrupted       bool     `json:"interrupted,omitempty"`
+	SkippedNamespaces []string `json:"skippedNamespaces,omitempty"`
+	// Quick marks an audit --quick report, built from index definitions
+	// without collection or index stats.
+	Quick bool `json:"quick,omitempty"`
+	// Lang is the --lang the report text was rendered in; empty means English.
+	Lang string `json:"lang,omitempty"`
 }
 
 // Report holds the structured audit output.
@@ -39,6 +55,10 @@ type Report struct {
 	Summary     Summary                       `json:"summary"`
 	Scan        *scanner.ScanResult           `json:"scan,omitempty"`
 	Collections []mongoinspect.CollectionInfo `json:"collections,omitempty"`
+	// TenantGroups aggregates collections matching configured name patterns.
+	TenantGroups []analyzer.TenantGroup `json:"tenantGroups,omitempty"`
+	// Performance records where the run spent its time.
+	Performance *Performance `json:"performance,omitempty"`
 }
 
 // Summary counts findings by severity.
@@ -70,14 +90,19 @@ func NewReport(findings []analyzer.Finding) Report {
 		Metadata: Metadata{
 			Timestamp: time.Now().UTC().Format(time.RFC3339),
 		},
-		Findings:    findings,
+		Findings:    analyzer.AnnotateRules(findings),
 		MaxSeverity: analyzer.MaxSeverity(findings),
 		Summary:     s,
 	}
 }
 
-// Write outputs the report in the given format.
+// Write outputs the report in the given format. Only text is translated:
+// machine-readable formats stay in English so baselines and tools that read
+// them compare the same messages whatever --lang was.
 func Write(w io.Writer, report *Report, format Format) error {
+	if format != FormatText {
+		report = english(report)
+	}
 	switch format {
 	case FormatJSON:
 		return writeJSON(w, report)
@@ -85,28 +110,79 @@ func Write(w io.Writer, report *Report, format Format) error {
 		return writeSARIF(w, report)
 	case FormatSpectreHub:
 		return writeSpectreHub(w, report)
+	case FormatNDJSON:
+		return writeNDJSON(w, report)
+	case FormatLSPJSON:
+		return writeLSP(w, report)
 	default:
-		return writeText(w, report)
+		return writeText(w, localize(report))
 	}
 }
 
-// WriteBaselineDiff outputs a baseline comparison summary.
-func WriteBaselineDiff(w io.Writer, diff []analyzer.BaselineFinding) {
-	var newCount, resolvedCount, unchangedCount int
+// english returns the report without a language, for formats that are
+// never translated.
+func english(report *Report) *Report {
+	if report.Metadata.Lang == "" {
+		return report
+	}
+	out := *report
+	out.Metadata.Lang = ""
+	return &out
+}
+
+// localize returns a copy of the report with finding messages translated
+// into Metadata.Lang. Rule IDs and finding types are left as they are.
+func localize(report *Report) *Report {
+	l := i18n.Lang(report.Metadata.Lang)
+	if l == "" || l == i18n.English || len(report.Findings) == 0 {
+		return report
+	}
+	out := *report
+	out.Findings = make([]analyzer.Finding, len(report.Findings))
+	for i := range report.Findings {
+		out.Findings[i] = report.Findings[i]
+		out.Findings[i].Message = l.Message(report.Findings[i].Message)
+	}
+	return &out
+}
+
+// WriteBaselineDiff outputs a baseline comparison summary in the given
+// language.
+func WriteBaselineDiff(w io.Writer, diff []analyzer.BaselineFinding, l i18n.Lang) {
+	var newCount, resolvedCount, changedCount, unchangedCount int
 	for _, f := range diff {
 		switch f.Status {
 		case analyzer.StatusNew:
 			newCount++
-			_, _ = fmt.Fprintf(w, "+ [%s] %s: %s\n", f.Status, f.Type, f.Message)
+			_, _ = fmt.Fprintf(w, "+ [%s] %s: %s\n", f.Status, f.Type, l.Message(f.Message))
 		case analyzer.StatusResolved:
 			resolvedCount++
-			_, _ = fmt.Fprintf(w, "- [%s] %s: %s\n", f.Status, f.Type, f.Message)
+			_, _ = fmt.Fprintf(w, "- [%s] %s: %s\n", f.Status, f.Type, l.Message(f.Message))
+		case analyzer.StatusChanged:
+			changedCount++
+			_, _ = fmt.Fprintf(w, "~ [%s] %s: %s%s\n", f.Status, f.Type, l.Message(f.Message), changeNote(&f, l))
 		default:
 			unchangedCount++
 		}
 	}
-	_, _ = fmt.Fprintf(w, "\nBaseline diff: %d new, %d resolved, %d unchanged\n\n",
-		newCount, resolvedCount, unchangedCount)
+	_, _ = fmt.Fprintf(w, "\n%s\n\n", l.Sprintf("Baseline diff: %d new, %d resolved, %d unchanged, %d changed",
+		newCount, resolvedCount, unchangedCount, changedCount))
+}
+
+// changeNote describes what a changed finding had in the baseline, e.g.
+// " (severity medium → high; was: <old message>)".
+func changeNote(f *analyzer.BaselineFinding, l i18n.Lang) string {
+	var parts []string
+	if f.PreviousSeverity != "" {
+		parts = append(parts, l.Sprintf("severity %s → %s", f.PreviousSeverity, f.Severity))
+	}
+	if f.PreviousMessage != "" {
+		parts = append(parts, l.Sprintf("was: %s", l.Message(f.PreviousMessage)))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, "; ") + ")"
 }
 
 func writeJSON(w io.Writer, report *Report) error {
@@ -116,59 +192,146 @@ func writeJSON(w io.Writer, report *Report) error {
 }
 
 func writeText(w io.Writer, report *Report) error {
-	// Print header when metadata is populated.
-	if report.Metadata.Command != "" {
-		header := "mongospectre"
-		if report.Metadata.Version != "" {
-			header += " " + report.Metadata.Version
-		}
-		header += " | " + report.Metadata.Command
-		if report.Metadata.MongoDBVersion != "" {
-			header += " | MongoDB " + report.Metadata.MongoDBVersion
-		}
-		if report.Metadata.Host != "" {
-			header += " | " + report.Metadata.Host
+	l := i18n.Lang(report.Metadata.Lang)
+	if err := writeTextHeader(w, report); err != nil {
+		return err
+	}
+
+	if report.Summary.Total == 0 {
+		if _, err := fmt.Fprintln(w, l.T("No findings.")); err != nil {
+			return err
 		}
-		if report.Metadata.Database != "" {
-			header += " | db=" + report.Metadata.Database
+		return writeTenantGroups(w, report.TenantGroups, l)
+	}
+
+	for _, f := range report.Findings {
+		label := l.T(severityLabel[f.Severity])
+		loc := f.Database + "." + f.Collection
+		if f.Index != "" {
+			loc += "." + f.Index
 		}
-		if _, err := fmt.Fprintln(w, header); err != nil {
+		if _, err := fmt.Fprintf(w, "[%s] %s: %s (%s)\n", label, f.Type, f.Message, loc); err != nil {
 			return err
 		}
-		if _, err := fmt.Fprintln(w); err != nil {
+		if err := writeBlame(w, &f, "  ", l); err != nil {
 			return err
 		}
 	}
 
-	if report.Summary.Total == 0 {
-		_, err := fmt.Fprintln(w, "No findings.")
-		return err
-	}
+	return writeTextSummary(w, report)
+}
 
-	severityLabel := map[analyzer.Severity]string{
-		analyzer.SeverityHigh:   "HIGH",
-		analyzer.SeverityMedium: "MEDIUM",
-		analyzer.SeverityLow:    "LOW",
-		analyzer.SeverityInfo:   "INFO",
+// writeBlame prints who last changed the code behind f, when --blame
+// found it.
+func writeBlame(w io.Writer, f *analyzer.Finding, indent string, l i18n.Lang) error {
+	b := f.Blame
+	if b == nil {
+		return nil
 	}
+	commit := b.Commit
+	if len(commit) > 8 {
+		commit = commit[:8]
+	}
+	_, err := fmt.Fprintf(w, "%s%s\n", indent, l.Sprintf("%s:%d last changed by %s <%s> in %s on %s",
+		f.File, f.Line, b.Author, b.Email, commit, b.Date.Format("2006-01-02")))
+	return err
+}
 
-	for _, f := range report.Findings {
-		label := severityLabel[f.Severity]
-		loc := f.Database + "." + f.Collection
-		if f.Index != "" {
-			loc += "." + f.Index
+// writeTextHeader prints the report header when metadata is populated.
+func writeTextHeader(w io.Writer, report *Report) error {
+	if report.Metadata.Command == "" {
+		return nil
+	}
+	header := "mongospectre"
+	if report.Metadata.Version != "" {
+		header += " " + report.Metadata.Version
+	}
+	header += " | " + report.Metadata.Command
+	if report.Metadata.MongoDBVersion != "" {
+		header += " | MongoDB " + report.Metadata.MongoDBVersion
+	}
+	if report.Metadata.Host != "" {
+		header += " | " + report.Metadata.Host
+	}
+	if report.Metadata.Database != "" {
+		header += " | db=" + report.Metadata.Database
+	}
+	if report.Metadata.Cluster != "" {
+		header += " | cluster=" + report.Metadata.Cluster
+	}
+	if len(report.Metadata.Tags) > 0 {
+		header += " [" + strings.Join(report.Metadata.Tags, ",") + "]"
+	}
+	if _, err := fmt.Fprintln(w, header); err != nil {
+		return err
+	}
+	if report.Metadata.Truncated || report.Metadata.Interrupted {
+		l := i18n.Lang(report.Metadata.Lang)
+		reason := "timeout reached"
+		if report.Metadata.Interrupted {
+			reason = "interrupted"
 		}
-		if _, err := fmt.Fprintf(w, "[%s] %s: %s (%s)\n", label, f.Type, f.Message, loc); err != nil {
+		if _, err := fmt.Fprintln(w, l.Sprintf("Partial results: %s, %d namespaces not inspected",
+			l.T(reason), len(report.Metadata.SkippedNamespaces))); err != nil {
 			return err
 		}
 	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
 
-	_, err := fmt.Fprintf(w, "\nSummary: %d findings (high=%d medium=%d low=%d info=%d)\n",
+func writeTextSummary(w io.Writer, report *Report) error {
+	l := i18n.Lang(report.Metadata.Lang)
+	if _, err := fmt.Fprintf(w, "\n%s\n", l.Sprintf("Summary: %d findings (high=%d medium=%d low=%d info=%d)",
 		report.Summary.Total, report.Summary.High, report.Summary.Medium,
-		report.Summary.Low, report.Summary.Info)
+		report.Summary.Low, report.Summary.Info)); err != nil {
+		return err
+	}
+	if err := writeRuleDocs(w, report.Findings, l); err != nil {
+		return err
+	}
+	return writeTenantGroups(w, report.TenantGroups, l)
+}
+
+// writeRuleDocs lists the rule ID and documentation URL of each finding
+// type in the report, in order of first appearance.
+func writeRuleDocs(w io.Writer, findings []analyzer.Finding, l i18n.Lang) error {
+	seen := make(map[analyzer.FindingType]bool)
+	var lines []string
+	for i := range findings {
+		t := findings[i].Type
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+		if rule, ok := analyzer.RuleFor(t); ok {
+			lines = append(lines, fmt.Sprintf("  %s %s: %s", rule.ID, t, rule.DocURL()))
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	_, err := fmt.Fprintf(w, "\n%s\n%s\n", l.T("Rules:"), strings.Join(lines, "\n"))
 	return err
 }
 
+// writeTenantGroups lists aggregate stats for pattern-named collections.
+func writeTenantGroups(w io.Writer, groups []analyzer.TenantGroup, l i18n.Lang) error {
+	if len(groups) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintf(w, "\n%s\n", l.T("Collection patterns:")); err != nil {
+		return err
+	}
+	for _, g := range groups {
+		if _, err := fmt.Fprintf(w, "  %s\n", l.Sprintf("%s.%s: %d collections, %d docs, data %s, indexes %s",
+			g.Database, g.Pattern, g.Collections, g.DocCount, humanBytes(g.Size), humanBytes(g.TotalIndexSize))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ExitCodeHint returns a human-readable explanation for the exit code.
 func ExitCodeHint(code int) string {
 	switch code {
@@ -176,7 +339,10 @@ func ExitCodeHint(code int) string {
 		return "Exit 1: medium-severity findings detected"
 	case 2:
 		return "Exit 2: high-severity findings detected"
-	default:
-		return ""
 	}
+	// 128 + the signal number, e.g. 130 for SIGINT and 143 for SIGTERM.
+	if code > 128 && code <= 128+64 {
+		return fmt.Sprintf("Exit %d: interrupted, report is partial", code)
+	}
+	return ""
 }
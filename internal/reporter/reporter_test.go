@@ -3,10 +3,15 @@ package reporter
 import (
 	"bytes"
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ppiankov/mongospectre/internal/analyzer"
+	"github.com/ppiankov/mongospectre/internal/gitinfo"
+	"github.com/ppiankov/mongospectre/internal/i18n"
 )
 
 var testFindings = []analyzer.Finding{
@@ -92,6 +97,22 @@ func TestWriteText_Empty(t *testing.T) {
 	}
 }
 
+func TestWriteText_TenantGroups(t *testing.T) {
+	r := NewReport(nil)
+	r.TenantGroups = []analyzer.TenantGroup{{
+		Database: "app", Pattern: "events_{tenant}", Collections: 1200,
+		DocCount: 5000, Size: 3 << 30, TotalIndexSize: 512 << 20,
+	}}
+	var buf bytes.Buffer
+	if err := Write(&buf, &r, FormatText); err != nil {
+		t.Fatal(err)
+	}
+	want := "app.events_{tenant}: 1200 collections, 5000 docs, data 3.0 GB, indexes 512.0 MB"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("output missing tenant group line %q:\n%s", want, buf.String())
+	}
+}
+
 func TestNewReport_AllSeverities(t *testing.T) {
 	findings := []analyzer.Finding{
 		{Severity: analyzer.SeverityHigh},
@@ -147,6 +168,64 @@ func TestWriteJSON(t *testing.T) {
 	}
 }
 
+func TestWriteNDJSON(t *testing.T) {
+	r := NewReport(testFindings)
+	var buf bytes.Buffer
+	if err := Write(&buf, &r, FormatNDJSON); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(testFindings) {
+		t.Fatalf("lines = %d, want %d", len(lines), len(testFindings))
+	}
+	for i, line := range lines {
+		var f analyzer.Finding
+		if err := json.Unmarshal([]byte(line), &f); err != nil {
+			t.Fatalf("line %d invalid JSON: %v", i, err)
+		}
+		if f.Type != testFindings[i].Type || f.RuleID == "" {
+			t.Errorf("line %d = %+v", i, f)
+		}
+	}
+}
+
+func TestRuleReferences(t *testing.T) {
+	r := NewReport(testFindings)
+	if r.Findings[1].RuleID != "MS004" || !strings.HasSuffix(r.Findings[1].DocURL, "#ms004") {
+		t.Errorf("finding not annotated: %+v", r.Findings[1])
+	}
+
+	var text bytes.Buffer
+	if err := Write(&text, &r, FormatText); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text.String(), "Rules:\n  MS002 UNUSED_INDEX: "+analyzer.RuleDocsURL+"#ms002\n  MS004 MISSING_INDEX:") {
+		t.Errorf("text missing rule docs:\n%s", text.String())
+	}
+
+	var sarif bytes.Buffer
+	if err := Write(&sarif, &r, FormatSARIF); err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(sarif.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	rules := log.Runs[0].Tool.Driver.Rules
+	if len(rules) != 2 || rules[0].ID != "UNUSED_INDEX" || rules[0].HelpURI != analyzer.RuleDocsURL+"#ms002" || rules[0].Properties["ruleId"] != "MS002" {
+		t.Errorf("SARIF rules = %+v", rules)
+	}
+
+	var hub bytes.Buffer
+	if err := Write(&hub, &r, FormatSpectreHub); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(hub.String(), `"rule_id": "MS004"`) {
+		t.Errorf("spectrehub missing rule_id:\n%s", hub.String())
+	}
+}
+
 func TestWriteSARIF(t *testing.T) {
 	r := NewReport(testFindings)
 	r.Metadata.Version = "0.2.0"
@@ -206,6 +285,95 @@ func TestWriteSARIF_Empty(t *testing.T) {
 	}
 }
 
+func TestWriteLSP(t *testing.T) {
+	repo := t.TempDir()
+	r := NewReport([]analyzer.Finding{
+		{Type: analyzer.FindingUnindexedQuery, Severity: analyzer.SeverityMedium, Database: "app", Collection: "users", Message: "field \"status\" is queried in code but has no index", File: "src/users.js", Line: 12},
+		{Type: analyzer.FindingMissingCollection, Severity: analyzer.SeverityHigh, Database: "app", Collection: "ghosts", Message: "collection is referenced in code but does not exist", File: "src/users.js", Line: 3},
+		{Type: analyzer.FindingUnusedIndex, Severity: analyzer.SeverityMedium, Database: "app", Collection: "users", Index: "status_1", Message: "index has zero operations"},
+	})
+	r.Metadata.RepoPath = repo
+
+	var buf bytes.Buffer
+	if err := Write(&buf, &r, FormatLSPJSON); err != nil {
+		t.Fatal(err)
+	}
+	var files []lspPublishDiagnostics
+	if err := json.Unmarshal(buf.Bytes(), &files); err != nil {
+		t.Fatalf("invalid LSP JSON: %v", err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("files = %+v, want one (findings without a location are left out)", files)
+	}
+	if want := "file://" + filepath.ToSlash(filepath.Join(repo, "src", "users.js")); files[0].URI != want {
+		t.Errorf("uri = %s, want %s", files[0].URI, want)
+	}
+	diags := files[0].Diagnostics
+	if len(diags) != 2 {
+		t.Fatalf("diagnostics = %+v", diags)
+	}
+	d := diags[0]
+	if d.Range.Start.Line != 11 || d.Range.End.Line != 12 || d.Severity != lspSeverityWarning || d.Code != "MS017" || d.Source != "mongospectre" {
+		t.Errorf("UNINDEXED_QUERY diagnostic = %+v", d)
+	}
+	if d.CodeDescription == nil || d.CodeDescription.Href != analyzer.RuleDocsURL+"#ms017" {
+		t.Errorf("codeDescription = %+v", d.CodeDescription)
+	}
+	if d.Data.Type != analyzer.FindingUnindexedQuery || d.Data.Collection != "users" {
+		t.Errorf("data = %+v", d.Data)
+	}
+	if diags[1].Severity != lspSeverityError || diags[1].Range.Start.Line != 2 {
+		t.Errorf("MISSING_COLLECTION diagnostic = %+v", diags[1])
+	}
+
+	empty := NewReport(nil)
+	buf.Reset()
+	if err := Write(&buf, &empty, FormatLSPJSON); err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("empty report = %q, want []", buf.String())
+	}
+}
+
+func TestWriteCodeLocationAndBlame(t *testing.T) {
+	r := NewReport([]analyzer.Finding{{
+		Type:     analyzer.FindingHardcodedURI,
+		Severity: analyzer.SeverityHigh,
+		Message:  "connection string with password committed to source",
+		File:     "config/db.js",
+		Line:     12,
+		Blame: &gitinfo.Blame{
+			Commit: "3f2a9c41d0e5b6a7c8d9e0f1a2b3c4d5e6f7a8b9",
+			Author: "Dana",
+			Email:  "dana@example.com",
+			Date:   time.Date(2025, 11, 3, 9, 0, 0, 0, time.UTC),
+		},
+	}})
+
+	var text bytes.Buffer
+	if err := Write(&text, &r, FormatText); err != nil {
+		t.Fatal(err)
+	}
+	want := "  config/db.js:12 last changed by Dana <dana@example.com> in 3f2a9c41 on 2025-11-03\n"
+	if !strings.Contains(text.String(), want) {
+		t.Errorf("text output missing blame line %q:\n%s", want, text.String())
+	}
+
+	var sarif bytes.Buffer
+	if err := Write(&sarif, &r, FormatSARIF); err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(sarif.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF JSON: %v", err)
+	}
+	phys := log.Runs[0].Results[0].Locations[0].PhysicalLocation
+	if phys == nil || phys.ArtifactLocation.URI != "config/db.js" || phys.Region == nil || phys.Region.StartLine != 12 {
+		t.Errorf("physical location = %+v", phys)
+	}
+}
+
 func TestWriteBaselineDiff(t *testing.T) {
 	diff := []analyzer.BaselineFinding{
 		{Finding: analyzer.Finding{Type: analyzer.FindingMissingIndex, Severity: analyzer.SeverityHigh, Message: "new one"}, Status: analyzer.StatusNew},
@@ -213,7 +381,7 @@ func TestWriteBaselineDiff(t *testing.T) {
 		{Finding: analyzer.Finding{Type: analyzer.FindingMissingTTL, Severity: analyzer.SeverityLow, Message: "same"}, Status: analyzer.StatusUnchanged},
 	}
 	var buf bytes.Buffer
-	WriteBaselineDiff(&buf, diff)
+	WriteBaselineDiff(&buf, diff, i18n.English)
 	out := buf.String()
 	if !strings.Contains(out, "+ [new]") {
 		t.Error("missing new marker")
@@ -226,6 +394,24 @@ func TestWriteBaselineDiff(t *testing.T) {
 	}
 }
 
+func TestWriteBaselineDiffChanged(t *testing.T) {
+	diff := []analyzer.BaselineFinding{{
+		Finding:          analyzer.Finding{Type: analyzer.FindingMissingIndex, Severity: analyzer.SeverityHigh, Message: "seen 300 times"},
+		Status:           analyzer.StatusChanged,
+		PreviousSeverity: analyzer.SeverityMedium,
+		PreviousMessage:  "seen 100 times",
+	}}
+	var buf bytes.Buffer
+	WriteBaselineDiff(&buf, diff, i18n.English)
+	out := buf.String()
+	if !strings.Contains(out, "~ [changed] MISSING_INDEX: seen 300 times (severity medium → high; was: seen 100 times)") {
+		t.Errorf("missing changed line:\n%s", out)
+	}
+	if !strings.Contains(out, "0 unchanged, 1 changed") {
+		t.Errorf("missing changed count:\n%s", out)
+	}
+}
+
 func TestWriteSpectreHub(t *testing.T) {
 	r := NewReport(testFindings)
 	r.Metadata.Version = "0.2.0"
@@ -340,6 +526,9 @@ func TestExitCodeHint(t *testing.T) {
 	if h := ExitCodeHint(2); !strings.Contains(h, "high") {
 		t.Errorf("hint for 2 should mention high, got %q", h)
 	}
+	if h := ExitCodeHint(143); h != "Exit 143: interrupted, report is partial" {
+		t.Errorf("hint for 143 = %q", h)
+	}
 }
 
 func TestSeverityToSARIFLevel(t *testing.T) {
@@ -359,3 +548,226 @@ func TestSeverityToSARIFLevel(t *testing.T) {
 		}
 	}
 }
+
+func TestSaveBaselineRotates(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "baselines")
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	var saved []string
+	for i := 0; i < 4; i++ {
+		report := NewReport(testFindings)
+		report.Metadata.Timestamp = start.Add(time.Duration(i) * time.Hour).Format(time.RFC3339)
+		path, removed, err := SaveBaseline(dir, &report, 3)
+		if err != nil {
+			t.Fatalf("SaveBaseline: %v", err)
+		}
+		saved = append(saved, path)
+		if i < 3 && len(removed) != 0 {
+			t.Fatalf("run %d: unexpected rotation %v", i, removed)
+		}
+		if i == 3 && (len(removed) != 1 || removed[0] != saved[0]) {
+			t.Fatalf("expected oldest baseline rotated out, got %v", removed)
+		}
+	}
+
+	paths, err := analyzer.ListBaselines(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 3 || paths[2] != saved[3] {
+		t.Fatalf("unexpected retained baselines: %v", paths)
+	}
+
+	findings, err := analyzer.LoadBaseline(saved[3])
+	if err != nil {
+		t.Fatalf("saved baseline not loadable: %v", err)
+	}
+	if len(findings) != len(testFindings) {
+		t.Fatalf("loaded %d findings, want %d", len(findings), len(testFindings))
+	}
+
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if strings.HasSuffix(e.Name(), ".tmp") {
+			t.Fatalf("temp file left behind: %s", e.Name())
+		}
+	}
+}
+
+func TestWriteTrendText(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	report := TrendReport{
+		Trend: analyzer.Trend{
+			From:      start,
+			To:        start.Add(48 * time.Hour),
+			Snapshots: 3,
+			Collections: []analyzer.CollectionTrend{
+				{
+					Database: "app", Collection: "events", Accelerating: true,
+					GrowthPerDay: 25 << 20, IndexGrowthPerDay: 1 << 20,
+					Points: []analyzer.TrendPoint{
+						{Timestamp: start, Size: 100 << 20},
+						{Timestamp: start.Add(24 * time.Hour), Size: 110 << 20},
+						{Timestamp: start.Add(48 * time.Hour), Size: 150 << 20},
+					},
+				},
+				{
+					Database: "app", Collection: "users",
+					Points: []analyzer.TrendPoint{{Timestamp: start, Size: 512}},
+				},
+			},
+			Findings: []analyzer.FindingCountPoint{
+				{Timestamp: start, Total: 2, High: 1, Medium: 1},
+				{Timestamp: start.Add(48 * time.Hour), Total: 4, High: 1, Medium: 3},
+			},
+		},
+		Findings: []analyzer.Finding{{
+			Type: analyzer.FindingAcceleratingGrowth, Severity: analyzer.SeverityMedium,
+			Database: "app", Collection: "events", Message: "growth rate accelerated",
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTrend(&buf, &report, FormatText, 1); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"Trend: 3 baselines from 2026-01-01 00:00 to 2026-01-03 00:00",
+		"total=4",
+		"app.events  ▁▂█  100.0 MB → 150.0 MB  data +25.0 MB/day  index +1.0 MB/day  ACCELERATING",
+		"1 more collections",
+		"[MEDIUM] ACCELERATING_GROWTH",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("trend output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteTrendJSON(t *testing.T) {
+	report := TrendReport{Metadata: Metadata{Command: "trend"}, Trend: analyzer.Trend{Snapshots: 0}}
+	var buf bytes.Buffer
+	if err := WriteTrend(&buf, &report, FormatJSON, 0); err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if _, ok := decoded["trend"]; !ok {
+		t.Fatalf("missing trend key: %s", buf.String())
+	}
+}
+
+func TestWriteText_HeaderWithClusterTags(t *testing.T) {
+	r := NewReport(nil)
+	r.Metadata.Command = "audit"
+	r.Metadata.Cluster = "prod-eu"
+	r.Metadata.Tags = []string{"prod", "eu"}
+	var buf bytes.Buffer
+	if err := Write(&buf, &r, FormatText); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "cluster=prod-eu [prod,eu]") {
+		t.Errorf("missing cluster in header: %q", buf.String())
+	}
+}
+
+func TestWriteText_HeaderTruncated(t *testing.T) {
+	r := NewReport(nil)
+	r.Metadata.Command = "audit"
+	r.Metadata.Truncated = true
+	r.Metadata.SkippedNamespaces = []string{"app.events", "logs"}
+	var buf bytes.Buffer
+	if err := Write(&buf, &r, FormatText); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Partial results: timeout reached, 2 namespaces not inspected") {
+		t.Errorf("missing truncation notice: %q", buf.String())
+	}
+}
+
+func TestWriteText_HeaderInterrupted(t *testing.T) {
+	r := NewReport(nil)
+	r.Metadata.Command = "check"
+	r.Metadata.Interrupted = true
+	var buf bytes.Buffer
+	if err := Write(&buf, &r, FormatText); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "Partial results: interrupted, 0 namespaces not inspected") {
+		t.Errorf("missing interruption notice: %q", buf.String())
+	}
+}
+
+func TestWriteLocalized(t *testing.T) {
+	findings := []analyzer.Finding{
+		{Type: analyzer.FindingMissingIndex, Severity: analyzer.SeverityHigh, Database: "app", Collection: "orders",
+			Message: "collection has 50000 documents but only the _id index"},
+		{Type: analyzer.FindingUnusedIndex, Severity: analyzer.SeverityMedium, Database: "app", Collection: "orders", Index: "x_1",
+			Message: "not in the catalog"},
+	}
+	r := NewReport(findings)
+	r.Metadata.Lang = "es"
+
+	var buf bytes.Buffer
+	if err := Write(&buf, &r, FormatText); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "[ALTO] MISSING_INDEX: la colección tiene 50000 documentos pero solo el índice _id (app.orders)") {
+		t.Errorf("missing translated finding:\n%s", out)
+	}
+	if !strings.Contains(out, "[MEDIO] UNUSED_INDEX: not in the catalog") {
+		t.Errorf("untranslated message should fall back to English:\n%s", out)
+	}
+	if !strings.Contains(out, "Reglas:\n  MS004 MISSING_INDEX") {
+		t.Errorf("rule IDs should be kept:\n%s", out)
+	}
+
+	buf.Reset()
+	if err := Write(&buf, &r, FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	// JSON stays in English so baselines compare across languages.
+	if decoded.Metadata.Lang != "" || decoded.Findings[0].RuleID != "MS004" || decoded.Findings[0].Type != analyzer.FindingMissingIndex {
+		t.Errorf("unexpected JSON metadata or rule: %+v", decoded.Findings[0])
+	}
+	if decoded.Findings[0].Message != findings[0].Message {
+		t.Errorf("JSON message translated: %q", decoded.Findings[0].Message)
+	}
+
+	buf.Reset()
+	if err := Write(&buf, &r, FormatSARIF); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "la colección") {
+		t.Error("SARIF message translated")
+	}
+	if r.Findings[0].Message != findings[0].Message {
+		t.Error("Write must not modify the report")
+	}
+}
+
+func TestWritePerformance(t *testing.T) {
+	p := &Performance{
+		TotalMS:   1520.5,
+		Phases:    []Timing{NewTiming("connect", 20*time.Millisecond), NewTiming("inspect", 1500*time.Millisecond)},
+		Detectors: []Timing{NewTiming("missing-index", 250*time.Microsecond)},
+	}
+	var buf bytes.Buffer
+	WritePerformance(&buf, p)
+	want := "Timings (total 1.5205s):\n" +
+		"  connect        20ms\n" +
+		"  inspect        1.5s\n" +
+		"Detectors:\n" +
+		"  missing-index  250µs\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
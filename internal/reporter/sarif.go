@@ -3,6 +3,7 @@ package reporter
 import (
 	"encoding/json"
 	"io"
+	"path/filepath"
 
 	"github.com/ppiankov/mongospectre/internal/analyzer"
 )
@@ -37,7 +38,9 @@ type sarifDriver struct {
 type sarifReportingDescriptor struct {
 	ID               string             `json:"id"`
 	ShortDescription sarifMessage       `json:"shortDescription"`
+	HelpURI          string             `json:"helpUri,omitempty"`
 	DefaultConfig    sarifDefaultConfig `json:"defaultConfiguration,omitempty"`
+	Properties       map[string]string  `json:"properties,omitempty"`
 }
 
 type sarifDefaultConfig struct {
@@ -62,6 +65,11 @@ type sarifLocation struct {
 
 type sarifPhysicalLocation struct {
 	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
 }
 
 type sarifArtifactLocation struct {
@@ -73,47 +81,33 @@ type sarifLogicalLocation struct {
 	Kind               string `json:"kind,omitempty"`
 }
 
-// sarifRules defines the SARIF rule descriptors for each finding type.
-var sarifRules = map[analyzer.FindingType]sarifReportingDescriptor{
-	analyzer.FindingUnusedCollection:     {ID: "UNUSED_COLLECTION", ShortDescription: sarifMessage{Text: "Collection exists but is unused"}, DefaultConfig: sarifDefaultConfig{Level: "warning"}},
-	analyzer.FindingUnusedIndex:          {ID: "UNUSED_INDEX", ShortDescription: sarifMessage{Text: "Index has never been used"}, DefaultConfig: sarifDefaultConfig{Level: "warning"}},
-	analyzer.FindingMissingIndex:         {ID: "MISSING_INDEX", ShortDescription: sarifMessage{Text: "Collection may need additional indexes"}, DefaultConfig: sarifDefaultConfig{Level: "error"}},
-	analyzer.FindingDuplicateIndex:       {ID: "DUPLICATE_INDEX", ShortDescription: sarifMessage{Text: "Index is a prefix duplicate of another"}, DefaultConfig: sarifDefaultConfig{Level: "warning"}},
-	analyzer.FindingOversizedCollection:  {ID: "OVERSIZED_COLLECTION", ShortDescription: sarifMessage{Text: "Collection has a very high document count"}, DefaultConfig: sarifDefaultConfig{Level: "warning"}},
-	analyzer.FindingMissingTTL:           {ID: "MISSING_TTL", ShortDescription: sarifMessage{Text: "Timestamp collection without TTL index"}, DefaultConfig: sarifDefaultConfig{Level: "note"}},
-	analyzer.FindingUnshardedLarge:       {ID: "UNSHARDED_LARGE", ShortDescription: sarifMessage{Text: "Large collection without sharding"}, DefaultConfig: sarifDefaultConfig{Level: "warning"}},
-	analyzer.FindingMonotonicShardKey:    {ID: "MONOTONIC_SHARD_KEY", ShortDescription: sarifMessage{Text: "Shard key is monotonic and may create hot shards"}, DefaultConfig: sarifDefaultConfig{Level: "warning"}},
-	analyzer.FindingUnbalancedChunks:     {ID: "UNBALANCED_CHUNKS", ShortDescription: sarifMessage{Text: "Chunk distribution is heavily skewed"}, DefaultConfig: sarifDefaultConfig{Level: "error"}},
-	analyzer.FindingJumboChunks:          {ID: "JUMBO_CHUNKS", ShortDescription: sarifMessage{Text: "Jumbo chunks cannot be split automatically"}, DefaultConfig: sarifDefaultConfig{Level: "error"}},
-	analyzer.FindingBalancerDisabled:     {ID: "BALANCER_DISABLED", ShortDescription: sarifMessage{Text: "Chunk balancer is disabled"}, DefaultConfig: sarifDefaultConfig{Level: "warning"}},
-	analyzer.FindingMissingCollection:    {ID: "MISSING_COLLECTION", ShortDescription: sarifMessage{Text: "Collection referenced in code does not exist"}, DefaultConfig: sarifDefaultConfig{Level: "error"}},
-	analyzer.FindingOrphanedIndex:        {ID: "ORPHANED_INDEX", ShortDescription: sarifMessage{Text: "Index on unreferenced collection with zero usage"}, DefaultConfig: sarifDefaultConfig{Level: "note"}},
-	analyzer.FindingUnindexedQuery:       {ID: "UNINDEXED_QUERY", ShortDescription: sarifMessage{Text: "Queried field has no covering index"}, DefaultConfig: sarifDefaultConfig{Level: "warning"}},
-	analyzer.FindingSuggestIndex:         {ID: "SUGGEST_INDEX", ShortDescription: sarifMessage{Text: "Consider adding an index for queried field"}, DefaultConfig: sarifDefaultConfig{Level: "none"}},
-	analyzer.FindingSlowQuerySource:      {ID: "SLOW_QUERY_SOURCE", ShortDescription: sarifMessage{Text: "Code location correlates with slow profiler queries"}, DefaultConfig: sarifDefaultConfig{Level: "warning"}},
-	analyzer.FindingCollectionScanSource: {ID: "COLLECTION_SCAN_SOURCE", ShortDescription: sarifMessage{Text: "Code location correlates with profiler COLLSCAN queries"}, DefaultConfig: sarifDefaultConfig{Level: "error"}},
-	analyzer.FindingFrequentSlowQuery:    {ID: "FREQUENT_SLOW_QUERY", ShortDescription: sarifMessage{Text: "Repeated slow query shape in profiler"}, DefaultConfig: sarifDefaultConfig{Level: "warning"}},
-	analyzer.FindingValidatorMissing:     {ID: "VALIDATOR_MISSING", ShortDescription: sarifMessage{Text: "Collection writes are not constrained by a validator"}, DefaultConfig: sarifDefaultConfig{Level: "warning"}},
-	analyzer.FindingValidatorStale:       {ID: "VALIDATOR_STALE", ShortDescription: sarifMessage{Text: "Validator field type does not match code write type"}, DefaultConfig: sarifDefaultConfig{Level: "warning"}},
-	analyzer.FindingValidatorStrictRisk:  {ID: "VALIDATOR_STRICT_RISK", ShortDescription: sarifMessage{Text: "Strict/error validator can reject writes on schema drift"}, DefaultConfig: sarifDefaultConfig{Level: "note"}},
-	analyzer.FindingValidatorWarnOnly:    {ID: "VALIDATOR_WARN_ONLY", ShortDescription: sarifMessage{Text: "Validator is warn-only and does not reject invalid writes"}, DefaultConfig: sarifDefaultConfig{Level: "none"}},
-	analyzer.FindingFieldNotInValidator:  {ID: "FIELD_NOT_IN_VALIDATOR", ShortDescription: sarifMessage{Text: "Code writes a field missing from validator properties"}, DefaultConfig: sarifDefaultConfig{Level: "warning"}},
-	analyzer.FindingAtlasIndexSuggestion: {ID: "ATLAS_INDEX_SUGGESTION", ShortDescription: sarifMessage{Text: "Atlas Performance Advisor recommends an index"}, DefaultConfig: sarifDefaultConfig{Level: "note"}},
-	analyzer.FindingAtlasAlertActive:     {ID: "ATLAS_ALERT_ACTIVE", ShortDescription: sarifMessage{Text: "Atlas project has active alerts"}, DefaultConfig: sarifDefaultConfig{Level: "warning"}},
-	analyzer.FindingAtlasTierMismatch:    {ID: "ATLAS_TIER_MISMATCH", ShortDescription: sarifMessage{Text: "Atlas cluster tier may be undersized for current data footprint"}, DefaultConfig: sarifDefaultConfig{Level: "error"}},
-	analyzer.FindingAtlasVersionBehind:   {ID: "ATLAS_VERSION_BEHIND", ShortDescription: sarifMessage{Text: "Atlas cluster version is behind available project LTS versions"}, DefaultConfig: sarifDefaultConfig{Level: "warning"}},
-	analyzer.FindingOK:                   {ID: "OK", ShortDescription: sarifMessage{Text: "Collection exists and is referenced"}, DefaultConfig: sarifDefaultConfig{Level: "none"}},
+// sarifRule builds the SARIF rule descriptor for a finding type from the
+// rule catalog. The SARIF rule ID stays the finding type so code scanning
+// alerts keep their history; the catalog ID is in the properties.
+func sarifRule(t analyzer.FindingType) (sarifReportingDescriptor, bool) {
+	rule, ok := analyzer.RuleFor(t)
+	if !ok {
+		return sarifReportingDescriptor{}, false
+	}
+	return sarifReportingDescriptor{
+		ID:               string(t),
+		ShortDescription: sarifMessage{Text: rule.Description},
+		HelpURI:          rule.DocURL(),
+		DefaultConfig:    sarifDefaultConfig{Level: severityToSARIFLevel(rule.Severity)},
+		Properties:       map[string]string{"ruleId": rule.ID},
+	}, true
 }
 
 func writeSARIF(w io.Writer, report *Report) error {
 	// Collect unique rules used in findings.
 	usedRules := make(map[analyzer.FindingType]bool)
+	var rules []sarifReportingDescriptor
 	for _, f := range report.Findings {
+		if usedRules[f.Type] {
+			continue
+		}
 		usedRules[f.Type] = true
-	}
-	var rules []sarifReportingDescriptor
-	for ft := range usedRules {
-		if r, ok := sarifRules[ft]; ok {
+		if r, ok := sarifRule(f.Type); ok {
 			rules = append(rules, r)
 		}
 	}
@@ -134,6 +128,14 @@ func writeSARIF(w io.Writer, report *Report) error {
 				Kind:               "object",
 			}},
 		}
+		if f.File != "" {
+			loc.PhysicalLocation = &sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(f.File)},
+			}
+			if f.Line > 0 {
+				loc.PhysicalLocation.Region = &sarifRegion{StartLine: f.Line}
+			}
+		}
 		r.Locations = []sarifLocation{loc}
 		results = append(results, r)
 	}
@@ -7,6 +7,8 @@ import (
 	"io"
 	"net/url"
 	"time"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
 )
 
 // FormatSpectreHub is the SpectreHub envelope output format.
@@ -98,12 +100,16 @@ func writeSpectreHub(w io.Writer, report *Report) error {
 		if f.Index != "" {
 			loc += "." + f.Index
 		}
-		envelope.Findings = append(envelope.Findings, SpectreHubFinding{
+		hf := SpectreHubFinding{
 			ID:       string(f.Type),
 			Severity: string(f.Severity),
 			Location: loc,
 			Message:  f.Message,
-		})
+		}
+		if rule, ok := analyzer.RuleFor(f.Type); ok {
+			hf.Metadata = map[string]any{"rule_id": rule.ID, "doc_url": rule.DocURL()}
+		}
+		envelope.Findings = append(envelope.Findings, hf)
 	}
 	// Ensure findings array is never null in JSON.
 	if envelope.Findings == nil {
@@ -0,0 +1,135 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+)
+
+// TrendReport is the output of the trend command.
+type TrendReport struct {
+	Metadata    Metadata           `json:"metadata"`
+	Trend       analyzer.Trend     `json:"trend"`
+	Findings    []analyzer.Finding `json:"findings"`
+	MaxSeverity analyzer.Severity  `json:"maxSeverity"`
+}
+
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// WriteTrend renders a trend report as JSON or as text with ASCII sparklines.
+// In text format only the top collections by growth rate are listed (top <= 0
+// lists all).
+func WriteTrend(w io.Writer, report *TrendReport, format Format, top int) error {
+	if format == FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+
+	t := &report.Trend
+	if t.Snapshots == 0 {
+		_, err := fmt.Fprintln(w, "No timestamped baselines found.")
+		return err
+	}
+	_, _ = fmt.Fprintf(w, "Trend: %d baselines from %s to %s\n\n",
+		t.Snapshots, t.From.UTC().Format("2006-01-02 15:04"), t.To.UTC().Format("2006-01-02 15:04"))
+
+	_, _ = fmt.Fprintln(w, "Findings over time")
+	maxTotal := 0
+	for _, p := range t.Findings {
+		maxTotal = max(maxTotal, p.Total)
+	}
+	for _, p := range t.Findings {
+		bar := ""
+		if maxTotal > 0 {
+			bar = strings.Repeat("█", (p.Total*30+maxTotal-1)/maxTotal)
+		}
+		_, _ = fmt.Fprintf(w, "  %s  total=%-4d high=%-3d medium=%-3d low=%-3d info=%-3d %s\n",
+			p.Timestamp.UTC().Format("2006-01-02 15:04"), p.Total, p.High, p.Medium, p.Low, p.Info, bar)
+	}
+
+	_, _ = fmt.Fprintln(w, "\nCollection growth (data size)")
+	collections := t.Collections
+	if top > 0 && len(collections) > top {
+		collections = collections[:top]
+	}
+	width := 0
+	for i := range collections {
+		width = max(width, len(collections[i].Database)+1+len(collections[i].Collection))
+	}
+	for i := range collections {
+		ct := &collections[i]
+		sizes := make([]int64, len(ct.Points))
+		for j, p := range ct.Points {
+			sizes[j] = p.Size
+		}
+		first, last := ct.Points[0], ct.Points[len(ct.Points)-1]
+		line := fmt.Sprintf("  %-*s  %s  %s → %s  data %s/day  index %s/day",
+			width, ct.Database+"."+ct.Collection, sparkline(sizes),
+			humanBytes(first.Size), humanBytes(last.Size),
+			signedBytes(ct.GrowthPerDay), signedBytes(ct.IndexGrowthPerDay))
+		if ct.Accelerating {
+			line += "  ACCELERATING"
+		}
+		_, _ = fmt.Fprintln(w, line)
+	}
+	if len(collections) < len(t.Collections) {
+		_, _ = fmt.Fprintf(w, "  ... %d more collections (use --top 0 to list all)\n", len(t.Collections)-len(collections))
+	}
+
+	if len(report.Findings) > 0 {
+		_, _ = fmt.Fprintln(w)
+		for _, f := range report.Findings {
+			line := fmt.Sprintf("[%s] %s: %s", strings.ToUpper(string(f.Severity)), f.Type, f.Message)
+			if f.Database != "" {
+				line += fmt.Sprintf(" (%s.%s)", f.Database, f.Collection)
+			}
+			_, _ = fmt.Fprintln(w, line)
+		}
+	}
+	return nil
+}
+
+// sparkline scales values between their min and max onto block characters.
+func sparkline(values []int64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		lo = min(lo, v)
+		hi = max(hi, v)
+	}
+	var b strings.Builder
+	for _, v := range values {
+		idx := 0
+		if hi > lo {
+			idx = int((v - lo) * int64(len(sparkBars)-1) / (hi - lo))
+		}
+		b.WriteRune(sparkBars[idx])
+	}
+	return b.String()
+}
+
+func humanBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit && exp < 4; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTP"[exp])
+}
+
+func signedBytes(v float64) string {
+	if v < 0 {
+		return "-" + humanBytes(int64(-v))
+	}
+	return "+" + humanBytes(int64(v))
+}
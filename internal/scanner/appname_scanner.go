@@ -0,0 +1,23 @@
+package scanner
+
+import "regexp"
+
+// appNameRe matches an appName set in code: appName: "billing",
+// appname="billing", SetAppName("billing"), applicationName("billing")
+// (Java) and ApplicationName = "billing" (C#).
+var appNameRe = regexp.MustCompile(`(?i)app(?:lication)?_?name["']?\s*[:=(]\s*["'\x60]([\w.@:/-]+)["'\x60]`)
+
+// appNameURIRe matches the appName option of a connection string.
+var appNameURIRe = regexp.MustCompile(`(?i)mongodb(?:\+srv)?://[^\s"'\x60]*[?&]appname=([\w.@:-]+)`)
+
+// ScanLineAppNames returns the client appNames set on a line.
+func ScanLineAppNames(line string) []string {
+	var names []string
+	for _, m := range appNameURIRe.FindAllStringSubmatch(line, -1) {
+		names = append(names, m[1])
+	}
+	for _, m := range appNameRe.FindAllStringSubmatch(line, -1) {
+		names = append(names, m[1])
+	}
+	return names
+}
@@ -0,0 +1,26 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScanLineAppNames(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{`const client = new MongoClient(uri, { appName: "checkout-api" });`, []string{"checkout-api"}},
+		{`client = MongoClient(uri, appname="billing-worker")`, []string{"billing-worker"}},
+		{`opts := options.Client().ApplyURI(uri).SetAppName("inventory")`, []string{"inventory"}},
+		{`MongoClientSettings.builder().applicationName("ledger").build();`, []string{"ledger"}},
+		{`settings.ApplicationName = "reports";`, []string{"reports"}},
+		{`MONGO_URL = "mongodb://db1:27017/shop?replicaSet=rs0&appName=shop-web"`, []string{"shop-web"}},
+		{`const title = "My App";`, nil},
+	}
+	for _, tt := range tests {
+		if got := ScanLineAppNames(tt.line); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ScanLineAppNames(%q) = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package scanner
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// driverManifestRes match a MongoDB driver dependency in the manifests
+// the scanner reads, by file name. The last group is the version.
+var driverManifestRes = map[string]*regexp.Regexp{
+	// go.mongodb.org/mongo-driver v1.17.1, inside a require block or not.
+	"go.mod": regexp.MustCompile(`^\s*(?:require\s+)?(go\.mongodb\.org/mongo-driver(?:/v\d+)?)\s+(v\d[\w.+-]*)`),
+	// "mongodb": "^6.3.0"
+	"package.json": regexp.MustCompile(`"(mongodb)"\s*:\s*"[\^~>=v\s]*(\d[\w.+-]*)"`),
+	// pymongo==4.6.1, pymongo[srv]>=4.2,<5
+	"requirements.txt": regexp.MustCompile(`(?i)^\s*(pymongo)(?:\[[^\]]*\])?\s*(?:===?|>=|~=)\s*(\d[\w.]*)`),
+}
+
+// scanDriverManifest returns the MongoDB driver dependencies declared in a
+// go.mod, package.json or requirements.txt. Version ranges are recorded by
+// their lower bound.
+func scanDriverManifest(path, repoPath string) ([]DriverRef, error) {
+	re := driverManifestRes[filepath.Base(path)]
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	relPath, _ := filepath.Rel(repoPath, path)
+	var refs []DriverRef
+	sc := bufio.NewScanner(f)
+	for lineNum := 1; sc.Scan(); lineNum++ {
+		m := re.FindStringSubmatch(sc.Text())
+		if m == nil {
+			continue
+		}
+		refs = append(refs, DriverRef{
+			Package: strings.ToLower(m[1]),
+			Version: m[2],
+			File:    relPath,
+			Line:    lineNum,
+		})
+	}
+	return refs, sc.Err()
+}
@@ -0,0 +1,33 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScanDriverManifests(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "go.mod", "module example.com/app\n\ngo 1.22\n\nrequire (\n\tgithub.com/spf13/cobra v1.8.0\n\tgo.mongodb.org/mongo-driver v1.11.4\n)\n")
+	writeFile(t, dir, "worker/go.mod", "module example.com/worker\n\nrequire go.mongodb.org/mongo-driver/v2 v2.0.0 // indirect\n")
+	writeFile(t, dir, "web/package.json", "{\n  \"dependencies\": {\n    \"express\": \"^4.18.0\",\n    \"mongodb\": \"^5.9.2\"\n  }\n}\n")
+	writeFile(t, dir, "jobs/requirements.txt", "requests==2.31.0\nPyMongo[srv]>=3.12,<4\nmotor==3.3.2\n")
+	writeFile(t, dir, "tools/requirements.txt", "pymongo\n")
+	writeFile(t, dir, "node_modules/mongodb/package.json", "{\n  \"name\": \"mongodb\",\n  \"version\": \"6.8.0\"\n}\n")
+
+	result, err := Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DriverRef{
+		{Package: "go.mongodb.org/mongo-driver", Version: "v1.11.4", File: "go.mod", Line: 7},
+		{Package: "pymongo", Version: "3.12", File: "jobs/requirements.txt", Line: 2},
+		{Package: "mongodb", Version: "5.9.2", File: "web/package.json", Line: 4},
+		{Package: "go.mongodb.org/mongo-driver/v2", Version: "v2.0.0", File: "worker/go.mod", Line: 3},
+	}
+	if !reflect.DeepEqual(result.DriverRefs, want) {
+		t.Errorf("DriverRefs = %+v, want %+v", result.DriverRefs, want)
+	}
+	if result.FilesScanned != 0 {
+		t.Errorf("FilesScanned = %d, manifests are not code", result.FilesScanned)
+	}
+}
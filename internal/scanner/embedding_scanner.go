@@ -0,0 +1,67 @@
+package scanner
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// embeddingDimensionsRe matches an explicit embedding size in code or
+// config constants: dimensions=256, numDimensions: 1536, EMBEDDING_DIM = 768.
+var embeddingDimensionsRe = regexp.MustCompile(`(?i)\b(?:dimensions|num_?dimensions|embedding_?dim(?:ension)?s?|output_?dimensionality|vector_?(?:size|dim(?:ension)?s?))["']?\s*[:=]\s*(\d{2,5})\b`)
+
+// embeddingModelRe matches quoted embedding model names.
+var embeddingModelRe = regexp.MustCompile(`["'\x60]((?:[\w.-]+/)?(?:text-embedding-(?:3-small|3-large|ada-002|004|005)|embed-(?:english|multilingual)(?:-light)?-v3\.0|all-MiniLM-L6-v2|all-MiniLM-L12-v2|all-mpnet-base-v2|nomic-embed-text(?:-v1\.5)?|mxbai-embed-large|voyage-3(?:\.5)?(?:-lite|-large)?|amazon\.titan-embed-text-v[12](?::0)?))["'\x60]`)
+
+// embeddingModelDimensions are the default output sizes of common models.
+var embeddingModelDimensions = map[string]int{
+	"text-embedding-3-small":        1536,
+	"text-embedding-3-large":        3072,
+	"text-embedding-ada-002":        1536,
+	"text-embedding-004":            768,
+	"text-embedding-005":            768,
+	"embed-english-v3.0":            1024,
+	"embed-multilingual-v3.0":       1024,
+	"embed-english-light-v3.0":      384,
+	"embed-multilingual-light-v3.0": 384,
+	"all-MiniLM-L6-v2":              384,
+	"all-MiniLM-L12-v2":             384,
+	"all-mpnet-base-v2":             768,
+	"nomic-embed-text":              768,
+	"nomic-embed-text-v1.5":         768,
+	"mxbai-embed-large":             1024,
+	"voyage-3":                      1024,
+	"voyage-3-lite":                 512,
+	"voyage-3-large":                1024,
+	"voyage-3.5":                    1024,
+	"voyage-3.5-lite":               1024,
+	"amazon.titan-embed-text-v1":    1536,
+	"amazon.titan-embed-text-v2":    1024,
+	"amazon.titan-embed-text-v1:0":  1536,
+	"amazon.titan-embed-text-v2:0":  1024,
+}
+
+// embeddingMatch is an embedding size implied by one line of code.
+type embeddingMatch struct {
+	Model      string
+	Dimensions int
+}
+
+// ScanLineEmbedding extracts the embedding size a line implies: an explicit
+// dimensions setting, else the default of a known model named on the line.
+// It reports false when the line implies none.
+func ScanLineEmbedding(line string) (embeddingMatch, bool) {
+	var m embeddingMatch
+	if mm := embeddingModelRe.FindStringSubmatch(line); mm != nil {
+		m.Model = mm[1]
+		name := m.Model
+		if i := strings.LastIndexByte(name, '/'); i >= 0 {
+			name = name[i+1:] // sentence-transformers/all-MiniLM-L6-v2
+		}
+		m.Dimensions = embeddingModelDimensions[name]
+	}
+	if dm := embeddingDimensionsRe.FindStringSubmatch(line); dm != nil {
+		m.Dimensions, _ = strconv.Atoi(dm[1])
+	}
+	return m, m.Dimensions > 0
+}
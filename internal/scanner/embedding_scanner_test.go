@@ -0,0 +1,25 @@
+package scanner
+
+import "testing"
+
+func TestScanLineEmbedding(t *testing.T) {
+	tests := []struct {
+		line string
+		want embeddingMatch
+		ok   bool
+	}{
+		{`resp = client.embeddings.create(model="text-embedding-3-small", input=text)`, embeddingMatch{Model: "text-embedding-3-small", Dimensions: 1536}, true},
+		{`client.embeddings.create({ model: "text-embedding-3-large", dimensions: 256, input })`, embeddingMatch{Model: "text-embedding-3-large", Dimensions: 256}, true},
+		{`model = SentenceTransformer("sentence-transformers/all-MiniLM-L6-v2")`, embeddingMatch{Model: "sentence-transformers/all-MiniLM-L6-v2", Dimensions: 384}, true},
+		{`EMBEDDING_DIM = 768`, embeddingMatch{Dimensions: 768}, true},
+		{`{"type": "vector", "path": "embedding", "numDimensions": 1024, "similarity": "cosine"}`, embeddingMatch{Dimensions: 1024}, true},
+		{`model = "gpt-4o"`, embeddingMatch{}, false},
+		{`const dimensions = computeDims()`, embeddingMatch{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ScanLineEmbedding(tt.line)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ScanLineEmbedding(%q) = %+v, %v; want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
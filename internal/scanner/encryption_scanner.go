@@ -0,0 +1,189 @@
+package scanner
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// encryptionMapRe matches the client options that carry per-namespace
+// encryption schemas: Queryable Encryption's encryptedFieldsMap and CSFLE's
+// schemaMap, in camelCase and snake_case, including setters such as
+// SetEncryptedFieldsMap.
+var encryptionMapRe = regexp.MustCompile(`(?i)(encryptedFieldsMap|encrypted_fields_map|schemaMap|schema_map)\b`)
+
+// encryptionNamespaceRe matches a "db.collection": key opening a map entry,
+// optionally followed by a Go composite literal type before the brace.
+var encryptionNamespaceRe = regexp.MustCompile(`^["']([A-Za-z0-9_\-]+)\.([A-Za-z0-9_.\-]+)["']\s*:\s*[A-Za-z0-9_.\[\]]*\{`)
+
+// encryptedPathRe extracts Queryable Encryption field paths: path: "ssn".
+var encryptedPathRe = regexp.MustCompile(`["']?path["']?\s*:\s*["']([^"']+)["']`)
+
+// encryptedQueryTypeRe extracts Queryable Encryption query types:
+// queryType: "equality".
+var encryptedQueryTypeRe = regexp.MustCompile(`["']?queryType["']?\s*:\s*["'](\w+)["']`)
+
+// encryptedPropertyRe extracts CSFLE JSON Schema properties with an encrypt
+// keyword: ssn: { encrypt: ... }.
+var encryptedPropertyRe = regexp.MustCompile(`["']?([A-Za-z_][A-Za-z0-9_]*)["']?\s*:\s*[A-Za-z0-9_.\[\]]*\{\s*["']?encrypt["']?\s*:`)
+
+// encryptionMapLookahead bounds how far after the option name the map's
+// opening brace may appear.
+const encryptionMapLookahead = 200
+
+// scanEncryptedFields finds encryptedFieldsMap and schemaMap literals in a
+// file and returns the encrypted fields declared for each namespace. Only
+// literal "db.collection" keys are recognized.
+func scanEncryptedFields(lines []string, relPath string) []EncryptedFieldRef {
+	text := strings.Join(lines, "\n")
+	var refs []EncryptedFieldRef
+	for _, loc := range encryptionMapRe.FindAllStringSubmatchIndex(text, -1) {
+		kind := EncryptionCSFLE
+		if strings.Contains(strings.ToLower(text[loc[2]:loc[3]]), "encrypted") {
+			kind = EncryptionQueryable
+		}
+		rest := text[loc[1]:]
+		open := strings.IndexByte(rest, '{')
+		if open < 0 || open > encryptionMapLookahead {
+			continue
+		}
+		start := loc[1] + open
+		end := matchingBrace(text, start)
+		if end < 0 {
+			continue
+		}
+		for _, entry := range namespaceEntries(text, start, end) {
+			body := text[entry.start:entry.end]
+			var fields []string
+			var queries map[string][]string
+			if kind == EncryptionQueryable {
+				for _, m := range encryptedPathRe.FindAllStringSubmatchIndex(body, -1) {
+					path := body[m[2]:m[3]]
+					fields = append(fields, path)
+					for _, q := range encryptedQueryTypeRe.FindAllStringSubmatch(enclosingObject(body, m[0]), -1) {
+						if queries == nil {
+							queries = make(map[string][]string)
+						}
+						queries[path] = append(queries[path], q[1])
+					}
+				}
+			} else {
+				for _, m := range encryptedPropertyRe.FindAllStringSubmatch(body, -1) {
+					fields = append(fields, m[1])
+				}
+			}
+			if len(fields) == 0 {
+				continue
+			}
+			sort.Strings(fields)
+			refs = append(refs, EncryptedFieldRef{
+				Database:   entry.database,
+				Collection: entry.collection,
+				Fields:     fields,
+				Queries:    queries,
+				Kind:       kind,
+				File:       relPath,
+				Line:       strings.Count(text[:entry.keyPos], "\n") + 1,
+			})
+		}
+	}
+	return refs
+}
+
+type namespaceEntry struct {
+	database, collection string
+	keyPos, start, end   int
+}
+
+// namespaceEntries returns the "db.coll": {...} entries directly inside the
+// map literal spanning text[start:end+1].
+func namespaceEntries(text string, start, end int) []namespaceEntry {
+	var entries []namespaceEntry
+	depth := 0
+	for i := start; i <= end; i++ {
+		switch c := text[i]; c {
+		case '{', '[', '(':
+			depth++
+		case '}', ']', ')':
+			depth--
+		case '"', '\'':
+			if depth == 1 {
+				if m := encryptionNamespaceRe.FindStringSubmatchIndex(text[i : end+1]); m != nil {
+					open := i + m[1] - 1
+					close := matchingBrace(text, open)
+					if close < 0 {
+						return entries
+					}
+					entries = append(entries, namespaceEntry{
+						database:   text[i+m[2] : i+m[3]],
+						collection: text[i+m[4] : i+m[5]],
+						keyPos:     i,
+						start:      open,
+						end:        close + 1,
+					})
+					i = close
+					continue
+				}
+			}
+			i = skipString(text, i, end)
+		}
+	}
+	return entries
+}
+
+// enclosingObject returns the innermost {...} of text containing pos, or ""
+// when there is none.
+func enclosingObject(text string, pos int) string {
+	depth := 0
+	for i := pos - 1; i >= 0; i-- {
+		switch text[i] {
+		case '}':
+			depth++
+		case '{':
+			if depth == 0 {
+				if end := matchingBrace(text, i); end >= 0 {
+					return text[i : end+1]
+				}
+				return ""
+			}
+			depth--
+		}
+	}
+	return ""
+}
+
+// matchingBrace returns the index of the brace closing the one at open,
+// skipping quoted strings, or -1.
+func matchingBrace(text string, open int) int {
+	depth := 0
+	for i := open; i < len(text); i++ {
+		switch text[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		case '"', '\'':
+			i = skipString(text, i, len(text)-1)
+		}
+	}
+	return -1
+}
+
+// skipString returns the index of the quote closing the string starting at i.
+func skipString(text string, i, end int) int {
+	quote := text[i]
+	for j := i + 1; j <= end; j++ {
+		switch text[j] {
+		case '\\':
+			j++
+		case quote:
+			return j
+		case '\n':
+			return j
+		}
+	}
+	return end
+}
@@ -0,0 +1,79 @@
+package scanner
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScanEncryptedFields_QueryableJS(t *testing.T) {
+	src := `const client = new MongoClient(uri, {
+  autoEncryption: {
+    keyVaultNamespace: "encryption.__keyVault",
+    encryptedFieldsMap: {
+      "medical.patients": {
+        fields: [
+          { keyId: k1, path: "ssn", bsonType: "string", queries: { queryType: "equality" } },
+          { keyId: k2, path: "billing.card", bsonType: "string" },
+        ],
+      },
+    },
+  },
+});`
+	refs := scanEncryptedFields(strings.Split(src, "\n"), "db.js")
+	if len(refs) != 1 {
+		t.Fatalf("expected 1 ref, got %+v", refs)
+	}
+	r := refs[0]
+	if r.Database != "medical" || r.Collection != "patients" || r.Kind != EncryptionQueryable || r.Line != 5 {
+		t.Errorf("unexpected ref %+v", r)
+	}
+	if !reflect.DeepEqual(r.Fields, []string{"billing.card", "ssn"}) {
+		t.Errorf("fields = %v", r.Fields)
+	}
+	if !reflect.DeepEqual(r.Queries, map[string][]string{"ssn": {"equality"}}) {
+		t.Errorf("queries = %v", r.Queries)
+	}
+}
+
+func TestScanEncryptedFields_SchemaMapPython(t *testing.T) {
+	src := `schema_map = {
+    "hr.employees": {
+        "bsonType": "object",
+        "properties": {
+            "salary": {"encrypt": {"bsonType": "int", "algorithm": RANDOM}},
+            "name": {"bsonType": "string"},
+        },
+    },
+    "hr.reviews": {"properties": {"notes": {"encrypt": {"bsonType": "string"}}}},
+}`
+	refs := scanEncryptedFields(strings.Split(src, "\n"), "app.py")
+	if len(refs) != 2 {
+		t.Fatalf("expected 2 refs, got %+v", refs)
+	}
+	if refs[0].Collection != "employees" || refs[0].Kind != EncryptionCSFLE || !reflect.DeepEqual(refs[0].Fields, []string{"salary"}) {
+		t.Errorf("unexpected first ref %+v", refs[0])
+	}
+	if refs[1].Collection != "reviews" || !reflect.DeepEqual(refs[1].Fields, []string{"notes"}) || refs[1].Line != 9 {
+		t.Errorf("unexpected second ref %+v", refs[1])
+	}
+}
+
+func TestScanEncryptedFields_GoLiteral(t *testing.T) {
+	src := `opts := options.AutoEncryption().SetEncryptedFieldsMap(map[string]any{
+	"shop.orders": bson.M{
+		"fields": bson.A{bson.M{"path": "card", "bsonType": "string"}},
+	},
+})`
+	refs := scanEncryptedFields(strings.Split(src, "\n"), "main.go")
+	if len(refs) != 1 || refs[0].Collection != "orders" || !reflect.DeepEqual(refs[0].Fields, []string{"card"}) {
+		t.Fatalf("unexpected refs %+v", refs)
+	}
+}
+
+func TestScanEncryptedFields_NoLiteralNamespace(t *testing.T) {
+	src := `const encryptedFieldsMap = { [namespace]: { fields } };`
+	if refs := scanEncryptedFields([]string{src}, "x.js"); len(refs) != 0 {
+		t.Errorf("expected no refs, got %+v", refs)
+	}
+}
@@ -0,0 +1,304 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// fixtureDirs are directory names whose JSON and YAML files are read as
+// seed data.
+var fixtureDirs = map[string]bool{
+	"fixtures":                   true,
+	"fixture":                    true,
+	"seeds":                      true,
+	"seed":                       true,
+	"seeders":                    true,
+	"seed-data":                  true,
+	"seed_data":                  true,
+	"mongo-seed":                 true,
+	"mongo-init":                 true,
+	"docker-entrypoint-initdb.d": true,
+}
+
+// maxFixtureBytes skips fixture files too large to be hand-written seeds.
+const maxFixtureBytes = 10 << 20
+
+// isFixturePath reports whether the file at relPath is read as possible
+// seed data: a JSON, NDJSON or YAML file in a fixture directory or with
+// "seed" or "fixture" in its name. Whether it seeds a collection is left
+// to the analyzer, which knows the live and referenced collections.
+func isFixturePath(relPath string) bool {
+	switch strings.ToLower(filepath.Ext(relPath)) {
+	case ".json", ".ndjson", ".jsonl", ".yml", ".yaml":
+	default:
+		return false
+	}
+	base := strings.ToLower(filepath.Base(relPath))
+	if strings.Contains(base, "seed") || strings.Contains(base, "fixture") {
+		return true
+	}
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(relPath)), "/") {
+		if fixtureDirs[strings.ToLower(dir)] {
+			return true
+		}
+	}
+	return false
+}
+
+// scanFixture reads the documents of a seed file. An array of documents,
+// or one document per line, seeds the collection named like the file, as
+// mongoimport does; an object whose values are arrays of documents seeds
+// one collection per key. Other shapes yield no refs. JavaScript and shell
+// init scripts are not read here.
+func scanFixture(path, repoPath string) ([]FixtureRef, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.Size() > maxFixtureBytes {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	relPath, _ := filepath.Rel(repoPath, path)
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	var groups []fixtureGroup
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml":
+		groups = yamlFixtureGroups(data, name)
+	default:
+		groups = jsonFixtureGroups(data, name)
+	}
+
+	var refs []FixtureRef
+	for _, g := range groups {
+		if !isValidCollectionName(g.collection) || len(g.docs) == 0 {
+			continue
+		}
+		refs = append(refs, fixtureRef(g, relPath))
+	}
+	return refs, nil
+}
+
+// fixtureGroup is the documents a fixture seeds into one collection.
+type fixtureGroup struct {
+	collection string
+	line       int
+	docs       []map[string]any
+}
+
+func jsonFixtureGroups(data []byte, name string) []fixtureGroup {
+	var root any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&root); err == nil && !dec.More() {
+		if docs, ok := fixtureDocs(root); ok {
+			return []fixtureGroup{{collection: name, line: 1, docs: docs}}
+		}
+		obj, ok := root.(map[string]any)
+		if !ok {
+			return nil
+		}
+		var groups []fixtureGroup
+		for key, v := range obj {
+			docs, ok := fixtureDocs(v)
+			if !ok {
+				return nil // not a seed file: a value is not a list of documents
+			}
+			groups = append(groups, fixtureGroup{collection: key, line: jsonKeyLine(data, key), docs: docs})
+		}
+		sort.Slice(groups, func(i, j int) bool {
+			if groups[i].line != groups[j].line {
+				return groups[i].line < groups[j].line
+			}
+			return groups[i].collection < groups[j].collection
+		})
+		return groups
+	}
+
+	// One document per line.
+	var docs []map[string]any
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64*1024), maxFixtureBytes)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var doc map[string]any
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.UseNumber()
+		if err := dec.Decode(&doc); err != nil {
+			return nil
+		}
+		docs = append(docs, doc)
+	}
+	return []fixtureGroup{{collection: name, line: 1, docs: docs}}
+}
+
+func yamlFixtureGroups(data []byte, name string) []fixtureGroup {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil || len(root.Content) == 0 {
+		return nil
+	}
+	doc := root.Content[0]
+	decode := func(n *yaml.Node) ([]map[string]any, bool) {
+		var v any
+		if n.Decode(&v) != nil {
+			return nil, false
+		}
+		return fixtureDocs(v)
+	}
+	switch doc.Kind {
+	case yaml.SequenceNode:
+		if docs, ok := decode(doc); ok {
+			return []fixtureGroup{{collection: name, line: doc.Line, docs: docs}}
+		}
+	case yaml.MappingNode:
+		var groups []fixtureGroup
+		for i := 0; i+1 < len(doc.Content); i += 2 {
+			docs, ok := decode(doc.Content[i+1])
+			if !ok {
+				return nil
+			}
+			groups = append(groups, fixtureGroup{collection: doc.Content[i].Value, line: doc.Content[i].Line, docs: docs})
+		}
+		return groups
+	}
+	return nil
+}
+
+// fixtureDocs returns v as a list of documents.
+func fixtureDocs(v any) ([]map[string]any, bool) {
+	list, ok := v.([]any)
+	if !ok || len(list) == 0 {
+		return nil, false
+	}
+	docs := make([]map[string]any, 0, len(list))
+	for _, item := range list {
+		doc, ok := item.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		docs = append(docs, doc)
+	}
+	return docs, true
+}
+
+// jsonKeyLine returns the line of the first occurrence of key as a JSON
+// object key.
+func jsonKeyLine(data []byte, key string) int {
+	quoted, _ := json.Marshal(key)
+	idx := bytes.Index(data, quoted)
+	if idx < 0 {
+		return 1
+	}
+	return bytes.Count(data[:idx], []byte("\n")) + 1
+}
+
+// fixtureRef summarizes the top-level fields of a fixture's documents.
+func fixtureRef(g fixtureGroup, relPath string) FixtureRef {
+	types := make(map[string]map[string]bool)
+	counts := make(map[string]int)
+	for _, doc := range g.docs {
+		for field, v := range doc {
+			counts[field]++
+			if types[field] == nil {
+				types[field] = make(map[string]bool)
+			}
+			types[field][fixtureValueType(v)] = true
+		}
+	}
+	ref := FixtureRef{Collection: g.collection, Docs: len(g.docs), File: relPath, Line: g.line}
+	for _, doc := range g.docs {
+		if _, ok := doc["_id"]; ok || hasExtendedJSON(doc) {
+			ref.MongoData = true
+			break
+		}
+	}
+	for field, n := range counts {
+		f := FixtureField{Name: field, Count: n}
+		for t := range types[field] {
+			f.Types = append(f.Types, t)
+		}
+		sort.Strings(f.Types)
+		ref.Fields = append(ref.Fields, f)
+	}
+	sort.Slice(ref.Fields, func(i, j int) bool { return ref.Fields[i].Name < ref.Fields[j].Name })
+	return ref
+}
+
+// extendedJSONKeys are the Extended JSON wrappers that mark a document as
+// MongoDB data rather than other JSON.
+var extendedJSONKeys = map[string]bool{
+	"$oid":           true,
+	"$date":          true,
+	"$numberLong":    true,
+	"$numberDecimal": true,
+}
+
+// hasExtendedJSON reports whether v holds an Extended JSON wrapper at any
+// depth.
+func hasExtendedJSON(v any) bool {
+	switch x := v.(type) {
+	case map[string]any:
+		for k, item := range x {
+			if extendedJSONKeys[k] || hasExtendedJSON(item) {
+				return true
+			}
+		}
+	case []any:
+		for _, item := range x {
+			if hasExtendedJSON(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fixtureValueType returns the ValueType of a decoded fixture value.
+// Extended JSON wrappers such as {"$oid": ...} count as the type they
+// encode.
+func fixtureValueType(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ValueTypeNull
+	case string:
+		return ValueTypeString
+	case bool:
+		return ValueTypeBool
+	case time.Time:
+		return ValueTypeDate
+	case json.Number, int, int64, float64:
+		return ValueTypeNumber
+	case []any:
+		return ValueTypeArray
+	case map[string]any:
+		if len(x) == 1 {
+			for k := range x {
+				switch k {
+				case "$oid":
+					return ValueTypeObjectID
+				case "$date":
+					return ValueTypeDate
+				case "$numberInt", "$numberLong", "$numberDouble", "$numberDecimal":
+					return ValueTypeNumber
+				}
+			}
+		}
+		return ValueTypeObject
+	}
+	return ValueTypeUnknown
+}
@@ -0,0 +1,47 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScanFixtures(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "test/fixtures/users.json", `[
+  {"_id": {"$oid": "65a1b2c3d4e5f6a7b8c9d0e1"}, "email": "a@example.com", "age": 31},
+  {"_id": {"$oid": "65a1b2c3d4e5f6a7b8c9d0e2"}, "age": "unknown", "createdAt": {"$date": "2024-01-01T00:00:00Z"}}
+]
+`)
+	writeFile(t, dir, "db/seed.json", "{\n  \"orders\": [{\"total\": 10}],\n  \"carts\": [{\"items\": []}, {\"items\": [1]}]\n}\n")
+	writeFile(t, dir, "docker-entrypoint-initdb.d/events.ndjson", "{\"type\": \"signup\"}\n{\"type\": \"login\", \"meta\": {\"ip\": \"::1\"}}\n")
+	writeFile(t, dir, "seeds/catalog.yml", "products:\n  - sku: A-1\n    price: 9.5\n    listed: 2024-01-01\n")
+	writeFile(t, dir, "fixtures/config.json", `{"name": "not a seed", "tags": ["a"]}`)
+	writeFile(t, dir, "api/users.json", `[{"email": "a@example.com"}]`)
+
+	result, err := Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []FixtureRef{
+		{Collection: "orders", Docs: 1, File: "db/seed.json", Line: 2, Fields: []FixtureField{{Name: "total", Types: []string{"number"}, Count: 1}}},
+		{Collection: "carts", Docs: 2, File: "db/seed.json", Line: 3, Fields: []FixtureField{{Name: "items", Types: []string{"array"}, Count: 2}}},
+		{Collection: "events", Docs: 2, File: "docker-entrypoint-initdb.d/events.ndjson", Line: 1, Fields: []FixtureField{
+			{Name: "meta", Types: []string{"object"}, Count: 1},
+			{Name: "type", Types: []string{"string"}, Count: 2},
+		}},
+		{Collection: "products", Docs: 1, File: "seeds/catalog.yml", Line: 1, Fields: []FixtureField{
+			{Name: "listed", Types: []string{"date"}, Count: 1},
+			{Name: "price", Types: []string{"number"}, Count: 1},
+			{Name: "sku", Types: []string{"string"}, Count: 1},
+		}},
+		{Collection: "users", Docs: 2, File: "test/fixtures/users.json", Line: 1, Fields: []FixtureField{
+			{Name: "_id", Types: []string{"objectId"}, Count: 2},
+			{Name: "age", Types: []string{"number", "string"}, Count: 2},
+			{Name: "createdAt", Types: []string{"date"}, Count: 1},
+			{Name: "email", Types: []string{"string"}, Count: 1},
+		}, MongoData: true},
+	}
+	if !reflect.DeepEqual(result.FixtureRefs, want) {
+		t.Errorf("FixtureRefs =\n%+v\nwant\n%+v", result.FixtureRefs, want)
+	}
+}
@@ -0,0 +1,277 @@
+package scanner
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// iacResource is an Atlas resource type of the MongoDB Atlas Terraform
+// provider, which the Pulumi provider mirrors.
+type iacResource struct {
+	kind  IaCResourceKind
+	attrs []string // attributes holding the resource's value, in order
+}
+
+// iacResources are the Atlas resource types whose declarations are
+// compared with the live project.
+var iacResources = map[string]iacResource{
+	"mongodbatlas_database_user":          {IaCDatabaseUser, []string{"username"}},
+	"mongodbatlas_project_ip_access_list": {IaCAccessList, []string{"cidr_block", "ip_address", "aws_security_group"}},
+	"mongodbatlas_cluster":                {IaCCluster, []string{"name"}},
+	"mongodbatlas_advanced_cluster":       {IaCCluster, []string{"name"}},
+}
+
+var (
+	hclResourceRe  = regexp.MustCompile(`^\s*resource\s+"(mongodbatlas_\w+)"\s+"([^"]+)"\s*\{`)
+	hclAttributeRe = regexp.MustCompile(`^\s*([A-Za-z_][\w-]*)\s*=\s*(.*)$`)
+)
+
+// isIaCFile reports whether name is a Terraform configuration or state
+// file, or a Pulumi YAML program.
+func isIaCFile(name string) bool {
+	switch name {
+	case "Pulumi.yaml", "Pulumi.yml", "Main.yaml", "Main.yml":
+		return true
+	}
+	return strings.HasSuffix(name, ".tf") || strings.HasSuffix(name, ".tfstate")
+}
+
+// scanIaC reads the Atlas resources declared in a Terraform or Pulumi
+// file.
+func scanIaC(path, repoPath string) ([]IaCRef, error) {
+	relPath, _ := filepath.Rel(repoPath, path)
+	name := filepath.Base(path)
+	switch {
+	case strings.HasSuffix(name, ".tf"):
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = f.Close() }()
+		var lines []string
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			lines = append(lines, sc.Text())
+		}
+		if err := sc.Err(); err != nil {
+			return nil, err
+		}
+		return scanHCL(lines, relPath), nil
+	case strings.HasSuffix(name, ".tfstate"):
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return scanTerraformState(data, relPath), nil
+	default:
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return scanPulumiYAML(data, relPath), nil
+	}
+}
+
+// scanHCL reads the Atlas resource blocks of Terraform configuration.
+// Only top-level attributes of a block are read; a value that is not a
+// plain string literal is recorded as set from an expression.
+func scanHCL(lines []string, relPath string) []IaCRef {
+	var refs []IaCRef
+	for i := 0; i < len(lines); i++ {
+		m := hclResourceRe.FindStringSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+		start := i
+		attrs := make(map[string]string)
+		depth := strings.Count(lines[i], "{") - strings.Count(lines[i], "}")
+		for i++; i < len(lines) && depth > 0; i++ {
+			line := lines[i]
+			if depth == 1 && !isCommentLine(line) {
+				if a := hclAttributeRe.FindStringSubmatch(line); a != nil {
+					attrs[a[1]], _ = hclString(a[2])
+				}
+			}
+			depth += strings.Count(line, "{") - strings.Count(line, "}")
+		}
+		i--
+		if ref, ok := iacResourceRef(IaCTerraform, m[1], m[2], attrs, relPath, start+1); ok {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// hclString returns the string literal that expr starts with. It fails
+// for other expressions and for strings with interpolations.
+func hclString(expr string) (string, bool) {
+	if !strings.HasPrefix(expr, `"`) {
+		return "", false
+	}
+	end := strings.IndexByte(expr[1:], '"')
+	if end < 0 {
+		return "", false
+	}
+	s := expr[1 : end+1]
+	if strings.Contains(s, "${") || strings.Contains(s, `\`) {
+		return "", false
+	}
+	return s, true
+}
+
+// terraformState is the part of a Terraform state file that is read.
+type terraformState struct {
+	Resources []struct {
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Instances []struct {
+			IndexKey   any            `json:"index_key"`
+			Attributes map[string]any `json:"attributes"`
+		} `json:"instances"`
+	} `json:"resources"`
+}
+
+// scanTerraformState reads the managed Atlas resources of a Terraform
+// state file, one ref per instance.
+func scanTerraformState(data []byte, relPath string) []IaCRef {
+	var state terraformState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil
+	}
+	var refs []IaCRef
+	for _, res := range state.Resources {
+		if res.Mode != "managed" {
+			continue
+		}
+		for _, inst := range res.Instances {
+			name := res.Name
+			if inst.IndexKey != nil {
+				key, _ := json.Marshal(inst.IndexKey)
+				name = fmt.Sprintf("%s[%s]", name, key)
+			}
+			attrs := make(map[string]string)
+			for k, v := range inst.Attributes {
+				if s, ok := v.(string); ok && s != "" {
+					attrs[k] = s
+				}
+			}
+			ref, ok := iacResourceRef(IaCTerraform, res.Type, name, attrs, relPath, 1)
+			if !ok {
+				continue
+			}
+			if ref.Value != "" {
+				ref.Line = jsonKeyLine(data, ref.Value)
+			}
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// scanPulumiYAML reads the Atlas resources of a Pulumi YAML program.
+// Resource types such as mongodbatlas:DatabaseUser map to the Terraform
+// resource they wrap, and camelCase properties to its attributes.
+func scanPulumiYAML(data []byte, relPath string) []IaCRef {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil || len(root.Content) == 0 {
+		return nil
+	}
+	resources := yamlMapValue(root.Content[0], "resources")
+	if resources == nil || resources.Kind != yaml.MappingNode {
+		return nil
+	}
+	var refs []IaCRef
+	for i := 0; i+1 < len(resources.Content); i += 2 {
+		key, res := resources.Content[i], resources.Content[i+1]
+		typ := yamlMapValue(res, "type")
+		if typ == nil || !strings.HasPrefix(typ.Value, "mongodbatlas:") {
+			continue
+		}
+		token := typ.Value[strings.LastIndexByte(typ.Value, ':')+1:]
+		attrs := make(map[string]string)
+		if props := yamlMapValue(res, "properties"); props != nil && props.Kind == yaml.MappingNode {
+			for j := 0; j+1 < len(props.Content); j += 2 {
+				v := props.Content[j+1]
+				value := ""
+				if v.Kind == yaml.ScalarNode && !strings.Contains(v.Value, "${") {
+					value = v.Value
+				}
+				attrs[snakeCase(props.Content[j].Value)] = value
+			}
+		}
+		if ref, ok := iacResourceRef(IaCPulumi, "mongodbatlas_"+snakeCase(token), key.Value, attrs, relPath, key.Line); ok {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// yamlMapValue returns the value of key in the mapping node n.
+func yamlMapValue(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// snakeCase converts a camelCase or PascalCase name to snake_case.
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// iacResourceRef builds the ref of a resource of Terraform type tfType.
+// attrs maps attribute names to literal values, or to "" when set from an
+// expression. It fails for resource types that are not compared.
+func iacResourceRef(tool, tfType, name string, attrs map[string]string, relPath string, line int) (IaCRef, bool) {
+	res, ok := iacResources[tfType]
+	if !ok {
+		return IaCRef{}, false
+	}
+	ref := IaCRef{
+		Tool:      tool,
+		Kind:      res.kind,
+		Address:   tfType + "." + name,
+		ProjectID: attrs["project_id"],
+		File:      relPath,
+		Line:      line,
+	}
+	if res.kind == IaCDatabaseUser {
+		ref.AuthDatabase = attrs["auth_database_name"]
+	}
+	_, counted := attrs["count"]
+	_, each := attrs["for_each"]
+	if counted || each {
+		return ref, true
+	}
+	for _, a := range res.attrs {
+		if v, set := attrs[a]; set {
+			ref.Attribute, ref.Value = a, v
+			break
+		}
+	}
+	return ref, true
+}
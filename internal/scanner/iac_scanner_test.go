@@ -0,0 +1,115 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScanIaC(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "infra/atlas.tf", `resource "mongodbatlas_database_user" "app" {
+  project_id         = "proj1"
+  username           = "app" # service account
+  auth_database_name = "admin"
+
+  roles {
+    role_name     = "readWrite"
+    database_name = "shop"
+  }
+}
+
+resource "mongodbatlas_database_user" "readers" {
+  for_each           = toset(var.readers)
+  username           = each.value
+  auth_database_name = "admin"
+}
+
+resource "mongodbatlas_project_ip_access_list" "office" {
+  project_id = mongodbatlas_project.main.id
+  cidr_block = "203.0.113.0/24"
+}
+
+resource "mongodbatlas_advanced_cluster" "main" {
+  name = "${var.env}-cluster"
+}
+
+resource "aws_s3_bucket" "backups" {
+  bucket = "backups"
+}
+`)
+	writeFile(t, dir, "infra/terraform.tfstate", `{
+  "version": 4,
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "mongodbatlas_project_ip_access_list",
+      "name": "vpn",
+      "instances": [
+        {"index_key": 0, "attributes": {"project_id": "proj1", "cidr_block": "", "ip_address": "198.51.100.7"}}
+      ]
+    },
+    {
+      "mode": "data",
+      "type": "mongodbatlas_cluster",
+      "name": "existing",
+      "instances": [{"attributes": {"name": "Legacy"}}]
+    }
+  ]
+}
+`)
+	writeFile(t, dir, "pulumi/Pulumi.yaml", `name: atlas
+runtime: yaml
+resources:
+  reporting:
+    type: mongodbatlas:DatabaseUser
+    properties:
+      username: reporting
+      authDatabaseName: admin
+      projectId: ${projectId}
+  cluster:
+    type: mongodbatlas:index/cluster:Cluster
+    properties:
+      name: Cluster0
+`)
+	writeFile(t, dir, ".terraform/modules/atlas/main.tf", `resource "mongodbatlas_database_user" "vendored" {
+  username = "vendored"
+}
+`)
+
+	result, err := Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []IaCRef{
+		{Tool: IaCTerraform, Kind: IaCDatabaseUser, Address: "mongodbatlas_database_user.app", Value: "app", Attribute: "username",
+			AuthDatabase: "admin", ProjectID: "proj1", File: "infra/atlas.tf", Line: 1},
+		{Tool: IaCTerraform, Kind: IaCDatabaseUser, Address: "mongodbatlas_database_user.readers",
+			AuthDatabase: "admin", File: "infra/atlas.tf", Line: 12},
+		{Tool: IaCTerraform, Kind: IaCAccessList, Address: "mongodbatlas_project_ip_access_list.office", Value: "203.0.113.0/24",
+			Attribute: "cidr_block", File: "infra/atlas.tf", Line: 18},
+		{Tool: IaCTerraform, Kind: IaCCluster, Address: "mongodbatlas_advanced_cluster.main", Attribute: "name",
+			File: "infra/atlas.tf", Line: 23},
+		{Tool: IaCTerraform, Kind: IaCAccessList, Address: "mongodbatlas_project_ip_access_list.vpn[0]", Value: "198.51.100.7",
+			Attribute: "ip_address", ProjectID: "proj1", File: "infra/terraform.tfstate", Line: 9},
+		{Tool: IaCPulumi, Kind: IaCDatabaseUser, Address: "mongodbatlas_database_user.reporting", Value: "reporting",
+			Attribute: "username", AuthDatabase: "admin", File: "pulumi/Pulumi.yaml", Line: 4},
+		{Tool: IaCPulumi, Kind: IaCCluster, Address: "mongodbatlas_cluster.cluster", Value: "Cluster0",
+			Attribute: "name", File: "pulumi/Pulumi.yaml", Line: 10},
+	}
+	if !reflect.DeepEqual(result.IaCRefs, want) {
+		t.Errorf("IaCRefs =\n%+v\nwant\n%+v", result.IaCRefs, want)
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	for in, want := range map[string]string{
+		"DatabaseUser":        "database_user",
+		"ProjectIpAccessList": "project_ip_access_list",
+		"authDatabaseName":    "auth_database_name",
+		"name":                "name",
+	} {
+		if got := snakeCase(in); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package scanner
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Index keeps the scan result of every file in a repo so that changed files
+// can be rescanned without walking the whole tree again.
+type Index struct {
+	repoPath string
+	files    map[string]ScanResult // by path relative to repoPath
+	skipped  map[string]bool
+}
+
+// NewIndex scans repoPath and returns an index of its files.
+func NewIndex(repoPath string) (*Index, error) {
+	x := &Index{repoPath: repoPath, files: make(map[string]ScanResult), skipped: make(map[string]bool)}
+	err := filepath.WalkDir(repoPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if skipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		x.Update(path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// SkipDir reports whether the scanner ignores directories named name.
+func SkipDir(name string) bool {
+	return skipDirs[name]
+}
+
+// Update rescans path, or drops it from the index when it no longer exists.
+// A removed directory drops every file below it. Paths the scanner would
+// not read are ignored. It reports whether the indexed references changed.
+func (x *Index) Update(path string) bool {
+	rel, ok := x.rel(path)
+	if !ok {
+		return false
+	}
+	if !supportedExtensions[strings.ToLower(filepath.Ext(rel))] {
+		return x.dropMissingDir(path, rel)
+	}
+	prev, had := x.files[rel]
+	wasSkipped := x.skipped[rel]
+	delete(x.files, rel)
+	delete(x.skipped, rel)
+
+	refs, err := scanFile(path, x.repoPath)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return had || wasSkipped
+	case err != nil:
+		x.skipped[rel] = true
+		return had
+	}
+	x.files[rel] = refs
+	// Line numbers count as a change, since findings point at them.
+	return !had || !reflect.DeepEqual(prev, refs)
+}
+
+// dropMissingDir removes the files below rel when path no longer exists.
+func (x *Index) dropMissingDir(path, rel string) bool {
+	if _, err := os.Lstat(path); !errors.Is(err, fs.ErrNotExist) {
+		return false
+	}
+	prefix := rel + string(filepath.Separator)
+	dropped := false
+	for name := range x.files {
+		if strings.HasPrefix(name, prefix) {
+			delete(x.files, name)
+			dropped = true
+		}
+	}
+	for name := range x.skipped {
+		if strings.HasPrefix(name, prefix) {
+			delete(x.skipped, name)
+			dropped = true
+		}
+	}
+	return dropped
+}
+
+// Result merges the indexed files into a ScanResult, ordered by file.
+func (x *Index) Result() ScanResult {
+	result := ScanResult{RepoPath: x.repoPath, FilesScanned: len(x.files), FilesSkipped: len(x.skipped)}
+	names := make([]string, 0, len(x.files))
+	for name := range x.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		refs := x.files[name]
+		appendRefs(&result, &refs)
+	}
+	result.Collections = uniqueCollections(result.Refs)
+	return result
+}
+
+// rel returns path relative to the repo, or false when it is outside the
+// repo or inside a skipped directory.
+func (x *Index) rel(path string) (string, bool) {
+	rel, err := filepath.Rel(x.repoPath, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for _, dir := range parts[:len(parts)-1] {
+		if skipDirs[dir] {
+			return "", false
+		}
+	}
+	return rel, true
+}
@@ -0,0 +1,75 @@
+package scanner
+
+import (
+	"regexp"
+	"strings"
+)
+
+// indexDefinitionRe matches index creation calls across drivers.
+var indexDefinitionRe = regexp.MustCompile(`(?i)\.(createindex|createindexes|ensureindex|create_index|create_indexes|createone|createmany)\(|IndexModel\{`)
+
+// indexKeyPairRe extracts "field": spec pairs from JS/Python/Go bson.M key documents.
+var indexKeyPairRe = regexp.MustCompile(`["']?([a-zA-Z_$][a-zA-Z0-9_.$*]*)["']?\s*:\s*(-?1|["'](?:text|hashed|2dsphere|2d)["'])`)
+
+// indexKeyDocRe extracts Go bson.D elements: {Key: "field", Value: spec}.
+var indexKeyDocRe = regexp.MustCompile(`Key:\s*"([a-zA-Z_$][a-zA-Z0-9_.$*]*)",\s*Value:\s*(-?1|"(?:text|hashed|2dsphere|2d)")`)
+
+// indexKeyTupleRe extracts pymongo key tuples: ("field", pymongo.TEXT).
+var indexKeyTupleRe = regexp.MustCompile(`\(\s*["']([a-zA-Z_$][a-zA-Z0-9_.$*]*)["']\s*,\s*(?:pymongo\.)?(ASCENDING|DESCENDING|TEXT|HASHED|GEOSPHERE|-?1)\s*\)`)
+
+// indexUniqueRe matches unique index options.
+var indexUniqueRe = regexp.MustCompile(`(?i)["']?unique["']?\s*[:=]\s*true|SetUnique\(true\)`)
+
+// indexDef is an index definition found on one line.
+type indexDef struct {
+	Fields     []string
+	TextFields []string
+	Unique     bool
+}
+
+// ScanLineIndexDef extracts an index definition from a line that creates an
+// index. It reports false when the line does not create an index or no key
+// fields could be read.
+func ScanLineIndexDef(line string) (indexDef, bool) {
+	loc := indexDefinitionRe.FindStringIndex(line)
+	if loc == nil {
+		return indexDef{}, false
+	}
+	body := line[loc[0]:]
+
+	var def indexDef
+	seen := make(map[string]bool)
+	add := func(field, spec string) {
+		if seen[field] || field == "unique" || field == "sparse" || field == "background" {
+			return
+		}
+		seen[field] = true
+		def.Fields = append(def.Fields, field)
+		spec = strings.Trim(spec, `"'`)
+		if spec == "text" || spec == "TEXT" {
+			def.TextFields = append(def.TextFields, field)
+		}
+	}
+	for _, m := range indexKeyDocRe.FindAllStringSubmatch(body, -1) {
+		add(m[1], m[2])
+	}
+	for _, m := range indexKeyTupleRe.FindAllStringSubmatch(body, -1) {
+		add(m[1], m[2])
+	}
+	if len(def.Fields) == 0 {
+		// Only the first document is the key; later ones hold options
+		// such as wildcardProjection.
+		keyDoc := body
+		if end := strings.IndexByte(keyDoc, '}'); end >= 0 {
+			keyDoc = keyDoc[:end]
+		}
+		for _, m := range indexKeyPairRe.FindAllStringSubmatch(keyDoc, -1) {
+			add(m[1], m[2])
+		}
+	}
+	if len(def.Fields) == 0 {
+		return indexDef{}, false
+	}
+	def.Unique = indexUniqueRe.MatchString(body)
+	return def, true
+}
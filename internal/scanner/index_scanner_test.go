@@ -0,0 +1,86 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScanLineIndexDef(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		fields []string
+		text   []string
+		unique bool
+	}{
+		{
+			name:   "js createIndex unique",
+			line:   `db.collection("users").createIndex({ email: 1 }, { unique: true })`,
+			fields: []string{"email"},
+			unique: true,
+		},
+		{
+			name:   "js text index",
+			line:   `db.articles.createIndex({ "title": "text", "body": "text", "lang": 1 })`,
+			fields: []string{"title", "body", "lang"},
+			text:   []string{"title", "body"},
+		},
+		{
+			name:   "wildcard ignores projection",
+			line:   `db.events.createIndex({ "$**": 1 }, { wildcardProjection: { "attrs": 1 } })`,
+			fields: []string{"$**"},
+		},
+		{
+			name:   "pymongo tuples",
+			line:   `db.posts.create_index([("title", pymongo.TEXT), ("created_at", pymongo.DESCENDING)])`,
+			fields: []string{"title", "created_at"},
+			text:   []string{"title"},
+		},
+		{
+			name:   "go IndexModel",
+			line:   `mongo.IndexModel{Keys: bson.D{{Key: "sku", Value: 1}}, Options: options.Index().SetUnique(true)}`,
+			fields: []string{"sku"},
+			unique: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def, ok := ScanLineIndexDef(tt.line)
+			if !ok {
+				t.Fatal("expected index definition")
+			}
+			if !reflect.DeepEqual(def.Fields, tt.fields) {
+				t.Errorf("fields = %v, want %v", def.Fields, tt.fields)
+			}
+			if !reflect.DeepEqual(def.TextFields, tt.text) {
+				t.Errorf("textFields = %v, want %v", def.TextFields, tt.text)
+			}
+			if def.Unique != tt.unique {
+				t.Errorf("unique = %v, want %v", def.Unique, tt.unique)
+			}
+		})
+	}
+}
+
+func TestScanLineIndexDef_NotIndex(t *testing.T) {
+	if _, ok := ScanLineIndexDef(`db.collection("users").find({ email: 1 })`); ok {
+		t.Fatal("find should not be an index definition")
+	}
+}
+
+func TestScan_IndexRefs(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "setup.js", `db.collection("articles").createIndex({ title: "text" })
+`)
+	result, err := Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.IndexRefs) != 1 {
+		t.Fatalf("expected 1 index ref, got %+v", result.IndexRefs)
+	}
+	ref := result.IndexRefs[0]
+	if ref.Collection != "articles" || ref.Line != 1 || len(ref.TextFields) != 1 {
+		t.Errorf("unexpected ref %+v", ref)
+	}
+}
@@ -0,0 +1,74 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestIndex(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "users.js", `db.collection("users").find({ status: "active" })`+"\n")
+	writeFile(t, dir, "orders.py", `db["orders"].insert_one({"total": 1})`+"\n")
+	writeFile(t, dir, "node_modules/lib/x.js", `db.collection("vendored").find({})`+"\n")
+
+	x, err := NewIndex(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	full, err := Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := x.Result(); !reflect.DeepEqual(got.Collections, full.Collections) || got.FilesScanned != full.FilesScanned {
+		t.Fatalf("index = %v (%d files), scan = %v (%d files)", got.Collections, got.FilesScanned, full.Collections, full.FilesScanned)
+	}
+
+	// Unchanged content is not a change; a new reference is.
+	users := filepath.Join(dir, "users.js")
+	if x.Update(users) {
+		t.Error("rescanning an unchanged file reported a change")
+	}
+	writeFile(t, dir, "users.js", `db.collection("users").find({ status: "active" })`+"\n"+`db.collection("sessions").find({})`+"\n")
+	if !x.Update(users) {
+		t.Error("adding a reference was not reported as a change")
+	}
+	if got := x.Result().Collections; !reflect.DeepEqual(got, []string{"orders", "sessions", "users"}) {
+		t.Errorf("collections after edit = %v", got)
+	}
+
+	// Deleted files drop out; skipped and unsupported paths are ignored.
+	if err := os.Remove(filepath.Join(dir, "orders.py")); err != nil {
+		t.Fatal(err)
+	}
+	if !x.Update(filepath.Join(dir, "orders.py")) {
+		t.Error("deleting a file was not reported as a change")
+	}
+	writeFile(t, dir, "node_modules/lib/y.js", `db.collection("other").find({})`+"\n")
+	writeFile(t, dir, "notes.txt", `db.collection("notes").find({})`+"\n")
+	for _, name := range []string{"node_modules/lib/y.js", "notes.txt"} {
+		if x.Update(filepath.Join(dir, name)) {
+			t.Errorf("Update(%s) reported a change", name)
+		}
+	}
+	got := x.Result()
+	if !reflect.DeepEqual(got.Collections, []string{"sessions", "users"}) || got.FilesScanned != 1 {
+		t.Errorf("collections after delete = %v (%d files)", got.Collections, got.FilesScanned)
+	}
+
+	// Removing a directory drops the files below it.
+	writeFile(t, dir, "jobs/sync.go", `coll := db.Collection("jobs")`+"\n")
+	if !x.Update(filepath.Join(dir, "jobs", "sync.go")) {
+		t.Error("adding a file was not reported as a change")
+	}
+	if err := os.RemoveAll(filepath.Join(dir, "jobs")); err != nil {
+		t.Fatal(err)
+	}
+	if !x.Update(filepath.Join(dir, "jobs")) {
+		t.Error("removing a directory was not reported as a change")
+	}
+	if got := x.Result().Collections; !reflect.DeepEqual(got, []string{"sessions", "users"}) {
+		t.Errorf("collections after removing a directory = %v", got)
+	}
+}
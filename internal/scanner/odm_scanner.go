@@ -0,0 +1,402 @@
+package scanner
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	mongooseSchemaRe      = regexp.MustCompile(`(\w+)\s*=\s*new\s+(?:mongoose\.)?Schema\s*\(`)
+	mongooseModelRe       = regexp.MustCompile(`(?:(\w+)\s*=\s*(?:await\s+)?)?\b(?:mongoose\.|\w+\.)?model\s*\(\s*["'](\w+)["']\s*,\s*(\w+)(?:\s*,\s*["']([\w.-]+)["'])?`)
+	mongooseSchemaIndexRe = regexp.MustCompile(`\b(\w+)\.index\s*\(\s*\{`)
+	mongooseFieldIndexRe  = regexp.MustCompile(`\b(?:(index|unique|text)\s*:\s*true\b|(expires)\s*:\s*["']?\w)`)
+	mongooseTimestampsRe  = regexp.MustCompile(`\btimestamps\s*:\s*(true|\{[^}]*\})`)
+	mongooseTimestampRe   = regexp.MustCompile(`\b(createdAt|updatedAt)\s*:\s*(false|["']([\w.]+)["'])`)
+	mongooseAutoIndexRe   = regexp.MustCompile(`\bautoIndex["']?\s*[:,]\s*(true|false)\b`)
+	mongooseSyncRe        = regexp.MustCompile(`\b(\w+)\.(syncIndexes|ensureIndexes)\s*\(`)
+	schemaKeyRe           = regexp.MustCompile(`^\s*["']?([\w$]+)["']?\s*:`)
+	schemaCollectionOptRe = regexp.MustCompile(`\bcollection\s*:\s*["']([\w.-]+)["']`)
+
+	springDocumentRe      = regexp.MustCompile(`@Document\b(?:\s*\(\s*(?:collection\s*=\s*|value\s*=\s*)?"([\w.-]+)"[^)]*\))?`)
+	springClassRe         = regexp.MustCompile(`\bclass\s+(\w+)`)
+	springIndexedRe       = regexp.MustCompile(`@Indexed\b(\([^)]*\))?`)
+	springCompoundIndexRe = regexp.MustCompile(`@CompoundIndex\s*\(([^)]*)\)`)
+	springFieldNameRe     = regexp.MustCompile(`@Field\s*\(\s*(?:(?:name|value)\s*=\s*)?"([\w.]+)"`)
+	springFieldDeclRe     = regexp.MustCompile(`^\s*(?:(?:private|protected|public|final)\s+)*[\w.<>\[\], ?]+\s+(\w+)\s*(?:=[^;]*)?;`)
+	springUniqueRe        = regexp.MustCompile(`\bunique\s*=\s*true\b`)
+	springAutoIndexRe     = regexp.MustCompile(`\bautoIndexCreation\s*\(\s*\)\s*\{`)
+	springSetAutoIndexRe  = regexp.MustCompile(`\bsetAutoIndexCreation\s*\(\s*(true|false)\s*\)`)
+	springEnsureIndexRe   = regexp.MustCompile(`\bindexOps\s*\(\s*(?:(\w+)\.class|"([\w.-]+)")?[^)]*\)\s*\.(ensureIndex|createIndex)\s*\(`)
+	springConfigAutoRe    = regexp.MustCompile(`auto-index-creation["']?\s*[=:]\s*["']?(true|false)\b`)
+
+	mgmIndexSyncRe = regexp.MustCompile(`\bmgm\.Coll\(\s*&?(\w+)\{\}\s*\)\.Indexes\(\)\.(CreateOne|CreateMany)\(`)
+)
+
+// scanODM returns the ODM configuration found in a source file: Mongoose
+// schemas and models, Spring Data entities and mgm index builds.
+func scanODM(lines []string, relPath string) []ODMRef {
+	text := strings.Join(lines, "\n")
+	var refs []ODMRef
+	if strings.Contains(text, "mongoose") || strings.Contains(text, "Schema(") {
+		refs = append(refs, scanMongoose(lines, relPath)...)
+	}
+	if strings.Contains(text, "org.springframework.data.mongodb") {
+		refs = append(refs, scanSpringData(lines, relPath)...)
+	}
+	if strings.Contains(text, "mgm.") {
+		for i, line := range lines {
+			if m := mgmIndexSyncRe.FindStringSubmatch(line); m != nil {
+				refs = append(refs, ODMRef{ODM: ODMMgm, Kind: ODMIndexSync, Model: m[1], Collection: mgmCollectionName(m[1]),
+					Call: "Indexes()." + m[2], File: relPath, Line: i + 1})
+			}
+		}
+	}
+	return refs
+}
+
+// scanSpringConfig returns the auto-index-creation setting of a Spring Boot
+// application.properties or application.yml.
+func scanSpringConfig(path, repoPath string) ([]ODMRef, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	relPath, _ := filepath.Rel(repoPath, path)
+	var refs []ODMRef
+	sc := bufio.NewScanner(f)
+	for lineNum := 1; sc.Scan(); lineNum++ {
+		line := sc.Text()
+		if isCommentLine(line) {
+			continue
+		}
+		if m := springConfigAutoRe.FindStringSubmatch(line); m != nil {
+			refs = append(refs, ODMRef{ODM: ODMSpringData, Kind: ODMAutoIndex, Enabled: m[1] == "true", File: relPath, Line: lineNum})
+		}
+	}
+	return refs, sc.Err()
+}
+
+// isSpringConfig reports whether name is a Spring Boot configuration file.
+func isSpringConfig(name string) bool {
+	if !strings.HasPrefix(name, "application") {
+		return false
+	}
+	return strings.HasSuffix(name, ".properties") || strings.HasSuffix(name, ".yml") || strings.HasSuffix(name, ".yaml")
+}
+
+// mongooseSchema is a schema variable and what was read from its
+// definition.
+type mongooseSchema struct {
+	collection string  // from the collection option
+	timestamps *ODMRef // from the timestamps option
+	indexes    []ODMRef
+}
+
+func scanMongoose(lines []string, relPath string) []ODMRef {
+	schemas := make(map[string]*mongooseSchema)
+	var order []string
+	schemaFor := func(name string) *mongooseSchema {
+		s := schemas[name]
+		if s == nil {
+			s = &mongooseSchema{}
+			schemas[name] = s
+			order = append(order, name)
+		}
+		return s
+	}
+
+	var refs []ODMRef
+	for i, line := range lines {
+		if isCommentLine(line) {
+			continue
+		}
+		if loc := mongooseSchemaRe.FindStringSubmatchIndex(line); loc != nil {
+			s := schemaFor(line[loc[2]:loc[3]])
+			s.indexes = append(s.indexes, mongooseFieldIndexes(lines, i, loc[1]-1, relPath, s)...)
+		}
+		if m := mongooseSchemaIndexRe.FindStringSubmatchIndex(line); m != nil {
+			body := line[m[1]-1:]
+			keyDoc := body
+			if end := strings.IndexByte(keyDoc, '}'); end >= 0 {
+				keyDoc = keyDoc[:end]
+			}
+			var fields, text []string
+			for _, kv := range indexKeyPairRe.FindAllStringSubmatch(keyDoc, -1) {
+				fields = append(fields, kv[1])
+				if strings.Trim(kv[2], `"'`) == "text" {
+					text = append(text, kv[1])
+				}
+			}
+			if len(fields) > 0 {
+				s := schemaFor(line[m[2]:m[3]])
+				s.indexes = append(s.indexes, ODMRef{ODM: ODMMongoose, Kind: ODMIndex, Fields: fields, TextFields: text,
+					Unique: indexUniqueRe.MatchString(body), File: relPath, Line: i + 1})
+			}
+		}
+		if m := mongooseAutoIndexRe.FindStringSubmatch(line); m != nil {
+			refs = append(refs, ODMRef{ODM: ODMMongoose, Kind: ODMAutoIndex, Enabled: m[1] == "true", File: relPath, Line: i + 1})
+		}
+		if m := mongooseSyncRe.FindStringSubmatch(line); m != nil {
+			refs = append(refs, ODMRef{ODM: ODMMongoose, Kind: ODMIndexSync, Model: m[1], Call: m[2], File: relPath, Line: i + 1})
+		}
+	}
+
+	// Models bind schemas to collections: an explicit collection argument,
+	// else the schema's collection option, else the pluralized model name.
+	bound := make(map[string]string)
+	for i, line := range lines {
+		m := mongooseModelRe.FindStringSubmatch(line)
+		if m == nil || isCommentLine(line) {
+			continue
+		}
+		collection := m[4]
+		if collection == "" && schemas[m[3]] != nil {
+			collection = schemas[m[3]].collection
+		}
+		if collection == "" {
+			collection = mongoosePluralize(m[2])
+		}
+		model := m[1]
+		if model == "" {
+			model = m[2]
+		}
+		bound[m[3]] = collection
+		refs = append(refs, ODMRef{ODM: ODMMongoose, Kind: ODMModel, Model: model, Collection: collection, File: relPath, Line: i + 1})
+	}
+	for _, name := range order {
+		s := schemas[name]
+		if s.timestamps != nil {
+			s.indexes = append(s.indexes, *s.timestamps)
+		}
+		for _, ref := range s.indexes {
+			ref.Model = name
+			ref.Collection = bound[name]
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// readOptions stores the collection and timestamps options found in text,
+// a part of the schema options on line.
+func (s *mongooseSchema) readOptions(text, relPath string, line int) {
+	if m := schemaCollectionOptRe.FindStringSubmatch(text); m != nil {
+		s.collection = m[1]
+	}
+	if m := mongooseTimestampsRe.FindStringSubmatch(text); m != nil {
+		s.timestamps = mongooseTimestamps(m[1], relPath, line)
+	}
+}
+
+// mongooseTimestamps returns the paths a timestamps schema option adds:
+// createdAt and updatedAt, renamed or switched off in the object form.
+func mongooseTimestamps(option, relPath string, line int) *ODMRef {
+	paths := map[string]string{"createdAt": "createdAt", "updatedAt": "updatedAt"}
+	for _, m := range mongooseTimestampRe.FindAllStringSubmatch(option, -1) {
+		if m[2] == "false" {
+			delete(paths, m[1])
+		} else {
+			paths[m[1]] = m[3]
+		}
+	}
+	ref := &ODMRef{ODM: ODMMongoose, Kind: ODMTimestamps, File: relPath, Line: line}
+	for _, key := range []string{"createdAt", "updatedAt"} {
+		if p, ok := paths[key]; ok {
+			ref.Fields = append(ref.Fields, p)
+		}
+	}
+	return ref
+}
+
+// mongooseFieldIndexes reads the schema definition opened at column col
+// of line start and returns the paths declared with index: true,
+// unique: true, text: true or an expires TTL. The collection and
+// timestamps schema options are stored in s.
+func mongooseFieldIndexes(lines []string, start, col int, relPath string, s *mongooseSchema) []ODMRef {
+	end := bracketEnd(lines, start, col)
+	depth := 0        // open braces, counted from the schema definition
+	var keys []string // keys[d] is the key opened at depth d+1
+	closed := false   // past the definition, in the options
+	var refs []ODMRef
+	seen := make(map[string]int)
+	for j := start; j <= end; j++ {
+		line := lines[j]
+		from := 0
+		if j == start {
+			from = col
+		}
+		if isCommentLine(line) {
+			continue
+		}
+		if closed {
+			s.readOptions(line[from:], relPath, j+1)
+			continue
+		}
+		if depth >= 1 {
+			if m := schemaKeyRe.FindStringSubmatch(line[from:]); m != nil {
+				if len(keys) > depth-1 {
+					keys = keys[:depth-1]
+				}
+				for len(keys) < depth-1 {
+					keys = append(keys, "")
+				}
+				keys = append(keys, m[1])
+			}
+		}
+		matches := mongooseFieldIndexRe.FindAllStringSubmatchIndex(line, -1)
+		for len(matches) > 0 && matches[0][0] < from {
+			matches = matches[1:]
+		}
+		for p := from; p < len(line); p++ {
+			for len(matches) > 0 && matches[0][0] == p {
+				if path := strings.Join(keys[:min(depth-1, len(keys))], "."); depth >= 2 && path != "" {
+					option := ""
+					if matches[0][2] >= 0 {
+						option = line[matches[0][2]:matches[0][3]]
+					}
+					k, ok := seen[path]
+					if !ok {
+						k = len(refs)
+						seen[path] = k
+						refs = append(refs, ODMRef{ODM: ODMMongoose, Kind: ODMIndex, Fields: []string{path}, File: relPath, Line: j + 1})
+					}
+					switch option {
+					case "unique":
+						refs[k].Unique = true
+					case "text":
+						refs[k].TextFields = []string{path}
+					}
+				}
+				matches = matches[1:]
+			}
+			switch line[p] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+				if depth == 0 && !closed {
+					closed = true
+					s.readOptions(line[p:], relPath, j+1)
+				}
+			}
+		}
+	}
+	return refs
+}
+
+// scanSpringData reads the @Document collection, @Indexed fields,
+// @CompoundIndex definitions and index settings of a Java entity or
+// configuration class.
+func scanSpringData(lines []string, relPath string) []ODMRef {
+	collection := ""
+	for i, line := range lines {
+		m := springDocumentRe.FindStringSubmatch(line)
+		if m == nil || isCommentLine(line) {
+			continue
+		}
+		collection = m[1]
+		if collection == "" {
+			// Spring's default is the uncapitalized class name.
+			for j := i; j < len(lines) && j <= i+5; j++ {
+				if c := springClassRe.FindStringSubmatch(lines[j]); c != nil {
+					collection = strings.ToLower(c[1][:1]) + c[1][1:]
+					break
+				}
+			}
+		}
+		break
+	}
+
+	var refs []ODMRef
+	for i, line := range lines {
+		if isCommentLine(line) {
+			continue
+		}
+		if m := springIndexedRe.FindStringSubmatch(line); m != nil && collection != "" {
+			if field := springAnnotatedField(lines, i); field != "" {
+				refs = append(refs, ODMRef{ODM: ODMSpringData, Kind: ODMIndex, Collection: collection, Fields: []string{field},
+					Unique: springUniqueRe.MatchString(m[1]), File: relPath, Line: i + 1})
+			}
+		}
+		if m := springCompoundIndexRe.FindStringSubmatch(line); m != nil && collection != "" {
+			var fields []string
+			for _, kv := range indexKeyPairRe.FindAllStringSubmatch(m[1], -1) {
+				if kv[1] != "unique" {
+					fields = append(fields, kv[1])
+				}
+			}
+			if len(fields) > 0 {
+				refs = append(refs, ODMRef{ODM: ODMSpringData, Kind: ODMIndex, Collection: collection, Fields: fields,
+					Unique: springUniqueRe.MatchString(m[1]), File: relPath, Line: i + 1})
+			}
+		}
+		if m := springSetAutoIndexRe.FindStringSubmatch(line); m != nil {
+			refs = append(refs, ODMRef{ODM: ODMSpringData, Kind: ODMAutoIndex, Enabled: m[1] == "true", File: relPath, Line: i + 1})
+		}
+		if springAutoIndexRe.MatchString(line) {
+			for j := i; j < len(lines) && j <= i+3; j++ {
+				if strings.Contains(lines[j], "return true") || strings.Contains(lines[j], "return false") {
+					refs = append(refs, ODMRef{ODM: ODMSpringData, Kind: ODMAutoIndex, Enabled: strings.Contains(lines[j], "return true"),
+						File: relPath, Line: i + 1})
+					break
+				}
+			}
+		}
+		if m := springEnsureIndexRe.FindStringSubmatch(line); m != nil {
+			ref := ODMRef{ODM: ODMSpringData, Kind: ODMIndexSync, Model: m[1], Collection: m[2], Call: "indexOps()." + m[3], File: relPath, Line: i + 1}
+			if ref.Collection == "" && ref.Model != "" {
+				ref.Collection = strings.ToLower(ref.Model[:1]) + ref.Model[1:]
+			}
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// springAnnotatedField returns the document field of the declaration that
+// follows the annotation on line i: its @Field name if set, else the Java
+// field name.
+func springAnnotatedField(lines []string, i int) string {
+	name := ""
+	for j := i; j < len(lines) && j <= i+5; j++ {
+		if m := springFieldNameRe.FindStringSubmatch(lines[j]); m != nil {
+			name = m[1]
+		}
+		decl := lines[j]
+		if j == i {
+			// @Indexed private String email; on one line.
+			decl = springIndexedRe.ReplaceAllString(decl, "")
+		}
+		if strings.HasPrefix(strings.TrimSpace(decl), "@") {
+			continue
+		}
+		if m := springFieldDeclRe.FindStringSubmatch(decl); m != nil {
+			if name == "" {
+				name = m[1]
+			}
+			return name
+		}
+	}
+	return ""
+}
+
+// mgmCollectionName applies mgm's default collection naming: the model
+// struct name in snake case, pluralized ("BookStore" -> "book_stores").
+func mgmCollectionName(model string) string {
+	var b strings.Builder
+	for i, r := range model {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			prev := model[i-1]
+			if prev >= 'a' && prev <= 'z' || prev >= '0' && prev <= '9' {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(r)
+	}
+	return mongoosePluralize(b.String())
+}
@@ -0,0 +1,143 @@
+package scanner
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScanODM_Mongoose(t *testing.T) {
+	src := `const mongoose = require("mongoose");
+mongoose.set("autoIndex", false);
+
+const userSchema = new mongoose.Schema({
+  email: { type: String, required: true, unique: true },
+  name: String,
+  address: {
+    city: { type: String, index: true },
+  },
+  tags: [{ type: String, index: true }],
+}, { timestamps: true, collection: "accounts" });
+
+userSchema.index({ tenantId: 1, createdAt: -1 });
+const User = mongoose.model("User", userSchema);
+
+const orderSchema = new Schema({
+  // index: true
+  status: {
+    type: String,
+    index: true,
+  },
+  notes: { type: String, text: true },
+  expiresAt: { type: Date, expires: "1d" },
+}, { timestamps: { createdAt: "placedAt", updatedAt: false } });
+module.exports.Order = mongoose.model("Order", orderSchema, "sales");
+User.syncIndexes();
+orderSchema.index({ title: "text", body: "text", tenantId: 1 });
+`
+	refs := scanODM(strings.Split(src, "\n"), "models.js")
+	want := []ODMRef{
+		{ODM: ODMMongoose, Kind: ODMAutoIndex, Enabled: false, File: "models.js", Line: 2},
+		{ODM: ODMMongoose, Kind: ODMIndexSync, Model: "User", Call: "syncIndexes", File: "models.js", Line: 26},
+		{ODM: ODMMongoose, Kind: ODMModel, Model: "User", Collection: "accounts", File: "models.js", Line: 14},
+		{ODM: ODMMongoose, Kind: ODMModel, Model: "Order", Collection: "sales", File: "models.js", Line: 25},
+		{ODM: ODMMongoose, Kind: ODMIndex, Model: "userSchema", Collection: "accounts", Fields: []string{"email"}, Unique: true, File: "models.js", Line: 5},
+		{ODM: ODMMongoose, Kind: ODMIndex, Model: "userSchema", Collection: "accounts", Fields: []string{"address.city"}, File: "models.js", Line: 8},
+		{ODM: ODMMongoose, Kind: ODMIndex, Model: "userSchema", Collection: "accounts", Fields: []string{"tags"}, File: "models.js", Line: 10},
+		{ODM: ODMMongoose, Kind: ODMIndex, Model: "userSchema", Collection: "accounts", Fields: []string{"tenantId", "createdAt"}, File: "models.js", Line: 13},
+		{ODM: ODMMongoose, Kind: ODMTimestamps, Model: "userSchema", Collection: "accounts", Fields: []string{"createdAt", "updatedAt"}, File: "models.js", Line: 11},
+		{ODM: ODMMongoose, Kind: ODMIndex, Model: "orderSchema", Collection: "sales", Fields: []string{"status"}, File: "models.js", Line: 20},
+		{ODM: ODMMongoose, Kind: ODMIndex, Model: "orderSchema", Collection: "sales", Fields: []string{"notes"}, TextFields: []string{"notes"}, File: "models.js", Line: 22},
+		{ODM: ODMMongoose, Kind: ODMIndex, Model: "orderSchema", Collection: "sales", Fields: []string{"expiresAt"}, File: "models.js", Line: 23},
+		{ODM: ODMMongoose, Kind: ODMIndex, Model: "orderSchema", Collection: "sales", Fields: []string{"title", "body", "tenantId"}, TextFields: []string{"title", "body"}, File: "models.js", Line: 27},
+		{ODM: ODMMongoose, Kind: ODMTimestamps, Model: "orderSchema", Collection: "sales", Fields: []string{"placedAt"}, File: "models.js", Line: 24},
+	}
+	if !reflect.DeepEqual(refs, want) {
+		t.Errorf("refs =\n%+v\nwant\n%+v", refs, want)
+	}
+}
+
+func TestScanODM_SpringData(t *testing.T) {
+	src := `package com.example;
+
+import org.springframework.data.mongodb.core.index.Indexed;
+import org.springframework.data.mongodb.core.mapping.Document;
+
+@Document
+@CompoundIndex(name = "tenant_created", def = "{'tenantId': 1, 'createdAt': -1}", unique = true)
+public class CustomerOrder {
+    @Id
+    private String id;
+
+    @Indexed(unique = true)
+    @Field("order_no")
+    private String orderNumber;
+
+    @Indexed private String status;
+
+    private String note;
+}
+`
+	refs := scanODM(strings.Split(src, "\n"), "CustomerOrder.java")
+	want := []ODMRef{
+		{ODM: ODMSpringData, Kind: ODMIndex, Collection: "customerOrder", Fields: []string{"tenantId", "createdAt"}, Unique: true, File: "CustomerOrder.java", Line: 7},
+		{ODM: ODMSpringData, Kind: ODMIndex, Collection: "customerOrder", Fields: []string{"order_no"}, Unique: true, File: "CustomerOrder.java", Line: 12},
+		{ODM: ODMSpringData, Kind: ODMIndex, Collection: "customerOrder", Fields: []string{"status"}, File: "CustomerOrder.java", Line: 16},
+	}
+	if !reflect.DeepEqual(refs, want) {
+		t.Errorf("refs =\n%+v\nwant\n%+v", refs, want)
+	}
+
+	config := `import org.springframework.data.mongodb.config.AbstractMongoClientConfiguration;
+
+public class MongoConfig extends AbstractMongoClientConfiguration {
+    @Override
+    protected boolean autoIndexCreation() {
+        return true;
+    }
+
+    @EventListener(ApplicationReadyEvent.class)
+    public void initIndexes() {
+        mongoTemplate.indexOps(Invoice.class).ensureIndex(new Index().on("dueDate", Sort.Direction.ASC));
+    }
+}
+`
+	refs = scanODM(strings.Split(config, "\n"), "MongoConfig.java")
+	want = []ODMRef{
+		{ODM: ODMSpringData, Kind: ODMAutoIndex, Enabled: true, File: "MongoConfig.java", Line: 5},
+		{ODM: ODMSpringData, Kind: ODMIndexSync, Model: "Invoice", Collection: "invoice", Call: "indexOps().ensureIndex", File: "MongoConfig.java", Line: 11},
+	}
+	if !reflect.DeepEqual(refs, want) {
+		t.Errorf("config refs =\n%+v\nwant\n%+v", refs, want)
+	}
+}
+
+func TestScanODM_Mgm(t *testing.T) {
+	src := `func init() {
+	_, _ = mgm.Coll(&BookStore{}).Indexes().CreateMany(ctx, models)
+}`
+	refs := scanODM(strings.Split(src, "\n"), "db.go")
+	want := []ODMRef{{ODM: ODMMgm, Kind: ODMIndexSync, Model: "BookStore", Collection: "book_stores", Call: "Indexes().CreateMany", File: "db.go", Line: 2}}
+	if !reflect.DeepEqual(refs, want) {
+		t.Errorf("refs = %+v, want %+v", refs, want)
+	}
+}
+
+func TestScanSpringConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "src/main/resources/application.properties", "# spring.data.mongodb.auto-index-creation=false\nspring.data.mongodb.auto-index-creation=true\n")
+	writeFile(t, dir, "src/main/resources/application-prod.yml", "spring:\n  data:\n    mongodb:\n      auto-index-creation: false\n")
+	writeFile(t, dir, "config.yml", "auto-index-creation: true\n")
+
+	result, err := Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ODMRef{
+		{ODM: ODMSpringData, Kind: ODMAutoIndex, Enabled: false, File: "src/main/resources/application-prod.yml", Line: 4},
+		{ODM: ODMSpringData, Kind: ODMAutoIndex, Enabled: true, File: "src/main/resources/application.properties", Line: 2},
+	}
+	if !reflect.DeepEqual(result.ODMRefs, want) {
+		t.Errorf("ODMRefs = %+v, want %+v", result.ODMRefs, want)
+	}
+}
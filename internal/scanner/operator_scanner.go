@@ -0,0 +1,170 @@
+package scanner
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// operatorKindRe picks out the YAML files worth parsing as manifests.
+var operatorKindRe = regexp.MustCompile(`(?m)^kind:\s*["']?(MongoDBCommunity|MongoDB|MongoDBUser|AtlasDeployment|AtlasDatabaseUser)["']?\s*$`)
+
+// isYAMLFile reports whether name is a YAML file.
+func isYAMLFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".yml" || ext == ".yaml"
+}
+
+// scanOperatorManifest reads the MongoDB operator custom resources of a
+// Kubernetes manifest, which may hold several documents. Templates that
+// are not valid YAML, or leave the name to a template, yield no refs.
+func scanOperatorManifest(path, repoPath string) ([]OperatorRef, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !operatorKindRe.Match(data) {
+		return nil, nil
+	}
+	relPath, _ := filepath.Rel(repoPath, path)
+
+	var refs []OperatorRef
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return refs, nil
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		if ref, ok := operatorRef(doc.Content[0], relPath); ok {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+// operatorRef reads one custom resource.
+func operatorRef(n *yaml.Node, relPath string) (OperatorRef, bool) {
+	apiVersion := yamlScalar(yamlMapValue(n, "apiVersion"))
+	kindNode := yamlMapValue(n, "kind")
+	if !strings.Contains(apiVersion, "mongodb.com") || kindNode == nil {
+		return OperatorRef{}, false
+	}
+	spec := yamlMapValue(n, "spec")
+	ref := OperatorRef{
+		Kind: kindNode.Value,
+		Name: yamlScalar(yamlPath(n, "metadata", "name")),
+		File: relPath,
+		Line: kindNode.Line,
+	}
+	switch ref.Kind {
+	case CRDMongoDBCommunity, CRDMongoDB:
+		ref.Version = yamlScalar(yamlMapValue(spec, "version"))
+		ref.Members, _ = strconv.Atoi(yamlScalar(yamlMapValue(spec, "members")))
+		ref.Topology = yamlScalar(yamlMapValue(spec, "type"))
+		for _, u := range yamlItems(yamlMapValue(spec, "users")) {
+			ref.Users = append(ref.Users, OperatorUser{
+				Name:  yamlScalar(yamlMapValue(u, "name")),
+				DB:    yamlScalar(yamlMapValue(u, "db")),
+				Roles: operatorRoles(yamlMapValue(u, "roles"), "name", "db"),
+				Line:  u.Line,
+			})
+		}
+		ref.Roles = operatorRoles(yamlPath(spec, "security", "roles"), "role", "db")
+	case CRDMongoDBUser:
+		ref.Cluster = yamlScalar(yamlPath(spec, "mongodbResourceRef", "name"))
+		ref.Users = []OperatorUser{{
+			Name:  yamlScalar(yamlMapValue(spec, "username")),
+			DB:    yamlScalar(yamlMapValue(spec, "db")),
+			Roles: operatorRoles(yamlMapValue(spec, "roles"), "name", "db"),
+			Line:  ref.Line,
+		}}
+	case CRDAtlasDeployment:
+		deployment := yamlMapValue(spec, "deploymentSpec")
+		if deployment == nil {
+			deployment = yamlMapValue(spec, "advancedDeploymentSpec")
+		}
+		if name := yamlScalar(yamlMapValue(deployment, "name")); name != "" {
+			ref.Name = name
+		}
+		ref.Version = yamlScalar(yamlMapValue(deployment, "mongoDBMajorVersion"))
+		ref.Topology = yamlScalar(yamlMapValue(deployment, "clusterType"))
+	case CRDAtlasDatabaseUser:
+		// A user scoped to one cluster belongs to it; others to the project.
+		var clusters []string
+		for _, s := range yamlItems(yamlMapValue(spec, "scopes")) {
+			if strings.EqualFold(yamlScalar(yamlMapValue(s, "type")), "CLUSTER") {
+				clusters = append(clusters, yamlScalar(yamlMapValue(s, "name")))
+			}
+		}
+		if len(clusters) == 1 {
+			ref.Cluster = clusters[0]
+		}
+		ref.Users = []OperatorUser{{
+			Name:  yamlScalar(yamlMapValue(spec, "username")),
+			DB:    yamlScalar(yamlMapValue(spec, "databaseName")),
+			Roles: operatorRoles(yamlMapValue(spec, "roles"), "roleName", "databaseName"),
+			Line:  ref.Line,
+		}}
+	default:
+		return OperatorRef{}, false
+	}
+	if ref.Name == "" {
+		return OperatorRef{}, false // e.g. a templated name
+	}
+	for i := range ref.Users {
+		if ref.Users[i].DB == "" {
+			ref.Users[i].DB = "admin"
+		}
+	}
+	return ref, true
+}
+
+// operatorRoles reads a list of roles whose name and database are under
+// the given keys.
+func operatorRoles(n *yaml.Node, roleKey, dbKey string) []OperatorRole {
+	var roles []OperatorRole
+	for _, item := range yamlItems(n) {
+		role := OperatorRole{Role: yamlScalar(yamlMapValue(item, roleKey)), DB: yamlScalar(yamlMapValue(item, dbKey))}
+		if role.Role != "" {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// yamlPath returns the value at the path of keys below the mapping n.
+func yamlPath(n *yaml.Node, keys ...string) *yaml.Node {
+	for _, key := range keys {
+		n = yamlMapValue(n, key)
+	}
+	return n
+}
+
+// yamlItems returns the items of the sequence node n.
+func yamlItems(n *yaml.Node) []*yaml.Node {
+	if n == nil || n.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return n.Content
+}
+
+// yamlScalar returns the value of the scalar node n, or "".
+func yamlScalar(n *yaml.Node) string {
+	if n == nil || n.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return n.Value
+}
@@ -0,0 +1,110 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScanOperatorManifests(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "k8s/mongodb.yaml", `apiVersion: v1
+kind: Secret
+metadata:
+  name: app-password
+---
+apiVersion: mongodbcommunity.mongodb.com/v1
+kind: MongoDBCommunity
+metadata:
+  name: shop-db
+spec:
+  members: 3
+  type: ReplicaSet
+  version: "6.0.5"
+  security:
+    authentication:
+      modes: ["SCRAM"]
+    roles:
+      - role: orderReader
+        db: shop
+        privileges: []
+  users:
+    - name: app
+      db: admin
+      passwordSecretRef:
+        name: app-password
+      roles:
+        - name: readWrite
+          db: shop
+    - name: reporting
+      roles:
+        - name: orderReader
+          db: shop
+`)
+	writeFile(t, dir, "k8s/user.yml", `apiVersion: mongodb.com/v1
+kind: MongoDBUser
+metadata:
+  name: etl
+spec:
+  username: etl
+  db: admin
+  mongodbResourceRef:
+    name: shop-db
+  roles:
+    - name: read
+      db: shop
+`)
+	writeFile(t, dir, "atlas/deployment.yaml", `apiVersion: atlas.mongodb.com/v1
+kind: AtlasDeployment
+metadata:
+  name: prod-deployment
+spec:
+  deploymentSpec:
+    name: Cluster0
+    clusterType: REPLICASET
+    mongoDBMajorVersion: "7.0"
+---
+apiVersion: atlas.mongodb.com/v1
+kind: AtlasDatabaseUser
+metadata:
+  name: analytics-user
+spec:
+  username: analytics
+  databaseName: admin
+  roles:
+    - roleName: read
+      databaseName: shop
+  scopes:
+    - name: Cluster0
+      type: CLUSTER
+`)
+	writeFile(t, dir, "charts/mongodb/templates/db.yaml", `apiVersion: mongodbcommunity.mongodb.com/v1
+kind: MongoDBCommunity
+metadata:
+  name: {{ .Release.Name }}
+`)
+	writeFile(t, dir, "config/app.yaml", "kind: MongoDB\nname: not a manifest\n")
+
+	result, err := Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []OperatorRef{
+		{Kind: CRDAtlasDeployment, Name: "Cluster0", Version: "7.0", Topology: "REPLICASET", File: "atlas/deployment.yaml", Line: 2},
+		{Kind: CRDAtlasDatabaseUser, Name: "analytics-user", Cluster: "Cluster0", File: "atlas/deployment.yaml", Line: 12, Users: []OperatorUser{
+			{Name: "analytics", DB: "admin", Roles: []OperatorRole{{Role: "read", DB: "shop"}}, Line: 12},
+		}},
+		{Kind: CRDMongoDBCommunity, Name: "shop-db", Version: "6.0.5", Members: 3, Topology: "ReplicaSet", File: "k8s/mongodb.yaml", Line: 7,
+			Users: []OperatorUser{
+				{Name: "app", DB: "admin", Roles: []OperatorRole{{Role: "readWrite", DB: "shop"}}, Line: 22},
+				{Name: "reporting", DB: "admin", Roles: []OperatorRole{{Role: "orderReader", DB: "shop"}}, Line: 29},
+			},
+			Roles: []OperatorRole{{Role: "orderReader", DB: "shop"}},
+		},
+		{Kind: CRDMongoDBUser, Name: "etl", Cluster: "shop-db", File: "k8s/user.yml", Line: 2, Users: []OperatorUser{
+			{Name: "etl", DB: "admin", Roles: []OperatorRole{{Role: "read", DB: "shop"}}, Line: 2},
+		}},
+	}
+	if !reflect.DeepEqual(result.OperatorRefs, want) {
+		t.Errorf("OperatorRefs =\n%+v\nwant\n%+v", result.OperatorRefs, want)
+	}
+}
@@ -2,6 +2,7 @@ package scanner
 
 import (
 	"regexp"
+	"slices"
 	"strings"
 )
 
@@ -69,6 +70,8 @@ type fieldMatch struct {
 	Usage        FieldUsage
 	Direction    int
 	QueryContext string
+	Constant     string
+	Operators    []string
 }
 
 // ScanLineFields checks a single line for queried field names.
@@ -155,9 +158,17 @@ func ScanLineFields(line string) []fieldMatch {
 		addMatch(fieldMatch{Field: f, Usage: FieldUsageUnknown, QueryContext: queryContext})
 	}
 
+	constants := extractConstantPredicates(line)
+	operators := extractFieldOperators(line)
+
 	out := make([]fieldMatch, 0, len(order))
 	for _, field := range order {
-		out = append(out, byField[field])
+		m := byField[field]
+		if m.Usage == FieldUsageEquality {
+			m.Constant = constants[field]
+		}
+		m.Operators = operators[field]
+		out = append(out, m)
 	}
 
 	return out
@@ -190,9 +201,39 @@ var sortPairRe = regexp.MustCompile(`["']?([a-zA-Z_][a-zA-Z0-9_.]*)["']?\s*:\s*(
 // rangeFieldRe extracts fields used with range-like operators.
 var rangeFieldRe = regexp.MustCompile(`["']?([a-zA-Z_][a-zA-Z0-9_.]*)["']?\s*:\s*\{\s*["']?\$(?:gt|gte|lt|lte|ne|nin|in|regex|not)\b`)
 
+// fieldOperatorsRe extracts a field and the body of the operator document
+// applied to it: "age": {"$gte": 18, "$lt": 65} or "age": bson.M{"$gt": 5}.
+var fieldOperatorsRe = regexp.MustCompile(`["']?([a-zA-Z_][a-zA-Z0-9_.]*)["']?\s*:\s*(?:bson\.[MD])?\{(\s*["']?\$[^{}]*)\}`)
+
+// bsonDOperatorRe extracts the same from Go bson.D elements:
+// {Key: "age", Value: bson.D{{Key: "$gt", Value: 5}}}.
+var bsonDOperatorRe = regexp.MustCompile(`Key:\s*"([a-zA-Z_][a-zA-Z0-9_.]*)",\s*Value:\s*bson\.[DM]\{\{?(?:Key:\s*)?"(\$\w+)"`)
+
+// operatorNameRe extracts operator names from an operator document body.
+var operatorNameRe = regexp.MustCompile(`["']?\$(\w+)["']?\s*:`)
+
+// constantPredicateRe extracts fields compared to a string or boolean literal,
+// the predicates a partialFilterExpression can express.
+var constantPredicateRe = regexp.MustCompile(`["']?([a-zA-Z_][a-zA-Z0-9_.]*)["']?\s*:\s*("[^"]*"|'[^']*'|\btrue\b|\bfalse\b|\bTrue\b|\bFalse\b)`)
+
+// bsonDConstantRe extracts the same from Go bson.D elements: {Key: "status", Value: "active"}.
+var bsonDConstantRe = regexp.MustCompile(`Key:\s*"([a-zA-Z_][a-zA-Z0-9_.]*)",\s*Value:\s*("[^"]*"|\btrue\b|\bfalse\b)`)
+
+// updateOperatorRe matches update operators whose documents hold new values
+// rather than predicates.
+var updateOperatorRe = regexp.MustCompile(`["']?\$(?:set|setOnInsert|inc|push|addToSet|unset|rename)\b`)
+
 // queryContextRe extracts the primary call name for grouping query contexts.
 var queryContextRe = regexp.MustCompile(`\.(findOneAndUpdate|findOneAndDelete|findOneAndReplace|findOne|find_one|find|updateOne|updateMany|update_one|update_many|deleteOne|deleteMany|delete_one|delete_many|countDocuments|count_documents|aggregate|sort)\(`)
 
+// readOperationRe matches driver calls that return documents to the caller.
+var readOperationRe = regexp.MustCompile(`(?i)\.(find|findone|find_one|findoneandupdate|find_one_and_update|findoneandreplace|find_one_and_replace|findoneanddelete|find_one_and_delete|aggregate|countdocuments|count_documents|estimateddocumentcount|estimated_document_count|distinct|watch)\(`)
+
+// IsReadOperation reports whether a line contains a MongoDB read operation.
+func IsReadOperation(line string) bool {
+	return readOperationRe.MatchString(line)
+}
+
 // extractObjectKeys pulls all keys from object literals on lines that
 // look like MongoDB query calls. This catches the second, third, etc. keys
 // in multi-field queries like .find({"status": 1, "created_at": -1}).
@@ -215,6 +256,63 @@ func extractObjectKeys(line string) []string {
 	return fields
 }
 
+// extractConstantPredicates maps fields to the literal they are compared to
+// on query lines, normalized to "value", true or false. Lines containing
+// update operators or inserts are skipped since their literals are values
+// being written, not filters.
+func extractConstantPredicates(line string) map[string]string {
+	if !objectKeyContextRe.MatchString(line) && !pipelineStageContextRe.MatchString(line) && !strings.Contains(line, "bson.") {
+		return nil
+	}
+	if updateOperatorRe.MatchString(line) || strings.Contains(strings.ToLower(line), "insert") {
+		return nil
+	}
+	out := make(map[string]string)
+	for _, m := range constantPredicateRe.FindAllStringSubmatch(line, -1) {
+		field, value := m[1], m[2]
+		if !isValidFieldName(field) {
+			continue
+		}
+		switch {
+		case strings.HasPrefix(value, "'"):
+			value = `"` + strings.Trim(value, "'") + `"`
+		case value == "True" || value == "False":
+			value = strings.ToLower(value)
+		}
+		out[field] = value
+	}
+	for _, m := range bsonDConstantRe.FindAllStringSubmatch(line, -1) {
+		if isValidFieldName(m[1]) {
+			out[m[1]] = m[2]
+		}
+	}
+	return out
+}
+
+// extractFieldOperators maps fields to the query operators applied to them
+// on a line, without the "$": {"age": {"$gte": 18}} gives age: [gte].
+func extractFieldOperators(line string) map[string][]string {
+	if !strings.Contains(line, "$") {
+		return nil
+	}
+	out := make(map[string][]string)
+	add := func(field, op string) {
+		if !isValidFieldName(field) || slices.Contains(out[field], op) {
+			return
+		}
+		out[field] = append(out[field], op)
+	}
+	for _, m := range fieldOperatorsRe.FindAllStringSubmatch(line, -1) {
+		for _, op := range operatorNameRe.FindAllStringSubmatch(m[2], -1) {
+			add(m[1], op[1])
+		}
+	}
+	for _, m := range bsonDOperatorRe.FindAllStringSubmatch(line, -1) {
+		add(m[1], strings.TrimPrefix(m[2], "$"))
+	}
+	return out
+}
+
 // extractSortFields extracts sort keys and directions from query lines.
 func extractSortFields(line string) []fieldMatch {
 	var fields []fieldMatch
@@ -1,6 +1,7 @@
 package scanner
 
 import (
+	"reflect"
 	"sort"
 	"testing"
 )
@@ -266,3 +267,50 @@ func TestIsValidFieldName(t *testing.T) {
 		t.Error("dotted field should be valid")
 	}
 }
+
+func TestScanLineFields_ConstantPredicates(t *testing.T) {
+	tests := []struct {
+		line string
+		want map[string]string
+	}{
+		{`coll.Find(ctx, bson.M{"status": "active", "user_id": uid})`, map[string]string{"status": `"active"`, "user_id": ""}},
+		{`db.orders.find({deleted: false, total: {$gt: 10}})`, map[string]string{"deleted": "false", "total": ""}},
+		{`db.users.find_one({'state': 'open', 'verified': True})`, map[string]string{"state": `"open"`, "verified": "true"}},
+		{`coll.Find(ctx, bson.D{{Key: "archived", Value: false}})`, map[string]string{"archived": "false"}},
+		// Literals in update documents are values, not predicates.
+		{`db.users.updateOne({_id: id}, {$set: {status: "active"}})`, map[string]string{"status": ""}},
+	}
+	for _, tt := range tests {
+		got := make(map[string]string)
+		for _, m := range ScanLineFields(tt.line) {
+			got[m.Field] = m.Constant
+		}
+		for field, want := range tt.want {
+			if got[field] != want {
+				t.Errorf("ScanLineFields(%q) constant for %s = %q, want %q", tt.line, field, got[field], want)
+			}
+		}
+	}
+}
+
+func TestScanLineFields_Operators(t *testing.T) {
+	tests := []struct {
+		line string
+		want map[string][]string
+	}{
+		{`db.patients.find({"dob": {"$gte": start, "$lt": end}, "ssn": ssn})`, map[string][]string{"dob": {"gte", "lt"}}},
+		{`coll.Find(ctx, bson.M{"name": bson.M{"$regex": "^A"}})`, map[string][]string{"name": {"regex"}}},
+		{`coll.Find(ctx, bson.D{{Key: "age", Value: bson.D{{Key: "$gt", Value: 5}}}})`, map[string][]string{"age": {"gt"}}},
+	}
+	for _, tt := range tests {
+		got := make(map[string][]string)
+		for _, m := range ScanLineFields(tt.line) {
+			if m.Operators != nil {
+				got[m.Field] = m.Operators
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("operators of %q = %v, want %v", tt.line, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package scanner
+
+import (
+	"regexp"
+	"strings"
+)
+
+// readPrefOptionRe matches a read preference option or argument:
+// readPreference: "secondary", read_preference=..., ?readPreference=nearest,
+// and Mongoose .read("secondaryPreferred").
+var readPrefOptionRe = regexp.MustCompile(`(?i)(?:\bread_?preference["']?\s*[:=(]\s*|\.read\(\s*)["']?(primary_?preferred|secondary_?preferred|secondary|nearest|primary)\b`)
+
+// readPrefConstRe matches driver read preference constants and helpers:
+// ReadPreference.SECONDARY (Python, Java), readpref.Nearest() (Go).
+var readPrefConstRe = regexp.MustCompile(`(?i)\bread_?pref(?:erence)?s?\.(primary_?preferred|secondary_?preferred|secondary|nearest|primary)\b`)
+
+// readConcernRe matches a read concern level: readConcern: { level:
+// "majority" }, ReadConcern("majority"), readConcernLevel=majority,
+// readconcern.Majority() and ReadConcern.MAJORITY.
+var readConcernRe = regexp.MustCompile(`(?i)\bread_?concern(?:_?level)?(?:["']?\s*[:=(]\s*(?:\{\s*["']?level["']?\s*[:=]\s*)?["']?|\.)(majority|local|available|linearizable|snapshot)\b`)
+
+// functionStartRe matches the first line of a function or method.
+var functionStartRe = regexp.MustCompile(`^\s*(?:export\s+)?(?:(?:public|private|protected|internal|static|async|override|final|virtual)\s+)*(?:func|def|function)\b|^\s*(?:(?:public|private|protected|internal|static|async|override|final|virtual)\s+)+[\w<>\[\],.? ]+\s+\w+\s*\(|=>\s*\{\s*$`)
+
+// functionEndRe matches a closing brace in the first column, which ends a
+// top-level function or block in brace languages.
+var functionEndRe = regexp.MustCompile(`^\}`)
+
+// maxFlowLines limits how far back a read's flow is followed.
+const maxFlowLines = 60
+
+// readSettingMatch is the read preference and read concern one expression
+// sets. Mode and Level are lower case without underscores, as in
+// "secondarypreferred".
+type readSettingMatch struct {
+	Mode  string
+	Level string
+}
+
+// ScanLineReadSettings extracts the read preference mode and read concern
+// level set in an expression, reporting false when it sets neither.
+func ScanLineReadSettings(line string) (readSettingMatch, bool) {
+	var m readSettingMatch
+	if r := readPrefOptionRe.FindStringSubmatch(line); r != nil {
+		m.Mode = r[1]
+	} else if r := readPrefConstRe.FindStringSubmatch(line); r != nil {
+		m.Mode = r[1]
+	}
+	m.Mode = strings.ToLower(strings.ReplaceAll(m.Mode, "_", ""))
+	if r := readConcernRe.FindStringSubmatch(line); r != nil {
+		m.Level = strings.ToLower(r[1])
+	}
+	return m, m.Mode != "" || m.Level != ""
+}
+
+// readFlow locates the line at index i in its flow: the 0-based first line
+// of the enclosing function, or of the top-level code around it, and the
+// nearest earlier line in that flow that writes to MongoDB, or -1.
+// Top-level code starts at the top of the file or after the closing brace
+// of the function above it, so each stretch of it is a flow of its own.
+// When no bound is found within maxFlowLines, the flow is unknown and both
+// are -1, rather than taking a write in another function for the read's.
+func readFlow(lines []string, i int) (flow, write int) {
+	write = -1
+	for j := i - 1; j >= 0; j-- {
+		if i-j > maxFlowLines {
+			return -1, -1
+		}
+		if functionEndRe.MatchString(lines[j]) {
+			return j + 1, write
+		}
+		if write < 0 && IsWriteOperation(lines[j]) {
+			write = j
+		}
+		if functionStartRe.MatchString(lines[j]) {
+			return j, write
+		}
+	}
+	return 0, write
+}
@@ -0,0 +1,75 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestScanLineReadSettings(t *testing.T) {
+	tests := []struct {
+		line string
+		want readSettingMatch
+		ok   bool
+	}{
+		{`const o = await db.collection("orders").findOne({ _id: id }, { readPreference: "secondary" });`, readSettingMatch{Mode: "secondary"}, true},
+		{`orders = db.get_collection("orders", read_preference=ReadPreference.SECONDARY_PREFERRED)`, readSettingMatch{Mode: "secondarypreferred"}, true},
+		{`opts := options.Collection().SetReadPreference(readpref.Nearest()).SetReadConcern(readconcern.Majority())`, readSettingMatch{Mode: "nearest", Level: "majority"}, true},
+		{`coll.withReadConcern(ReadConcern.MAJORITY).withReadPreference(ReadPreference.secondary());`, readSettingMatch{Mode: "secondary", Level: "majority"}, true},
+		{`db.orders.with_options(read_concern=ReadConcern("local"))`, readSettingMatch{Level: "local"}, true},
+		{`readConcern: { level: "majority" },`, readSettingMatch{Level: "majority"}, true},
+		{`Order.find({ user }).read("secondaryPreferred")`, readSettingMatch{Mode: "secondarypreferred"}, true},
+		{`"mongodb://db1/app?replicaSet=rs0&readPreference=secondary&readConcernLevel=majority"`, readSettingMatch{Mode: "secondary", Level: "majority"}, true},
+		{`const data = fs.readFileSync(path);`, readSettingMatch{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ScanLineReadSettings(tt.line)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ScanLineReadSettings(%q) = %+v, %v; want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestScan_ReadSettingRefs(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "orders.js", `async function placeOrder(db, order) {
+  await db.collection("orders").insertOne(order);
+  return db.collection("orders").findOne({ _id: order._id }, { readPreference: "secondary" });
+}
+
+async function listOrders(db, user) {
+  return db.collection("orders").find({ user }, { readPreference: "secondaryPreferred" }).toArray();
+}
+
+const client = new MongoClient(uri, { readConcern: { level: "majority" } });
+function audit(db) {
+  db.collection("audit").insertOne({ at: new Date() });
+}
+db.collection("audit").find({}, { readPreference: "nearest" });
+`)
+
+	result, err := Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ReadSettingRef{
+		{Collection: "orders", Mode: "secondary", Function: 1, AfterWrite: 2, File: "orders.js", Line: 3},
+		{Collection: "orders", Mode: "secondarypreferred", Function: 6, File: "orders.js", Line: 7},
+		{Level: "majority", Function: 9, File: "orders.js", Line: 10},
+		// Top-level code after audit() is a flow of its own, without the write.
+		{Collection: "audit", Mode: "nearest", Function: 14, File: "orders.js", Line: 14},
+	}
+	if !reflect.DeepEqual(result.ReadSettingRefs, want) {
+		t.Errorf("read settings = %+v, want %+v", result.ReadSettingRefs, want)
+	}
+}
+
+func TestReadFlowUnknownBeyondLimit(t *testing.T) {
+	lines := []string{`  db.collection("orders").insertOne(order);`}
+	for range maxFlowLines {
+		lines = append(lines, "  total += 1;")
+	}
+	lines = append(lines, `  db.collection("orders").find({}, { readPreference: "secondary" });`)
+	if flow, write := readFlow(lines, len(lines)-1); flow != -1 || write != -1 {
+		t.Errorf("readFlow = %d, %d; want -1, -1 when no function start is in reach", flow, write)
+	}
+}
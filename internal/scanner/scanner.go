@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 )
@@ -32,6 +33,7 @@ var skipDirs = map[string]bool{
 	"dist":         true,
 	"build":        true,
 	"bin":          true,
+	".terraform":   true,
 }
 
 // Scan walks a directory tree and finds all MongoDB collection references.
@@ -50,22 +52,69 @@ func Scan(repoPath string) (ScanResult, error) {
 			return nil
 		}
 
+		if driverManifestRes[d.Name()] != nil {
+			refs, manifestErr := scanDriverManifest(path, repoPath)
+			if manifestErr != nil {
+				result.FilesSkipped++
+				return nil
+			}
+			result.DriverRefs = append(result.DriverRefs, refs...)
+			return nil
+		}
+
+		if isSpringConfig(d.Name()) {
+			refs, configErr := scanSpringConfig(path, repoPath)
+			if configErr != nil {
+				result.FilesSkipped++
+				return nil
+			}
+			result.ODMRefs = append(result.ODMRefs, refs...)
+			return nil
+		}
+
+		if isIaCFile(d.Name()) {
+			refs, iacErr := scanIaC(path, repoPath)
+			if iacErr != nil {
+				result.FilesSkipped++
+				return nil
+			}
+			result.IaCRefs = append(result.IaCRefs, refs...)
+			return nil
+		}
+
+		if rel, _ := filepath.Rel(repoPath, path); isFixturePath(rel) {
+			refs, fixtureErr := scanFixture(path, repoPath)
+			if fixtureErr != nil {
+				result.FilesSkipped++
+				return nil
+			}
+			result.FixtureRefs = append(result.FixtureRefs, refs...)
+			return nil
+		}
+
+		if isYAMLFile(d.Name()) {
+			refs, manifestErr := scanOperatorManifest(path, repoPath)
+			if manifestErr != nil {
+				result.FilesSkipped++
+				return nil
+			}
+			result.OperatorRefs = append(result.OperatorRefs, refs...)
+			return nil
+		}
+
 		ext := strings.ToLower(filepath.Ext(path))
 		if !supportedExtensions[ext] {
 			return nil
 		}
 
-		refs, fieldRefs, writeRefs, dynRefs, scanErr := scanFile(path, repoPath)
+		fileRefs, scanErr := scanFile(path, repoPath)
 		if scanErr != nil {
 			result.FilesSkipped++
 			return nil
 		}
 
 		result.FilesScanned++
-		result.Refs = append(result.Refs, refs...)
-		result.FieldRefs = append(result.FieldRefs, fieldRefs...)
-		result.WriteRefs = append(result.WriteRefs, writeRefs...)
-		result.DynamicRefs = append(result.DynamicRefs, dynRefs...)
+		appendRefs(&result, &fileRefs)
 		return nil
 	})
 	if err != nil {
@@ -76,12 +125,32 @@ func Scan(repoPath string) (ScanResult, error) {
 	return result, nil
 }
 
-// scanFile reads a file, joins multi-line expressions, and returns collection refs,
-// field refs, and dynamic (unresolvable variable) refs.
-func scanFile(path, repoPath string) ([]CollectionRef, []FieldRef, []WriteRef, []DynamicRef, error) {
+// appendRefs appends the ref slices of src to dst.
+func appendRefs(dst, src *ScanResult) {
+	dst.Refs = append(dst.Refs, src.Refs...)
+	dst.FieldRefs = append(dst.FieldRefs, src.FieldRefs...)
+	dst.WriteRefs = append(dst.WriteRefs, src.WriteRefs...)
+	dst.ReadRefs = append(dst.ReadRefs, src.ReadRefs...)
+	dst.IndexRefs = append(dst.IndexRefs, src.IndexRefs...)
+	dst.UpsertRefs = append(dst.UpsertRefs, src.UpsertRefs...)
+	dst.SearchRefs = append(dst.SearchRefs, src.SearchRefs...)
+	dst.EmbeddingRefs = append(dst.EmbeddingRefs, src.EmbeddingRefs...)
+	dst.TransactionRefs = append(dst.TransactionRefs, src.TransactionRefs...)
+	dst.WriteConcernRefs = append(dst.WriteConcernRefs, src.WriteConcernRefs...)
+	dst.ReadSettingRefs = append(dst.ReadSettingRefs, src.ReadSettingRefs...)
+	dst.AppNameRefs = append(dst.AppNameRefs, src.AppNameRefs...)
+	dst.ODMRefs = append(dst.ODMRefs, src.ODMRefs...)
+	dst.EncryptedFieldRefs = append(dst.EncryptedFieldRefs, src.EncryptedFieldRefs...)
+	dst.CredentialURIRefs = append(dst.CredentialURIRefs, src.CredentialURIRefs...)
+	dst.DynamicRefs = append(dst.DynamicRefs, src.DynamicRefs...)
+}
+
+// scanFile reads a file, joins multi-line expressions, and returns the
+// references found in it. Only the ref slices of the result are set.
+func scanFile(path, repoPath string) (ScanResult, error) {
 	f, err := os.Open(path)
 	if err != nil {
-		return nil, nil, nil, nil, err
+		return ScanResult{}, err
 	}
 	defer func() { _ = f.Close() }()
 
@@ -96,17 +165,30 @@ func scanFile(path, repoPath string) ([]CollectionRef, []FieldRef, []WriteRef, [
 		lines = append(lines, sc.Text())
 	}
 	if err := sc.Err(); err != nil {
-		return nil, nil, nil, nil, err
+		return ScanResult{}, err
 	}
 
 	joined := joinContinuationLines(lines)
 	stringVars := collectStringVars(joined)
 
-	var refs []CollectionRef
-	var fieldRefs []FieldRef
-	var writeRefs []WriteRef
-	var dynamicRefs []DynamicRef
+	var out ScanResult
+	out.EncryptedFieldRefs = scanEncryptedFields(lines, relPath)
+	out.ODMRefs = scanODM(lines, relPath)
 	seenDynamic := make(map[string]bool)
+	// lineCollections maps each line to the collection named in the
+	// expression it belongs to, for stages read from raw lines.
+	lineCollections := make([]string, len(lines))
+
+	for i, line := range lines {
+		for _, m := range scanLineCredentialURIs(line) {
+			out.CredentialURIRefs = append(out.CredentialURIRefs, CredentialURIRef{
+				User: m.User,
+				URI:  m.Redacted,
+				File: relPath,
+				Line: i + 1,
+			})
+		}
+	}
 
 	for _, jl := range joined {
 		lineMatches := ScanLine(jl.text)
@@ -118,7 +200,7 @@ func scanFile(path, repoPath string) ([]CollectionRef, []FieldRef, []WriteRef, [
 			for _, v := range dynamicVars {
 				if !seenDynamic[v] {
 					seenDynamic[v] = true
-					dynamicRefs = append(dynamicRefs, DynamicRef{
+					out.DynamicRefs = append(out.DynamicRefs, DynamicRef{
 						Variable: v,
 						File:     relPath,
 						Line:     jl.lineNum,
@@ -129,7 +211,7 @@ func scanFile(path, repoPath string) ([]CollectionRef, []FieldRef, []WriteRef, [
 
 		var lineCollection string
 		for _, m := range lineMatches {
-			refs = append(refs, CollectionRef{
+			out.Refs = append(out.Refs, CollectionRef{
 				Collection: m.Collection,
 				File:       relPath,
 				Line:       jl.lineNum,
@@ -141,8 +223,11 @@ func scanFile(path, repoPath string) ([]CollectionRef, []FieldRef, []WriteRef, [
 		}
 
 		if lineCollection != "" {
+			for n := jl.lineNum - 1; n < jl.endLine; n++ {
+				lineCollections[n] = lineCollection
+			}
 			for _, fm := range ScanLineFields(jl.text) {
-				fieldRefs = append(fieldRefs, FieldRef{
+				out.FieldRefs = append(out.FieldRefs, FieldRef{
 					Collection:   lineCollection,
 					Field:        fm.Field,
 					File:         relPath,
@@ -150,20 +235,47 @@ func scanFile(path, repoPath string) ([]CollectionRef, []FieldRef, []WriteRef, [
 					Usage:        fm.Usage,
 					Direction:    fm.Direction,
 					QueryContext: fm.QueryContext,
+					Constant:     fm.Constant,
+					Operators:    fm.Operators,
+				})
+			}
+			if def, ok := ScanLineIndexDef(jl.text); ok {
+				out.IndexRefs = append(out.IndexRefs, IndexRef{
+					Collection: lineCollection,
+					Fields:     def.Fields,
+					TextFields: def.TextFields,
+					Unique:     def.Unique,
+					File:       relPath,
+					Line:       jl.lineNum,
+				})
+			}
+			if keys := ScanLineUpsertKeys(jl.text); len(keys) > 0 {
+				out.UpsertRefs = append(out.UpsertRefs, UpsertRef{
+					Collection: lineCollection,
+					Fields:     keys,
+					File:       relPath,
+					Line:       jl.lineNum,
+				})
+			}
+			if IsReadOperation(jl.text) {
+				out.ReadRefs = append(out.ReadRefs, ReadRef{
+					Collection: lineCollection,
+					File:       relPath,
+					Line:       jl.lineNum,
 				})
 			}
 			if IsWriteOperation(jl.text) {
 				writes := ScanLineWriteFields(jl.text)
 				if len(writes) == 0 {
 					// Record collection-level write intent even when field extraction fails.
-					writeRefs = append(writeRefs, WriteRef{
+					out.WriteRefs = append(out.WriteRefs, WriteRef{
 						Collection: lineCollection,
 						File:       relPath,
 						Line:       jl.lineNum,
 					})
 				}
 				for _, w := range writes {
-					writeRefs = append(writeRefs, WriteRef{
+					out.WriteRefs = append(out.WriteRefs, WriteRef{
 						Collection: lineCollection,
 						Field:      w.Field,
 						ValueType:  w.ValueType,
@@ -173,14 +285,75 @@ func scanFile(path, repoPath string) ([]CollectionRef, []FieldRef, []WriteRef, [
 				}
 			}
 		}
+		if wc, ok := ScanLineWriteConcern(jl.text); ok {
+			out.WriteConcernRefs = append(out.WriteConcernRefs, WriteConcernRef{
+				Collection: lineCollection,
+				W:          wc.W,
+				NoJournal:  wc.NoJournal,
+				WTimeout:   wc.WTimeout,
+				File:       relPath,
+				Line:       jl.lineNum,
+			})
+		}
+		if rs, ok := ScanLineReadSettings(jl.text); ok {
+			flow, write := readFlow(lines, jl.lineNum-1)
+			out.ReadSettingRefs = append(out.ReadSettingRefs, ReadSettingRef{
+				Collection: lineCollection,
+				Mode:       rs.Mode,
+				Level:      rs.Level,
+				Function:   flow + 1,
+				AfterWrite: write + 1,
+				File:       relPath,
+				Line:       jl.lineNum,
+			})
+		}
 	}
-	return refs, fieldRefs, writeRefs, dynamicRefs, nil
+
+	for i := range lines {
+		if st, ok := scanSearchStage(lines, i); ok {
+			out.SearchRefs = append(out.SearchRefs, SearchRef{
+				Collection:    stageCollection(lines, lineCollections, i),
+				Stage:         st.Stage,
+				Index:         st.Index,
+				Paths:         st.Paths,
+				NumCandidates: st.NumCandidates,
+				Limit:         st.Limit,
+				FilterFields:  st.FilterFields,
+				File:          relPath,
+				Line:          i + 1,
+			})
+		}
+		for _, name := range ScanLineAppNames(lines[i]) {
+			out.AppNameRefs = append(out.AppNameRefs, AppNameRef{Name: name, File: relPath, Line: i + 1})
+		}
+		if em, ok := ScanLineEmbedding(lines[i]); ok {
+			out.EmbeddingRefs = append(out.EmbeddingRefs, EmbeddingRef{
+				Model:      em.Model,
+				Dimensions: em.Dimensions,
+				File:       relPath,
+				Line:       i + 1,
+			})
+		}
+	}
+	for _, tx := range scanTransactions(lines) {
+		ref := TransactionRef{Risks: tx.Risks, File: relPath, Line: tx.Start + 1, EndLine: tx.End + 1}
+		for _, r := range out.Refs {
+			if r.Line >= ref.Line && r.Line <= ref.EndLine && !slices.Contains(ref.Collections, r.Collection) {
+				ref.Collections = append(ref.Collections, r.Collection)
+			}
+		}
+		slices.Sort(ref.Collections)
+		out.TransactionRefs = append(out.TransactionRefs, ref)
+	}
+	return out, nil
 }
 
-// joinedLine holds a possibly multi-line expression with its starting line number.
+// joinedLine holds a possibly multi-line expression with its first and
+// last line numbers.
 type joinedLine struct {
 	text    string
 	lineNum int
+	endLine int
 }
 
 // maxJoinLines limits how many lines can be joined into a single expression.
@@ -214,6 +387,7 @@ func joinContinuationLines(lines []string) []joinedLine {
 			result = append(result, joinedLine{
 				text:    buf.String(),
 				lineNum: startLine,
+				endLine: i + 1,
 			})
 			depth = 0
 		}
@@ -224,6 +398,7 @@ func joinContinuationLines(lines []string) []joinedLine {
 		result = append(result, joinedLine{
 			text:    buf.String(),
 			lineNum: startLine,
+			endLine: len(lines),
 		})
 	}
 
@@ -233,6 +408,17 @@ func joinContinuationLines(lines []string) []joinedLine {
 // parenBalance counts the net parenthesis depth change for a line,
 // skipping characters inside string literals and line comments.
 func parenBalance(s string) int {
+	return balance(s, "(", ")")
+}
+
+// bracketBalance is parenBalance for parentheses, brackets and braces.
+func bracketBalance(s string) int {
+	return balance(s, "([{", ")]}")
+}
+
+// balance counts the net depth change for a line of the opening and
+// closing characters in opens and closes.
+func balance(s, opens, closes string) int {
 	depth := 0
 	inStr := byte(0)
 
@@ -262,10 +448,12 @@ func parenBalance(s string) int {
 			if i+1 < len(s) && s[i+1] == '/' {
 				return depth // rest is a line comment
 			}
-		case '(':
-			depth++
-		case ')':
-			depth--
+		default:
+			if strings.IndexByte(opens, c) >= 0 {
+				depth++
+			} else if strings.IndexByte(closes, c) >= 0 {
+				depth--
+			}
 		}
 	}
 	return depth
@@ -306,3 +306,26 @@ func TestScan_WriteRefs(t *testing.T) {
 		t.Fatalf("profile write type = %q, want object", types["profile"])
 	}
 }
+
+func TestScan_ReadRefs(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "app.js", `db.collection("users").find({"email": "a@x.com"})
+db.collection("events").insertOne({"type": "click"})
+db.orders.aggregate([{"$match": {"status": "open"}}])`)
+
+	result, err := Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	read := make(map[string]bool)
+	for _, ref := range result.ReadRefs {
+		read[ref.Collection] = true
+	}
+	if !read["users"] || !read["orders"] {
+		t.Fatalf("read refs = %+v, want users and orders", result.ReadRefs)
+	}
+	if read["events"] {
+		t.Fatal("insertOne should not be recorded as a read")
+	}
+}
@@ -0,0 +1,170 @@
+package scanner
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// searchStageRe matches Atlas Search and vector search stage keys, quoted
+// or, in JavaScript, bare, up to the opening brace of the stage document.
+// Requiring a document skips the string operand of legacy {$text: {$search: "..."}}.
+var searchStageRe = regexp.MustCompile("(?:^|[^\\w$])[\"'`]?\\$(search|searchMeta|vectorSearch)[\"'`]?\\s*[:,]\\s*(?:Value:\\s*)?(?:bson\\.[DM])?\\{")
+
+// searchOptionRe returns a regex matching an option key of a stage document,
+// as "key": or Go bson.D {Key: "key", Value: ...}, up to its value.
+func searchOptionRe(key string) string {
+	return `(?:(?:^|[^\w$])["']?` + key + `["']?\s*:|Key:\s*"` + key + `",\s*Value:)\s*`
+}
+
+var (
+	searchIndexRe         = regexp.MustCompile(searchOptionRe("index") + `["']([^"']+)["']`)
+	searchPathRe          = regexp.MustCompile(searchOptionRe("path") + `(?:["']([^"']+)["']|\[([^\]]*)\]|(?:bson\.A|\[\]string)\{([^}]*)\})`)
+	searchNumCandidatesRe = regexp.MustCompile(searchOptionRe("numCandidates") + `(\d+)`)
+	searchLimitRe         = regexp.MustCompile(searchOptionRe("limit") + `(\d+)`)
+	searchFilterRe        = regexp.MustCompile(searchOptionRe("filter") + `(?:bson\.[DM])?\{`)
+	filterKeyRe           = regexp.MustCompile(`(?:^|[{,\s])["']?(\$?[a-zA-Z_][a-zA-Z0-9_.]*)["']?\s*:`)
+	filterDocKeyRe        = regexp.MustCompile(`Key:\s*"(\$?[a-zA-Z_][a-zA-Z0-9_.]*)"`)
+	quotedStringRe        = regexp.MustCompile(`["']([^"']+)["']`)
+)
+
+// maxSearchStageLines limits how many lines a stage document may span.
+const maxSearchStageLines = 30
+
+// searchStage is an Atlas Search or vector search stage found in code.
+type searchStage struct {
+	Stage         SearchStage
+	Index         string
+	Paths         []string
+	NumCandidates int
+	Limit         int
+	FilterFields  []string
+}
+
+// scanSearchStage extracts the $search, $searchMeta or $vectorSearch stage
+// starting on lines[i]. The stage document may continue on the following
+// lines; it ends where its braces balance. It reports false when the line
+// has no search stage.
+func scanSearchStage(lines []string, i int) (searchStage, bool) {
+	loc := searchStageRe.FindStringSubmatchIndex(lines[i])
+	if loc == nil {
+		return searchStage{}, false
+	}
+	s := searchStage{Stage: SearchStage(lines[i][loc[2]:loc[3]])}
+	body := stageDocument(lines, i, loc[1]-1)
+
+	if m := searchIndexRe.FindStringSubmatch(body); m != nil {
+		s.Index = m[1]
+	}
+	seen := make(map[string]bool)
+	for _, m := range searchPathRe.FindAllStringSubmatch(body, -1) {
+		paths := []string{m[1]}
+		if m[1] == "" {
+			paths = nil
+			for _, q := range quotedStringRe.FindAllStringSubmatch(m[2]+m[3], -1) {
+				paths = append(paths, q[1])
+			}
+		}
+		for _, p := range paths {
+			if isValidFieldName(p) && !seen[p] {
+				seen[p] = true
+				s.Paths = append(s.Paths, p)
+			}
+		}
+	}
+	if m := searchNumCandidatesRe.FindStringSubmatch(body); m != nil {
+		s.NumCandidates, _ = strconv.Atoi(m[1])
+	}
+	if m := searchLimitRe.FindStringSubmatch(body); m != nil {
+		s.Limit, _ = strconv.Atoi(m[1])
+	}
+	if loc := searchFilterRe.FindStringIndex(body); loc != nil && s.Stage == SearchStageVector {
+		s.FilterFields = filterFields(balancedDocument(body[loc[1]-1:]))
+	}
+	return s, true
+}
+
+// stageCollection returns the collection of the statement that the stage
+// on lines[i] belongs to. An aggregate pipeline often names its collection
+// several lines above the stage, so when lineCollections has none for line
+// i, the lines above are searched for one whose brackets are still open at
+// line i, up to maxSearchStageLines back.
+func stageCollection(lines, lineCollections []string, i int) string {
+	if lineCollections[i] != "" {
+		return lineCollections[i]
+	}
+	depth, enclosing := 0, 0
+	for n := i - 1; n >= 0 && n > i-maxSearchStageLines; n-- {
+		depth += bracketBalance(lines[n])
+		if depth <= enclosing {
+			// Closed again before line i: a sibling, not an enclosing line.
+			continue
+		}
+		enclosing = depth
+		if lineCollections[n] != "" {
+			return lineCollections[n]
+		}
+	}
+	return ""
+}
+
+// filterFields returns the field names of a $vectorSearch filter document,
+// skipping operators such as $gt and $and.
+func filterFields(doc string) []string {
+	var fields []string
+	seen := make(map[string]bool)
+	matches := filterKeyRe.FindAllStringSubmatch(doc, -1)
+	matches = append(matches, filterDocKeyRe.FindAllStringSubmatch(doc, -1)...)
+	for _, m := range matches {
+		field := m[1]
+		if strings.HasPrefix(field, "$") || field == "Key" || field == "Value" || !isValidFieldName(field) || seen[field] {
+			continue
+		}
+		seen[field] = true
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// stageDocument returns the document that opens at offset start on
+// lines[i], reading further lines until its braces balance.
+func stageDocument(lines []string, i, start int) string {
+	text := lines[i][start:]
+	for n := i + 1; n < len(lines) && n < i+maxSearchStageLines; n++ {
+		text += " " + strings.TrimSpace(lines[n])
+	}
+	return balancedDocument(text)
+}
+
+// balancedDocument returns the prefix of s up to the brace that closes its
+// first "{". Braces inside string literals are ignored. An unterminated
+// document is returned whole.
+func balancedDocument(s string) string {
+	depth := 0
+	opened := false
+	inStr := byte(0)
+	for j := 0; j < len(s); j++ {
+		c := s[j]
+		if inStr != 0 {
+			if c == '\\' {
+				j++
+			} else if c == inStr {
+				inStr = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'', '`':
+			inStr = c
+		case '{':
+			depth++
+			opened = true
+		case '}':
+			depth--
+			if opened && depth == 0 {
+				return s[:j+1]
+			}
+		}
+	}
+	return s
+}
@@ -0,0 +1,140 @@
+package scanner
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScanSearchStage(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want searchStage
+		ok   bool
+	}{
+		{
+			name: "js vector search across lines",
+			src: `  {
+    $vectorSearch: {
+      index: "plot_vectors",
+      path: "plot_embedding",
+      queryVector: embedding,
+      numCandidates: 150,
+      limit: 10,
+    },
+  },
+  { $limit: 5 },`,
+			want: searchStage{Stage: SearchStageVector, Index: "plot_vectors", Paths: []string{"plot_embedding"}, NumCandidates: 150, Limit: 10},
+			ok:   true,
+		},
+		{
+			name: "python search with path list",
+			src:  `pipeline = [{"$search": {"index": "catalog", "compound": {"should": [{"text": {"query": q, "path": ["title", "description"]}}, {"text": {"query": q, "path": "title"}}]}}}, {"$limit": 20}]`,
+			want: searchStage{Stage: SearchStageText, Index: "catalog", Paths: []string{"title", "description"}},
+			ok:   true,
+		},
+		{
+			name: "go bson.D search meta on default index",
+			src:  `{{Key: "$searchMeta", Value: bson.D{{Key: "facet", Value: bson.D{{Key: "operator", Value: bson.D{{Key: "text", Value: bson.D{{Key: "path", Value: bson.A{"genres"}}}}}}}}}}}`,
+			want: searchStage{Stage: SearchStageMeta, Paths: []string{"genres"}},
+			ok:   true,
+		},
+		{
+			name: "go bson.D vector search",
+			src:  `{{Key: "$vectorSearch", Value: bson.D{{Key: "index", Value: "vec"}, {Key: "path", Value: "embedding"}, {Key: "numCandidates", Value: 200}, {Key: "limit", Value: 20}}}}`,
+			want: searchStage{Stage: SearchStageVector, Index: "vec", Paths: []string{"embedding"}, NumCandidates: 200, Limit: 20},
+			ok:   true,
+		},
+		{
+			name: "vector search filter fields",
+			src:  `{"$vectorSearch": {"index": "vec", "path": "embedding", "filter": {"$and": [{"year": {"$gt": 1990}}, {"genres": {"$in": ["Drama"]}}]}, "limit": 5}}`,
+			want: searchStage{Stage: SearchStageVector, Index: "vec", Paths: []string{"embedding"}, Limit: 5, FilterFields: []string{"year", "genres"}},
+			ok:   true,
+		},
+		{
+			name: "go bson.D vector search filter",
+			src:  `{{Key: "$vectorSearch", Value: bson.D{{Key: "index", Value: "vec"}, {Key: "filter", Value: bson.D{{Key: "tenant", Value: id}}}, {Key: "path", Value: "embedding"}}}}`,
+			want: searchStage{Stage: SearchStageVector, Index: "vec", Paths: []string{"embedding"}, FilterFields: []string{"tenant"}},
+			ok:   true,
+		},
+		{
+			name: "legacy text search operator",
+			src:  `db.articles.find({ $text: { $search: "coffee" } })`,
+		},
+		{
+			name: "no stage",
+			src:  `db.articles.aggregate([{ $match: { status: "A" } }])`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := strings.Split(tt.src, "\n")
+			start := 0
+			for i, l := range lines {
+				if strings.Contains(l, "$search") || strings.Contains(l, "$vectorSearch") || strings.Contains(l, "$searchMeta") {
+					start = i
+					break
+				}
+			}
+			got, ok := scanSearchStage(lines, start)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("stage = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScan_SearchRefs(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "search.js", `async function similar(db, embedding) {
+  return db.collection("movies").aggregate([
+    {
+      $vectorSearch: {
+        index: "plot_vectors",
+        path: "plot_embedding",
+        queryVector: embedding,
+        numCandidates: 100,
+        limit: 5,
+      },
+    },
+  ]).toArray();
+}
+
+const searchPipeline = [{ $search: { index: "titles", text: { query: "x", path: "title" } } }];
+
+async function recent(db, embedding) {
+  db.collection("audit").insertOne({ at: new Date() });
+  return db.collection("movies").aggregate([
+    {
+      $match: {
+        year: { $gte: 2000 },
+        rated: "PG",
+      },
+    },
+    {
+      $vectorSearch: {
+        index: "plot_vectors",
+        path: "plot_embedding",
+      },
+    },
+  ]).toArray();
+}
+`)
+
+	result, err := Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SearchRef{
+		{Collection: "movies", Stage: SearchStageVector, Index: "plot_vectors", Paths: []string{"plot_embedding"}, NumCandidates: 100, Limit: 5, File: "search.js", Line: 4},
+		{Stage: SearchStageText, Index: "titles", Paths: []string{"title"}, File: "search.js", Line: 15},
+		{Collection: "movies", Stage: SearchStageVector, Index: "plot_vectors", Paths: []string{"plot_embedding"}, File: "search.js", Line: 27},
+	}
+	if !reflect.DeepEqual(result.SearchRefs, want) {
+		t.Errorf("search refs = %+v, want %+v", result.SearchRefs, want)
+	}
+}
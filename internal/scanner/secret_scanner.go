@@ -0,0 +1,42 @@
+package scanner
+
+import (
+	"regexp"
+	"strings"
+)
+
+// credentialURIRe matches a MongoDB connection string with a user and
+// password in its authority: scheme, user, password, remainder.
+var credentialURIRe = regexp.MustCompile("(mongodb(?:\\+srv)?://)([^:@/\\s\"'`]+):([^@/\\s\"'`]+)@([^\\s\"'`]*)")
+
+// placeholderPasswordRe matches passwords that are obviously not real:
+// ****, xxx, %s and common dummy words.
+var placeholderPasswordRe = regexp.MustCompile(`(?i)^(\*+|x+|\.+|%[sv]|password|passwd|pass|pwd|secret|changeme|example)$`)
+
+// scanLineCredentialURIs returns connection strings on a line that embed a
+// literal password. Interpolated or placeholder passwords are skipped.
+func scanLineCredentialURIs(line string) []credentialURIMatch {
+	var out []credentialURIMatch
+	for _, m := range credentialURIRe.FindAllStringSubmatch(line, -1) {
+		user, pass := m[2], m[3]
+		if isInterpolated(user) || isInterpolated(pass) || placeholderPasswordRe.MatchString(pass) {
+			continue
+		}
+		out = append(out, credentialURIMatch{
+			User:     user,
+			Redacted: m[1] + user + ":***@" + m[4],
+		})
+	}
+	return out
+}
+
+type credentialURIMatch struct {
+	User     string
+	Redacted string
+}
+
+// isInterpolated reports whether s contains template or format syntax from
+// any supported language, meaning the value is filled in at runtime.
+func isInterpolated(s string) bool {
+	return strings.ContainsAny(s, "{}$<>") || strings.Contains(s, "%(") || strings.Contains(s, "%s")
+}
@@ -0,0 +1,131 @@
+package scanner
+
+import (
+	"regexp"
+	"strings"
+)
+
+// transactionStartRe matches the calls that open a transaction:
+// withTransaction callbacks and explicit startTransaction in every driver.
+var transactionStartRe = regexp.MustCompile(`(?i)\b(with_?transaction|start_?transaction)\s*\(`)
+
+// transactionEndRe matches the calls that finish an explicit transaction.
+var transactionEndRe = regexp.MustCompile(`(?i)\b(?:commit|abort)_?transaction\s*\(`)
+
+// transactionRiskRes are work that keeps a transaction open for long:
+// remote calls and waits do not touch the database, but hold its locks
+// and snapshot until the transaction commits.
+var transactionRiskRes = []struct {
+	re   *regexp.Regexp
+	risk string
+}{
+	{regexp.MustCompile(`\b(?:fetch|axios(?:\.\w+)?|requests\.(?:get|post|put|patch|delete)|httpx\.\w+|urlopen|http\.(?:Get|Post|NewRequest\w*)|[Hh]ttp[Cc]lient\.\w+|[Rr]est[Tt]emplate\.\w+)\s*\(`), "network call"},
+	{regexp.MustCompile(`(?i)\b(?:time\.Sleep|Thread\.sleep|asyncio\.sleep|time\.sleep|setTimeout|sleep)\s*\(`), "sleep"},
+}
+
+// maxTransactionLines limits how far a transaction body is followed.
+const maxTransactionLines = 200
+
+// transactionBody is one transaction found in code: the 0-based first and
+// last line of its body and the risks seen in it.
+type transactionBody struct {
+	Start, End int
+	Risks      []string
+}
+
+// scanTransactions finds the transactions opened in lines. A
+// withTransaction body ends where the call's brackets close, an explicit
+// startTransaction ends at the next commit or abort, and a Python
+// "with ... start_transaction():" block ends with its indentation.
+// Transactions nested in one already found are folded into it.
+func scanTransactions(lines []string) []transactionBody {
+	var out []transactionBody
+	for i := 0; i < len(lines); i++ {
+		loc := transactionStartRe.FindStringSubmatchIndex(lines[i])
+		if loc == nil || isCommentLine(lines[i]) {
+			continue
+		}
+		name := strings.ToLower(strings.ReplaceAll(lines[i][loc[2]:loc[3]], "_", ""))
+		end := i
+		switch {
+		case strings.HasSuffix(strings.TrimSpace(lines[i]), ":"):
+			end = indentedBlockEnd(lines, i)
+		case name == "withtransaction":
+			end = bracketEnd(lines, i, loc[0])
+		default:
+			for j := i; j < len(lines) && j-i < maxTransactionLines; j++ {
+				if transactionEndRe.MatchString(lines[j]) {
+					end = j
+					break
+				}
+			}
+		}
+		body := transactionBody{Start: i, End: end}
+		seen := make(map[string]bool)
+		for _, line := range lines[i : end+1] {
+			if isCommentLine(line) {
+				continue
+			}
+			for _, r := range transactionRiskRes {
+				if !seen[r.risk] && r.re.MatchString(line) {
+					seen[r.risk] = true
+					body.Risks = append(body.Risks, r.risk)
+				}
+			}
+		}
+		out = append(out, body)
+		i = end
+	}
+	return out
+}
+
+// bracketEnd returns the line where the brackets opened from column col
+// of line start are closed again.
+func bracketEnd(lines []string, start, col int) int {
+	depth := 0
+	for j := start; j < len(lines) && j-start < maxTransactionLines; j++ {
+		line := lines[j]
+		if j == start {
+			line = line[col:]
+		}
+		for _, r := range line {
+			switch r {
+			case '(', '{', '[':
+				depth++
+			case ')', '}', ']':
+				depth--
+			}
+		}
+		if depth <= 0 {
+			return j
+		}
+	}
+	return start
+}
+
+// indentedBlockEnd returns the last line of the block opened by the line
+// at start, by indentation.
+func indentedBlockEnd(lines []string, start int) int {
+	indent := leadingSpace(lines[start])
+	end := start
+	for j := start + 1; j < len(lines) && j-start < maxTransactionLines; j++ {
+		if strings.TrimSpace(lines[j]) == "" {
+			continue
+		}
+		if leadingSpace(lines[j]) <= indent {
+			break
+		}
+		end = j
+	}
+	return end
+}
+
+// isCommentLine reports whether line is a whole-line comment.
+func isCommentLine(line string) bool {
+	t := strings.TrimSpace(line)
+	return strings.HasPrefix(t, "//") || strings.HasPrefix(t, "#") || strings.HasPrefix(t, "/*") || strings.HasPrefix(t, "*")
+}
+
+func leadingSpace(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
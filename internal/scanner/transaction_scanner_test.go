@@ -0,0 +1,93 @@
+package scanner
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScanTransactions(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []transactionBody
+	}{
+		{
+			name: "js withTransaction callback",
+			src: `await session.withTransaction(async () => {
+  await db.collection("orders").insertOne(order, { session });
+  const rate = await fetch(ratesURL);
+  await db.collection("ledger").insertOne(entry, { session });
+});
+await db.collection("audit").insertOne(entry);`,
+			want: []transactionBody{{Start: 0, End: 4, Risks: []string{"network call"}}},
+		},
+		{
+			name: "go explicit start and commit",
+			src: `if err := sess.StartTransaction(); err != nil {
+	return err
+}
+_, err = orders.InsertOne(sc, order)
+time.Sleep(2 * time.Second)
+return sess.CommitTransaction(sc)`,
+			want: []transactionBody{{Start: 0, End: 5, Risks: []string{"sleep"}}},
+		},
+		{
+			name: "python with block",
+			src: `with client.start_session() as s:
+    with s.start_transaction():
+        db.orders.insert_one(order, session=s)
+        requests.post(webhook, json=order)
+    db.audit.insert_one(entry)`,
+			want: []transactionBody{{Start: 1, End: 3, Risks: []string{"network call"}}},
+		},
+		{
+			name: "nested start folded into withTransaction",
+			src: `session.withTransaction(() => {
+  session.startTransaction();
+  session.commitTransaction();
+});`,
+			want: []transactionBody{{Start: 0, End: 3}},
+		},
+		{
+			name: "commented out",
+			src:  `// session.withTransaction(() => {})`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scanTransactions(strings.Split(tt.src, "\n"))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScan_TransactionRefs(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "checkout.js", `async function checkout(db, session, order) {
+  await session.withTransaction(async () => {
+    await db.collection("orders").insertOne(order, { session });
+    await db.collection("inventory").updateOne({ sku: order.sku }, { $inc: { qty: -1 } }, { session });
+    await db.collection("orders").updateOne({ _id: order._id }, { $set: { state: "paid" } }, { session });
+  });
+  await db.collection("audit").insertOne({ order: order._id });
+}
+`)
+
+	result, err := Scan(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.TransactionRefs) != 1 {
+		t.Fatalf("got %d transactions, want 1: %+v", len(result.TransactionRefs), result.TransactionRefs)
+	}
+	tx := result.TransactionRefs[0]
+	if tx.File != "checkout.js" || tx.Line != 2 || tx.EndLine != 6 {
+		t.Errorf("transaction = %+v", tx)
+	}
+	if want := []string{"inventory", "orders"}; !reflect.DeepEqual(tx.Collections, want) {
+		t.Errorf("collections = %v, want %v", tx.Collections, want)
+	}
+}
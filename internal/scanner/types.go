@@ -27,6 +27,8 @@ type FieldRef struct {
 	Usage        FieldUsage `json:"usage,omitempty"`        // equality/sort/range/unknown
 	Direction    int        `json:"direction,omitempty"`    // used for sort keys (-1/1)
 	QueryContext string     `json:"queryContext,omitempty"` // find/aggregate/update/etc.
+	Constant     string     `json:"constant,omitempty"`     // literal compared by equality: "active", true, false
+	Operators    []string   `json:"operators,omitempty"`    // query operators applied to the field, without "$": gt, in, regex
 }
 
 // FieldUsage describes how a field is used in a query shape.
@@ -61,6 +63,278 @@ const (
 	ValueTypeObjectID = "objectId"
 )
 
+// ReadRef represents a read operation in code, tied to a collection.
+type ReadRef struct {
+	Collection string `json:"collection"`
+	File       string `json:"file"`
+	Line       int    `json:"line"`
+}
+
+// IndexRef represents an index created by code, tied to a collection.
+type IndexRef struct {
+	Collection string   `json:"collection"`
+	Fields     []string `json:"fields"`               // key fields in definition order
+	TextFields []string `json:"textFields,omitempty"` // fields with a "text" key
+	Unique     bool     `json:"unique,omitempty"`
+	File       string   `json:"file"`
+	Line       int      `json:"line"`
+}
+
+// UpsertRef represents an upserting update whose filter fields act as a
+// natural key, tied to a collection.
+type UpsertRef struct {
+	Collection string   `json:"collection"`
+	Fields     []string `json:"fields"` // filter fields, sorted
+	File       string   `json:"file"`
+	Line       int      `json:"line"`
+}
+
+// CredentialURIRef is a MongoDB connection string with an embedded password
+// committed to source. URI has the password masked.
+type CredentialURIRef struct {
+	User string `json:"user"`
+	URI  string `json:"uri"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// EncryptionKind identifies the client-side encryption scheme of a schema.
+type EncryptionKind string
+
+const (
+	EncryptionQueryable EncryptionKind = "queryable" // encryptedFieldsMap
+	EncryptionCSFLE     EncryptionKind = "csfle"     // schemaMap with encrypt keywords
+)
+
+// EncryptedFieldRef lists the fields a client encryption schema in code
+// declares as encrypted for one namespace.
+type EncryptedFieldRef struct {
+	Database   string         `json:"database"`
+	Collection string         `json:"collection"`
+	Fields     []string       `json:"fields"` // sorted
+	Kind       EncryptionKind `json:"kind"`
+	File       string         `json:"file"`
+	Line       int            `json:"line"`
+	// Queries are the query types of queryable fields, by path.
+	Queries map[string][]string `json:"queries,omitempty"`
+}
+
+// SearchStage identifies an Atlas Search aggregation stage.
+type SearchStage string
+
+const (
+	SearchStageText   SearchStage = "search"       // $search
+	SearchStageMeta   SearchStage = "searchMeta"   // $searchMeta
+	SearchStageVector SearchStage = "vectorSearch" // $vectorSearch
+)
+
+// SearchRef represents an Atlas Search or vector search stage in code.
+// Collection is empty when the pipeline is not built on the line that
+// names the collection, and Index is empty when the stage uses the
+// "default" search index.
+type SearchRef struct {
+	Collection    string      `json:"collection,omitempty"`
+	Stage         SearchStage `json:"stage"`
+	Index         string      `json:"index,omitempty"`
+	Paths         []string    `json:"paths,omitempty"`         // searched fields
+	NumCandidates int         `json:"numCandidates,omitempty"` // $vectorSearch candidates considered
+	Limit         int         `json:"limit,omitempty"`         // $vectorSearch results returned
+	FilterFields  []string    `json:"filterFields,omitempty"`  // $vectorSearch pre-filter fields
+	File          string      `json:"file"`
+	Line          int         `json:"line"`
+}
+
+// EmbeddingRef is an embedding size implied by code: an explicit
+// dimensions setting, or the default size of a named embedding model.
+type EmbeddingRef struct {
+	Model      string `json:"model,omitempty"`
+	Dimensions int    `json:"dimensions"`
+	File       string `json:"file"`
+	Line       int    `json:"line"`
+}
+
+// TransactionRef is a transaction opened in code, with the collections
+// named in its body and the work in it that can hold it open for long.
+type TransactionRef struct {
+	Collections []string `json:"collections,omitempty"` // sorted
+	Risks       []string `json:"risks,omitempty"`       // e.g. "network call", "sleep"
+	File        string   `json:"file"`
+	Line        int      `json:"line"`
+	EndLine     int      `json:"endLine"`
+}
+
+// WriteConcernRef is a write concern set in code. W is "majority", a
+// member count, or a tag set name, and is empty when only the journal
+// option is set.
+type WriteConcernRef struct {
+	Collection string `json:"collection,omitempty"`
+	W          string `json:"w,omitempty"`
+	NoJournal  bool   `json:"noJournal,omitempty"` // j: false
+	WTimeout   bool   `json:"wtimeout,omitempty"`  // a wtimeout or timeoutMS is set
+	File       string `json:"file"`
+	Line       int    `json:"line"`
+}
+
+// ReadSettingRef is a read preference or read concern set in code. Mode
+// and Level are lower case, such as "secondarypreferred" and "majority".
+// Function and AfterWrite place it in its flow: the first line of the
+// enclosing function, or of the stretch of top-level code it is in, and
+// the nearest earlier write in it, 0 when unknown.
+type ReadSettingRef struct {
+	Collection string `json:"collection,omitempty"`
+	Mode       string `json:"mode,omitempty"`
+	Level      string `json:"level,omitempty"`
+	Function   int    `json:"function,omitempty"`
+	AfterWrite int    `json:"afterWrite,omitempty"`
+	File       string `json:"file"`
+	Line       int    `json:"line"`
+}
+
+// ODM names the object-document mappers whose configuration is scanned.
+const (
+	ODMMongoose   = "mongoose"
+	ODMSpringData = "spring-data"
+	ODMMgm        = "mgm"
+)
+
+// ODMRefKind identifies what an ODMRef records.
+type ODMRefKind string
+
+const (
+	ODMModel      ODMRefKind = "model"      // a model bound to a collection
+	ODMIndex      ODMRefKind = "index"      // an index declared in a schema or entity
+	ODMAutoIndex  ODMRefKind = "auto_index" // automatic index builds switched on or off
+	ODMIndexSync  ODMRefKind = "index_sync" // a call that builds a model's indexes
+	ODMTimestamps ODMRefKind = "timestamps" // the timestamp paths a schema adds
+)
+
+// ODMRef is an object-document mapper setting found in code: a model, an
+// index declared in a schema, automatic index builds, a call building a
+// model's indexes, or the timestamp paths a schema adds. Collection is
+// empty when the model's collection is not known.
+type ODMRef struct {
+	ODM        string     `json:"odm"`
+	Kind       ODMRefKind `json:"kind"`
+	Model      string     `json:"model,omitempty"` // model or schema variable, entity class or struct
+	Collection string     `json:"collection,omitempty"`
+	Fields     []string   `json:"fields,omitempty"`     // index keys, for ODMIndex; paths, for ODMTimestamps
+	TextFields []string   `json:"textFields,omitempty"` // text index keys, for ODMIndex
+	Unique     bool       `json:"unique,omitempty"`     // for ODMIndex
+	Enabled    bool       `json:"enabled,omitempty"`    // for ODMAutoIndex
+	Call       string     `json:"call,omitempty"`       // for ODMIndexSync, e.g. "syncIndexes"
+	File       string     `json:"file"`
+	Line       int        `json:"line"`
+}
+
+// FixtureRef is the seed data a fixture file holds for one collection.
+type FixtureRef struct {
+	Collection string         `json:"collection"`
+	Docs       int            `json:"docs"`
+	Fields     []FixtureField `json:"fields,omitempty"`    // top-level fields, sorted by name
+	MongoData  bool           `json:"mongoData,omitempty"` // documents carry _id or Extended JSON
+	File       string         `json:"file"`
+	Line       int            `json:"line"`
+}
+
+// FixtureField is a top-level field of a fixture's documents.
+type FixtureField struct {
+	Name  string   `json:"name"`
+	Types []string `json:"types"` // ValueType* of its values
+	Count int      `json:"count"` // documents that set it
+}
+
+// DriverRef is a MongoDB driver dependency declared in a go.mod,
+// package.json or requirements.txt.
+type DriverRef struct {
+	Package string `json:"package"` // module or package name, lowercased
+	Version string `json:"version"` // lower bound of the declared version
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+}
+
+// IaC names the infrastructure-as-code tools whose Atlas resources are
+// scanned.
+const (
+	IaCTerraform = "terraform"
+	IaCPulumi    = "pulumi"
+)
+
+// IaCResourceKind identifies the Atlas resource an IaCRef declares.
+type IaCResourceKind string
+
+const (
+	IaCDatabaseUser IaCResourceKind = "database_user" // a database user
+	IaCAccessList   IaCResourceKind = "access_list"   // an IP access list entry
+	IaCCluster      IaCResourceKind = "cluster"       // a cluster
+)
+
+// IaCRef is an Atlas resource declared in Terraform configuration or
+// state, or in a Pulumi YAML program. Value is the username, CIDR block,
+// IP address, AWS security group or cluster name; it is empty when the
+// value is set from a variable or expression, or the resource uses count
+// or for_each.
+type IaCRef struct {
+	Tool         string          `json:"tool"`
+	Kind         IaCResourceKind `json:"kind"`
+	Address      string          `json:"address"` // e.g. mongodbatlas_database_user.app
+	Value        string          `json:"value,omitempty"`
+	Attribute    string          `json:"attribute,omitempty"`    // the attribute Value was read from
+	AuthDatabase string          `json:"authDatabase,omitempty"` // for IaCDatabaseUser
+	ProjectID    string          `json:"projectId,omitempty"`    // when set literally
+	File         string          `json:"file"`
+	Line         int             `json:"line"`
+}
+
+// Kubernetes operator custom resource kinds that declare MongoDB
+// deployments and database users.
+const (
+	CRDMongoDBCommunity  = "MongoDBCommunity"  // Community operator deployment
+	CRDMongoDB           = "MongoDB"           // Enterprise operator deployment
+	CRDMongoDBUser       = "MongoDBUser"       // Enterprise operator user
+	CRDAtlasDeployment   = "AtlasDeployment"   // Atlas operator cluster
+	CRDAtlasDatabaseUser = "AtlasDatabaseUser" // Atlas operator user
+)
+
+// OperatorRef is a Kubernetes operator custom resource found in the repo:
+// a deployment with its version, topology, inline users and custom roles,
+// or a single database user. Cluster is the deployment a user resource
+// refers to, when it names one.
+type OperatorRef struct {
+	Kind     string         `json:"kind"`
+	Name     string         `json:"name"` // metadata.name, or the Atlas deployment name
+	Cluster  string         `json:"cluster,omitempty"`
+	Version  string         `json:"version,omitempty"`  // MongoDB version, or major version for Atlas
+	Members  int            `json:"members,omitempty"`  // replica set members
+	Topology string         `json:"topology,omitempty"` // e.g. ReplicaSet, ShardedCluster, REPLICASET
+	Users    []OperatorUser `json:"users,omitempty"`
+	Roles    []OperatorRole `json:"roles,omitempty"` // custom roles the deployment defines
+	File     string         `json:"file"`
+	Line     int            `json:"line"`
+}
+
+// OperatorUser is a database user declared in an operator resource.
+type OperatorUser struct {
+	Name  string         `json:"name"`
+	DB    string         `json:"db"`
+	Roles []OperatorRole `json:"roles,omitempty"`
+	Line  int            `json:"line"`
+}
+
+// OperatorRole is a role of a database, granted to a user or defined as
+// a custom role.
+type OperatorRole struct {
+	Role string `json:"role"`
+	DB   string `json:"db"`
+}
+
+// AppNameRef is a client appName set in code, which the server reports
+// for the client's operations.
+type AppNameRef struct {
+	Name string `json:"name"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
 // DynamicRef records a collection call using a variable that could not be resolved.
 type DynamicRef struct {
 	Variable string `json:"variable"`
@@ -70,12 +344,41 @@ type DynamicRef struct {
 
 // ScanResult holds all collection references found in a repository.
 type ScanResult struct {
-	RepoPath     string          `json:"repoPath"`
-	Refs         []CollectionRef `json:"refs"`
-	FieldRefs    []FieldRef      `json:"fieldRefs,omitempty"`
-	WriteRefs    []WriteRef      `json:"writeRefs,omitempty"`
-	DynamicRefs  []DynamicRef    `json:"dynamicRefs,omitempty"`
-	Collections  []string        `json:"collections"` // deduplicated collection names
-	FilesScanned int             `json:"filesScanned"`
-	FilesSkipped int             `json:"filesSkipped,omitempty"`
+	RepoPath   string          `json:"repoPath"`
+	Refs       []CollectionRef `json:"refs"`
+	FieldRefs  []FieldRef      `json:"fieldRefs,omitempty"`
+	WriteRefs  []WriteRef      `json:"writeRefs,omitempty"`
+	ReadRefs   []ReadRef       `json:"readRefs,omitempty"`
+	IndexRefs  []IndexRef      `json:"indexRefs,omitempty"`
+	UpsertRefs []UpsertRef     `json:"upsertRefs,omitempty"`
+	// SearchRefs are $search, $searchMeta and $vectorSearch stages.
+	SearchRefs []SearchRef `json:"searchRefs,omitempty"`
+	// EmbeddingRefs are embedding sizes set or implied in code.
+	EmbeddingRefs []EmbeddingRef `json:"embeddingRefs,omitempty"`
+	// TransactionRefs are transactions opened in code.
+	TransactionRefs []TransactionRef `json:"transactionRefs,omitempty"`
+	// WriteConcernRefs are write concerns set in code.
+	WriteConcernRefs []WriteConcernRef `json:"writeConcernRefs,omitempty"`
+	// ReadSettingRefs are read preferences and read concerns set in code.
+	ReadSettingRefs []ReadSettingRef `json:"readSettingRefs,omitempty"`
+	// AppNameRefs are client appNames set in code.
+	AppNameRefs []AppNameRef `json:"appNameRefs,omitempty"`
+	// ODMRefs are ODM models, schema indexes and index settings.
+	ODMRefs []ODMRef `json:"odmRefs,omitempty"`
+	// FixtureRefs are seed documents in JSON and YAML fixture files.
+	FixtureRefs []FixtureRef `json:"fixtureRefs,omitempty"`
+	// DriverRefs are MongoDB driver dependencies declared in manifests.
+	DriverRefs []DriverRef `json:"driverRefs,omitempty"`
+	// IaCRefs are Atlas resources declared in Terraform or Pulumi.
+	IaCRefs []IaCRef `json:"iacRefs,omitempty"`
+	// OperatorRefs are Kubernetes operator custom resources.
+	OperatorRefs []OperatorRef `json:"operatorRefs,omitempty"`
+	// EncryptedFieldRefs are encryption schemas declared in code.
+	EncryptedFieldRefs []EncryptedFieldRef `json:"encryptedFieldRefs,omitempty"`
+	// CredentialURIRefs are connection strings with hardcoded passwords.
+	CredentialURIRefs []CredentialURIRef `json:"credentialUriRefs,omitempty"`
+	DynamicRefs       []DynamicRef       `json:"dynamicRefs,omitempty"`
+	Collections       []string           `json:"collections"` // deduplicated collection names
+	FilesScanned      int                `json:"filesScanned"`
+	FilesSkipped      int                `json:"filesSkipped,omitempty"`
 }
@@ -10,6 +10,9 @@ import (
 
 var writeOperationRe = regexp.MustCompile(`(?i)\.(insertone|insertmany|insert_one|insert_many|replaceone|replace_one|findoneandreplace|find_one_and_replace|updateone|updatemany|update_one|update_many|findoneandupdate|find_one_and_update|bulkwrite|bulk_write)\(`)
 
+// upsertOptionRe matches an upsert option in JS/Python/Go driver calls.
+var upsertOptionRe = regexp.MustCompile(`(?i)["']?upsert["']?\s*[:=]\s*true|SetUpsert\(true\)`)
+
 var numberLiteralRe = regexp.MustCompile(`^-?\d+(?:\.\d+)?(?:[eE][-+]?\d+)?$`)
 
 var updateWriteOperators = map[string]bool{
@@ -88,6 +91,47 @@ func ScanLineWriteFields(line string) []writeFieldMatch {
 	return out
 }
 
+// ScanLineUpsertKeys returns the filter fields of an update or replace call
+// that upserts, sorted. Upserts match on these fields to decide whether to
+// insert, so they act as a natural key. Filters on operators or _id, and
+// calls without upsert, return nil.
+func ScanLineUpsertKeys(line string) []string {
+	if !upsertOptionRe.MatchString(line) {
+		return nil
+	}
+	bounds := writeOperationRe.FindStringSubmatchIndex(line)
+	if len(bounds) < 4 {
+		return nil
+	}
+	switch strings.ToLower(line[bounds[2]:bounds[3]]) {
+	case "insertone", "insert_one", "insertmany", "insert_many", "bulkwrite", "bulk_write":
+		return nil
+	}
+
+	var filter string
+	for _, arg := range splitCallArgs(line, bounds[1]-1) {
+		if looksLikeDocumentExpr(arg) {
+			filter = arg
+			break
+		}
+	}
+	seen := make(map[string]bool)
+	var keys []string
+	for _, pair := range parseAssignments(filter) {
+		field := strings.TrimSpace(pair.key)
+		if strings.HasPrefix(field, "$") || field == "_id" {
+			return nil
+		}
+		if !isValidFieldName(field) || seen[field] {
+			continue
+		}
+		seen[field] = true
+		keys = append(keys, field)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func writeScopesForOperation(op string, args []string) []string {
 	switch op {
 	case "insertone", "insert_one", "insertmany", "insert_many":
@@ -1,6 +1,9 @@
 package scanner
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func writeFieldTypes(matches []writeFieldMatch) map[string]string {
 	out := make(map[string]string)
@@ -66,3 +69,25 @@ func TestScanLineWriteFields_InsertOneGo(t *testing.T) {
 		t.Fatalf("profile type = %q, want object", got["profile"])
 	}
 }
+
+func TestScanLineUpsertKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []string
+	}{
+		{"js updateOne", `db.collection("users").updateOne({ email: e, tenant: t }, { $set: { name: n } }, { upsert: true })`, []string{"email", "tenant"}},
+		{"python update_one", `db.users.update_one({"email": e}, {"$set": {"name": n}}, upsert=True)`, []string{"email"}},
+		{"go UpdateOne", `coll.UpdateOne(ctx, bson.M{"sku": sku}, update, options.UpdateOne().SetUpsert(true))`, []string{"sku"}},
+		{"no upsert", `db.collection("users").updateOne({ email: e }, { $set: { name: n } })`, nil},
+		{"upsert by _id", `db.collection("users").updateOne({ _id: id }, { $set: { name: n } }, { upsert: true })`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ScanLineUpsertKeys(tt.line)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("ScanLineUpsertKeys = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package scanner
+
+import (
+	"regexp"
+	"strings"
+)
+
+// writeConcernContextRe gates the generic key patterns below: a bare
+// "w: 0" or "j: false" is only read as a write concern on a line that
+// mentions one, or in a connection string.
+var writeConcernContextRe = regexp.MustCompile(`(?i)write_?concern|\bwtimeout|mongodb(?:\+srv)?://`)
+
+// writeConcernWRe matches the w option: w: 0, w="majority", W: 1, ?w=2.
+var writeConcernWRe = regexp.MustCompile(`(?i)\bw["']?\s*[:=]\s*(?:["']([\w-]+)["']|(\d+)\b|(majority)\b)`)
+
+// writeConcernJournalOffRe matches j: false and journal=false.
+var writeConcernJournalOffRe = regexp.MustCompile(`(?i)\b(?:j|journal)["']?\s*[:=]\s*(?:false|0)\b`)
+
+// writeConcernTimeoutRe matches a wtimeout, or a client-side timeoutMS
+// that also bounds how long a write waits for acknowledgment.
+var writeConcernTimeoutRe = regexp.MustCompile(`(?i)\b(?:w_?timeout(?:_?ms)?|timeout_?ms)["']?\s*[:=]`)
+
+// writeConcernConstRe matches driver write concern constants and helpers:
+// WriteConcern.MAJORITY (Java), WriteConcern.Unacknowledged (C#),
+// writeconcern.Majority() and writeconcern.W1() (Go).
+var writeConcernConstRe = regexp.MustCompile(`(?i)\bwrite_?concern\.(w_?majority|majority|unacknowledged|acknowledged|w[0-9])\b`)
+
+// writeConcernMatch is the write concern one expression sets.
+type writeConcernMatch struct {
+	W         string
+	NoJournal bool
+	WTimeout  bool
+}
+
+// ScanLineWriteConcern extracts the write concern set in an expression. W
+// is "majority", a member count such as "0" or "1", or a tag set name. It
+// reports false when the expression sets no write concern.
+func ScanLineWriteConcern(line string) (writeConcernMatch, bool) {
+	var m writeConcernMatch
+	if c := writeConcernConstRe.FindStringSubmatch(line); c != nil {
+		switch name := strings.ToLower(strings.ReplaceAll(c[1], "_", "")); name {
+		case "majority", "wmajority":
+			m.W = "majority"
+		case "unacknowledged":
+			m.W = "0"
+		case "acknowledged":
+			m.W = "1"
+		default:
+			m.W = name[1:]
+		}
+	}
+	if !writeConcernContextRe.MatchString(line) {
+		return m, m.W != ""
+	}
+	if w := writeConcernWRe.FindStringSubmatch(line); w != nil && m.W == "" {
+		m.W = w[1] + w[2] + w[3]
+		if strings.EqualFold(m.W, "majority") {
+			m.W = "majority"
+		}
+	}
+	m.NoJournal = writeConcernJournalOffRe.MatchString(line)
+	m.WTimeout = writeConcernTimeoutRe.MatchString(line)
+	return m, m.W != "" || m.NoJournal
+}
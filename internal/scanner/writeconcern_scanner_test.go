@@ -0,0 +1,28 @@
+package scanner
+
+import "testing"
+
+func TestScanLineWriteConcern(t *testing.T) {
+	tests := []struct {
+		line string
+		want writeConcernMatch
+		ok   bool
+	}{
+		{`await coll.insertOne(doc, { writeConcern: { w: 0 } });`, writeConcernMatch{W: "0"}, true},
+		{`db.orders.with_options(write_concern=WriteConcern(w="majority", wtimeout=5000))`, writeConcernMatch{W: "majority", WTimeout: true}, true},
+		{`opts := options.Collection().SetWriteConcern(writeconcern.Majority())`, writeConcernMatch{W: "majority"}, true},
+		{`wc := &writeconcern.WriteConcern{W: 1, Journal: &journal}`, writeConcernMatch{W: "1"}, true},
+		{`collection.withWriteConcern(WriteConcern.UNACKNOWLEDGED);`, writeConcernMatch{W: "0"}, true},
+		{`var wc = WriteConcern.WMajority;`, writeConcernMatch{W: "majority"}, true},
+		{`const uri = "mongodb://db1,db2/app?replicaSet=rs0&w=2&journal=false";`, writeConcernMatch{W: "2", NoJournal: true}, true},
+		{`writeConcern: { w: "analytics", j: false }`, writeConcernMatch{W: "analytics", NoJournal: true}, true},
+		{`ctx.drawImage(img, { x: 0, w: 0, h: 0 });`, writeConcernMatch{}, false},
+		{`const concern = { j: false };`, writeConcernMatch{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ScanLineWriteConcern(tt.line)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ScanLineWriteConcern(%q) = %+v, %v; want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAndNext(t *testing.T) {
+	base := time.Date(2026, 3, 4, 10, 17, 30, 0, time.UTC) // Wednesday
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 3, 4, 10, 18, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2026, 3, 5, 3, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 3, 4, 10, 30, 0, 0, time.UTC)},
+		{"0-30/10 11 * * *", time.Date(2026, 3, 4, 11, 0, 0, 0, time.UTC)},
+		{"0 9 * * mon-fri", time.Date(2026, 3, 5, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"30 2 15 * 1", time.Date(2026, 3, 9, 2, 30, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 3, 4, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			c, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got := c.Next(base); !got.Equal(tt.want) {
+				t.Fatalf("Next = %s, want %s", got, tt.want)
+			}
+			if c.String() != tt.expr {
+				t.Fatalf("String = %q", c.String())
+			}
+		})
+	}
+}
+
+func TestNextIsStrictlyAfter(t *testing.T) {
+	c, err := Parse("0 3 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2026, 3, 4, 3, 0, 0, 0, time.UTC)
+	if got := c.Next(at); !got.Equal(at.Add(24 * time.Hour)) {
+		t.Fatalf("Next = %s, want next day", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"* * * *", "expected 5 fields"},
+		{"60 * * * *", "out of range"},
+		{"* 24 * * *", "out of range"},
+		{"*/0 * * * *", "invalid step"},
+		{"5-1 * * * *", "reversed"},
+		{"* * * foo *", "invalid value"},
+		{"1,,2 * * * *", "empty list element"},
+		{"0 0 30 2 *", "never matches"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Parse(%q) error = %v, want %q", tt.expr, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// LastRun is the /lastrun payload: the most recent run plus scheduler state.
+type LastRun struct {
+	Run       *Run       `json:"run,omitempty"`
+	Running   bool       `json:"running"`
+	RunningMS int64      `json:"runningMs,omitempty"`
+	NextRunAt *time.Time `json:"nextRunAt,omitempty"`
+	Healthy   bool       `json:"healthy"`
+	Reason    string     `json:"reason,omitempty"`
+}
+
+// Started marks the beginning of an audit run so /healthz can detect runs
+// that never finish.
+func (s *Server) Started(t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running = true
+	s.runStarted = t
+	s.nextRun = time.Time{}
+}
+
+// ScheduleNext records when the next audit is due so /healthz can detect a
+// loop that stopped scheduling runs.
+func (s *Server) ScheduleNext(t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextRun = t
+}
+
+// SetStaleAfter sets how long a run may take, or how overdue the next run may
+// be, before /healthz reports the process as stuck. Zero disables the check.
+func (s *Server) SetStaleAfter(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.staleAfter = d
+}
+
+// Status returns the most recent run and whether the audit loop looks alive.
+func (s *Server) Status() LastRun {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.now()
+	status := LastRun{Running: s.running, Healthy: true}
+	if len(s.history) > 0 {
+		run := s.history[len(s.history)-1]
+		status.Run = &run
+	}
+	if s.running {
+		status.RunningMS = now.Sub(s.runStarted).Milliseconds()
+	}
+	if !s.nextRun.IsZero() {
+		next := s.nextRun.UTC()
+		status.NextRunAt = &next
+	}
+
+	if s.staleAfter > 0 {
+		switch {
+		case s.running && now.Sub(s.runStarted) > s.staleAfter:
+			status.Healthy = false
+			status.Reason = fmt.Sprintf("audit running for %s, exceeds %s", now.Sub(s.runStarted).Round(time.Second), s.staleAfter)
+		case !s.running && !s.nextRun.IsZero() && now.Sub(s.nextRun) > s.staleAfter:
+			status.Healthy = false
+			status.Reason = fmt.Sprintf("next audit overdue by %s", now.Sub(s.nextRun).Round(time.Second))
+		}
+	}
+	return status
+}
+
+func (s *Server) registerHealth(mux *http.ServeMux) {
+	mux.HandleFunc("GET /healthz", s.handleHealthz)
+	mux.HandleFunc("GET /readyz", s.handleReadyz)
+	mux.HandleFunc("GET /lastrun", s.handleLastRun)
+}
+
+// HealthHandler serves only /healthz, /readyz and /lastrun, for watch mode
+// where the dashboard is not exposed.
+func (s *Server) HealthHandler() http.Handler {
+	mux := http.NewServeMux()
+	s.registerHealth(mux)
+	return mux
+}
+
+// handleHealthz is the liveness probe: it fails only when the audit loop is stuck.
+func (s *Server) handleHealthz(w http.ResponseWriter, _ *http.Request) {
+	status := s.Status()
+	if !status.Healthy {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "stuck", "reason": status.Reason})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleReadyz is the readiness probe: ready once an audit has succeeded.
+func (s *Server) handleReadyz(w http.ResponseWriter, _ *http.Request) {
+	if s.Latest() == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "not ready", "reason": "no audit has completed yet"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
+}
+
+func (s *Server) handleLastRun(w http.ResponseWriter, _ *http.Request) {
+	status := s.Status()
+	code := http.StatusOK
+	if !status.Healthy {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, status)
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/reporter"
+)
+
+func get(t *testing.T, h http.Handler, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+	return rec
+}
+
+func TestReadyzAfterFirstSuccessfulRun(t *testing.T) {
+	s := New(0)
+	h := s.HealthHandler()
+
+	if rec := get(t, h, "/readyz"); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("readyz before first run = %d, want 503", rec.Code)
+	}
+	s.Record(time.Now(), 0, nil, errors.New("auth failed"))
+	if rec := get(t, h, "/readyz"); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("readyz after failed run = %d, want 503", rec.Code)
+	}
+	report := reporter.NewReport(nil)
+	s.Record(time.Now(), 0, &report, nil)
+	if rec := get(t, h, "/readyz"); rec.Code != http.StatusOK {
+		t.Fatalf("readyz after success = %d, want 200", rec.Code)
+	}
+}
+
+func TestHealthzDetectsStuckRun(t *testing.T) {
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+	s := New(0)
+	s.now = func() time.Time { return now }
+	s.SetStaleAfter(time.Minute)
+	h := s.Handler()
+
+	s.Started(now.Add(-30 * time.Second))
+	if rec := get(t, h, "/healthz"); rec.Code != http.StatusOK {
+		t.Fatalf("healthz during short run = %d, want 200", rec.Code)
+	}
+
+	s.Started(now.Add(-5 * time.Minute))
+	rec := get(t, h, "/healthz")
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), "audit running for 5m0s") {
+		t.Fatalf("healthz during stuck run = %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestHealthzDetectsOverdueRun(t *testing.T) {
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+	s := New(0)
+	s.now = func() time.Time { return now }
+	s.SetStaleAfter(time.Minute)
+
+	report := reporter.NewReport(nil)
+	s.Started(now.Add(-time.Hour))
+	s.Record(now.Add(-time.Hour), time.Second, &report, nil)
+	s.ScheduleNext(now.Add(-30 * time.Second))
+	if !s.Status().Healthy {
+		t.Fatal("expected healthy within grace period")
+	}
+
+	s.ScheduleNext(now.Add(-10 * time.Minute))
+	status := s.Status()
+	if status.Healthy || !strings.Contains(status.Reason, "overdue by 10m0s") {
+		t.Fatalf("expected overdue status, got %+v", status)
+	}
+	if rec := get(t, s.HealthHandler(), "/healthz"); rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("healthz = %d, want 503", rec.Code)
+	}
+}
+
+func TestLastRunPayload(t *testing.T) {
+	s := New(0)
+	h := s.HealthHandler()
+
+	rec := get(t, h, "/lastrun")
+	var empty LastRun
+	if err := json.Unmarshal(rec.Body.Bytes(), &empty); err != nil {
+		t.Fatal(err)
+	}
+	if rec.Code != http.StatusOK || empty.Run != nil || !empty.Healthy {
+		t.Fatalf("unexpected empty lastrun: %d %+v", rec.Code, empty)
+	}
+
+	s.Record(time.Now(), 2*time.Second, nil, errors.New("timeout"))
+	next := time.Now().Add(time.Hour)
+	s.ScheduleNext(next)
+
+	rec = get(t, h, "/lastrun")
+	var got LastRun
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Run == nil || got.Run.Error != "timeout" || got.Running {
+		t.Fatalf("unexpected lastrun: %+v", got)
+	}
+	if got.NextRunAt == nil || !got.NextRunAt.Equal(next.UTC().Round(0)) {
+		t.Fatalf("nextRunAt = %v, want %v", got.NextRunAt, next)
+	}
+}
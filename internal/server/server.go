@@ -0,0 +1,141 @@
+package server
+
+import (
+	"embed"
+	"encoding/json"
+	"io/fs"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	"github.com/ppiankov/mongospectre/internal/reporter"
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+// DefaultHistorySize is the number of runs retained when no limit is given.
+const DefaultHistorySize = 50
+
+// Run summarizes the outcome of a single audit run.
+type Run struct {
+	ID          int               `json:"id"`
+	StartedAt   time.Time         `json:"startedAt"`
+	DurationMS  int64             `json:"durationMs"`
+	Error       string            `json:"error,omitempty"`
+	Summary     reporter.Summary  `json:"summary"`
+	MaxSeverity analyzer.Severity `json:"maxSeverity,omitempty"`
+}
+
+// Server holds the latest audit report and run history for the web dashboard.
+// It is safe for concurrent use: the audit loop records runs while HTTP
+// handlers read them.
+type Server struct {
+	mu         sync.RWMutex
+	latest     *reporter.Report
+	history    []Run
+	maxHistory int
+	nextID     int
+
+	// Health state for /healthz and /lastrun.
+	running    bool
+	runStarted time.Time
+	nextRun    time.Time
+	staleAfter time.Duration
+	now        func() time.Time
+
+	// Slack interactivity; nil leaves /api/slack/actions disabled.
+	slack    *SlackActions
+	ignoreMu sync.Mutex
+}
+
+// New creates a Server retaining at most maxHistory runs.
+func New(maxHistory int) *Server {
+	if maxHistory <= 0 {
+		maxHistory = DefaultHistorySize
+	}
+	return &Server{maxHistory: maxHistory, nextID: 1, now: time.Now}
+}
+
+// Record stores the outcome of an audit run. A failed run (err != nil) is
+// added to history but keeps the previous report as the latest.
+func (s *Server) Record(startedAt time.Time, duration time.Duration, report *reporter.Report, err error) Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	run := Run{
+		ID:         s.nextID,
+		StartedAt:  startedAt.UTC(),
+		DurationMS: duration.Milliseconds(),
+	}
+	s.nextID++
+	s.running = false
+
+	if err != nil {
+		run.Error = err.Error()
+	} else if report != nil {
+		run.Summary = report.Summary
+		run.MaxSeverity = report.MaxSeverity
+		s.latest = report
+	}
+
+	s.history = append(s.history, run)
+	if len(s.history) > s.maxHistory {
+		s.history = s.history[len(s.history)-s.maxHistory:]
+	}
+	return run
+}
+
+// Latest returns the most recent successful report, or nil if none exists.
+func (s *Server) Latest() *reporter.Report {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.latest
+}
+
+// History returns recorded runs, newest first.
+func (s *Server) History() []Run {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]Run, len(s.history))
+	for i := range s.history {
+		out[len(s.history)-1-i] = s.history[i]
+	}
+	return out
+}
+
+// Handler returns the HTTP handler serving the dashboard, JSON API, and
+// health endpoints.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	static, _ := fs.Sub(staticFiles, "static")
+	mux.Handle("GET /", http.FileServer(http.FS(static)))
+	mux.HandleFunc("GET /api/report", s.handleReport)
+	mux.HandleFunc("GET /api/history", s.handleHistory)
+	mux.HandleFunc("POST /api/slack/actions", s.handleSlackActions)
+	s.registerHealth(mux)
+
+	return mux
+}
+
+func (s *Server) handleReport(w http.ResponseWriter, _ *http.Request) {
+	report := s.Latest()
+	if report == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "no audit has completed yet"})
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+func (s *Server) handleHistory(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, s.History())
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	"github.com/ppiankov/mongospectre/internal/reporter"
+)
+
+func TestRecordKeepsLatestSuccessfulReport(t *testing.T) {
+	s := New(10)
+	report := reporter.NewReport([]analyzer.Finding{
+		{Type: analyzer.FindingUnusedIndex, Severity: analyzer.SeverityMedium, Database: "app", Collection: "users", Index: "idx"},
+	})
+
+	s.Record(time.Now(), time.Second, &report, nil)
+	run := s.Record(time.Now(), time.Second, nil, errors.New("connection refused"))
+
+	if run.ID != 2 || run.Error != "connection refused" {
+		t.Fatalf("unexpected failed run: %+v", run)
+	}
+	latest := s.Latest()
+	if latest == nil || latest.Summary.Total != 1 {
+		t.Fatalf("expected previous report to remain latest, got %+v", latest)
+	}
+
+	history := s.History()
+	if len(history) != 2 {
+		t.Fatalf("expected 2 runs, got %d", len(history))
+	}
+	if history[0].ID != 2 || history[1].ID != 1 {
+		t.Fatalf("expected newest first, got ids %d,%d", history[0].ID, history[1].ID)
+	}
+	if history[1].MaxSeverity != analyzer.SeverityMedium {
+		t.Fatalf("expected max severity medium, got %q", history[1].MaxSeverity)
+	}
+}
+
+func TestRecordTrimsHistory(t *testing.T) {
+	s := New(3)
+	for i := 0; i < 5; i++ {
+		report := reporter.NewReport(nil)
+		s.Record(time.Now(), 0, &report, nil)
+	}
+	history := s.History()
+	if len(history) != 3 {
+		t.Fatalf("expected 3 runs, got %d", len(history))
+	}
+	if history[0].ID != 5 || history[2].ID != 3 {
+		t.Fatalf("unexpected retained runs: %+v", history)
+	}
+}
+
+func TestHandlerReportBeforeFirstRun(t *testing.T) {
+	s := New(0)
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/report", http.NoBody))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "no audit has completed yet") {
+		t.Fatalf("unexpected body: %s", rec.Body.String())
+	}
+}
+
+func TestHandlerReportAndHistory(t *testing.T) {
+	s := New(0)
+	report := reporter.NewReport([]analyzer.Finding{
+		{Type: analyzer.FindingMissingIndex, Severity: analyzer.SeverityHigh, Database: "app", Collection: "orders"},
+	})
+	report.Metadata.Command = "serve"
+	s.Record(time.Now(), 1500*time.Millisecond, &report, nil)
+
+	h := s.Handler()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/report", http.NoBody))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("report status = %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("content type = %q", ct)
+	}
+	var got reporter.Report
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if got.Metadata.Command != "serve" || len(got.Findings) != 1 {
+		t.Fatalf("unexpected report: %+v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/history", http.NoBody))
+	var runs []Run
+	if err := json.Unmarshal(rec.Body.Bytes(), &runs); err != nil {
+		t.Fatalf("decode history: %v", err)
+	}
+	if len(runs) != 1 || runs[0].DurationMS != 1500 || runs[0].Summary.High != 1 {
+		t.Fatalf("unexpected history: %+v", runs)
+	}
+}
+
+func TestHandlerServesDashboard(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New(0).Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", http.NoBody))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"<title>mongospectre</title>", "api/report", "api/history", "sev-filter"} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("dashboard missing %q", want)
+		}
+	}
+}
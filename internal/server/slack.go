@@ -0,0 +1,188 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/analyzer"
+	"github.com/ppiankov/mongospectre/internal/notify"
+)
+
+// slackMaxSkew is how old a signed Slack request may be before it is
+// rejected as a possible replay.
+const slackMaxSkew = 5 * time.Minute
+
+// SlackActions configures the endpoint behind the acknowledge and snooze
+// buttons of Slack notifications.
+type SlackActions struct {
+	SigningSecret string        // the Slack app's signing secret
+	IgnoreDir     string        // directory holding .mongospectreignore
+	AckFor        time.Duration // how long an acknowledged finding stays ignored
+}
+
+// SetSlackActions enables POST /api/slack/actions. Button clicks append
+// an expiring rule to the ignore file, so the finding drops out of the
+// next audit run.
+func (s *Server) SetSlackActions(cfg SlackActions) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.slack = &cfg
+}
+
+func (s *Server) slackActions() *SlackActions {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.slack
+}
+
+// slackInteraction is the part of a Slack block_actions payload the
+// endpoint reads.
+type slackInteraction struct {
+	Type string `json:"type"`
+	User struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+	} `json:"user"`
+	ResponseURL string `json:"response_url"`
+	Actions     []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+}
+
+func (s *Server) handleSlackActions(w http.ResponseWriter, r *http.Request) {
+	cfg := s.slackActions()
+	if cfg == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "read body"})
+		return
+	}
+	if err := verifySlackSignature(cfg.SigningSecret, r.Header, body, s.now()); err != nil {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		return
+	}
+
+	// The signature covers the raw body, so the form is parsed from it
+	// rather than with r.ParseForm.
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid form body"})
+		return
+	}
+	var payload slackInteraction
+	if err := json.Unmarshal([]byte(form.Get("payload")), &payload); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return
+	}
+
+	var replies []string
+	for _, action := range payload.Actions {
+		var (
+			expiry time.Duration
+			verb   string
+		)
+		switch action.ActionID {
+		case notify.SlackActionAck:
+			expiry, verb = cfg.AckFor, "acknowledged"
+		case notify.SlackActionSnooze:
+			expiry, verb = notify.SlackSnoozeFor, "snoozed"
+		default:
+			continue
+		}
+		rule, ok := analyzer.ParseIgnoreRule(action.Value)
+		if !ok {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid finding %q", action.Value)})
+			return
+		}
+		if analyzer.IsRedacted(&analyzer.Finding{Collection: rule.Collection, Index: rule.Index}) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("finding %q is redacted; add its ignore rule by hand", action.Value)})
+			return
+		}
+		rule.Expires = s.now().Add(expiry).UTC().Truncate(time.Second)
+
+		s.ignoreMu.Lock()
+		err := analyzer.AppendIgnoreRule(cfg.IgnoreDir, rule, fmt.Sprintf("%s by %s via Slack", verb, slackUser(&payload)))
+		s.ignoreMu.Unlock()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "write ignore file: " + err.Error()})
+			return
+		}
+		replies = append(replies, fmt.Sprintf("%s %s until %s", slackUser(&payload), verb, rule.Expires.Format("2006-01-02 15:04 MST")))
+	}
+
+	if len(replies) > 0 && strings.HasPrefix(payload.ResponseURL, "https://hooks.slack.com/") {
+		// Slack wants the interaction answered within three seconds; the
+		// confirmation message is posted separately.
+		go s.postSlackReply(payload.ResponseURL, strings.Join(replies, "\n"))
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func slackUser(payload *slackInteraction) string {
+	if payload.User.Username != "" {
+		return "@" + payload.User.Username
+	}
+	if payload.User.ID != "" {
+		return payload.User.ID
+	}
+	return "unknown user"
+}
+
+func (s *Server) postSlackReply(responseURL, text string) {
+	data, _ := json.Marshal(map[string]interface{}{
+		"response_type":    "in_channel",
+		"replace_original": false,
+		"text":             text,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, responseURL, bytes.NewReader(data))
+	if err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return
+	}
+	_ = resp.Body.Close()
+}
+
+// verifySlackSignature checks the v0 request signature Slack computes
+// with the app's signing secret.
+func verifySlackSignature(secret string, header http.Header, body []byte, now time.Time) error {
+	ts := header.Get("X-Slack-Request-Timestamp")
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return errors.New("missing request timestamp")
+	}
+	if skew := now.Sub(time.Unix(sec, 0)); skew > slackMaxSkew || skew < -slackMaxSkew {
+		return errors.New("stale request timestamp")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = fmt.Fprintf(mac, "v0:%s:", ts)
+	_, _ = mac.Write(body)
+	want := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(want), []byte(header.Get("X-Slack-Signature"))) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ppiankov/mongospectre/internal/notify"
+)
+
+func slackRequest(t *testing.T, secret, payload string, sentAt time.Time) *http.Request {
+	t.Helper()
+	body := url.Values{"payload": {payload}}.Encode()
+	ts := strconv.FormatInt(sentAt.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte("v0:" + ts + ":" + body))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/slack/actions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-Slack-Request-Timestamp", ts)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func TestSlackActionsWriteIgnoreRule(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	s := New(10)
+	s.now = func() time.Time { return now }
+	s.SetSlackActions(SlackActions{SigningSecret: "s3cret", IgnoreDir: dir, AckFor: 30 * 24 * time.Hour})
+
+	payload := `{"type":"block_actions","user":{"id":"U1","username":"alice"},"actions":[{"action_id":"` +
+		notify.SlackActionSnooze + `","value":"UNUSED_INDEX app.users.idx_old"}]}`
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, slackRequest(t, "s3cret", payload, now))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, ".mongospectreignore"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# snoozed by @alice via Slack\nUNUSED_INDEX app.users.idx_old expires=2026-03-09T12:00:00Z\n"
+	if string(data) != want {
+		t.Fatalf("ignore file = %q, want %q", data, want)
+	}
+}
+
+func TestSlackActionsRejectBadRequests(t *testing.T) {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	s := New(10)
+	s.now = func() time.Time { return now }
+
+	payload := `{"actions":[{"action_id":"` + notify.SlackActionAck + `","value":"UNUSED_INDEX app.users"}]}`
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, slackRequest(t, "s3cret", payload, now))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("disabled endpoint status = %d, want 404", rec.Code)
+	}
+
+	s.SetSlackActions(SlackActions{SigningSecret: "s3cret", IgnoreDir: t.TempDir(), AckFor: time.Hour})
+	for name, req := range map[string]*http.Request{
+		"wrong secret": slackRequest(t, "other", payload, now),
+		"replayed":     slackRequest(t, "s3cret", payload, now.Add(-10*time.Minute)),
+	} {
+		rec := httptest.NewRecorder()
+		s.Handler().ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("%s: status = %d, want 401", name, rec.Code)
+		}
+	}
+	// A rule for a masked index name would never match.
+	redacted := `{"actions":[{"action_id":"` + notify.SlackActionAck + `","value":"UNUSED_INDEX app.users.[redacted:pii]_1"}]}`
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, slackRequest(t, "s3cret", redacted, now))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "redacted") {
+		t.Errorf("redacted finding: status = %d, body %s", rec.Code, rec.Body)
+	}
+}
@@ -634,6 +634,10 @@ func (m *model) renderDetail(f *analyzer.Finding) string {
 			formatBytes(coll.StorageSize),
 			len(coll.Indexes),
 		)
+		if coll.DataAge != nil {
+			_, _ = fmt.Fprintf(&b, "Sampled data age: oldest %s, newest %s\n",
+				coll.DataAge.Oldest.Format(time.DateOnly), coll.DataAge.Newest.Format(time.DateOnly))
+		}
 
 		if idx, ok := lookupIndex(&coll, f.Index); ok {
 			_, _ = fmt.Fprintf(&b, "Index definition: %s", formatIndexKey(idx.Key))